@@ -0,0 +1,119 @@
+// analysis/acknowledgements.go
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Acknowledgement actions: demoted anomalies stay in the report at info severity,
+// suppressed ones are moved to a separate list.
+const (
+	AcknowledgeDemote   = "demote"
+	AcknowledgeSuppress = "suppress"
+)
+
+// AnomalyAcknowledgement marks a known anomaly as expected. Type, Model and Host are
+// path.Match patterns; empty patterns match everything.
+type AnomalyAcknowledgement struct {
+	Type  string `json:"type,omitempty" yaml:"type,omitempty"`
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+	// Host matches the cluster label the analysis was generated with (--host-name).
+	Host   string `json:"host,omitempty" yaml:"host,omitempty"`
+	Reason string `json:"reason" yaml:"reason"`
+	// Action is demote (the default) or suppress.
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// AnomalyAcknowledgements is the root document of an acknowledgements file.
+type AnomalyAcknowledgements struct {
+	Acknowledgements []AnomalyAcknowledgement `json:"acknowledgements" yaml:"acknowledgements"`
+}
+
+// LoadAnomalyAcknowledgements reads a YAML or JSON acknowledgements file.
+func LoadAnomalyAcknowledgements(filePath string) (AnomalyAcknowledgements, error) {
+	var acks AnomalyAcknowledgements
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return acks, fmt.Errorf("unable to read acknowledgements file %s: %w", filePath, err)
+	}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		err = json.Unmarshal(data, &acks)
+	} else {
+		err = yaml.Unmarshal(data, &acks)
+	}
+	if err != nil {
+		return acks, fmt.Errorf("unable to parse acknowledgements file %s: %w", filePath, err)
+	}
+	for i, ack := range acks.Acknowledgements {
+		for _, pattern := range []string{ack.Type, ack.Model, ack.Host} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return acks, fmt.Errorf("acknowledgement %d in %s: invalid pattern %q: %w", i+1, filePath, pattern, err)
+			}
+		}
+		if strings.TrimSpace(ack.Reason) == "" {
+			return acks, fmt.Errorf("acknowledgement %d in %s: a reason is required", i+1, filePath)
+		}
+		switch action := strings.ToLower(strings.TrimSpace(ack.Action)); action {
+		case "":
+			acks.Acknowledgements[i].Action = AcknowledgeDemote
+		case AcknowledgeDemote, AcknowledgeSuppress:
+			acks.Acknowledgements[i].Action = action
+		default:
+			return acks, fmt.Errorf("acknowledgement %d in %s: unknown action %q (want demote or suppress)", i+1, filePath, ack.Action)
+		}
+	}
+	return acks, nil
+}
+
+// Match returns the first acknowledgement covering an anomaly of model on host.
+func (a AnomalyAcknowledgements) Match(anomaly Anomaly, host string) (AnomalyAcknowledgement, bool) {
+	for _, ack := range a.Acknowledgements {
+		if matchPattern(ack.Type, anomaly.Type) && matchPattern(ack.Model, anomaly.ModelName) && matchPattern(ack.Host, host) {
+			return ack, true
+		}
+	}
+	return AnomalyAcknowledgement{}, false
+}
+
+// matchPattern reports whether value matches pattern, treating an empty pattern as a wildcard.
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// AcknowledgeAnomalies applies acks to the analysis: demoted anomalies drop to info
+// severity and move below the unacknowledged ones, suppressed anomalies move to
+// SuppressedAnomalies. Both keep the acknowledgement reason.
+func (a *Analysis) AcknowledgeAnomalies(acks AnomalyAcknowledgements) {
+	kept := make([]Anomaly, 0, len(a.Anomalies))
+	for _, anomaly := range a.Anomalies {
+		ack, ok := acks.Match(anomaly, a.HostInfo.ClusterName)
+		if !ok {
+			kept = append(kept, anomaly)
+			continue
+		}
+		anomaly.Acknowledgement = ack.Reason
+		if ack.Action == AcknowledgeSuppress {
+			a.SuppressedAnomalies = append(a.SuppressedAnomalies, anomaly)
+			continue
+		}
+		anomaly.OriginalSeverity = anomaly.Severity
+		anomaly.Severity = "info"
+		kept = append(kept, anomaly)
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Acknowledgement == "" && kept[j].Acknowledgement != ""
+	})
+	a.Anomalies = kept
+}
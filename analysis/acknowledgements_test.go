@@ -0,0 +1,82 @@
+// analysis/acknowledgements_test.go
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAcknowledgeAnomalies verifies that matching anomalies are demoted or suppressed with
+// their reason, that host patterns match the cluster label, and that others are untouched.
+func TestAcknowledgeAnomalies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acks.yaml")
+	doc := `acknowledgements:
+  - type: very_high_latency
+    model: "big:*"
+    host: "cpu-*"
+    reason: "CPU-only host"
+    action: suppress
+  - type: high_variance
+    reason: "shared GPU"
+`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write acknowledgements: %v", err)
+	}
+	acks, err := LoadAnomalyAcknowledgements(path)
+	if err != nil {
+		t.Fatalf("LoadAnomalyAcknowledgements returned error: %v", err)
+	}
+	if acks.Acknowledgements[1].Action != AcknowledgeDemote {
+		t.Fatalf("expected demote by default, got %q", acks.Acknowledgements[1].Action)
+	}
+
+	analysis := Analysis{
+		HostInfo: HostInfo{ClusterName: "cpu-lab"},
+		Anomalies: []Anomaly{
+			{Type: "high_variance", ModelName: "small:1b", Severity: "warning"},
+			{Type: "very_high_latency", ModelName: "big:70b", Severity: "critical"},
+			{Type: "slow_small_model", ModelName: "small:1b", Severity: "warning"},
+		},
+	}
+	analysis.AcknowledgeAnomalies(acks)
+
+	if len(analysis.SuppressedAnomalies) != 1 || analysis.SuppressedAnomalies[0].Acknowledgement != "CPU-only host" {
+		t.Fatalf("unexpected suppressed anomalies: %+v", analysis.SuppressedAnomalies)
+	}
+	if len(analysis.Anomalies) != 2 || analysis.Anomalies[0].Type != "slow_small_model" {
+		t.Fatalf("expected the unacknowledged anomaly first, got %+v", analysis.Anomalies)
+	}
+	demoted := analysis.Anomalies[1]
+	if demoted.Severity != "info" || demoted.OriginalSeverity != "warning" || demoted.Acknowledgement != "shared GPU" {
+		t.Fatalf("unexpected demoted anomaly: %+v", demoted)
+	}
+
+	other := Analysis{
+		HostInfo:  HostInfo{ClusterName: "gpu-lab"},
+		Anomalies: []Anomaly{{Type: "very_high_latency", ModelName: "big:70b", Severity: "critical"}},
+	}
+	other.AcknowledgeAnomalies(acks)
+	if len(other.SuppressedAnomalies) != 0 || other.Anomalies[0].Severity != "critical" {
+		t.Fatalf("acknowledgement applied to the wrong host: %+v", other)
+	}
+}
+
+// TestLoadAnomalyAcknowledgementsInvalid verifies that missing reasons, unknown actions
+// and bad patterns are rejected.
+func TestLoadAnomalyAcknowledgementsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, doc := range map[string]string{
+		"reason.json":  `{"acknowledgements": [{"type": "high_variance"}]}`,
+		"action.json":  `{"acknowledgements": [{"reason": "x", "action": "hide"}]}`,
+		"pattern.json": `{"acknowledgements": [{"model": "[", "reason": "x"}]}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, err := LoadAnomalyAcknowledgements(path); err == nil {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
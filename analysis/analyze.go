@@ -0,0 +1,861 @@
+// analysis/analyze.go
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Stats mirrors the per-iteration statistics captured in benchmark JSON.
+type Stats struct {
+	TotalExecutionTime int64   `json:"totalExecutionTime"`
+	TimeToFirstToken   int64   `json:"timeToFirstToken"`
+	TokensPerSecond    float64 `json:"tokensPerSecond"`
+	InputTokenCount    int     `json:"inputTokenCount"`
+	OutputTokenCount   int     `json:"outputTokenCount"`
+}
+
+// Iteration captures a single benchmark run for a model.
+type Iteration struct {
+	Iteration int   `json:"iteration"`
+	Stats     Stats `json:"stats"`
+	// QuestionID, Suite, Difficulty and Correct describe the prompt-suite question asked, if any.
+	QuestionID string `json:"questionId,omitempty"`
+	Suite      string `json:"suite,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Correct    *bool  `json:"correct,omitempty"`
+	// Grader is the grader that scored the answer, e.g. "regex" or "judge".
+	Grader      string `json:"grader,omitempty"`
+	GradeReason string `json:"gradeReason,omitempty"`
+	// SystemPrompt names the system prompt variant the question was asked with and
+	// SystemPromptHash identifies its text.
+	SystemPrompt     string `json:"systemPrompt,omitempty"`
+	SystemPromptHash string `json:"systemPromptHash,omitempty"`
+	// ParameterTemplate names the parameter template the question was asked with.
+	ParameterTemplate string `json:"parameterTemplate,omitempty"`
+	// Contended reports that the iteration overlapped another on the same host.
+	Contended bool `json:"contended,omitempty"`
+	// Warmup reports that the iteration was left out of the averages as a warm-up.
+	Warmup bool `json:"warmup,omitempty"`
+	// RecordID names the iteration's raw request/response archive, if one was kept.
+	RecordID string `json:"recordId,omitempty"`
+	// ToolCalls names the MCP tools called while answering, and ToolNeeded whether the
+	// suite says the question needs one.
+	ToolCalls  []string `json:"toolCalls,omitempty"`
+	ToolNeeded *bool    `json:"toolNeeded,omitempty"`
+	// SecondOpinion is the second judge's verdict and SecondReason its explanation, when a
+	// second judge graded the answer.
+	SecondOpinion *bool  `json:"secondOpinion,omitempty"`
+	SecondReason  string `json:"secondReason,omitempty"`
+}
+
+// ModelBenchmark is the root payload for a model's benchmark record.
+type ModelBenchmark struct {
+	ModelName      string `json:"modelName"`
+	BenchmarkCount int    `json:"benchmarkCount"`
+	AverageStats   Stats  `json:"averageStats"`
+	MinStats       Stats  `json:"minStats"`
+	MaxStats       Stats  `json:"maxStats"`
+	// P50Stats to P99Stats are percentiles of the averaged iterations.
+	P50Stats   *Stats      `json:"p50Stats,omitempty"`
+	P90Stats   *Stats      `json:"p90Stats,omitempty"`
+	P95Stats   *Stats      `json:"p95Stats,omitempty"`
+	P99Stats   *Stats      `json:"p99Stats,omitempty"`
+	Iterations []Iteration `json:"iterations"`
+	// Accuracy is set when the run asked prompt-suite questions with expected answers.
+	Accuracy *AccuracyStats `json:"accuracy,omitempty"`
+	// Concurrency is how many questions ran at once on the host, when more than one.
+	Concurrency int `json:"concurrency,omitempty"`
+	// ColdStart records the model's load time and first iteration, before it was warm.
+	ColdStart *ColdStartStats `json:"coldStart,omitempty"`
+	// Tools reports that the model could call MCP tools while answering.
+	Tools bool `json:"tools,omitempty"`
+}
+
+// ColdStartStats records how a model behaved before it was warm. Durations are in
+// nanoseconds, like Stats. WarmupIterations is how many leading iterations were left out
+// of the averages and variance.
+type ColdStartStats struct {
+	LoadTime                int64 `json:"loadTime"`
+	FirstTimeToFirstToken   int64 `json:"firstTimeToFirstToken"`
+	FirstTotalExecutionTime int64 `json:"firstTotalExecutionTime"`
+	WarmupIterations        int   `json:"warmupIterations,omitempty"`
+}
+
+// AccuracyCount tallies scored answers.
+type AccuracyCount struct {
+	Scored  int `json:"scored"`
+	Correct int `json:"correct"`
+}
+
+// AccuracyStats summarizes how many prompt-suite answers a model got right, overall and
+// broken down by suite, difficulty, system prompt variant and, for multi-turn questions,
+// turn number.
+type AccuracyStats struct {
+	AccuracyCount
+	Rate           float64                  `json:"rate"`
+	BySuite        map[string]AccuracyCount `json:"bySuite,omitempty"`
+	ByDifficulty   map[string]AccuracyCount `json:"byDifficulty,omitempty"`
+	BySystemPrompt map[string]AccuracyCount `json:"bySystemPrompt,omitempty"`
+	ByTurn         map[string]AccuracyCount `json:"byTurn,omitempty"`
+}
+
+// Add records one scored answer and updates the rate. systemPrompt labels the system
+// prompt variant the answer was given under and is empty when none was set.
+func (a *AccuracyStats) Add(suite, difficulty, systemPrompt string, correct bool) {
+	a.Scored++
+	if correct {
+		a.Correct++
+	}
+	a.Rate = float64(a.Correct) / float64(a.Scored)
+	a.BySuite = tallyAccuracy(a.BySuite, suite, correct)
+	a.ByDifficulty = tallyAccuracy(a.ByDifficulty, difficulty, correct)
+	a.BySystemPrompt = tallyAccuracy(a.BySystemPrompt, systemPrompt, correct)
+}
+
+// AddTurn records one scored turn of a multi-turn question, so accuracy can be compared
+// as the conversation grows.
+func (a *AccuracyStats) AddTurn(suite, difficulty, systemPrompt string, turn int, correct bool) {
+	a.Add(suite, difficulty, systemPrompt, correct)
+	a.ByTurn = tallyAccuracy(a.ByTurn, fmt.Sprint(turn), correct)
+}
+
+// tallyAccuracy counts one answer under key, skipping empty keys.
+func tallyAccuracy(counts map[string]AccuracyCount, key string, correct bool) map[string]AccuracyCount {
+	if key == "" {
+		return counts
+	}
+	if counts == nil {
+		counts = make(map[string]AccuracyCount)
+	}
+	count := counts[key]
+	count.Scored++
+	if correct {
+		count.Correct++
+	}
+	counts[key] = count
+	return counts
+}
+
+// BenchmarkResults stores the entire benchmark document keyed by model name.
+type BenchmarkResults map[string]ModelBenchmark
+
+// AggregatedStats stores derived aggregate values for a model.
+type AggregatedStats struct {
+	TokensPerSecond           float64 `json:"tokensPerSecond"`
+	TimeToFirstTokenSeconds   float64 `json:"timeToFirstTokenSeconds"`
+	TotalExecutionTimeSeconds float64 `json:"totalExecutionTimeSeconds"`
+	InputTokens               float64 `json:"inputTokens"`
+	OutputTokens              float64 `json:"outputTokens"`
+}
+
+// VarianceStats stores standard deviation metrics for key values.
+type VarianceStats struct {
+	TokensPerSecondStdDev         float64 `json:"tokensPerSecondStdDev"`
+	TimeToFirstTokenStdDevSeconds float64 `json:"timeToFirstTokenStdDevSeconds"`
+	OutputTokensStdDev            float64 `json:"outputTokensStdDev"`
+}
+
+// ScoreStats contains normalized scores for each model.
+type ScoreStats struct {
+	ThroughputScore float64 `json:"throughputScore"`
+	LatencyScore    float64 `json:"latencyScore"`
+	EfficiencyScore float64 `json:"efficiencyScore"`
+}
+
+// LabelStats captures qualitative assessments for a model.
+type LabelStats struct {
+	RelativeSpeedTier      string `json:"relativeSpeedTier"`
+	LatencyProfile         string `json:"latencyProfile"`
+	Stability              string `json:"stability"`
+	InteractiveSuitability string `json:"interactiveSuitability"`
+}
+
+// DerivedRatios stores ratio-based comparisons for a model.
+type DerivedRatios struct {
+	LatencyShareOfTotal float64 `json:"latencyShareOfTotal"`
+	RelativeToFastest   float64 `json:"relativeToFastest"`
+}
+
+// IterationSample is the per-iteration view of a model's run embedded for report charts.
+type IterationSample struct {
+	Iteration                 int      `json:"iteration"`
+	TokensPerSecond           float64  `json:"tokensPerSecond"`
+	TimeToFirstTokenSeconds   float64  `json:"timeToFirstTokenSeconds"`
+	TotalExecutionTimeSeconds float64  `json:"totalExecutionTimeSeconds"`
+	OutputTokens              int      `json:"outputTokens"`
+	QuestionID                string   `json:"questionId,omitempty"`
+	Suite                     string   `json:"suite,omitempty"`
+	Difficulty                string   `json:"difficulty,omitempty"`
+	Correct                   *bool    `json:"correct,omitempty"`
+	Grader                    string   `json:"grader,omitempty"`
+	GradeReason               string   `json:"gradeReason,omitempty"`
+	SystemPrompt              string   `json:"systemPrompt,omitempty"`
+	SystemPromptHash          string   `json:"systemPromptHash,omitempty"`
+	ParameterTemplate         string   `json:"parameterTemplate,omitempty"`
+	Contended                 bool     `json:"contended,omitempty"`
+	Warmup                    bool     `json:"warmup,omitempty"`
+	RecordID                  string   `json:"recordId,omitempty"`
+	ToolCalls                 []string `json:"toolCalls,omitempty"`
+	ToolNeeded                *bool    `json:"toolNeeded,omitempty"`
+	SecondOpinion             *bool    `json:"secondOpinion,omitempty"`
+	SecondReason              string   `json:"secondReason,omitempty"`
+}
+
+// ModelAnalysis is the top-level entry for each model in the analysis.
+type ModelAnalysis struct {
+	ModelName      string            `json:"modelName"`
+	BenchmarkCount int               `json:"benchmarkCount"`
+	Avg            AggregatedStats   `json:"avg"`
+	Min            AggregatedStats   `json:"min"`
+	Max            AggregatedStats   `json:"max"`
+	P50            AggregatedStats   `json:"p50"`
+	P90            AggregatedStats   `json:"p90"`
+	P95            AggregatedStats   `json:"p95"`
+	P99            AggregatedStats   `json:"p99"`
+	Variance       VarianceStats     `json:"variance"`
+	Scores         ScoreStats        `json:"scores"`
+	Labels         LabelStats        `json:"labels"`
+	DerivedRatios  DerivedRatios     `json:"derivedRatios"`
+	Notes          []string          `json:"notes"`
+	Iterations     []IterationSample `json:"iterations,omitempty"`
+	Accuracy       *AccuracyStats    `json:"accuracy,omitempty"`
+	Concurrency    int               `json:"concurrency,omitempty"`
+	ColdStart      *ColdStartSummary `json:"coldStart,omitempty"`
+	// ParameterTemplates compares the model's parameter templates when it ran under more
+	// than one.
+	ParameterTemplates []ParameterTemplateStats `json:"parameterTemplates,omitempty"`
+	// ToolUse summarizes the tools the model called when it ran with MCP tools.
+	ToolUse *ToolUseStats `json:"toolUse,omitempty"`
+	// Judging measures how often two judges disagreed when a second judge graded answers.
+	Judging *JudgingStats `json:"judging,omitempty"`
+}
+
+// ColdStartSummary is the cold-start view of a model in seconds.
+type ColdStartSummary struct {
+	LoadTimeSeconds                float64 `json:"loadTimeSeconds"`
+	FirstTimeToFirstTokenSeconds   float64 `json:"firstTimeToFirstTokenSeconds"`
+	FirstTotalExecutionTimeSeconds float64 `json:"firstTotalExecutionTimeSeconds"`
+	WarmupIterations               int     `json:"warmupIterations,omitempty"`
+}
+
+// ThroughputRankingEntry captures ordering by throughput.
+type ThroughputRankingEntry struct {
+	ModelName          string  `json:"modelName"`
+	AvgTokensPerSecond float64 `json:"avgTokensPerSecond"`
+}
+
+// LatencyRankingEntry captures ordering by latency.
+type LatencyRankingEntry struct {
+	ModelName                  string  `json:"modelName"`
+	AvgTimeToFirstTokenSeconds float64 `json:"avgTimeToFirstTokenSeconds"`
+}
+
+// EfficiencyRankingEntry captures ordering by efficiency score.
+type EfficiencyRankingEntry struct {
+	ModelName       string  `json:"modelName"`
+	EfficiencyScore float64 `json:"efficiencyScore"`
+}
+
+// Rankings groups the sorted ranking lists.
+type Rankings struct {
+	ByThroughput      []ThroughputRankingEntry `json:"byThroughput"`
+	ByLatency         []LatencyRankingEntry    `json:"byLatency"`
+	ByEfficiencyScore []EfficiencyRankingEntry `json:"byEfficiencyScore"`
+}
+
+// Anomaly describes any notable outlier detected in the analysis.
+type Anomaly struct {
+	Type      string `json:"type"`
+	ModelName string `json:"modelName"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	// Acknowledgement is the reason from the acknowledgements file when the anomaly is a
+	// known one; OriginalSeverity is its severity before it was demoted.
+	Acknowledgement  string `json:"acknowledgement,omitempty"`
+	OriginalSeverity string `json:"originalSeverity,omitempty"`
+}
+
+// OverallSummary provides a concise overview for the report header.
+type OverallSummary struct {
+	FastestModel       string   `json:"fastestModel"`
+	BestLatencyModel   string   `json:"bestLatencyModel"`
+	MostEfficientModel string   `json:"mostEfficientModel"`
+	SummaryNotes       []string `json:"summaryNotes"`
+}
+
+// HostInfo is optional metadata describing the environment.
+type HostInfo struct {
+	ClusterName string `json:"clusterName"`
+	Notes       string `json:"notes"`
+}
+
+// Analysis is the root document returned by AnalyzeMetrics and rendered by agon's reports.
+type Analysis struct {
+	// SchemaVersion is the SchemaVersion the document was written under; documents from
+	// before versioning leave it empty.
+	SchemaVersion   string          `json:"schemaVersion,omitempty"`
+	GeneratedAt     time.Time       `json:"generatedAt"`
+	HostInfo        HostInfo        `json:"hostInfo"`
+	Overall         OverallSummary  `json:"overall"`
+	Models          []ModelAnalysis `json:"models"`
+	Rankings        Rankings        `json:"rankings"`
+	Anomalies       []Anomaly       `json:"anomalies"`
+	Recommendations []string        `json:"recommendations"`
+	Scoring         *ScoringConfig  `json:"scoring,omitempty"`
+	// SuppressedAnomalies are anomalies an acknowledgement hid from Anomalies.
+	SuppressedAnomalies []Anomaly `json:"suppressedAnomalies,omitempty"`
+	// Environment is the snapshot recorded beside the benchmark results, when one exists.
+	Environment *EnvironmentSnapshot `json:"environment,omitempty"`
+	// EnvironmentSummary is Environment rendered as lines for report tooltips.
+	EnvironmentSummary []string `json:"environmentSummary,omitempty"`
+}
+
+// AttachEnvironment records the run environment on the analysis.
+func (a *Analysis) AttachEnvironment(env EnvironmentSnapshot) {
+	a.Environment = &env
+	a.EnvironmentSummary = env.Summary()
+}
+
+// AnalyzeMetrics transforms raw benchmark results into a structured Analysis object
+// using the default scoring profile.
+func AnalyzeMetrics(results BenchmarkResults, host HostInfo) Analysis {
+	return AnalyzeMetricsWithScoring(results, host, DefaultScoringConfig())
+}
+
+// AnalyzeMetricsWithScoring is AnalyzeMetrics with caller-supplied score weights and label cutoffs.
+func AnalyzeMetricsWithScoring(results BenchmarkResults, host HostInfo, scoring ScoringConfig) Analysis {
+	analysis := Analysis{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		HostInfo:      host,
+		Scoring:       &scoring,
+	}
+
+	if len(results) == 0 {
+		analysis.Models = []ModelAnalysis{}
+		analysis.Recommendations = []string{"Consider keeping sessions warm and reducing context size to minimize TTFT across all models."}
+		return analysis
+	}
+
+	modelNames := make([]string, 0, len(results))
+	for name := range results {
+		modelNames = append(modelNames, name)
+	}
+	sort.Strings(modelNames)
+
+	globalMaxAvgTPS := 0.0
+	globalMinAvgTTFT := math.MaxFloat64
+
+	modelAnalyses := make([]*ModelAnalysis, 0, len(modelNames))
+
+	for _, name := range modelNames {
+		bench := results[name]
+		ma := &ModelAnalysis{
+			ModelName:      name,
+			BenchmarkCount: bench.BenchmarkCount,
+			Accuracy:       bench.Accuracy,
+			Concurrency:    bench.Concurrency,
+		}
+		if cs := bench.ColdStart; cs != nil {
+			ma.ColdStart = &ColdStartSummary{
+				LoadTimeSeconds:                nsToSeconds(cs.LoadTime),
+				FirstTimeToFirstTokenSeconds:   nsToSeconds(cs.FirstTimeToFirstToken),
+				FirstTotalExecutionTimeSeconds: nsToSeconds(cs.FirstTotalExecutionTime),
+				WarmupIterations:               cs.WarmupIterations,
+			}
+		}
+		if ma.BenchmarkCount == 0 {
+			ma.BenchmarkCount = len(bench.Iterations)
+		}
+
+		iterTPS := make([]float64, 0, len(bench.Iterations))
+		iterTTFT := make([]float64, 0, len(bench.Iterations))
+		iterOutputTokens := make([]float64, 0, len(bench.Iterations))
+		iterInputTokens := make([]float64, 0, len(bench.Iterations))
+		iterTotalExec := make([]float64, 0, len(bench.Iterations))
+
+		// Warm-up iterations stay in the samples but not in the aggregates, unless the run
+		// has nothing else.
+		steadyOnly := false
+		for _, iter := range bench.Iterations {
+			if !iter.Warmup {
+				steadyOnly = true
+				break
+			}
+		}
+
+		for _, iter := range bench.Iterations {
+			ma.Iterations = append(ma.Iterations, IterationSample{
+				Iteration:                 iter.Iteration,
+				TokensPerSecond:           iter.Stats.TokensPerSecond,
+				TimeToFirstTokenSeconds:   nsToSeconds(iter.Stats.TimeToFirstToken),
+				TotalExecutionTimeSeconds: nsToSeconds(iter.Stats.TotalExecutionTime),
+				OutputTokens:              iter.Stats.OutputTokenCount,
+				QuestionID:                iter.QuestionID,
+				Suite:                     iter.Suite,
+				Difficulty:                iter.Difficulty,
+				Correct:                   iter.Correct,
+				Grader:                    iter.Grader,
+				GradeReason:               iter.GradeReason,
+				SystemPrompt:              iter.SystemPrompt,
+				SystemPromptHash:          iter.SystemPromptHash,
+				ParameterTemplate:         iter.ParameterTemplate,
+				Contended:                 iter.Contended,
+				Warmup:                    iter.Warmup,
+				RecordID:                  iter.RecordID,
+				ToolCalls:                 iter.ToolCalls,
+				ToolNeeded:                iter.ToolNeeded,
+				SecondOpinion:             iter.SecondOpinion,
+				SecondReason:              iter.SecondReason,
+			})
+			if steadyOnly && iter.Warmup {
+				continue
+			}
+			iterTPS = append(iterTPS, iter.Stats.TokensPerSecond)
+			iterTTFT = append(iterTTFT, nsToSeconds(iter.Stats.TimeToFirstToken))
+			iterOutputTokens = append(iterOutputTokens, float64(iter.Stats.OutputTokenCount))
+			iterInputTokens = append(iterInputTokens, float64(iter.Stats.InputTokenCount))
+			iterTotalExec = append(iterTotalExec, nsToSeconds(iter.Stats.TotalExecutionTime))
+		}
+
+		avgTPS := bench.AverageStats.TokensPerSecond
+		if avgTPS == 0 {
+			avgTPS = meanFloat64(iterTPS)
+		}
+
+		avgTTFT := nsToSeconds(bench.AverageStats.TimeToFirstToken)
+		if avgTTFT == 0 {
+			avgTTFT = meanFloat64(iterTTFT)
+		}
+
+		avgTotalExec := nsToSeconds(bench.AverageStats.TotalExecutionTime)
+		if avgTotalExec == 0 {
+			avgTotalExec = meanFloat64(iterTotalExec)
+		}
+
+		avgInputTokens := fallbackAverage(float64(bench.AverageStats.InputTokenCount), iterInputTokens)
+		avgOutputTokens := fallbackAverage(float64(bench.AverageStats.OutputTokenCount), iterOutputTokens)
+
+		ma.Avg = AggregatedStats{
+			TokensPerSecond:           avgTPS,
+			TimeToFirstTokenSeconds:   avgTTFT,
+			TotalExecutionTimeSeconds: avgTotalExec,
+			InputTokens:               avgInputTokens,
+			OutputTokens:              avgOutputTokens,
+		}
+
+		ma.Min = AggregatedStats{
+			TokensPerSecond:           bench.MinStats.TokensPerSecond,
+			TimeToFirstTokenSeconds:   nsToSeconds(bench.MinStats.TimeToFirstToken),
+			TotalExecutionTimeSeconds: nsToSeconds(bench.MinStats.TotalExecutionTime),
+			InputTokens:               float64(bench.MinStats.InputTokenCount),
+			OutputTokens:              float64(bench.MinStats.OutputTokenCount),
+		}
+
+		ma.Max = AggregatedStats{
+			TokensPerSecond:           bench.MaxStats.TokensPerSecond,
+			TimeToFirstTokenSeconds:   nsToSeconds(bench.MaxStats.TimeToFirstToken),
+			TotalExecutionTimeSeconds: nsToSeconds(bench.MaxStats.TotalExecutionTime),
+			InputTokens:               float64(bench.MaxStats.InputTokenCount),
+			OutputTokens:              float64(bench.MaxStats.OutputTokenCount),
+		}
+
+		// Percentiles come from the iterations; inputs without them fall back to the
+		// percentiles the benchmark stored.
+		percentiles := func(p float64, stored *Stats) AggregatedStats {
+			if len(iterTPS) == 0 && stored != nil {
+				return AggregatedStats{
+					TokensPerSecond:           stored.TokensPerSecond,
+					TimeToFirstTokenSeconds:   nsToSeconds(stored.TimeToFirstToken),
+					TotalExecutionTimeSeconds: nsToSeconds(stored.TotalExecutionTime),
+					InputTokens:               float64(stored.InputTokenCount),
+					OutputTokens:              float64(stored.OutputTokenCount),
+				}
+			}
+			return AggregatedStats{
+				TokensPerSecond:           Percentile(iterTPS, p),
+				TimeToFirstTokenSeconds:   Percentile(iterTTFT, p),
+				TotalExecutionTimeSeconds: Percentile(iterTotalExec, p),
+				InputTokens:               Percentile(iterInputTokens, p),
+				OutputTokens:              Percentile(iterOutputTokens, p),
+			}
+		}
+		ma.P50 = percentiles(50, bench.P50Stats)
+		ma.P90 = percentiles(90, bench.P90Stats)
+		ma.P95 = percentiles(95, bench.P95Stats)
+		ma.P99 = percentiles(99, bench.P99Stats)
+
+		ma.ParameterTemplates = compareParameterTemplates(bench.Iterations)
+		if bench.Tools {
+			ma.ToolUse = summarizeToolUse(bench.Iterations)
+		}
+		ma.Judging = summarizeJudging(bench.Iterations)
+
+		ma.Variance = VarianceStats{
+			TokensPerSecondStdDev:         stddevFromValues(iterTPS, ma.Avg.TokensPerSecond),
+			TimeToFirstTokenStdDevSeconds: stddevFromValues(iterTTFT, ma.Avg.TimeToFirstTokenSeconds),
+			OutputTokensStdDev:            stddevFromValues(iterOutputTokens, ma.Avg.OutputTokens),
+		}
+
+		if ma.Avg.TokensPerSecond > globalMaxAvgTPS {
+			globalMaxAvgTPS = ma.Avg.TokensPerSecond
+		}
+		if ma.Avg.TimeToFirstTokenSeconds > 0 && ma.Avg.TimeToFirstTokenSeconds < globalMinAvgTTFT {
+			globalMinAvgTTFT = ma.Avg.TimeToFirstTokenSeconds
+		}
+
+		modelAnalyses = append(modelAnalyses, ma)
+	}
+
+	if globalMinAvgTTFT == math.MaxFloat64 {
+		globalMinAvgTTFT = 0
+	}
+
+	rankThroughput := make([]ThroughputRankingEntry, 0, len(modelAnalyses))
+	rankLatency := make([]LatencyRankingEntry, 0, len(modelAnalyses))
+	rankEfficiency := make([]EfficiencyRankingEntry, 0, len(modelAnalyses))
+
+	multiModel := len(modelAnalyses) > 1
+
+	for _, ma := range modelAnalyses {
+		if multiModel && globalMaxAvgTPS > 0 {
+			ma.Scores.ThroughputScore = clampFloat((ma.Avg.TokensPerSecond/globalMaxAvgTPS)*100, 0, 100)
+		} else if !multiModel {
+			ma.Scores.ThroughputScore = 100
+		}
+
+		if multiModel && ma.Avg.TimeToFirstTokenSeconds > 0 && globalMinAvgTTFT > 0 {
+			ma.Scores.LatencyScore = clampFloat((globalMinAvgTTFT/ma.Avg.TimeToFirstTokenSeconds)*100, 0, 100)
+		} else if !multiModel {
+			ma.Scores.LatencyScore = 100
+		} else if ma.Avg.TimeToFirstTokenSeconds == 0 {
+			ma.Scores.LatencyScore = 100
+		}
+
+		ma.Scores.EfficiencyScore = scoring.efficiencyScore(ma.Scores.ThroughputScore, ma.Scores.LatencyScore)
+
+		if globalMaxAvgTPS > 0 {
+			ma.DerivedRatios.RelativeToFastest = ma.Avg.TokensPerSecond / globalMaxAvgTPS
+		}
+
+		if ma.Avg.TotalExecutionTimeSeconds > 0 {
+			ratio := ma.Avg.TimeToFirstTokenSeconds / ma.Avg.TotalExecutionTimeSeconds
+			ma.DerivedRatios.LatencyShareOfTotal = clampFloat(ratio, 0, 1)
+		}
+
+		ma.Labels.RelativeSpeedTier = classifySpeedTier(ma.DerivedRatios.RelativeToFastest, scoring)
+		ma.Labels.LatencyProfile = classifyLatencyProfile(ma.Avg.TimeToFirstTokenSeconds, scoring)
+		ma.Labels.Stability = classifyStability(ma.Variance.TokensPerSecondStdDev, ma.Avg.TokensPerSecond, scoring)
+		ma.Labels.InteractiveSuitability = classifyInteractiveSuitability(ma.Avg.TimeToFirstTokenSeconds, ma.Avg.TokensPerSecond, scoring)
+
+		ma.Notes = buildModelNotes(*ma)
+
+		rankThroughput = append(rankThroughput, ThroughputRankingEntry{
+			ModelName:          ma.ModelName,
+			AvgTokensPerSecond: ma.Avg.TokensPerSecond,
+		})
+		rankLatency = append(rankLatency, LatencyRankingEntry{
+			ModelName:                  ma.ModelName,
+			AvgTimeToFirstTokenSeconds: ma.Avg.TimeToFirstTokenSeconds,
+		})
+		rankEfficiency = append(rankEfficiency, EfficiencyRankingEntry{
+			ModelName:       ma.ModelName,
+			EfficiencyScore: ma.Scores.EfficiencyScore,
+		})
+	}
+
+	sort.Slice(rankThroughput, func(i, j int) bool {
+		return rankThroughput[i].AvgTokensPerSecond > rankThroughput[j].AvgTokensPerSecond
+	})
+	sort.Slice(rankLatency, func(i, j int) bool {
+		return rankLatency[i].AvgTimeToFirstTokenSeconds < rankLatency[j].AvgTimeToFirstTokenSeconds
+	})
+	sort.Slice(rankEfficiency, func(i, j int) bool {
+		return rankEfficiency[i].EfficiencyScore > rankEfficiency[j].EfficiencyScore
+	})
+
+	finalModels := make([]ModelAnalysis, len(modelAnalyses))
+	for i, ma := range modelAnalyses {
+		finalModels[i] = *ma
+	}
+
+	analysis.Models = finalModels
+	analysis.Rankings = Rankings{
+		ByThroughput:      rankThroughput,
+		ByLatency:         rankLatency,
+		ByEfficiencyScore: rankEfficiency,
+	}
+
+	analysis.Overall = buildOverallSummary(analysis.Rankings)
+	analysis.Anomalies = detectAnomalies(analysis.Models, scoring)
+	analysis.Recommendations = buildRecommendations(analysis.Models)
+
+	return analysis
+}
+
+// buildOverallSummary creates a summary of the benchmark results.
+func buildOverallSummary(rankings Rankings) OverallSummary {
+	var summary OverallSummary
+	if len(rankings.ByThroughput) > 0 {
+		entry := rankings.ByThroughput[0]
+		summary.FastestModel = entry.ModelName
+		summary.SummaryNotes = append(summary.SummaryNotes, fmt.Sprintf("Fastest model by throughput is %s with %.2f tokens/sec.", entry.ModelName, entry.AvgTokensPerSecond))
+	}
+	if len(rankings.ByLatency) > 0 {
+		entry := rankings.ByLatency[0]
+		summary.BestLatencyModel = entry.ModelName
+		summary.SummaryNotes = append(summary.SummaryNotes, fmt.Sprintf("Best latency model is %s with time to first token ≈ %.2fs.", entry.ModelName, entry.AvgTimeToFirstTokenSeconds))
+	}
+	if len(rankings.ByEfficiencyScore) > 0 {
+		entry := rankings.ByEfficiencyScore[0]
+		summary.MostEfficientModel = entry.ModelName
+		summary.SummaryNotes = append(summary.SummaryNotes, fmt.Sprintf("Most efficient model is %s with an efficiency score of %.1f.", entry.ModelName, entry.EfficiencyScore))
+	}
+	return summary
+}
+
+// detectAnomalies identifies any notable outliers in the analysis.
+func detectAnomalies(models []ModelAnalysis, scoring ScoringConfig) []Anomaly {
+	anomalies := make([]Anomaly, 0)
+	for _, model := range models {
+		if model.Avg.TimeToFirstTokenSeconds > scoring.UnusableTTFTSeconds {
+			anomalies = append(anomalies, Anomaly{
+				Type:      "very_high_latency",
+				ModelName: model.ModelName,
+				Severity:  "critical",
+				Message:   fmt.Sprintf("%s has extremely high time to first token, making it unsuitable for interactive workloads.", model.ModelName),
+			})
+		}
+		if model.Labels.RelativeSpeedTier == "slow" && hasFasterModelWithSimilarOutputs(model, models) {
+			anomalies = append(anomalies, Anomaly{
+				Type:      "slow_small_model",
+				ModelName: model.ModelName,
+				Severity:  "warning",
+				Message:   fmt.Sprintf("%s is significantly slower than other models despite similar or smaller outputs; fixed overhead may dominate on this hardware.", model.ModelName),
+			})
+		}
+		if model.Labels.Stability == "unstable" {
+			anomalies = append(anomalies, Anomaly{
+				Type:      "high_variance",
+				ModelName: model.ModelName,
+				Severity:  "warning",
+				Message:   fmt.Sprintf("%s shows high variability across runs; may indicate contention or thermal throttling.", model.ModelName),
+			})
+		}
+		if model.Judging != nil && len(model.Judging.Unreliable) > 0 {
+			anomalies = append(anomalies, Anomaly{
+				Type:      "unreliable_grading",
+				ModelName: model.ModelName,
+				Severity:  "warning",
+				Message:   fmt.Sprintf("Judges often disagree on %s's answers to %s; their grading is unreliable.", model.ModelName, unreliableQuestionList(model.Judging.Unreliable)),
+			})
+		}
+	}
+	return anomalies
+}
+
+// buildRecommendations generates a list of recommendations based on the analysis.
+func buildRecommendations(models []ModelAnalysis) []string {
+	recs := make([]string, 0)
+	for _, model := range models {
+		if model.Labels.InteractiveSuitability == "good" && model.Labels.RelativeSpeedTier == "top" {
+			recs = append(recs, fmt.Sprintf("Use %s as the default interactive model on this hardware.", model.ModelName))
+		}
+		if model.Labels.InteractiveSuitability == "unusable" {
+			recs = append(recs, fmt.Sprintf("Avoid %s for interactive usage; reserve it for batch-style or very small outputs.", model.ModelName))
+		}
+	}
+	recs = append(recs, "Consider keeping sessions warm and reducing context size to minimize TTFT across all models.")
+	return recs
+}
+
+// classifySpeedTier categorizes a model's speed based on its performance relative to the fastest model.
+func classifySpeedTier(relative float64, scoring ScoringConfig) string {
+	switch {
+	case relative >= scoring.TopTierRelative:
+		return "top"
+	case relative >= scoring.MidTierRelative:
+		return "mid"
+	default:
+		return "slow"
+	}
+}
+
+// classifyLatencyProfile categorizes a model's latency profile based on its time to first token.
+func classifyLatencyProfile(seconds float64, scoring ScoringConfig) string {
+	switch {
+	case seconds < scoring.LowLatencySeconds:
+		return "low"
+	case seconds <= scoring.MediumLatencySeconds:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// classifyStability categorizes a model's performance stability based on its coefficient of variation.
+func classifyStability(stddev, avg float64, scoring ScoringConfig) string {
+	if avg <= 0 {
+		if stddev == 0 {
+			return "stable"
+		}
+		return "unstable"
+	}
+	cv := stddev / avg
+	switch {
+	case cv < scoring.StableCV:
+		return "stable"
+	case cv < scoring.ModerateCV:
+		return "moderate"
+	default:
+		return "unstable"
+	}
+}
+
+// classifyInteractiveSuitability determines a model's suitability for interactive use cases.
+func classifyInteractiveSuitability(ttftSeconds, tokensPerSecond float64, scoring ScoringConfig) string {
+	switch {
+	case ttftSeconds > scoring.UnusableTTFTSeconds:
+		return "unusable"
+	case ttftSeconds > scoring.BorderlineTTFTSeconds:
+		return "borderline"
+	case tokensPerSecond < scoring.MinInteractiveTokensPerSec:
+		return "borderline"
+	default:
+		return "good"
+	}
+}
+
+// buildModelNotes creates a list of human-readable notes for a model based on its performance characteristics.
+func buildModelNotes(model ModelAnalysis) []string {
+	notes := make([]string, 0, 3)
+	if model.Labels.LatencyProfile == "high" {
+		notes = append(notes, "Very high time to first token; most of the time is spent before streaming.")
+	}
+	if model.Labels.RelativeSpeedTier == "top" {
+		notes = append(notes, "This is one of the fastest models by throughput.")
+	}
+	if model.Labels.Stability == "unstable" {
+		notes = append(notes, "Performance is highly variable across runs.")
+	}
+	if cs := model.ColdStart; cs != nil && cs.LoadTimeSeconds > 0 {
+		note := fmt.Sprintf("Cold start: loaded in %.2fs.", cs.LoadTimeSeconds)
+		if cs.FirstTimeToFirstTokenSeconds > 0 {
+			note = fmt.Sprintf("Cold start: loaded in %.2fs; the first iteration took %.2fs to the first token.", cs.LoadTimeSeconds, cs.FirstTimeToFirstTokenSeconds)
+		}
+		if cs.WarmupIterations > 0 {
+			note += fmt.Sprintf(" %d warm-up iterations are left out of the averages.", cs.WarmupIterations)
+		}
+		notes = append(notes, note)
+	}
+	contended := 0
+	for _, it := range model.Iterations {
+		if it.Contended {
+			contended++
+		}
+	}
+	if contended > 0 {
+		notes = append(notes, fmt.Sprintf("%d of %d iterations overlapped others on the host (up to %d at once); their throughput and latency are contended.",
+			contended, len(model.Iterations), model.Concurrency))
+	}
+	notes = append(notes, toolUseNotes(model.ToolUse)...)
+	if judging := model.Judging; judging != nil {
+		notes = append(notes, fmt.Sprintf("Judges disagreed on %d of %d answers (%.0f%%).", judging.Disagreements, judging.Judged, judging.DisagreementRate*100))
+	}
+	return notes
+}
+
+// hasFasterModelWithSimilarOutputs checks if there is a faster model with a similar or smaller output size.
+func hasFasterModelWithSimilarOutputs(target ModelAnalysis, models []ModelAnalysis) bool {
+	if target.Avg.OutputTokens <= 0 {
+		return false
+	}
+	const tolerance = 1.1
+	for _, other := range models {
+		if other.ModelName == target.ModelName {
+			continue
+		}
+		if other.Avg.TokensPerSecond <= target.Avg.TokensPerSecond {
+			continue
+		}
+		if other.Avg.OutputTokens <= 0 {
+			continue
+		}
+		if target.Avg.OutputTokens <= other.Avg.OutputTokens*tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// fallbackAverage returns the primary value if it's positive, otherwise it calculates the mean of the fallback values.
+func fallbackAverage(primary float64, fallback []float64) float64 {
+	if primary > 0 {
+		return primary
+	}
+	return meanFloat64(fallback)
+}
+
+// meanFloat64 calculates the mean of a slice of float64 values.
+func meanFloat64(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// stddevFromValues calculates the standard deviation of a slice of float64 values given their mean.
+func stddevFromValues(values []float64, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		diff := v - mean
+		sum += diff * diff
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// Percentile returns the p-th percentile of values using linear interpolation between closest ranks.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := clampFloat(p, 0, 100) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+// nsToSeconds converts nanoseconds to seconds.
+func nsToSeconds(ns int64) float64 {
+	return float64(ns) / 1e9
+}
+
+// clampFloat restricts a float64 value to a given range.
+func clampFloat(val, min, max float64) float64 {
+	if val < min {
+		return min
+	}
+	if val > max {
+		return max
+	}
+	return val
+}
@@ -0,0 +1,264 @@
+// analysis/decode.go
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BenchmarkFormat names a benchmark JSON layout recognized by DecodeBenchmarkResults.
+type BenchmarkFormat string
+
+const (
+	// FormatBenchmarkResults is the map of model name to ModelBenchmark written by benchmark runs.
+	FormatBenchmarkResults BenchmarkFormat = "benchmark-results"
+	// FormatAggregatorMetrics is the array of ModelMetrics saved by the metrics aggregator.
+	FormatAggregatorMetrics BenchmarkFormat = "aggregator-metrics"
+	// FormatLegacyBenchmark is benchmark output from an earlier layout that was upgraded on load.
+	FormatLegacyBenchmark BenchmarkFormat = "legacy-benchmark"
+)
+
+// ErrLegacyFormat is returned in strict mode for input that needs an upgrade to load.
+var ErrLegacyFormat = errors.New("legacy benchmark format")
+
+// DecodeReport describes how a benchmark document was decoded.
+type DecodeReport struct {
+	Format BenchmarkFormat
+	// Upgrades lists the changes applied to bring a legacy document to the current layout.
+	Upgrades []string
+}
+
+// durationFields are the Stats fields holding nanosecond durations.
+var durationFields = []string{"totalExecutionTime", "timeToFirstToken"}
+
+// statsFields are the ModelBenchmark fields holding a Stats object.
+var statsFields = []string{"averageStats", "minStats", "maxStats"}
+
+// DecodeBenchmarkResults parses benchmark JSON in the current layout, the aggregator's metrics
+// array, or a legacy layout written by earlier releases. Legacy documents are upgraded on a
+// best-effort basis: model lists become a map keyed by model name, snake_case fields are
+// renamed, and duration strings such as "1.5s" become nanoseconds. With strict set, legacy
+// documents are rejected with ErrLegacyFormat instead.
+func DecodeBenchmarkResults(data []byte, strict bool) (BenchmarkResults, DecodeReport, error) {
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, DecodeReport{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if isAggregatorDocument(doc) {
+		var models []ModelMetrics
+		if err := json.Unmarshal(data, &models); err != nil {
+			return nil, DecodeReport{}, fmt.Errorf("unable to decode aggregator metrics: %w", err)
+		}
+		return ResultsFromModelMetrics(models), DecodeReport{Format: FormatAggregatorMetrics}, nil
+	}
+
+	upgrader := &benchmarkUpgrader{seen: make(map[string]bool)}
+	upgraded, err := upgrader.document(doc)
+	if err != nil {
+		return nil, DecodeReport{}, err
+	}
+	report := DecodeReport{Format: FormatBenchmarkResults, Upgrades: upgrader.upgrades}
+	if len(report.Upgrades) > 0 {
+		report.Format = FormatLegacyBenchmark
+		if strict {
+			return nil, report, fmt.Errorf("%w: %s", ErrLegacyFormat, strings.Join(report.Upgrades, "; "))
+		}
+	}
+
+	normalized, err := json.Marshal(upgraded)
+	if err != nil {
+		return nil, report, fmt.Errorf("unable to re-encode upgraded benchmark JSON: %w", err)
+	}
+	var results BenchmarkResults
+	if err := json.Unmarshal(normalized, &results); err != nil {
+		return nil, report, fmt.Errorf("json did not match the benchmark results schema: %w", err)
+	}
+	return results, report, nil
+}
+
+// ResultsFromModelMetrics converts aggregator metrics into benchmark results, using each
+// model's overall mean, minimum and maximum as its average, min and max stats.
+func ResultsFromModelMetrics(models []ModelMetrics) BenchmarkResults {
+	out := make(BenchmarkResults, len(models))
+	for _, m := range models {
+		overall := m.OverallStats
+		out[m.ModelName] = ModelBenchmark{
+			ModelName:      m.ModelName,
+			BenchmarkCount: int(overall.TotalRequests),
+			AverageStats:   statsFromRunning(overall, func(rs RunningStat) float64 { return rs.Mean }),
+			MinStats:       statsFromRunning(overall, func(rs RunningStat) float64 { return rs.Min }),
+			MaxStats:       statsFromRunning(overall, func(rs RunningStat) float64 { return rs.Max }),
+		}
+	}
+	return out
+}
+
+// statsFromRunning builds Stats from one value of each running statistic.
+func statsFromRunning(overall RunningAggregatedStats, value func(RunningStat) float64) Stats {
+	return Stats{
+		TotalExecutionTime: int64(value(overall.TotalDurationMillis) * 1e6),
+		TimeToFirstToken:   int64(value(overall.TTFTMillis) * 1e6),
+		TokensPerSecond:    value(overall.TokensPerSecond),
+		InputTokenCount:    roundCount(value(overall.InputTokens)),
+		OutputTokenCount:   roundCount(value(overall.OutputTokens)),
+	}
+}
+
+// roundCount rounds a mean token count, treating NaN and infinities as zero.
+func roundCount(val float64) int {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0
+	}
+	return int(math.Round(val))
+}
+
+// isAggregatorDocument reports whether doc is the aggregator's array of per-model metrics.
+func isAggregatorDocument(doc any) bool {
+	list, ok := doc.([]any)
+	if !ok || len(list) == 0 {
+		return false
+	}
+	first, ok := list[0].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = first["overall_stats"]
+	return ok
+}
+
+// benchmarkUpgrader rewrites a decoded document into the current layout and records each
+// kind of change it made once.
+type benchmarkUpgrader struct {
+	upgrades []string
+	seen     map[string]bool
+}
+
+// note records an upgrade the first time it is applied.
+func (u *benchmarkUpgrader) note(upgrade string) {
+	if !u.seen[upgrade] {
+		u.seen[upgrade] = true
+		u.upgrades = append(u.upgrades, upgrade)
+	}
+}
+
+// document upgrades the top level of a benchmark document.
+func (u *benchmarkUpgrader) document(doc any) (map[string]any, error) {
+	switch value := doc.(type) {
+	case map[string]any:
+		for name, entry := range value {
+			model, ok := entry.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("json did not match benchmark results schema or aggregator metrics array: entry %q is not an object", name)
+			}
+			value[name] = u.model(name, model)
+		}
+		return value, nil
+	case []any:
+		if len(value) == 0 {
+			return nil, fmt.Errorf("json did not match benchmark results schema or aggregator metrics array: empty array")
+		}
+		u.note("model list converted to a map keyed by model name")
+		results := make(map[string]any, len(value))
+		for i, entry := range value {
+			model, ok := entry.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("benchmark list entry %d is not an object", i)
+			}
+			model = u.model("", model)
+			name, _ := model["modelName"].(string)
+			if name == "" {
+				return nil, fmt.Errorf("benchmark list entry %d has no model name", i)
+			}
+			results[name] = model
+		}
+		return results, nil
+	default:
+		return nil, fmt.Errorf("json did not match benchmark results schema or aggregator metrics array")
+	}
+}
+
+// model upgrades one model's benchmark record. key is its name in the results map, if any.
+func (u *benchmarkUpgrader) model(key string, model map[string]any) map[string]any {
+	model = u.renameKeys(model)
+	if name, _ := model["modelName"].(string); name == "" && key != "" {
+		model["modelName"] = key
+		u.note("missing modelName filled from the results key")
+	}
+	for _, field := range statsFields {
+		if stats, ok := model[field].(map[string]any); ok {
+			model[field] = u.stats(stats)
+		}
+	}
+	if iterations, ok := model["iterations"].([]any); ok {
+		for i, entry := range iterations {
+			iteration, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			iteration = u.renameKeys(iteration)
+			if stats, ok := iteration["stats"].(map[string]any); ok {
+				iteration["stats"] = u.stats(stats)
+			}
+			iterations[i] = iteration
+		}
+	}
+	return model
+}
+
+// stats upgrades a Stats object, converting duration strings to nanoseconds. Strings that
+// do not parse are left for the final decode to reject.
+func (u *benchmarkUpgrader) stats(stats map[string]any) map[string]any {
+	stats = u.renameKeys(stats)
+	for _, field := range durationFields {
+		text, ok := stats[field].(string)
+		if !ok {
+			continue
+		}
+		if d, err := time.ParseDuration(text); err == nil {
+			stats[field] = d.Nanoseconds()
+			u.note("duration strings converted to nanoseconds")
+		}
+	}
+	return stats
+}
+
+// renameKeys renames snake_case keys to the current camelCase names.
+func (u *benchmarkUpgrader) renameKeys(object map[string]any) map[string]any {
+	renamed := make(map[string]any, len(object))
+	for k, v := range object {
+		if strings.Contains(k, "_") {
+			u.note("snake_case field names renamed to camelCase")
+			k = snakeToCamel(k)
+		}
+		renamed[k] = v
+	}
+	return renamed
+}
+
+// snakeToCamel converts a snake_case name such as "time_to_first_token" to "timeToFirstToken".
+func snakeToCamel(name string) string {
+	parts := strings.Split(name, "_")
+	var b strings.Builder
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i == 0 || b.Len() == 0 {
+			b.WriteString(part)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+// analysis/decode_test.go
+package analysis
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDecodeBenchmarkResultsCurrentFormats verifies that current benchmark output and the
+// aggregator's metrics array load without upgrades.
+func TestDecodeBenchmarkResultsCurrentFormats(t *testing.T) {
+	current := []byte(`{"llama3":{"modelName":"llama3","benchmarkCount":2,"averageStats":{"totalExecutionTime":2000000000,"timeToFirstToken":500000000,"tokensPerSecond":42}}}`)
+	results, report, err := DecodeBenchmarkResults(current, true)
+	if err != nil {
+		t.Fatalf("DecodeBenchmarkResults returned error: %v", err)
+	}
+	if report.Format != FormatBenchmarkResults || len(report.Upgrades) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := results["llama3"].AverageStats; got.TimeToFirstToken != 500000000 || got.TokensPerSecond != 42 {
+		t.Fatalf("unexpected stats: %+v", got)
+	}
+
+	aggregator := []byte(`[{"model_name":"qwen3","overall_stats":{"total_requests":3,"ttft_ms":{"mean":250,"min":200,"max":300},"input_tokens":{"mean":10.4}}}]`)
+	results, report, err = DecodeBenchmarkResults(aggregator, true)
+	if err != nil || report.Format != FormatAggregatorMetrics {
+		t.Fatalf("aggregator metrics: %+v, %v", report, err)
+	}
+	if got := results["qwen3"]; got.BenchmarkCount != 3 || got.MaxStats.TimeToFirstToken != 300000000 || got.AverageStats.InputTokenCount != 10 {
+		t.Fatalf("unexpected aggregator conversion: %+v", got)
+	}
+}
+
+// TestDecodeBenchmarkResultsLegacy verifies that a legacy model list with snake_case fields
+// and duration strings is upgraded, and rejected in strict mode.
+func TestDecodeBenchmarkResultsLegacy(t *testing.T) {
+	legacy := []byte(`[{"model_name":"llama3","benchmark_count":1,"average_stats":{"total_execution_time":"1.5s","time_to_first_token":"250ms","tokens_per_second":30},
+		"iterations":[{"iteration":1,"stats":{"time_to_first_token":"250ms","output_token_count":64}}]}]`)
+
+	results, report, err := DecodeBenchmarkResults(legacy, false)
+	if err != nil {
+		t.Fatalf("DecodeBenchmarkResults returned error: %v", err)
+	}
+	if report.Format != FormatLegacyBenchmark || len(report.Upgrades) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	model, ok := results["llama3"]
+	if !ok || model.BenchmarkCount != 1 || model.AverageStats.TotalExecutionTime != 1500000000 {
+		t.Fatalf("unexpected upgrade: %+v", results)
+	}
+	if len(model.Iterations) != 1 || model.Iterations[0].Stats.TimeToFirstToken != 250000000 || model.Iterations[0].Stats.OutputTokenCount != 64 {
+		t.Fatalf("iterations not upgraded: %+v", model.Iterations)
+	}
+
+	if _, _, err := DecodeBenchmarkResults(legacy, true); !errors.Is(err, ErrLegacyFormat) {
+		t.Fatalf("expected ErrLegacyFormat in strict mode, got %v", err)
+	}
+	if _, _, err := DecodeBenchmarkResults([]byte(`"not benchmark output"`), false); err == nil {
+		t.Fatalf("expected an error for a document that is not benchmark output")
+	}
+}
@@ -0,0 +1,198 @@
+// analysis/diff.go
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mwiater/agon/internal/modelname"
+)
+
+// DefaultDiffTolerancePercent is the change in TPS or TTFT tolerated before a model is
+// flagged. Accuracy is held to the same number of percentage points.
+const DefaultDiffTolerancePercent = 5.0
+
+// Model delta statuses.
+const (
+	DeltaChanged = "changed"
+	DeltaAdded   = "added"
+	DeltaRemoved = "removed"
+)
+
+// ModelDelta compares one model between a baseline and a candidate analysis. Percent
+// changes are relative to the baseline; ranks are 1-based efficiency ranks (0 when absent).
+type ModelDelta struct {
+	ModelName             string   `json:"modelName"`
+	Status                string   `json:"status"`
+	BaselineTokensPerSec  float64  `json:"baselineTokensPerSecond"`
+	CandidateTokensPerSec float64  `json:"candidateTokensPerSecond"`
+	TokensPerSecChangePct float64  `json:"tokensPerSecondChangePct"`
+	BaselineTTFTSeconds   float64  `json:"baselineTimeToFirstTokenSeconds"`
+	CandidateTTFTSeconds  float64  `json:"candidateTimeToFirstTokenSeconds"`
+	TTFTChangePct         float64  `json:"timeToFirstTokenChangePct"`
+	BaselineRank          int      `json:"baselineRank"`
+	CandidateRank         int      `json:"candidateRank"`
+	RankMovement          int      `json:"rankMovement"`
+	Regression            bool     `json:"regression"`
+	Improvement           bool     `json:"improvement"`
+	Reasons               []string `json:"reasons,omitempty"`
+	// BaselineAccuracy and CandidateAccuracy are prompt-suite accuracy rates from 0 to 1,
+	// nil when a run scored no answers. AccuracyChangePoints is the change in percentage
+	// points, and AccuracyRegression reports a drop beyond the tolerance.
+	BaselineAccuracy     *float64 `json:"baselineAccuracy,omitempty"`
+	CandidateAccuracy    *float64 `json:"candidateAccuracy,omitempty"`
+	AccuracyChangePoints float64  `json:"accuracyChangePoints"`
+	AccuracyRegression   bool     `json:"accuracyRegression,omitempty"`
+}
+
+// AnalysisDiff is the delta report between two analyses.
+type AnalysisDiff struct {
+	GeneratedAt          time.Time    `json:"generatedAt"`
+	BaselineLabel        string       `json:"baselineLabel"`
+	CandidateLabel       string       `json:"candidateLabel"`
+	BaselineGeneratedAt  time.Time    `json:"baselineGeneratedAt"`
+	CandidateGeneratedAt time.Time    `json:"candidateGeneratedAt"`
+	TolerancePercent     float64      `json:"tolerancePercent"`
+	Models               []ModelDelta `json:"models"`
+	Regressions          int          `json:"regressions"`
+	Improvements         int          `json:"improvements"`
+}
+
+// DiffAnalyses compares a candidate analysis against a baseline. Models are matched by
+// normalized name. A model regresses when its TPS drops, or its TTFT rises, by more than
+// tolerancePercent, or when its accuracy drops by more than tolerancePercent percentage
+// points; the opposite movements beyond the tolerance count as improvements.
+func DiffAnalyses(baseline, candidate Analysis, tolerancePercent float64) AnalysisDiff {
+	if tolerancePercent <= 0 {
+		tolerancePercent = DefaultDiffTolerancePercent
+	}
+	diff := AnalysisDiff{
+		GeneratedAt:          time.Now().UTC(),
+		BaselineGeneratedAt:  baseline.GeneratedAt,
+		CandidateGeneratedAt: candidate.GeneratedAt,
+		TolerancePercent:     tolerancePercent,
+	}
+
+	baselineRanks := efficiencyRanks(baseline)
+	candidateRanks := efficiencyRanks(candidate)
+
+	matched := make(map[string]bool)
+	for _, base := range baseline.Models {
+		key := modelname.Normalize(base.ModelName)
+		delta := ModelDelta{
+			ModelName:            base.ModelName,
+			Status:               DeltaRemoved,
+			BaselineTokensPerSec: base.Avg.TokensPerSecond,
+			BaselineTTFTSeconds:  base.Avg.TimeToFirstTokenSeconds,
+			BaselineRank:         baselineRanks[key],
+			BaselineAccuracy:     accuracyRate(base),
+		}
+		for _, cand := range candidate.Models {
+			if modelname.Normalize(cand.ModelName) != key {
+				continue
+			}
+			matched[key] = true
+			delta.Status = DeltaChanged
+			delta.CandidateTokensPerSec = cand.Avg.TokensPerSecond
+			delta.CandidateTTFTSeconds = cand.Avg.TimeToFirstTokenSeconds
+			delta.CandidateRank = candidateRanks[key]
+			delta.CandidateAccuracy = accuracyRate(cand)
+			if delta.BaselineAccuracy != nil && delta.CandidateAccuracy != nil {
+				delta.AccuracyChangePoints = (*delta.CandidateAccuracy - *delta.BaselineAccuracy) * 100
+			}
+			delta.TokensPerSecChangePct = percentChange(delta.BaselineTokensPerSec, delta.CandidateTokensPerSec)
+			delta.TTFTChangePct = percentChange(delta.BaselineTTFTSeconds, delta.CandidateTTFTSeconds)
+			if delta.BaselineRank > 0 && delta.CandidateRank > 0 {
+				delta.RankMovement = delta.BaselineRank - delta.CandidateRank
+			}
+			classifyDelta(&delta, tolerancePercent)
+			break
+		}
+		diff.Models = append(diff.Models, delta)
+	}
+	for _, cand := range candidate.Models {
+		key := modelname.Normalize(cand.ModelName)
+		if matched[key] {
+			continue
+		}
+		diff.Models = append(diff.Models, ModelDelta{
+			ModelName:             cand.ModelName,
+			Status:                DeltaAdded,
+			CandidateTokensPerSec: cand.Avg.TokensPerSecond,
+			CandidateTTFTSeconds:  cand.Avg.TimeToFirstTokenSeconds,
+			CandidateRank:         candidateRanks[key],
+			CandidateAccuracy:     accuracyRate(cand),
+		})
+	}
+
+	sort.SliceStable(diff.Models, func(i, j int) bool {
+		if diff.Models[i].Regression != diff.Models[j].Regression {
+			return diff.Models[i].Regression
+		}
+		return diff.Models[i].ModelName < diff.Models[j].ModelName
+	})
+	for _, delta := range diff.Models {
+		if delta.Regression {
+			diff.Regressions++
+		}
+		if delta.Improvement {
+			diff.Improvements++
+		}
+	}
+	return diff
+}
+
+// classifyDelta flags regressions and improvements that exceed the tolerance.
+func classifyDelta(delta *ModelDelta, tolerancePercent float64) {
+	if delta.TokensPerSecChangePct < -tolerancePercent {
+		delta.Regression = true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("throughput down %.1f%%", -delta.TokensPerSecChangePct))
+	} else if delta.TokensPerSecChangePct > tolerancePercent {
+		delta.Improvement = true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("throughput up %.1f%%", delta.TokensPerSecChangePct))
+	}
+	if delta.TTFTChangePct > tolerancePercent {
+		delta.Regression = true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("TTFT up %.1f%%", delta.TTFTChangePct))
+	} else if delta.TTFTChangePct < -tolerancePercent {
+		delta.Improvement = true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("TTFT down %.1f%%", -delta.TTFTChangePct))
+	}
+	if delta.AccuracyChangePoints < -tolerancePercent {
+		delta.Regression, delta.AccuracyRegression = true, true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("accuracy down %.1f points", -delta.AccuracyChangePoints))
+	} else if delta.AccuracyChangePoints > tolerancePercent {
+		delta.Improvement = true
+		delta.Reasons = append(delta.Reasons, fmt.Sprintf("accuracy up %.1f points", delta.AccuracyChangePoints))
+	}
+	if delta.Regression {
+		delta.Improvement = false
+	}
+}
+
+// accuracyRate returns a model's prompt-suite accuracy, or nil when it scored no answers.
+func accuracyRate(model ModelAnalysis) *float64 {
+	if model.Accuracy == nil || model.Accuracy.Scored == 0 {
+		return nil
+	}
+	rate := model.Accuracy.Rate
+	return &rate
+}
+
+// efficiencyRanks maps normalized model names to their 1-based efficiency rank.
+func efficiencyRanks(analysis Analysis) map[string]int {
+	ranks := make(map[string]int, len(analysis.Rankings.ByEfficiencyScore))
+	for i, entry := range analysis.Rankings.ByEfficiencyScore {
+		ranks[modelname.Normalize(entry.ModelName)] = i + 1
+	}
+	return ranks
+}
+
+// percentChange returns the change from base to next as a percentage of base.
+func percentChange(base, next float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (next - base) / base * 100
+}
@@ -0,0 +1,16 @@
+// analysis/doc.go
+
+// Package analysis turns agon benchmark results into the Analysis document behind
+// `agon analyze metrics`: per-model aggregates and percentiles, scores and labels,
+// rankings, anomalies and recommendations, plus diffs between two analyses. It has no
+// template or HTML dependencies, so other tools can embed it; agon's reports are rendered
+// from its types by internal/metrics.
+//
+// The JSON layout of Analysis and the types it embeds is versioned by SchemaVersion.
+// Field tags are stable within a major version: minor versions only add fields, and a tag
+// is renamed or removed only in a new major version. ReadAnalysis rejects documents from a
+// newer major version.
+package analysis
+
+// SchemaVersion is the semantic version of the Analysis JSON layout.
+const SchemaVersion = "1.0.0"
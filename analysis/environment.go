@@ -0,0 +1,58 @@
+// analysis/environment.go
+package analysis
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProviderVersion records the server version reported by one configured host.
+type ProviderVersion struct {
+	Host    string `json:"host"`
+	URL     string `json:"url"`
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EnvironmentSnapshot captures the conditions a run was recorded under so anomalous
+// results can be explained after the fact. Fields the platform cannot report are empty.
+type EnvironmentSnapshot struct {
+	CapturedAt  time.Time         `json:"capturedAt"`
+	AgonVersion string            `json:"agonVersion"`
+	GoVersion   string            `json:"goVersion"`
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Hostname    string            `json:"hostname,omitempty"`
+	NumCPU      int               `json:"numCPU"`
+	CPUGovernor string            `json:"cpuGovernor,omitempty"`
+	LoadAverage string            `json:"loadAverage,omitempty"`
+	GPUDriver   string            `json:"gpuDriver,omitempty"`
+	Providers   []ProviderVersion `json:"providers,omitempty"`
+}
+
+// Summary renders the snapshot as short lines for report tooltips.
+func (e EnvironmentSnapshot) Summary() []string {
+	lines := []string{
+		fmt.Sprintf("Captured %s", e.CapturedAt.Format("2006-01-02 15:04:05 MST")),
+		fmt.Sprintf("agon %s (%s, %s/%s, %d CPUs)", e.AgonVersion, e.GoVersion, e.OS, e.Arch, e.NumCPU),
+	}
+	if e.CPUGovernor != "" {
+		lines = append(lines, "CPU governor: "+e.CPUGovernor)
+	}
+	if e.LoadAverage != "" {
+		lines = append(lines, "Load average: "+e.LoadAverage)
+	}
+	if e.GPUDriver != "" {
+		lines = append(lines, "GPU driver: "+e.GPUDriver)
+	}
+	for _, p := range e.Providers {
+		switch {
+		case p.Version != "":
+			lines = append(lines, fmt.Sprintf("%s: %s %s", p.Host, p.Type, p.Version))
+		case p.Error != "":
+			lines = append(lines, fmt.Sprintf("%s: version unavailable (%s)", p.Host, p.Error))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,83 @@
+// analysis/judging.go
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnreliableDisagreementRate is the share of a question's judged answers the two judges
+// must disagree on for its grading to be flagged as unreliable.
+const UnreliableDisagreementRate = 0.25
+
+// JudgingStats measures how often a second judge disagreed with the first, overall and
+// per question. Warm-up iterations are included, since grading does not warm up.
+type JudgingStats struct {
+	// Judged counts the answers both judges graded.
+	Judged           int     `json:"judged"`
+	Disagreements    int     `json:"disagreements"`
+	DisagreementRate float64 `json:"disagreementRate"`
+	// Unreliable lists the questions whose disagreement rate reaches
+	// UnreliableDisagreementRate, the most disputed first.
+	Unreliable []QuestionJudging `json:"unreliable,omitempty"`
+}
+
+// QuestionJudging is the judges' agreement on one question's answers.
+type QuestionJudging struct {
+	QuestionID       string  `json:"questionId"`
+	Judged           int     `json:"judged"`
+	Disagreements    int     `json:"disagreements"`
+	DisagreementRate float64 `json:"disagreementRate"`
+}
+
+// summarizeJudging compares the two judges' verdicts in iterations. It returns nil when no
+// answer was graded by a second judge.
+func summarizeJudging(iterations []Iteration) *JudgingStats {
+	stats := &JudgingStats{}
+	questions := make(map[string]*QuestionJudging)
+	for _, iter := range iterations {
+		if iter.Correct == nil || iter.SecondOpinion == nil {
+			continue
+		}
+		question, ok := questions[iter.QuestionID]
+		if !ok {
+			question = &QuestionJudging{QuestionID: iter.QuestionID}
+			questions[iter.QuestionID] = question
+		}
+		stats.Judged++
+		question.Judged++
+		if *iter.Correct != *iter.SecondOpinion {
+			stats.Disagreements++
+			question.Disagreements++
+		}
+	}
+	if stats.Judged == 0 {
+		return nil
+	}
+
+	stats.DisagreementRate = float64(stats.Disagreements) / float64(stats.Judged)
+	for _, question := range questions {
+		question.DisagreementRate = float64(question.Disagreements) / float64(question.Judged)
+		if question.Disagreements > 0 && question.DisagreementRate >= UnreliableDisagreementRate {
+			stats.Unreliable = append(stats.Unreliable, *question)
+		}
+	}
+	sort.Slice(stats.Unreliable, func(i, j int) bool {
+		a, b := stats.Unreliable[i], stats.Unreliable[j]
+		if a.DisagreementRate != b.DisagreementRate {
+			return a.DisagreementRate > b.DisagreementRate
+		}
+		return a.QuestionID < b.QuestionID
+	})
+	return stats
+}
+
+// unreliableQuestionList names questions with their disagreement rates for messages.
+func unreliableQuestionList(questions []QuestionJudging) string {
+	names := make([]string, len(questions))
+	for i, question := range questions {
+		names[i] = fmt.Sprintf("%s (%d of %d)", question.QuestionID, question.Disagreements, question.Judged)
+	}
+	return strings.Join(names, ", ")
+}
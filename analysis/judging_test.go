@@ -0,0 +1,58 @@
+// analysis/judging_test.go
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSummarizeJudging verifies disagreements between the judges are counted, questions
+// the judges often disagree on are flagged, and runs without a second judge are skipped.
+func TestSummarizeJudging(t *testing.T) {
+	yes, no := true, false
+	iteration := func(question string, correct, second *bool) Iteration {
+		return Iteration{QuestionID: question, Correct: correct, SecondOpinion: second, Stats: Stats{TotalExecutionTime: 1e9, TokensPerSecond: 10}}
+	}
+	results := BenchmarkResults{
+		"llama": {ModelName: "llama", Iterations: []Iteration{
+			iteration("math/sum", &yes, &yes),
+			iteration("math/sum", &no, &no),
+			iteration("math/sum", &yes, &yes),
+			iteration("math/sum", &yes, &no),
+			iteration("essay/poem", &yes, &no),
+			iteration("essay/poem", &no, &yes),
+			iteration("trivia/capital", &yes, nil),
+		}},
+		"qwen": {ModelName: "qwen", Iterations: []Iteration{iteration("math/sum", &yes, nil)}},
+	}
+	analysis := AnalyzeMetrics(results, HostInfo{})
+
+	var llama, qwen ModelAnalysis
+	for _, model := range analysis.Models {
+		if model.ModelName == "llama" {
+			llama = model
+		} else {
+			qwen = model
+		}
+	}
+	if qwen.Judging != nil {
+		t.Fatalf("expected no judging stats without a second judge, got %+v", qwen.Judging)
+	}
+	judging := llama.Judging
+	if judging == nil || judging.Judged != 6 || judging.Disagreements != 3 || judging.DisagreementRate != 0.5 {
+		t.Fatalf("unexpected judging %+v", judging)
+	}
+	if len(judging.Unreliable) != 2 || judging.Unreliable[0].QuestionID != "essay/poem" || judging.Unreliable[1].DisagreementRate != 0.25 {
+		t.Fatalf("unexpected unreliable questions %+v", judging.Unreliable)
+	}
+
+	var flagged bool
+	for _, anomaly := range analysis.Anomalies {
+		if anomaly.Type == "unreliable_grading" && anomaly.ModelName == "llama" && strings.Contains(anomaly.Message, "essay/poem (2 of 2), math/sum (1 of 4)") {
+			flagged = true
+		}
+	}
+	if !flagged {
+		t.Fatalf("expected an unreliable grading anomaly, got %+v", analysis.Anomalies)
+	}
+}
@@ -0,0 +1,78 @@
+// analysis/read.go
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Options configure New. The zero value analyzes with the default scoring profile and
+// upgrades legacy benchmark documents.
+type Options struct {
+	Host HostInfo
+	// Scoring overrides the default scoring profile when set.
+	Scoring *ScoringConfig
+	// Strict rejects legacy benchmark documents with ErrLegacyFormat instead of upgrading them.
+	Strict bool
+	// Acknowledgements demote or suppress known anomalies when set.
+	Acknowledgements *AnomalyAcknowledgements
+	// Environment is recorded on the analysis when set.
+	Environment *EnvironmentSnapshot
+}
+
+// New reads benchmark results from r, in any layout DecodeBenchmarkResults accepts, and
+// analyzes them. The report describes how the results were decoded.
+func New(r io.Reader, opts Options) (Analysis, DecodeReport, error) {
+	results, report, err := ReadBenchmarkResults(r, opts.Strict)
+	if err != nil {
+		return Analysis{}, report, err
+	}
+	scoring := DefaultScoringConfig()
+	if opts.Scoring != nil {
+		scoring = *opts.Scoring
+	}
+	analysis := AnalyzeMetricsWithScoring(results, opts.Host, scoring)
+	if opts.Acknowledgements != nil {
+		analysis.AcknowledgeAnomalies(*opts.Acknowledgements)
+	}
+	if opts.Environment != nil {
+		analysis.AttachEnvironment(*opts.Environment)
+	}
+	return analysis, report, nil
+}
+
+// ReadBenchmarkResults is DecodeBenchmarkResults for a reader.
+func ReadBenchmarkResults(r io.Reader, strict bool) (BenchmarkResults, DecodeReport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, DecodeReport{}, fmt.Errorf("unable to read benchmark results: %w", err)
+	}
+	return DecodeBenchmarkResults(data, strict)
+}
+
+// ReadAnalysis decodes an analysis document written by `agon analyze metrics` or by
+// encoding an Analysis as JSON. Documents from a newer major SchemaVersion are rejected,
+// since their fields may no longer mean what this package expects.
+func ReadAnalysis(r io.Reader) (Analysis, error) {
+	var analysis Analysis
+	if err := json.NewDecoder(r).Decode(&analysis); err != nil {
+		return Analysis{}, err
+	}
+	if analysis.SchemaVersion != "" && schemaMajor(analysis.SchemaVersion) != schemaMajor(SchemaVersion) {
+		return Analysis{}, fmt.Errorf("unsupported analysis schema version %s (want %d.x)", analysis.SchemaVersion, schemaMajor(SchemaVersion))
+	}
+	return analysis, nil
+}
+
+// schemaMajor returns the major component of a semantic version, or -1 when it has none.
+func schemaMajor(version string) int {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,119 @@
+// analysis/read_test.go
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNew verifies that an analysis can be built from a reader with the options applied,
+// and that the result reads back through ReadAnalysis.
+func TestNew(t *testing.T) {
+	results := `{"llama": {"modelName": "llama", "benchmarkCount": 1, "averageStats": {"tokensPerSecond": 12, "timeToFirstToken": 500000000},
+		"iterations": [{"iteration": 1, "stats": {"tokensPerSecond": 12, "timeToFirstToken": 500000000}}]}}`
+	env := EnvironmentSnapshot{AgonVersion: "test", NumCPU: 4}
+	analysis, report, err := New(strings.NewReader(results), Options{Host: HostInfo{ClusterName: "lab"}, Environment: &env})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if report.Format != FormatBenchmarkResults || len(analysis.Models) != 1 || analysis.HostInfo.ClusterName != "lab" {
+		t.Fatalf("unexpected analysis %+v (format %s)", analysis, report.Format)
+	}
+	if analysis.SchemaVersion != SchemaVersion || analysis.Environment == nil || len(analysis.EnvironmentSummary) == 0 {
+		t.Fatalf("expected the schema version and environment to be recorded, got %+v", analysis)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(analysis); err != nil {
+		t.Fatalf("encode analysis: %v", err)
+	}
+	decoded, err := ReadAnalysis(&buf)
+	if err != nil {
+		t.Fatalf("ReadAnalysis returned error: %v", err)
+	}
+	if decoded.Models[0].ModelName != "llama" || decoded.Models[0].Avg.TokensPerSecond != 12 {
+		t.Fatalf("analysis did not round-trip: %+v", decoded.Models)
+	}
+
+	if _, _, err := New(strings.NewReader(`{"models": []}`), Options{Strict: true}); err == nil {
+		t.Fatalf("expected strict mode to reject a legacy document")
+	}
+}
+
+// TestReadAnalysisSchemaVersion verifies that unversioned and same-major documents are
+// read while a newer major version is rejected.
+func TestReadAnalysisSchemaVersion(t *testing.T) {
+	for doc, ok := range map[string]bool{
+		`{"models": []}`: true,
+		`{"schemaVersion": "1.4.0", "models": []}`: true,
+		`{"schemaVersion": "2.0.0", "models": []}`: false,
+		`not json`: false,
+	} {
+		if _, err := ReadAnalysis(strings.NewReader(doc)); (err == nil) != ok {
+			t.Fatalf("ReadAnalysis(%s) error = %v, want ok=%v", doc, err, ok)
+		}
+	}
+}
+
+// TestSchemaFieldNames pins JSON field names of the 1.x schema. Removing or renaming one
+// breaks consumers of the analysis JSON and needs a new major SchemaVersion; adding one
+// needs a new minor version.
+func TestSchemaFieldNames(t *testing.T) {
+	fields := jsonFieldPaths(reflect.TypeOf(Analysis{}), "")
+	for _, want := range []string{
+		"schemaVersion", "generatedAt", "hostInfo.clusterName", "hostInfo.notes",
+		"overall.fastestModel", "overall.bestLatencyModel", "overall.mostEfficientModel",
+		"models[].modelName", "models[].benchmarkCount",
+		"models[].avg.tokensPerSecond", "models[].avg.timeToFirstTokenSeconds", "models[].avg.totalExecutionTimeSeconds",
+		"models[].p95.tokensPerSecond", "models[].scores.efficiencyScore",
+		"models[].labels.relativeSpeedTier", "models[].labels.interactiveSuitability",
+		"models[].iterations[].tokensPerSecond", "models[].iterations[].questionId",
+		"models[].accuracy", "models[].coldStart.loadTimeSeconds", "models[].parameterTemplates[].name",
+		"rankings.byThroughput[].avgTokensPerSecond", "rankings.byLatency[].avgTimeToFirstTokenSeconds",
+		"rankings.byEfficiencyScore[].efficiencyScore",
+		"anomalies[].type", "anomalies[].severity", "anomalies[].message",
+		"recommendations", "scoring", "suppressedAnomalies[].acknowledgement",
+		"environment.agonVersion", "environmentSummary",
+	} {
+		if !fields[want] {
+			t.Fatalf("analysis JSON no longer has %q", want)
+		}
+	}
+}
+
+// jsonFieldPaths collects the JSON paths of t's fields, with [] marking list elements.
+func jsonFieldPaths(t reflect.Type, prefix string) map[string]bool {
+	paths := make(map[string]bool)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		if t.Kind() == reflect.Slice {
+			prefix += "[]"
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return paths
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		paths[path] = true
+		for sub := range jsonFieldPaths(field.Type, path) {
+			paths[sub] = true
+		}
+	}
+	return paths
+}
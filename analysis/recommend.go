@@ -0,0 +1,95 @@
+// analysis/recommend.go
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/mwiater/agon/internal/modelname"
+)
+
+// LoadAnalysis reads an analysis document previously written by `agon analyze metrics`.
+func LoadAnalysis(path string) (Analysis, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Analysis{}, fmt.Errorf("unable to read analysis %s: %w", path, err)
+	}
+	defer file.Close()
+	analysis, err := ReadAnalysis(file)
+	if err != nil {
+		return Analysis{}, fmt.Errorf("unable to parse analysis %s: %w", path, err)
+	}
+	return analysis, nil
+}
+
+// RecommendPipelineModels picks a model for each of stageCount pipeline stages from the
+// candidates that appear in the analysis, skipping models unusable for interactive work.
+// Early stages get the fastest interactive model: the lowest time to first token among
+// models labelled good for interactive use, then the highest throughput. The final stage
+// gets the most accurate model by prompt-suite accuracy; when no candidate has accuracy
+// data, it falls back to the lowest-throughput model as a proxy for the largest, most
+// capable one. Returned names are the candidate spellings, not the analysis spellings.
+func RecommendPipelineModels(analysis Analysis, candidates []string, stageCount int) ([]string, error) {
+	if stageCount <= 0 {
+		return nil, nil
+	}
+
+	type match struct {
+		candidate string
+		model     ModelAnalysis
+	}
+	var matches []match
+	for _, model := range analysis.Models {
+		if model.Labels.InteractiveSuitability == "unusable" {
+			continue
+		}
+		for _, candidate := range candidates {
+			if modelname.Equal(candidate, model.ModelName) {
+				matches = append(matches, match{candidate: candidate, model: model})
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no configured models appear in the analysis")
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i].model, matches[j].model
+		aGood, bGood := a.Labels.InteractiveSuitability == "good", b.Labels.InteractiveSuitability == "good"
+		if aGood != bGood {
+			return aGood
+		}
+		if a.Avg.TimeToFirstTokenSeconds != b.Avg.TimeToFirstTokenSeconds {
+			return a.Avg.TimeToFirstTokenSeconds < b.Avg.TimeToFirstTokenSeconds
+		}
+		return a.Avg.TokensPerSecond > b.Avg.TokensPerSecond
+	})
+	interactive := matches[0].candidate
+
+	var final *match
+	for i, m := range matches {
+		if m.model.Accuracy == nil || m.model.Accuracy.Scored == 0 {
+			continue
+		}
+		if final == nil || m.model.Accuracy.Rate > final.model.Accuracy.Rate {
+			final = &matches[i]
+		}
+	}
+	if final == nil {
+		final = &matches[0]
+		for i, m := range matches[1:] {
+			if m.model.Avg.TokensPerSecond < final.model.Avg.TokensPerSecond {
+				final = &matches[i+1]
+			}
+		}
+	}
+
+	picks := make([]string, stageCount)
+	for i := range picks {
+		picks[i] = interactive
+	}
+	picks[stageCount-1] = final.candidate
+	return picks, nil
+}
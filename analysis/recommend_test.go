@@ -0,0 +1,40 @@
+// analysis/recommend_test.go
+package analysis
+
+import "testing"
+
+// TestRecommendPipelineModels verifies that early stages get the usable model with the
+// lowest time to first token, the final stage gets the most accurate one, or the slowest
+// without accuracy data, and names follow the candidates.
+func TestRecommendPipelineModels(t *testing.T) {
+	analysis := Analysis{Models: []ModelAnalysis{
+		{ModelName: "Fast:1B", Avg: AggregatedStats{TokensPerSecond: 60, TimeToFirstTokenSeconds: 0.2}, Scores: ScoreStats{EfficiencyScore: 80}, Labels: LabelStats{InteractiveSuitability: "good"}},
+		{ModelName: "mid:8b", Avg: AggregatedStats{TokensPerSecond: 30, TimeToFirstTokenSeconds: 0.4}, Scores: ScoreStats{EfficiencyScore: 95}, Labels: LabelStats{InteractiveSuitability: "good"}},
+		{ModelName: "big:14b", Avg: AggregatedStats{TokensPerSecond: 8, TimeToFirstTokenSeconds: 0.1}, Scores: ScoreStats{EfficiencyScore: 30}, Labels: LabelStats{InteractiveSuitability: "borderline"}},
+		{ModelName: "huge:70b", Avg: AggregatedStats{TokensPerSecond: 1}, Scores: ScoreStats{EfficiencyScore: 5}, Labels: LabelStats{InteractiveSuitability: "unusable"}},
+		{ModelName: "unconfigured:3b", Avg: AggregatedStats{TokensPerSecond: 40, TimeToFirstTokenSeconds: 0.05}, Scores: ScoreStats{EfficiencyScore: 99}, Labels: LabelStats{InteractiveSuitability: "good"}},
+	}}
+	candidates := []string{"fast:1b", "mid:8b", "big:14b", "huge:70b"}
+
+	check := func(want []string) {
+		t.Helper()
+		picks, err := RecommendPipelineModels(analysis, candidates, len(want))
+		if err != nil {
+			t.Fatalf("RecommendPipelineModels returned error: %v", err)
+		}
+		for i := range want {
+			if picks[i] != want[i] {
+				t.Fatalf("picks = %v, want %v", picks, want)
+			}
+		}
+	}
+	check([]string{"fast:1b", "fast:1b", "fast:1b", "big:14b"})
+
+	analysis.Models[0].Accuracy = &AccuracyStats{AccuracyCount: AccuracyCount{Scored: 10, Correct: 6}, Rate: 0.6}
+	analysis.Models[1].Accuracy = &AccuracyStats{AccuracyCount: AccuracyCount{Scored: 10, Correct: 9}, Rate: 0.9}
+	check([]string{"fast:1b", "fast:1b", "mid:8b"})
+
+	if _, err := RecommendPipelineModels(analysis, []string{"missing:1b"}, 4); err == nil {
+		t.Fatalf("expected an error when no candidates appear in the analysis")
+	}
+}
@@ -0,0 +1,173 @@
+// analysis/scoring.go
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ScoringConfig holds the weights and cutoffs AnalyzeMetrics uses for scores and labels.
+// Profiles loaded from a file start from the built-in profile named by Base (default
+// "interactive"), so a file only needs the values it changes.
+type ScoringConfig struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	Base string `json:"base,omitempty" yaml:"base,omitempty"`
+
+	// ThroughputWeight and LatencyWeight blend the throughput and latency scores into the
+	// efficiency score; they are normalized by their sum.
+	ThroughputWeight float64 `json:"throughputWeight" yaml:"throughputWeight"`
+	LatencyWeight    float64 `json:"latencyWeight" yaml:"latencyWeight"`
+
+	// TopTierRelative and MidTierRelative are the fractions of the fastest model's
+	// throughput a model needs for the "top" and "mid" speed tiers.
+	TopTierRelative float64 `json:"topTierRelative" yaml:"topTierRelative"`
+	MidTierRelative float64 `json:"midTierRelative" yaml:"midTierRelative"`
+
+	// LowLatencySeconds and MediumLatencySeconds bound the "low" and "medium" latency profiles.
+	LowLatencySeconds    float64 `json:"lowLatencySeconds" yaml:"lowLatencySeconds"`
+	MediumLatencySeconds float64 `json:"mediumLatencySeconds" yaml:"mediumLatencySeconds"`
+
+	// StableCV and ModerateCV bound the throughput coefficient of variation for stability labels.
+	StableCV   float64 `json:"stableCV" yaml:"stableCV"`
+	ModerateCV float64 `json:"moderateCV" yaml:"moderateCV"`
+
+	// BorderlineTTFTSeconds, UnusableTTFTSeconds and MinInteractiveTokensPerSec drive the
+	// interactive suitability label; UnusableTTFTSeconds also triggers the latency anomaly.
+	BorderlineTTFTSeconds      float64 `json:"borderlineTTFTSeconds" yaml:"borderlineTTFTSeconds"`
+	UnusableTTFTSeconds        float64 `json:"unusableTTFTSeconds" yaml:"unusableTTFTSeconds"`
+	MinInteractiveTokensPerSec float64 `json:"minInteractiveTokensPerSecond" yaml:"minInteractiveTokensPerSecond"`
+}
+
+// scoringProfiles are the built-in profiles selectable by name.
+var scoringProfiles = map[string]ScoringConfig{
+	"interactive": {
+		Name:                       "interactive",
+		ThroughputWeight:           0.6,
+		LatencyWeight:              0.4,
+		TopTierRelative:            0.75,
+		MidTierRelative:            0.4,
+		LowLatencySeconds:          10,
+		MediumLatencySeconds:       60,
+		StableCV:                   0.1,
+		ModerateCV:                 0.25,
+		BorderlineTTFTSeconds:      60,
+		UnusableTTFTSeconds:        120,
+		MinInteractiveTokensPerSec: 2,
+	},
+	"batch": {
+		Name:                       "batch",
+		ThroughputWeight:           0.85,
+		LatencyWeight:              0.15,
+		TopTierRelative:            0.75,
+		MidTierRelative:            0.4,
+		LowLatencySeconds:          30,
+		MediumLatencySeconds:       180,
+		StableCV:                   0.15,
+		ModerateCV:                 0.35,
+		BorderlineTTFTSeconds:      180,
+		UnusableTTFTSeconds:        600,
+		MinInteractiveTokensPerSec: 1,
+	},
+}
+
+// DefaultScoringConfig returns the interactive profile, which matches the historical scoring.
+func DefaultScoringConfig() ScoringConfig {
+	return scoringProfiles["interactive"]
+}
+
+// ScoringProfileNames lists the built-in profile names.
+func ScoringProfileNames() []string {
+	names := make([]string, 0, len(scoringProfiles))
+	for name := range scoringProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveScoringConfig returns a built-in profile by name or loads a profile file by path.
+// An empty reference yields the default profile.
+func ResolveScoringConfig(ref string) (ScoringConfig, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return DefaultScoringConfig(), nil
+	}
+	if profile, ok := scoringProfiles[strings.ToLower(ref)]; ok {
+		return profile, nil
+	}
+	if _, err := os.Stat(ref); err != nil && filepath.Ext(ref) == "" {
+		return ScoringConfig{}, fmt.Errorf("unknown scoring profile %q (want one of %s, or a profile file)", ref, strings.Join(ScoringProfileNames(), ", "))
+	}
+	return LoadScoringConfig(ref)
+}
+
+// LoadScoringConfig reads a YAML or JSON scoring profile, applying it on top of its base profile.
+func LoadScoringConfig(path string) (ScoringConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScoringConfig{}, fmt.Errorf("unable to read scoring profile %s: %w", path, err)
+	}
+	unmarshal := yaml.Unmarshal
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		unmarshal = json.Unmarshal
+	}
+
+	var header ScoringConfig
+	if err := unmarshal(data, &header); err != nil {
+		return ScoringConfig{}, fmt.Errorf("unable to parse scoring profile %s: %w", path, err)
+	}
+	base := DefaultScoringConfig()
+	if header.Base != "" {
+		profile, ok := scoringProfiles[strings.ToLower(header.Base)]
+		if !ok {
+			return ScoringConfig{}, fmt.Errorf("scoring profile %s: unknown base %q (want one of %s)", path, header.Base, strings.Join(ScoringProfileNames(), ", "))
+		}
+		base = profile
+	}
+
+	cfg := base
+	if err := unmarshal(data, &cfg); err != nil {
+		return ScoringConfig{}, fmt.Errorf("unable to parse scoring profile %s: %w", path, err)
+	}
+	if header.Name == "" {
+		cfg.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := cfg.Validate(); err != nil {
+		return ScoringConfig{}, fmt.Errorf("scoring profile %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports weights and cutoffs that would produce meaningless scores or labels.
+func (s ScoringConfig) Validate() error {
+	switch {
+	case s.ThroughputWeight < 0 || s.LatencyWeight < 0:
+		return fmt.Errorf("weights must not be negative")
+	case s.ThroughputWeight+s.LatencyWeight == 0:
+		return fmt.Errorf("at least one weight must be positive")
+	case s.MidTierRelative <= 0 || s.TopTierRelative < s.MidTierRelative:
+		return fmt.Errorf("speed tiers need 0 < midTierRelative <= topTierRelative")
+	case s.LowLatencySeconds <= 0 || s.MediumLatencySeconds < s.LowLatencySeconds:
+		return fmt.Errorf("latency cutoffs need 0 < lowLatencySeconds <= mediumLatencySeconds")
+	case s.StableCV <= 0 || s.ModerateCV < s.StableCV:
+		return fmt.Errorf("stability cutoffs need 0 < stableCV <= moderateCV")
+	case s.BorderlineTTFTSeconds <= 0 || s.UnusableTTFTSeconds < s.BorderlineTTFTSeconds:
+		return fmt.Errorf("interactive cutoffs need 0 < borderlineTTFTSeconds <= unusableTTFTSeconds")
+	}
+	return nil
+}
+
+// efficiencyScore blends throughput and latency scores with the normalized weights.
+func (s ScoringConfig) efficiencyScore(throughput, latency float64) float64 {
+	total := s.ThroughputWeight + s.LatencyWeight
+	if total <= 0 {
+		return 0
+	}
+	return (s.ThroughputWeight*throughput + s.LatencyWeight*latency) / total
+}
@@ -0,0 +1,65 @@
+// analysis/scoring_test.go
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestScoringProfiles verifies that a YAML profile overrides its base profile field by
+// field and that the weights and cutoffs change the efficiency score and labels.
+func TestScoringProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "throughput-only.yaml")
+	profile := "base: batch\nthroughputWeight: 1\nlatencyWeight: 0\n"
+	if err := os.WriteFile(path, []byte(profile), 0o644); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+
+	scoring, err := ResolveScoringConfig(path)
+	if err != nil {
+		t.Fatalf("ResolveScoringConfig returned error: %v", err)
+	}
+	if scoring.Name != "throughput-only" || scoring.LatencyWeight != 0 || scoring.UnusableTTFTSeconds != 600 {
+		t.Fatalf("profile not applied over batch base: %+v", scoring)
+	}
+
+	results := BenchmarkResults{
+		"fast": {ModelName: "fast", AverageStats: Stats{TokensPerSecond: 100, TimeToFirstToken: 200e9}},
+		"slow": {ModelName: "slow", AverageStats: Stats{TokensPerSecond: 50, TimeToFirstToken: 1e9}},
+	}
+
+	defaults := AnalyzeMetrics(results, HostInfo{})
+	tuned := AnalyzeMetricsWithScoring(results, HostInfo{}, scoring)
+	if defaults.Rankings.ByEfficiencyScore[0].ModelName != "slow" {
+		t.Fatalf("default weights should favour the low-latency model, got %+v", defaults.Rankings.ByEfficiencyScore)
+	}
+	if tuned.Rankings.ByEfficiencyScore[0].ModelName != "fast" || tuned.Rankings.ByEfficiencyScore[0].EfficiencyScore != 100 {
+		t.Fatalf("throughput-only weights should rank fast first at 100, got %+v", tuned.Rankings.ByEfficiencyScore)
+	}
+	if defaults.Models[0].Labels.InteractiveSuitability != "unusable" || tuned.Models[0].Labels.InteractiveSuitability != "borderline" {
+		t.Fatalf("interactive cutoffs not applied: default=%q tuned=%q",
+			defaults.Models[0].Labels.InteractiveSuitability, tuned.Models[0].Labels.InteractiveSuitability)
+	}
+	if tuned.Scoring == nil || tuned.Scoring.Name != "throughput-only" {
+		t.Fatalf("analysis should record the scoring profile, got %+v", tuned.Scoring)
+	}
+}
+
+// TestScoringConfigValidate verifies that inconsistent cutoffs and unknown bases are rejected.
+func TestScoringConfigValidate(t *testing.T) {
+	bad := DefaultScoringConfig()
+	bad.MidTierRelative = 0.9
+	if err := bad.Validate(); err == nil {
+		t.Fatalf("expected mid tier above top tier to be rejected")
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte(`{"base":"nightly"}`), 0o644); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+	if _, err := LoadScoringConfig(path); err == nil {
+		t.Fatalf("expected unknown base profile to be rejected")
+	}
+}
@@ -0,0 +1,107 @@
+// analysis/templates.go
+package analysis
+
+// ParameterTemplateStats summarizes a model's iterations under one parameter template.
+// The deltas compare it with the model's first template, the baseline.
+type ParameterTemplateStats struct {
+	Name       string `json:"name"`
+	Iterations int    `json:"iterations"`
+	Scored     int    `json:"scored"`
+	Correct    int    `json:"correct"`
+	// Accuracy is nil when none of the template's answers were scored.
+	Accuracy                       *float64 `json:"accuracy,omitempty"`
+	TimeToFirstTokenSeconds        float64  `json:"timeToFirstTokenSeconds"`
+	TotalExecutionTimeSeconds      float64  `json:"totalExecutionTimeSeconds"`
+	TokensPerSecond                float64  `json:"tokensPerSecond"`
+	AccuracyDelta                  *float64 `json:"accuracyDelta,omitempty"`
+	TimeToFirstTokenDeltaSeconds   float64  `json:"timeToFirstTokenDeltaSeconds"`
+	TotalExecutionTimeDeltaSeconds float64  `json:"totalExecutionTimeDeltaSeconds"`
+	// Winner marks the model's best template: the most accurate, then the fastest.
+	Winner bool `json:"winner,omitempty"`
+}
+
+// compareParameterTemplates groups iterations by parameter template, in the order the
+// templates were first asked, leaving out warm-up iterations when steady ones exist. It
+// returns nil unless at least two templates were used.
+func compareParameterTemplates(iterations []Iteration) []ParameterTemplateStats {
+	steadyOnly := false
+	for _, iter := range iterations {
+		if !iter.Warmup {
+			steadyOnly = true
+			break
+		}
+	}
+
+	index := make(map[string]int)
+	var stats []ParameterTemplateStats
+	for _, iter := range iterations {
+		if iter.ParameterTemplate == "" || (steadyOnly && iter.Warmup) {
+			continue
+		}
+		i, ok := index[iter.ParameterTemplate]
+		if !ok {
+			i = len(stats)
+			index[iter.ParameterTemplate] = i
+			stats = append(stats, ParameterTemplateStats{Name: iter.ParameterTemplate})
+		}
+		s := &stats[i]
+		s.Iterations++
+		s.TimeToFirstTokenSeconds += nsToSeconds(iter.Stats.TimeToFirstToken)
+		s.TotalExecutionTimeSeconds += nsToSeconds(iter.Stats.TotalExecutionTime)
+		s.TokensPerSecond += iter.Stats.TokensPerSecond
+		if iter.Correct != nil {
+			s.Scored++
+			if *iter.Correct {
+				s.Correct++
+			}
+		}
+	}
+	if len(stats) < 2 {
+		return nil
+	}
+
+	for i := range stats {
+		s := &stats[i]
+		count := float64(s.Iterations)
+		s.TimeToFirstTokenSeconds /= count
+		s.TotalExecutionTimeSeconds /= count
+		s.TokensPerSecond /= count
+		if s.Scored > 0 {
+			accuracy := float64(s.Correct) / float64(s.Scored)
+			s.Accuracy = &accuracy
+		}
+	}
+
+	baseline := stats[0]
+	winner := 0
+	for i := range stats {
+		s := &stats[i]
+		if s.Accuracy != nil && baseline.Accuracy != nil {
+			delta := *s.Accuracy - *baseline.Accuracy
+			s.AccuracyDelta = &delta
+		}
+		s.TimeToFirstTokenDeltaSeconds = s.TimeToFirstTokenSeconds - baseline.TimeToFirstTokenSeconds
+		s.TotalExecutionTimeDeltaSeconds = s.TotalExecutionTimeSeconds - baseline.TotalExecutionTimeSeconds
+		if betterTemplate(*s, stats[winner]) {
+			winner = i
+		}
+	}
+	stats[winner].Winner = true
+	return stats
+}
+
+// betterTemplate reports whether a beats b: higher accuracy first, with unscored templates
+// last, then lower average total time.
+func betterTemplate(a, b ParameterTemplateStats) bool {
+	accuracyA, accuracyB := -1.0, -1.0
+	if a.Accuracy != nil {
+		accuracyA = *a.Accuracy
+	}
+	if b.Accuracy != nil {
+		accuracyB = *b.Accuracy
+	}
+	if accuracyA != accuracyB {
+		return accuracyA > accuracyB
+	}
+	return a.TotalExecutionTimeSeconds < b.TotalExecutionTimeSeconds
+}
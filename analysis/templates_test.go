@@ -0,0 +1,50 @@
+// analysis/templates_test.go
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCompareParameterTemplates verifies iterations are grouped by template with deltas
+// against the first one, and that the most accurate template wins.
+func TestCompareParameterTemplates(t *testing.T) {
+	yes, no := true, false
+	iteration := func(template string, seconds float64, correct *bool) Iteration {
+		return Iteration{ParameterTemplate: template, Correct: correct, Stats: Stats{TotalExecutionTime: int64(seconds * 1e9), TokensPerSecond: 10 / seconds}}
+	}
+	results := BenchmarkResults{
+		"llama": {ModelName: "llama", Iterations: []Iteration{
+			iteration("greedy", 1, &no),
+			iteration("creative", 3, &yes),
+			iteration("greedy", 1, &yes),
+			iteration("creative", 3, &yes),
+		}},
+		"qwen": {ModelName: "qwen", Iterations: []Iteration{iteration("", 2, &yes)}},
+	}
+	analysis := AnalyzeMetrics(results, HostInfo{})
+
+	var llama, qwen ModelAnalysis
+	for _, model := range analysis.Models {
+		if model.ModelName == "llama" {
+			llama = model
+		} else {
+			qwen = model
+		}
+	}
+	if qwen.ParameterTemplates != nil || len(llama.ParameterTemplates) != 2 {
+		t.Fatalf("unexpected template comparisons: %+v / %+v", llama.ParameterTemplates, qwen.ParameterTemplates)
+	}
+	greedy, creative := llama.ParameterTemplates[0], llama.ParameterTemplates[1]
+	if greedy.Name != "greedy" || greedy.Winner || *greedy.Accuracy != 0.5 || *greedy.AccuracyDelta != 0 {
+		t.Fatalf("unexpected baseline template: %+v", greedy)
+	}
+	if !creative.Winner || *creative.AccuracyDelta != 0.5 || math.Abs(creative.TotalExecutionTimeDeltaSeconds-2) > 1e-9 {
+		t.Fatalf("expected the more accurate template to win with deltas, got %+v", creative)
+	}
+
+	tied := compareParameterTemplates([]Iteration{iteration("slow", 4, nil), iteration("fast", 2, nil)})
+	if tied[0].Winner || !tied[1].Winner || tied[1].Accuracy != nil {
+		t.Fatalf("expected the faster unscored template to win, got %+v", tied)
+	}
+}
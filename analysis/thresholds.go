@@ -0,0 +1,158 @@
+// analysis/thresholds.go
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+// ThresholdLimits holds the optional limits evaluated for a model. Nil fields are not checked.
+type ThresholdLimits struct {
+	MinTokensPerSecond         *float64 `json:"minTokensPerSecond,omitempty"`
+	MaxTimeToFirstTokenSeconds *float64 `json:"maxTimeToFirstTokenSeconds,omitempty"`
+	MaxP95TTFTSeconds          *float64 `json:"maxP95TimeToFirstTokenSeconds,omitempty"`
+	// MinAccuracy is the lowest prompt-suite accuracy allowed, from 0 to 1. Models that
+	// scored no answers are not checked.
+	MinAccuracy *float64 `json:"minAccuracy,omitempty"`
+}
+
+// ThresholdRule applies limits to every model whose name matches Pattern (path.Match syntax).
+type ThresholdRule struct {
+	Pattern string `json:"pattern"`
+	ThresholdLimits
+}
+
+// Thresholds is the root document for alerting thresholds.
+type Thresholds struct {
+	Defaults ThresholdLimits `json:"defaults"`
+	Models   []ThresholdRule `json:"models"`
+}
+
+// ThresholdViolation describes a single limit breached by a model.
+type ThresholdViolation struct {
+	ModelName string  `json:"modelName"`
+	Metric    string  `json:"metric"`
+	Limit     float64 `json:"limit"`
+	Actual    float64 `json:"actual"`
+	Message   string  `json:"message"`
+}
+
+// LoadThresholds reads a thresholds JSON document from disk.
+func LoadThresholds(filePath string) (Thresholds, error) {
+	var thresholds Thresholds
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return thresholds, fmt.Errorf("unable to read thresholds file %s: %w", filePath, err)
+	}
+	if err := json.Unmarshal(data, &thresholds); err != nil {
+		return thresholds, fmt.Errorf("unable to parse thresholds file %s: %w", filePath, err)
+	}
+	if err := thresholds.Defaults.validate(); err != nil {
+		return thresholds, fmt.Errorf("invalid defaults in %s: %w", filePath, err)
+	}
+	for _, rule := range thresholds.Models {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return thresholds, fmt.Errorf("invalid model pattern %q in %s: %w", rule.Pattern, filePath, err)
+		}
+		if err := rule.validate(); err != nil {
+			return thresholds, fmt.Errorf("invalid limits for %q in %s: %w", rule.Pattern, filePath, err)
+		}
+	}
+	return thresholds, nil
+}
+
+// validate checks that the accuracy limit is a rate rather than a percentage.
+func (l ThresholdLimits) validate() error {
+	if l.MinAccuracy != nil && (*l.MinAccuracy < 0 || *l.MinAccuracy > 1) {
+		return fmt.Errorf("minAccuracy %g must be between 0 and 1", *l.MinAccuracy)
+	}
+	return nil
+}
+
+// LimitsFor resolves the effective limits for a model. Matching rules are applied in order
+// on top of the defaults, so later rules override earlier ones field by field.
+func (t Thresholds) LimitsFor(modelName string) ThresholdLimits {
+	limits := t.Defaults
+	for _, rule := range t.Models {
+		if ok, _ := path.Match(rule.Pattern, modelName); !ok {
+			continue
+		}
+		if rule.MinTokensPerSecond != nil {
+			limits.MinTokensPerSecond = rule.MinTokensPerSecond
+		}
+		if rule.MaxTimeToFirstTokenSeconds != nil {
+			limits.MaxTimeToFirstTokenSeconds = rule.MaxTimeToFirstTokenSeconds
+		}
+		if rule.MaxP95TTFTSeconds != nil {
+			limits.MaxP95TTFTSeconds = rule.MaxP95TTFTSeconds
+		}
+		if rule.MinAccuracy != nil {
+			limits.MinAccuracy = rule.MinAccuracy
+		}
+	}
+	return limits
+}
+
+// CheckThresholds evaluates every model in the analysis against the thresholds and
+// returns the violations ordered by model name.
+func CheckThresholds(analysis Analysis, thresholds Thresholds) []ThresholdViolation {
+	violations := make([]ThresholdViolation, 0)
+	for _, model := range analysis.Models {
+		limits := thresholds.LimitsFor(model.ModelName)
+
+		if limits.MinTokensPerSecond != nil && model.Avg.TokensPerSecond < *limits.MinTokensPerSecond {
+			violations = append(violations, ThresholdViolation{
+				ModelName: model.ModelName,
+				Metric:    "tokensPerSecond",
+				Limit:     *limits.MinTokensPerSecond,
+				Actual:    model.Avg.TokensPerSecond,
+				Message:   fmt.Sprintf("%s averages %.2f tokens/sec, below the minimum of %.2f.", model.ModelName, model.Avg.TokensPerSecond, *limits.MinTokensPerSecond),
+			})
+		}
+
+		if limits.MaxTimeToFirstTokenSeconds != nil && model.Avg.TimeToFirstTokenSeconds > *limits.MaxTimeToFirstTokenSeconds {
+			violations = append(violations, ThresholdViolation{
+				ModelName: model.ModelName,
+				Metric:    "timeToFirstTokenSeconds",
+				Limit:     *limits.MaxTimeToFirstTokenSeconds,
+				Actual:    model.Avg.TimeToFirstTokenSeconds,
+				Message:   fmt.Sprintf("%s averages %.2fs to first token, above the maximum of %.2fs.", model.ModelName, model.Avg.TimeToFirstTokenSeconds, *limits.MaxTimeToFirstTokenSeconds),
+			})
+		}
+
+		if limits.MaxP95TTFTSeconds != nil {
+			// Aggregated inputs carry no iterations, so the observed maximum stands in for P95.
+			p95 := model.P95.TimeToFirstTokenSeconds
+			if p95 == 0 {
+				p95 = model.Max.TimeToFirstTokenSeconds
+			}
+			if p95 > *limits.MaxP95TTFTSeconds {
+				violations = append(violations, ThresholdViolation{
+					ModelName: model.ModelName,
+					Metric:    "p95TimeToFirstTokenSeconds",
+					Limit:     *limits.MaxP95TTFTSeconds,
+					Actual:    p95,
+					Message:   fmt.Sprintf("%s has a P95 time to first token of %.2fs, above the maximum of %.2fs.", model.ModelName, p95, *limits.MaxP95TTFTSeconds),
+				})
+			}
+		}
+
+		if limits.MinAccuracy != nil && model.Accuracy != nil && model.Accuracy.Scored > 0 && model.Accuracy.Rate < *limits.MinAccuracy {
+			violations = append(violations, ThresholdViolation{
+				ModelName: model.ModelName,
+				Metric:    "accuracy",
+				Limit:     *limits.MinAccuracy,
+				Actual:    model.Accuracy.Rate,
+				Message:   fmt.Sprintf("%s answered %.1f%% of scored questions correctly, below the minimum of %.1f%%.", model.ModelName, model.Accuracy.Rate*100, *limits.MinAccuracy*100),
+			})
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].ModelName < violations[j].ModelName
+	})
+	return violations
+}
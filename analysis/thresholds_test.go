@@ -0,0 +1,72 @@
+// analysis/thresholds_test.go
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckThresholds verifies that default limits apply to every model, that matching
+// pattern rules override them, and that the P95 limit falls back to the maximum TTFT
+// when no iterations were recorded.
+func TestCheckThresholds(t *testing.T) {
+	minTPS := 10.0
+	strictTPS := 50.0
+	maxP95 := 5.0
+
+	thresholds := Thresholds{
+		Defaults: ThresholdLimits{MinTokensPerSecond: &minTPS, MaxP95TTFTSeconds: &maxP95},
+		Models:   []ThresholdRule{{Pattern: "fast:*", ThresholdLimits: ThresholdLimits{MinTokensPerSecond: &strictTPS}}},
+	}
+
+	analysis := Analysis{Models: []ModelAnalysis{
+		{ModelName: "fast:1b", Avg: AggregatedStats{TokensPerSecond: 40}},
+		{ModelName: "slow:7b", Avg: AggregatedStats{TokensPerSecond: 12}, Max: AggregatedStats{TimeToFirstTokenSeconds: 9}},
+		{ModelName: "steady:3b", Avg: AggregatedStats{TokensPerSecond: 20}, P95: AggregatedStats{TimeToFirstTokenSeconds: 2}, Max: AggregatedStats{TimeToFirstTokenSeconds: 9}},
+	}}
+
+	violations := CheckThresholds(analysis, thresholds)
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %d: %+v", len(violations), violations)
+	}
+	if violations[0].ModelName != "fast:1b" || violations[0].Metric != "tokensPerSecond" || violations[0].Limit != strictTPS {
+		t.Fatalf("unexpected first violation: %+v", violations[0])
+	}
+	if violations[1].ModelName != "slow:7b" || violations[1].Metric != "p95TimeToFirstTokenSeconds" || violations[1].Actual != 9 {
+		t.Fatalf("unexpected second violation: %+v", violations[1])
+	}
+}
+
+// TestCheckThresholdsAccuracy verifies that a model below the minimum accuracy is flagged,
+// that rules override the default, and that models without scored answers are skipped.
+func TestCheckThresholdsAccuracy(t *testing.T) {
+	minAccuracy, lenient := 0.8, 0.5
+	thresholds := Thresholds{
+		Defaults: ThresholdLimits{MinAccuracy: &minAccuracy},
+		Models:   []ThresholdRule{{Pattern: "tiny:*", ThresholdLimits: ThresholdLimits{MinAccuracy: &lenient}}},
+	}
+	accuracy := func(rate float64) *AccuracyStats {
+		return &AccuracyStats{AccuracyCount: AccuracyCount{Scored: 10, Correct: int(rate * 10)}, Rate: rate}
+	}
+	analysis := Analysis{Models: []ModelAnalysis{
+		{ModelName: "big:70b", Accuracy: accuracy(0.9)},
+		{ModelName: "mid:8b", Accuracy: accuracy(0.7)},
+		{ModelName: "tiny:1b", Accuracy: accuracy(0.6)},
+		{ModelName: "unscored:3b"},
+	}}
+
+	violations := CheckThresholds(analysis, thresholds)
+	if len(violations) != 1 || violations[0].ModelName != "mid:8b" || violations[0].Metric != "accuracy" || violations[0].Actual != 0.7 {
+		t.Fatalf("unexpected violations: %+v", violations)
+	}
+
+	path := filepath.Join(t.TempDir(), "thresholds.json")
+	if err := os.WriteFile(path, []byte(`{"defaults": {"minAccuracy": 80}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadThresholds(path); err == nil || !strings.Contains(err.Error(), "between 0 and 1") {
+		t.Fatalf("expected an error for a percentage accuracy limit, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// analysis/tools.go
+package analysis
+
+import "fmt"
+
+// ToolUseStats summarizes how a model used MCP tools in a run with tools, leaving out
+// warm-up iterations when steady ones exist.
+type ToolUseStats struct {
+	Iterations int `json:"iterations"`
+	// Invoked counts the answers for which the model called at least one tool.
+	Invoked        int     `json:"invoked"`
+	InvocationRate float64 `json:"invocationRate"`
+	// Calls counts the calls of each tool.
+	Calls map[string]int `json:"calls,omitempty"`
+	// Needed counts answers to questions the suite marks as needing a tool, and Missed
+	// those of them given without one. Unneeded counts answers to questions marked as not
+	// needing one, and Unnecessary those of them that called a tool anyway.
+	Needed      int `json:"needed"`
+	Missed      int `json:"missed"`
+	Unneeded    int `json:"unneeded"`
+	Unnecessary int `json:"unnecessary"`
+	// WithTool and WithoutTool compare the answers that called a tool with the rest.
+	WithTool    ToolUseGroup `json:"withTool"`
+	WithoutTool ToolUseGroup `json:"withoutTool"`
+}
+
+// ToolUseGroup is the accuracy and latency of a group of answers.
+type ToolUseGroup struct {
+	Iterations int `json:"iterations"`
+	Scored     int `json:"scored"`
+	Correct    int `json:"correct"`
+	// Accuracy is nil when none of the group's answers were scored.
+	Accuracy                  *float64 `json:"accuracy,omitempty"`
+	TimeToFirstTokenSeconds   float64  `json:"timeToFirstTokenSeconds"`
+	TotalExecutionTimeSeconds float64  `json:"totalExecutionTimeSeconds"`
+}
+
+// summarizeToolUse tallies the tools called in iterations. It returns nil when there are
+// no iterations.
+func summarizeToolUse(iterations []Iteration) *ToolUseStats {
+	steadyOnly := false
+	for _, iter := range iterations {
+		if !iter.Warmup {
+			steadyOnly = true
+			break
+		}
+	}
+
+	stats := &ToolUseStats{}
+	for _, iter := range iterations {
+		if steadyOnly && iter.Warmup {
+			continue
+		}
+		stats.Iterations++
+		invoked := len(iter.ToolCalls) > 0
+		group := &stats.WithoutTool
+		if invoked {
+			stats.Invoked++
+			group = &stats.WithTool
+		}
+		for _, tool := range iter.ToolCalls {
+			if stats.Calls == nil {
+				stats.Calls = make(map[string]int)
+			}
+			stats.Calls[tool]++
+		}
+		if iter.ToolNeeded != nil {
+			switch {
+			case *iter.ToolNeeded:
+				stats.Needed++
+				if !invoked {
+					stats.Missed++
+				}
+			default:
+				stats.Unneeded++
+				if invoked {
+					stats.Unnecessary++
+				}
+			}
+		}
+		group.Iterations++
+		group.TimeToFirstTokenSeconds += nsToSeconds(iter.Stats.TimeToFirstToken)
+		group.TotalExecutionTimeSeconds += nsToSeconds(iter.Stats.TotalExecutionTime)
+		if iter.Correct != nil {
+			group.Scored++
+			if *iter.Correct {
+				group.Correct++
+			}
+		}
+	}
+	if stats.Iterations == 0 {
+		return nil
+	}
+
+	stats.InvocationRate = float64(stats.Invoked) / float64(stats.Iterations)
+	for _, group := range []*ToolUseGroup{&stats.WithTool, &stats.WithoutTool} {
+		if group.Iterations > 0 {
+			group.TimeToFirstTokenSeconds /= float64(group.Iterations)
+			group.TotalExecutionTimeSeconds /= float64(group.Iterations)
+		}
+		if group.Scored > 0 {
+			accuracy := float64(group.Correct) / float64(group.Scored)
+			group.Accuracy = &accuracy
+		}
+	}
+	return stats
+}
+
+// toolUseNotes describes a model's tool use for its notes.
+func toolUseNotes(use *ToolUseStats) []string {
+	if use == nil {
+		return nil
+	}
+	notes := []string{fmt.Sprintf("Called tools for %d of %d answers (%.0f%%).", use.Invoked, use.Iterations, use.InvocationRate*100)}
+	if use.Missed > 0 {
+		notes = append(notes, fmt.Sprintf("Answered %d of %d questions that need a tool without calling one.", use.Missed, use.Needed))
+	}
+	if use.Unnecessary > 0 {
+		notes = append(notes, fmt.Sprintf("Called tools for %d of %d questions that need none.", use.Unnecessary, use.Unneeded))
+	}
+	return notes
+}
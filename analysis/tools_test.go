@@ -0,0 +1,54 @@
+// analysis/tools_test.go
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSummarizeToolUse verifies tool calls are tallied, needed tools that were skipped and
+// unneeded calls are counted, and answers with and without tools are compared.
+func TestSummarizeToolUse(t *testing.T) {
+	yes, no := true, false
+	iteration := func(seconds float64, correct, needed *bool, tools ...string) Iteration {
+		return Iteration{Correct: correct, ToolNeeded: needed, ToolCalls: tools, Stats: Stats{TotalExecutionTime: int64(seconds * 1e9), TokensPerSecond: 10}}
+	}
+	results := BenchmarkResults{
+		"llama": {ModelName: "llama", Tools: true, Iterations: []Iteration{
+			iteration(4, &yes, &yes, "weather", "weather"),
+			iteration(1, &no, &yes),
+			iteration(3, &yes, &no, "calculator"),
+			iteration(1, &yes, &no),
+		}},
+		"qwen": {ModelName: "qwen", Iterations: []Iteration{iteration(1, &yes, nil)}},
+	}
+	analysis := AnalyzeMetrics(results, HostInfo{})
+
+	var llama, qwen ModelAnalysis
+	for _, model := range analysis.Models {
+		if model.ModelName == "llama" {
+			llama = model
+		} else {
+			qwen = model
+		}
+	}
+	if qwen.ToolUse != nil {
+		t.Fatalf("expected no tool use for a run without tools, got %+v", qwen.ToolUse)
+	}
+	use := llama.ToolUse
+	if use == nil || use.Iterations != 4 || use.Invoked != 2 || use.InvocationRate != 0.5 {
+		t.Fatalf("unexpected tool use %+v", use)
+	}
+	if use.Calls["weather"] != 2 || use.Calls["calculator"] != 1 {
+		t.Fatalf("unexpected calls %v", use.Calls)
+	}
+	if use.Needed != 2 || use.Missed != 1 || use.Unneeded != 2 || use.Unnecessary != 1 {
+		t.Fatalf("unexpected needed counts %+v", use)
+	}
+	if *use.WithTool.Accuracy != 1 || use.WithTool.TotalExecutionTimeSeconds != 3.5 || *use.WithoutTool.Accuracy != 0.5 || use.WithoutTool.TotalExecutionTimeSeconds != 1 {
+		t.Fatalf("unexpected comparison %+v / %+v", use.WithTool, use.WithoutTool)
+	}
+	if !strings.Contains(strings.Join(llama.Notes, " "), "Answered 1 of 2 questions that need a tool without calling one.") {
+		t.Fatalf("expected a note about the missed tool, got %v", llama.Notes)
+	}
+}
@@ -1,5 +1,5 @@
-// internal/metrics/types.go
-package metrics
+// analysis/types.go
+package analysis
 
 import "time"
 
@@ -9,6 +9,7 @@ type ModelMetrics struct {
 	LastUpdatedUTC     time.Time              `json:"last_updated_utc"`
 	OverallStats       RunningAggregatedStats `json:"overall_stats"`
 	PerformanceBuckets []PerformanceBucket    `json:"performance_buckets"`
+	ErrorCounts        map[string]int64       `json:"error_counts,omitempty"`
 }
 
 // PerformanceBucket holds aggregated stats for a specific dimension, like input token count.
@@ -28,6 +29,9 @@ type RunningAggregatedStats struct {
 	InputTokens         RunningStat `json:"input_tokens"`
 	OutputTokens        RunningStat `json:"output_tokens"`
 	TotalDurationMillis RunningStat `json:"total_duration_ms"`
+	// QueueWaitMillis is time spent waiting for a free slot on the host, kept apart from
+	// TTFT and total duration so queueing is not mistaken for slow inference.
+	QueueWaitMillis RunningStat `json:"queue_wait_ms"`
 }
 
 // RunningStat holds the necessary values for online calculation of mean, variance, and stddev.
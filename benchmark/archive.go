@@ -0,0 +1,142 @@
+// benchmark/archive.go
+package benchmark
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/modelname"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// rawRecord is the archived evidence for one benchmark iteration: every request sent to
+// the provider and the response streamed back, in order. Records are written when
+// rawArchive is set, and the iteration's result links to its RecordID.
+type rawRecord struct {
+	RecordID     string        `json:"recordId"`
+	Model        string        `json:"model"`
+	Host         string        `json:"host"`
+	Iteration    int           `json:"iteration"`
+	QuestionID   string        `json:"questionId,omitempty"`
+	SystemPrompt string        `json:"systemPrompt,omitempty"`
+	Exchanges    []rawExchange `json:"exchanges"`
+}
+
+// rawExchange is one streamed request and its response. Offsets are measured from
+// StartedAt; Error is set when the stream failed.
+type rawExchange struct {
+	StartedAt time.Time                 `json:"startedAt"`
+	Request   rawRequest                `json:"request"`
+	Chunks    []rawChunk                `json:"chunks"`
+	Metadata  *providers.StreamMetadata `json:"metadata,omitempty"`
+	Duration  time.Duration             `json:"duration"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// rawRequest is the archived form of a stream request. The host is reduced to its name,
+// URL and type so headers and proxy credentials never reach the archive.
+type rawRequest struct {
+	HostName     string                  `json:"hostName"`
+	HostURL      string                  `json:"hostUrl"`
+	HostType     string                  `json:"hostType"`
+	Model        string                  `json:"model"`
+	SystemPrompt string                  `json:"systemPrompt,omitempty"`
+	History      []providers.ChatMessage `json:"history"`
+	Parameters   appconfig.Parameters    `json:"parameters"`
+	JSONMode     bool                    `json:"jsonMode,omitempty"`
+}
+
+// rawChunk is one streamed chunk and when it arrived.
+type rawChunk struct {
+	Offset  time.Duration `json:"offset"`
+	Content string        `json:"content"`
+}
+
+// rawRecorder wraps a provider and keeps every exchange it streams, so an iteration can
+// be archived once it finishes.
+type rawRecorder struct {
+	providers.ChatProvider
+
+	mu        sync.Mutex
+	exchanges []rawExchange
+}
+
+// Stream forwards req to the wrapped provider, recording the request, each chunk and the
+// final metadata or error.
+func (r *rawRecorder) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
+	exchange := rawExchange{
+		StartedAt: time.Now(),
+		Request: rawRequest{
+			HostName:     req.Host.Name,
+			HostURL:      req.Host.URL,
+			HostType:     req.Host.Type,
+			Model:        req.Model,
+			SystemPrompt: req.SystemPrompt,
+			History:      append([]providers.ChatMessage(nil), req.History...),
+			Parameters:   req.Parameters,
+			JSONMode:     req.JSONMode,
+		},
+	}
+	recording := providers.StreamCallbacks{
+		OnChunk: func(chunk providers.ChatMessage) error {
+			exchange.Chunks = append(exchange.Chunks, rawChunk{Offset: time.Since(exchange.StartedAt), Content: chunk.Content})
+			if callbacks.OnChunk != nil {
+				return callbacks.OnChunk(chunk)
+			}
+			return nil
+		},
+		OnComplete: func(meta providers.StreamMetadata) error {
+			exchange.Metadata = &meta
+			if callbacks.OnComplete != nil {
+				return callbacks.OnComplete(meta)
+			}
+			return nil
+		},
+	}
+
+	err := r.ChatProvider.Stream(ctx, req, recording)
+	exchange.Duration = time.Since(exchange.StartedAt)
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, exchange)
+	r.mu.Unlock()
+	return err
+}
+
+// rawRecordID identifies an iteration's archive across runs: the run's start time, the
+// normalized model name and the iteration number.
+func rawRecordID(runStarted time.Time, model string, iteration int) string {
+	return fmt.Sprintf("%s-%s-%04d", runStarted.UTC().Format("20060102T150405Z"), modelname.Normalize(model), iteration)
+}
+
+// writeRawRecord writes record as gzip-compressed JSON to dir/<recordId>.json.gz and
+// returns the file's path.
+func writeRawRecord(dir string, record rawRecord) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating raw archive directory: %w", err)
+	}
+	path := filepath.Join(dir, record.RecordID+".json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("error creating raw archive file: %w", err)
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	if err := json.NewEncoder(zw).Encode(record); err != nil {
+		return "", fmt.Errorf("error writing raw archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("error writing raw archive: %w", err)
+	}
+	return path, nil
+}
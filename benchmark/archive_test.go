@@ -0,0 +1,65 @@
+// benchmark/archive_test.go
+package benchmark
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestRawArchiveRoundTrip verifies the recorder captures every turn's request and streamed
+// reply without exposing host headers, and that the archive is written under its record ID.
+func TestRawArchiveRoundTrip(t *testing.T) {
+	question := mustQuestion(t, `{"id": "recall", "turns": [{"prompt": "My name is Ada."}, {"prompt": "What is my name?"}]}`)
+	recorder := &rawRecorder{ChatProvider: &scriptedProvider{replies: []string{"OK", "Ada"}}}
+	host := appconfig.Host{Name: "gpu", URL: "http://gpu:11434", Models: []string{"Llama3:8B"}, Headers: map[string]string{"Authorization": "secret"}}
+
+	if _, err := runQuestion(context.Background(), recorder, host, question, appconfig.SystemPromptVariant{}, appconfig.ParameterTemplate{}, nil); err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+	if len(recorder.exchanges) != 2 || len(recorder.exchanges[1].Request.History) != 3 || recorder.exchanges[1].Chunks[0].Content != "Ada" {
+		t.Fatalf("unexpected exchanges: %+v", recorder.exchanges)
+	}
+
+	started := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := rawRecord{RecordID: rawRecordID(started, host.Models[0], 7), Model: host.Models[0], Host: host.Name, Iteration: 7, Exchanges: recorder.exchanges}
+	if record.RecordID != "20250301T120000Z-llama3_8b-0007" {
+		t.Fatalf("unexpected record ID %q", record.RecordID)
+	}
+	path, err := writeRawRecord(t.TempDir(), record)
+	if err != nil {
+		t.Fatalf("writeRawRecord returned error: %v", err)
+	}
+	if filepath.Base(path) != record.RecordID+".json.gz" {
+		t.Fatalf("unexpected archive path %q", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("archive is not gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatalf("expected host headers to be left out of the archive")
+	}
+	var got rawRecord
+	if err := json.Unmarshal(data, &got); err != nil || got.RecordID != record.RecordID || len(got.Exchanges) != 2 || got.Exchanges[0].Metadata == nil {
+		t.Fatalf("unexpected archived record: %+v (%v)", got, err)
+	}
+}
@@ -5,26 +5,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"log"
 
+	"github.com/mwiater/agon/analysis"
 	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/metrics"
+	"github.com/mwiater/agon/internal/modelname"
 	"github.com/mwiater/agon/internal/models"
 	"github.com/mwiater/agon/internal/providerfactory"
 	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/usage"
 )
 
+// userPrompt is the built-in benchmark prompt, used when no prompt suites are configured.
 const userPrompt = "List 3 different fruits in alphabetical order? None of the three can be an apple."
 
+// ResultsDir is the directory benchmark result files are written to.
+const ResultsDir = "benchmark/benchmarks"
+
+// capacityPollInterval is how often a busy host is polled before an iteration is dispatched.
+const capacityPollInterval = time.Second
+
 // agonCLIPath is the path to the agon CLI executable for the current OS.
 const agonCLIPath = "dist/agon_linux_amd64_v1/agon"
 
-// BenchmarkModels runs benchmarks for models defined in the configuration.
-func BenchmarkModels(cfg *appconfig.Config) error {
+// BenchmarkModels runs benchmarks for models defined in the configuration, one model per
+// host with the hosts in parallel. Each of the benchmarkCount passes asks every question
+// of the configured prompt suites, or the built-in prompt when none are set, under each
+// of the model's system prompt variants and parameter templates. The graded results are
+// written to ResultsDir, with the run's environment snapshot beside them.
+func BenchmarkModels(cfg *appconfig.Config, agonVersion string) error {
 	if !cfg.BenchmarkMode {
 		return fmt.Errorf("benchmark mode is not enabled in the configuration")
 	}
@@ -39,6 +56,43 @@ func BenchmarkModels(cfg *appconfig.Config) error {
 		}
 	}
 
+	questions := builtinQuestions()
+	if len(cfg.PromptSuites) > 0 {
+		loaded, err := LoadSuites(cfg.PromptSuites)
+		if err != nil {
+			return err
+		}
+		questions = loaded
+	}
+
+	variants := make(map[string][]appconfig.SystemPromptVariant)
+	templates := make(map[string][]appconfig.ParameterTemplate)
+	for _, host := range cfg.Hosts {
+		hostVariants, err := systemPromptVariants(cfg, host.Models[0])
+		if err != nil {
+			return err
+		}
+		variants[host.Models[0]] = hostVariants
+		hostTemplates, err := parameterTemplates(cfg, host.Models[0])
+		if err != nil {
+			return err
+		}
+		templates[host.Models[0]] = hostTemplates
+	}
+
+	judge, err := NewJudge(cfg)
+	if err != nil {
+		return err
+	}
+	if judge == nil && usesJudge(questions) {
+		return fmt.Errorf("prompt suites use the judge grader but no judge is configured")
+	}
+	defer judge.Close()
+
+	environment := metrics.CaptureEnvironment(context.Background(), agonVersion, cfg.Hosts, cfg.RequestTimeout())
+
+	// Every run starts cold so load times are comparable, which is also why the benchmark
+	// does not order its runs by warm state: each host serves a single model anyway.
 	models.UnloadModels(cfg)
 
 	var modelNames []string
@@ -52,10 +106,14 @@ func BenchmarkModels(cfg *appconfig.Config) error {
 		results[host.Models[0]] = &BenchmarkResult{
 			ModelName:      host.Models[0],
 			BenchmarkCount: cfg.BenchmarkCount,
-			Iterations:     make([]IterationResult, 0, cfg.BenchmarkCount),
+			Tools:          cfg.MCPMode,
+			Iterations:     make([]IterationResult, 0, cfg.BenchmarkCount*len(questions)*len(variants[host.Models[0]])*len(templates[host.Models[0]])),
 		}
 	}
 
+	rawDir := cfg.RawArchivePath()
+	var disagreementsMu sync.Mutex
+	var disagreements []JudgeDisagreement
 	var wg sync.WaitGroup
 	for _, host := range cfg.Hosts {
 		wg.Add(1)
@@ -68,101 +126,165 @@ func BenchmarkModels(cfg *appconfig.Config) error {
 			}
 
 			log.Printf("Ensuring model %s is loaded on host %s...", host.Models[0], host.Name)
+			loadStart := time.Now()
 			if err := provider.EnsureModelReady(context.Background(), host, host.Models[0]); err != nil {
 				log.Printf("error ensuring model %s is ready on host %s: %v", host.Models[0], host.Name, err)
 				return
 			}
-
-			for i := 0; i < cfg.BenchmarkCount; i++ {
-				log.Printf("Running iteration %d of %d for model %s on host %s...", i+1, cfg.BenchmarkCount, host.Models[0], host.Name)
-
-				startTime := time.Now()
-				var timeToFirstToken time.Duration
-				firstChunk := true
-
-				var outputTokens int
-				var inputTokens int
-
-				req := providers.StreamRequest{
-					Host:  host,
-					Model: host.Models[0],
-					History: []providers.ChatMessage{{
-						Role:    "user",
-						Content: userPrompt,
-					}},
+			loadTime := time.Since(loadStart)
+
+			// Each question is asked under every system prompt variant and parameter template
+			// in turn, so variants and templates are compared on the same questions.
+			hostVariants := variants[host.Models[0]]
+			hostTemplates := templates[host.Models[0]]
+			iterations := cfg.BenchmarkCount * len(questions) * len(hostVariants) * len(hostTemplates)
+			concurrency := cfg.BenchmarkConcurrencyFor(host)
+			if concurrency > 1 {
+				log.Printf("Running up to %d questions at once for model %s on host %s; timings will be flagged as contended", concurrency, host.Models[0], host.Name)
+			}
+			ask := func(ctx context.Context, i int) (IterationResult, error) {
+				template := hostTemplates[i%len(hostTemplates)]
+				variant := hostVariants[i/len(hostTemplates)%len(hostVariants)]
+				question := questions[i/(len(hostTemplates)*len(hostVariants))%len(questions)]
+				asked := question.ID
+				if variant.Name != "" {
+					asked += ", system prompt " + variant.Name
 				}
-
-				callbacks := providers.StreamCallbacks{
-					OnChunk: func(chunk providers.ChatMessage) error {
-						if firstChunk {
-							timeToFirstToken = time.Since(startTime)
-							firstChunk = false
-							log.Printf("First chunk received for model %s on host %s after %s", host.Models[0], host.Name, timeToFirstToken)
-						}
-						return nil
-					},
-					OnComplete: func(meta providers.StreamMetadata) error {
-						outputTokens = meta.EvalCount
-						inputTokens = meta.PromptEvalCount
-						return nil
-					},
+				if template.Name != "" {
+					asked += ", parameters " + template.Name
+				}
+				log.Printf("Running iteration %d of %d (%s) for model %s on host %s...", i+1, iterations, asked, host.Models[0], host.Name)
+
+				// Requests queued on the server would be timed as slow responses, so wait for a
+				// free slot. Concurrent runs load the host on purpose and flag their timings instead.
+				if concurrency == 1 {
+					_ = providers.WaitForCapacity(ctx, provider, host, capacityPollInterval, cfg.RequestTimeout(), func(load providers.HostLoad) {
+						log.Printf("Delaying iteration %d for model %s on host %s: %s", i+1, host.Models[0], host.Name, load)
+					})
 				}
 
-				if err := provider.Stream(context.Background(), req, callbacks); err != nil {
+				// With raw archiving on, the iteration's exchanges are recorded and written
+				// under a record ID the results link to.
+				asker := provider
+				var recorder *rawRecorder
+				if rawDir != "" {
+					recorder = &rawRecorder{ChatProvider: provider}
+					asker = recorder
+				}
+				iterationResult, err := runQuestion(ctx, asker, host, question, variant, template, judge)
+				if err != nil {
 					log.Printf("error during stream with model %s: %v", host.Models[0], err)
-					continue
+					if !providers.Retryable(err) {
+						log.Printf("stopping benchmark for model %s on host %s: %s", host.Models[0], host.Name, providers.UserMessage(err))
+					}
+					return IterationResult{}, err
 				}
 
-				endTime := time.Now()
-				totalExecutionTime := endTime.Sub(startTime)
-				tokensPerSecond := float64(outputTokens) / totalExecutionTime.Seconds()
-
-				iterationResult := IterationResult{
-					Iteration: i + 1,
-					Stats: IterationStats{
-						TotalExecutionTime: totalExecutionTime,
-						TimeToFirstToken:   timeToFirstToken,
-						TokensPerSecond:    tokensPerSecond,
-						InputTokenCount:    inputTokens,
-						OutputTokenCount:   outputTokens,
-					},
+				if recorder != nil {
+					record := rawRecord{
+						RecordID:     rawRecordID(environment.CapturedAt, host.Models[0], i+1),
+						Model:        host.Models[0],
+						Host:         host.Name,
+						Iteration:    i + 1,
+						QuestionID:   question.ID,
+						SystemPrompt: variant.Name,
+						Exchanges:    recorder.exchanges,
+					}
+					if path, err := writeRawRecord(rawDir, record); err != nil {
+						log.Printf("unable to archive iteration %d for model %s on host %s: %v", i+1, host.Models[0], host.Name, err)
+					} else {
+						iterationResult.RecordID = record.RecordID
+						log.Printf("Raw exchanges for iteration %d archived to %s", i+1, path)
+					}
 				}
 
-				modelResult := results[host.Models[0]]
-				modelResult.Iterations = append(modelResult.Iterations, iterationResult)
-
+				stats := iterationResult.Stats
 				log.Printf("Iteration %d for model %s on host %s complete:", i+1, host.Models[0], host.Name)
-				log.Printf("  Total Execution Time: %s", totalExecutionTime)
-				log.Printf("  Time to First Token: %s", timeToFirstToken)
-				log.Printf("  Tokens per Second: %.2f", tokensPerSecond)
-				log.Printf("  Input Tokens: %d", inputTokens)
-				log.Printf("  Output Tokens: %d", outputTokens)
+				log.Printf("  Total Execution Time: %s", stats.TotalExecutionTime)
+				log.Printf("  Time to First Token: %s", stats.TimeToFirstToken)
+				log.Printf("  Tokens per Second: %.2f", stats.TokensPerSecond)
+				log.Printf("  Input Tokens: %d", stats.InputTokenCount)
+				log.Printf("  Output Tokens: %d", stats.OutputTokenCount)
+				if len(iterationResult.ToolCalls) > 0 {
+					log.Printf("  Tools Called: %s", strings.Join(iterationResult.ToolCalls, ", "))
+				}
+				if len(iterationResult.disagreements) > 0 {
+					log.Printf("  Judges Disagreed: %s", question.ID)
+					disagreementsMu.Lock()
+					for _, disagreement := range iterationResult.disagreements {
+						disagreement.Model, disagreement.Host, disagreement.Iteration = host.Models[0], host.Name, i+1
+						disagreements = append(disagreements, disagreement)
+					}
+					disagreementsMu.Unlock()
+				}
+				return iterationResult, nil
+			}
+
+			modelResult := results[host.Models[0]]
+			modelResult.Iterations = append(modelResult.Iterations, runIterations(context.Background(), iterations, concurrency, ask)...)
+			if concurrency > 1 {
+				modelResult.Concurrency = concurrency
 			}
+			modelResult.ColdStart = coldStart(modelResult.Iterations, loadTime, markWarmup(modelResult.Iterations, cfg.BenchmarkWarmup))
 		}(host)
 	}
 	wg.Wait()
 
 	for _, result := range results {
 		calculateAggregates(result)
+		result.Accuracy = calculateAccuracy(result.Iterations)
+	}
+
+	fileName, err := writeResults(results, cfg.BenchmarkCount, cfg.MCPMode)
+	if err != nil {
+		return err
 	}
+	envPath := metrics.EnvironmentPathFor(fileName)
+	if err := metrics.WriteEnvironment(envPath, environment); err != nil {
+		return err
+	}
+	log.Printf("Environment snapshot written to %s", envPath)
+	if judge != nil && judge.second != nil {
+		disagreementsPath := DisagreementsPathFor(fileName)
+		if err := writeDisagreements(disagreementsPath, disagreements); err != nil {
+			return err
+		}
+		log.Printf("%d judge disagreements written to %s", len(disagreements), disagreementsPath)
+	}
+	recordBenchmarkUsage(*cfg, results, time.Since(environment.CapturedAt))
+	return nil
+}
 
-	return writeResults(results, cfg.BenchmarkCount)
+// recordBenchmarkUsage adds the run and its token totals to the opt-in usage stats.
+func recordBenchmarkUsage(cfg appconfig.Config, results map[string]*BenchmarkResult, elapsed time.Duration) {
+	event := usage.Event{Kind: usage.KindBenchmark, Name: "benchmark", DurationSeconds: elapsed.Seconds()}
+	for _, result := range results {
+		for _, iteration := range result.Iterations {
+			event.PromptTokens += iteration.Stats.InputTokenCount
+			event.OutputTokens += iteration.Stats.OutputTokenCount
+		}
+	}
+	if err := usage.Record(cfg, event); err != nil {
+		log.Printf("unable to record usage stats: %v", err)
+	}
 }
 
-// calculateAggregates calculates the average, min, and max statistics for a benchmark result.
+// calculateAggregates calculates the average, min, and max statistics for a benchmark
+// result, leaving out warm-up iterations.
 func calculateAggregates(result *BenchmarkResult) {
-	if len(result.Iterations) == 0 {
+	iterations := steadyIterations(result.Iterations)
+	if len(iterations) == 0 {
 		return
 	}
 
-	result.MinStats = result.Iterations[0].Stats
-	result.MaxStats = result.Iterations[0].Stats
+	result.MinStats = iterations[0].Stats
+	result.MaxStats = iterations[0].Stats
 
 	var totalExecutionTime time.Duration
 	var timeToFirstToken time.Duration
 	var tokensPerSecond float64
 
-	for _, iter := range result.Iterations {
+	for _, iter := range iterations {
 		totalExecutionTime += iter.Stats.TotalExecutionTime
 		timeToFirstToken += iter.Stats.TimeToFirstToken
 		tokensPerSecond += iter.Stats.TokensPerSecond
@@ -189,34 +311,68 @@ func calculateAggregates(result *BenchmarkResult) {
 		}
 	}
 
-	count := float64(len(result.Iterations))
+	count := float64(len(iterations))
 	result.AverageStats.TotalExecutionTime = time.Duration(float64(totalExecutionTime) / count)
 	result.AverageStats.TimeToFirstToken = time.Duration(float64(timeToFirstToken) / count)
 	result.AverageStats.TokensPerSecond = tokensPerSecond / count
+
+	result.P50Stats = percentileStats(iterations, 50)
+	result.P90Stats = percentileStats(iterations, 90)
+	result.P95Stats = percentileStats(iterations, 95)
+	result.P99Stats = percentileStats(iterations, 99)
+}
+
+// percentileStats returns the p-th percentile of each statistic across iterations, each
+// computed on its own.
+func percentileStats(iterations []IterationResult, p float64) *IterationStats {
+	var total, ttft, tps, input, output []float64
+	for _, iter := range iterations {
+		total = append(total, float64(iter.Stats.TotalExecutionTime))
+		ttft = append(ttft, float64(iter.Stats.TimeToFirstToken))
+		tps = append(tps, iter.Stats.TokensPerSecond)
+		input = append(input, float64(iter.Stats.InputTokenCount))
+		output = append(output, float64(iter.Stats.OutputTokenCount))
+	}
+	return &IterationStats{
+		TotalExecutionTime: time.Duration(analysis.Percentile(total, p)),
+		TimeToFirstToken:   time.Duration(analysis.Percentile(ttft, p)),
+		TokensPerSecond:    analysis.Percentile(tps, p),
+		InputTokenCount:    int(math.Round(analysis.Percentile(input, p))),
+		OutputTokenCount:   int(math.Round(analysis.Percentile(output, p))),
+	}
 }
 
-// writeResults writes the benchmark results to a JSON file.
-func writeResults(results map[string]*BenchmarkResult, benchmarkCount int) error {
+// writeResults writes the benchmark results to a JSON file and returns its path.
+func writeResults(results map[string]*BenchmarkResult, benchmarkCount int, tools bool) (string, error) {
 	var modelNames []string
 	for name := range results {
 		modelNames = append(modelNames, name)
 	}
 
-	fileName := fmt.Sprintf("benchmark/benchmarks/%s-%d.json", strings.Join(modelNames, "-"), benchmarkCount)
+	fileName := filepath.Join(ResultsDir, resultFileName(modelNames, benchmarkCount, tools))
 
 	file, err := os.Create(fileName)
 	if err != nil {
-		return fmt.Errorf("error creating result file: %w", err)
+		return "", fmt.Errorf("error creating result file: %w", err)
 	}
 	defer file.Close()
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(results); err != nil {
-		return fmt.Errorf("error writing results to file: %w", err)
+		return "", fmt.Errorf("error writing results to file: %w", err)
 	}
 
 	log.Printf("Benchmark results written to %s", fileName)
 
-	return nil
+	return fileName, nil
+}
+
+// resultFileName builds the canonical result file name for a set of benchmarked models,
+// marking runs in which the models could call tools.
+func resultFileName(modelNames []string, benchmarkCount int, tools bool) string {
+	if tools {
+		return fmt.Sprintf("%s-%d-tools.json", modelname.BundleStem(modelNames), benchmarkCount)
+	}
+	return fmt.Sprintf("%s-%d.json", modelname.BundleStem(modelNames), benchmarkCount)
 }
@@ -0,0 +1,28 @@
+// benchmark/benchmark_test.go
+package benchmark
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCalculateAggregatesPercentiles verifies percentiles are computed per statistic from
+// the iterations, so one slow iteration shows in P99 but not in P50.
+func TestCalculateAggregatesPercentiles(t *testing.T) {
+	result := &BenchmarkResult{}
+	for i := 1; i <= 5; i++ {
+		ttft := time.Second
+		if i == 5 {
+			ttft = 11 * time.Second
+		}
+		result.Iterations = append(result.Iterations, IterationResult{Iteration: i, Stats: IterationStats{TimeToFirstToken: ttft, TokensPerSecond: float64(10 * i)}})
+	}
+	calculateAggregates(result)
+
+	if result.P50Stats.TimeToFirstToken != time.Second || result.P50Stats.TokensPerSecond != 30 {
+		t.Fatalf("unexpected P50: %+v", result.P50Stats)
+	}
+	if result.P99Stats.TimeToFirstToken <= 10*time.Second || result.P90Stats.TokensPerSecond != 46 {
+		t.Fatalf("unexpected tail percentiles: P90 %+v, P99 %+v", result.P90Stats, result.P99Stats)
+	}
+}
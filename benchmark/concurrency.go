@@ -0,0 +1,99 @@
+// benchmark/concurrency.go
+package benchmark
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// timedIteration is a finished iteration and the wall-clock span its requests took.
+type timedIteration struct {
+	result     IterationResult
+	start, end time.Time
+}
+
+// runIterations asks iterations 0 to n-1 with up to concurrency of them in flight, as the
+// host's benchmarkConcurrency allows, and returns the successful ones in iteration order.
+// A non-retryable error stops further iterations from starting; those already running
+// finish. Iterations whose requests overlapped another iteration's are marked Contended.
+func runIterations(ctx context.Context, n, concurrency int, ask func(ctx context.Context, i int) (IterationResult, error)) []IterationResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu       sync.Mutex
+		timed    []timedIteration
+		wg       sync.WaitGroup
+		stop     = make(chan struct{})
+		stopOnce sync.Once
+		indexes  = make(chan int)
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				select {
+				case <-stop:
+					continue
+				default:
+				}
+				start := time.Now()
+				result, err := ask(ctx, i)
+				end := time.Now()
+				if err != nil {
+					if !providers.Retryable(err) {
+						stopOnce.Do(func() { close(stop) })
+					}
+					continue
+				}
+				result.Iteration = i + 1
+				mu.Lock()
+				timed = append(timed, timedIteration{result: result, start: start, end: end})
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-stop:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	markContended(timed)
+	sort.Slice(timed, func(a, b int) bool { return timed[a].result.Iteration < timed[b].result.Iteration })
+	results := make([]IterationResult, len(timed))
+	for i, t := range timed {
+		results[i] = t.result
+	}
+	return results
+}
+
+// markContended sorts timed by start time and marks each iteration whose span overlaps
+// another's, since its throughput and latency were measured while sharing the host.
+func markContended(timed []timedIteration) {
+	sort.Slice(timed, func(a, b int) bool { return timed[a].start.Before(timed[b].start) })
+	var latestEnd time.Time
+	for i := range timed {
+		overlapsEarlier := latestEnd.After(timed[i].start)
+		overlapsLater := i+1 < len(timed) && timed[i+1].start.Before(timed[i].end)
+		if overlapsEarlier || overlapsLater {
+			timed[i].result.Contended = true
+		}
+		if timed[i].end.After(latestEnd) {
+			latestEnd = timed[i].end
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// benchmark/concurrency_test.go
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestRunIterationsConcurrent verifies iterations run up to the concurrency limit, come
+// back in order and are flagged contended only when they overlapped.
+func TestRunIterationsConcurrent(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	ask := func(ctx context.Context, i int) (IterationResult, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		return IterationResult{QuestionID: "q"}, nil
+	}
+
+	results := runIterations(context.Background(), 6, 3, ask)
+	if len(results) != 6 || peak.Load() != 3 {
+		t.Fatalf("expected 6 results with 3 in flight, got %d with %d", len(results), peak.Load())
+	}
+	for i, r := range results {
+		if r.Iteration != i+1 || !r.Contended {
+			t.Fatalf("unexpected result %d: %+v", i, r)
+		}
+	}
+
+	for _, r := range runIterations(context.Background(), 3, 1, ask) {
+		if r.Contended {
+			t.Fatalf("serial iterations must not be contended: %+v", r)
+		}
+	}
+}
+
+// TestRunIterationsStopsOnFatalError verifies a non-retryable error stops new iterations
+// while retryable ones are skipped.
+func TestRunIterationsStopsOnFatalError(t *testing.T) {
+	var asked atomic.Int32
+	ask := func(ctx context.Context, i int) (IterationResult, error) {
+		asked.Add(1)
+		switch i {
+		case 0:
+			return IterationResult{}, errors.New("connection reset")
+		case 2:
+			return IterationResult{}, providers.ErrModelNotFound
+		}
+		return IterationResult{}, nil
+	}
+	results := runIterations(context.Background(), 10, 1, ask)
+	if asked.Load() != 3 || len(results) != 1 || results[0].Iteration != 2 {
+		t.Fatalf("expected to stop after the third iteration, asked %d, got %+v", asked.Load(), results)
+	}
+}
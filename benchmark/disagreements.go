@@ -0,0 +1,73 @@
+// benchmark/disagreements.go
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DisagreementsFileSuffix replaces a result file's extension to name the file listing the
+// answers the judge and second judge graded differently.
+const DisagreementsFileSuffix = ".disagreements.jsonl"
+
+// JudgeDisagreement is one answer the judge and second judge graded differently.
+type JudgeDisagreement struct {
+	Model      string `json:"model"`
+	Host       string `json:"host"`
+	Iteration  int    `json:"iteration"`
+	QuestionID string `json:"questionId"`
+	Suite      string `json:"suite,omitempty"`
+	// Turn numbers the turn of a multi-turn question; it is zero otherwise.
+	Turn              int    `json:"turn,omitempty"`
+	SystemPrompt      string `json:"systemPrompt,omitempty"`
+	ParameterTemplate string `json:"parameterTemplate,omitempty"`
+	Answer            string `json:"answer"`
+	// Judge, Correct and Reason are the first judge's model, verdict and explanation, and
+	// the Second fields the second judge's.
+	Judge         string `json:"judge"`
+	Correct       bool   `json:"correct"`
+	Reason        string `json:"reason,omitempty"`
+	SecondJudge   string `json:"secondJudge"`
+	SecondOpinion bool   `json:"secondOpinion"`
+	SecondReason  string `json:"secondReason,omitempty"`
+}
+
+// DisagreementsPathFor returns the disagreements file written beside a result file.
+func DisagreementsPathFor(resultPath string) string {
+	return strings.TrimSuffix(resultPath, filepath.Ext(resultPath)) + DisagreementsFileSuffix
+}
+
+// writeDisagreements writes one disagreement per line, ordered by model, iteration and
+// turn. An empty file records that the judges agreed on every answer.
+func writeDisagreements(path string, disagreements []JudgeDisagreement) error {
+	sort.Slice(disagreements, func(i, j int) bool {
+		a, b := disagreements[i], disagreements[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		if a.Iteration != b.Iteration {
+			return a.Iteration < b.Iteration
+		}
+		return a.Turn < b.Turn
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create disagreements file %s: %w", path, err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, disagreement := range disagreements {
+		if err := encoder.Encode(disagreement); err != nil {
+			file.Close()
+			return fmt.Errorf("unable to write disagreements file %s: %w", path, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write disagreements file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+// benchmark/grading.go
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providerfactory"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// Graders a question can select with its "grader" field.
+const (
+	// GraderContains passes answers that contain the expected text, ignoring case and spacing.
+	GraderContains = "contains"
+	// GraderExact passes answers equal to the expected text, ignoring case and spacing.
+	GraderExact = "exact"
+	// GraderNumeric passes answers whose last number is within the margin of the expected one.
+	GraderNumeric = "numeric"
+	// GraderRegex passes answers matching the expected regular expression.
+	GraderRegex = "regex"
+	// GraderJSON passes answers whose JSON value, or the value at field, equals the expected one.
+	GraderJSON = "json"
+	// GraderJudge asks the configured judge model whether the answer is correct.
+	GraderJudge = "judge"
+)
+
+// Grade is the outcome of grading one answer.
+type Grade struct {
+	Correct bool
+	// Grader is the grader that decided, e.g. "regex".
+	Grader string
+	// Reason explains the decision; only the judge grader sets it.
+	Reason string
+	// SecondOpinion is the second judge's verdict and SecondReason its explanation, when a
+	// second judge is configured and answered.
+	SecondOpinion *bool
+	SecondReason  string
+}
+
+// Disagrees reports whether the second judge reached a different verdict.
+func (g Grade) Disagrees() bool {
+	return g.SecondOpinion != nil && *g.SecondOpinion != g.Correct
+}
+
+// Grade grades answer with the question's grader. scored is false for questions without
+// an expected answer or rubric. judge is only used by the judge grader.
+func (q Question) Grade(ctx context.Context, answer string, judge *Judge) (grade Grade, scored bool, err error) {
+	grade.Grader = q.Grader
+	switch q.Grader {
+	case "":
+		return Grade{}, false, nil
+	case GraderContains:
+		grade.Correct = strings.Contains(normalizeAnswer(answer), normalizeAnswer(q.Expected))
+	case GraderExact:
+		grade.Correct = normalizeAnswer(answer) == normalizeAnswer(q.Expected)
+	case GraderNumeric:
+		grade.Correct = gradeNumeric(answer, q.Expected, q.Margin)
+	case GraderRegex:
+		grade.Correct = q.pattern.MatchString(answer)
+	case GraderJSON:
+		grade.Correct = gradeJSON(answer, q.Field, q.expectedValue, q.Margin)
+	case GraderJudge:
+		if judge == nil {
+			return Grade{}, false, fmt.Errorf("question %s uses the judge grader but no judge is configured", q.ID)
+		}
+		grade.Correct, grade.Reason, err = judge.Grade(ctx, q, answer)
+		if err != nil {
+			return Grade{}, false, err
+		}
+		// A failed second opinion leaves the answer graded by the first judge alone.
+		if judge.second != nil {
+			second, reason, err := judge.second.Grade(ctx, q, answer)
+			if err != nil {
+				log.Printf("no second opinion on %s: %v", q.ID, err)
+			} else {
+				grade.SecondOpinion, grade.SecondReason = &second, reason
+			}
+		}
+	default:
+		return Grade{}, false, fmt.Errorf("question %s: unknown grader %q", q.ID, q.Grader)
+	}
+	return grade, true, nil
+}
+
+// gradeNumeric compares the last number in answer with expected, within margin.
+func gradeNumeric(answer, expected string, margin float64) bool {
+	want, ok := parseNumber(expected)
+	if !ok {
+		return false
+	}
+	numbers := numberPattern.FindAllString(answer, -1)
+	if len(numbers) == 0 {
+		return false
+	}
+	got, ok := parseNumber(numbers[len(numbers)-1])
+	return ok && math.Abs(got-want) <= margin
+}
+
+// gradeJSON decodes the first JSON object or array in answer, which may be wrapped in
+// prose or a code fence, and compares the value at the dotted field path with expected.
+func gradeJSON(answer, field string, expected any, margin float64) bool {
+	value, ok := extractJSON(answer)
+	if !ok {
+		return false
+	}
+	if field != "" {
+		for _, key := range strings.Split(field, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return false
+			}
+			if value, ok = object[key]; !ok {
+				return false
+			}
+		}
+	}
+	return jsonEqual(value, expected, margin)
+}
+
+// extractJSON returns the first JSON object or array that decodes from answer.
+func extractJSON(answer string) (any, bool) {
+	for start := 0; start < len(answer); start++ {
+		if answer[start] != '{' && answer[start] != '[' {
+			continue
+		}
+		var value any
+		decoder := json.NewDecoder(strings.NewReader(answer[start:]))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err == nil {
+			return normalizeJSON(value), true
+		}
+	}
+	return nil, false
+}
+
+// normalizeJSON converts json.Number values to float64 so decoded answers compare with
+// expected values decoded from the suite.
+func normalizeJSON(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeJSON(item)
+		}
+	}
+	return value
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, allowing numbers to differ
+// by margin and strings to differ in case and spacing.
+func jsonEqual(got, want any, margin float64) bool {
+	switch w := want.(type) {
+	case float64:
+		g, ok := got.(float64)
+		return ok && math.Abs(g-w) <= margin
+	case string:
+		g, ok := got.(string)
+		return ok && normalizeAnswer(g) == normalizeAnswer(w)
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for key, item := range w {
+			if !jsonEqual(g[key], item, margin) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !jsonEqual(g[i], w[i], margin) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// judgeSystemPrompt instructs the judge model how to grade and how to reply.
+const judgeSystemPrompt = `You grade answers to benchmark questions. Decide whether the answer is correct using the reference answer and rubric provided. Ignore style and length unless the rubric mentions them. Reply with only a JSON object: {"correct": true or false, "reason": "one short sentence"}.`
+
+// judgeVerdict matches the JSON object in a judge reply.
+var judgeVerdict = regexp.MustCompile(`(?s)\{.*\}`)
+
+// Judge grades answers by asking a judge model.
+type Judge struct {
+	provider providers.ChatProvider
+	host     appconfig.Host
+	model    string
+	// second is the judge asked for a second opinion on every answer, if any.
+	second *Judge
+}
+
+// NewJudge returns the judge configured in cfg, or nil when none is configured. When cfg
+// also configures a second judge, it grades every answer the judge does.
+func NewJudge(cfg *appconfig.Config) (*Judge, error) {
+	if cfg.Judge == nil {
+		if cfg.SecondJudge != nil {
+			return nil, fmt.Errorf("secondJudge requires a judge")
+		}
+		return nil, nil
+	}
+	judge, err := newJudge(cfg, *cfg.Judge, "judge")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SecondJudge != nil {
+		if judge.second, err = newJudge(cfg, *cfg.SecondJudge, "secondJudge"); err != nil {
+			judge.Close()
+			return nil, err
+		}
+	}
+	return judge, nil
+}
+
+// newJudge returns a judge for one judge config; name labels it in errors and is the
+// default host name.
+func newJudge(cfg *appconfig.Config, judge appconfig.JudgeConfig, name string) (*Judge, error) {
+	if strings.TrimSpace(judge.Host.URL) == "" || strings.TrimSpace(judge.Model) == "" {
+		return nil, fmt.Errorf("%s requires a host url and a model", name)
+	}
+	host := judge.Host
+	if host.Name == "" {
+		host.Name = name
+	}
+	// The judge bypasses metrics so its requests are not counted as benchmark traffic.
+	return &Judge{provider: providerfactory.NewHostRouter(cfg), host: host, model: judge.Model}, nil
+}
+
+// Grade asks the judge whether answer correctly answers q, returning its verdict and reason.
+func (j *Judge) Grade(ctx context.Context, q Question, answer string) (bool, string, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Question:\n%s\n\n", q.Prompt)
+	if q.Expected != "" {
+		fmt.Fprintf(&prompt, "Reference answer:\n%s\n\n", q.Expected)
+	}
+	if q.Rubric != "" {
+		fmt.Fprintf(&prompt, "Rubric:\n%s\n\n", q.Rubric)
+	}
+	fmt.Fprintf(&prompt, "Answer to grade:\n%s", answer)
+
+	temperature := 0.0
+	params := j.host.Parameters
+	if params.Temperature == nil {
+		params.Temperature = &temperature
+	}
+
+	var reply strings.Builder
+	err := j.provider.Stream(ctx, providers.StreamRequest{
+		Host:         j.host,
+		Model:        j.model,
+		SystemPrompt: judgeSystemPrompt,
+		History:      []providers.ChatMessage{{Role: "user", Content: prompt.String()}},
+		Parameters:   params,
+		JSONMode:     true,
+	}, providers.StreamCallbacks{
+		OnChunk: func(chunk providers.ChatMessage) error {
+			reply.WriteString(chunk.Content)
+			return nil
+		},
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("judge %s: %w", j.model, err)
+	}
+	return parseVerdict(reply.String())
+}
+
+// parseVerdict decodes the judge's JSON verdict, tolerating prose around it.
+func parseVerdict(reply string) (bool, string, error) {
+	var verdict struct {
+		Correct *bool  `json:"correct"`
+		Reason  string `json:"reason"`
+	}
+	raw := judgeVerdict.FindString(reply)
+	if err := json.Unmarshal([]byte(raw), &verdict); err != nil || verdict.Correct == nil {
+		return false, "", fmt.Errorf("judge reply is not a verdict: %q", reply)
+	}
+	return *verdict.Correct, strings.TrimSpace(verdict.Reason), nil
+}
+
+// Close releases the judge's provider and the second judge's.
+func (j *Judge) Close() error {
+	if j == nil {
+		return nil
+	}
+	return errors.Join(j.provider.Close(), j.second.Close())
+}
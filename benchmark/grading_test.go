@@ -0,0 +1,153 @@
+// benchmark/grading_test.go
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// mustQuestion parses one suite line, failing the test on error.
+func mustQuestion(t *testing.T, line string) Question {
+	t.Helper()
+	question, err := parseSuiteEntry("suite", 1, []byte(line))
+	if err != nil {
+		t.Fatalf("parseSuiteEntry(%s) returned error: %v", line, err)
+	}
+	return question
+}
+
+// TestQuestionGrade verifies each local grader and the grader inferred from the expected answer.
+func TestQuestionGrade(t *testing.T) {
+	cases := []struct {
+		line    string
+		answer  string
+		grader  string
+		correct bool
+	}{
+		{`{"prompt": "p", "expected": 72, "margin": 0.5}`, "180 km in 2.5 hours gives 72.4", GraderNumeric, true},
+		{`{"prompt": "p", "expected": 72, "margin": 0.5}`, "The answer is 73.", GraderNumeric, false},
+		{`{"prompt": "p", "expected": 1000}`, "It is 1,000.", GraderNumeric, true},
+		{`{"prompt": "p", "expected": "New  York"}`, "The city is new york.", GraderContains, true},
+		{`{"prompt": "p", "expected": "Paris", "grader": "exact"}`, " paris ", GraderExact, true},
+		{`{"prompt": "p", "expected": "Paris", "grader": "exact"}`, "Paris, France", GraderExact, false},
+		{`{"prompt": "p", "expected": "(?i)^answer:\\s*[B]$", "grader": "regex"}`, "answer: B", GraderRegex, true},
+		{`{"prompt": "p", "expected": "(?i)^answer:\\s*[B]$", "grader": "regex"}`, "answer: C", GraderRegex, false},
+		{`{"prompt": "p", "expected": 42.1, "grader": "json", "field": "result.total", "margin": 0.2}`, "Sure:\n```json\n{\"result\": {\"total\": 42, \"unit\": \"kg\"}}\n```", GraderJSON, true},
+		{`{"prompt": "p", "expected": {"city": "Paris", "tags": [1, 2]}, "grader": "json"}`, `{"tags": [1, 2], "city": "paris"}`, GraderJSON, true},
+		{`{"prompt": "p", "expected": {"city": "Paris"}, "grader": "json"}`, `{"city": "Paris", "extra": true}`, GraderJSON, false},
+		{`{"prompt": "p", "expected": true, "grader": "json", "field": "ok"}`, "no json here", GraderJSON, false},
+	}
+	for _, tc := range cases {
+		question := mustQuestion(t, tc.line)
+		grade, scored, err := question.Grade(context.Background(), tc.answer, nil)
+		if err != nil || !scored {
+			t.Fatalf("Grade(%q) for %s: scored=%v err=%v", tc.answer, tc.line, scored, err)
+		}
+		if grade.Grader != tc.grader || grade.Correct != tc.correct {
+			t.Fatalf("Grade(%q) for %s = %+v, want grader %s correct %v", tc.answer, tc.line, grade, tc.grader, tc.correct)
+		}
+	}
+
+	if _, scored, _ := mustQuestion(t, `{"prompt": "p"}`).Grade(context.Background(), "anything", nil); scored {
+		t.Fatalf("expected a question without an expected answer to be unscored")
+	}
+}
+
+// TestParseSuiteEntryValidatesGraders verifies grader-specific schema errors.
+func TestParseSuiteEntryValidatesGraders(t *testing.T) {
+	for line, want := range map[string]string{
+		`{"prompt": "p", "grader": "fuzzy", "expected": "x"}`:      "must be contains, exact, numeric, regex, json or judge",
+		`{"prompt": "p", "grader": "regex", "expected": "(["}`:     "valid pattern",
+		`{"prompt": "p", "grader": "numeric", "expected": "many"}`: "numeric expected answer",
+		`{"prompt": "p", "grader": "json"}`:                        "requires an expected value",
+		`{"prompt": "p", "grader": "judge"}`:                       "expected answer or a rubric",
+		`{"prompt": "p", "expected": "x", "field": "a"}`:           "field is only used by the json grader",
+		`{"prompt": "p", "expected": "x", "rubric": "r"}`:          "rubric is only used by the judge grader",
+		`{"prompt": "p", "expected": [1], "grader": "exact"}`:      "expected must be a string or a number",
+	} {
+		_, err := parseSuiteEntry("suite", 1, []byte(line))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("parseSuiteEntry(%s) error = %v, want it to mention %q", line, err, want)
+		}
+	}
+}
+
+// TestJudgeGrade verifies that the judge grader sends the question, reference and rubric to
+// the configured judge model and records its verdict.
+func TestJudgeGrade(t *testing.T) {
+	var payload struct {
+		Model    string `json:"model"`
+		Format   string `json:"format"`
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode judge request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"{\"correct\": false, \"reason\": \"Misses the second fruit.\"}"},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	judge, err := NewJudge(&appconfig.Config{
+		TimeoutSeconds: 5,
+		Judge:          &appconfig.JudgeConfig{Host: appconfig.Host{URL: server.URL, Type: "ollama"}, Model: "judge-model"},
+	})
+	if err != nil {
+		t.Fatalf("NewJudge returned error: %v", err)
+	}
+	defer judge.Close()
+
+	question := mustQuestion(t, `{"prompt": "Name two fruits.", "grader": "judge", "expected": "apple, pear", "rubric": "Both fruits must be named."}`)
+	grade, scored, err := question.Grade(context.Background(), "apple", judge)
+	if err != nil || !scored {
+		t.Fatalf("Grade returned scored=%v err=%v", scored, err)
+	}
+	if grade.Correct || grade.Grader != GraderJudge || grade.Reason != "Misses the second fruit." {
+		t.Fatalf("unexpected grade: %+v", grade)
+	}
+	if payload.Model != "judge-model" || payload.Format != "json" || len(payload.Messages) != 2 {
+		t.Fatalf("unexpected judge request: %+v", payload)
+	}
+	for _, want := range []string{"Name two fruits.", "Reference answer:\napple, pear", "Rubric:\nBoth fruits must be named.", "Answer to grade:\napple"} {
+		if !strings.Contains(payload.Messages[1].Content, want) {
+			t.Fatalf("judge prompt is missing %q:\n%s", want, payload.Messages[1].Content)
+		}
+	}
+
+	if _, _, err := question.Grade(context.Background(), "apple", nil); err == nil {
+		t.Fatalf("expected the judge grader to fail without a judge")
+	}
+}
+
+// TestNewJudgeSecond verifies that a second judge is built beside the judge and that it
+// cannot be configured alone.
+func TestNewJudgeSecond(t *testing.T) {
+	judgeConfig := &appconfig.JudgeConfig{Host: appconfig.Host{URL: "http://localhost:11434", Type: "ollama"}, Model: "judge-a"}
+	secondConfig := &appconfig.JudgeConfig{Host: appconfig.Host{URL: "http://localhost:11434", Type: "ollama"}, Model: "judge-b"}
+
+	judge, err := NewJudge(&appconfig.Config{TimeoutSeconds: 5, Judge: judgeConfig, SecondJudge: secondConfig})
+	if err != nil {
+		t.Fatalf("NewJudge returned error: %v", err)
+	}
+	if judge.second == nil || judge.second.model != "judge-b" || judge.second.host.Name != "secondJudge" {
+		t.Fatalf("unexpected second judge %+v", judge.second)
+	}
+	if err := judge.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	if _, err := NewJudge(&appconfig.Config{SecondJudge: secondConfig}); err == nil || !strings.Contains(err.Error(), "secondJudge requires a judge") {
+		t.Fatalf("expected an error for a second judge without a judge, got %v", err)
+	}
+	if _, err := NewJudge(&appconfig.Config{Judge: judgeConfig, SecondJudge: &appconfig.JudgeConfig{Model: "judge-b"}}); err == nil || !strings.Contains(err.Error(), "secondJudge requires a host url") {
+		t.Fatalf("expected an error for a second judge without a host, got %v", err)
+	}
+}
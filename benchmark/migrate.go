@@ -0,0 +1,97 @@
+// benchmark/migrate.go
+package benchmark
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/metrics"
+)
+
+// RenamedFile describes a result file moved to its canonical name by MigrateResultFiles.
+type RenamedFile struct {
+	From string
+	To   string
+}
+
+// MigrateResultFiles renames result files in dir to the canonical names produced by
+// writeResults, deriving the model set and iteration count from each file's contents.
+// Files that cannot be parsed are left untouched; existing canonical files are never overwritten.
+func MigrateResultFiles(dir string, dryRun bool) ([]RenamedFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read benchmark directory %s: %w", dir, err)
+	}
+
+	var renamed []RenamedFile
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		from := filepath.Join(dir, entry.Name())
+		target, ok := canonicalResultName(from)
+		if !ok || target == entry.Name() {
+			continue
+		}
+		to := filepath.Join(dir, target)
+		if _, err := os.Stat(to); err == nil {
+			errs = append(errs, fmt.Errorf("skipping %s: %s already exists", from, to))
+			continue
+		}
+		if !dryRun {
+			if err := os.Rename(from, to); err != nil {
+				errs = append(errs, fmt.Errorf("unable to rename %s: %w", from, err))
+				continue
+			}
+		}
+		renamed = append(renamed, RenamedFile{From: from, To: to})
+
+		// The environment snapshot and judge disagreements follow their result file.
+		for _, companion := range []func(string) string{metrics.EnvironmentPathFor, DisagreementsPathFor} {
+			companionFrom, companionTo := companion(from), companion(to)
+			if _, err := os.Stat(companionFrom); err != nil {
+				continue
+			}
+			if !dryRun {
+				if err := os.Rename(companionFrom, companionTo); err != nil {
+					errs = append(errs, fmt.Errorf("unable to rename %s: %w", companionFrom, err))
+					continue
+				}
+			}
+			renamed = append(renamed, RenamedFile{From: companionFrom, To: companionTo})
+		}
+	}
+	return renamed, errors.Join(errs...)
+}
+
+// canonicalResultName reads a result file, in the current or a legacy layout, and returns
+// the name it should have.
+func canonicalResultName(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	results, _, err := analysis.DecodeBenchmarkResults(data, false)
+	if err != nil || len(results) == 0 {
+		return "", false
+	}
+
+	names := make([]string, 0, len(results))
+	count := 0
+	tools := false
+	for name, result := range results {
+		names = append(names, name)
+		if result.BenchmarkCount > count {
+			count = result.BenchmarkCount
+		}
+		tools = tools || result.Tools
+	}
+	sort.Strings(names)
+	return resultFileName(names, count, tools), true
+}
@@ -0,0 +1,32 @@
+// benchmark/parameters.go
+package benchmark
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// parameterTemplates returns the parameter templates model is benchmarked with. Without
+// templates it returns a single unnamed template, so questions are asked with the
+// provider's default parameters.
+func parameterTemplates(cfg *appconfig.Config, model string) ([]appconfig.ParameterTemplate, error) {
+	templates := cfg.ParameterTemplatesFor(model)
+	if len(templates) == 0 {
+		return []appconfig.ParameterTemplate{{}}, nil
+	}
+	seen := make(map[string]bool)
+	for i, template := range templates {
+		name := strings.TrimSpace(template.Name)
+		if name == "" {
+			return nil, fmt.Errorf("parameter template %d for model %s has no name", i+1, model)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate parameter template %q for model %s", name, model)
+		}
+		seen[name] = true
+		templates[i].Name = name
+	}
+	return templates, nil
+}
@@ -0,0 +1,48 @@
+// benchmark/parameters_test.go
+package benchmark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestParameterTemplates verifies per-model and shared templates are combined, that
+// unnamed and duplicate templates are rejected, and that a template's parameters are sent
+// and its name recorded on the iteration.
+func TestParameterTemplates(t *testing.T) {
+	cold, hot := 0.1, 1.2
+	cfg := &appconfig.Config{ParameterTemplates: map[string][]appconfig.ParameterTemplate{
+		"llama": {{Name: "cold", Parameters: appconfig.Parameters{Temperature: &cold}}},
+		"*":     {{Name: "hot", Parameters: appconfig.Parameters{Temperature: &hot}}},
+	}}
+
+	templates, err := parameterTemplates(cfg, "llama")
+	if err != nil || len(templates) != 2 || templates[0].Name != "cold" || templates[1].Name != "hot" {
+		t.Fatalf("unexpected templates: %+v, %v", templates, err)
+	}
+	if templates, err := parameterTemplates(&appconfig.Config{}, "llama"); err != nil || len(templates) != 1 || templates[0].Name != "" {
+		t.Fatalf("expected a single unnamed template, got %+v, %v", templates, err)
+	}
+	for _, bad := range [][]appconfig.ParameterTemplate{
+		{{Name: " "}},
+		{{Name: "a"}, {Name: "a"}},
+	} {
+		cfg := &appconfig.Config{ParameterTemplates: map[string][]appconfig.ParameterTemplate{"llama": bad}}
+		if _, err := parameterTemplates(cfg, "llama"); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+
+	question := mustQuestion(t, `{"id": "sum", "prompt": "What is 2 plus 2?", "expected": 4}`)
+	provider := &scriptedProvider{replies: []string{"4"}}
+	host := appconfig.Host{Name: "gpu", Models: []string{"llama"}}
+	result, err := runQuestion(context.Background(), provider, host, question, appconfig.SystemPromptVariant{}, templates[0], nil)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+	if result.ParameterTemplate != "cold" || provider.parameters[0].Temperature == nil || *provider.parameters[0].Temperature != cold {
+		t.Fatalf("expected the cold template to be sent and recorded, got %+v / %+v", result, provider.parameters)
+	}
+}
@@ -0,0 +1,44 @@
+// benchmark/prompts.go
+package benchmark
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// systemPromptHash identifies a system prompt's text, matching the hash chat and pipeline
+// exports record.
+func systemPromptHash(prompt string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(prompt))
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// systemPromptVariants returns the system prompts model is benchmarked with, naming
+// unnamed variants by their hash. Without variants it returns a single empty variant, so
+// questions are asked without a system prompt.
+func systemPromptVariants(cfg *appconfig.Config, model string) ([]appconfig.SystemPromptVariant, error) {
+	variants := cfg.SystemPromptVariantsFor(model)
+	if len(variants) == 0 {
+		return []appconfig.SystemPromptVariant{{}}, nil
+	}
+	seen := make(map[string]bool)
+	for i, variant := range variants {
+		if strings.TrimSpace(variant.Prompt) == "" {
+			return nil, fmt.Errorf("system prompt variant %d for model %s has no prompt", i+1, model)
+		}
+		name := strings.TrimSpace(variant.Name)
+		if name == "" {
+			name = systemPromptHash(variant.Prompt)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate system prompt variant %q for model %s", name, model)
+		}
+		seen[name] = true
+		variants[i].Name = name
+	}
+	return variants, nil
+}
@@ -0,0 +1,76 @@
+// benchmark/prompts_test.go
+package benchmark
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestSystemPromptVariants verifies per-model and shared variants, hash naming, and the
+// empty variant used when none are configured.
+func TestSystemPromptVariants(t *testing.T) {
+	cfg := &appconfig.Config{SystemPromptVariants: map[string][]appconfig.SystemPromptVariant{
+		"llama": {{Name: "terse", Prompt: "Answer in one word."}},
+		"*":     {{Prompt: "Think step by step."}},
+	}}
+
+	variants, err := systemPromptVariants(cfg, "llama")
+	if err != nil {
+		t.Fatalf("systemPromptVariants returned error: %v", err)
+	}
+	if len(variants) != 2 || variants[0].Name != "terse" || variants[1].Name != systemPromptHash("Think step by step.") {
+		t.Fatalf("unexpected variants: %+v", variants)
+	}
+	if cfg.SystemPromptVariants["*"][0].Name != "" {
+		t.Fatalf("naming variants must not modify the config")
+	}
+
+	variants, err = systemPromptVariants(&appconfig.Config{}, "llama")
+	if err != nil || len(variants) != 1 || variants[0] != (appconfig.SystemPromptVariant{}) {
+		t.Fatalf("expected a single empty variant, got %+v, %v", variants, err)
+	}
+
+	for _, bad := range [][]appconfig.SystemPromptVariant{
+		{{Name: "a", Prompt: " "}},
+		{{Name: "a", Prompt: "x"}, {Name: "a", Prompt: "y"}},
+	} {
+		cfg := &appconfig.Config{SystemPromptVariants: map[string][]appconfig.SystemPromptVariant{"llama": bad}}
+		if _, err := systemPromptVariants(cfg, "llama"); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+// TestRunQuestionSystemPrompt verifies the variant's prompt is sent and recorded, and that
+// accuracy is broken down by variant.
+func TestRunQuestionSystemPrompt(t *testing.T) {
+	question := mustQuestion(t, `{"id": "sum", "prompt": "What is 2 plus 2?", "expected": 4}`)
+	host := appconfig.Host{Name: "gpu", Models: []string{"model"}}
+	terse := appconfig.SystemPromptVariant{Name: "terse", Prompt: "Answer with a number only."}
+	chatty := appconfig.SystemPromptVariant{Name: "chatty", Prompt: "Explain your reasoning."}
+
+	provider := &scriptedProvider{replies: []string{"4", "Two plus two makes five."}}
+	first, err := runQuestion(context.Background(), provider, host, question, terse, appconfig.ParameterTemplate{}, nil)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+	second, err := runQuestion(context.Background(), provider, host, question, chatty, appconfig.ParameterTemplate{}, nil)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+
+	if strings.Join(provider.systemPrompts, "|") != terse.Prompt+"|"+chatty.Prompt {
+		t.Fatalf("unexpected system prompts sent: %q", provider.systemPrompts)
+	}
+	if first.SystemPrompt != "terse" || first.SystemPromptHash != systemPromptHash(terse.Prompt) {
+		t.Fatalf("unexpected variant on result: %+v", first)
+	}
+
+	accuracy := calculateAccuracy([]IterationResult{first, second})
+	if accuracy.BySystemPrompt["terse"].Correct != 1 || accuracy.BySystemPrompt["chatty"].Scored != 1 || accuracy.BySystemPrompt["chatty"].Correct != 0 {
+		t.Fatalf("unexpected per-variant accuracy: %+v", accuracy.BySystemPrompt)
+	}
+}
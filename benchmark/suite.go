@@ -0,0 +1,326 @@
+// benchmark/suite.go
+package benchmark
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// difficulties are the accepted values of a question's difficulty.
+var difficulties = map[string]bool{"": true, "easy": true, "medium": true, "hard": true}
+
+// numberPattern matches integers and decimals, with optional thousands separators.
+var numberPattern = regexp.MustCompile(`-?\d[\d,]*(?:\.\d+)?`)
+
+// Question is one prompt of a prompt suite.
+type Question struct {
+	// ID is "<suite>/<id>", using the line number when the entry has no id.
+	ID     string `json:"id"`
+	Suite  string `json:"suite"`
+	Prompt string `json:"prompt"`
+	// Expected is the reference answer; questions without one are timed but not scored.
+	Expected   string `json:"expected,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	// Margin is the tolerance for a numeric expected answer.
+	Margin float64 `json:"margin,omitempty"`
+	// Grader decides how answers are graded; empty for unscored questions.
+	Grader string `json:"grader,omitempty"`
+	// Field is the dotted path of the value the json grader compares.
+	Field string `json:"field,omitempty"`
+	// Rubric tells the judge grader what a correct answer must contain.
+	Rubric string `json:"rubric,omitempty"`
+	// NeedsTool says whether answering needs an MCP tool; nil when the suite does not say.
+	NeedsTool *bool `json:"needsTool,omitempty"`
+	// Turns are the scripted user turns of a multi-turn question, asked in one conversation
+	// and graded one by one; Prompt and the grading fields are empty when Turns is set.
+	Turns []Question `json:"turns,omitempty"`
+
+	pattern       *regexp.Regexp
+	expectedValue any
+}
+
+// suiteEntry is one line of a prompt suite file as written by the user.
+type suiteEntry struct {
+	ID         string            `json:"id"`
+	Prompt     string            `json:"prompt"`
+	Expected   any               `json:"expected"`
+	Difficulty string            `json:"difficulty"`
+	Margin     *float64          `json:"margin"`
+	Grader     string            `json:"grader"`
+	Field      string            `json:"field"`
+	Rubric     string            `json:"rubric"`
+	NeedsTool  *bool             `json:"needsTool"`
+	Turns      []json.RawMessage `json:"turns"`
+}
+
+// builtinQuestions is the single unscored prompt used when no suites are configured.
+func builtinQuestions() []Question {
+	return []Question{{ID: "builtin", Prompt: userPrompt}}
+}
+
+// LoadSuites reads and validates JSONL prompt suites and returns their questions in file
+// order, so several suites can be mixed into one run. Every invalid line is reported.
+func LoadSuites(paths []string) ([]Question, error) {
+	var questions []Question
+	var errs []error
+	for _, path := range paths {
+		suite, err := loadSuite(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		questions = append(questions, suite...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// loadSuite reads one prompt suite file.
+func loadSuite(path string) ([]Question, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open prompt suite: %w", err)
+	}
+	defer file.Close()
+
+	suite := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var questions []Question
+	var errs []error
+	seen := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		question, err := parseSuiteEntry(suite, line, []byte(text))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", path, line, err))
+			continue
+		}
+		if first, ok := seen[question.ID]; ok {
+			errs = append(errs, fmt.Errorf("%s:%d: duplicate id %q (first on line %d)", path, line, question.ID, first))
+			continue
+		}
+		seen[question.ID] = line
+		questions = append(questions, question)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read prompt suite %s: %w", path, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("prompt suite %s has no questions", path)
+	}
+	return questions, nil
+}
+
+// parseSuiteEntry validates one suite line against the suite schema.
+func parseSuiteEntry(suite string, line int, data []byte) (Question, error) {
+	entry, err := decodeSuiteEntry(data)
+	if err != nil {
+		return Question{}, err
+	}
+	difficulty := strings.ToLower(strings.TrimSpace(entry.Difficulty))
+	if !difficulties[difficulty] {
+		return Question{}, fmt.Errorf("difficulty %q must be easy, medium or hard", entry.Difficulty)
+	}
+
+	question := Question{Suite: suite, Difficulty: difficulty}
+	id := strings.TrimSpace(entry.ID)
+	if id == "" {
+		id = strconv.Itoa(line)
+	}
+	question.ID = suite + "/" + id
+
+	if entry.Turns == nil {
+		if err := parseTurn(&question, entry); err != nil {
+			return Question{}, err
+		}
+		return question, nil
+	}
+
+	if len(entry.Turns) == 0 {
+		return Question{}, fmt.Errorf("turns must not be empty")
+	}
+	if entry.Prompt != "" || entry.Expected != nil || entry.Margin != nil || entry.Grader != "" || entry.Field != "" || entry.Rubric != "" {
+		return Question{}, fmt.Errorf("a question with turns sets prompt and grading fields on each turn")
+	}
+	if entry.NeedsTool != nil {
+		return Question{}, fmt.Errorf("a question with turns sets needsTool on each turn")
+	}
+	for n, raw := range entry.Turns {
+		turnEntry, err := decodeSuiteEntry(raw)
+		if err != nil {
+			return Question{}, fmt.Errorf("turn %d: %w", n+1, err)
+		}
+		if turnEntry.ID != "" || turnEntry.Difficulty != "" || turnEntry.Turns != nil {
+			return Question{}, fmt.Errorf("turn %d: id, difficulty and turns belong to the question", n+1)
+		}
+		turn := Question{ID: fmt.Sprintf("%s#%d", question.ID, n+1), Suite: suite, Difficulty: difficulty}
+		if err := parseTurn(&turn, turnEntry); err != nil {
+			return Question{}, fmt.Errorf("turn %d: %w", n+1, err)
+		}
+		question.Turns = append(question.Turns, turn)
+	}
+	return question, nil
+}
+
+// decodeSuiteEntry decodes one suite entry, rejecting unknown fields.
+func decodeSuiteEntry(data []byte) (suiteEntry, error) {
+	var entry suiteEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entry); err != nil {
+		return suiteEntry{}, fmt.Errorf("invalid entry: %w", err)
+	}
+	return entry, nil
+}
+
+// parseTurn validates the prompt and grading fields of a single prompt and stores them
+// on question.
+func parseTurn(question *Question, entry suiteEntry) error {
+	if strings.TrimSpace(entry.Prompt) == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	question.Prompt = entry.Prompt
+	question.NeedsTool = entry.NeedsTool
+
+	question.Field = strings.TrimSpace(entry.Field)
+	question.Rubric = strings.TrimSpace(entry.Rubric)
+	question.Grader = strings.ToLower(strings.TrimSpace(entry.Grader))
+	if question.Grader == GraderJSON {
+		if entry.Expected == nil {
+			return fmt.Errorf("the json grader requires an expected value")
+		}
+		question.expectedValue = entry.Expected
+		raw, _ := json.Marshal(entry.Expected)
+		question.Expected = string(raw)
+	} else {
+		switch expected := entry.Expected.(type) {
+		case nil:
+		case string:
+			question.Expected = strings.TrimSpace(expected)
+		case float64:
+			question.Expected = strconv.FormatFloat(expected, 'f', -1, 64)
+		default:
+			return fmt.Errorf("expected must be a string or a number")
+		}
+	}
+	if question.Grader == "" && question.Expected != "" {
+		question.Grader = GraderContains
+		if _, ok := parseNumber(question.Expected); ok {
+			question.Grader = GraderNumeric
+		}
+	}
+
+	switch question.Grader {
+	case "":
+	case GraderContains, GraderExact:
+		if question.Expected == "" {
+			return fmt.Errorf("the %s grader requires an expected answer", question.Grader)
+		}
+	case GraderNumeric:
+		if _, ok := parseNumber(question.Expected); !ok {
+			return fmt.Errorf("the numeric grader requires a numeric expected answer")
+		}
+	case GraderRegex:
+		pattern, err := regexp.Compile(question.Expected)
+		if question.Expected == "" || err != nil {
+			return fmt.Errorf("the regex grader requires a valid pattern as expected answer: %v", err)
+		}
+		question.pattern = pattern
+	case GraderJSON:
+	case GraderJudge:
+		if question.Expected == "" && question.Rubric == "" {
+			return fmt.Errorf("the judge grader requires an expected answer or a rubric")
+		}
+	default:
+		return fmt.Errorf("grader %q must be contains, exact, numeric, regex, json or judge", entry.Grader)
+	}
+	if question.Field != "" && question.Grader != GraderJSON {
+		return fmt.Errorf("field is only used by the json grader")
+	}
+	if question.Rubric != "" && question.Grader != GraderJudge {
+		return fmt.Errorf("rubric is only used by the judge grader")
+	}
+	if entry.Margin != nil {
+		if *entry.Margin < 0 {
+			return fmt.Errorf("margin must not be negative")
+		}
+		if question.Grader != GraderNumeric && question.Grader != GraderJSON {
+			return fmt.Errorf("margin requires a numeric expected answer")
+		}
+		question.Margin = *entry.Margin
+	}
+	return nil
+}
+
+// usesJudge reports whether any question or turn is graded by the judge.
+func usesJudge(questions []Question) bool {
+	for _, question := range questions {
+		if question.Grader == GraderJudge || usesJudge(question.Turns) {
+			return true
+		}
+	}
+	return false
+}
+
+// turns returns the prompts asked for the question: its turns, or the question itself.
+func (q Question) turns() []Question {
+	if len(q.Turns) > 0 {
+		return q.Turns
+	}
+	return []Question{q}
+}
+
+// parseNumber parses a number, ignoring thousands separators.
+func parseNumber(text string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", ""), 64)
+	return value, err == nil
+}
+
+// normalizeAnswer lowercases text and collapses whitespace for comparison.
+func normalizeAnswer(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// calculateAccuracy summarizes the scored iterations of a result, overall and per suite,
+// difficulty and system prompt variant. Each scored turn of a multi-turn question counts as one answer and is
+// also tallied by turn number. It returns nil when nothing was scored.
+func calculateAccuracy(iterations []IterationResult) *analysis.AccuracyStats {
+	var stats analysis.AccuracyStats
+	for _, iteration := range iterations {
+		if len(iteration.Turns) > 0 {
+			for _, turn := range iteration.Turns {
+				if turn.Correct != nil {
+					stats.AddTurn(iteration.Suite, iteration.Difficulty, iteration.SystemPrompt, turn.Turn, *turn.Correct)
+				}
+			}
+			continue
+		}
+		if iteration.Correct == nil {
+			continue
+		}
+		stats.Add(iteration.Suite, iteration.Difficulty, iteration.SystemPrompt, *iteration.Correct)
+	}
+	if stats.Scored == 0 {
+		return nil
+	}
+	return &stats
+}
@@ -0,0 +1,98 @@
+// benchmark/suite_test.go
+package benchmark
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSuite writes a prompt suite file into dir and returns its path.
+func writeSuite(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write suite: %v", err)
+	}
+	return path
+}
+
+// TestLoadSuitesMixesFiles verifies that several suites load in order with suite-qualified IDs.
+func TestLoadSuitesMixesFiles(t *testing.T) {
+	dir := t.TempDir()
+	math := writeSuite(t, dir, "math.jsonl", `# arithmetic
+{"id": "add", "prompt": "2+2?", "expected": 4, "difficulty": "Easy"}
+
+{"prompt": "Pi to two decimals?", "expected": "3.14", "margin": 0.01}
+`)
+	trivia := writeSuite(t, dir, "trivia.jsonl", `{"id": "fr", "prompt": "Capital of France?", "expected": "Paris", "difficulty": "medium"}
+{"prompt": "Write a poem."}
+`)
+
+	questions, err := LoadSuites([]string{math, trivia})
+	if err != nil {
+		t.Fatalf("LoadSuites returned error: %v", err)
+	}
+	var ids []string
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	if got := strings.Join(ids, ","); got != "math/add,math/4,trivia/fr,trivia/2" {
+		t.Fatalf("unexpected question ids: %s", got)
+	}
+	if questions[0].Expected != "4" || questions[0].Difficulty != "easy" || questions[1].Margin != 0.01 || questions[3].Expected != "" {
+		t.Fatalf("unexpected questions: %+v", questions)
+	}
+}
+
+// TestLoadSuitesRejectsInvalidEntries verifies that every schema violation is reported with its line.
+func TestLoadSuitesRejectsInvalidEntries(t *testing.T) {
+	path := writeSuite(t, t.TempDir(), "bad.jsonl", `{"id": "a", "prompt": "ok"}
+{"id": "a", "prompt": "duplicate"}
+{"prompt": "x", "answer": "typo"}
+{"expected": "no prompt"}
+{"prompt": "x", "difficulty": "extreme"}
+{"prompt": "x", "expected": "blue", "margin": 1}
+{"prompt": "x", "expected": 3, "margin": -1}
+{"prompt": "x", "expected": [1, 2]}
+`)
+
+	_, err := LoadSuites([]string{path})
+	if err == nil {
+		t.Fatalf("expected invalid suite to be rejected")
+	}
+	for _, want := range []string{
+		"bad.jsonl:2: duplicate id",
+		`bad.jsonl:3: invalid entry: json: unknown field "answer"`,
+		"bad.jsonl:4: prompt is required",
+		"bad.jsonl:5: difficulty",
+		"bad.jsonl:6: margin requires a numeric expected answer",
+		"bad.jsonl:7: margin must not be negative",
+		"bad.jsonl:8: expected must be a string or a number",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+// TestCalculateAccuracy verifies accuracy totals per suite and difficulty, ignoring unscored iterations.
+func TestCalculateAccuracy(t *testing.T) {
+	yes, no := true, false
+	stats := calculateAccuracy([]IterationResult{
+		{Suite: "math", Difficulty: "easy", Correct: &yes},
+		{Suite: "math", Difficulty: "hard", Correct: &no},
+		{Suite: "trivia", Difficulty: "easy", Correct: &yes},
+		{Suite: "trivia"},
+	})
+	if stats == nil || stats.Scored != 3 || stats.Correct != 2 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if stats.BySuite["math"].Correct != 1 || stats.ByDifficulty["easy"].Correct != 2 || stats.ByDifficulty["hard"].Scored != 1 {
+		t.Fatalf("unexpected breakdown: %+v", stats)
+	}
+	if calculateAccuracy([]IterationResult{{Suite: "x"}}) != nil {
+		t.Fatalf("expected nil accuracy without scored iterations")
+	}
+}
@@ -0,0 +1,182 @@
+// benchmark/turns.go
+package benchmark
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// runQuestion asks every turn of question in one conversation under the variant's system
+// prompt and the template's parameters, each turn seeing the earlier prompts and answers,
+// and grades each answer. A single-prompt question yields one iteration with that
+// prompt's stats; a multi-turn question also records every turn. Answers the judge and
+// second judge graded differently are kept for the disagreements file.
+func runQuestion(ctx context.Context, provider providers.ChatProvider, host appconfig.Host, question Question, variant appconfig.SystemPromptVariant, template appconfig.ParameterTemplate, judge *Judge) (IterationResult, error) {
+	result := IterationResult{
+		QuestionID:        question.ID,
+		Suite:             question.Suite,
+		Difficulty:        question.Difficulty,
+		SystemPrompt:      variant.Name,
+		ParameterTemplate: template.Name,
+	}
+	if variant.Prompt != "" {
+		result.SystemPromptHash = systemPromptHash(variant.Prompt)
+	}
+
+	var history []providers.ChatMessage
+	var turns []TurnResult
+	for n, turn := range question.turns() {
+		history = append(history, providers.ChatMessage{Role: "user", Content: turn.Prompt})
+		stats, answer, toolCalls, err := streamTurn(ctx, provider, host, variant.Prompt, template.Parameters, history)
+		if err != nil {
+			return IterationResult{}, err
+		}
+		history = append(history, providers.ChatMessage{Role: "assistant", Content: answer})
+
+		turnResult := TurnResult{Turn: n + 1, Stats: stats, ToolCalls: toolCalls, ToolNeeded: turn.NeedsTool}
+		grade, scored, err := turn.Grade(ctx, answer, judge)
+		if err != nil {
+			log.Printf("error grading %s for model %s on host %s: %v", turn.ID, host.Models[0], host.Name, err)
+		} else if scored {
+			turnResult.Correct = &grade.Correct
+			turnResult.Grader = grade.Grader
+			turnResult.GradeReason = grade.Reason
+			turnResult.SecondOpinion, turnResult.SecondReason = grade.SecondOpinion, grade.SecondReason
+			if grade.Disagrees() {
+				disagreement := JudgeDisagreement{
+					QuestionID:        question.ID,
+					Suite:             question.Suite,
+					SystemPrompt:      variant.Name,
+					ParameterTemplate: template.Name,
+					Answer:            answer,
+					Judge:             judge.model,
+					Correct:           grade.Correct,
+					Reason:            grade.Reason,
+					SecondJudge:       judge.second.model,
+					SecondOpinion:     *grade.SecondOpinion,
+					SecondReason:      grade.SecondReason,
+				}
+				if len(question.Turns) > 0 {
+					disagreement.Turn = n + 1
+				}
+				result.disagreements = append(result.disagreements, disagreement)
+			}
+		}
+		turns = append(turns, turnResult)
+	}
+
+	if len(question.Turns) == 0 {
+		only := turns[0]
+		result.Stats, result.Correct, result.Grader, result.GradeReason = only.Stats, only.Correct, only.Grader, only.GradeReason
+		result.ToolCalls, result.ToolNeeded = only.ToolCalls, only.ToolNeeded
+		result.SecondOpinion, result.SecondReason = only.SecondOpinion, only.SecondReason
+		return result, nil
+	}
+
+	result.Turns = turns
+	result.Stats = sumTurnStats(turns)
+	secondOpinion, seconded := true, false
+	for _, turn := range turns {
+		result.ToolCalls = append(result.ToolCalls, turn.ToolCalls...)
+		if turn.ToolNeeded != nil && (result.ToolNeeded == nil || *turn.ToolNeeded) {
+			needed := *turn.ToolNeeded
+			result.ToolNeeded = &needed
+		}
+		if turn.Correct == nil {
+			continue
+		}
+		if result.Correct == nil {
+			passed := true
+			result.Correct = &passed
+		}
+		*result.Correct = *result.Correct && *turn.Correct
+		verdict := *turn.Correct
+		if turn.SecondOpinion != nil {
+			verdict, seconded = *turn.SecondOpinion, true
+		}
+		secondOpinion = secondOpinion && verdict
+	}
+	if seconded {
+		result.SecondOpinion = &secondOpinion
+	}
+	return result, nil
+}
+
+// streamTurn sends the conversation so far with parameters, times the reply and returns
+// it with the tools called while answering, which only happens in MCP mode.
+func streamTurn(ctx context.Context, provider providers.ChatProvider, host appconfig.Host, systemPrompt string, parameters appconfig.Parameters, history []providers.ChatMessage) (IterationStats, string, []string, error) {
+	startTime := time.Now()
+	var timeToFirstToken time.Duration
+	firstChunk := true
+
+	var outputTokens int
+	var inputTokens int
+	var answer strings.Builder
+	var toolCalls []string
+
+	req := providers.StreamRequest{
+		Host:         host,
+		Model:        host.Models[0],
+		SystemPrompt: systemPrompt,
+		History:      history,
+		Parameters:   parameters,
+	}
+
+	callbacks := providers.StreamCallbacks{
+		OnChunk: func(chunk providers.ChatMessage) error {
+			answer.WriteString(chunk.Content)
+			if firstChunk {
+				timeToFirstToken = time.Since(startTime)
+				firstChunk = false
+				log.Printf("First chunk received for model %s on host %s after %s", host.Models[0], host.Name, timeToFirstToken)
+			}
+			return nil
+		},
+		OnComplete: func(meta providers.StreamMetadata) error {
+			outputTokens = meta.EvalCount
+			inputTokens = meta.PromptEvalCount
+			toolCalls = meta.ToolCalls
+			if ttft := meta.TimeToFirstToken(); ttft > 0 {
+				timeToFirstToken = ttft
+			}
+			return nil
+		},
+	}
+
+	if err := provider.Stream(ctx, req, callbacks); err != nil {
+		return IterationStats{}, "", nil, err
+	}
+
+	totalExecutionTime := time.Since(startTime)
+	return IterationStats{
+		TotalExecutionTime: totalExecutionTime,
+		TimeToFirstToken:   timeToFirstToken,
+		TokensPerSecond:    float64(outputTokens) / totalExecutionTime.Seconds(),
+		InputTokenCount:    inputTokens,
+		OutputTokenCount:   outputTokens,
+	}, answer.String(), toolCalls, nil
+}
+
+// sumTurnStats combines turn stats into iteration stats: times and token counts are
+// summed, time to first token is averaged, and tokens per second covers all turns.
+func sumTurnStats(turns []TurnResult) IterationStats {
+	var stats IterationStats
+	for _, turn := range turns {
+		stats.TotalExecutionTime += turn.Stats.TotalExecutionTime
+		stats.TimeToFirstToken += turn.Stats.TimeToFirstToken
+		stats.InputTokenCount += turn.Stats.InputTokenCount
+		stats.OutputTokenCount += turn.Stats.OutputTokenCount
+	}
+	if len(turns) > 0 {
+		stats.TimeToFirstToken /= time.Duration(len(turns))
+	}
+	if stats.TotalExecutionTime > 0 {
+		stats.TokensPerSecond = float64(stats.OutputTokenCount) / stats.TotalExecutionTime.Seconds()
+	}
+	return stats
+}
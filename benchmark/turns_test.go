@@ -0,0 +1,179 @@
+// benchmark/turns_test.go
+package benchmark
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// scriptedProvider answers each request with the next scripted reply, reporting the next
+// scripted tool calls, and records the conversation, system prompt and parameters it was sent.
+type scriptedProvider struct {
+	replies       []string
+	toolCalls     [][]string
+	histories     [][]providers.ChatMessage
+	systemPrompts []string
+	parameters    []appconfig.Parameters
+}
+
+func (p *scriptedProvider) LoadedModels(context.Context, appconfig.Host) ([]string, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) EnsureModelReady(context.Context, appconfig.Host, string) error {
+	return nil
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
+	p.histories = append(p.histories, append([]providers.ChatMessage(nil), req.History...))
+	p.systemPrompts = append(p.systemPrompts, req.SystemPrompt)
+	p.parameters = append(p.parameters, req.Parameters)
+	reply := p.replies[len(p.histories)-1]
+	time.Sleep(time.Millisecond)
+	if err := callbacks.OnChunk(providers.ChatMessage{Role: "assistant", Content: reply}); err != nil {
+		return err
+	}
+	meta := providers.StreamMetadata{EvalCount: 10, PromptEvalCount: 5 * len(p.histories)}
+	if n := len(p.histories) - 1; n < len(p.toolCalls) {
+		meta.ToolCalls = p.toolCalls[n]
+	}
+	if callbacks.OnComplete == nil {
+		return nil
+	}
+	return callbacks.OnComplete(meta)
+}
+
+func (p *scriptedProvider) Close() error {
+	return nil
+}
+
+// TestRunQuestionMultiTurn verifies that turns share one conversation, that each turn is
+// graded and timed, and that the iteration sums the turns and passes only if all do.
+func TestRunQuestionMultiTurn(t *testing.T) {
+	question := mustQuestion(t, `{"id": "recall", "difficulty": "medium", "turns": [
+		{"prompt": "My name is Ada. Reply OK."},
+		{"prompt": "What is 6 times 7?", "expected": 42},
+		{"prompt": "What is my name?", "expected": "Ada"}
+	]}`)
+	provider := &scriptedProvider{replies: []string{"OK", "It is 42.", "I don't know."}}
+	host := appconfig.Host{Name: "gpu", Models: []string{"model"}}
+
+	result, err := runQuestion(context.Background(), provider, host, question, appconfig.SystemPromptVariant{}, appconfig.ParameterTemplate{}, nil)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+
+	last := provider.histories[2]
+	if len(last) != 5 || last[1].Content != "OK" || last[3].Content != "It is 42." || last[4].Content != "What is my name?" {
+		t.Fatalf("expected the third turn to carry the whole conversation, got %+v", last)
+	}
+	if len(result.Turns) != 3 || result.Turns[0].Correct != nil || !*result.Turns[1].Correct || *result.Turns[2].Correct {
+		t.Fatalf("unexpected turn grades: %+v", result.Turns)
+	}
+	if result.Turns[1].Grader != GraderNumeric || result.Turns[2].Grader != GraderContains {
+		t.Fatalf("unexpected turn graders: %+v", result.Turns)
+	}
+	if result.Correct == nil || *result.Correct {
+		t.Fatalf("expected the conversation to fail because a turn failed")
+	}
+	if result.Stats.OutputTokenCount != 30 || result.Stats.InputTokenCount != 30 || result.Stats.TotalExecutionTime <= 0 {
+		t.Fatalf("unexpected summed stats: %+v", result.Stats)
+	}
+
+	accuracy := calculateAccuracy([]IterationResult{result})
+	if accuracy.Scored != 2 || accuracy.Correct != 1 || accuracy.ByTurn["2"].Correct != 1 || accuracy.ByTurn["3"].Scored != 1 || accuracy.ByTurn["3"].Correct != 0 {
+		t.Fatalf("unexpected per-turn accuracy: %+v", accuracy)
+	}
+}
+
+// TestParseSuiteEntryTurns verifies turn IDs and the multi-turn schema errors.
+func TestParseSuiteEntryTurns(t *testing.T) {
+	question := mustQuestion(t, `{"id": "chat", "difficulty": "hard", "turns": [{"prompt": "a"}, {"prompt": "b", "expected": "x"}]}`)
+	if len(question.Turns) != 2 || question.Turns[1].ID != "suite/chat#2" || question.Turns[1].Difficulty != "hard" || question.Turns[1].Grader != GraderContains {
+		t.Fatalf("unexpected turns: %+v", question.Turns)
+	}
+
+	for line, want := range map[string]string{
+		`{"turns": []}`: "turns must not be empty",
+		`{"prompt": "p", "turns": [{"prompt": "a"}]}`:     "sets prompt and grading fields on each turn",
+		`{"turns": [{"prompt": "a"}, {"expected": "x"}]}`: "turn 2: prompt is required",
+		`{"turns": [{"prompt": "a", "id": "x"}]}`:         "turn 1: id, difficulty and turns belong to the question",
+		`{"turns": [{"prompt": "a", "answer": "x"}]}`:     `turn 1: invalid entry: json: unknown field "answer"`,
+		`{"needsTool": true, "turns": [{"prompt": "a"}]}`: "sets needsTool on each turn",
+	} {
+		_, err := parseSuiteEntry("suite", 1, []byte(line))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("parseSuiteEntry(%s) error = %v, want it to mention %q", line, err, want)
+		}
+	}
+}
+
+// TestRunQuestionToolCalls verifies the tools called on each turn are recorded with the
+// suite's needsTool, and that the iteration needs a tool when any turn does.
+func TestRunQuestionToolCalls(t *testing.T) {
+	question := mustQuestion(t, `{"id": "trip", "turns": [
+		{"prompt": "Hi, I'm in Paris.", "needsTool": false},
+		{"prompt": "What's the weather here?", "needsTool": true}
+	]}`)
+	provider := &scriptedProvider{replies: []string{"Hello!", "Sunny."}, toolCalls: [][]string{nil, {"weather"}}}
+	host := appconfig.Host{Name: "gpu", Models: []string{"model"}}
+
+	result, err := runQuestion(context.Background(), provider, host, question, appconfig.SystemPromptVariant{}, appconfig.ParameterTemplate{}, nil)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+	if len(result.Turns[0].ToolCalls) != 0 || *result.Turns[0].ToolNeeded || result.Turns[1].ToolCalls[0] != "weather" || !*result.Turns[1].ToolNeeded {
+		t.Fatalf("unexpected turns: %+v", result.Turns)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolNeeded == nil || !*result.ToolNeeded {
+		t.Fatalf("unexpected iteration tool use: calls %v, needed %v", result.ToolCalls, result.ToolNeeded)
+	}
+
+	single := mustQuestion(t, `{"id": "sum", "prompt": "What is 2+2?", "expected": 4}`)
+	plain, err := runQuestion(context.Background(), &scriptedProvider{replies: []string{"4"}}, host, single, appconfig.SystemPromptVariant{}, appconfig.ParameterTemplate{}, nil)
+	if err != nil || plain.ToolCalls != nil || plain.ToolNeeded != nil {
+		t.Fatalf("expected no tool use recorded, got %+v, %v", plain, err)
+	}
+}
+
+// TestRunQuestionSecondOpinion verifies that a second judge grades every judged turn, that
+// the answers the judges disagree on are kept for the disagreements file, and that a
+// multi-turn iteration combines the second judge's verdicts.
+func TestRunQuestionSecondOpinion(t *testing.T) {
+	question := mustQuestion(t, `{"id": "fruit", "turns": [
+		{"prompt": "Name a red fruit.", "grader": "judge", "rubric": "Any red fruit."},
+		{"prompt": "Name a yellow fruit.", "grader": "judge", "rubric": "Any yellow fruit."}
+	]}`)
+	judge := &Judge{
+		provider: &scriptedProvider{replies: []string{`{"correct": true, "reason": "A red fruit."}`, `{"correct": true, "reason": "A yellow fruit."}`}},
+		model:    "judge-a",
+		second: &Judge{
+			provider: &scriptedProvider{replies: []string{`{"correct": true, "reason": "Red."}`, `{"correct": false, "reason": "Lemons are not fruit."}`}},
+			model:    "judge-b",
+		},
+	}
+	host := appconfig.Host{Name: "gpu", Models: []string{"model"}}
+
+	result, err := runQuestion(context.Background(), &scriptedProvider{replies: []string{"Cherry.", "Lemon."}}, host, question, appconfig.SystemPromptVariant{}, appconfig.ParameterTemplate{}, judge)
+	if err != nil {
+		t.Fatalf("runQuestion returned error: %v", err)
+	}
+	if !*result.Turns[0].SecondOpinion || *result.Turns[1].SecondOpinion || result.Turns[1].SecondReason != "Lemons are not fruit." {
+		t.Fatalf("unexpected turns: %+v", result.Turns)
+	}
+	if !*result.Correct || result.SecondOpinion == nil || *result.SecondOpinion {
+		t.Fatalf("expected the judges to disagree on the iteration, got correct %v, second opinion %v", *result.Correct, result.SecondOpinion)
+	}
+	if len(result.disagreements) != 1 {
+		t.Fatalf("expected one disagreement, got %+v", result.disagreements)
+	}
+	disagreement := result.disagreements[0]
+	if disagreement.QuestionID != "suite/fruit" || disagreement.Turn != 2 || disagreement.Answer != "Lemon." || disagreement.Judge != "judge-a" || disagreement.SecondJudge != "judge-b" || !disagreement.Correct || disagreement.SecondOpinion {
+		t.Fatalf("unexpected disagreement %+v", disagreement)
+	}
+}
@@ -1,22 +1,94 @@
 // benchmark/types.go
 package benchmark
 
-import "time"
+import (
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+)
 
 // BenchmarkResult holds the aggregated results for a single model's benchmark.
 type BenchmarkResult struct {
-	ModelName      string            `json:"modelName"`
-	BenchmarkCount int               `json:"benchmarkCount"`
-	AverageStats   IterationStats    `json:"averageStats"`
-	MinStats       IterationStats    `json:"minStats"`
-	MaxStats       IterationStats    `json:"maxStats"`
-	Iterations     []IterationResult `json:"iterations"`
+	ModelName      string         `json:"modelName"`
+	BenchmarkCount int            `json:"benchmarkCount"`
+	AverageStats   IterationStats `json:"averageStats"`
+	MinStats       IterationStats `json:"minStats"`
+	MaxStats       IterationStats `json:"maxStats"`
+	// P50Stats to P99Stats are percentiles of the averaged iterations.
+	P50Stats   *IterationStats   `json:"p50Stats,omitempty"`
+	P90Stats   *IterationStats   `json:"p90Stats,omitempty"`
+	P95Stats   *IterationStats   `json:"p95Stats,omitempty"`
+	P99Stats   *IterationStats   `json:"p99Stats,omitempty"`
+	Iterations []IterationResult `json:"iterations"`
+	// Accuracy is set when prompt-suite questions with expected answers were asked.
+	Accuracy *analysis.AccuracyStats `json:"accuracy,omitempty"`
+	// Concurrency is how many questions ran at once on the host, when more than one.
+	Concurrency int `json:"concurrency,omitempty"`
+	// ColdStart records the model's load time and first iteration, before it was warm.
+	ColdStart *analysis.ColdStartStats `json:"coldStart,omitempty"`
+	// Tools reports that the model could call MCP tools while answering.
+	Tools bool `json:"tools,omitempty"`
 }
 
 // IterationResult holds the statistics for a single benchmark iteration.
 type IterationResult struct {
 	Iteration int            `json:"iteration"`
 	Stats     IterationStats `json:"stats"`
+	// QuestionID, Suite and Difficulty identify the prompt-suite question asked, if any.
+	QuestionID string `json:"questionId,omitempty"`
+	Suite      string `json:"suite,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	// Correct reports whether the answer matched; it is nil for unscored questions.
+	Correct *bool `json:"correct,omitempty"`
+	// Grader is the grader that scored the answer and GradeReason the judge's explanation.
+	Grader      string `json:"grader,omitempty"`
+	GradeReason string `json:"gradeReason,omitempty"`
+	// SystemPrompt names the system prompt variant the question was asked with and
+	// SystemPromptHash identifies its text; both are empty when no variants are configured.
+	SystemPrompt     string `json:"systemPrompt,omitempty"`
+	SystemPromptHash string `json:"systemPromptHash,omitempty"`
+	// ParameterTemplate names the parameter template the question was asked with; it is
+	// empty when no templates are configured.
+	ParameterTemplate string `json:"parameterTemplate,omitempty"`
+	// Turns holds each turn of a multi-turn question; Stats then sums them and Correct
+	// reports whether every scored turn passed.
+	Turns []TurnResult `json:"turns,omitempty"`
+	// Contended reports that the iteration overlapped another on the same host, so its
+	// timings were measured while the host was shared.
+	Contended bool `json:"contended,omitempty"`
+	// Warmup reports that the iteration was left out of the averages as a warm-up.
+	Warmup bool `json:"warmup,omitempty"`
+	// RecordID names the iteration's raw archive, when raw archiving is on.
+	RecordID string `json:"recordId,omitempty"`
+	// ToolCalls names the MCP tools the model called while answering, in order; for a
+	// multi-turn question, across all turns.
+	ToolCalls []string `json:"toolCalls,omitempty"`
+	// ToolNeeded is the suite's needsTool for the question, true when any turn needs a
+	// tool; nil when the suite does not say.
+	ToolNeeded *bool `json:"toolNeeded,omitempty"`
+	// SecondOpinion is the second judge's verdict, when a second judge graded the answer,
+	// and SecondReason its explanation. For a multi-turn question it is the verdict on
+	// every scored turn, taking the first judge's for turns the second did not grade.
+	SecondOpinion *bool  `json:"secondOpinion,omitempty"`
+	SecondReason  string `json:"secondReason,omitempty"`
+
+	// disagreements are the answers the two judges graded differently, for the
+	// disagreements file.
+	disagreements []JudgeDisagreement
+}
+
+// TurnResult holds the statistics and grade of one turn of a multi-turn question.
+type TurnResult struct {
+	Turn        int            `json:"turn"`
+	Stats       IterationStats `json:"stats"`
+	Correct     *bool          `json:"correct,omitempty"`
+	Grader      string         `json:"grader,omitempty"`
+	GradeReason string         `json:"gradeReason,omitempty"`
+	ToolCalls   []string       `json:"toolCalls,omitempty"`
+	ToolNeeded  *bool          `json:"toolNeeded,omitempty"`
+	// SecondOpinion and SecondReason are the second judge's verdict and explanation.
+	SecondOpinion *bool  `json:"secondOpinion,omitempty"`
+	SecondReason  string `json:"secondReason,omitempty"`
 }
 
 // IterationStats contains the detailed performance metrics for one iteration.
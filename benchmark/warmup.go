@@ -0,0 +1,49 @@
+// benchmark/warmup.go
+package benchmark
+
+import (
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// markWarmup flags the iterations numbered up to warmup, the benchmarkWarmup setting, as
+// warm-up so they are left out of the averages, and returns how many were flagged. The
+// count is capped so at least one iteration is left to average.
+func markWarmup(iterations []IterationResult, warmup int) int {
+	if warmup >= len(iterations) {
+		warmup = len(iterations) - 1
+	}
+	marked := 0
+	for i := range iterations {
+		if iterations[i].Iteration <= warmup {
+			iterations[i].Warmup = true
+			marked++
+		}
+	}
+	return marked
+}
+
+// steadyIterations returns the iterations that are not warm-up.
+func steadyIterations(iterations []IterationResult) []IterationResult {
+	steady := make([]IterationResult, 0, len(iterations))
+	for _, iter := range iterations {
+		if !iter.Warmup {
+			steady = append(steady, iter)
+		}
+	}
+	return steady
+}
+
+// coldStart records the model's load time and the timings of its first iteration, if
+// that iteration succeeded.
+func coldStart(iterations []IterationResult, loadTime time.Duration, warmup int) *analysis.ColdStartStats {
+	stats := &analysis.ColdStartStats{LoadTime: int64(loadTime), WarmupIterations: warmup}
+	for _, iter := range iterations {
+		if iter.Iteration == 1 {
+			stats.FirstTimeToFirstToken = int64(iter.Stats.TimeToFirstToken)
+			stats.FirstTotalExecutionTime = int64(iter.Stats.TotalExecutionTime)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,40 @@
+// benchmark/warmup_test.go
+package benchmark
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWarmupExcludedFromAggregates verifies warm-up iterations are flagged and left out of
+// the averages, that the cold start keeps the first iteration, and that at least one
+// iteration is always averaged.
+func TestWarmupExcludedFromAggregates(t *testing.T) {
+	iteration := func(n int, ttft time.Duration, tps float64) IterationResult {
+		return IterationResult{Iteration: n, Stats: IterationStats{TimeToFirstToken: ttft, TotalExecutionTime: 2 * ttft, TokensPerSecond: tps}}
+	}
+	result := &BenchmarkResult{Iterations: []IterationResult{
+		iteration(1, 9*time.Second, 5),
+		iteration(2, time.Second, 40),
+		iteration(3, time.Second, 50),
+	}}
+
+	warmup := markWarmup(result.Iterations, 1)
+	result.ColdStart = coldStart(result.Iterations, 3*time.Second, warmup)
+	calculateAggregates(result)
+
+	if warmup != 1 || !result.Iterations[0].Warmup || result.Iterations[1].Warmup {
+		t.Fatalf("unexpected warm-up flags: %+v", result.Iterations)
+	}
+	if result.AverageStats.TokensPerSecond != 45 || result.MinStats.TokensPerSecond != 40 || result.AverageStats.TimeToFirstToken != time.Second {
+		t.Fatalf("expected the warm-up iteration to be left out, got %+v / %+v", result.AverageStats, result.MinStats)
+	}
+	cs := result.ColdStart
+	if cs.LoadTime != int64(3*time.Second) || cs.FirstTimeToFirstToken != int64(9*time.Second) || cs.WarmupIterations != 1 {
+		t.Fatalf("unexpected cold start: %+v", cs)
+	}
+
+	if got := markWarmup(result.Iterations, 10); got != 2 || result.Iterations[2].Warmup {
+		t.Fatalf("expected the last iteration to stay averaged, marked %d", got)
+	}
+}
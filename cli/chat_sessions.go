@@ -0,0 +1,182 @@
+// cli/chat_sessions.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/sessions"
+)
+
+// sessionsKey opens the session browser from the pickers and the chat view.
+var sessionsKey = key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "sessions"))
+
+// hostPickerHelp exposes the model refresh and session browser keys in the host picker.
+func hostPickerHelp() []key.Binding {
+	return []key.Binding{refreshModelsKey, sessionsKey}
+}
+
+// sessionItem renders a recorded session inside the session browser.
+type sessionItem struct {
+	summary sessions.Summary
+}
+
+// Title returns the session's first prompt, or its ID when it has none.
+func (i sessionItem) Title() string {
+	if i.summary.Preview != "" {
+		return i.summary.Preview
+	}
+	return i.summary.ID
+}
+
+// Description returns where and when the session ran.
+func (i sessionItem) Description() string {
+	return fmt.Sprintf("%s • %s • %d messages • %s • %s", i.summary.Host, i.summary.Model, i.summary.Messages,
+		i.summary.Updated.Local().Format("2006-01-02 15:04"), i.summary.ID)
+}
+
+// FilterValue returns the text the browser filters on.
+func (i sessionItem) FilterValue() string {
+	return i.summary.Preview + " " + i.summary.Host + " " + i.summary.Model + " " + i.summary.ID
+}
+
+// newSessionStore returns the configured session store, or nil when recording is disabled.
+func newSessionStore(cfg *Config) *sessions.Store {
+	if cfg == nil {
+		return nil
+	}
+	dir := cfg.SessionsPath()
+	if dir == "" {
+		return nil
+	}
+	return sessions.NewStore(dir)
+}
+
+// recordMessage appends a chat message to the current session, starting one on the first
+// message. params are recorded with generated messages and are nil for the user's. The
+// session follows the chat across host and model switches.
+func (m *model) recordMessage(role, content string, params *messageParams) {
+	if m.sessionStore == nil {
+		return
+	}
+	if m.session == nil {
+		m.session = m.sessionStore.Create(m.selectedHost.Name, m.selectedModel)
+	}
+	m.session.Host, m.session.Model = m.selectedHost.Name, m.selectedModel
+	var err error
+	if params != nil {
+		err = m.session.AppendGenerated(role, content, params.sessionParams())
+	} else {
+		err = m.session.Append(role, content)
+	}
+	if err != nil {
+		logging.LogError("chat session: %v", err)
+	}
+}
+
+// openSessionBrowser lists the recorded sessions, newest first.
+func (m *model) openSessionBrowser() tea.Cmd {
+	if m.sessionStore == nil {
+		m.err = fmt.Errorf("session recording is disabled (disableSessions is set)")
+		return nil
+	}
+	summaries, err := m.sessionStore.List()
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	items := make([]list.Item, len(summaries))
+	for i, summary := range summaries {
+		items[i] = sessionItem{summary: summary}
+	}
+	m.sessionList.Title = fmt.Sprintf("Resume a Session (%d recorded)", len(summaries))
+	m.browserReturnState = m.state
+	m.state = viewSessionBrowser
+	m.textArea.Blur()
+	return m.sessionList.SetItems(items)
+}
+
+// closeSessionBrowser returns to the view the browser was opened from.
+func (m *model) closeSessionBrowser() {
+	m.state = m.browserReturnState
+	if m.state == viewChat {
+		m.textArea.Focus()
+	}
+}
+
+// updateSessionBrowser handles keys while the session browser is open.
+func (m *model) updateSessionBrowser(msg tea.Msg) tea.Cmd {
+	if km, ok := msg.(tea.KeyMsg); ok && m.sessionList.FilterState() != list.Filtering {
+		switch km.String() {
+		case "esc":
+			m.closeSessionBrowser()
+			return nil
+		case "enter":
+			selected, ok := m.sessionList.SelectedItem().(sessionItem)
+			if !ok {
+				return nil
+			}
+			if err := m.resumeSession(selected.summary.ID); err != nil {
+				m.err = err
+				return nil
+			}
+			return tea.Batch(m.spinner.Tick, loadModelCmd(m.selectedHost, m.selectedModel, m.provider), tickCmd())
+		}
+	}
+	var cmd tea.Cmd
+	m.sessionList, cmd = m.sessionList.Update(msg)
+	return cmd
+}
+
+// resumeSession restores a recorded conversation and points the chat at the host and model
+// it last used. The caller loads the model; new messages are appended to the same session.
+func (m *model) resumeSession(id string) error {
+	if m.sessionStore == nil {
+		return fmt.Errorf("session recording is disabled (disableSessions is set)")
+	}
+	session, entries, err := m.sessionStore.Open(strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	host, ok := m.findHost(session.Host)
+	if !ok {
+		return fmt.Errorf("session %s used host %q, which is not in the config", session.ID, session.Host)
+	}
+
+	history := make([]chatMessage, 0, len(entries))
+	params := make(map[int]messageParams)
+	for i, entry := range entries {
+		history = append(history, chatMessage{Role: entry.Role, Content: entry.Content})
+		if p, ok := entryMessageParams(entry); ok {
+			params[i] = p
+		}
+	}
+	m.session = session
+	m.chatHistory = history
+	m.context = contextState{}
+	m.messageParams = params
+	m.responseBuf.Reset()
+	m.responseMeta = LLMResponseMeta{}
+	m.selectedHost = host
+	m.selectedModel = session.Model
+	m.state = viewLoadingChat
+	m.isLoading = true
+	m.err = nil
+	m.requestStartTime = time.Now()
+	return nil
+}
+
+// findHost returns the configured host with the given name.
+func (m *model) findHost(name string) (Host, bool) {
+	for _, host := range m.config.Hosts {
+		if host.Name == name {
+			return host, true
+		}
+	}
+	return Host{}, false
+}
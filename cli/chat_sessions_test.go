@@ -0,0 +1,45 @@
+// cli/chat_sessions_test.go
+package cli
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestChatSessionResume verifies that chat messages are recorded to a session and that the
+// session browser restores the conversation with its host and model.
+func TestChatSessionResume(t *testing.T) {
+	cfg := &Config{Hosts: []Host{{Name: "HostA", URL: "http://x", Models: []string{"m1"}}}, SessionsDir: t.TempDir()}
+	m := initialModel(context.Background(), cfg, newTestProvider())
+	m.selectedHost = cfg.Hosts[0]
+	m.selectedModel = "m1"
+	m.recordMessage("user", "hello there", nil)
+	m.recordMessage("assistant", "general kenobi", nil)
+	recorded := m.session.ID
+
+	fresh := initialModel(context.Background(), cfg, newTestProvider())
+	fresh.state = viewHostSelector
+	fresh.openSessionBrowser()
+	if fresh.state != viewSessionBrowser || len(fresh.sessionList.Items()) != 1 {
+		t.Fatalf("browser not opened with the recorded session: state=%v items=%d", fresh.state, len(fresh.sessionList.Items()))
+	}
+	if cmd := fresh.updateSessionBrowser(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Fatalf("resuming should load the session's model")
+	}
+	if fresh.state != viewLoadingChat || fresh.selectedHost.Name != "HostA" || fresh.selectedModel != "m1" {
+		t.Fatalf("resume did not restore the assignment: state=%v host=%q model=%q", fresh.state, fresh.selectedHost.Name, fresh.selectedModel)
+	}
+	if len(fresh.chatHistory) != 2 || fresh.chatHistory[1].Content != "general kenobi" || fresh.session.ID != recorded {
+		t.Fatalf("resume did not restore the history: %+v", fresh.chatHistory)
+	}
+
+	if err := fresh.resumeSession("20990101T000000Z-000000"); err == nil {
+		t.Fatalf("expected an error for an unknown session")
+	}
+	cfg.Hosts[0].Name = "Renamed"
+	if err := fresh.resumeSession(recorded); err == nil {
+		t.Fatalf("expected an error when the session's host is no longer configured")
+	}
+}
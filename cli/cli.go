@@ -4,11 +4,13 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -20,7 +22,7 @@ import (
 	"github.com/mwiater/agon/internal/models"
 	"github.com/mwiater/agon/internal/providerfactory"
 	"github.com/mwiater/agon/internal/providers"
-	"github.com/mwiater/agon/internal/providers/ollama"
+	"github.com/mwiater/agon/internal/sessions"
 )
 
 // Config represents the shared application configuration for the CLI.
@@ -36,6 +38,10 @@ type Parameters = appconfig.Parameters
 // This metadata mirrors providers.StreamMetadata for UI presentation.
 type LLMResponseMeta = providers.StreamMetadata
 
+// streamLog logs the requests sent and chunks received in chat mode under the provider
+// subsystem, at debug level.
+var streamLog = logging.For(logging.SubsystemProvider)
+
 // chatMessage represents a single message exchanged with the model.
 type chatMessage = providers.ChatMessage
 
@@ -51,6 +57,8 @@ const (
 	viewLoadingChat
 	// viewChat is the state where the user is interacting with the chat.
 	viewChat
+	// viewSessionBrowser is the state where the user picks a recorded session to resume.
+	viewSessionBrowser
 )
 
 // model is the main application model for the Bubble Tea UI.
@@ -73,9 +81,34 @@ type model struct {
 	selectedHost     Host
 	selectedModel    string
 	loadedModels     []string
+	warmState        warmStateMsg
 	width, height    int
 	program          *tea.Program
 	requestStartTime time.Time
+	hostLoad         providers.HostLoad
+	hostLoadSeq      int
+	// capabilityWarning notes a mode the selected model may not honor, such as JSON mode.
+	capabilityWarning string
+	logPane           logPane
+	quit              quitPrompt
+	// approval asks the user before the model runs a tool that requires it.
+	approval approvalPrompt
+	// streamCancel cancels the reply being streamed when the user quits by cancelling it.
+	streamCancel  context.CancelFunc
+	messageParams map[int]messageParams
+	showInspector bool
+	// parameterOverrides are the sampling settings set with /set, applied over the host's.
+	parameterOverrides Parameters
+	// notice reports a config reload or the context window policy beneath the chat header
+	// until the next message is sent.
+	notice string
+	// context holds the summary standing in for the start of the conversation.
+	context contextState
+
+	sessionStore       *sessions.Store
+	session            *sessions.Session
+	sessionList        list.Model
+	browserReturnState viewState
 }
 
 // initialModel creates and initializes a new model with default values.
@@ -100,20 +133,28 @@ func initialModel(ctx context.Context, cfg *Config, provider providers.ChatProvi
 	hostDelegate := list.NewDefaultDelegate()
 	hostList := list.New(hostItems, hostDelegate, 0, 0)
 	hostList.Title = "Select a Host"
+	hostList.AdditionalShortHelpKeys = hostPickerHelp
+	modelList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	modelList.AdditionalShortHelpKeys = refreshModelsHelp
 
 	vp := viewport.New(100, 5)
 
+	sessionList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+
 	return &model{
-		ctx:       ctx,
-		config:    cfg,
-		provider:  provider,
-		mcpStatus: deriveMCPStatus(cfg, provider),
-		state:     viewHostSelector,
-		spinner:   s,
-		textArea:  ta,
-		hostList:  hostList,
-		modelList: list.New(nil, list.NewDefaultDelegate(), 0, 0),
-		viewport:  vp,
+		ctx:           ctx,
+		config:        cfg,
+		provider:      provider,
+		mcpStatus:     deriveMCPStatus(cfg, provider),
+		state:         viewHostSelector,
+		spinner:       s,
+		textArea:      ta,
+		hostList:      hostList,
+		modelList:     modelList,
+		viewport:      vp,
+		messageParams: make(map[int]messageParams),
+		sessionStore:  newSessionStore(cfg),
+		sessionList:   sessionList,
 	}
 }
 
@@ -122,6 +163,7 @@ type item struct {
 	title  string
 	desc   string
 	loaded bool
+	caps   providers.Capabilities
 }
 
 // Title returns the title of the list item.
@@ -130,9 +172,9 @@ func (i item) Title() string { return i.title }
 // Description returns the description of the list item.
 func (i item) Description() string {
 	if i.loaded {
-		return "Currently loaded"
+		return withBadges("Currently loaded", i.caps)
 	}
-	return i.desc
+	return withBadges(i.desc, i.caps)
 }
 
 // FilterValue returns the title of the item, used for filtering.
@@ -191,11 +233,14 @@ func fetchAndSelectModelsCmd(host Host, provider providers.ChatProvider) tea.Cmd
 			loadedModelSet[m] = struct{}{}
 		}
 
+		capsCtx, cancel := context.WithTimeout(context.Background(), warmStateTimeout)
+		defer cancel()
 		var loadedItems []list.Item
 		var otherItems []list.Item
 		for _, m := range allModels {
 			_, isLoaded := loadedModelSet[m]
-			listItem := item{title: m, desc: "Select this model", loaded: isLoaded}
+			caps, _ := providers.ReportModelCapabilities(capsCtx, provider, host, m)
+			listItem := item{title: m, desc: "Select this model", loaded: isLoaded, caps: caps}
 			if isLoaded {
 				loadedItems = append(loadedItems, listItem)
 			} else {
@@ -225,23 +270,28 @@ func loadModelCmd(host Host, modelName string, provider providers.ChatProvider)
 
 // streamChatCmd creates a Bubble Tea command that initiates a streaming chat
 // conversation with the selected language model.
-func streamChatCmd(ctx context.Context, p *tea.Program, provider providers.ChatProvider, host Host, modelName string, history []chatMessage, systemPrompt string, JSONFormat bool, parameters Parameters) tea.Cmd {
+func streamChatCmd(ctx context.Context, p *tea.Program, provider providers.ChatProvider, cfg *Config, host Host, modelName string, history []chatMessage, state contextState, systemPrompt string, JSONFormat bool, parameters Parameters) tea.Cmd {
 	return func() tea.Msg {
+		fit := fitContext(ctx, provider, cfg, host, modelName, systemPrompt, history, state)
+		if fit.notice != "" || fit.state != state {
+			p.Send(contextFitMsg{state: fit.state, notice: fit.notice, messages: len(history)})
+		}
 		req := providers.StreamRequest{
 			Host:         host,
 			Model:        modelName,
-			History:      history,
-			SystemPrompt: systemPrompt,
+			History:      fit.history,
+			SystemPrompt: fit.systemPrompt,
 			Parameters:   parameters,
 			JSONMode:     JSONFormat,
+			ApproveTool:  toolApprover(p),
 		}
 
-		log.Printf("[agon -> %s (%s)] Outgoing request: user_prompt='%s', system_prompt='%s'", host.Name, modelName, lastUserPrompt(history), systemPrompt)
+		streamLog.Debugf("[agon -> %s (%s)] Outgoing request: user_prompt='%s', system_prompt='%s'", host.Name, modelName, lastUserPrompt(history), systemPrompt)
 
 		go func() {
 			err := provider.Stream(ctx, req, providers.StreamCallbacks{
 				OnChunk: func(msg providers.ChatMessage) error {
-					log.Printf("[provider -> agon] Incoming chunk: %s", msg.Content)
+					streamLog.Debugf("[provider -> agon] Incoming chunk: %s", msg.Content)
 					p.Send(streamChunkMsg(msg.Content))
 					return nil
 				},
@@ -268,11 +318,25 @@ func tickCmd() tea.Cmd {
 
 // Init initializes the Bubble Tea model and returns a command to start the spinner animation.
 func (m *model) Init() tea.Cmd {
-	return m.spinner.Tick
+	if m.state == viewLoadingChat {
+		// A session resumed from the command line starts by loading its model.
+		return tea.Batch(m.spinner.Tick, loadModelCmd(m.selectedHost, m.selectedModel, m.provider), tickCmd())
+	}
+	return tea.Batch(m.spinner.Tick, fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts))
 }
 
-// Update is the central update function for the Bubble Tea model.
+// Update is the central update function for the Bubble Tea model. It quits once a reply
+// the user chose to wait for has finished.
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m.quit.settled(m.streaming()) {
+		return model, tea.Quit
+	}
+	return model, cmd
+}
+
+// update handles a message for the active screen.
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -280,20 +344,42 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if choice, handled := m.quit.handleKey(msg.String()); handled {
+			return m, m.applyQuitChoice(choice)
+		}
+		if m.approval.handleKey(msg.String()) {
+			return m, nil
+		}
+		if handled, cmd := m.logPane.handleKey(msg.String()); handled {
+			return m, cmd
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
-			return m, tea.Quit
+			if m.quit.request(m.streaming()) {
+				return m, tea.Quit
+			}
+			return m, nil
 		case "tab":
 			if m.state == viewChat {
 				m.state = viewHostSelector
+				return m, fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts)
+			}
+		case "ctrl+t":
+			if m.state == viewChat {
+				m.showInspector = !m.showInspector
 				return m, nil
 			}
+		case "ctrl+o":
+			if !m.isLoading && m.state != viewLoadingChat && m.state != viewSessionBrowser {
+				return m, m.openSessionBrowser()
+			}
 		}
 
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		m.hostList.SetSize(msg.Width-2, msg.Height-4)
 		m.modelList.SetSize(msg.Width-2, msg.Height-4)
+		m.sessionList.SetSize(msg.Width-2, msg.Height-4)
 		m.textArea.SetWidth(msg.Width - 3)
 		headerHeight := 4
 		footerHeight := 5
@@ -307,6 +393,14 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.GotoBottom()
 		return m, nil
 
+	case warmStateMsg:
+		m.warmState = msg
+		items := make([]list.Item, len(m.config.Hosts))
+		for i, h := range m.config.Hosts {
+			items[i] = item{title: h.Name, desc: describeHost(h, m.warmState)}
+		}
+		return m, m.hostList.SetItems(items)
+
 	case modelsReadyMsg:
 		m.isLoading = false
 		m.modelList.SetItems(msg.models)
@@ -318,7 +412,20 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case hostLoadMsg:
+		if msg.seq != m.hostLoadSeq || !m.isLoading || m.responseBuf.Len() > 0 {
+			m.hostLoad = providers.HostLoad{}
+			return m, nil
+		}
+		m.hostLoad = msg.load
+		return m, hostLoadCmd(m.ctx, m.provider, m.selectedHost, m.hostLoadSeq, hostLoadPollInterval)
+
+	case toolApprovalMsg:
+		m.approval.add(msg)
+		return m, nil
+
 	case streamChunkMsg:
+		m.hostLoad = providers.HostLoad{}
 		m.responseBuf.WriteString(string(msg))
 		m.viewport.GotoBottom()
 		return m, nil
@@ -330,6 +437,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Role:    "assistant",
 				Content: m.responseBuf.String(),
 			})
+			params := newMessageParams(m.selectedHost, m.selectedModel, m.effectiveParameters(), m.config.JSONMode)
+			m.messageParams[len(m.chatHistory)-1] = params
+			m.recordMessage("assistant", m.responseBuf.String(), &params)
 			m.responseBuf.Reset()
 		}
 		m.isLoading = false
@@ -337,6 +447,40 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.GotoBottom()
 		return m, nil
 
+	case modelsRefreshedMsg:
+		status := applyModelsRefresh(m.config, msg)
+		if m.state == viewModelSelector {
+			m.selectedHost.Models = hostModels(m.config, m.selectedHost.Name)
+			return m, tea.Batch(m.modelList.NewStatusMessage(status), fetchAndSelectModelsCmd(m.selectedHost, m.provider))
+		}
+		return m, m.hostList.NewStatusMessage(status)
+
+	case contextFitMsg:
+		// A summary only applies to the conversation it was made for.
+		if msg.messages <= len(m.chatHistory) {
+			m.context = msg.state
+		}
+		if msg.notice != "" {
+			logging.LogWarn("context window: %s", msg.notice)
+			m.notice = msg.notice
+		}
+
+	case configReloadMsg:
+		status, ok := applyConfigReload(m.config, msg)
+		if !ok {
+			return m, m.statusMessage(status)
+		}
+		m.selectedHost = reloadedHost(m.config, m.selectedHost)
+		return m, tea.Batch(m.statusMessage(status), reloadProviderCmd(m.config), refreshModelsCmd(m.config), fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts))
+
+	case providerReloadMsg:
+		status := applyProviderReload(m.provider, msg)
+		m.mcpStatus = deriveMCPStatus(m.config, m.provider)
+		if status == "" {
+			return m, nil
+		}
+		return m, m.statusMessage(status)
+
 	case modelsLoadErr:
 		m.isLoading = false
 		m.err = msg.error
@@ -350,6 +494,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case streamErr:
 		m.isLoading = false
 		m.err = msg.error
+		streamLog.Errorf("chat stream failed: host=%s model=%s: %v", m.selectedHost.Name, m.selectedModel, msg.error)
 		return m, nil
 
 	case tickMsg:
@@ -357,10 +502,16 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tickCmd()
 		}
 		return m, nil
+
+	case logPaneTickMsg:
+		return m, m.logPane.handleTick()
 	}
 
 	switch m.state {
 	case viewHostSelector:
+		if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, refreshModelsKey) && m.hostList.FilterState() != list.Filtering {
+			return m, refreshModelsCmd(m.config)
+		}
 		m.hostList, cmd = m.hostList.Update(msg)
 		cmds = append(cmds, cmd)
 		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
@@ -373,10 +524,19 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case viewModelSelector:
+		if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, refreshModelsKey) && m.modelList.FilterState() != list.Filtering {
+			return m, refreshModelsCmd(m.config)
+		}
 		m.modelList, cmd = m.modelList.Update(msg)
 		cmds = append(cmds, cmd)
 		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
 			if selectedItem, ok := m.modelList.SelectedItem().(item); ok {
+				warning, err := checkModelCapabilities(m.config, m.selectedHost, selectedItem.Title(), selectedItem.caps)
+				if err != nil {
+					cmds = append(cmds, m.modelList.NewStatusMessage(providers.UserMessage(err)))
+					return m, tea.Batch(cmds...)
+				}
+				m.capabilityWarning = warning
 				m.selectedModel = selectedItem.Title()
 				m.state = viewLoadingChat
 				m.isLoading = true
@@ -386,6 +546,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case viewSessionBrowser:
+		cmds = append(cmds, m.updateSessionBrowser(msg))
+
 	case viewChat:
 		m.viewport, cmd = m.viewport.Update(msg)
 		cmds = append(cmds, cmd)
@@ -395,15 +558,24 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
 			userInput := strings.TrimSpace(m.textArea.Value())
-			if userInput != "" {
+			if m.applyParameterOverrides(userInput) {
+				m.textArea.Reset()
+			} else if userInput != "" {
 				m.responseMeta = LLMResponseMeta{}
 				m.requestStartTime = time.Now()
 				m.chatHistory = append(m.chatHistory, chatMessage{Role: "user", Content: userInput})
+				m.recordMessage("user", userInput, nil)
 				m.textArea.Reset()
 				m.isLoading = true
 				m.err = nil
-
-				cmds = append(cmds, m.spinner.Tick, streamChatCmd(m.ctx, m.program, m.provider, m.selectedHost, m.selectedModel, m.chatHistory, m.selectedHost.SystemPrompt, m.config.JSONMode, m.selectedHost.Parameters))
+				m.notice = ""
+				m.hostLoad = providers.HostLoad{}
+				m.hostLoadSeq++
+
+				var streamCtx context.Context
+				streamCtx, m.streamCancel = context.WithCancel(m.ctx)
+				cmds = append(cmds, hostLoadCmd(m.ctx, m.provider, m.selectedHost, m.hostLoadSeq, 0))
+				cmds = append(cmds, m.spinner.Tick, streamChatCmd(streamCtx, m.program, m.provider, m.config, m.selectedHost, m.selectedModel, m.chatHistory, m.context, m.selectedHost.SystemPrompt, m.config.JSONMode, m.effectiveParameters()))
 			}
 		}
 	}
@@ -416,15 +588,59 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// statusMessage shows status in the picker on screen, or beneath the chat header.
+func (m *model) statusMessage(status string) tea.Cmd {
+	switch m.state {
+	case viewHostSelector:
+		return m.hostList.NewStatusMessage(status)
+	case viewModelSelector:
+		return m.modelList.NewStatusMessage(status)
+	}
+	m.notice = status
+	return nil
+}
+
+// streaming reports whether a reply is being streamed into the chat.
+func (m *model) streaming() bool {
+	return m.state == viewChat && m.isLoading
+}
+
+// applyQuitChoice carries out the answer to the quit prompt. Cancelling stops the reply
+// and saves what arrived of it to the session.
+func (m *model) applyQuitChoice(choice quitChoice) tea.Cmd {
+	switch choice {
+	case quitForce:
+		return tea.Quit
+	case quitCancel:
+		if m.streamCancel != nil {
+			m.streamCancel()
+		}
+		if m.streaming() && m.responseBuf.Len() > 0 {
+			params := newMessageParams(m.selectedHost, m.selectedModel, m.effectiveParameters(), m.config.JSONMode)
+			m.recordMessage("assistant", m.responseBuf.String(), &params)
+			m.responseBuf.Reset()
+		}
+		m.isLoading = false
+		return tea.Quit
+	}
+	return nil
+}
+
 // View renders the application's UI based on the current state of the model.
 func (m *model) View() string {
+	view := m.approval.attach(m.logPane.attach(m.renderView(), m.width, m.height), m.width, m.height)
+	return m.quit.attach(view, m.width, m.height)
+}
+
+// renderView renders the active screen without the log pane.
+func (m *model) renderView() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
 
 	if m.err != nil {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Padding(1)
-		return errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+		return errorStyle.Render("Error: " + describeError(m.config, m.err, m.selectedModel, m.chatHistory))
 	}
 
 	switch m.state {
@@ -455,6 +671,12 @@ func (m *model) View() string {
 	case viewChat:
 		return m.chatView()
 
+	case viewSessionBrowser:
+		if len(m.sessionList.Items()) == 0 {
+			return lipgloss.NewStyle().Margin(1, 2).Render("No sessions recorded yet.\n\nesc to go back")
+		}
+		return lipgloss.NewStyle().Margin(1, 2).Render(m.sessionList.View())
+
 	default:
 		return "Unknown state"
 	}
@@ -463,6 +685,7 @@ func (m *model) View() string {
 // chatView renders the chat interface, including the header, chat history,
 // current response (if streaming), and the input text area.
 func (m *model) chatView() string {
+	params := m.effectiveParameters()
 	var builder strings.Builder
 
 	headerStyle := lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230")).Padding(0, 1)
@@ -478,75 +701,82 @@ func (m *model) chatView() string {
 	mcpBadge := renderMCPBadge(m.mcpStatus)
 
 	var modelTopK string
-	if m.selectedHost.Parameters.TopK != nil {
-		modelTopK = fmt.Sprintf("TopK: %v", *m.selectedHost.Parameters.TopK)
+	if params.TopK != nil {
+		modelTopK = fmt.Sprintf("TopK: %v", *params.TopK)
 	} else {
 		modelTopK = "TopK: n/a"
 	}
 
 	var modelTopP string
-	if m.selectedHost.Parameters.TopP != nil {
-		modelTopP = fmt.Sprintf("TopP: %v", *m.selectedHost.Parameters.TopP)
+	if params.TopP != nil {
+		modelTopP = fmt.Sprintf("TopP: %v", *params.TopP)
 	} else {
 		modelTopP = "TopP: n/a"
 	}
 
 	var modelMinP string
-	if m.selectedHost.Parameters.MinP != nil {
-		modelMinP = fmt.Sprintf("MinP: %v", *m.selectedHost.Parameters.MinP)
+	if params.MinP != nil {
+		modelMinP = fmt.Sprintf("MinP: %v", *params.MinP)
 	} else {
 		modelMinP = "MinP: n/a"
 	}
 
 	var modelTFSZ string
-	if m.selectedHost.Parameters.TFSZ != nil {
-		modelTFSZ = fmt.Sprintf("TFSZ: %v", *m.selectedHost.Parameters.TFSZ)
+	if params.TFSZ != nil {
+		modelTFSZ = fmt.Sprintf("TFSZ: %v", *params.TFSZ)
 	} else {
 		modelTFSZ = "TFSZ: n/a"
 	}
 
 	var modelTypicalP string
-	if m.selectedHost.Parameters.TypicalP != nil {
-		modelTypicalP = fmt.Sprintf("TypicalP: %v", *m.selectedHost.Parameters.TypicalP)
+	if params.TypicalP != nil {
+		modelTypicalP = fmt.Sprintf("TypicalP: %v", *params.TypicalP)
 	} else {
 		modelTypicalP = "TypicalP: n/a"
 	}
 
 	var modelRepeatLastN string
-	if m.selectedHost.Parameters.RepeatLastN != nil {
-		modelRepeatLastN = fmt.Sprintf("RepeatLastN: %v", *m.selectedHost.Parameters.RepeatLastN)
+	if params.RepeatLastN != nil {
+		modelRepeatLastN = fmt.Sprintf("RepeatLastN: %v", *params.RepeatLastN)
 	} else {
 		modelRepeatLastN = "RepeatLastN: n/a"
 	}
 
 	var modelTemperature string
-	if m.selectedHost.Parameters.Temperature != nil {
-		modelTemperature = fmt.Sprintf("Temperature: %v", *m.selectedHost.Parameters.Temperature)
+	if params.Temperature != nil {
+		modelTemperature = fmt.Sprintf("Temperature: %v", *params.Temperature)
 	} else {
 		modelTemperature = "Temperature: n/a"
 	}
 
 	var modelRepeatPenalty string
-	if m.selectedHost.Parameters.RepeatPenalty != nil {
-		modelRepeatPenalty = fmt.Sprintf("RepeatPenalty: %v", *m.selectedHost.Parameters.RepeatPenalty)
+	if params.RepeatPenalty != nil {
+		modelRepeatPenalty = fmt.Sprintf("RepeatPenalty: %v", *params.RepeatPenalty)
 	} else {
 		modelRepeatPenalty = "RepeatPenalty: n/a"
 	}
 
 	var modelPresencePenalty string
-	if m.selectedHost.Parameters.PresencePenalty != nil {
-		modelPresencePenalty = fmt.Sprintf("PresencePenalty: %v", *m.selectedHost.Parameters.PresencePenalty)
+	if params.PresencePenalty != nil {
+		modelPresencePenalty = fmt.Sprintf("PresencePenalty: %v", *params.PresencePenalty)
 	} else {
 		modelPresencePenalty = "PresencePenalty: n/a"
 	}
 
 	var modelFrequencyPenalty string
-	if m.selectedHost.Parameters.FrequencyPenalty != nil {
-		modelFrequencyPenalty = fmt.Sprintf("FrequencyPenalty: %v", *m.selectedHost.Parameters.FrequencyPenalty)
+	if params.FrequencyPenalty != nil {
+		modelFrequencyPenalty = fmt.Sprintf("FrequencyPenalty: %v", *params.FrequencyPenalty)
 	} else {
 		modelFrequencyPenalty = "FrequencyPenalty: n/a"
 	}
 
+	var modelSeed string
+	if params.Seed != nil {
+		modelSeed = fmt.Sprintf("Seed: %v", *params.Seed)
+	} else {
+		modelSeed = "Seed: n/a"
+	}
+
 	var longestLength int
 
 	modelStrings := []string{
@@ -560,6 +790,7 @@ func (m *model) chatView() string {
 		modelRepeatPenalty,
 		modelPresencePenalty,
 		modelFrequencyPenalty,
+		modelSeed,
 	}
 
 	for _, s := range modelStrings {
@@ -602,10 +833,17 @@ func (m *model) chatView() string {
 
 	configSettingsLine4 := lipgloss.JoinHorizontal(lipgloss.Top,
 		paramStyle.MarginLeft(len(labelString)+1).Render(modelFrequencyPenalty),
+		paramStyle.Render(modelSeed),
 	)
 
-	help := lipgloss.NewStyle().Render(" (tab to change, esc to quit)")
+	help := lipgloss.NewStyle().Render(" (tab to change, ctrl+o sessions, ctrl+t to inspect, f2 logs, esc to quit)")
 	builder.WriteString(status + help + configSettingsLine1 + configSettingsLine2 + configSettingsLine3 + configSettingsLine4 + "\n\n")
+	if m.capabilityWarning != "" {
+		builder.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("Warning: "+m.capabilityWarning) + "\n")
+	}
+	if m.notice != "" {
+		builder.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(m.notice) + "\n")
+	}
 
 	var historyBuilder strings.Builder
 	userStyle := lipgloss.NewStyle().Bold(true)
@@ -630,12 +868,16 @@ func (m *model) chatView() string {
 		historyBuilder.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, role, wrappedContent))
 	}
 
-	m.viewport.SetContent(historyBuilder.String())
+	if m.showInspector {
+		m.viewport.SetContent(renderMessageInspector(m.chatHistory, m.messageParams, m.width))
+	} else {
+		m.viewport.SetContent(historyBuilder.String())
+	}
 	builder.WriteString(m.viewport.View())
 
 	if m.isLoading {
 		timer := fmt.Sprintf("%.1f", time.Since(m.requestStartTime).Seconds())
-		loadingText := fmt.Sprintf(" Assistant is thinking... %ss", timer)
+		loadingText := fmt.Sprintf(" Assistant is thinking... %ss", timer) + busyHint(m.hostLoad)
 		builder.WriteString("\n" + m.spinner.View() + loadingText)
 	} else {
 		builder.WriteString("\n" + m.textArea.View())
@@ -648,6 +890,21 @@ func (m *model) chatView() string {
 	return builder.String()
 }
 
+// describeError explains err for the chat views. When the conversation overflowed the
+// model's context window, it adds the conversation's estimated size.
+func describeError(cfg *Config, err error, model string, history []chatMessage) string {
+	message := providers.UserMessage(err)
+	if !errors.Is(err, providers.ErrContextOverflow) || cfg == nil || len(history) == 0 {
+		return message
+	}
+	contents := make([]string, len(history))
+	for i, msg := range history {
+		contents[i] = msg.Content
+	}
+	tokenizer := cfg.TokenizerFor(model)
+	return fmt.Sprintf("%s The conversation is about %d tokens (%s estimate).", message, tokenizer.CountMessages(contents), tokenizer.Family())
+}
+
 // formatMeta formats the LLMResponseMeta into a human-readable string.
 func formatMeta(meta LLMResponseMeta) string {
 	style := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
@@ -657,7 +914,7 @@ func formatMeta(meta LLMResponseMeta) string {
 	evalDur := float64(meta.EvalDuration) / 1e9
 	totalDur := float64(meta.TotalDuration) / 1e9
 
-	return style.Render(fmt.Sprintf(
+	line := fmt.Sprintf(
 		"  >>> [Model Load Duration: %.1fs] [Prompt Eval: %.1fs | %d Tokens] [Response Eval: %.1fs | %d Tokens] [Total Duration: %.1fs]",
 		loadDur,
 		promptEvalDur,
@@ -665,7 +922,11 @@ func formatMeta(meta LLMResponseMeta) string {
 		evalDur,
 		meta.EvalCount,
 		totalDur,
-	))
+	)
+	if meta.QueueWait > 0 {
+		line += fmt.Sprintf(" [Queue Wait: %.1fs]", meta.QueueWait.Seconds())
+	}
+	return style.Render(line)
 }
 
 // StartGUI initializes and runs the interactive TUI for single-model chat.
@@ -684,18 +945,21 @@ func StartGUI(ctx context.Context, cfg *appconfig.Config, cancel context.CancelF
 		log.Fatalf("Failed to start: configuration is not loaded")
 	}
 
-	provider, err := providerfactory.NewChatProvider(cfg)
+	discoverStartupModels(cfg)
+
+	provider, err := providerfactory.NewUnscheduledChatProvider(cfg)
 	if err != nil {
 		if cfg.MCPMode {
-			logging.LogEvent("MCP provider unavailable: %v — falling back to direct Ollama access", err)
-			provider = ollama.New(cfg)
+			logging.LogWarn("MCP provider unavailable: %v — falling back to direct host access", err)
+			provider = providerfactory.NewHostRouter(cfg)
 		} else {
 			log.Fatalf("Failed to initialize provider: %v", err)
 		}
 	}
+	provider = newReloadableProvider(cfg, provider)
 	defer func() {
 		if err := provider.Close(); err != nil {
-			logging.LogEvent("provider shutdown error: %v", err)
+			logging.LogError("provider shutdown error: %v", err)
 		}
 	}()
 
@@ -708,9 +972,15 @@ func StartGUI(ctx context.Context, cfg *appconfig.Config, cancel context.CancelF
 	}
 
 	m := initialModel(ctx, cfg, provider)
+	if cfg.ResumeSession != "" {
+		if err := m.resumeSession(cfg.ResumeSession); err != nil {
+			log.Fatalf("Failed to resume session: %v", err)
+		}
+	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	m.program = p
+	defer watchConfig(ctx, cfg, p)()
 
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Error running program: %v", err)
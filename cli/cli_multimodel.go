@@ -14,7 +14,9 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/sessions"
 )
 
 // multimodelViewState represents the current state of the multimodel application's view.
@@ -46,6 +48,19 @@ type multimodelColumnResponse struct {
 	meta             LLMResponseMeta
 	chatHistory      []chatMessage
 	requestStartTime time.Time
+	session          *sessions.Session
+	// messageParams records the settings each generated message in chatHistory was
+	// produced with, keyed by its index.
+	messageParams map[int]messageParams
+	// context holds the summary standing in for the start of the column's conversation.
+	context contextState
+}
+
+// multimodelContextFitMsg reports the context state and notice after a column's request
+// was fitted to its model's context window.
+type multimodelContextFitMsg struct {
+	hostIndex int
+	contextFitMsg
 }
 
 // multimodelModel is the Bubble Tea model for multimodel mode.
@@ -71,6 +86,8 @@ type multimodelModel struct {
 	modelList list.Model
 	// inModelSelection indicates whether the user is choosing a model for a host.
 	inModelSelection bool
+	// warmState records which models each host currently has loaded.
+	warmState warmStateMsg
 
 	// textArea collects chat prompts from the user.
 	textArea textarea.Model
@@ -85,11 +102,28 @@ type multimodelModel struct {
 	columnResponses [4]multimodelColumnResponse
 	// requestStartTime marks when the latest request was issued.
 	requestStartTime time.Time
+	// comparisonRows accumulates per-prompt, per-model results for export.
+	comparisonRows []comparisonRow
+	// statusBanner reports the outcome of ratings and exports.
+	statusBanner string
 
 	// width and height capture the current viewport dimensions.
 	width, height int
 	// program references the Bubble Tea program running the TUI.
 	program *tea.Program
+	// logPane tails recent warnings and errors beneath the chat columns.
+	logPane logPane
+	// quit confirms quitting while replies are streaming; streamCancel stops them.
+	quit         quitPrompt
+	streamCancel context.CancelFunc
+	// approval asks the user before a model runs a tool that requires it.
+	approval approvalPrompt
+	// sessionStore records each column's conversation so it can be resumed in single-model chat.
+	sessionStore *sessions.Store
+	// pipelineImports are the columns /stage marked for Pipeline mode; switchToPipeline
+	// is set when ctrl+p leaves to open it.
+	pipelineImports  []pipelineImport
+	switchToPipeline bool
 
 	requestWg sync.WaitGroup
 }
@@ -166,9 +200,54 @@ func initialMultimodelModel(ctx context.Context, cfg *Config, provider providers
 		textArea:          ta,
 		viewport:          vp,
 		columnResponses:   columnResponses,
+		sessionStore:      newSessionStore(cfg),
+	}
+}
+
+// recordColumnMessage appends a message to the session of one column, starting it on the
+// column's first message. params are recorded with generated messages and are nil for
+// the user's.
+func (m *multimodelModel) recordColumnMessage(index int, role, content string, params *messageParams) {
+	if m.sessionStore == nil || index >= len(m.assignments) {
+		return
+	}
+	column := &m.columnResponses[index]
+	assignment := m.assignments[index]
+	if column.session == nil {
+		column.session = m.sessionStore.Create(assignment.host.Name, assignment.selectedModel)
+	}
+	var err error
+	if params != nil {
+		err = column.session.AppendGenerated(role, content, params.sessionParams())
+	} else {
+		err = column.session.Append(role, content)
+	}
+	if err != nil {
+		logging.LogError("chat session: %v", err)
 	}
 }
 
+// finishColumnMessage records the parameters of a column's latest reply and saves the
+// reply to the column's session. It does nothing when the column has no reply yet.
+func (m *multimodelModel) finishColumnMessage(index int) {
+	if index >= len(m.assignments) || index >= len(m.columnResponses) {
+		return
+	}
+	column := &m.columnResponses[index]
+	last := len(column.chatHistory) - 1
+	if last < 0 || column.chatHistory[last].Role != "assistant" {
+		return
+	}
+	assignment := m.assignments[index]
+	// Columns stream with their host's parameters, as multimodelStreamChatCmd sends them.
+	params := newMessageParams(assignment.host, assignment.selectedModel, assignment.host.Parameters, m.config.JSONMode)
+	if column.messageParams == nil {
+		column.messageParams = make(map[int]messageParams)
+	}
+	column.messageParams[last] = params
+	m.recordColumnMessage(index, "assistant", column.chatHistory[last].Content, &params)
+}
+
 // loadMultimodelChatCmd prepares the chat interface for multimodel mode.
 func loadMultimodelChatCmd(assignments []hostModelAssignment) tea.Cmd {
 	return func() tea.Msg {
@@ -178,17 +257,21 @@ func loadMultimodelChatCmd(assignments []hostModelAssignment) tea.Cmd {
 }
 
 // multimodelStreamChatCmd initiates streaming chat for all assigned host/model pairs.
-func multimodelStreamChatCmd(p *tea.Program, m *multimodelModel) tea.Cmd {
+func multimodelStreamChatCmd(ctx context.Context, p *tea.Program, m *multimodelModel) tea.Cmd {
 	return func() tea.Msg {
 		for i, assignment := range m.assignments {
 			if assignment.isAssigned {
 				m.requestWg.Add(1)
-				go func(hostIndex int, host Host, model string, history []chatMessage) {
+				go func(hostIndex int, host Host, model string, history []chatMessage, state contextState) {
 					defer m.requestWg.Done()
-					if err := streamToColumn(m.ctx, p, m.provider, hostIndex, host, model, history, host.SystemPrompt, m.config.JSONMode, host.Parameters); err != nil {
+					fit := fitContext(ctx, m.provider, m.config, host, model, host.SystemPrompt, history, state)
+					if fit.notice != "" || fit.state != state {
+						p.Send(multimodelContextFitMsg{hostIndex: hostIndex, contextFitMsg: contextFitMsg{state: fit.state, notice: fit.notice, messages: len(history)}})
+					}
+					if err := streamToColumn(ctx, p, m.provider, hostIndex, host, model, fit.history, fit.systemPrompt, m.config.JSONMode, host.Parameters); err != nil {
 						p.Send(multimodelStreamErr{hostIndex: hostIndex, err: err})
 					}
-				}(i, assignment.host, assignment.selectedModel, m.columnResponses[i].chatHistory)
+				}(i, assignment.host, assignment.selectedModel, m.columnResponses[i].chatHistory, m.columnResponses[i].context)
 			}
 		}
 		return nil
@@ -204,6 +287,7 @@ func streamToColumn(ctx context.Context, p *tea.Program, provider providers.Chat
 		SystemPrompt: systemPrompt,
 		Parameters:   parameters,
 		JSONMode:     JSONFormat,
+		ApproveTool:  toolApprover(p),
 	}
 
 	return provider.Stream(ctx, req, providers.StreamCallbacks{
@@ -220,11 +304,21 @@ func streamToColumn(ctx context.Context, p *tea.Program, provider providers.Chat
 
 // Init initializes the multimodel Bubble Tea model.
 func (m *multimodelModel) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts))
 }
 
-// Update handles all message updates for multimodel mode.
+// Update handles all message updates for multimodel mode, quitting once replies the user
+// chose to wait for have finished.
 func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m.quit.settled(m.streaming()) {
+		return model, tea.Quit
+	}
+	return model, cmd
+}
+
+// update handles a message for the active multimodel screen.
+func (m *multimodelModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -232,13 +326,32 @@ func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if choice, handled := m.quit.handleKey(msg.String()); handled {
+			return m, m.applyQuitChoice(choice)
+		}
+		if m.approval.handleKey(msg.String()) {
+			return m, nil
+		}
+		if handled, cmd := m.logPane.handleKey(msg.String()); handled {
+			return m, cmd
+		}
 		switch msg.String() {
 		case "ctrl+c":
+			if m.quit.request(m.streaming()) {
+				return m, tea.Quit
+			}
+			return m, nil
+		case "ctrl+p":
+			if m.isLoading {
+				m.statusBanner = "Wait for the responses before switching to Pipeline"
+				return m, nil
+			}
+			m.switchToPipeline = true
 			return m, tea.Quit
 		case "tab":
 			if m.state == multimodelViewChat {
 				m.state = multimodelViewAssignment
-				return m, nil
+				return m, fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts)
 			}
 		}
 
@@ -251,6 +364,39 @@ func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Width = msg.Width
 		m.viewport.Height = msg.Height - headerHeight - footerHeight
 
+	case warmStateMsg:
+		m.warmState = msg
+		return m, nil
+
+	case modelsRefreshedMsg:
+		m.statusBanner = applyModelsRefresh(m.config, msg)
+		for i := range m.assignments {
+			assignment := &m.assignments[i]
+			assignment.host.Models = hostModels(m.config, assignment.host.Name)
+			assignment.models = assignment.host.Models
+		}
+		return m, nil
+
+	case configReloadMsg:
+		status, ok := applyConfigReload(m.config, msg)
+		m.statusBanner = status
+		if !ok {
+			return m, nil
+		}
+		for i := range m.assignments {
+			assignment := &m.assignments[i]
+			assignment.host = reloadedHost(m.config, assignment.host)
+			assignment.models = assignment.host.Models
+		}
+		return m, tea.Batch(reloadProviderCmd(m.config), refreshModelsCmd(m.config), fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts))
+
+	case providerReloadMsg:
+		if status := applyProviderReload(m.provider, msg); status != "" {
+			m.statusBanner = status
+		}
+		m.mcpStatus = deriveMCPStatus(m.config, m.provider)
+		return m, nil
+
 	case multimodelChatReadyMsg:
 		m.isLoading = false
 		m.state = multimodelViewChat
@@ -278,6 +424,8 @@ func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.hostIndex < len(m.columnResponses) {
 			m.columnResponses[msg.hostIndex].meta = msg.meta
 			m.columnResponses[msg.hostIndex].isStreaming = false
+			m.finishColumnMessage(msg.hostIndex)
+			m.recordComparison(msg.hostIndex, msg.meta)
 		}
 		allDone := true
 		for i, assignment := range m.assignments {
@@ -314,11 +462,25 @@ func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case multimodelContextFitMsg:
+		if msg.hostIndex < len(m.columnResponses) {
+			column := &m.columnResponses[msg.hostIndex]
+			if msg.messages <= len(column.chatHistory) {
+				column.context = msg.state
+			}
+		}
+		if msg.notice != "" {
+			logging.LogWarn("context window: column=%d: %s", msg.hostIndex+1, msg.notice)
+			m.statusBanner = fmt.Sprintf("Column %d: %s", msg.hostIndex+1, msg.notice)
+		}
+		return m, nil
+
 	case multimodelStreamErr:
 		if msg.hostIndex < len(m.columnResponses) {
 			m.columnResponses[msg.hostIndex].error = msg.err
 			m.columnResponses[msg.hostIndex].isStreaming = false
 		}
+		streamLog.Errorf("multimodel stream failed: column=%d: %v", msg.hostIndex+1, msg.err)
 		return m, nil
 
 	case tickMsg:
@@ -326,6 +488,13 @@ func (m *multimodelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tickCmd()
 		}
 		return m, nil
+
+	case toolApprovalMsg:
+		m.approval.add(msg)
+		return m, nil
+
+	case logPaneTickMsg:
+		return m, m.logPane.handleTick()
 	}
 
 	switch m.state {
@@ -360,9 +529,15 @@ func (m *multimodelModel) updateAssignment(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch keyMsg.String() {
 			case "enter":
 				if selectedItem, ok := m.modelList.SelectedItem().(item); ok {
+					m.inModelSelection = false
+					warning, err := checkModelCapabilities(m.config, m.assignments[m.selectedHostIndex].host, selectedItem.Title(), selectedItem.caps)
+					if err != nil {
+						m.statusBanner = providers.UserMessage(err)
+						break
+					}
+					m.statusBanner = warning
 					m.assignments[m.selectedHostIndex].selectedModel = selectedItem.Title()
 					m.assignments[m.selectedHostIndex].isAssigned = true
-					m.inModelSelection = false
 				}
 			case "esc":
 				m.inModelSelection = false
@@ -381,13 +556,17 @@ func (m *multimodelModel) updateAssignment(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedHostIndex++
 				}
 			case "enter":
-				items := make([]list.Item, len(m.assignments[m.selectedHostIndex].models))
-				for i, model := range m.assignments[m.selectedHostIndex].models {
-					items[i] = item{title: model, desc: "Select this model"}
+				assignment := m.assignments[m.selectedHostIndex]
+				warm := m.warmState[assignment.host.Name]
+				items := make([]list.Item, len(assignment.models))
+				for i, model := range assignment.models {
+					items[i] = item{title: model, desc: "Select this model", loaded: warm.isLoaded(model), caps: warm.capabilitiesOf(model)}
 				}
 				m.modelList.SetItems(items)
 				m.modelList.Title = fmt.Sprintf("Select Model for %s", m.assignments[m.selectedHostIndex].host.Name)
 				m.inModelSelection = true
+			case "r":
+				return m, refreshModelsCmd(m.config)
 			case "c":
 				hasAssignment := false
 				for _, assignment := range m.assignments {
@@ -425,13 +604,23 @@ func (m *multimodelModel) updateChat(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.textArea, cmd = m.textArea.Update(msg)
 	cmds = append(cmds, cmd)
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+e" && !m.isLoading {
+		m.exportComparison()
+	}
+
 	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "enter" {
 		userInput := strings.TrimSpace(m.textArea.Value())
+		if m.applyRating(userInput) || m.applyStageImport(userInput) {
+			m.textArea.Reset()
+			userInput = ""
+		}
 		if userInput != "" {
+			m.statusBanner = ""
 			userMsg := chatMessage{Role: "user", Content: userInput}
 			for i := range m.columnResponses {
 				if m.assignments[i].isAssigned {
 					m.columnResponses[i].chatHistory = append(m.columnResponses[i].chatHistory, userMsg)
+					m.recordColumnMessage(i, "user", userInput, nil)
 					m.columnResponses[i].requestStartTime = time.Now()
 					m.columnResponses[i].isStreaming = true
 				} else {
@@ -446,22 +635,59 @@ func (m *multimodelModel) updateChat(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textArea.Reset()
 			m.textArea.Blur()
 
-			cmds = append(cmds, m.spinner.Tick, multimodelStreamChatCmd(m.program, m))
+			var streamCtx context.Context
+			streamCtx, m.streamCancel = context.WithCancel(m.ctx)
+			cmds = append(cmds, m.spinner.Tick, multimodelStreamChatCmd(streamCtx, m.program, m))
 		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// streaming reports whether replies are being streamed into the columns.
+func (m *multimodelModel) streaming() bool {
+	return m.state == multimodelViewChat && m.isLoading
+}
+
+// applyQuitChoice carries out the answer to the quit prompt. Cancelling stops the replies
+// and saves what arrived of each to its column's session.
+func (m *multimodelModel) applyQuitChoice(choice quitChoice) tea.Cmd {
+	switch choice {
+	case quitForce:
+		return tea.Quit
+	case quitCancel:
+		if m.streamCancel != nil {
+			m.streamCancel()
+		}
+		for i := range m.columnResponses {
+			column := &m.columnResponses[i]
+			if !column.isStreaming {
+				continue
+			}
+			column.isStreaming = false
+			m.finishColumnMessage(i)
+		}
+		m.isLoading = false
+		return tea.Quit
+	}
+	return nil
+}
+
 // View renders the multimodel UI based on current state.
 func (m *multimodelModel) View() string {
+	view := m.approval.attach(m.logPane.attach(m.renderView(), m.width, m.height), m.width, m.height)
+	return m.quit.attach(view, m.width, m.height)
+}
+
+// renderView renders the active multimodel screen without the log pane.
+func (m *multimodelModel) renderView() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
 
 	if m.err != nil {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Padding(1)
-		return errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+		return errorStyle.Render("Error: " + providers.UserMessage(m.err))
 	}
 
 	switch m.state {
@@ -483,7 +709,11 @@ func (m *multimodelModel) assignmentView() string {
 
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
 	builder.WriteString(titleStyle.Render("Multimodel Mode - Assign Models to Hosts") + "\n")
-	builder.WriteString(renderMCPBadge(m.mcpStatus) + "\n\n")
+	builder.WriteString(renderMCPBadge(m.mcpStatus) + "\n")
+	if m.statusBanner != "" {
+		builder.WriteString(bannerStyle.Render(m.statusBanner) + "\n")
+	}
+	builder.WriteString("\n")
 
 	if m.inModelSelection {
 		return lipgloss.NewStyle().Margin(1, 2).Render(m.modelList.View())
@@ -510,13 +740,17 @@ func (m *multimodelModel) assignmentView() string {
 			line.WriteString(placeholderStyle.Render("(no model assigned)"))
 		}
 
+		if state, ok := m.warmState[assignment.host.Name]; ok {
+			line.WriteString(lipgloss.NewStyle().Faint(true).Render("  [" + state.summary() + "]"))
+		}
+
 		builder.WriteString(line.String() + "\n")
 	}
 
 	builder.WriteString("\n")
 
 	helpStyle := lipgloss.NewStyle().Faint(true)
-	builder.WriteString(helpStyle.Render("↑/↓: Navigate  Enter: Select Model  C: Start Chat  esc: Quit\n"))
+	builder.WriteString(helpStyle.Render("↑/↓: Navigate  Enter: Select Model  R: Refresh Models  C: Start Chat  esc: Quit\n"))
 
 	hasAssignment := false
 	for _, assignment := range m.assignments {
@@ -540,8 +774,12 @@ func (m *multimodelModel) multimodelChatView() string {
 
 	headerStyle := lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230")).Padding(0, 1)
 	header := lipgloss.JoinHorizontal(lipgloss.Top, headerStyle.Render("Multimodel Chat"), renderMCPBadge(m.mcpStatus))
-	help := lipgloss.NewStyle().Faint(true).Render(" (tab to reassign, q to quit)")
-	builder.WriteString(header + help + "\n\n")
+	help := lipgloss.NewStyle().Faint(true).Render(" (tab to reassign, /rate <col> <1-5> to score, /stage <col> <n> [context] for pipeline, ctrl+p for pipeline, ctrl+e to export, q to quit)")
+	builder.WriteString(header + help + "\n")
+	if m.statusBanner != "" {
+		builder.WriteString(bannerStyle.Render(m.statusBanner))
+	}
+	builder.WriteString("\n")
 
 	colWidth := (m.width - 8) / 4
 
@@ -608,7 +846,7 @@ func (m *multimodelModel) multimodelChatView() string {
 		if i < len(m.assignments) && m.assignments[i].isAssigned {
 			if m.columnResponses[i].error != nil {
 				errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-				colChatHistory.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.columnResponses[i].error)))
+				colChatHistory.WriteString(errorStyle.Render("Error: " + describeError(m.config, m.columnResponses[i].error, m.assignments[i].selectedModel, m.columnResponses[i].chatHistory)))
 			} else {
 				userStyle := lipgloss.NewStyle().Bold(true)
 				assistantStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
@@ -662,8 +900,16 @@ func StartMultimodelGUI(ctx context.Context, cfg *Config, provider providers.Cha
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	m.program = p
+	stopWatching := watchConfig(ctx, cfg, p)
 
 	_, err := p.Run()
+	stopWatching()
+	if m.switchToPipeline && err == nil {
+		m.requestWg.Wait()
+		// The provider reads the mode from cfg, e.g. for the MCP tool audit log.
+		cfg.MultimodelMode, cfg.PipelineMode = false, true
+		return startPipelineGUI(ctx, cfg, cancel, provider, m.pipelineImports)
+	}
 	cancel()
 	m.requestWg.Wait()
 	return err
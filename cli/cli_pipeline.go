@@ -6,6 +6,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"net/http"
@@ -16,19 +17,21 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providerfactory"
 	"github.com/mwiater/agon/internal/providers"
-	"github.com/mwiater/agon/internal/providers/ollama"
+	"github.com/mwiater/agon/internal/tokencount"
+	"github.com/mwiater/agon/internal/usage"
 	"github.com/mwiater/agon/internal/util"
 )
 
 const (
-	// pipelineStageCount defines the number of stages in the pipeline.
-	pipelineStageCount = 4
+	// minStageColumnWidth is the narrowest a stage column is drawn in the pipeline view.
+	minStageColumnWidth = 24
 	// pipelineMaxHandoffTokens limits the number of tokens in a handoff payload.
 	pipelineMaxHandoffTokens = 4096
 	// pipelinePreviewRunes limits the number of runes in a handoff preview.
@@ -61,6 +64,8 @@ var (
 	overlayStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("63")).Padding(1).Width(80)
 	// bannerStyle is the Lipgloss style for the status banner.
 	bannerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("124")).Padding(0, 1)
+	// pipelineLog logs stage dispatch, handoffs, retries and failures under the pipeline subsystem.
+	pipelineLog = logging.For(logging.SubsystemPipeline)
 )
 
 // pipelineViewState describes which primary view is active inside pipeline mode.
@@ -134,27 +139,40 @@ type pipelineHandoff struct {
 type hostSelectorItem struct {
 	index int
 	host  Host
+	desc  string
 }
 
 // Title returns the title of the host selector item.
 func (i hostSelectorItem) Title() string { return i.host.Name }
 
 // Description returns the description of the host selector item.
-func (i hostSelectorItem) Description() string { return i.host.URL }
+func (i hostSelectorItem) Description() string {
+	if i.desc != "" {
+		return i.desc
+	}
+	return i.host.URL
+}
 
 // FilterValue returns the filter value for the host selector item.
 func (i hostSelectorItem) FilterValue() string { return i.host.Name }
 
 // modelSelectorItem renders models inside the assignment picker.
 type modelSelectorItem struct {
-	name string
+	name   string
+	loaded bool
+	caps   providers.Capabilities
 }
 
 // Title returns the title of the model selector item.
 func (i modelSelectorItem) Title() string { return i.name }
 
 // Description returns the description of the model selector item.
-func (i modelSelectorItem) Description() string { return "Select model" }
+func (i modelSelectorItem) Description() string {
+	if i.loaded {
+		return withBadges("Currently loaded", i.caps)
+	}
+	return withBadges("Select model", i.caps)
+}
 
 // FilterValue returns the filter value for the model selector item.
 func (i modelSelectorItem) FilterValue() string { return i.name }
@@ -179,12 +197,28 @@ type pipelineStage struct {
 	stats        LLMResponseMeta
 	cacheHit     bool
 
-	startedAt   time.Time
-	firstToken  time.Time
-	completedAt time.Time
+	startedAt    time.Time
+	firstToken   time.Time
+	completedAt  time.Time
+	queuedAt     time.Time
+	dispatchedAt time.Time
+	firstChunkAt time.Time
+	cacheLookup  time.Duration
 
 	history []chatMessage
-	handoff pipelineHandoff
+	// context is a conversation imported from multimodel chat that each run starts with.
+	context         []chatMessage
+	handoff         pipelineHandoff
+	handoffSelector string
+	handoffTemplate string
+
+	retries       int
+	fallbackHost  Host
+	fallbackModel string
+	attempt       int
+	retriesUsed   int
+	usingFallback bool
+	attemptErrors []string
 }
 
 // pipelineCacheEntry memoizes a stage response for reuse within the session.
@@ -195,6 +229,17 @@ type pipelineCacheEntry struct {
 	timestamp time.Time
 }
 
+// pipelineRunExport is the JSON document written for a pipeline run.
+type pipelineRunExport struct {
+	RunStarted   time.Time              `json:"runStarted"`
+	RunCompleted time.Time              `json:"runCompleted"`
+	Cancelled    bool                   `json:"cancelled,omitempty"`
+	Tag          string                 `json:"tag,omitempty"`
+	Note         string                 `json:"note,omitempty"`
+	JSONMode     bool                   `json:"jsonMode"`
+	Stages       []pipelineExportRecord `json:"stages"`
+}
+
 // pipelineExportRecord captures per-stage export data.
 type pipelineExportRecord struct {
 	Stage             int           `json:"stage"`
@@ -208,21 +253,34 @@ type pipelineExportRecord struct {
 	HandoffPayload    string        `json:"handoff"`
 	CacheHit          bool          `json:"cacheHit"`
 	TruncationSummary string        `json:"truncationSummary,omitempty"`
+	HandoffMode       string        `json:"handoffMode,omitempty"`
+	HandoffSelector   string        `json:"handoffSelector,omitempty"`
+	HandoffTemplate   string        `json:"handoffTemplate,omitempty"`
+	Neutralized       []string      `json:"neutralized,omitempty"`
+	Retries           int           `json:"retries,omitempty"`
+	FallbackFrom      string        `json:"fallbackFrom,omitempty"`
+	AttemptErrors     []string      `json:"attemptErrors,omitempty"`
 }
 
 // exportTimings captures timing metrics for an exported pipeline stage.
 type exportTimings struct {
-	TotalSeconds      float64 `json:"totalSeconds"`
-	LoadSeconds       float64 `json:"loadSeconds"`
-	PromptEvalSeconds float64 `json:"promptEvalSeconds"`
-	EvalSeconds       float64 `json:"evalSeconds"`
-	TimeToFirstToken  float64 `json:"timeToFirstToken"`
+	TotalSeconds      float64       `json:"totalSeconds"`
+	LoadSeconds       float64       `json:"loadSeconds"`
+	PromptEvalSeconds float64       `json:"promptEvalSeconds"`
+	EvalSeconds       float64       `json:"evalSeconds"`
+	TimeToFirstToken  float64       `json:"timeToFirstToken"`
+	Breakdown         *stageLatency `json:"breakdown,omitempty"`
 }
 
 // exportTokens captures token counts for an exported pipeline stage.
 type exportTokens struct {
 	Prompt int `json:"prompt"`
 	Eval   int `json:"eval"`
+	// Handoff is the size of the payload passed to the next stage.
+	Handoff int `json:"handoff"`
+	// Estimated is set when the host reported no counts and Prompt and Eval were
+	// estimated with the model's tokenizer.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // pipelineModel owns all state for the pipeline Bubble Tea program.
@@ -233,13 +291,14 @@ type pipelineModel struct {
 	requestTimeout time.Duration
 	mcpStatus      mcpStatus
 	provider       providers.ChatProvider
+	guard          *handoffGuard
 
 	viewState     pipelineViewState
 	focusIndex    int
 	expandedIndex int
 
 	stages      []pipelineStage
-	stageInputs [pipelineStageCount]string
+	stageInputs []string
 
 	spinner   spinner.Model
 	textArea  textarea.Model
@@ -247,9 +306,11 @@ type pipelineModel struct {
 	hostList  list.Model
 	modelList list.Model
 
-	selectingHost  bool
-	selectingModel bool
-	selectedStage  int
+	selectingHost     bool
+	selectingModel    bool
+	assigningFallback bool
+	selectedStage     int
+	warmState         warmStateMsg
 
 	width, height    int
 	program          *tea.Program
@@ -257,15 +318,31 @@ type pipelineModel struct {
 
 	statusBanner  string
 	runInProgress bool
+	logPane       logPane
+	quit          quitPrompt
+	// approval asks the user before a stage's model runs a tool that requires it.
+	approval approvalPrompt
+
+	// runCtx is cancelled, through runCancel, when the user quits by cancelling the run.
+	runCtx       context.Context
+	runCancel    context.CancelFunc
+	runCancelled bool
 
 	showHandoffOverlay bool
 	overlayStageIndex  int
 
+	editingHandoff  bool
+	handoffEditMode pipelineHandoffMode
+	handoffEditor   textinput.Model
+
 	memoCache map[string]pipelineCacheEntry
 
 	exportRecords      []pipelineExportRecord
 	exportPath         string
 	exportMarkdownPath string
+	historyDir         string
+	runTag             string
+	runNote            string
 	runStarted         time.Time
 	runCompleted       time.Time
 
@@ -276,7 +353,7 @@ type pipelineModel struct {
 	globalDefaultModel string
 }
 
-// initialPipelineModel constructs a model with sensible defaults and four stages.
+// initialPipelineModel constructs a model with sensible defaults and the configured number of stages.
 func initialPipelineModel(ctx context.Context, cfg *Config, provider providers.ChatProvider) *pipelineModel {
 	timeout := cfg.RequestTimeout()
 
@@ -293,16 +370,10 @@ func initialPipelineModel(ctx context.Context, cfg *Config, provider providers.C
 
 	vp := viewport.New(100, 5)
 
-	stages := make([]pipelineStage, pipelineStageCount)
+	stages := make([]pipelineStage, cfg.PipelineStageCount())
 	for i := range stages {
-		stages[i] = pipelineStage{
-			index:  i,
-			view:   pipelineStageViewOutput,
-			status: pipelineStageStatusUnassigned,
-			handoff: pipelineHandoff{
-				mode: pipelineHandoffRaw,
-			},
-		}
+		stages[i] = newPipelineStage(i)
+		stages[i].retries = cfg.StageRetryCount()
 	}
 
 	hostItems := make([]list.Item, len(cfg.Hosts))
@@ -315,12 +386,18 @@ func initialPipelineModel(ctx context.Context, cfg *Config, provider providers.C
 	modelList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
 	modelList.Title = "Select a Model"
 
+	guard, err := newHandoffGuard(cfg.HandoffGuard)
+	if err != nil {
+		pipelineLog.Errorf("%v; handoff guard disabled", err)
+	}
+
 	return &pipelineModel{
 		ctx:                ctx,
 		config:             cfg,
 		requestTimeout:     timeout,
 		mcpStatus:          deriveMCPStatus(cfg, provider),
 		provider:           provider,
+		guard:              guard,
 		viewState:          pipelineViewAssignment,
 		focusIndex:         0,
 		expandedIndex:      -1,
@@ -335,6 +412,7 @@ func initialPipelineModel(ctx context.Context, cfg *Config, provider providers.C
 		memoCache:          make(map[string]pipelineCacheEntry),
 		exportPath:         cfg.ExportPath,
 		exportMarkdownPath: cfg.ExportMarkdownPath,
+		historyDir:         cfg.PipelineHistoryDir,
 		nextHostIndex:      0,
 		defaultModelByHost: make(map[string]string),
 	}
@@ -342,7 +420,7 @@ func initialPipelineModel(ctx context.Context, cfg *Config, provider providers.C
 
 // Init satisfies the tea.Model interface.
 func (m *pipelineModel) Init() tea.Cmd {
-	return nil
+	return fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts)
 }
 
 // pipelineStageChunkMsg is a message indicating a new chunk of output from a pipeline stage.
@@ -353,9 +431,11 @@ type pipelineStageChunkMsg struct {
 
 // pipelineStageDoneMsg is a message indicating a pipeline stage has completed.
 type pipelineStageDoneMsg struct {
-	Stage  int
-	Output string
-	Meta   LLMResponseMeta
+	Stage        int
+	Output       string
+	Meta         LLMResponseMeta
+	DispatchedAt time.Time
+	FirstChunkAt time.Time
 }
 
 // pipelineStageErrorMsg is a message indicating an error occurred in a pipeline stage.
@@ -364,17 +444,94 @@ type pipelineStageErrorMsg struct {
 	Err   error
 }
 
+// pipelineContextNoticeMsg reports what the context window policy did to a stage's request.
+type pipelineContextNoticeMsg struct {
+	Stage  int
+	Notice string
+}
+
 // pipelineStageCacheHitMsg is a message indicating a pipeline stage result was retrieved from cache.
 type pipelineStageCacheHitMsg struct {
 	Stage int
 	Entry pipelineCacheEntry
 }
 
-// Update routes incoming messages to the appropriate handlers.
+// Update routes incoming messages to the appropriate handlers, quitting once a run the user
+// chose to wait for has finished.
 func (m *pipelineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m.quit.settled(m.runInProgress) {
+		return model, tea.Quit
+	}
+	return model, cmd
+}
+
+// update routes incoming messages to the appropriate handlers.
+func (m *pipelineModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if choice, handled := m.quit.handleKey(msg.String()); handled {
+			return m, m.applyQuitChoice(choice)
+		}
+		if m.approval.handleKey(msg.String()) {
+			return m, nil
+		}
+		if handled, cmd := m.logPane.handleKey(msg.String()); handled {
+			return m, cmd
+		}
+
+	case toolApprovalMsg:
+		m.approval.add(msg)
+		return m, nil
+
+	case logPaneTickMsg:
+		return m, m.logPane.handleTick()
+
+	case warmStateMsg:
+		m.warmState = msg
+		hostItems := make([]list.Item, len(m.config.Hosts))
+		for i, host := range m.config.Hosts {
+			hostItems[i] = hostSelectorItem{index: i, host: host, desc: describeHost(host, m.warmState)}
+		}
+		return m, m.hostList.SetItems(hostItems)
+
+	case modelsRefreshedMsg:
+		m.statusBanner = applyModelsRefresh(m.config, msg)
+		for i := range m.stages {
+			stage := &m.stages[i]
+			if stage.hasAssignment {
+				stage.host.Models = hostModels(m.config, stage.host.Name)
+				stage.availableModels = append([]string(nil), stage.host.Models...)
+			}
+		}
+		return m, nil
+
+	case configReloadMsg:
+		status, ok := applyConfigReload(m.config, msg)
+		m.statusBanner = status
+		if !ok {
+			return m, nil
+		}
+		for i := range m.stages {
+			if stage := &m.stages[i]; stage.hasAssignment {
+				stage.host = reloadedHost(m.config, stage.host)
+			}
+		}
+		m.requestTimeout = m.config.RequestTimeout()
+		if m.client != nil {
+			m.client.Timeout = m.requestTimeout
+		}
+		return m, tea.Batch(reloadProviderCmd(m.config), refreshModelsCmd(m.config), fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts))
+
+	case providerReloadMsg:
+		if status := applyProviderReload(m.provider, msg); status != "" {
+			m.statusBanner = status
+		}
+		m.mcpStatus = deriveMCPStatus(m.config, m.provider)
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		m.hostList.SetSize(msg.Width-2, m.height-6)
@@ -398,9 +555,16 @@ func (m *pipelineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case pipelineStageErrorMsg:
-		m.handleStageError(msg)
+		return m, m.handleStageError(msg)
+
+	case pipelineContextNoticeMsg:
+		pipelineLog.Warnf("context window: stage=%d: %s", msg.Stage+1, msg.Notice)
+		m.statusBanner = fmt.Sprintf("Stage %d: %s", msg.Stage+1, msg.Notice)
 		return m, nil
 
+	case pipelineStageRetryMsg:
+		return m, m.handleStageRetry(msg)
+
 	case pipelineStageCacheHitMsg:
 		cmd := m.handleStageCacheHit(msg)
 		if cmd != nil {
@@ -443,7 +607,10 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 		if km, ok := msg.(tea.KeyMsg); ok {
 			switch km.String() {
 			case "enter":
-				if item, ok := m.hostList.SelectedItem().(hostSelectorItem); ok {
+				if item, ok := m.hostList.SelectedItem().(hostSelectorItem); ok && m.assigningFallback {
+					m.selectingHost = false
+					m.selectingModel = m.openFallbackModels(item.host)
+				} else if ok {
 					stage := &m.stages[m.selectedStage]
 					stage.host = item.host
 					stage.hostIndex = item.index
@@ -458,7 +625,7 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 					m.modelList.SetItems(nil)
 					modelItems := make([]list.Item, len(stage.availableModels))
 					for i, model := range stage.availableModels {
-						modelItems[i] = modelSelectorItem{name: model}
+						modelItems[i] = modelSelectorItem{name: model, loaded: m.warmState[stage.host.Name].isLoaded(model), caps: m.warmState[stage.host.Name].capabilitiesOf(model)}
 					}
 					m.modelList.SetItems(modelItems)
 					if len(modelItems) > 0 {
@@ -485,6 +652,7 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 				}
 			case "esc":
 				m.selectingHost = false
+				m.assigningFallback = false
 			}
 		}
 		return cmd
@@ -496,7 +664,9 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 		if km, ok := msg.(tea.KeyMsg); ok {
 			switch km.String() {
 			case "enter":
-				if item, ok := m.modelList.SelectedItem().(modelSelectorItem); ok {
+				if item, ok := m.modelList.SelectedItem().(modelSelectorItem); ok && m.assigningFallback {
+					m.assignFallback(item.name)
+				} else if ok {
 					stage := &m.stages[m.selectedStage]
 					stage.selectedModel = item.name
 					stage.hasAssignment = true
@@ -521,12 +691,13 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 						m.nextHostIndex = (stage.hostIndex + 1) % len(m.config.Hosts)
 					}
 
-					if m.selectedStage < pipelineStageCount-1 {
+					if m.selectedStage < len(m.stages)-1 {
 						m.selectedStage++
 					}
 				}
 			case "esc":
 				m.selectingModel = false
+				m.assigningFallback = false
 			}
 		}
 		return cmd
@@ -535,13 +706,13 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 	if km, ok := msg.(tea.KeyMsg); ok {
 		switch km.String() {
 		case "ctrl+c", "q":
-			return tea.Quit
+			return m.requestQuit()
 		case "up", "k":
 			if m.selectedStage > 0 {
 				m.selectedStage--
 			}
 		case "down", "j":
-			if m.selectedStage < pipelineStageCount-1 {
+			if m.selectedStage < len(m.stages)-1 {
 				m.selectedStage++
 			}
 		case "enter", "h":
@@ -557,7 +728,7 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 				m.hostList.Select(idx)
 			}
 			m.selectingHost = true
-			return nil
+			return fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts)
 		case "m":
 			stage := &m.stages[m.selectedStage]
 			if !stage.hasAssignment {
@@ -566,7 +737,7 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 			}
 			modelItems := make([]list.Item, len(stage.availableModels))
 			for i, model := range stage.availableModels {
-				modelItems[i] = modelSelectorItem{name: model}
+				modelItems[i] = modelSelectorItem{name: model, loaded: m.warmState[stage.host.Name].isLoaded(model), caps: m.warmState[stage.host.Name].capabilitiesOf(model)}
 			}
 			m.modelList.SetItems(modelItems)
 			if len(modelItems) > 0 {
@@ -589,6 +760,20 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 				m.modelList.Select(sel)
 				m.selectingModel = true
 			}
+		case "f":
+			return m.startFallbackPick()
+		case "x":
+			m.clearFallback()
+		case "R":
+			m.cycleStageRetries()
+		case "+", "=":
+			m.insertStage()
+		case "-":
+			m.removeStage()
+		case "a":
+			m.autoAssignStages()
+		case "r":
+			return refreshModelsCmd(m.config)
 		case "d":
 			stage := &m.stages[m.selectedStage]
 			stage.host = Host{}
@@ -597,7 +782,10 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 			stage.status = pipelineStageStatusUnassigned
 			stage.statusMessage = ""
 			stage.availableModels = nil
+			stage.fallbackHost = Host{}
+			stage.fallbackModel = ""
 			stage.history = nil
+			stage.context = nil
 			stage.outputBuffer.Reset()
 			stage.finalOutput = ""
 		case "c":
@@ -615,7 +803,7 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 				m.focusIndex = 0
 			}
 			m.textArea.Focus()
-			m.statusBanner = ""
+			m.statusBanner = strings.Join(m.capabilityWarnings(), " | ")
 			return nil
 		}
 	}
@@ -625,13 +813,19 @@ func (m *pipelineModel) updateAssignment(msg tea.Msg) tea.Cmd {
 
 // updateActive handles interactions while the pipeline view is visible.
 func (m *pipelineModel) updateActive(msg tea.Msg) tea.Cmd {
+	if m.editingHandoff {
+		return m.updateHandoffEditor(msg)
+	}
 	textFocused := m.textArea.Focused()
 
 	switch km := msg.(type) {
 	case tea.KeyMsg:
+		if index, ok := promptMacroIndex(km.String(), textFocused); ok {
+			return m.runPromptMacro(index)
+		}
 		switch km.String() {
 		case "ctrl+c", "ctrl+q":
-			return tea.Quit
+			return m.requestQuit()
 		case "left":
 			if !textFocused {
 				m.moveFocus(-1)
@@ -664,6 +858,14 @@ func (m *pipelineModel) updateActive(msg tea.Msg) tea.Cmd {
 					m.overlayStageIndex = m.focusIndex
 				}
 			}
+		case "s":
+			if !textFocused {
+				return m.startHandoffEdit(pipelineHandoffSelector)
+			}
+		case "t":
+			if !textFocused {
+				return m.startHandoffEdit(pipelineHandoffTemplate)
+			}
 		case "tab":
 			if textFocused {
 				break
@@ -681,6 +883,10 @@ func (m *pipelineModel) updateActive(msg tea.Msg) tea.Cmd {
 		case "enter":
 			if m.textArea.Focused() && !m.runInProgress {
 				input := strings.TrimSpace(m.textArea.Value())
+				if m.applyRunAnnotation(input) {
+					m.textArea.Reset()
+					return nil
+				}
 				if input != "" {
 					return m.startPipelineRun(input)
 				}
@@ -735,6 +941,13 @@ func (m *pipelineModel) updateActive(msg tea.Msg) tea.Cmd {
 					notices = append(notices, fmt.Sprintf("Markdown → %s", markdownPath))
 				}
 			}
+			if dir := strings.TrimSpace(m.historyDir); dir != "" {
+				if path, err := m.archivePipelineRun(dir); err != nil {
+					notices = append(notices, fmt.Sprintf("History archive failed: %v", err))
+				} else {
+					notices = append(notices, fmt.Sprintf("History → %s", path))
+				}
+			}
 			m.statusBanner = strings.Join(notices, " | ")
 		}
 	}
@@ -790,6 +1003,12 @@ func (m *pipelineModel) updateExpanded(msg tea.Msg) tea.Cmd {
 
 // View renders the current pipeline view.
 func (m *pipelineModel) View() string {
+	view := m.approval.attach(m.logPane.attach(m.renderView(), m.width, m.height), m.width, m.height)
+	return m.quit.attach(view, m.width, m.height)
+}
+
+// renderView renders the active pipeline screen without the log pane.
+func (m *pipelineModel) renderView() string {
 	if m.width == 0 {
 		return "Initializing pipeline mode..."
 	}
@@ -838,12 +1057,16 @@ func (m *pipelineModel) assignmentView() string {
 			builder.WriteString("  ")
 			builder.WriteString(stageStatusStyles[stage.status].Render(stage.statusMessage))
 		}
+		if label := stageRecoveryLabel(stage); label != "" {
+			builder.WriteString("  ")
+			builder.WriteString(lipgloss.NewStyle().Faint(true).Render(label))
+		}
 
 		builder.WriteString("\n")
 	}
 
 	builder.WriteString("\n")
-	help := "↑/↓ select stage  Enter/h pick host  m pick model  d clear  c continue  q quit"
+	help := "↑/↓ select stage  Enter/h pick host  m pick model  f/x set/clear fallback  R cycle retries  +/- add/remove stage  a auto-assign  r refresh models  d clear  c continue  q quit"
 	if m.statusBanner != "" {
 		builder.WriteString(bannerStyle.Render(m.statusBanner) + "\n")
 	}
@@ -863,7 +1086,11 @@ func (m *pipelineModel) assignmentView() string {
 func (m *pipelineModel) pipelineView() string {
 	var parts []string
 
-	parts = append(parts, m.renderProgressLine())
+	progress := m.renderProgressLine()
+	if label := m.runAnnotationLabel(); label != "" {
+		progress += "  " + lipgloss.NewStyle().Faint(true).Render(label)
+	}
+	parts = append(parts, progress)
 	if m.statusBanner != "" {
 		parts = append(parts, bannerStyle.Render(m.statusBanner))
 	}
@@ -891,11 +1118,19 @@ func (m *pipelineModel) pipelineView() string {
 	if m.runInProgress {
 		timer := fmt.Sprintf("%.1fs", time.Since(m.requestStartTime).Seconds())
 		parts = append(parts, fmt.Sprintf("%s Running pipeline... %s", m.spinner.View(), timer))
+	} else if m.editingHandoff {
+		parts = append(parts, m.handoffEditor.View())
 	} else {
 		parts = append(parts, m.textArea.View())
 	}
 
-	help := "Enter send  Ctrl+←/→ focus  Ctrl+Enter expand  Ctrl+S cycle  Ctrl+O overlay  Ctrl+P multimodel  Ctrl+E export  Ctrl+Q quit"
+	help := "Enter send  /tag /note annotate  Ctrl+←/→ focus  Ctrl+Enter expand  Ctrl+S cycle  Ctrl+O overlay  Esc then s/t selector/template  Ctrl+P multimodel  Ctrl+E export  Ctrl+Q quit"
+	if m.editingHandoff {
+		help = "Enter save (empty clears)  Esc cancel  Selector: $.field[0].name  Template: {{.Output}} {{.Selected}} {{.JSON.field}} {{.Input}} {{json .JSON}} {{select \"a.b\" .JSON}}"
+	}
+	if macros := m.promptMacroHelp(); macros != "" {
+		help += "\n" + macros
+	}
 	parts = append(parts, lipgloss.NewStyle().Faint(true).Render(help))
 
 	return lipgloss.NewStyle().Margin(1, 2).Render(strings.Join(parts, "\n\n"))
@@ -977,7 +1212,7 @@ func (m *pipelineModel) renderProgressLine() string {
 
 // renderStageColumns renders the columns for each pipeline stage.
 func (m *pipelineModel) renderStageColumns(targetHeight int) string {
-	colWidth := util.Max(30, (m.width-8)/pipelineStageCount)
+	colWidth := util.Max(minStageColumnWidth, (m.width-8)/util.Max(1, len(m.stages)))
 	var columns []string
 
 	for i, stage := range m.stages {
@@ -1082,7 +1317,9 @@ func (m *pipelineModel) renderStageStats(stage pipelineStage) string {
 		fmt.Sprintf("Prompt Eval: %.2fs (%d tokens)", prompt, stage.stats.PromptEvalCount),
 		fmt.Sprintf("Eval: %.2fs (%d tokens)", eval, stage.stats.EvalCount),
 		fmt.Sprintf("Tokens/s: %.2f", tokensPerSecond),
+		fmt.Sprintf("Sampling: %s", formatSamplingSummary(stage.parameters)),
 	}
+	stats = append(stats, computeStageLatency(stage).lines()...)
 
 	return strings.Join(stats, "\n")
 }
@@ -1092,14 +1329,13 @@ func (m *pipelineModel) renderHandoffOverlay(stage pipelineStage) string {
 	var builder strings.Builder
 
 	builder.WriteString(fmt.Sprintf("Stage %d handoff", stage.index+1) + "\n")
-	mode := "raw"
-	switch stage.handoff.mode {
-	case pipelineHandoffSelector:
-		mode = "selector"
-	case pipelineHandoffTemplate:
-		mode = "template"
-	}
-	builder.WriteString(fmt.Sprintf("Mode: %s\n", mode))
+	builder.WriteString(fmt.Sprintf("Mode: %s\n", stage.handoff.mode))
+	if stage.handoffSelector != "" {
+		builder.WriteString(fmt.Sprintf("Selector: %s\n", stage.handoffSelector))
+	}
+	if stage.handoffTemplate != "" {
+		builder.WriteString(fmt.Sprintf("Template: %s\n", stage.handoffTemplate))
+	}
 	builder.WriteString(fmt.Sprintf("Tokens: %d\n", stage.handoff.tokenCount))
 	if stage.handoff.truncated {
 		builder.WriteString(stage.handoff.truncationSummary + "\n")
@@ -1118,8 +1354,12 @@ func (m *pipelineModel) renderHandoffOverlay(stage pipelineStage) string {
 // moveFocus shifts the focus between pipeline stages.
 func (m *pipelineModel) moveFocus(delta int) {
 	newIndex := m.focusIndex
-	for attempt := 0; attempt < pipelineStageCount; attempt++ {
-		newIndex = (newIndex + delta + pipelineStageCount) % pipelineStageCount
+	count := len(m.stages)
+	if count == 0 {
+		return
+	}
+	for attempt := 0; attempt < count; attempt++ {
+		newIndex = (newIndex + delta + count) % count
 		if newIndex >= 0 && newIndex < len(m.stages) {
 			break
 		}
@@ -1134,7 +1374,12 @@ func (m *pipelineModel) startPipelineRun(input string) tea.Cmd {
 	m.requestStartTime = time.Now()
 	m.runStarted = time.Now()
 	m.runCompleted = time.Time{}
+	m.runCancelled = false
 	m.exportRecords = nil
+	if m.runCancel != nil {
+		m.runCancel()
+	}
+	m.runCtx, m.runCancel = context.WithCancel(m.ctx)
 	m.textArea.Reset()
 	m.textArea.Blur()
 	m.statusBanner = ""
@@ -1148,11 +1393,16 @@ func (m *pipelineModel) startPipelineRun(input string) tea.Cmd {
 		stage.firstToken = time.Time{}
 		stage.completedAt = time.Time{}
 		stage.startedAt = time.Time{}
+		stage.queuedAt = time.Time{}
+		stage.dispatchedAt = time.Time{}
+		stage.firstChunkAt = time.Time{}
+		stage.cacheLookup = 0
 		stage.handoff = pipelineHandoff{mode: pipelineHandoffRaw}
+		stage.resetStageAttempts()
 		if stage.hasAssignment {
 			stage.status = pipelineStageStatusWaiting
 			stage.statusMessage = "Waiting"
-			stage.history = []chatMessage{{Role: "user", Content: input}}
+			stage.history = append(append([]chatMessage(nil), stage.context...), chatMessage{Role: "user", Content: input})
 		} else {
 			stage.status = pipelineStageStatusSkipped
 			stage.statusMessage = "Skipped"
@@ -1160,7 +1410,7 @@ func (m *pipelineModel) startPipelineRun(input string) tea.Cmd {
 		}
 	}
 
-	m.stageInputs = [pipelineStageCount]string{}
+	m.stageInputs = make([]string, len(m.stages))
 
 	first := m.firstAssignedStage()
 	if first == -1 {
@@ -1195,15 +1445,30 @@ func (m *pipelineModel) queueStage(index int) tea.Cmd {
 		return m.advanceToNextStage(index, payload)
 	}
 
-	cacheKey := makeCacheKey(index, stage.host.URL, stage.selectedModel, payload)
-	if entry, ok := m.memoCache[cacheKey]; ok {
+	stage.queuedAt = time.Now()
+	cacheKey := makeCacheKey(index, stage.host.URL, stage.selectedModel, stage.cachePayload(payload))
+	entry, ok := m.memoCache[cacheKey]
+	stage.cacheLookup = time.Since(stage.queuedAt)
+	if ok {
 		stage.cacheHit = true
 		return func() tea.Msg { return pipelineStageCacheHitMsg{Stage: index, Entry: entry} }
 	}
 
-	stage.status = pipelineStageStatusRunning
 	stage.statusMessage = "Running"
+	return m.dispatchStage(index)
+}
+
+// dispatchStage streams the stage's input to its current target, discarding any partial
+// output from an earlier attempt. The status chip keeps a retry or fallback message.
+func (m *pipelineModel) dispatchStage(index int) tea.Cmd {
+	payload := ""
+	if index < len(m.stageInputs) {
+		payload = m.stageInputs[index]
+	}
+	stage := &m.stages[index]
+	stage.status = pipelineStageStatusRunning
 	stage.startedAt = time.Now()
+	stage.firstToken = time.Time{}
 	stage.cacheHit = false
 	stage.outputBuffer.Reset()
 
@@ -1214,7 +1479,17 @@ func (m *pipelineModel) queueStage(index int) tea.Cmd {
 		}
 	}
 
-	return pipelineStreamStageCmd(m.ctx, m.program, m.provider, index, stage.host, stage.selectedModel, messages, stage.systemPrompt, stage.parameters, payload, m.config.JSONMode, m.requestTimeout)
+	systemPrompt := stage.systemPrompt
+	if isGuardedHandoff(payload) {
+		systemPrompt = guardedSystemPrompt(systemPrompt)
+	}
+
+	ctx := m.runCtx
+	if ctx == nil {
+		ctx = m.ctx
+	}
+	host, model := stage.target()
+	return pipelineStreamStageCmd(ctx, m.program, m.provider, m.config, index, host, model, messages, systemPrompt, stage.parameters, payload, m.config.JSONMode, m.requestTimeout)
 }
 
 // advanceToNextStage moves the pipeline to the next assigned stage.
@@ -1227,6 +1502,7 @@ func (m *pipelineModel) advanceToNextStage(current int, payload string) tea.Cmd
 			m.runCompleted = time.Now()
 		}
 		m.autoExport()
+		m.recordUsage()
 		m.textArea.Focus()
 		return nil
 	}
@@ -1237,6 +1513,50 @@ func (m *pipelineModel) advanceToNextStage(current int, payload string) tea.Cmd
 	return m.queueStage(next)
 }
 
+// requestQuit quits, or asks how to when a run is in progress.
+func (m *pipelineModel) requestQuit() tea.Cmd {
+	if m.quit.request(m.runInProgress) {
+		return tea.Quit
+	}
+	return nil
+}
+
+// applyQuitChoice carries out the answer to the quit prompt.
+func (m *pipelineModel) applyQuitChoice(choice quitChoice) tea.Cmd {
+	switch choice {
+	case quitForce:
+		return tea.Quit
+	case quitCancel:
+		m.cancelRun()
+		return tea.Quit
+	}
+	return nil
+}
+
+// cancelRun stops the run in progress and exports the stages that finished before it,
+// marking the export as cancelled.
+func (m *pipelineModel) cancelRun() {
+	if !m.runInProgress {
+		return
+	}
+	if m.runCancel != nil {
+		m.runCancel()
+	}
+	for i := range m.stages {
+		stage := &m.stages[i]
+		if stage.status == pipelineStageStatusWaiting || stage.status == pipelineStageStatusRunning {
+			stage.status = pipelineStageStatusSkipped
+			stage.statusMessage = "Cancelled"
+		}
+	}
+	m.runInProgress = false
+	m.runCancelled = true
+	m.viewState = pipelineViewReady
+	m.runCompleted = time.Now()
+	m.autoExport()
+	m.recordUsage()
+}
+
 // handleStageChunk processes a new chunk of output from a pipeline stage.
 func (m *pipelineModel) handleStageChunk(msg pipelineStageChunkMsg) {
 	if msg.Stage < 0 || msg.Stage >= len(m.stages) {
@@ -1258,21 +1578,19 @@ func (m *pipelineModel) handleStageDone(msg pipelineStageDoneMsg) tea.Cmd {
 	stage := &m.stages[msg.Stage]
 	stage.finalOutput = stage.outputBuffer.String()
 	stage.stats = msg.Meta
+	stage.dispatchedAt = msg.DispatchedAt
+	stage.firstChunkAt = msg.FirstChunkAt
 	stage.status = pipelineStageStatusDone
 	stage.statusMessage = m.formatCompletionStatus(msg.Meta)
+	if stage.usingFallback {
+		stage.statusMessage = "Fallback: " + stage.fallbackHost.Name + " • " + stage.statusMessage
+	}
 	stage.completedAt = time.Now()
 
 	stage.history = append(stage.history, chatMessage{Role: "assistant", Content: stage.finalOutput})
 
-	if !m.prepareHandoff(stage) {
-		stage.status = pipelineStageStatusError
-		stage.statusMessage = "JSON validation failed"
-		m.runInProgress = false
-		m.viewState = pipelineViewReady
-		if m.runCompleted.IsZero() {
-			m.runCompleted = time.Now()
-		}
-		m.textArea.Focus()
+	if err := m.prepareHandoff(stage); err != nil {
+		m.failHandoff(stage, err)
 		return nil
 	}
 
@@ -1281,7 +1599,8 @@ func (m *pipelineModel) handleStageDone(msg pipelineStageDoneMsg) tea.Cmd {
 		inbound = m.stageInputs[msg.Stage]
 	}
 
-	cacheKey := makeCacheKey(msg.Stage, stage.host.URL, stage.selectedModel, inbound)
+	host, model := stage.target()
+	cacheKey := makeCacheKey(msg.Stage, host.URL, model, stage.cachePayload(inbound))
 	m.memoCache[cacheKey] = pipelineCacheEntry{output: stage.finalOutput, meta: msg.Meta, handoff: stage.handoff, timestamp: time.Now()}
 
 	m.exportRecords = append(m.exportRecords, m.buildExportRecord(msg.Stage, stage))
@@ -1289,21 +1608,28 @@ func (m *pipelineModel) handleStageDone(msg pipelineStageDoneMsg) tea.Cmd {
 	return m.advanceToNextStage(msg.Stage, stage.handoff.payload)
 }
 
-// handleStageError processes an error in a pipeline stage.
-func (m *pipelineModel) handleStageError(msg pipelineStageErrorMsg) {
+// handleStageError processes an error in a pipeline stage, retrying it or switching to its
+// fallback when it has either left; otherwise the run stops.
+func (m *pipelineModel) handleStageError(msg pipelineStageErrorMsg) tea.Cmd {
 	if msg.Stage < 0 || msg.Stage >= len(m.stages) {
-		return
+		return nil
 	}
 	stage := &m.stages[msg.Stage]
+	if cmd, ok := m.recoverStage(stage, msg.Err); ok {
+		return cmd
+	}
+	host, model := stage.target()
 	stage.status = pipelineStageStatusError
 	stage.statusMessage = "Error"
-	m.statusBanner = fmt.Sprintf("Stage %d error: %v", stage.index+1, msg.Err)
+	m.statusBanner = fmt.Sprintf("Stage %d error: %s", stage.index+1, providers.UserMessage(msg.Err))
+	pipelineLog.Errorf("pipeline stage %d failed: host=%s model=%s: %v", stage.index+1, host.Name, model, msg.Err)
 	m.runInProgress = false
 	m.viewState = pipelineViewReady
 	if m.runCompleted.IsZero() {
 		m.runCompleted = time.Now()
 	}
 	m.textArea.Focus()
+	return nil
 }
 
 // handleStageCacheHit processes a cache hit for a pipeline stage.
@@ -1320,18 +1646,24 @@ func (m *pipelineModel) handleStageCacheHit(msg pipelineStageCacheHitMsg) tea.Cm
 	stage.cacheHit = true
 	stage.completedAt = time.Now()
 	stage.history = append(stage.history, chatMessage{Role: "assistant", Content: stage.finalOutput})
+	// Rebuild the handoff since the stage's selector or template may have changed since the entry was cached.
+	if err := m.prepareHandoff(stage); err != nil {
+		m.failHandoff(stage, err)
+		return nil
+	}
 
 	m.exportRecords = append(m.exportRecords, m.buildExportRecord(msg.Stage, stage))
 
 	return m.advanceToNextStage(msg.Stage, stage.handoff.payload)
 }
 
-// prepareHandoff prepares the data to be handed off to the next pipeline stage.
-func (m *pipelineModel) prepareHandoff(stage *pipelineStage) bool {
+// prepareHandoff prepares the data to be handed off to the next pipeline stage,
+// applying the stage's selector and template when set.
+func (m *pipelineModel) prepareHandoff(stage *pipelineStage) error {
 	payload := strings.TrimSpace(stage.finalOutput)
 	if payload == "" {
 		stage.handoff = pipelineHandoff{mode: pipelineHandoffRaw, payload: "", preview: "(empty)", tokenCount: 0}
-		return true
+		return nil
 	}
 
 	if m.config.JSONMode {
@@ -1340,34 +1672,84 @@ func (m *pipelineModel) prepareHandoff(stage *pipelineStage) bool {
 			if ok {
 				payload = repaired
 			} else {
-				return false
+				return errHandoffInvalidJSON
 			}
 		}
 	}
 
-	tokens := len(strings.Fields(payload))
-	truncated := false
-	if tokens > pipelineMaxHandoffTokens {
-		truncated = true
-		fields := strings.Fields(payload)
-		payload = strings.Join(fields[len(fields)-pipelineMaxHandoffTokens:], " ")
+	mode := stage.handoffMode()
+	if mode != pipelineHandoffRaw {
+		input := ""
+		if stage.index < len(m.stageInputs) {
+			input = m.stageInputs[stage.index]
+		}
+		shaped, err := shapeHandoff(*stage, payload, input)
+		if err != nil {
+			return err
+		}
+		payload = shaped
+	}
+
+	var neutralized []string
+	if m.guard != nil {
+		payload, neutralized = m.guard.sanitize(payload)
+		for _, n := range neutralized {
+			pipelineLog.Infof("pipeline stage %d handoff: %s", stage.index+1, n)
+		}
 	}
 
+	tokenizer := m.handoffTokenizer(stage)
+	payload, truncated := tokenizer.Tail(payload, pipelineMaxHandoffTokens)
+	tokens := tokenizer.Count(payload)
+
 	preview := util.TruncateRunes(payload, pipelinePreviewRunes)
 	summary := ""
 	if truncated {
 		summary = fmt.Sprintf("Truncated (tail, %d tokens)", pipelineMaxHandoffTokens)
 	}
+	if m.guard != nil {
+		payload = wrapHandoff(payload)
+	}
 
 	stage.handoff = pipelineHandoff{
-		mode:              pipelineHandoffRaw,
+		mode:              mode,
 		payload:           payload,
 		preview:           preview,
 		truncated:         truncated,
 		truncationSummary: summary,
-		tokenCount:        util.Min(tokens, pipelineMaxHandoffTokens),
+		redactions:        neutralized,
+		tokenCount:        tokens,
+	}
+	return nil
+}
+
+// handoffTokenizer returns the tokenizer of the model that receives stage's handoff, or
+// of stage's own model when it is the last stage.
+func (m *pipelineModel) handoffTokenizer(stage *pipelineStage) tokencount.Tokenizer {
+	_, model := stage.target()
+	if next := stage.index + 1; next < len(m.stages) {
+		if _, nextModel := m.stages[next].target(); nextModel != "" {
+			model = nextModel
+		}
+	}
+	return m.config.TokenizerFor(model)
+}
+
+// failHandoff marks a stage whose handoff could not be prepared and stops the run.
+func (m *pipelineModel) failHandoff(stage *pipelineStage, err error) {
+	stage.status = pipelineStageStatusError
+	if errors.Is(err, errHandoffInvalidJSON) {
+		stage.statusMessage = "JSON validation failed"
+	} else {
+		stage.statusMessage = "Handoff failed"
+		m.statusBanner = fmt.Sprintf("Stage %d handoff: %v", stage.index+1, err)
+	}
+	m.runInProgress = false
+	m.viewState = pipelineViewReady
+	if m.runCompleted.IsZero() {
+		m.runCompleted = time.Now()
 	}
-	return true
+	m.textArea.Focus()
 }
 
 // anyStageAssigned checks if at least one stage in the pipeline has an assignment.
@@ -1390,11 +1772,29 @@ func (m *pipelineModel) preflightAssignments() error {
 			if stage.selectedModel == "" {
 				return fmt.Errorf("Stage %d: model not selected", i+1)
 			}
+			if _, err := checkModelCapabilities(m.config, stage.host, stage.selectedModel, m.warmState[stage.host.Name].capabilitiesOf(stage.selectedModel)); err != nil {
+				return fmt.Errorf("Stage %d: %s", i+1, providers.UserMessage(err))
+			}
 		}
 	}
 	return nil
 }
 
+// capabilityWarnings lists the assigned stages whose models may not honor the enabled
+// modes, such as JSON mode without constrained output.
+func (m *pipelineModel) capabilityWarnings() []string {
+	var warnings []string
+	for i, stage := range m.stages {
+		if !stage.hasAssignment {
+			continue
+		}
+		if warning, _ := checkModelCapabilities(m.config, stage.host, stage.selectedModel, m.warmState[stage.host.Name].capabilitiesOf(stage.selectedModel)); warning != "" {
+			warnings = append(warnings, fmt.Sprintf("Stage %d: %s", i+1, warning))
+		}
+	}
+	return warnings
+}
+
 // firstAssignedStage returns the index of the first assigned stage, or -1 if none are assigned.
 func (m *pipelineModel) firstAssignedStage() int {
 	for i, stage := range m.stages {
@@ -1491,12 +1891,10 @@ func (m *pipelineModel) tokensPerSecond(meta LLMResponseMeta) float64 {
 
 // buildExportRecord creates a pipelineExportRecord for a given stage.
 func (m *pipelineModel) buildExportRecord(idx int, stage *pipelineStage) pipelineExportRecord {
-	hash := fnv.New64a()
-	hash.Write([]byte(stage.systemPrompt))
-	promptHash := fmt.Sprintf("%x", hash.Sum64())
+	promptHash := systemPromptHash(stage.systemPrompt)
 
 	outputHash := fnv.New64a()
-	hash.Write([]byte(stage.finalOutput))
+	outputHash.Write([]byte(stage.finalOutput))
 
 	timings := exportTimings{
 		TotalSeconds:      float64(stage.stats.TotalDuration) / 1e9,
@@ -1507,22 +1905,61 @@ func (m *pipelineModel) buildExportRecord(idx int, stage *pipelineStage) pipelin
 	if !stage.firstToken.IsZero() && !stage.startedAt.IsZero() {
 		timings.TimeToFirstToken = stage.firstToken.Sub(stage.startedAt).Seconds()
 	}
+	if breakdown := computeStageLatency(*stage); breakdown.WallSeconds > 0 {
+		timings.Breakdown = &breakdown
+	}
+
+	host, model := stage.target()
+	fallbackFrom := ""
+	if stage.usingFallback {
+		fallbackFrom = fmt.Sprintf("%s • %s", stage.host.Name, stage.selectedModel)
+	}
 
 	return pipelineExportRecord{
 		Stage:             idx + 1,
-		Host:              stage.host.Name,
-		Model:             stage.selectedModel,
+		Host:              host.Name,
+		Model:             model,
 		Parameters:        stage.parameters,
 		SystemPromptHash:  promptHash,
 		Timings:           timings,
-		Tokens:            exportTokens{Prompt: stage.stats.PromptEvalCount, Eval: stage.stats.EvalCount},
+		Tokens:            m.exportTokenCounts(stage),
 		OutputHash:        fmt.Sprintf("%x", outputHash.Sum64()),
 		HandoffPayload:    stage.handoff.payload,
 		CacheHit:          stage.cacheHit,
 		TruncationSummary: stage.handoff.truncationSummary,
+		HandoffMode:       handoffModeLabel(stage.handoff.mode),
+		HandoffSelector:   stage.handoffSelector,
+		HandoffTemplate:   stage.handoffTemplate,
+		Neutralized:       stage.handoff.redactions,
+		Retries:           stage.retriesUsed,
+		FallbackFrom:      fallbackFrom,
+		AttemptErrors:     append([]string(nil), stage.attemptErrors...),
 	}
 }
 
+// exportTokenCounts returns stage's token counts as the host reported them, estimating
+// them with the model's tokenizer when the host reported none.
+func (m *pipelineModel) exportTokenCounts(stage *pipelineStage) exportTokens {
+	tokens := exportTokens{
+		Prompt:  stage.stats.PromptEvalCount,
+		Eval:    stage.stats.EvalCount,
+		Handoff: stage.handoff.tokenCount,
+	}
+	if tokens.Prompt > 0 || tokens.Eval > 0 {
+		return tokens
+	}
+	_, model := stage.target()
+	tokenizer := m.config.TokenizerFor(model)
+	input := ""
+	if stage.index < len(m.stageInputs) {
+		input = m.stageInputs[stage.index]
+	}
+	tokens.Prompt = tokenizer.CountMessages([]string{stage.systemPrompt, input})
+	tokens.Eval = tokenizer.Count(stage.finalOutput)
+	tokens.Estimated = true
+	return tokens
+}
+
 // autoExport automatically exports pipeline run data if export paths are configured.
 func (m *pipelineModel) autoExport() {
 	if len(m.exportRecords) == 0 {
@@ -1539,34 +1976,34 @@ func (m *pipelineModel) autoExport() {
 			errs = append(errs, fmt.Sprintf("Markdown export failed: %v", err))
 		}
 	}
+	if dir := strings.TrimSpace(m.historyDir); dir != "" {
+		if _, err := m.archivePipelineRun(dir); err != nil {
+			errs = append(errs, fmt.Sprintf("History archive failed: %v", err))
+		}
+	}
 	if len(errs) > 0 {
 		m.statusBanner = strings.Join(errs, " | ")
 	}
 }
 
+// recordUsage adds the finished run and its token totals to the opt-in usage stats.
+func (m *pipelineModel) recordUsage() {
+	event := usage.Event{Kind: usage.KindPipeline, Name: "pipeline", DurationSeconds: m.runCompleted.Sub(m.runStarted).Seconds()}
+	for _, record := range m.exportRecords {
+		event.PromptTokens += record.Tokens.Prompt
+		event.OutputTokens += record.Tokens.Eval
+	}
+	if err := usage.Record(*m.config, event); err != nil {
+		pipelineLog.Errorf("usage stats: %v", err)
+	}
+}
+
 // exportPipelineJSON writes the latest run data to a JSON file.
 func (m *pipelineModel) exportPipelineJSON(path string) error {
 	if len(m.exportRecords) == 0 {
 		return fmt.Errorf("no pipeline run to export")
 	}
-	export := struct {
-		RunStarted   time.Time              `json:"runStarted"`
-		RunCompleted time.Time              `json:"runCompleted"`
-		JSONMode     bool                   `json:"jsonMode"`
-		Stages       []pipelineExportRecord `json:"stages"`
-	}{
-		RunStarted: m.runStarted,
-		RunCompleted: func() time.Time {
-			if m.runCompleted.IsZero() {
-				return time.Now()
-			}
-			return m.runCompleted
-		}(),
-		JSONMode: m.config.JSONMode,
-		Stages:   m.exportRecords,
-	}
-
-	data, err := json.MarshalIndent(export, "", "  ")
+	data, err := json.MarshalIndent(m.buildRunExport(), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -1574,6 +2011,23 @@ func (m *pipelineModel) exportPipelineJSON(path string) error {
 	return util.WriteFile(path, data)
 }
 
+// buildRunExport assembles the JSON document describing the latest run.
+func (m *pipelineModel) buildRunExport() pipelineRunExport {
+	runCompleted := m.runCompleted
+	if runCompleted.IsZero() {
+		runCompleted = time.Now()
+	}
+	return pipelineRunExport{
+		RunStarted:   m.runStarted,
+		RunCompleted: runCompleted,
+		Cancelled:    m.runCancelled,
+		Tag:          m.runTag,
+		Note:         m.runNote,
+		JSONMode:     m.config.JSONMode,
+		Stages:       m.exportRecords,
+	}
+}
+
 // exportPipelineMarkdown writes the latest run data to a Markdown file.
 func (m *pipelineModel) exportPipelineMarkdown(path string) error {
 	if len(m.exportRecords) == 0 {
@@ -1587,17 +2041,36 @@ func (m *pipelineModel) exportPipelineMarkdown(path string) error {
 	builder.WriteString("# Pipeline Run\n\n")
 	builder.WriteString(fmt.Sprintf("- Run started: %s\n", m.runStarted.Format(time.RFC3339)))
 	builder.WriteString(fmt.Sprintf("- Run completed: %s\n", runCompleted.Format(time.RFC3339)))
+	if m.runCancelled {
+		builder.WriteString("- Cancelled before the last stage finished\n")
+	}
+	if m.runTag != "" {
+		builder.WriteString(fmt.Sprintf("- Tag: %s\n", m.runTag))
+	}
+	if m.runNote != "" {
+		builder.WriteString(fmt.Sprintf("- Note: %s\n", m.runNote))
+	}
 	builder.WriteString(fmt.Sprintf("- JSON mode: %t\n\n", m.config.JSONMode))
 	for _, rec := range m.exportRecords {
 		builder.WriteString(fmt.Sprintf("## Stage %d — %s (%s)\n\n", rec.Stage, rec.Host, rec.Model))
+		builder.WriteString(fmt.Sprintf("- Sampling: %s\n", formatSamplingSummary(rec.Parameters)))
+		builder.WriteString(fmt.Sprintf("- System prompt hash: %s\n", rec.SystemPromptHash))
 		builder.WriteString(fmt.Sprintf("- Cache hit: %t\n", rec.CacheHit))
-		builder.WriteString(fmt.Sprintf("- Prompt tokens: %d\n", rec.Tokens.Prompt))
-		builder.WriteString(fmt.Sprintf("- Eval tokens: %d\n", rec.Tokens.Eval))
+		estimated := ""
+		if rec.Tokens.Estimated {
+			estimated = " (estimated)"
+		}
+		builder.WriteString(fmt.Sprintf("- Prompt tokens: %d%s\n", rec.Tokens.Prompt, estimated))
+		builder.WriteString(fmt.Sprintf("- Eval tokens: %d%s\n", rec.Tokens.Eval, estimated))
+		builder.WriteString(fmt.Sprintf("- Handoff tokens: %d\n", rec.Tokens.Handoff))
 		builder.WriteString(fmt.Sprintf("- Total seconds: %.2f\n", rec.Timings.TotalSeconds))
 		builder.WriteString(fmt.Sprintf("- Load seconds: %.2f\n", rec.Timings.LoadSeconds))
 		builder.WriteString(fmt.Sprintf("- Prompt eval seconds: %.2f\n", rec.Timings.PromptEvalSeconds))
 		builder.WriteString(fmt.Sprintf("- Eval seconds: %.2f\n", rec.Timings.EvalSeconds))
 		builder.WriteString(fmt.Sprintf("- Time to first token: %.2f\n", rec.Timings.TimeToFirstToken))
+		if b := rec.Timings.Breakdown; b != nil {
+			builder.WriteString(fmt.Sprintf("- Latency breakdown: network %.2fs, load %.2fs, prompt eval %.2fs, generation %.2fs, agon overhead %.2fs\n", b.NetworkSeconds, b.LoadSeconds, b.PromptEvalSeconds, b.GenerationSeconds, b.OverheadSeconds))
+		}
 		if rec.TruncationSummary != "" {
 			builder.WriteString(fmt.Sprintf("- Handoff: %s\n", rec.TruncationSummary))
 		}
@@ -1610,12 +2083,28 @@ func (m *pipelineModel) exportPipelineMarkdown(path string) error {
 
 // StartPipelineGUI initializes the pipeline Bubble Tea program and blocks until exit.
 func StartPipelineGUI(ctx context.Context, cfg *Config, cancel context.CancelFunc) error {
-	provider, err := providerfactory.NewChatProvider(cfg)
-	if err != nil {
-		provider = ollama.New(cfg)
+	return startPipelineGUI(ctx, cfg, cancel, nil, nil)
+}
+
+// startPipelineGUI runs Pipeline mode with the stage assignments imported from
+// multimodel chat already applied. A provider handed over by multimodel chat is reused and
+// left for its owner to close, so MCP mode keeps a single server; with none, one is built
+// and closed here.
+func startPipelineGUI(ctx context.Context, cfg *Config, cancel context.CancelFunc, provider providers.ChatProvider, imports []pipelineImport) error {
+	discoverStartupModels(cfg)
+
+	owned := provider == nil
+	if owned {
+		var err error
+		provider, err = providerfactory.NewUnscheduledChatProvider(cfg)
+		if err != nil {
+			provider = providerfactory.NewHostRouter(cfg)
+		}
+		provider = newReloadableProvider(cfg, provider)
 	}
 
 	m := initialPipelineModel(ctx, cfg, provider)
+	m.applyImports(imports)
 	m.client = &http.Client{
 		Transport: &http.Transport{ForceAttemptHTTP2: false},
 		Timeout:   m.requestTimeout,
@@ -1623,31 +2112,18 @@ func StartPipelineGUI(ctx context.Context, cfg *Config, cancel context.CancelFun
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	m.program = p
+	stopWatching := watchConfig(ctx, cfg, p)
 
 	_, runErr := p.Run()
+	stopWatching()
 
 	if m.switchToMultimodel {
-		if provider == nil {
-			provider, err = providerfactory.NewChatProvider(cfg)
-			if err != nil {
-				if cfg.MCPMode {
-					logging.LogEvent("MCP provider unavailable: %v — falling back to direct Ollama access", err)
-					provider = ollama.New(cfg)
-				} else {
-					return err
-				}
-			}
-		}
-		multiErr := StartMultimodelGUI(m.ctx, cfg, provider, cancel)
-		if provider != nil {
-			if cerr := provider.Close(); cerr != nil && multiErr == nil {
-				multiErr = cerr
-			}
-		}
-		return multiErr
+		// The provider reads the mode from cfg, e.g. for the MCP tool audit log.
+		cfg.PipelineMode, cfg.MultimodelMode = false, true
+		runErr = StartMultimodelGUI(m.ctx, cfg, provider, cancel)
 	}
 
-	if provider != nil {
+	if owned {
 		if cerr := provider.Close(); cerr != nil && runErr == nil {
 			runErr = cerr
 		}
@@ -1657,21 +2133,32 @@ func StartPipelineGUI(ctx context.Context, cfg *Config, cancel context.CancelFun
 }
 
 // pipelineStreamStageCmd streams a stage response and emits updates to the Bubble Tea program.
-func pipelineStreamStageCmd(pctx context.Context, p *tea.Program, chatProvider providers.ChatProvider, stageIndex int, host Host, modelName string, history []chatMessage, systemPrompt string, parameters Parameters, payload string, jsonMode bool, timeout time.Duration) tea.Cmd {
+func pipelineStreamStageCmd(pctx context.Context, p *tea.Program, chatProvider providers.ChatProvider, cfg *Config, stageIndex int, host Host, modelName string, history []chatMessage, systemPrompt string, parameters Parameters, payload string, jsonMode bool, timeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(pctx, timeout)
+		// A stage sends one request per run, so there is no summary to keep between runs.
+		fit := fitContext(ctx, chatProvider, cfg, host, modelName, systemPrompt, history, contextState{})
+		if fit.notice != "" {
+			p.Send(pipelineContextNoticeMsg{Stage: stageIndex, Notice: fit.notice})
+		}
 		request := providers.StreamRequest{
 			Host:         host,
 			Model:        modelName,
-			History:      history,
-			SystemPrompt: systemPrompt,
+			History:      fit.history,
+			SystemPrompt: fit.systemPrompt,
 			Parameters:   parameters,
 			JSONMode:     jsonMode,
+			ApproveTool:  toolApprover(p),
 		}
 		go func() {
 			defer cancel()
+			dispatchedAt := time.Now()
+			var firstChunkAt time.Time
 			err := chatProvider.Stream(ctx, request, providers.StreamCallbacks{
 				OnChunk: func(msg providers.ChatMessage) error {
+					if firstChunkAt.IsZero() {
+						firstChunkAt = time.Now()
+					}
 					if msg.Content != "" {
 						p.Send(pipelineStageChunkMsg{Stage: stageIndex, Content: msg.Content})
 					}
@@ -1681,7 +2168,17 @@ func pipelineStreamStageCmd(pctx context.Context, p *tea.Program, chatProvider p
 					if meta.Model == "" {
 						meta.Model = modelName
 					}
-					p.Send(pipelineStageDoneMsg{Stage: stageIndex, Meta: meta})
+					// Prefer the provider's transport-level timestamps over callback wall-clock.
+					if !meta.RequestStart.IsZero() {
+						dispatchedAt = meta.RequestStart
+					} else {
+						// Time spent in the host's queue is queueing, not network.
+						dispatchedAt = dispatchedAt.Add(meta.QueueWait)
+					}
+					if !meta.FirstTokenAt.IsZero() {
+						firstChunkAt = meta.FirstTokenAt
+					}
+					p.Send(pipelineStageDoneMsg{Stage: stageIndex, Meta: meta, DispatchedAt: dispatchedAt, FirstChunkAt: firstChunkAt})
 					return nil
 				},
 			})
@@ -14,7 +14,7 @@ import (
 // It verifies that the UI transitions correctly between host selection, model selection, loading,
 // and chat views, and that chat messages are processed and displayed as expected.
 func TestSingleModel_StateTransitions_And_View(t *testing.T) {
-	cfg := &Config{Hosts: []Host{{Name: "HostA", URL: "http://x", Models: []string{"m1", "m2"}}}}
+	cfg := &Config{Hosts: []Host{{Name: "HostA", URL: "http://x", Models: []string{"m1", "m2"}}}, SessionsDir: t.TempDir()}
 	provider := newTestProvider()
 	provider.loadedModels["HostA"] = []string{"m1"}
 	m := initialModel(context.Background(), cfg, provider)
@@ -85,7 +85,7 @@ func TestMultimodel_Assignment_And_Chat_Flow(t *testing.T) {
 		{Name: "H2", URL: "http://y", Models: []string{"m3"}},
 		{Name: "H3", URL: "http://z", Models: []string{"m4"}},
 		{Name: "H4", URL: "http://w", Models: []string{"m5"}},
-	}}
+	}, SessionsDir: t.TempDir()}
 	provider := newTestProvider()
 
 	mm := initialMultimodelModel(context.Background(), cfg, provider)
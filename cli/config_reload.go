@@ -0,0 +1,125 @@
+// cli/config_reload.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/providerfactory"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// configReloadMsg carries a change to the config file, or the error reading it.
+type configReloadMsg appconfig.Update
+
+// providerReloadMsg carries the provider rebuilt for a reloaded config and the config's
+// host limits.
+type providerReloadMsg struct {
+	provider providers.ChatProvider
+	limits   providers.HostLimits
+	err      error
+}
+
+// newReloadableProvider wraps provider so a reloaded config can swap in one built from it.
+// The host scheduler stays in front of the swaps, so streams still running on a replaced
+// provider keep counting against their host's limit and queued requests keep their place.
+func newReloadableProvider(cfg *Config, provider providers.ChatProvider) *providers.Scheduler {
+	return providers.NewScheduler(providers.NewReloadable(provider), cfg.HostLimits)
+}
+
+// watchConfig sends a configReloadMsg to p whenever cfg's file changes, until the
+// returned function is called. It does nothing when reloading is disabled.
+func watchConfig(ctx context.Context, cfg *Config, p *tea.Program) func() {
+	if cfg == nil || cfg.DisableConfigReload || strings.TrimSpace(cfg.ConfigPath) == "" {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	watcher := appconfig.NewWatcher(cfg.ConfigPath, appconfig.DefaultConfigReloadInterval)
+	updates, _ := watcher.Subscribe()
+	go watcher.Run(ctx)
+	go func() {
+		for update := range updates {
+			p.Send(configReloadMsg(update))
+		}
+	}()
+	return cancel
+}
+
+// applyConfigReload stores a reloaded config in cfg and returns a status line. A config
+// that failed to load leaves cfg alone and reports false. Like refreshed models, the
+// config is only mutated from Update, on the UI goroutine.
+func applyConfigReload(cfg *Config, msg configReloadMsg) (string, bool) {
+	if msg.Err != nil {
+		logging.LogError("config reload failed: %v", msg.Err)
+		return fmt.Sprintf("Config not reloaded: %v", msg.Err), false
+	}
+	*cfg = cfg.Reloaded(msg.Config)
+	// Log levels and format apply as soon as the file is saved.
+	if err := logging.Configure(cfg.LogOptions()); err != nil {
+		logging.LogError("log settings not applied: %v", err)
+	}
+	logging.LogEvent("config reloaded from %s", cfg.ConfigPath)
+	return fmt.Sprintf("Reloaded %s", cfg.ConfigPath), true
+}
+
+// reloadProviderCmd builds a provider for a reloaded config, so new timeouts and MCP
+// settings apply to the next request. Starting an MCP server can take a while, so it
+// happens off the UI goroutine, from a copy of the config.
+func reloadProviderCmd(cfg *Config) tea.Cmd {
+	snapshot := *cfg
+	snapshot.Hosts = append([]Host(nil), cfg.Hosts...)
+	return func() tea.Msg {
+		provider, err := providerfactory.NewUnscheduledChatProvider(&snapshot)
+		if err != nil && snapshot.MCPMode {
+			logging.LogWarn("MCP provider unavailable: %v — falling back to direct host access", err)
+			provider, err = providerfactory.NewHostRouter(&snapshot), nil
+		}
+		return providerReloadMsg{provider: provider, limits: snapshot.HostLimits, err: err}
+	}
+}
+
+// applyProviderReload swaps a rebuilt provider into current, which must be the TUI's
+// provider from newReloadableProvider, applies the reloaded host limits to its scheduler,
+// and returns a status line when the swap failed.
+func applyProviderReload(current providers.ChatProvider, msg providerReloadMsg) string {
+	if msg.err != nil {
+		logging.LogError("provider not rebuilt after config reload: %v", msg.err)
+		return fmt.Sprintf("Config reloaded, but the provider could not be rebuilt: %v", msg.err)
+	}
+	scheduler, ok := current.(*providers.Scheduler)
+	var reloadable *providers.Reloadable
+	if ok {
+		reloadable, ok = scheduler.Unwrap().(*providers.Reloadable)
+	}
+	if !ok {
+		_ = msg.provider.Close()
+		return ""
+	}
+	if err := reloadable.Swap(msg.provider); err != nil {
+		logging.LogError("provider shutdown error: %v", err)
+	}
+	if msg.limits != nil {
+		scheduler.SetLimits(msg.limits)
+	}
+	return ""
+}
+
+// reloadedHost returns the configured host named like host, so a reload reaches the
+// hosts a TUI has already picked. A host that was removed is kept as it is, and models
+// discovered before the reload are kept until they are discovered again.
+func reloadedHost(cfg *Config, host Host) Host {
+	for _, configured := range cfg.Hosts {
+		if configured.Name != host.Name {
+			continue
+		}
+		if len(configured.Models) == 0 {
+			configured.Models = host.Models
+		}
+		return configured
+	}
+	return host
+}
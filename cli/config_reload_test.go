@@ -0,0 +1,120 @@
+// cli/config_reload_test.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestSingleModelConfigReload verifies a reload mid-chat updates the selected host and
+// keeps the conversation and the run's modes, and a broken file changes nothing.
+func TestSingleModelConfigReload(t *testing.T) {
+	cfg := &Config{
+		Hosts:       []Host{{Name: "HostA", URL: "http://a", Models: []string{"m1"}, SystemPrompt: "old"}},
+		JSONMode:    true,
+		ConfigPath:  "config/config.json",
+		SessionsDir: t.TempDir(),
+	}
+	m := initialModel(context.Background(), cfg, newTestProvider())
+	m.state = viewChat
+	m.selectedHost = cfg.Hosts[0]
+	m.chatHistory = []chatMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+
+	reloaded := appconfig.Config{Hosts: []Host{
+		{Name: "HostA", URL: "http://a", SystemPrompt: "new"},
+		{Name: "HostB", URL: "http://b"},
+	}, TimeoutSeconds: 30}
+	next, cmd := m.Update(configReloadMsg{Config: reloaded})
+	m = next.(*model)
+	if cmd == nil {
+		t.Fatalf("expected a reload to rebuild the provider and refresh the hosts")
+	}
+	if len(m.config.Hosts) != 2 || !m.config.JSONMode || m.config.ConfigPath != "config/config.json" {
+		t.Fatalf("unexpected config after reload: %+v", m.config)
+	}
+	if m.selectedHost.SystemPrompt != "new" || len(m.selectedHost.Models) != 1 {
+		t.Fatalf("expected the selected host to be refreshed with its models kept, got %+v", m.selectedHost)
+	}
+	if len(m.chatHistory) != 2 {
+		t.Fatalf("expected the conversation to survive the reload, got %v", m.chatHistory)
+	}
+	if !strings.Contains(m.chatView(), "Reloaded config/config.json") {
+		t.Fatalf("expected the chat view to report the reload")
+	}
+
+	next, _ = m.Update(configReloadMsg{Err: errors.New("unexpected EOF")})
+	m = next.(*model)
+	if len(m.config.Hosts) != 2 || !strings.Contains(m.notice, "unexpected EOF") {
+		t.Fatalf("expected a failed reload to keep the config and report the error, got %+v / %q", m.config.Hosts, m.notice)
+	}
+}
+
+// gatedTestProvider reports each stream on started, tagged with its name, and streams
+// until release has a value.
+type gatedTestProvider struct {
+	*testProvider
+	name    string
+	started chan string
+	release chan struct{}
+}
+
+func (g *gatedTestProvider) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
+	g.started <- g.name + "/" + req.Model
+	<-g.release
+	return nil
+}
+
+// TestProviderReloadKeepsHostLimit verifies a stream still running on the provider a
+// reload replaced keeps its slot, so the host's limit holds across the swap, and that
+// the reloaded limits apply.
+func TestProviderReloadKeepsHostLimit(t *testing.T) {
+	started, release := make(chan string, 3), make(chan struct{}, 3)
+	old := &gatedTestProvider{testProvider: newTestProvider(), name: "old", started: started, release: release}
+	rebuilt := &gatedTestProvider{testProvider: newTestProvider(), name: "new", started: started, release: release}
+	host := Host{Name: "HostA", URL: "http://a"}
+	provider := newReloadableProvider(&Config{Hosts: []Host{host}, MaxInFlight: 1}, old)
+
+	stream := func(model string) {
+		_ = provider.Stream(context.Background(), providers.StreamRequest{Host: host, Model: model}, providers.StreamCallbacks{})
+	}
+	go stream("first")
+	if got := <-started; got != "old/first" {
+		t.Fatalf("expected the first stream on the old provider, got %s", got)
+	}
+
+	reloaded := Config{Hosts: []Host{host}, MaxInFlight: 1}
+	if status := applyProviderReload(provider, providerReloadMsg{provider: rebuilt, limits: reloaded.HostLimits}); status != "" {
+		t.Fatalf("unexpected reload status %q", status)
+	}
+	go stream("second")
+	select {
+	case got := <-started:
+		t.Fatalf("expected %s to wait for the running stream, exceeding the host limit", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+	release <- struct{}{}
+	if got := <-started; got != "new/second" {
+		t.Fatalf("expected the queued stream on the rebuilt provider, got %s", got)
+	}
+
+	raised := Config{Hosts: []Host{host}, MaxInFlight: 2}
+	again := &gatedTestProvider{testProvider: newTestProvider(), name: "again", started: started, release: release}
+	applyProviderReload(provider, providerReloadMsg{provider: again, limits: raised.HostLimits})
+	go stream("third")
+	select {
+	case got := <-started:
+		if got != "again/third" {
+			t.Fatalf("expected the third stream on the latest provider, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the raised limit to start the third stream")
+	}
+	release <- struct{}{}
+	release <- struct{}{}
+}
@@ -0,0 +1,218 @@
+// cli/context_window.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/tokencount"
+)
+
+// contextSummaryPrompt is the system prompt for summarizing the start of a conversation.
+const contextSummaryPrompt = "You condense conversations. Summarize the conversation you are given in a few short paragraphs, keeping names, numbers, decisions, open questions and anything the user asked to remember. Write only the summary."
+
+// contextState remembers the summary that stands in for the start of a conversation, so
+// earlier turns are summarized once rather than before every request.
+type contextState struct {
+	// summary replaces the first summarized messages of the history.
+	summary    string
+	summarized int
+}
+
+// contextFitMsg reports the context state and notice after a chat request was fitted.
+type contextFitMsg struct {
+	state  contextState
+	notice string
+	// messages is the length of the history that was fitted.
+	messages int
+}
+
+// contextFit is a request fitted to the model's context window.
+type contextFit struct {
+	history      []chatMessage
+	systemPrompt string
+	// state is the conversation's context state after the fit.
+	state contextState
+	// notice tells the user what the policy did, or warns them; empty when nothing was needed.
+	notice string
+}
+
+// fitContext applies the context window policy to a request before it is sent. The
+// window comes from the model's metadata, and the request is measured with the model's
+// tokenizer. A summary in state replaces the messages it covers. Fitting never stops a
+// request: when summarizing fails, the oldest messages are dropped instead.
+func fitContext(ctx context.Context, provider providers.ChatProvider, cfg *Config, host Host, model, systemPrompt string, history []chatMessage, state contextState) contextFit {
+	fit := contextFit{history: history, systemPrompt: systemPrompt}
+	if cfg == nil {
+		return fit
+	}
+	settings := cfg.ContextWindowSettings()
+	policy := settings.PolicyName()
+	if policy == appconfig.ContextPolicyOff {
+		return fit
+	}
+	if state.summarized > 0 && state.summarized < len(history) {
+		fit.history = history[state.summarized:]
+		fit.systemPrompt = withContextSummary(systemPrompt, state.summary)
+		fit.state = state
+	}
+
+	window := contextWindowOf(ctx, provider, settings, host, model)
+	if window <= 0 {
+		return fit
+	}
+	tokenizer := cfg.TokenizerFor(model)
+	limit := int(float64(window) * settings.ThresholdShare())
+	used := requestTokens(tokenizer, fit.systemPrompt, fit.history)
+	if used <= limit {
+		return fit
+	}
+
+	switch policy {
+	case appconfig.ContextPolicyTruncate:
+		return truncateContext(fit, tokenizer, limit, settings.KeepRecentMessages(), model)
+	case appconfig.ContextPolicySummarize:
+		summarized, err := summarizeContext(ctx, provider, fit, host, model, systemPrompt, settings.KeepRecentMessages())
+		if err != nil {
+			logging.LogWarn("context summary for %s failed, truncating instead: %v", model, err)
+			fit = truncateContext(fit, tokenizer, limit, settings.KeepRecentMessages(), model)
+			fit.notice = strings.TrimSpace(fmt.Sprintf("Could not summarize earlier turns (%v). %s", err, fit.notice))
+			return fit
+		}
+		if requestTokens(tokenizer, summarized.systemPrompt, summarized.history) > limit {
+			// The summary and the latest messages still do not fit.
+			notice := summarized.notice
+			summarized = truncateContext(summarized, tokenizer, limit, settings.KeepRecentMessages(), model)
+			summarized.notice = strings.TrimSpace(notice + " " + summarized.notice)
+		}
+		return summarized
+	default:
+		fit.notice = fmt.Sprintf("The conversation is about %d tokens, %d%% of %s's %d-token context window; the host may drop its start. Start a new chat or set contextWindow.policy.", used, used*100/window, model, window)
+		return fit
+	}
+}
+
+// contextWindowOf returns model's context window from its metadata, or the configured
+// fallback when the host does not report one.
+func contextWindowOf(ctx context.Context, provider providers.ChatProvider, settings appconfig.ContextWindowConfig, host Host, model string) int {
+	if provider != nil {
+		caps, err := providers.ReportModelCapabilities(ctx, provider, host, model)
+		if err == nil && caps.ContextLength > 0 {
+			return caps.ContextLength
+		}
+	}
+	return settings.FallbackTokens
+}
+
+// requestTokens estimates the tokens a request with this system prompt and history takes.
+func requestTokens(tokenizer tokencount.Tokenizer, systemPrompt string, history []chatMessage) int {
+	contents := make([]string, 0, len(history)+1)
+	if strings.TrimSpace(systemPrompt) != "" {
+		contents = append(contents, systemPrompt)
+	}
+	for _, msg := range history {
+		contents = append(contents, msg.Content)
+	}
+	return tokenizer.CountMessages(contents)
+}
+
+// truncateContext drops the oldest messages until the request fits in limit, keeping the
+// latest keep messages. When the latest message alone is too long, its start is cut.
+func truncateContext(fit contextFit, tokenizer tokencount.Tokenizer, limit, keep int, model string) contextFit {
+	history := fit.history
+	dropped := 0
+	for len(history) > 1 && requestTokens(tokenizer, fit.systemPrompt, history) > limit {
+		if len(history) <= keep {
+			break
+		}
+		history = history[1:]
+		dropped++
+		// A conversation sent to the model starts with the user.
+		for len(history) > 1 && history[0].Role != "user" {
+			history = history[1:]
+			dropped++
+		}
+	}
+	var notes []string
+	if dropped > 0 {
+		notes = append(notes, fmt.Sprintf("Dropped the %d oldest messages to fit %s's context window.", dropped, model))
+	}
+	if over := requestTokens(tokenizer, fit.systemPrompt, history) - limit; over > 0 && len(history) > 0 {
+		last := history[len(history)-1]
+		budget := tokenizer.Count(last.Content) - over
+		if budget > 0 {
+			last.Content, _ = tokenizer.Tail(last.Content, budget)
+			history = append(append([]chatMessage(nil), history[:len(history)-1]...), last)
+			notes = append(notes, "Cut the start of the latest message to fit.")
+		} else {
+			notes = append(notes, "The latest messages alone exceed the context window.")
+		}
+	}
+	fit.history = history
+	fit.notice = strings.Join(notes, " ")
+	return fit
+}
+
+// summarizeContext has model summarize all but the latest keep messages, together with
+// any earlier summary, and returns the request with the summary in their place.
+func summarizeContext(ctx context.Context, provider providers.ChatProvider, fit contextFit, host Host, model, systemPrompt string, keep int) (contextFit, error) {
+	history := fit.history
+	cut := len(history) - keep
+	for cut > 0 && cut < len(history) && history[cut].Role != "user" {
+		cut++
+	}
+	if cut <= 0 || cut >= len(history) {
+		return fit, fmt.Errorf("only the latest %d messages remain", len(history))
+	}
+
+	var transcript strings.Builder
+	if fit.state.summary != "" {
+		fmt.Fprintf(&transcript, "Summary of the conversation so far:\n%s\n\n", fit.state.summary)
+	}
+	for _, msg := range history[:cut] {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", msg.Role, msg.Content)
+	}
+	var summary strings.Builder
+	err := provider.Stream(ctx, providers.StreamRequest{
+		Host:             host,
+		Model:            model,
+		History:          []chatMessage{{Role: "user", Content: transcript.String()}},
+		SystemPrompt:     contextSummaryPrompt,
+		Parameters:       host.Parameters,
+		DisableStreaming: true,
+	}, providers.StreamCallbacks{
+		OnChunk: func(msg providers.ChatMessage) error {
+			summary.WriteString(msg.Content)
+			return nil
+		},
+	})
+	if err != nil {
+		return fit, err
+	}
+	text := strings.TrimSpace(summary.String())
+	if text == "" {
+		return fit, fmt.Errorf("%s returned an empty summary", model)
+	}
+
+	state := contextState{summary: text, summarized: fit.state.summarized + cut}
+	logging.LogEvent("context summary: model=%s messages=%d", model, state.summarized)
+	return contextFit{
+		history:      history[cut:],
+		systemPrompt: withContextSummary(systemPrompt, text),
+		state:        state,
+		notice:       fmt.Sprintf("Summarized %d earlier messages to fit %s's context window.", state.summarized, model),
+	}, nil
+}
+
+// withContextSummary adds the summary of the earlier conversation to the system prompt.
+func withContextSummary(systemPrompt, summary string) string {
+	note := "Summary of the earlier conversation:\n" + summary
+	if strings.TrimSpace(systemPrompt) == "" {
+		return note
+	}
+	return systemPrompt + "\n\n" + note
+}
@@ -0,0 +1,121 @@
+// cli/context_window_test.go
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// longConversation returns turns alternating user and assistant messages of about
+// words tokens each.
+func longConversation(turns, words int) []chatMessage {
+	history := make([]chatMessage, 0, turns)
+	for i := 0; i < turns; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		history = append(history, chatMessage{Role: role, Content: strings.Repeat("word ", words)})
+	}
+	return history
+}
+
+// contextTestSetup returns a provider reporting a 1000-token window for "small" and a
+// config with the given policy.
+func contextTestSetup(policy string) (*testProvider, *Config) {
+	provider := newTestProvider()
+	provider.capabilities = map[string]providers.Capabilities{"small": {Known: true, ContextLength: 1000}}
+	cfg := &Config{ContextWindow: &appconfig.ContextWindowConfig{Policy: policy, KeepRecent: 2}}
+	return provider, cfg
+}
+
+// TestFitContextWithinWindow verifies a request that fits is sent unchanged.
+func TestFitContextWithinWindow(t *testing.T) {
+	provider, cfg := contextTestSetup("truncate")
+	history := longConversation(4, 50)
+	fit := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "small", "", history, contextState{})
+	if len(fit.history) != 4 || fit.notice != "" {
+		t.Fatalf("expected the request unchanged, got %d messages and notice %q", len(fit.history), fit.notice)
+	}
+}
+
+// TestFitContextWarn verifies the default policy sends everything and warns.
+func TestFitContextWarn(t *testing.T) {
+	provider, _ := contextTestSetup("")
+	history := longConversation(10, 200)
+	fit := fitContext(context.Background(), provider, &Config{}, Host{Name: "h"}, "small", "", history, contextState{})
+	if len(fit.history) != 10 {
+		t.Fatalf("expected every message to be sent, got %d", len(fit.history))
+	}
+	if !strings.Contains(fit.notice, "1000-token context window") {
+		t.Fatalf("expected a warning, got %q", fit.notice)
+	}
+}
+
+// TestFitContextTruncate verifies the oldest messages are dropped, the request starts with
+// the user, and the latest messages are kept.
+func TestFitContextTruncate(t *testing.T) {
+	provider, cfg := contextTestSetup("truncate")
+	history := longConversation(10, 200)
+	history[9] = chatMessage{Role: "user", Content: "latest question"}
+	fit := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "small", "", history, contextState{})
+	if len(fit.history) >= 10 || len(fit.history) == 0 {
+		t.Fatalf("expected older messages to be dropped, got %d", len(fit.history))
+	}
+	if fit.history[0].Role != "user" {
+		t.Fatalf("expected the request to start with the user, got %s", fit.history[0].Role)
+	}
+	if last := fit.history[len(fit.history)-1]; last.Content != "latest question" {
+		t.Fatalf("expected the latest message to be kept, got %q", last.Content)
+	}
+	if used := requestTokens(cfg.TokenizerFor("small"), "", fit.history); used > 900 {
+		t.Fatalf("expected the request to fit 90%% of the window, got %d tokens", used)
+	}
+	if !strings.Contains(fit.notice, "Dropped") {
+		t.Fatalf("unexpected notice %q", fit.notice)
+	}
+}
+
+// TestFitContextSummarize verifies earlier messages are replaced by the model's summary,
+// which is remembered and reused for the next request.
+func TestFitContextSummarize(t *testing.T) {
+	provider, cfg := contextTestSetup("summarize")
+	cfg.ContextWindow.KeepRecent = 3
+	provider.streamChunks = []providers.ChatMessage{{Role: "assistant", Content: "They discussed words."}}
+	history := longConversation(9, 150)
+	fit := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "small", "Be brief.", history, contextState{})
+	if len(fit.history) != 3 {
+		t.Fatalf("expected the latest three messages to be kept, got %d", len(fit.history))
+	}
+	if fit.state.summarized != 6 || fit.state.summary != "They discussed words." {
+		t.Fatalf("unexpected state %+v", fit.state)
+	}
+	if !strings.HasPrefix(fit.systemPrompt, "Be brief.") || !strings.Contains(fit.systemPrompt, "They discussed words.") {
+		t.Fatalf("expected the summary in the system prompt, got %q", fit.systemPrompt)
+	}
+
+	next := append(history, chatMessage{Role: "assistant", Content: "ok"}, chatMessage{Role: "user", Content: "and?"})
+	provider.streamChunks = nil
+	again := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "small", "Be brief.", next, fit.state)
+	if len(again.history) != 5 || again.state != fit.state || again.notice != "" {
+		t.Fatalf("expected the summary to be reused, got %d messages, state %+v, notice %q", len(again.history), again.state, again.notice)
+	}
+}
+
+// TestFitContextUnknownWindow verifies the check is skipped when the window is unknown,
+// unless a fallback is configured.
+func TestFitContextUnknownWindow(t *testing.T) {
+	provider, cfg := contextTestSetup("truncate")
+	history := longConversation(10, 200)
+	if fit := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "other", "", history, contextState{}); len(fit.history) != 10 {
+		t.Fatalf("expected no change without a known window, got %d messages", len(fit.history))
+	}
+	cfg.ContextWindow.FallbackTokens = 1000
+	if fit := fitContext(context.Background(), provider, cfg, Host{Name: "h"}, "other", "", history, contextState{}); len(fit.history) >= 10 {
+		t.Fatalf("expected the fallback window to apply, got %d messages", len(fit.history))
+	}
+}
@@ -0,0 +1,48 @@
+// cli/host_load.go
+package cli
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// hostLoadPollInterval is how often the chat re-checks a host's load while waiting for a reply.
+const hostLoadPollInterval = 2 * time.Second
+
+// hostLoadMsg carries a host load reading for the request numbered seq.
+type hostLoadMsg struct {
+	seq  int
+	load providers.HostLoad
+}
+
+// hostLoadCmd queries the host's load after delay. It returns nil when the provider cannot
+// report load, and a failed query reads as an idle host.
+func hostLoadCmd(ctx context.Context, provider providers.ChatProvider, host Host, seq int, delay time.Duration) tea.Cmd {
+	if _, ok := provider.(providers.LoadReporter); !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, warmStateTimeout)
+		defer cancel()
+		load, _, _ := providers.ReportHostLoad(queryCtx, provider, host)
+		return hostLoadMsg{seq: seq, load: load}
+	}
+}
+
+// busyHint renders the load for status lines, or "" when the host is not busy.
+func busyHint(load providers.HostLoad) string {
+	if !load.Busy() {
+		return ""
+	}
+	return " · " + load.String()
+}
@@ -0,0 +1,122 @@
+// cli/log_pane.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/util"
+)
+
+const (
+	// logPaneLines is the number of log entries shown when the pane is open.
+	logPaneLines = 6
+	// logPaneRefresh is how often the pane re-renders while visible.
+	logPaneRefresh = time.Second
+)
+
+// logPaneTickMsg refreshes the log pane while it is visible.
+type logPaneTickMsg time.Time
+
+// logPane is a toggleable bottom pane tailing recent log entries.
+type logPane struct {
+	visible bool
+	showAll bool
+}
+
+// handleKey toggles the pane (f2) or its filter (f3). It reports whether the key was consumed.
+func (lp *logPane) handleKey(key string) (bool, tea.Cmd) {
+	switch key {
+	case "f2":
+		lp.visible = !lp.visible
+		if lp.visible {
+			return true, logPaneTickCmd()
+		}
+		return true, nil
+	case "f3":
+		if !lp.visible {
+			return false, nil
+		}
+		lp.showAll = !lp.showAll
+		return true, nil
+	}
+	return false, nil
+}
+
+// handleTick keeps the refresh loop running only while the pane is visible.
+func (lp *logPane) handleTick() tea.Cmd {
+	if !lp.visible {
+		return nil
+	}
+	return logPaneTickCmd()
+}
+
+// logPaneTickCmd schedules the next log pane refresh.
+func logPaneTickCmd() tea.Cmd {
+	return tea.Tick(logPaneRefresh, func(t time.Time) tea.Msg {
+		return logPaneTickMsg(t)
+	})
+}
+
+// attach appends the pane beneath view, trimming view so the combined output fits height.
+func (lp logPane) attach(view string, width, height int) string {
+	if !lp.visible {
+		return view
+	}
+	pane := lp.render(width)
+	available := height - lipgloss.Height(pane)
+	lines := strings.Split(view, "\n")
+	if available > 0 && len(lines) > available {
+		lines = lines[:available]
+	}
+	return strings.Join(lines, "\n") + "\n" + pane
+}
+
+// render draws the pane contents for the given width.
+func (lp logPane) render(width int) string {
+	minLevel := logging.LevelWarn
+	filter := "warnings & errors"
+	if lp.showAll {
+		minLevel = logging.LevelInfo
+		filter = "all"
+	}
+
+	borderStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	levelStyles := map[logging.Level]lipgloss.Style{
+		logging.LevelDebug: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		logging.LevelInfo:  lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		logging.LevelWarn:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		logging.LevelError: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	}
+
+	lineWidth := width - 2
+	if lineWidth < 20 {
+		lineWidth = 20
+	}
+
+	var builder strings.Builder
+	builder.WriteString(borderStyle.Render(strings.Repeat("─", lineWidth)) + "\n")
+	builder.WriteString(headerStyle.Render(fmt.Sprintf(" Log (%s) — f2 close, f3 toggle filter", filter)) + "\n")
+
+	entries := logging.Recent(logPaneLines, minLevel)
+	if len(entries) == 0 {
+		builder.WriteString(headerStyle.Render(" No log entries."))
+	}
+	for i, entry := range entries {
+		text := strings.ReplaceAll(entry.Message, "\n", " ")
+		if entry.Subsystem != "" {
+			text = "[" + entry.Subsystem + "] " + text
+		}
+		line := fmt.Sprintf(" %s %-5s %s", entry.Time.Format("15:04:05"), entry.Level, text)
+		builder.WriteString(levelStyles[entry.Level].Render(util.TruncateRunes(line, lineWidth)))
+		if i < len(entries)-1 {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,89 @@
+// cli/log_pane_test.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/logging"
+)
+
+// TestLogPaneKeys verifies f2 toggles the pane and starts its refresh loop, f3 only
+// toggles the filter while the pane is open, and ticks stop once it is closed.
+func TestLogPaneKeys(t *testing.T) {
+	var lp logPane
+	if consumed, _ := lp.handleKey("f3"); consumed || lp.showAll {
+		t.Fatalf("expected f3 to be ignored while the pane is closed")
+	}
+	if consumed, cmd := lp.handleKey("f2"); !consumed || cmd == nil || !lp.visible {
+		t.Fatalf("expected f2 to open the pane and schedule a refresh")
+	}
+	if lp.handleTick() == nil {
+		t.Fatalf("expected ticks to continue while the pane is open")
+	}
+	if consumed, _ := lp.handleKey("f3"); !consumed || !lp.showAll {
+		t.Fatalf("expected f3 to show every level")
+	}
+	if consumed, _ := lp.handleKey("f2"); !consumed || lp.visible || lp.handleTick() != nil {
+		t.Fatalf("expected f2 to close the pane and stop the refresh loop")
+	}
+	if consumed, _ := lp.handleKey("enter"); consumed {
+		t.Fatalf("expected other keys to pass through")
+	}
+}
+
+// TestLogPaneRender verifies the pane follows newly logged entries, hides info entries
+// until the filter is toggled, and trims the view above it to fit the height.
+func TestLogPaneRender(t *testing.T) {
+	stamp := time.Now().UnixNano()
+	info := fmt.Sprintf("pane info %d", stamp)
+	warning := fmt.Sprintf("pane warning %d", stamp)
+	log := logging.For(logging.SubsystemPipeline)
+	log.Infof("%s", info)
+	log.Warnf("%s", warning)
+
+	lp := logPane{visible: true}
+	pane := lp.render(120)
+	if !strings.Contains(pane, "[pipeline] "+warning) || strings.Contains(pane, info) {
+		t.Fatalf("expected only the warning, got %q", pane)
+	}
+	lp.showAll = true
+	if pane := lp.render(120); !strings.Contains(pane, info) || !strings.Contains(pane, "(all)") {
+		t.Fatalf("expected info entries with the filter off, got %q", pane)
+	}
+
+	followed := fmt.Sprintf("pane followed %d", stamp)
+	log.Errorf("%s", followed)
+	if pane := lp.render(120); !strings.Contains(pane, followed) {
+		t.Fatalf("expected the appended entry, got %q", pane)
+	}
+
+	view := strings.TrimSuffix(strings.Repeat("line\n", 30), "\n")
+	if attached := lp.attach(view, 120, 20); lipgloss.Height(attached) != 20 {
+		t.Fatalf("expected the view trimmed to 20 lines, got %d", lipgloss.Height(attached))
+	}
+	if hidden := (logPane{}).attach(view, 120, 20); hidden != view {
+		t.Fatalf("expected a closed pane to leave the view unchanged")
+	}
+}
+
+// TestLogPaneShowsReloadFailures verifies that model discovery and provider reload
+// failures, whose messages name no level, reach the pane's default warn/error view.
+func TestLogPaneShowsReloadFailures(t *testing.T) {
+	stamp := time.Now().UnixNano()
+	discovery := fmt.Errorf("host one unreachable %d", stamp)
+	reload := fmt.Errorf("host two rejected the settings %d", stamp)
+	applyModelsRefresh(&Config{}, modelsRefreshedMsg{err: discovery})
+	applyProviderReload(nil, providerReloadMsg{err: reload})
+
+	pane := (logPane{visible: true}).render(160)
+	if !strings.Contains(pane, "model refresh incomplete: "+discovery.Error()) {
+		t.Fatalf("expected the discovery failure in the default view, got %q", pane)
+	}
+	if !strings.Contains(pane, "provider not rebuilt after config reload: "+reload.Error()) {
+		t.Fatalf("expected the reload failure in the default view, got %q", pane)
+	}
+}
@@ -27,8 +27,16 @@ func deriveMCPStatus(cfg *Config, provider providers.ChatProvider) mcpStatus {
 	if provider == nil {
 		return mcpStatusFallback
 	}
-	if _, ok := provider.(*mcpprovider.Provider); ok {
-		return mcpStatusActive
+	// Decorators such as the metrics provider wrap the MCP provider.
+	for provider != nil {
+		if _, ok := provider.(*mcpprovider.Provider); ok {
+			return mcpStatusActive
+		}
+		wrapper, ok := provider.(interface{ Unwrap() providers.ChatProvider })
+		if !ok {
+			break
+		}
+		provider = wrapper.Unwrap()
 	}
 	return mcpStatusFallback
 }
@@ -0,0 +1,249 @@
+// cli/message_params.go
+package cli
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/sessions"
+	"github.com/mwiater/agon/internal/util"
+)
+
+// messageParams records the effective sampling settings used to generate a message.
+type messageParams struct {
+	Host             string     `json:"host"`
+	Model            string     `json:"model"`
+	Parameters       Parameters `json:"parameters"`
+	JSONMode         bool       `json:"jsonMode"`
+	SystemPromptHash string     `json:"systemPromptHash"`
+	// Template is the system prompt the host was configured with.
+	Template    string    `json:"template,omitempty"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// newMessageParams snapshots the parameters in effect for a host/model at generation time.
+func newMessageParams(host Host, modelName string, parameters Parameters, jsonMode bool) messageParams {
+	return messageParams{
+		Host:             host.Name,
+		Model:            modelName,
+		Parameters:       parameters,
+		JSONMode:         jsonMode,
+		SystemPromptHash: systemPromptHash(host.SystemPrompt),
+		Template:         host.SystemPrompt,
+		GeneratedAt:      time.Now(),
+	}
+}
+
+// sessionParams converts p to the form recorded with a session message.
+func (p messageParams) sessionParams() sessions.Params {
+	return sessions.Params{Parameters: p.Parameters, JSONMode: p.JSONMode, Template: p.Template}
+}
+
+// entryMessageParams restores the parameters recorded with a session message, reporting
+// false for messages recorded without them.
+func entryMessageParams(entry sessions.Entry) (messageParams, bool) {
+	if entry.Params == nil {
+		return messageParams{}, false
+	}
+	return messageParams{
+		Host:             entry.Host,
+		Model:            entry.Model,
+		Parameters:       entry.Params.Parameters,
+		JSONMode:         entry.Params.JSONMode,
+		SystemPromptHash: systemPromptHash(entry.Params.Template),
+		Template:         entry.Params.Template,
+		GeneratedAt:      entry.Time,
+	}, true
+}
+
+// parseParameterOverrides parses space-separated name=value sampling settings, such as
+// "temperature=0.2 seed=42", using the parameters' config names. Unknown names, malformed
+// numbers and values outside a setting's range are rejected.
+func parseParameterOverrides(spec string) (Parameters, error) {
+	var params Parameters
+	for _, field := range strings.Fields(spec) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return Parameters{}, fmt.Errorf("expected name=value, got %q", field)
+		}
+		var err error
+		switch strings.ToLower(name) {
+		case "temperature":
+			params.Temperature, err = parseFloatParameter(name, value, 0, 2)
+		case "top_p":
+			params.TopP, err = parseFloatParameter(name, value, 0, 1)
+		case "min_p":
+			params.MinP, err = parseFloatParameter(name, value, 0, 1)
+		case "typical_p":
+			params.TypicalP, err = parseFloatParameter(name, value, 0, 1)
+		case "repeat_penalty":
+			params.RepeatPenalty, err = parseFloatParameter(name, value, 0, 2)
+		case "top_k":
+			params.TopK, err = parseIntParameter(name, value)
+		case "seed":
+			params.Seed, err = parseIntParameter(name, value)
+		default:
+			return Parameters{}, fmt.Errorf("unknown parameter %q (use temperature, top_p, top_k, min_p, typical_p, repeat_penalty or seed)", name)
+		}
+		if err != nil {
+			return Parameters{}, err
+		}
+	}
+	return params, nil
+}
+
+// parseFloatParameter parses a sampling setting that must lie between lo and hi.
+func parseFloatParameter(name, value string, lo, hi float64) (*float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number, got %q", name, value)
+	}
+	if v < lo || v > hi {
+		return nil, fmt.Errorf("%s must be between %v and %v, got %v", name, lo, hi, v)
+	}
+	return &v, nil
+}
+
+// parseIntParameter parses a non-negative integer sampling setting.
+func parseIntParameter(name, value string) (*int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return &v, nil
+}
+
+// mergeParameters layers the overrides that are set on top of the host defaults.
+func mergeParameters(defaults, overrides Parameters) Parameters {
+	merged := defaults
+	for _, pair := range []struct{ dst, src **float64 }{
+		{&merged.TopP, &overrides.TopP},
+		{&merged.MinP, &overrides.MinP},
+		{&merged.TFSZ, &overrides.TFSZ},
+		{&merged.TypicalP, &overrides.TypicalP},
+		{&merged.Temperature, &overrides.Temperature},
+		{&merged.RepeatPenalty, &overrides.RepeatPenalty},
+		{&merged.PresencePenalty, &overrides.PresencePenalty},
+		{&merged.FrequencyPenalty, &overrides.FrequencyPenalty},
+	} {
+		if *pair.src != nil {
+			*pair.dst = *pair.src
+		}
+	}
+	for _, pair := range []struct{ dst, src **int }{
+		{&merged.TopK, &overrides.TopK},
+		{&merged.RepeatLastN, &overrides.RepeatLastN},
+		{&merged.Seed, &overrides.Seed},
+	} {
+		if *pair.src != nil {
+			*pair.dst = *pair.src
+		}
+	}
+	return merged
+}
+
+// applyParameterOverrides handles the "/set name=value ..." prompt command, which overrides
+// the host's sampling settings for the rest of the chat; "/set" alone clears them. It
+// reports whether input was the command.
+func (m *model) applyParameterOverrides(input string) bool {
+	command, spec, _ := strings.Cut(input, " ")
+	if command != "/set" {
+		return false
+	}
+	if strings.TrimSpace(spec) == "" {
+		m.parameterOverrides = Parameters{}
+		m.notice = "Sampling overrides cleared"
+		return true
+	}
+	overrides, err := parseParameterOverrides(spec)
+	if err != nil {
+		m.notice = "Sampling overrides not changed: " + err.Error()
+		return true
+	}
+	m.parameterOverrides = mergeParameters(m.parameterOverrides, overrides)
+	m.notice = "Sampling: " + formatSamplingSummary(m.effectiveParameters())
+	return true
+}
+
+// effectiveParameters returns the selected host's sampling settings with the chat's
+// overrides applied.
+func (m *model) effectiveParameters() Parameters {
+	return mergeParameters(m.selectedHost.Parameters, m.parameterOverrides)
+}
+
+// systemPromptHash returns a short stable fingerprint of a system prompt.
+func systemPromptHash(prompt string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(prompt))
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// formatSamplingSummary renders the sampling parameters that influence output variance.
+func formatSamplingSummary(params Parameters) string {
+	parts := []string{
+		"temperature=" + formatOptionalFloat(params.Temperature),
+		"top_p=" + formatOptionalFloat(params.TopP),
+		"top_k=" + formatOptionalInt(params.TopK),
+		"seed=" + formatOptionalInt(params.Seed),
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatOptionalFloat renders a float pointer or "default" when unset.
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return "default"
+	}
+	return fmt.Sprintf("%v", *v)
+}
+
+// formatOptionalInt renders an int pointer or "default" when unset.
+func formatOptionalInt(v *int) string {
+	if v == nil {
+		return "default"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+// renderMessageInspector lists the recorded parameters for every assistant message in the history.
+func renderMessageInspector(history []chatMessage, params map[int]messageParams, width int) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
+	metaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	indexes := make([]int, 0, len(params))
+	for idx := range params {
+		if idx < len(history) {
+			indexes = append(indexes, idx)
+		}
+	}
+	sort.Ints(indexes)
+
+	if len(indexes) == 0 {
+		return metaStyle.Render("No generated messages yet. (ctrl+t to close)")
+	}
+
+	var builder strings.Builder
+	builder.WriteString(metaStyle.Render("Message inspector (ctrl+t to close)") + "\n\n")
+	for n, idx := range indexes {
+		p := params[idx]
+		preview := strings.ReplaceAll(history[idx].Content, "\n", " ")
+		if width > 10 {
+			preview = util.TruncateRunes(preview, width-10)
+		}
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("#%d %s", n+1, p.GeneratedAt.Format("15:04:05"))) + " " + preview + "\n")
+		builder.WriteString(metaStyle.Render(fmt.Sprintf("    host=%s model=%s json=%t system=%s", p.Host, p.Model, p.JSONMode, p.SystemPromptHash)) + "\n")
+		builder.WriteString(metaStyle.Render("    "+formatSamplingSummary(p.Parameters)) + "\n")
+		if template := strings.Join(strings.Fields(p.Template), " "); template != "" {
+			if width > 20 {
+				template = util.TruncateRunes(template, width-20)
+			}
+			builder.WriteString(metaStyle.Render("    template: "+template) + "\n")
+		}
+	}
+	return builder.String()
+}
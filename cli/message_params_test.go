@@ -0,0 +1,157 @@
+// cli/message_params_test.go
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestParseParameterOverrides verifies valid settings are parsed and malformed, unknown
+// or out-of-range values are rejected.
+func TestParseParameterOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{name: "empty", spec: "", want: "temperature=default top_p=default top_k=default seed=default"},
+		{name: "all sampling settings", spec: "temperature=0.2 top_p=0.9 top_k=40 seed=7", want: "temperature=0.2 top_p=0.9 top_k=40 seed=7"},
+		{name: "names are case-insensitive", spec: "Temperature=1 SEED=0", want: "temperature=1 top_p=default top_k=default seed=0"},
+		{name: "missing value", spec: "temperature=", wantErr: `expected name=value, got "temperature="`},
+		{name: "missing equals", spec: "seed", wantErr: `expected name=value, got "seed"`},
+		{name: "unknown name", spec: "temp=0.5", wantErr: `unknown parameter "temp"`},
+		{name: "not a number", spec: "temperature=hot", wantErr: `temperature must be a number, got "hot"`},
+		{name: "out of range", spec: "top_p=1.5", wantErr: "top_p must be between 0 and 1, got 1.5"},
+		{name: "negative integer", spec: "top_k=-1", wantErr: `top_k must be a non-negative integer, got "-1"`},
+		{name: "fractional integer", spec: "seed=1.5", wantErr: `seed must be a non-negative integer, got "1.5"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := parseParameterOverrides(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseParameterOverrides returned error: %v", err)
+			}
+			if got := formatSamplingSummary(params); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParameterOverridePrecedence verifies /set overrides win over the host defaults they
+// name and leave the rest alone, that a rejected /set keeps the previous overrides, and
+// that the recorded message parameters are the effective ones.
+func TestParameterOverridePrecedence(t *testing.T) {
+	temperature, topP, topK := 0.8, 0.95, 20
+	host := Host{Name: "gpu-a", Parameters: Parameters{Temperature: &temperature, TopP: &topP, TopK: &topK}}
+	m := initialModel(context.Background(), &Config{Hosts: []Host{host}}, newTestProvider())
+	m.selectedHost = host
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "/set", want: "temperature=0.8 top_p=0.95 top_k=20 seed=default"},
+		{input: "/set temperature=0.1 seed=3", want: "temperature=0.1 top_p=0.95 top_k=20 seed=3"},
+		{input: "/set top_k=5", want: "temperature=0.1 top_p=0.95 top_k=5 seed=3"},
+		{input: "/set temperature=9", want: "temperature=0.1 top_p=0.95 top_k=5 seed=3"},
+		{input: "/set", want: "temperature=0.8 top_p=0.95 top_k=20 seed=default"},
+	}
+	for _, tt := range tests {
+		if !m.applyParameterOverrides(tt.input) {
+			t.Fatalf("expected %q to be handled as a command", tt.input)
+		}
+		if got := formatSamplingSummary(m.effectiveParameters()); got != tt.want {
+			t.Fatalf("after %q expected %q, got %q (notice %q)", tt.input, tt.want, got, m.notice)
+		}
+	}
+	if !strings.Contains(m.notice, "cleared") {
+		t.Fatalf("expected a cleared notice, got %q", m.notice)
+	}
+	if m.applyParameterOverrides("/setting up a test") || m.applyParameterOverrides("hello") {
+		t.Fatalf("expected ordinary messages not to be handled")
+	}
+
+	m.applyParameterOverrides("/set seed=42")
+	recorded := newMessageParams(m.selectedHost, "llama", m.effectiveParameters(), false)
+	if recorded.Parameters.Seed == nil || *recorded.Parameters.Seed != 42 || *recorded.Parameters.Temperature != 0.8 {
+		t.Fatalf("unexpected recorded parameters %+v", recorded.Parameters)
+	}
+	if *host.Parameters.TopK != 20 || host.Parameters.Seed != nil {
+		t.Fatalf("expected the host defaults untouched, got %+v", host.Parameters)
+	}
+}
+
+// TestChatRecordsMessageParams verifies a finished chat reply records its parameters and
+// system prompt template with the session, and that resuming restores them.
+func TestChatRecordsMessageParams(t *testing.T) {
+	temperature := 0.3
+	host := Host{Name: "gpu-a", URL: "http://x", Models: []string{"llama"}, SystemPrompt: "Answer tersely.", Parameters: Parameters{Temperature: &temperature}}
+	cfg := &Config{Hosts: []Host{host}, SessionsDir: t.TempDir()}
+	m := initialModel(context.Background(), cfg, newTestProvider())
+	m.selectedHost, m.selectedModel = host, "llama"
+	m.chatHistory = []chatMessage{{Role: "user", Content: "hi"}}
+	m.recordMessage("user", "hi", nil)
+	m.applyParameterOverrides("/set seed=9")
+	m.responseBuf.WriteString("hello")
+	m.Update(streamEndMsg{})
+
+	fresh := initialModel(context.Background(), cfg, newTestProvider())
+	if err := fresh.resumeSession(m.session.ID); err != nil {
+		t.Fatalf("resumeSession returned error: %v", err)
+	}
+	if _, ok := fresh.messageParams[0]; ok {
+		t.Fatalf("expected no parameters for the user's message")
+	}
+	params, ok := fresh.messageParams[1]
+	if !ok || params.Template != "Answer tersely." || params.SystemPromptHash != systemPromptHash("Answer tersely.") {
+		t.Fatalf("unexpected restored params %+v", params)
+	}
+	if got := formatSamplingSummary(params.Parameters); got != "temperature=0.3 top_p=default top_k=default seed=9" {
+		t.Fatalf("unexpected restored sampling %q", got)
+	}
+}
+
+// TestMultimodelRecordsMessageParams verifies a finished column reply records its host's
+// parameters and template with the column's session and in the comparison export row.
+func TestMultimodelRecordsMessageParams(t *testing.T) {
+	seed := 5
+	cfg := &Config{
+		Hosts: []Host{
+			{Name: "alpha", Models: []string{"llama3"}, SystemPrompt: "Be brief.", Parameters: Parameters{Seed: &seed}},
+			{Name: "beta", Models: []string{"qwen3"}},
+		},
+		SessionsDir: t.TempDir(),
+	}
+	m := initialMultimodelModel(context.Background(), cfg, newTestProvider())
+	m.assignments[0].isAssigned = true
+	m.assignments[0].selectedModel = "llama3"
+	m.columnResponses[0].chatHistory = []chatMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+	m.columnResponses[0].isStreaming = true
+	m.Update(multimodelStreamEndMsg{hostIndex: 0})
+
+	column := m.columnResponses[0]
+	params, ok := column.messageParams[1]
+	if !ok || params.Host != "alpha" || params.Model != "llama3" || params.Template != "Be brief." {
+		t.Fatalf("unexpected column params %+v", params)
+	}
+	if row := m.comparisonRows[0]; row.Sampling != "temperature=default top_p=default top_k=default seed=5" {
+		t.Fatalf("unexpected comparison sampling %q", row.Sampling)
+	}
+
+	_, entries, err := m.sessionStore.Open(column.session.ID)
+	if err != nil || len(entries) != 1 || entries[0].Params == nil {
+		t.Fatalf("expected the reply recorded with params, got %+v, %v", entries, err)
+	}
+	if recorded := entries[0].Params; recorded.Template != "Be brief." || recorded.Parameters.Seed == nil || *recorded.Parameters.Seed != 5 {
+		t.Fatalf("unexpected recorded params %+v", recorded)
+	}
+}
@@ -0,0 +1,369 @@
+// cli/metrics_explorer.go
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/analysis"
+)
+
+// explorerColumn is one sortable column of an explorer table.
+type explorerColumn[T any] struct {
+	title string
+	width int
+	// text renders the cell and less orders two rows by this column.
+	text func(T) string
+	less func(a, b T) bool
+}
+
+// modelColumns are the columns of the explorer's model table.
+var modelColumns = []explorerColumn[analysis.ModelAnalysis]{
+	{"Model", 28, func(m analysis.ModelAnalysis) string { return m.ModelName }, func(a, b analysis.ModelAnalysis) bool { return a.ModelName < b.ModelName }},
+	{"Runs", 5, func(m analysis.ModelAnalysis) string { return fmt.Sprint(m.BenchmarkCount) }, func(a, b analysis.ModelAnalysis) bool { return a.BenchmarkCount < b.BenchmarkCount }},
+	{"Avg TPS", 8, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.1f", m.Avg.TokensPerSecond) }, func(a, b analysis.ModelAnalysis) bool { return a.Avg.TokensPerSecond < b.Avg.TokensPerSecond }},
+	{"P95 TPS", 8, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.1f", m.P95.TokensPerSecond) }, func(a, b analysis.ModelAnalysis) bool { return a.P95.TokensPerSecond < b.P95.TokensPerSecond }},
+	{"Avg TTFT", 9, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.2fs", m.Avg.TimeToFirstTokenSeconds) }, func(a, b analysis.ModelAnalysis) bool {
+		return a.Avg.TimeToFirstTokenSeconds < b.Avg.TimeToFirstTokenSeconds
+	}},
+	{"P95 TTFT", 9, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.2fs", m.P95.TimeToFirstTokenSeconds) }, func(a, b analysis.ModelAnalysis) bool {
+		return a.P95.TimeToFirstTokenSeconds < b.P95.TimeToFirstTokenSeconds
+	}},
+	{"Avg Total", 9, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.2fs", m.Avg.TotalExecutionTimeSeconds) }, func(a, b analysis.ModelAnalysis) bool {
+		return a.Avg.TotalExecutionTimeSeconds < b.Avg.TotalExecutionTimeSeconds
+	}},
+	{"Out Tok", 8, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.0f", m.Avg.OutputTokens) }, func(a, b analysis.ModelAnalysis) bool { return a.Avg.OutputTokens < b.Avg.OutputTokens }},
+	{"Effic.", 7, func(m analysis.ModelAnalysis) string { return fmt.Sprintf("%.1f", m.Scores.EfficiencyScore) }, func(a, b analysis.ModelAnalysis) bool { return a.Scores.EfficiencyScore < b.Scores.EfficiencyScore }},
+	{"Accuracy", 9, func(m analysis.ModelAnalysis) string { return formatAccuracy(m.Accuracy) }, func(a, b analysis.ModelAnalysis) bool { return accuracyRate(a.Accuracy) < accuracyRate(b.Accuracy) }},
+	{"Tier", 10, func(m analysis.ModelAnalysis) string { return m.Labels.RelativeSpeedTier }, func(a, b analysis.ModelAnalysis) bool { return a.Labels.RelativeSpeedTier < b.Labels.RelativeSpeedTier }},
+}
+
+// iterationColumns are the columns of the explorer's per-question table.
+var iterationColumns = []explorerColumn[analysis.IterationSample]{
+	{"#", 5, func(s analysis.IterationSample) string { return fmt.Sprint(s.Iteration) }, func(a, b analysis.IterationSample) bool { return a.Iteration < b.Iteration }},
+	{"Question", 24, func(s analysis.IterationSample) string { return s.QuestionID }, func(a, b analysis.IterationSample) bool { return a.QuestionID < b.QuestionID }},
+	{"Difficulty", 10, func(s analysis.IterationSample) string { return s.Difficulty }, func(a, b analysis.IterationSample) bool { return a.Difficulty < b.Difficulty }},
+	{"Correct", 8, func(s analysis.IterationSample) string { return formatCorrect(s.Correct) }, func(a, b analysis.IterationSample) bool { return correctRank(a.Correct) < correctRank(b.Correct) }},
+	{"Grader", 9, func(s analysis.IterationSample) string { return s.Grader }, func(a, b analysis.IterationSample) bool { return a.Grader < b.Grader }},
+	{"Prompt", 12, func(s analysis.IterationSample) string { return s.SystemPrompt }, func(a, b analysis.IterationSample) bool { return a.SystemPrompt < b.SystemPrompt }},
+	{"TPS", 8, func(s analysis.IterationSample) string { return fmt.Sprintf("%.1f", s.TokensPerSecond) }, func(a, b analysis.IterationSample) bool { return a.TokensPerSecond < b.TokensPerSecond }},
+	{"TTFT", 8, func(s analysis.IterationSample) string { return fmt.Sprintf("%.2fs", s.TimeToFirstTokenSeconds) }, func(a, b analysis.IterationSample) bool { return a.TimeToFirstTokenSeconds < b.TimeToFirstTokenSeconds }},
+	{"Total", 8, func(s analysis.IterationSample) string { return fmt.Sprintf("%.2fs", s.TotalExecutionTimeSeconds) }, func(a, b analysis.IterationSample) bool {
+		return a.TotalExecutionTimeSeconds < b.TotalExecutionTimeSeconds
+	}},
+	{"Out Tok", 8, func(s analysis.IterationSample) string { return fmt.Sprint(s.OutputTokens) }, func(a, b analysis.IterationSample) bool { return a.OutputTokens < b.OutputTokens }},
+}
+
+// explorerSort is the column and direction a table is ordered by.
+type explorerSort struct {
+	column int
+	desc   bool
+}
+
+// label renders the sort for the status line.
+func (s explorerSort) label(title string) string {
+	if s.desc {
+		return title + " ↓"
+	}
+	return title + " ↑"
+}
+
+// metricsExplorer is the Bubble Tea model behind `agon metrics explore`.
+type metricsExplorer struct {
+	analysis analysis.Analysis
+	table    table.Model
+	filter   textinput.Model
+	width    int
+	height   int
+
+	// selected is the model whose iterations are shown, or nil on the model table.
+	selected      *analysis.ModelAnalysis
+	modelSort     explorerSort
+	iterSort      explorerSort
+	scoredOnly    bool
+	incorrectOnly bool
+	filtering     bool
+
+	models     []analysis.ModelAnalysis
+	iterations []analysis.IterationSample
+}
+
+// newMetricsExplorer builds the explorer for an analysis, sorted by efficiency.
+func newMetricsExplorer(result analysis.Analysis) *metricsExplorer {
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter models or questions"
+
+	t := table.New(table.WithFocused(true))
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.BorderStyle(lipgloss.NormalBorder()).BorderBottom(true).Bold(true)
+	styles.Selected = styles.Selected.Foreground(lipgloss.Color("0")).Background(lipgloss.Color("229"))
+	t.SetStyles(styles)
+
+	m := &metricsExplorer{
+		analysis:  result,
+		table:     t,
+		filter:    filter,
+		modelSort: explorerSort{column: 8, desc: true},
+		iterSort:  explorerSort{column: 0},
+	}
+	m.refresh()
+	return m
+}
+
+// Init implements tea.Model.
+func (m *metricsExplorer) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles navigation, sorting and filter keys.
+func (m *metricsExplorer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.table.SetWidth(msg.Width)
+		m.table.SetHeight(max(msg.Height-7, 3))
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.filtering {
+			switch msg.String() {
+			case "enter", "esc":
+				m.filtering = false
+				m.filter.Blur()
+				m.table.Focus()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.filter, cmd = m.filter.Update(msg)
+			m.refresh()
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "/":
+			m.filtering = true
+			m.table.Blur()
+			return m, m.filter.Focus()
+		case "s", "S":
+			step := 1
+			if msg.String() == "S" {
+				step = -1
+			}
+			sortState, count := &m.modelSort, len(modelColumns)
+			if m.selected != nil {
+				sortState, count = &m.iterSort, len(iterationColumns)
+			}
+			sortState.column = (sortState.column + step + count) % count
+			m.refresh()
+			return m, nil
+		case "r":
+			if m.selected != nil {
+				m.iterSort.desc = !m.iterSort.desc
+			} else {
+				m.modelSort.desc = !m.modelSort.desc
+			}
+			m.refresh()
+			return m, nil
+		case "a":
+			if m.selected == nil {
+				m.scoredOnly = !m.scoredOnly
+			} else {
+				m.incorrectOnly = !m.incorrectOnly
+			}
+			m.refresh()
+			return m, nil
+		case "enter":
+			if m.selected == nil && len(m.models) > 0 {
+				selected := m.models[m.table.Cursor()]
+				m.selected = &selected
+				m.filter.SetValue("")
+				m.table.SetCursor(0)
+				m.refresh()
+			}
+			return m, nil
+		case "esc", "backspace":
+			if m.selected != nil {
+				name := m.selected.ModelName
+				m.selected = nil
+				m.filter.SetValue("")
+				m.refresh()
+				for i, model := range m.models {
+					if model.ModelName == name {
+						m.table.SetCursor(i)
+					}
+				}
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+// View renders the title, the current table and the key help.
+func (m *metricsExplorer) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	var title, status, help string
+	if m.selected == nil {
+		title = fmt.Sprintf("Metrics Explorer · %d models", len(m.analysis.Models))
+		if host := m.analysis.HostInfo.ClusterName; host != "" {
+			title += " · " + host
+		}
+		status = fmt.Sprintf("sorted by %s · showing %d", m.modelSort.label(modelColumns[m.modelSort.column].title), len(m.models))
+		if m.scoredOnly {
+			status += " · scored models only"
+		}
+		help = "enter: questions · s/S: sort column · r: reverse · a: scored only · /: filter · q: quit"
+	} else {
+		title = fmt.Sprintf("%s · %d iterations", m.selected.ModelName, len(m.selected.Iterations))
+		if acc := m.selected.Accuracy; acc != nil {
+			title += fmt.Sprintf(" · accuracy %s", formatAccuracy(acc))
+		}
+		status = fmt.Sprintf("sorted by %s · showing %d", m.iterSort.label(iterationColumns[m.iterSort.column].title), len(m.iterations))
+		if m.incorrectOnly {
+			status += " · incorrect answers only"
+		}
+		help = "esc: models · s/S: sort column · r: reverse · a: incorrect only · /: filter · q: quit"
+	}
+	if value := m.filter.Value(); value != "" && !m.filtering {
+		status += fmt.Sprintf(" · filter %q", value)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(title) + "\n")
+	b.WriteString(helpStyle.Render(status) + "\n\n")
+	b.WriteString(m.table.View() + "\n")
+	if m.filtering {
+		b.WriteString(m.filter.View() + "\n")
+	} else {
+		b.WriteString(helpStyle.Render(help) + "\n")
+	}
+	return b.String()
+}
+
+// refresh re-applies the filters and sort order and reloads the table.
+func (m *metricsExplorer) refresh() {
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if m.selected == nil {
+		m.models = m.models[:0]
+		for _, model := range m.analysis.Models {
+			if m.scoredOnly && model.Accuracy == nil {
+				continue
+			}
+			if query != "" && !strings.Contains(strings.ToLower(model.ModelName), query) {
+				continue
+			}
+			m.models = append(m.models, model)
+		}
+		sortRows(m.models, modelColumns[m.modelSort.column].less, m.modelSort.desc)
+		setTableRows(&m.table, modelColumns, m.models)
+		return
+	}
+
+	m.iterations = m.iterations[:0]
+	for _, sample := range m.selected.Iterations {
+		if m.incorrectOnly && (sample.Correct == nil || *sample.Correct) {
+			continue
+		}
+		haystack := strings.ToLower(sample.QuestionID + " " + sample.Difficulty)
+		if query != "" && !strings.Contains(haystack, query) {
+			continue
+		}
+		m.iterations = append(m.iterations, sample)
+	}
+	sortRows(m.iterations, iterationColumns[m.iterSort.column].less, m.iterSort.desc)
+	setTableRows(&m.table, iterationColumns, m.iterations)
+}
+
+// sortRows orders rows by less, descending when desc is set, keeping ties stable.
+func sortRows[T any](rows []T, less func(a, b T) bool, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// setTableRows replaces the table's columns and rows, clamping the cursor.
+func setTableRows[T any](t *table.Model, columns []explorerColumn[T], rows []T) {
+	cols := make([]table.Column, len(columns))
+	for i, column := range columns {
+		cols[i] = table.Column{Title: column.title, Width: column.width}
+	}
+	cells := make([]table.Row, len(rows))
+	for i, row := range rows {
+		cells[i] = make(table.Row, len(columns))
+		for j, column := range columns {
+			cells[i][j] = column.text(row)
+		}
+	}
+	// Rows must be cleared first: the table renders existing rows against the new columns.
+	t.SetRows(nil)
+	t.SetColumns(cols)
+	t.SetRows(cells)
+	if t.Cursor() >= len(cells) {
+		t.SetCursor(max(len(cells)-1, 0))
+	}
+}
+
+// formatAccuracy renders an accuracy summary as "83% (5/6)", or "-" when unscored.
+func formatAccuracy(acc *analysis.AccuracyStats) string {
+	if acc == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%% (%d/%d)", acc.Rate*100, acc.Correct, acc.Scored)
+}
+
+// accuracyRate returns the accuracy rate, ranking unscored models below every scored one.
+func accuracyRate(acc *analysis.AccuracyStats) float64 {
+	if acc == nil {
+		return -1
+	}
+	return acc.Rate
+}
+
+// formatCorrect renders an iteration's correctness.
+func formatCorrect(correct *bool) string {
+	switch {
+	case correct == nil:
+		return "-"
+	case *correct:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// correctRank orders unscored, incorrect and correct answers.
+func correctRank(correct *bool) int {
+	switch {
+	case correct == nil:
+		return 0
+	case *correct:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// StartMetricsExplorer opens the interactive metrics explorer for an analysis.
+func StartMetricsExplorer(result analysis.Analysis) error {
+	if len(result.Models) == 0 {
+		return fmt.Errorf("no models to explore")
+	}
+	_, err := tea.NewProgram(newMetricsExplorer(result), tea.WithAltScreen()).Run()
+	return err
+}
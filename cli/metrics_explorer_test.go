@@ -0,0 +1,102 @@
+// cli/metrics_explorer_test.go
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/analysis"
+)
+
+// explorerAnalysis returns an analysis with one scored and one unscored model.
+func explorerAnalysis() analysis.Analysis {
+	yes, no := true, false
+	return analysis.Analysis{Models: []analysis.ModelAnalysis{
+		{
+			ModelName: "fast-model",
+			Avg:       analysis.AggregatedStats{TokensPerSecond: 90},
+			Scores:    analysis.ScoreStats{EfficiencyScore: 40},
+		},
+		{
+			ModelName: "smart-model",
+			Avg:       analysis.AggregatedStats{TokensPerSecond: 30},
+			Scores:    analysis.ScoreStats{EfficiencyScore: 70},
+			Accuracy:  &analysis.AccuracyStats{AccuracyCount: analysis.AccuracyCount{Scored: 2, Correct: 1}, Rate: 0.5},
+			Iterations: []analysis.IterationSample{
+				{Iteration: 1, QuestionID: "math/add", Difficulty: "easy", Correct: &yes, TokensPerSecond: 31},
+				{Iteration: 2, QuestionID: "trivia/fr", Difficulty: "hard", Correct: &no, TokensPerSecond: 29},
+			},
+		},
+	}}
+}
+
+// explorerKeys sends key presses to the explorer in order.
+func explorerKeys(m *metricsExplorer, keys ...string) {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m.Update(msg)
+	}
+}
+
+// TestMetricsExplorerSortsAndFilters verifies that the model table sorts by the chosen
+// column in either direction and that the scored-only and name filters apply.
+func TestMetricsExplorerSortsAndFilters(t *testing.T) {
+	m := newMetricsExplorer(explorerAnalysis())
+	if m.models[0].ModelName != "smart-model" {
+		t.Fatalf("expected efficiency-descending order, got %s first", m.models[0].ModelName)
+	}
+
+	// Stepping back six columns from Efficiency reaches Avg TPS.
+	explorerKeys(m, "S", "S", "S", "S", "S", "S")
+	if col := modelColumns[m.modelSort.column].title; col != "Avg TPS" || m.models[0].ModelName != "fast-model" {
+		t.Fatalf("expected Avg TPS descending with fast-model first, got %s / %s", col, m.models[0].ModelName)
+	}
+	explorerKeys(m, "r")
+	if m.models[0].ModelName != "smart-model" {
+		t.Fatalf("expected reversed order, got %s first", m.models[0].ModelName)
+	}
+
+	explorerKeys(m, "a")
+	if len(m.models) != 1 || m.models[0].ModelName != "smart-model" {
+		t.Fatalf("expected only the scored model, got %+v", m.models)
+	}
+	explorerKeys(m, "a", "/", "f", "a", "s", "t", "enter")
+	if len(m.models) != 1 || m.models[0].ModelName != "fast-model" {
+		t.Fatalf("expected the name filter to keep fast-model, got %+v", m.models)
+	}
+	if view := m.View(); !strings.Contains(view, `filter "fast"`) {
+		t.Fatalf("expected the filter in the status line:\n%s", view)
+	}
+}
+
+// TestMetricsExplorerShowsQuestions verifies drilling into a model's per-question records,
+// the incorrect-only filter, and returning to the model table.
+func TestMetricsExplorerShowsQuestions(t *testing.T) {
+	m := newMetricsExplorer(explorerAnalysis())
+	explorerKeys(m, "enter")
+	if m.selected == nil || m.selected.ModelName != "smart-model" || len(m.iterations) != 2 {
+		t.Fatalf("expected smart-model's iterations, got %+v", m.selected)
+	}
+	if view := m.View(); !strings.Contains(view, "accuracy 50% (1/2)") || !strings.Contains(view, "trivia/fr") {
+		t.Fatalf("unexpected question view:\n%s", view)
+	}
+
+	explorerKeys(m, "a")
+	if len(m.iterations) != 1 || m.iterations[0].QuestionID != "trivia/fr" {
+		t.Fatalf("expected only the incorrect answer, got %+v", m.iterations)
+	}
+
+	explorerKeys(m, "esc")
+	if m.selected != nil || len(m.models) != 2 || m.table.Cursor() != 0 {
+		t.Fatalf("expected the model table with smart-model selected")
+	}
+}
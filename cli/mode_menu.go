@@ -0,0 +1,149 @@
+// cli/mode_menu.go
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/logging"
+)
+
+// modeMenuRecentSessions is how many recorded chat sessions the mode menu offers to resume.
+const modeMenuRecentSessions = 5
+
+// ModeChoice is one entry of the startup mode menu and the agon arguments it runs.
+type ModeChoice struct {
+	Title       string
+	Description string
+	Args        []string
+}
+
+// modeChoiceItem renders a ModeChoice inside the menu list.
+type modeChoiceItem struct {
+	choice ModeChoice
+}
+
+// Title returns the choice's title.
+func (i modeChoiceItem) Title() string { return i.choice.Title }
+
+// Description returns what the choice does.
+func (i modeChoiceItem) Description() string { return i.choice.Description }
+
+// FilterValue returns the text the menu filters on.
+func (i modeChoiceItem) FilterValue() string { return i.choice.Title + " " + i.choice.Description }
+
+// ModeMenuChoices lists the agon modes, followed by the most recent chat sessions and the
+// config's aliases as presets.
+func ModeMenuChoices(cfg *Config) []ModeChoice {
+	choices := []ModeChoice{
+		{Title: "Chat", Description: "Talk to one model on one host", Args: []string{"chat", "--multimodelMode=false", "--pipelineMode=false"}},
+		{Title: "Multimodel", Description: "Send each prompt to several models and compare their answers side by side", Args: []string{"chat", "--multimodelMode=true", "--pipelineMode=false"}},
+		{Title: "Pipeline", Description: "Chain models in stages, each stage's output feeding the next", Args: []string{"chat", "--pipelineMode=true", "--multimodelMode=false"}},
+		{Title: "Benchmark", Description: "Benchmark the configured models and record their metrics", Args: []string{"benchmark"}},
+		{Title: "Explore metrics", Description: "Browse benchmark results and per-question records", Args: []string{"metrics", "explore"}},
+	}
+	if cfg == nil {
+		return choices
+	}
+
+	if store := newSessionStore(cfg); store != nil {
+		summaries, err := store.List()
+		if err != nil {
+			logging.LogError("mode menu sessions: %v", err)
+		}
+		if len(summaries) > modeMenuRecentSessions {
+			summaries = summaries[:modeMenuRecentSessions]
+		}
+		for _, summary := range summaries {
+			item := sessionItem{summary: summary}
+			choices = append(choices, ModeChoice{
+				Title:       "Resume: " + item.Title(),
+				Description: item.Description(),
+				Args:        []string{"chat", "--resume", summary.ID, "--multimodelMode=false", "--pipelineMode=false"},
+			})
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		choices = append(choices, ModeChoice{
+			Title:       "Preset: " + name,
+			Description: "agon " + cfg.Aliases[name],
+			Args:        []string{name},
+		})
+	}
+	return choices
+}
+
+// modeMenu is the startup menu shown when agon runs without a command.
+type modeMenu struct {
+	list   list.Model
+	choice *ModeChoice
+}
+
+// newModeMenu builds the menu over choices.
+func newModeMenu(choices []ModeChoice) *modeMenu {
+	items := make([]list.Item, len(choices))
+	for i, choice := range choices {
+		items[i] = modeChoiceItem{choice: choice}
+	}
+	menu := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	menu.Title = "agon: choose a mode"
+	return &modeMenu{list: menu}
+}
+
+// Init implements tea.Model.
+func (m *modeMenu) Init() tea.Cmd {
+	return nil
+}
+
+// Update picks the highlighted choice on enter and quits on esc or ctrl+c.
+func (m *modeMenu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := lipgloss.NewStyle().Margin(1, 2).GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			if selected, ok := m.list.SelectedItem().(modeChoiceItem); ok {
+				choice := selected.choice
+				m.choice = &choice
+				return m, tea.Quit
+			}
+		case "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View renders the menu.
+func (m *modeMenu) View() string {
+	return lipgloss.NewStyle().Margin(1, 2).Render(m.list.View())
+}
+
+// RunModeMenu shows the startup mode menu and returns the agon arguments of the chosen
+// entry, or nil when the menu is closed without a choice.
+func RunModeMenu(cfg *Config) ([]string, error) {
+	menu := newModeMenu(ModeMenuChoices(cfg))
+	if _, err := tea.NewProgram(menu, tea.WithAltScreen()).Run(); err != nil {
+		return nil, fmt.Errorf("mode menu: %w", err)
+	}
+	if menu.choice == nil {
+		return nil, nil
+	}
+	return menu.choice.Args, nil
+}
@@ -0,0 +1,48 @@
+// cli/mode_menu_test.go
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/sessions"
+)
+
+// TestModeMenuChoices verifies the menu lists the modes, then recent sessions, then aliases.
+func TestModeMenuChoices(t *testing.T) {
+	cfg := &Config{SessionsDir: t.TempDir(), Aliases: map[string]string{"smoke": "benchmark --benchmarkCount 3", "ask": "chat"}}
+	session := sessions.NewStore(cfg.SessionsDir).Create("HostA", "m1")
+	if err := session.Append("user", "summarize the report"); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	choices := ModeMenuChoices(cfg)
+	if len(choices) != 8 {
+		t.Fatalf("expected 5 modes, 1 session and 2 presets, got %+v", choices)
+	}
+	if choices[2].Title != "Pipeline" || strings.Join(choices[2].Args, " ") != "chat --pipelineMode=true --multimodelMode=false" {
+		t.Fatalf("unexpected pipeline choice: %+v", choices[2])
+	}
+	if resume := choices[5]; resume.Title != "Resume: summarize the report" || resume.Args[2] != session.ID {
+		t.Fatalf("unexpected session choice: %+v", resume)
+	}
+	if choices[6].Title != "Preset: ask" || choices[7].Description != "agon benchmark --benchmarkCount 3" || choices[7].Args[0] != "smoke" {
+		t.Fatalf("unexpected preset choices: %+v", choices[6:])
+	}
+}
+
+// TestModeMenuSelect verifies enter picks the highlighted entry and esc closes without one.
+func TestModeMenuSelect(t *testing.T) {
+	menu := newModeMenu(ModeMenuChoices(nil))
+	menu.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if _, cmd := menu.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || menu.choice == nil || menu.choice.Title != "Multimodel" {
+		t.Fatalf("expected Multimodel to be chosen, got %+v", menu.choice)
+	}
+
+	closed := newModeMenu(ModeMenuChoices(nil))
+	if _, cmd := closed.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil || closed.choice != nil {
+		t.Fatalf("expected esc to quit without a choice, got %+v", closed.choice)
+	}
+}
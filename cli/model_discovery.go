@@ -0,0 +1,67 @@
+// cli/model_discovery.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/models"
+)
+
+// refreshModelsKey re-queries hosts for their models from the host and model pickers.
+var refreshModelsKey = key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh models"))
+
+// refreshModelsHelp exposes refreshModelsKey in a list's short help.
+func refreshModelsHelp() []key.Binding {
+	return []key.Binding{refreshModelsKey}
+}
+
+// modelsRefreshedMsg carries model lists re-discovered from the configured hosts.
+type modelsRefreshedMsg struct {
+	found map[string][]string
+	err   error
+}
+
+// discoverStartupModels fills empty host model lists from the hosts themselves so
+// the pickers have something to offer without a hand-maintained models array.
+func discoverStartupModels(cfg *Config) {
+	if err := models.DiscoverModels(cfg); err != nil {
+		logging.LogWarn("model discovery incomplete: %v", err)
+	}
+}
+
+// refreshModelsCmd re-queries every host whose models were discovered or left empty.
+// The result is applied in Update so the config is only mutated on the UI goroutine.
+func refreshModelsCmd(cfg *Config) tea.Cmd {
+	snapshot := *cfg
+	snapshot.Hosts = append([]Host(nil), cfg.Hosts...)
+	return func() tea.Msg {
+		found, err := models.DiscoverHostModels(snapshot, true)
+		return modelsRefreshedMsg{found: found, err: err}
+	}
+}
+
+// applyModelsRefresh stores a refresh result on the config and returns a status line.
+func applyModelsRefresh(cfg *Config, msg modelsRefreshedMsg) string {
+	models.ApplyDiscoveredModels(cfg, msg.found)
+	if msg.err != nil {
+		logging.LogWarn("model refresh incomplete: %v", msg.err)
+		return fmt.Sprintf("Model refresh incomplete: %v", msg.err)
+	}
+	if len(msg.found) == 0 {
+		return "No hosts use discovered models; add an empty models list to enable refresh"
+	}
+	return fmt.Sprintf("Refreshed models on %d host(s)", len(msg.found))
+}
+
+// hostModels returns the current model list for the named host.
+func hostModels(cfg *Config, name string) []string {
+	for _, host := range cfg.Hosts {
+		if host.Name == name {
+			return host.Models
+		}
+	}
+	return nil
+}
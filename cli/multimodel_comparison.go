@@ -0,0 +1,187 @@
+// cli/multimodel_comparison.go
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultComparisonExportPath is the file stem used when comparisonExport is not configured.
+const defaultComparisonExportPath = "multimodel-comparison"
+
+// comparisonRow records one model's response to one prompt in multimodel mode.
+type comparisonRow struct {
+	Prompt          string
+	Column          int
+	Host            string
+	Model           string
+	ResponseChars   int
+	LatencySeconds  float64
+	TokensPerSecond float64
+	Rating          int
+	// Sampling summarizes the parameters the response was generated with.
+	Sampling string
+}
+
+// recordComparison appends a row for a column whose stream just completed.
+func (m *multimodelModel) recordComparison(hostIndex int, meta LLMResponseMeta) {
+	if hostIndex < 0 || hostIndex >= len(m.assignments) || hostIndex >= len(m.columnResponses) {
+		return
+	}
+	column := &m.columnResponses[hostIndex]
+	var prompt, response string
+	responseIndex := -1
+	for i, msg := range column.chatHistory {
+		if msg.Role == "user" {
+			prompt = msg.Content
+			response, responseIndex = "", -1
+		} else if msg.Role == "assistant" {
+			response, responseIndex = msg.Content, i
+		}
+	}
+
+	row := comparisonRow{
+		Prompt:        prompt,
+		Column:        hostIndex + 1,
+		Host:          m.assignments[hostIndex].host.Name,
+		Model:         m.assignments[hostIndex].selectedModel,
+		ResponseChars: utf8.RuneCountInString(response),
+	}
+	if params, ok := column.messageParams[responseIndex]; ok {
+		row.Sampling = formatSamplingSummary(params.Parameters)
+	}
+	if !column.requestStartTime.IsZero() {
+		row.LatencySeconds = time.Since(column.requestStartTime).Seconds()
+	}
+	if evalSecs := time.Duration(meta.EvalDuration).Seconds(); evalSecs > 0 {
+		row.TokensPerSecond = float64(meta.EvalCount) / evalSecs
+	}
+	m.comparisonRows = append(m.comparisonRows, row)
+}
+
+// applyRating handles "/rate <column> <1-5>", scoring the column's latest response.
+// It reports whether input was a rating command.
+func (m *multimodelModel) applyRating(input string) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/rate" {
+		return false
+	}
+	if len(fields) != 3 {
+		m.statusBanner = "Usage: /rate <column 1-4> <score 1-5>"
+		return true
+	}
+	column, err := strconv.Atoi(fields[1])
+	if err != nil || column < 1 || column > len(m.columnResponses) {
+		m.statusBanner = fmt.Sprintf("Invalid column %q", fields[1])
+		return true
+	}
+	score, err := strconv.Atoi(fields[2])
+	if err != nil || score < 1 || score > 5 {
+		m.statusBanner = fmt.Sprintf("Invalid score %q: use 1-5", fields[2])
+		return true
+	}
+	for i := len(m.comparisonRows) - 1; i >= 0; i-- {
+		if m.comparisonRows[i].Column == column {
+			m.comparisonRows[i].Rating = score
+			m.statusBanner = fmt.Sprintf("Rated column %d (%s): %d/5", column, m.comparisonRows[i].Model, score)
+			return true
+		}
+	}
+	m.statusBanner = fmt.Sprintf("Column %d has no response to rate yet", column)
+	return true
+}
+
+// exportComparison writes the comparison table as CSV and Markdown next to each other.
+func (m *multimodelModel) exportComparison() {
+	if len(m.comparisonRows) == 0 {
+		m.statusBanner = "Send a prompt before exporting"
+		return
+	}
+	stem := strings.TrimSpace(m.config.ComparisonExportPath)
+	if stem == "" {
+		stem = defaultComparisonExportPath
+	}
+	stem = strings.TrimSuffix(strings.TrimSuffix(stem, ".csv"), ".md")
+
+	var notices []string
+	if err := writeComparisonCSV(stem+".csv", m.comparisonRows); err != nil {
+		notices = append(notices, fmt.Sprintf("CSV export failed: %v", err))
+	} else {
+		notices = append(notices, fmt.Sprintf("CSV → %s.csv", stem))
+	}
+	if err := os.WriteFile(stem+".md", []byte(renderComparisonMarkdown(m.comparisonRows)), 0o644); err != nil {
+		notices = append(notices, fmt.Sprintf("Markdown export failed: %v", err))
+	} else {
+		notices = append(notices, fmt.Sprintf("Markdown → %s.md", stem))
+	}
+	m.statusBanner = strings.Join(notices, " | ")
+}
+
+// comparisonHeader lists the exported columns in order.
+var comparisonHeader = []string{"prompt", "column", "host", "model", "response_chars", "latency_seconds", "tokens_per_second", "rating", "sampling"}
+
+// comparisonRecord formats a row for export; unrated rows leave the rating blank.
+func comparisonRecord(row comparisonRow) []string {
+	rating := ""
+	if row.Rating > 0 {
+		rating = strconv.Itoa(row.Rating)
+	}
+	return []string{
+		row.Prompt,
+		strconv.Itoa(row.Column),
+		row.Host,
+		row.Model,
+		strconv.Itoa(row.ResponseChars),
+		strconv.FormatFloat(row.LatencySeconds, 'f', 2, 64),
+		strconv.FormatFloat(row.TokensPerSecond, 'f', 2, 64),
+		rating,
+		row.Sampling,
+	}
+}
+
+// writeComparisonCSV writes rows to path as CSV with a header line.
+func writeComparisonCSV(path string, rows []comparisonRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(comparisonHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(comparisonRecord(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// renderComparisonMarkdown renders rows as a Markdown table.
+func renderComparisonMarkdown(rows []comparisonRow) string {
+	escape := func(value string) string {
+		value = strings.ReplaceAll(value, "|", `\|`)
+		return strings.Join(strings.Fields(value), " ")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Multimodel Comparison\n\n")
+	builder.WriteString("| " + strings.Join(comparisonHeader, " | ") + " |\n")
+	builder.WriteString("|" + strings.Repeat(" --- |", len(comparisonHeader)) + "\n")
+	for _, row := range rows {
+		record := comparisonRecord(row)
+		for i := range record {
+			record[i] = escape(record[i])
+		}
+		builder.WriteString("| " + strings.Join(record, " | ") + " |\n")
+	}
+	return builder.String()
+}
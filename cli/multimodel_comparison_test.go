@@ -0,0 +1,79 @@
+// cli/multimodel_comparison_test.go
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMultimodelComparisonRecordRateExport verifies completed streams become comparison
+// rows, that /rate scores the latest row for a column, and that CSV and Markdown exports
+// carry the table.
+func TestMultimodelComparisonRecordRateExport(t *testing.T) {
+	stem := filepath.Join(t.TempDir(), "comparison")
+	cfg := &Config{
+		ComparisonExportPath: stem,
+		Hosts: []Host{
+			{Name: "alpha", Models: []string{"llama3"}},
+			{Name: "beta", Models: []string{"qwen3"}},
+		},
+	}
+	m := initialMultimodelModel(context.Background(), cfg, newTestProvider())
+	for i := range m.assignments {
+		m.assignments[i].isAssigned = true
+		m.assignments[i].selectedModel = cfg.Hosts[i].Models[0]
+		m.columnResponses[i].requestStartTime = time.Now()
+		m.columnResponses[i].chatHistory = []chatMessage{
+			{Role: "user", Content: "Compare, please"},
+			{Role: "assistant", Content: "héllo"},
+		}
+	}
+
+	m.recordComparison(0, LLMResponseMeta{EvalCount: 20, EvalDuration: int64(2 * time.Second)})
+	m.recordComparison(1, LLMResponseMeta{})
+
+	if len(m.comparisonRows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(m.comparisonRows))
+	}
+	if row := m.comparisonRows[0]; row.ResponseChars != 5 || row.TokensPerSecond != 10 || row.Model != "llama3" {
+		t.Fatalf("unexpected first row: %+v", row)
+	}
+
+	if m.applyRating("hello") {
+		t.Fatalf("expected plain prompt to pass through")
+	}
+	if !m.applyRating("/rate 2 4") || m.comparisonRows[1].Rating != 4 {
+		t.Fatalf("expected column 2 to be rated, got %+v (%s)", m.comparisonRows[1], m.statusBanner)
+	}
+	if !m.applyRating("/rate 2 9") || m.comparisonRows[1].Rating != 4 {
+		t.Fatalf("expected out-of-range score to be rejected")
+	}
+
+	m.exportComparison()
+
+	file, err := os.Open(stem + ".csv")
+	if err != nil {
+		t.Fatalf("open csv: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "Compare, please" || records[2][7] != "4" || records[1][7] != "" {
+		t.Fatalf("unexpected csv records: %v", records)
+	}
+
+	markdown, err := os.ReadFile(stem + ".md")
+	if err != nil {
+		t.Fatalf("read markdown: %v", err)
+	}
+	if !strings.Contains(string(markdown), "| Compare, please | 2 | beta | qwen3 | 5 |") {
+		t.Fatalf("unexpected markdown:\n%s", markdown)
+	}
+}
@@ -0,0 +1,79 @@
+// cli/pipeline_autoassign.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// autoAssignStages fills every stage with a host and model recommended by the latest
+// metrics analysis: the fastest interactive model for early stages and the most accurate
+// usable model for the final stage.
+func (m *pipelineModel) autoAssignStages() {
+	path := m.config.AnalysisFilePath()
+	result, err := analysis.LoadAnalysis(path)
+	if err != nil {
+		m.statusBanner = fmt.Sprintf("Auto-assign unavailable: %v (run 'agon analyze metrics' first)", err)
+		return
+	}
+
+	var candidates []string
+	for _, host := range m.config.Hosts {
+		candidates = append(candidates, host.Models...)
+	}
+
+	picks, err := analysis.RecommendPipelineModels(result, candidates, len(m.stages))
+	if err != nil {
+		m.statusBanner = fmt.Sprintf("Auto-assign unavailable: %v", err)
+		return
+	}
+
+	for i, modelName := range picks {
+		hostIndex := m.hostForModel(modelName)
+		if hostIndex < 0 {
+			continue
+		}
+		m.assignStage(i, hostIndex, modelName)
+	}
+	m.statusBanner = fmt.Sprintf("Stages auto-assigned from %s", path)
+}
+
+// hostForModel returns the index of a host serving modelName, preferring hosts that
+// already have it loaded. It returns -1 when no host lists the model.
+func (m *pipelineModel) hostForModel(modelName string) int {
+	first := -1
+	for i, host := range m.config.Hosts {
+		for _, candidate := range host.Models {
+			if candidate != modelName {
+				continue
+			}
+			if m.warmState[host.Name].isLoaded(modelName) {
+				return i
+			}
+			if first == -1 {
+				first = i
+			}
+		}
+	}
+	return first
+}
+
+// assignStage binds a stage to a configured host and model, resetting its prior output.
+func (m *pipelineModel) assignStage(index, hostIndex int, modelName string) {
+	host := m.config.Hosts[hostIndex]
+	stage := &m.stages[index]
+	stage.host = host
+	stage.hostIndex = hostIndex
+	stage.availableModels = append([]string(nil), host.Models...)
+	stage.parameters = host.Parameters
+	stage.systemPrompt = host.SystemPrompt
+	stage.selectedModel = modelName
+	stage.hasAssignment = true
+	stage.context = nil
+	stage.status = pipelineStageStatusWaiting
+	stage.statusMessage = "Ready"
+	stage.view = pipelineStageViewOutput
+	stage.outputBuffer.Reset()
+	stage.finalOutput = ""
+}
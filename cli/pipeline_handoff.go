@@ -0,0 +1,287 @@
+// cli/pipeline_handoff.go
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/util"
+)
+
+// errHandoffInvalidJSON reports stage output that is not valid JSON in JSON mode.
+var errHandoffInvalidJSON = errors.New("JSON validation failed")
+
+// String returns the mode's display name.
+func (h pipelineHandoffMode) String() string {
+	switch h {
+	case pipelineHandoffSelector:
+		return "selector"
+	case pipelineHandoffTemplate:
+		return "template"
+	default:
+		return "raw"
+	}
+}
+
+// handoffMode reports how the stage shapes its output for the next stage. A template
+// takes precedence because it can embed the selector's result.
+func (s pipelineStage) handoffMode() pipelineHandoffMode {
+	switch {
+	case strings.TrimSpace(s.handoffTemplate) != "":
+		return pipelineHandoffTemplate
+	case strings.TrimSpace(s.handoffSelector) != "":
+		return pipelineHandoffSelector
+	default:
+		return pipelineHandoffRaw
+	}
+}
+
+// handoffTemplateData is the data a handoff template is executed against.
+type handoffTemplateData struct {
+	// Output is the stage's full output.
+	Output string
+	// JSON is the output decoded as JSON, or nil when it is not JSON.
+	JSON any
+	// Selected is the selector's result, or "" when no selector is set.
+	Selected string
+	// Input is the prompt the stage received.
+	Input string
+	// Stage is the 1-based stage number.
+	Stage int
+}
+
+// shapeHandoff applies the stage's selector and template to its output.
+func shapeHandoff(stage pipelineStage, output, input string) (string, error) {
+	data := handoffTemplateData{Output: output, Input: input, Stage: stage.index + 1}
+	var decoded any
+	if err := json.Unmarshal([]byte(output), &decoded); err == nil {
+		data.JSON = decoded
+	}
+
+	if selector := strings.TrimSpace(stage.handoffSelector); selector != "" {
+		if data.JSON == nil {
+			return "", fmt.Errorf("selector %q needs JSON output", selector)
+		}
+		value, err := selectJSON(data.JSON, selector)
+		if err != nil {
+			return "", err
+		}
+		data.Selected = formatSelected(value)
+	}
+
+	text := strings.TrimSpace(stage.handoffTemplate)
+	if text == "" {
+		return data.Selected, nil
+	}
+	tmpl, err := parseHandoffTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("handoff template: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// parseHandoffTemplate parses a handoff template. Besides the standard functions,
+// templates can call json to encode a value and select to apply a selector.
+func parseHandoffTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"select": func(path string, v any) (string, error) {
+			value, err := selectJSON(v, path)
+			if err != nil {
+				return "", err
+			}
+			return formatSelected(value), nil
+		},
+	}
+	tmpl, err := template.New("handoff").Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("handoff template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// selectorStep is one field name or array index in a selector path.
+type selectorStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseSelector splits a selector such as "$.items[0].name" or "items.0.name" into steps.
+func parseSelector(path string) ([]selectorStep, error) {
+	rest := strings.TrimSpace(path)
+	rest = strings.TrimPrefix(rest, "$")
+	rest = strings.TrimPrefix(rest, ".")
+	var steps []selectorStep
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: unclosed [", path)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("selector %q: invalid index %q", path, rest[1:end])
+			}
+			steps = append(steps, selectorStep{index: n, isIndex: true})
+			rest = strings.TrimPrefix(rest[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		if key == "" {
+			return nil, fmt.Errorf("selector %q: empty field name", path)
+		}
+		step := selectorStep{key: key}
+		if n, err := strconv.Atoi(key); err == nil {
+			step.index, step.isIndex = n, true
+		}
+		steps = append(steps, step)
+		rest = strings.TrimPrefix(rest[end:], ".")
+	}
+	return steps, nil
+}
+
+// selectJSON walks a decoded JSON value along a selector path. Negative indexes count
+// from the end of an array.
+func selectJSON(value any, path string) (any, error) {
+	steps, err := parseSelector(path)
+	if err != nil {
+		return nil, err
+	}
+	current := value
+	for _, step := range steps {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[step.key]
+			if !ok {
+				return nil, fmt.Errorf("selector %q: no field %q", path, step.key)
+			}
+			current = next
+		case []any:
+			if !step.isIndex {
+				return nil, fmt.Errorf("selector %q: %q is not an array index", path, step.key)
+			}
+			i := step.index
+			if i < 0 {
+				i += len(node)
+			}
+			if i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("selector %q: index %d out of range (%d items)", path, step.index, len(node))
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("selector %q: cannot descend into %T", path, current)
+		}
+	}
+	return current, nil
+}
+
+// formatSelected renders a selected value, passing strings through unquoted and
+// encoding everything else as JSON.
+func formatSelected(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// startHandoffEdit opens the selector or template editor for the focused stage.
+func (m *pipelineModel) startHandoffEdit(mode pipelineHandoffMode) tea.Cmd {
+	if m.runInProgress {
+		m.statusBanner = "Wait for the current run to finish before editing a handoff"
+		return nil
+	}
+	if m.focusIndex < 0 || m.focusIndex >= len(m.stages) {
+		return nil
+	}
+	stage := m.stages[m.focusIndex]
+	input := textinput.New()
+	input.CharLimit = 0
+	input.Width = util.Max(20, m.width-30)
+	if mode == pipelineHandoffTemplate {
+		input.Prompt = fmt.Sprintf("Stage %d template: ", stage.index+1)
+		input.Placeholder = `Summarize: {{.Selected}}`
+		input.SetValue(stage.handoffTemplate)
+	} else {
+		input.Prompt = fmt.Sprintf("Stage %d selector: ", stage.index+1)
+		input.Placeholder = "$.items[0].name"
+		input.SetValue(stage.handoffSelector)
+	}
+	m.handoffEditor = input
+	m.handoffEditMode = mode
+	m.editingHandoff = true
+	return m.handoffEditor.Focus()
+}
+
+// updateHandoffEditor handles keys while a selector or template is being edited.
+// Enter saves (an empty value clears it) and Esc cancels.
+func (m *pipelineModel) updateHandoffEditor(msg tea.Msg) tea.Cmd {
+	if km, ok := msg.(tea.KeyMsg); ok {
+		switch km.String() {
+		case "esc":
+			m.editingHandoff = false
+			return nil
+		case "enter":
+			value := strings.TrimSpace(m.handoffEditor.Value())
+			if err := validateHandoffSpec(m.handoffEditMode, value); err != nil {
+				m.statusBanner = err.Error()
+				return nil
+			}
+			stage := &m.stages[m.focusIndex]
+			if m.handoffEditMode == pipelineHandoffTemplate {
+				stage.handoffTemplate = value
+			} else {
+				stage.handoffSelector = value
+			}
+			m.editingHandoff = false
+			m.statusBanner = fmt.Sprintf("Stage %d hands off via %s", stage.index+1, stage.handoffMode())
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.handoffEditor, cmd = m.handoffEditor.Update(msg)
+	return cmd
+}
+
+// validateHandoffSpec checks a selector or template before it is saved.
+func validateHandoffSpec(mode pipelineHandoffMode, value string) error {
+	if value == "" {
+		return nil
+	}
+	if mode == pipelineHandoffTemplate {
+		_, err := parseHandoffTemplate(value)
+		return err
+	}
+	_, err := parseSelector(value)
+	return err
+}
+
+// handoffModeLabel returns the mode name for exports, leaving raw handoffs unlabelled.
+func handoffModeLabel(mode pipelineHandoffMode) string {
+	if mode == pipelineHandoffRaw {
+		return ""
+	}
+	return mode.String()
+}
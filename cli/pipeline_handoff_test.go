@@ -0,0 +1,91 @@
+// cli/pipeline_handoff_test.go
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+// TestSelectJSON verifies dotted, bracketed and negative-index selectors and their errors.
+func TestSelectJSON(t *testing.T) {
+	doc := map[string]any{
+		"items": []any{
+			map[string]any{"name": "alpha", "tags": []any{"a", "b"}},
+			map[string]any{"name": "beta"},
+		},
+	}
+	cases := map[string]string{
+		"$.items[0].name": "alpha",
+		"items.1.name":    "beta",
+		"items[-1].name":  "beta",
+		"items[0].tags":   `["a","b"]`,
+	}
+	for path, want := range cases {
+		value, err := selectJSON(doc, path)
+		if err != nil {
+			t.Fatalf("selectJSON(%q) returned error: %v", path, err)
+		}
+		if got := formatSelected(value); got != want {
+			t.Fatalf("selectJSON(%q) = %s, want %s", path, got, want)
+		}
+	}
+	for _, path := range []string{"items[2]", "missing", "items.name", "items[x]", "items[0"} {
+		if _, err := selectJSON(doc, path); err == nil {
+			t.Fatalf("selectJSON(%q) should fail", path)
+		}
+	}
+}
+
+// TestPrepareHandoffShaping verifies that selectors and templates shape the handoff
+// payload, that raw stages pass output through, and that shaping errors stop the run.
+func TestPrepareHandoffShaping(t *testing.T) {
+	m := &pipelineModel{config: &Config{}, stageInputs: []string{"Describe the city."}, textArea: textarea.New()}
+	output := `{"city":"Paris","facts":["capital of France","on the Seine"]}`
+
+	stage := &pipelineStage{finalOutput: output, handoffSelector: "$.facts[0]"}
+	if err := m.prepareHandoff(stage); err != nil {
+		t.Fatalf("prepareHandoff returned error: %v", err)
+	}
+	if stage.handoff.payload != "capital of France" || stage.handoff.mode != pipelineHandoffSelector {
+		t.Fatalf("unexpected selector handoff: %+v", stage.handoff)
+	}
+
+	stage.handoffTemplate = `Expand on {{.JSON.city}}, the {{.Selected}}. Original task: {{.Input}}`
+	if err := m.prepareHandoff(stage); err != nil {
+		t.Fatalf("prepareHandoff returned error: %v", err)
+	}
+	want := "Expand on Paris, the capital of France. Original task: Describe the city."
+	if stage.handoff.payload != want || stage.handoff.mode != pipelineHandoffTemplate {
+		t.Fatalf("template handoff = %q (%s), want %q", stage.handoff.payload, stage.handoff.mode, want)
+	}
+	if record := m.buildExportRecord(0, stage); record.HandoffMode != "template" || record.HandoffSelector != "$.facts[0]" {
+		t.Fatalf("export should record the handoff spec: %+v", record)
+	}
+
+	raw := &pipelineStage{finalOutput: "plain text"}
+	if err := m.prepareHandoff(raw); err != nil || raw.handoff.payload != "plain text" || raw.handoff.mode != pipelineHandoffRaw {
+		t.Fatalf("raw handoff changed output: %+v, %v", raw.handoff, err)
+	}
+
+	broken := &pipelineStage{finalOutput: "not json", handoffSelector: "city"}
+	err := m.prepareHandoff(broken)
+	if err == nil || !strings.Contains(err.Error(), "needs JSON output") {
+		t.Fatalf("selector over plain text should fail, got %v", err)
+	}
+	m.failHandoff(broken, err)
+	if broken.statusMessage != "Handoff failed" || !strings.Contains(m.statusBanner, "Stage 1 handoff") {
+		t.Fatalf("unexpected failure state: %q / %q", broken.statusMessage, m.statusBanner)
+	}
+
+	m.config.JSONMode = true
+	if err := m.prepareHandoff(&pipelineStage{finalOutput: "{{{"}); !errors.Is(err, errHandoffInvalidJSON) {
+		t.Fatalf("invalid JSON in JSON mode should report errHandoffInvalidJSON, got %v", err)
+	}
+
+	if err := validateHandoffSpec(pipelineHandoffTemplate, "{{.Output"); err == nil {
+		t.Fatalf("unterminated template should be rejected when saved")
+	}
+}
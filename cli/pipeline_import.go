@@ -0,0 +1,101 @@
+// cli/pipeline_import.go
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pipelineImport carries a multimodel column's host and model, and optionally its
+// conversation, into a pipeline stage when ctrl+p switches to Pipeline mode.
+type pipelineImport struct {
+	stage   int
+	host    Host
+	model   string
+	context []chatMessage
+}
+
+// applyStageImport handles "/stage <column> <stage> [context]" in multimodel chat. It
+// reports whether the input was the command, so it is not sent as a prompt.
+func (m *multimodelModel) applyStageImport(input string) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/stage" {
+		return false
+	}
+	if len(fields) < 3 || len(fields) > 4 || (len(fields) == 4 && fields[3] != "context") {
+		m.statusBanner = "Usage: /stage <column 1-4> <pipeline stage> [context]"
+		return true
+	}
+	column, err := strconv.Atoi(fields[1])
+	if err != nil || column < 1 || column > len(m.columnResponses) || column > len(m.assignments) || !m.assignments[column-1].isAssigned {
+		m.statusBanner = fmt.Sprintf("Column %q has no model assigned", fields[1])
+		return true
+	}
+	stages := m.config.PipelineStageCount()
+	stage, err := strconv.Atoi(fields[2])
+	if err != nil || stage < 1 || stage > stages {
+		m.statusBanner = fmt.Sprintf("Invalid stage %q: use 1-%d", fields[2], stages)
+		return true
+	}
+
+	assignment := m.assignments[column-1]
+	imported := pipelineImport{stage: stage - 1, host: assignment.host, model: assignment.selectedModel}
+	if len(fields) == 4 {
+		imported.context = append([]chatMessage(nil), m.columnResponses[column-1].chatHistory...)
+	}
+	for i, existing := range m.pipelineImports {
+		if existing.stage == imported.stage {
+			m.pipelineImports = append(m.pipelineImports[:i], m.pipelineImports[i+1:]...)
+			break
+		}
+	}
+	m.pipelineImports = append(m.pipelineImports, imported)
+
+	withContext := ""
+	if imported.context != nil {
+		withContext = fmt.Sprintf(" with %d messages of context", len(imported.context))
+	}
+	m.statusBanner = fmt.Sprintf("Stage %d ← %s/%s%s (ctrl+p to open Pipeline)", stage, assignment.host.Name, assignment.selectedModel, withContext)
+	return true
+}
+
+// applyImports assigns the stages imported from multimodel chat, skipping any whose host
+// or stage no longer exists.
+func (m *pipelineModel) applyImports(imports []pipelineImport) {
+	applied := 0
+	for _, imported := range imports {
+		if imported.stage >= len(m.stages) {
+			continue
+		}
+		hostIndex := -1
+		for i, host := range m.config.Hosts {
+			if host.Name == imported.host.Name {
+				hostIndex = i
+				break
+			}
+		}
+		if hostIndex < 0 {
+			continue
+		}
+		m.assignStage(imported.stage, hostIndex, imported.model)
+		m.stages[imported.stage].context = imported.context
+		applied++
+	}
+	if applied > 0 {
+		m.statusBanner = fmt.Sprintf("Imported %d stage assignment(s) from multimodel chat", applied)
+	}
+}
+
+// cachePayload prefixes payload with the stage's imported context, so runs with and
+// without it are cached apart.
+func (s *pipelineStage) cachePayload(payload string) string {
+	if len(s.context) == 0 {
+		return payload
+	}
+	var b strings.Builder
+	for _, msg := range s.context {
+		b.WriteString(msg.Role + ": " + msg.Content + "\n")
+	}
+	return b.String() + payload
+}
@@ -0,0 +1,55 @@
+// cli/pipeline_import_test.go
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestPipelineImportFromMultimodel verifies /stage records a column's host and model,
+// optionally with its conversation, and that Pipeline mode assigns the stage and starts
+// each run from the imported context.
+func TestPipelineImportFromMultimodel(t *testing.T) {
+	cfg := &Config{Hosts: []Host{
+		{Name: "alpha", Models: []string{"llama3"}},
+		{Name: "beta", Models: []string{"qwen3"}},
+	}}
+	mm := initialMultimodelModel(context.Background(), cfg, newTestProvider())
+	mm.assignments[1].isAssigned = true
+	mm.assignments[1].selectedModel = "qwen3"
+	mm.columnResponses[1].chatHistory = []chatMessage{
+		{Role: "user", Content: "Summarize this"},
+		{Role: "assistant", Content: "Done."},
+	}
+
+	if mm.applyStageImport("summarize the stage") {
+		t.Fatalf("expected a plain prompt to pass through")
+	}
+	if !mm.applyStageImport("/stage 1 2") || len(mm.pipelineImports) != 0 {
+		t.Fatalf("expected an unassigned column to be rejected, banner %q", mm.statusBanner)
+	}
+	if !mm.applyStageImport("/stage 2 9") || !strings.Contains(mm.statusBanner, "Invalid stage") {
+		t.Fatalf("expected an out-of-range stage to be rejected, banner %q", mm.statusBanner)
+	}
+	mm.applyStageImport("/stage 2 3")
+	mm.applyStageImport("/stage 2 3 context")
+	if len(mm.pipelineImports) != 1 || mm.pipelineImports[0].stage != 2 || len(mm.pipelineImports[0].context) != 2 {
+		t.Fatalf("expected one import for stage 3 with context, got %+v", mm.pipelineImports)
+	}
+
+	pm := initialPipelineModel(context.Background(), cfg, newTestProvider())
+	pm.applyImports(mm.pipelineImports)
+	stage := pm.stages[2]
+	if !stage.hasAssignment || stage.host.Name != "beta" || stage.selectedModel != "qwen3" || len(stage.context) != 2 {
+		t.Fatalf("unexpected imported stage: %+v", stage)
+	}
+	if stage.cachePayload("x") == "x" {
+		t.Fatalf("expected the imported context to be part of the cache key")
+	}
+
+	pm.startPipelineRun("Go")
+	if got := pm.stages[2].history; len(got) != 3 || got[0].Content != "Summarize this" || got[2].Content != "Go" {
+		t.Fatalf("expected the run to start from the imported context, got %+v", got)
+	}
+}
@@ -0,0 +1,82 @@
+// cli/pipeline_latency.go
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+// stageLatency attributes a stage's wall-clock time to network, model, and agon-side phases.
+type stageLatency struct {
+	WallSeconds       float64 `json:"wallSeconds"`
+	QueueSeconds      float64 `json:"queueSeconds"`
+	CacheLookupMillis float64 `json:"cacheLookupMillis"`
+	NetworkSeconds    float64 `json:"networkSeconds"`
+	LoadSeconds       float64 `json:"loadSeconds"`
+	PromptEvalSeconds float64 `json:"promptEvalSeconds"`
+	GenerationSeconds float64 `json:"generationSeconds"`
+	OverheadSeconds   float64 `json:"overheadSeconds"`
+}
+
+// computeStageLatency derives the latency breakdown from stage timestamps and provider metadata.
+// Network/TTFB is the time to the first streamed chunk minus the server-reported load and
+// prompt evaluation; overhead is everything on the agon side that the provider did not account for.
+func computeStageLatency(stage pipelineStage) stageLatency {
+	var out stageLatency
+	if stage.queuedAt.IsZero() || stage.completedAt.IsZero() {
+		return out
+	}
+
+	out.WallSeconds = stage.completedAt.Sub(stage.queuedAt).Seconds()
+	out.CacheLookupMillis = float64(stage.cacheLookup) / float64(time.Millisecond)
+	if stage.cacheHit || stage.dispatchedAt.IsZero() {
+		out.OverheadSeconds = out.WallSeconds
+		return out
+	}
+
+	out.QueueSeconds = nonNegativeSeconds(stage.dispatchedAt.Sub(stage.queuedAt))
+	out.LoadSeconds = float64(stage.stats.LoadDuration) / 1e9
+	out.PromptEvalSeconds = float64(stage.stats.PromptEvalDuration) / 1e9
+	out.GenerationSeconds = float64(stage.stats.EvalDuration) / 1e9
+
+	if !stage.firstChunkAt.IsZero() {
+		ttfb := stage.firstChunkAt.Sub(stage.dispatchedAt).Seconds()
+		out.NetworkSeconds = maxFloat(ttfb-out.LoadSeconds-out.PromptEvalSeconds, 0)
+	}
+
+	accounted := out.QueueSeconds + out.NetworkSeconds + out.LoadSeconds + out.PromptEvalSeconds + out.GenerationSeconds
+	out.OverheadSeconds = out.QueueSeconds + maxFloat(out.WallSeconds-accounted, 0)
+	return out
+}
+
+// lines renders the breakdown for the stage stats panel.
+func (l stageLatency) lines() []string {
+	if l.WallSeconds <= 0 {
+		return nil
+	}
+	return []string{
+		"Latency breakdown:",
+		fmt.Sprintf("  Wall: %.2fs", l.WallSeconds),
+		fmt.Sprintf("  Network/TTFB: %.2fs", l.NetworkSeconds),
+		fmt.Sprintf("  Load: %.2fs", l.LoadSeconds),
+		fmt.Sprintf("  Prompt eval: %.2fs", l.PromptEvalSeconds),
+		fmt.Sprintf("  Generation: %.2fs", l.GenerationSeconds),
+		fmt.Sprintf("  agon overhead: %.2fs (queue %.2fs, cache %.1fms)", l.OverheadSeconds, l.QueueSeconds, l.CacheLookupMillis),
+	}
+}
+
+// nonNegativeSeconds converts a duration to seconds, clamping negative values to zero.
+func nonNegativeSeconds(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+	return d.Seconds()
+}
+
+// maxFloat returns the larger of two float64 values.
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
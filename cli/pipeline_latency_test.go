@@ -0,0 +1,43 @@
+// cli/pipeline_latency_test.go
+package cli
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestComputeStageLatency verifies that the breakdown attributes time to network,
+// model phases, and agon overhead, and that cache hits count entirely as overhead.
+func TestComputeStageLatency(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stage := pipelineStage{
+		queuedAt:     base,
+		dispatchedAt: base.Add(100 * time.Millisecond),
+		firstChunkAt: base.Add(1600 * time.Millisecond),
+		completedAt:  base.Add(5 * time.Second),
+		stats: LLMResponseMeta{
+			LoadDuration:       int64(500 * time.Millisecond),
+			PromptEvalDuration: int64(700 * time.Millisecond),
+			EvalDuration:       int64(3 * time.Second),
+		},
+	}
+
+	got := computeStageLatency(stage)
+	approx := func(name string, value, want float64) {
+		t.Helper()
+		if math.Abs(value-want) > 1e-9 {
+			t.Fatalf("%s = %v, want %v", name, value, want)
+		}
+	}
+	approx("wall", got.WallSeconds, 5)
+	approx("queue", got.QueueSeconds, 0.1)
+	approx("network", got.NetworkSeconds, 0.3)
+	approx("generation", got.GenerationSeconds, 3)
+	approx("overhead", got.OverheadSeconds, 0.5)
+
+	stage.cacheHit = true
+	got = computeStageLatency(stage)
+	approx("cached overhead", got.OverheadSeconds, 5)
+	approx("cached generation", got.GenerationSeconds, 0)
+}
@@ -0,0 +1,69 @@
+// cli/pipeline_macros.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxPromptMacros is the number of saved prompts reachable from the number keys.
+const maxPromptMacros = 9
+
+// promptMacroIndex maps a key to a zero-based saved prompt index. Bare digits only
+// count while the prompt box is blurred so they can still be typed into prompts;
+// alt+digit works either way.
+func promptMacroIndex(key string, textFocused bool) (int, bool) {
+	digit, alt := strings.CutPrefix(key, "alt+")
+	if !alt && textFocused {
+		return 0, false
+	}
+	if len(digit) != 1 || digit[0] < '1' || digit[0] > '0'+maxPromptMacros {
+		return 0, false
+	}
+	return int(digit[0] - '1'), true
+}
+
+// runPromptMacro fires the saved prompt at index against the assembled pipeline.
+func (m *pipelineModel) runPromptMacro(index int) tea.Cmd {
+	if index >= len(m.config.SavedPrompts) {
+		m.statusBanner = fmt.Sprintf("No saved prompt bound to %d", index+1)
+		return nil
+	}
+	if m.runInProgress {
+		m.statusBanner = "Wait for the current run to finish before firing a saved prompt"
+		return nil
+	}
+	saved := m.config.SavedPrompts[index]
+	if strings.TrimSpace(saved.Prompt) == "" {
+		m.statusBanner = fmt.Sprintf("Saved prompt %d (%s) is empty", index+1, saved.Name)
+		return nil
+	}
+	cmd := m.startPipelineRun(saved.Prompt)
+	m.statusBanner = fmt.Sprintf("Running saved prompt %d: %s", index+1, promptMacroName(index, saved.Name))
+	return cmd
+}
+
+// promptMacroHelp lists the bound saved prompts for the ready view, or "" when none are configured.
+func (m *pipelineModel) promptMacroHelp() string {
+	var parts []string
+	for i, saved := range m.config.SavedPrompts {
+		if i == maxPromptMacros {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", i+1, promptMacroName(i, saved.Name)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Saved prompts (Esc then 1-9, or Alt+1-9): " + strings.Join(parts, "  ")
+}
+
+// promptMacroName returns a saved prompt's display name.
+func promptMacroName(index int, name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return fmt.Sprintf("prompt %d", index+1)
+}
@@ -0,0 +1,54 @@
+// cli/pipeline_macros_test.go
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestPromptMacroKeys verifies that bare digits only fire while the prompt box is blurred,
+// that alt+digit always fires, and that unbound or busy macros report why they did not run.
+func TestPromptMacroKeys(t *testing.T) {
+	cases := []struct {
+		key     string
+		focused bool
+		index   int
+		ok      bool
+	}{
+		{"1", false, 0, true},
+		{"9", false, 8, true},
+		{"1", true, 0, false},
+		{"alt+3", true, 2, true},
+		{"0", false, 0, false},
+		{"alt+x", false, 0, false},
+	}
+	for _, c := range cases {
+		index, ok := promptMacroIndex(c.key, c.focused)
+		if ok != c.ok || (ok && index != c.index) {
+			t.Fatalf("promptMacroIndex(%q, %v) = %d, %v; want %d, %v", c.key, c.focused, index, ok, c.index, c.ok)
+		}
+	}
+
+	m := &pipelineModel{config: &Config{SavedPrompts: []appconfig.SavedPrompt{
+		{Name: "summarize contract", Prompt: "Summarize this contract."},
+		{Prompt: "Extract entities."},
+	}}}
+	help := m.promptMacroHelp()
+	if !strings.Contains(help, "1 summarize contract") || !strings.Contains(help, "2 prompt 2") {
+		t.Fatalf("unexpected macro help: %q", help)
+	}
+
+	if cmd := m.runPromptMacro(4); cmd != nil || !strings.Contains(m.statusBanner, "No saved prompt bound to 5") {
+		t.Fatalf("unbound macro should only set a banner, got %q", m.statusBanner)
+	}
+	m.runInProgress = true
+	if cmd := m.runPromptMacro(0); cmd != nil || !strings.Contains(m.statusBanner, "current run") {
+		t.Fatalf("macro during a run should be refused, got %q", m.statusBanner)
+	}
+
+	if (&pipelineModel{config: &Config{}}).promptMacroHelp() != "" {
+		t.Fatalf("expected no macro help without saved prompts")
+	}
+}
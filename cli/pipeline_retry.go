@@ -0,0 +1,195 @@
+// cli/pipeline_retry.go
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+const (
+	// stageRetryBaseDelay is the wait before a stage's first retry; each later retry doubles it.
+	stageRetryBaseDelay = time.Second
+	// stageRetryMaxDelay caps the wait between retries.
+	stageRetryMaxDelay = 15 * time.Second
+)
+
+// pipelineStageRetryMsg re-dispatches a stage once its retry backoff has elapsed.
+type pipelineStageRetryMsg struct {
+	Stage   int
+	Attempt int
+}
+
+// target returns the host and model the stage currently sends requests to: the fallback
+// once the stage has switched to it, otherwise the primary assignment.
+func (s pipelineStage) target() (Host, string) {
+	if s.usingFallback {
+		return s.fallbackHost, s.fallbackModel
+	}
+	return s.host, s.selectedModel
+}
+
+// hasFallback reports whether a fallback host and model are assigned.
+func (s pipelineStage) hasFallback() bool {
+	return s.fallbackHost.URL != "" && s.fallbackModel != ""
+}
+
+// retryBackoff returns how long to wait before the given retry (1-based).
+func retryBackoff(attempt int) time.Duration {
+	delay := stageRetryBaseDelay
+	for i := 1; i < attempt && delay < stageRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > stageRetryMaxDelay {
+		delay = stageRetryMaxDelay
+	}
+	return delay
+}
+
+// recoverStage decides what to do after a stage attempt fails. Retryable errors are retried
+// with backoff until the stage's retry budget is spent; then, or straight away for errors that
+// would fail the same way again, the stage switches to its fallback assignment. It returns
+// false when the stage has nothing left to try.
+func (m *pipelineModel) recoverStage(stage *pipelineStage, err error) (tea.Cmd, bool) {
+	host, model := stage.target()
+	stage.attemptErrors = append(stage.attemptErrors, fmt.Sprintf("%s • %s: %s", host.Name, model, providers.UserMessage(err)))
+
+	if providers.Retryable(err) && stage.attempt < stage.retries {
+		stage.attempt++
+		stage.retriesUsed++
+		stage.status = pipelineStageStatusRunning
+		stage.statusMessage = fmt.Sprintf("Retry %d/%d", stage.attempt, stage.retries)
+		delay := retryBackoff(stage.attempt)
+		m.statusBanner = fmt.Sprintf("Stage %d failed (%s); retrying in %s", stage.index+1, providers.UserMessage(err), delay)
+		pipelineLog.Warnf("pipeline stage %d attempt failed, retry %d/%d in %s: host=%s model=%s: %v", stage.index+1, stage.attempt, stage.retries, delay, host.Name, model, err)
+		index, attempt := stage.index, stage.attempt
+		return tea.Tick(delay, func(time.Time) tea.Msg {
+			return pipelineStageRetryMsg{Stage: index, Attempt: attempt}
+		}), true
+	}
+
+	if stage.hasFallback() && !stage.usingFallback {
+		stage.usingFallback = true
+		stage.attempt = 0
+		stage.statusMessage = "Fallback: " + stage.fallbackHost.Name
+		m.statusBanner = fmt.Sprintf("Stage %d failed on %s (%s); falling back to %s • %s", stage.index+1, host.Name, providers.UserMessage(err), stage.fallbackHost.Name, stage.fallbackModel)
+		pipelineLog.Warnf("pipeline stage %d falling back to host=%s model=%s after: %v", stage.index+1, stage.fallbackHost.Name, stage.fallbackModel, err)
+		return m.dispatchStage(stage.index), true
+	}
+	return nil, false
+}
+
+// handleStageRetry re-dispatches a stage whose retry backoff has elapsed, ignoring ticks
+// left over from an earlier attempt or a finished run.
+func (m *pipelineModel) handleStageRetry(msg pipelineStageRetryMsg) tea.Cmd {
+	if !m.runInProgress || msg.Stage < 0 || msg.Stage >= len(m.stages) {
+		return nil
+	}
+	stage := &m.stages[msg.Stage]
+	if stage.attempt != msg.Attempt || stage.status != pipelineStageStatusRunning {
+		return nil
+	}
+	return m.dispatchStage(msg.Stage)
+}
+
+// resetStageAttempts clears retry and fallback state before a new run.
+func (s *pipelineStage) resetStageAttempts() {
+	s.attempt = 0
+	s.retriesUsed = 0
+	s.usingFallback = false
+	s.attemptErrors = nil
+}
+
+// cycleStageRetries steps the selected stage's retry count from 0 up to the maximum and back.
+func (m *pipelineModel) cycleStageRetries() {
+	if m.selectedStage < 0 || m.selectedStage >= len(m.stages) {
+		return
+	}
+	stage := &m.stages[m.selectedStage]
+	stage.retries = (stage.retries + 1) % (appconfig.MaxPipelineStageRetries + 1)
+	m.statusBanner = fmt.Sprintf("Stage %d retries: %d", stage.index+1, stage.retries)
+}
+
+// defaultStageRetries returns the configured retry count for new stages.
+func (m *pipelineModel) defaultStageRetries() int {
+	if m.config == nil {
+		return 0
+	}
+	return m.config.StageRetryCount()
+}
+
+// stageRecoveryLabel summarizes a stage's retry count and fallback for the assignment view.
+func stageRecoveryLabel(stage pipelineStage) string {
+	label := ""
+	if stage.retries > 0 {
+		label = fmt.Sprintf("retries %d", stage.retries)
+	}
+	if stage.hasFallback() {
+		if label != "" {
+			label += ", "
+		}
+		label += fmt.Sprintf("fallback %s • %s", stage.fallbackHost.Name, stage.fallbackModel)
+	}
+	return label
+}
+
+// startFallbackPick opens the host picker to choose the selected stage's fallback.
+func (m *pipelineModel) startFallbackPick() tea.Cmd {
+	stage := &m.stages[m.selectedStage]
+	if !stage.hasAssignment {
+		m.statusBanner = "Assign the stage before choosing a fallback"
+		return nil
+	}
+	if len(m.config.Hosts) == 0 {
+		m.statusBanner = "No hosts configured"
+		return nil
+	}
+	m.assigningFallback = true
+	m.selectingHost = true
+	return fetchWarmStateCmd(m.ctx, m.provider, m.config.Hosts)
+}
+
+// openFallbackModels fills the model picker with the fallback host's models and records the
+// host on the selected stage. It returns false when the host has no models to pick from.
+func (m *pipelineModel) openFallbackModels(host Host) bool {
+	stage := &m.stages[m.selectedStage]
+	stage.fallbackHost = host
+	stage.fallbackModel = ""
+	modelItems := make([]list.Item, len(host.Models))
+	for i, model := range host.Models {
+		modelItems[i] = modelSelectorItem{name: model, loaded: m.warmState[host.Name].isLoaded(model), caps: m.warmState[host.Name].capabilitiesOf(model)}
+	}
+	m.modelList.SetItems(modelItems)
+	if len(modelItems) == 0 {
+		stage.fallbackHost = Host{}
+		m.assigningFallback = false
+		m.statusBanner = fmt.Sprintf("%s has no models to fall back to", host.Name)
+		return false
+	}
+	m.modelList.Select(0)
+	return true
+}
+
+// assignFallback completes the fallback assignment for the selected stage.
+func (m *pipelineModel) assignFallback(model string) {
+	stage := &m.stages[m.selectedStage]
+	stage.fallbackModel = model
+	m.selectingModel = false
+	m.assigningFallback = false
+	m.statusBanner = fmt.Sprintf("Stage %d falls back to %s • %s", stage.index+1, stage.fallbackHost.Name, model)
+}
+
+// clearFallback removes the selected stage's fallback assignment.
+func (m *pipelineModel) clearFallback() {
+	stage := &m.stages[m.selectedStage]
+	if !stage.hasFallback() {
+		return
+	}
+	stage.fallbackHost = Host{}
+	stage.fallbackModel = ""
+	m.statusBanner = fmt.Sprintf("Stage %d fallback cleared", stage.index+1)
+}
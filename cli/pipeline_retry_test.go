@@ -0,0 +1,78 @@
+// cli/pipeline_retry_test.go
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestStageRetryAndFallback verifies that a failing stage is retried up to its budget,
+// then switched to its fallback, and that the attempts are recorded for export.
+func TestStageRetryAndFallback(t *testing.T) {
+	stage := newPipelineStage(0)
+	stage.host = Host{Name: "hostA", URL: "http://a"}
+	stage.selectedModel = "llama3"
+	stage.hasAssignment = true
+	stage.retries = 2
+	stage.fallbackHost = Host{Name: "hostB", URL: "http://b"}
+	stage.fallbackModel = "qwen3"
+	m := &pipelineModel{
+		config:        &Config{},
+		stages:        []pipelineStage{stage},
+		stageInputs:   []string{"hello"},
+		runInProgress: true,
+		textArea:      textarea.New(),
+	}
+
+	flaky := fmt.Errorf("dial: %w", providers.ErrConnectionRefused)
+	for want := 1; want <= 2; want++ {
+		if cmd := m.handleStageError(pipelineStageErrorMsg{Stage: 0, Err: flaky}); cmd == nil {
+			t.Fatalf("retry %d should schedule a tick", want)
+		}
+		if got := m.stages[0].statusMessage; got != fmt.Sprintf("Retry %d/2", want) {
+			t.Fatalf("status chip = %q", got)
+		}
+		if m.handleStageRetry(pipelineStageRetryMsg{Stage: 0, Attempt: want - 1}) != nil {
+			t.Fatalf("a stale retry tick should be ignored")
+		}
+		if m.handleStageRetry(pipelineStageRetryMsg{Stage: 0, Attempt: want}) == nil {
+			t.Fatalf("retry %d was not dispatched", want)
+		}
+	}
+
+	if m.handleStageError(pipelineStageErrorMsg{Stage: 0, Err: flaky}) == nil {
+		t.Fatalf("expected the stage to fall back once retries are spent")
+	}
+	if host, model := m.stages[0].target(); host.Name != "hostB" || model != "qwen3" {
+		t.Fatalf("fallback target = %s/%s", host.Name, model)
+	}
+	if got := m.stages[0].statusMessage; got != "Fallback: hostB" {
+		t.Fatalf("status chip = %q", got)
+	}
+
+	record := m.buildExportRecord(0, &m.stages[0])
+	if record.Host != "hostB" || record.Retries != 2 || record.FallbackFrom != "hostA • llama3" || len(record.AttemptErrors) != 3 {
+		t.Fatalf("export record missing retry metadata: %+v", record)
+	}
+
+	missing := fmt.Errorf("pull first: %w", providers.ErrModelNotFound)
+	if m.handleStageError(pipelineStageErrorMsg{Stage: 0, Err: missing}) != nil {
+		t.Fatalf("a non-retryable error on the fallback should end the run")
+	}
+	if m.stages[0].status != pipelineStageStatusError || m.runInProgress {
+		t.Fatalf("run should stop with the stage in error: status=%v running=%v", m.stages[0].status, m.runInProgress)
+	}
+}
+
+// TestRetryBackoff verifies that the delay doubles per retry and is capped.
+func TestRetryBackoff(t *testing.T) {
+	if retryBackoff(1) != stageRetryBaseDelay || retryBackoff(3) != 4*stageRetryBaseDelay {
+		t.Fatalf("unexpected backoff: %s, %s", retryBackoff(1), retryBackoff(3))
+	}
+	if retryBackoff(10) != stageRetryMaxDelay {
+		t.Fatalf("backoff should cap at %s, got %s", stageRetryMaxDelay, retryBackoff(10))
+	}
+}
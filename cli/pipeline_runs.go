@@ -0,0 +1,136 @@
+// cli/pipeline_runs.go
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/util"
+)
+
+// pipelineRunFilePrefix names archived run files so they sort chronologically.
+const pipelineRunFilePrefix = "pipeline-"
+
+// pipelineRunFileTime formats a run's start time into its archive name. Nanoseconds keep
+// runs started within the same second from overwriting each other.
+const pipelineRunFileTime = "20060102-150405.000000000"
+
+// PipelineRunSummary describes an archived pipeline run for history listings.
+type PipelineRunSummary struct {
+	Path         string
+	RunStarted   string
+	RunCompleted string
+	Tag          string
+	Note         string
+	Models       []string
+	// started orders the listing, since RunStarted only has second resolution.
+	started time.Time
+}
+
+// applyRunAnnotation handles "/tag <text>" and "/note <text>" prompt commands, which
+// label the current run (or the next one, before it starts). An empty value clears
+// the field. It reports whether input was an annotation command.
+func (m *pipelineModel) applyRunAnnotation(input string) bool {
+	command, value, _ := strings.Cut(input, " ")
+	value = strings.TrimSpace(value)
+	switch command {
+	case "/tag":
+		m.runTag = value
+	case "/note":
+		m.runNote = value
+	default:
+		return false
+	}
+
+	label := strings.TrimPrefix(command, "/")
+	if value == "" {
+		m.statusBanner = fmt.Sprintf("Run %s cleared", label)
+	} else {
+		m.statusBanner = fmt.Sprintf("Run %s set: %s", label, value)
+	}
+	// Annotating after a run rewrites its exports so the label travels with the data.
+	if len(m.exportRecords) > 0 && !m.runInProgress {
+		m.autoExport()
+	}
+	return true
+}
+
+// runAnnotationLabel renders the tag and note for the progress line.
+func (m *pipelineModel) runAnnotationLabel() string {
+	var parts []string
+	if m.runTag != "" {
+		parts = append(parts, "["+m.runTag+"]")
+	}
+	if m.runNote != "" {
+		parts = append(parts, m.runNote)
+	}
+	return strings.Join(parts, " ")
+}
+
+// archivePipelineRun writes the latest run into dir under a name derived from its
+// start time, so re-annotating a run replaces its archive entry instead of adding one.
+func (m *pipelineModel) archivePipelineRun(dir string) (string, error) {
+	if len(m.exportRecords) == 0 {
+		return "", fmt.Errorf("no pipeline run to archive")
+	}
+	data, err := json.MarshalIndent(m.buildRunExport(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("unable to create pipeline history %s: %w", dir, err)
+	}
+	name := pipelineRunFilePrefix + m.runStarted.Format(pipelineRunFileTime) + ".json"
+	path := filepath.Join(dir, name)
+	if err := util.WriteFile(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ListPipelineRuns reads the archived runs in dir, newest first. Files that are not
+// pipeline run exports are skipped.
+func ListPipelineRuns(dir string) ([]PipelineRunSummary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pipeline history %s: %w", dir, err)
+	}
+
+	var runs []PipelineRunSummary
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read pipeline run %s: %w", path, err)
+		}
+		var export pipelineRunExport
+		if err := json.Unmarshal(data, &export); err != nil || export.RunStarted.IsZero() {
+			continue
+		}
+		summary := PipelineRunSummary{
+			Path:         path,
+			RunStarted:   export.RunStarted.Format("2006-01-02 15:04:05"),
+			RunCompleted: export.RunCompleted.Format("2006-01-02 15:04:05"),
+			Tag:          export.Tag,
+			Note:         export.Note,
+			started:      export.RunStarted,
+		}
+		for _, stage := range export.Stages {
+			summary.Models = append(summary.Models, stage.Model)
+		}
+		runs = append(runs, summary)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].started.After(runs[j].started)
+	})
+	return runs, nil
+}
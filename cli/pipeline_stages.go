@@ -0,0 +1,66 @@
+// cli/pipeline_stages.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// newPipelineStage returns an unassigned stage at the given position.
+func newPipelineStage(index int) pipelineStage {
+	return pipelineStage{
+		index:  index,
+		view:   pipelineStageViewOutput,
+		status: pipelineStageStatusUnassigned,
+		handoff: pipelineHandoff{
+			mode: pipelineHandoffRaw,
+		},
+	}
+}
+
+// insertStage adds an unassigned stage after the selected one and selects it. Stages are only
+// reshaped in the assignment view, before any output has been buffered.
+func (m *pipelineModel) insertStage() {
+	if len(m.stages) >= appconfig.MaxPipelineStages {
+		m.statusBanner = fmt.Sprintf("A pipeline holds at most %d stages", appconfig.MaxPipelineStages)
+		return
+	}
+	at := m.selectedStage + 1
+	if len(m.stages) == 0 {
+		at = 0
+	}
+	m.stages = append(m.stages, pipelineStage{})
+	copy(m.stages[at+1:], m.stages[at:])
+	m.stages[at] = newPipelineStage(at)
+	m.stages[at].retries = m.defaultStageRetries()
+	m.reindexStages()
+	m.selectedStage = at
+	m.statusBanner = fmt.Sprintf("Added stage %d (%d stages)", at+1, len(m.stages))
+}
+
+// removeStage drops the selected stage, keeping at least one.
+func (m *pipelineModel) removeStage() {
+	if len(m.stages) <= 1 {
+		m.statusBanner = "A pipeline needs at least one stage"
+		return
+	}
+	at := m.selectedStage
+	m.stages = append(m.stages[:at], m.stages[at+1:]...)
+	m.reindexStages()
+	if m.selectedStage >= len(m.stages) {
+		m.selectedStage = len(m.stages) - 1
+	}
+	if m.focusIndex >= len(m.stages) {
+		m.focusIndex = len(m.stages) - 1
+	}
+	m.statusBanner = fmt.Sprintf("Removed stage %d (%d stages)", at+1, len(m.stages))
+}
+
+// reindexStages renumbers stages after one is added or removed and resizes the stage inputs to match.
+func (m *pipelineModel) reindexStages() {
+	for i := range m.stages {
+		m.stages[i].index = i
+	}
+	m.stageInputs = make([]string, len(m.stages))
+}
@@ -0,0 +1,53 @@
+// cli/pipeline_stages_test.go
+package cli
+
+import (
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestPipelineStageCount verifies that the configured stage count is honoured and that
+// stages can be added and removed within bounds while keeping their indexes in order.
+func TestPipelineStageCount(t *testing.T) {
+	if got := (appconfig.Config{}).PipelineStageCount(); got != 4 {
+		t.Fatalf("default stage count = %d, want 4", got)
+	}
+	if got := (appconfig.Config{PipelineStages: 20}).PipelineStageCount(); got != appconfig.MaxPipelineStages {
+		t.Fatalf("stage count should clamp to %d, got %d", appconfig.MaxPipelineStages, got)
+	}
+
+	m := &pipelineModel{stages: []pipelineStage{newPipelineStage(0), newPipelineStage(1)}}
+	m.stages[1].selectedModel = "qwen3:4b"
+	m.insertStage()
+	if len(m.stages) != 3 || m.selectedStage != 1 || m.stages[2].selectedModel != "qwen3:4b" {
+		t.Fatalf("insert should add a stage after the selection: selected=%d stages=%+v", m.selectedStage, m.stages)
+	}
+	for i, stage := range m.stages {
+		if stage.index != i {
+			t.Fatalf("stage %d has index %d", i, stage.index)
+		}
+	}
+	if len(m.stageInputs) != 3 {
+		t.Fatalf("stage inputs should track the stage count, got %d", len(m.stageInputs))
+	}
+
+	m.selectedStage = 2
+	m.removeStage()
+	m.removeStage()
+	if len(m.stages) != 1 || m.selectedStage != 0 {
+		t.Fatalf("remove should keep the selection in range: selected=%d len=%d", m.selectedStage, len(m.stages))
+	}
+	m.removeStage()
+	if len(m.stages) != 1 || m.statusBanner != "A pipeline needs at least one stage" {
+		t.Fatalf("the last stage should not be removable, banner %q", m.statusBanner)
+	}
+
+	for len(m.stages) < appconfig.MaxPipelineStages {
+		m.insertStage()
+	}
+	m.insertStage()
+	if len(m.stages) != appconfig.MaxPipelineStages {
+		t.Fatalf("insert should stop at %d stages, got %d", appconfig.MaxPipelineStages, len(m.stages))
+	}
+}
@@ -11,6 +11,7 @@ import (
 type testProvider struct {
 	loadedModels map[string][]string
 	streamChunks []providers.ChatMessage
+	capabilities map[string]providers.Capabilities
 }
 
 // newTestProvider creates a new instance of testProvider.
@@ -46,5 +47,10 @@ func (p *testProvider) Stream(ctx context.Context, req providers.StreamRequest,
 	return nil
 }
 
+// ModelCapabilities returns the capabilities set for the model, or unknown ones.
+func (p *testProvider) ModelCapabilities(ctx context.Context, host Host, model string) (providers.Capabilities, error) {
+	return p.capabilities[model], nil
+}
+
 // Close is a no-op for the test provider.
 func (p *testProvider) Close() error { return nil }
@@ -0,0 +1,149 @@
+// cli/pull_progress.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/models"
+)
+
+// pullBarWidth is the width of each download progress bar, in cells.
+const pullBarWidth = 30
+
+// pullProgressMsg carries one status update from a running pull.
+type pullProgressMsg models.PullProgress
+
+// pullFinishedMsg reports that every pull has ended.
+type pullFinishedMsg struct{ err error }
+
+// pullRow is the latest state of one host's pull of one model.
+type pullRow struct {
+	progress models.PullProgress
+}
+
+// pullProgressView shows one progress bar per host and model while pulls run.
+type pullProgressView struct {
+	cancel   context.CancelFunc
+	order    []string
+	rows     map[string]*pullRow
+	finished bool
+	err      error
+}
+
+// newPullProgressView returns an empty view; rows appear as pulls report progress.
+func newPullProgressView(cancel context.CancelFunc) *pullProgressView {
+	return &pullProgressView{cancel: cancel, rows: make(map[string]*pullRow)}
+}
+
+// Init implements tea.Model.
+func (v *pullProgressView) Init() tea.Cmd {
+	return nil
+}
+
+// Update records progress and quits once every pull has finished; ctrl+c cancels them.
+func (v *pullProgressView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pullProgressMsg:
+		key := msg.Host + "\x00" + msg.Model
+		row, ok := v.rows[key]
+		if !ok {
+			row = &pullRow{}
+			v.rows[key] = row
+			v.order = append(v.order, key)
+		}
+		update := models.PullProgress(msg)
+		// Status lines without byte counts, such as "verifying sha256 digest", keep the last bar.
+		if update.Total == 0 && !update.Done {
+			update.Completed, update.Total = row.progress.Completed, row.progress.Total
+		}
+		row.progress = update
+	case pullFinishedMsg:
+		v.finished = true
+		v.err = msg.err
+		return v, tea.Quit
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			v.cancel()
+		}
+	}
+	return v, nil
+}
+
+// View renders a line per pull with its bar, byte counts and status.
+func (v *pullProgressView) View() string {
+	hostStyle := lipgloss.NewStyle().Bold(true)
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	var b strings.Builder
+	if len(v.order) == 0 {
+		b.WriteString(mutedStyle.Render("Waiting for hosts to start pulling…") + "\n")
+	}
+	for _, key := range v.order {
+		p := v.rows[key].progress
+		status := mutedStyle.Render(p.Status)
+		switch {
+		case p.Done && p.Err != nil:
+			status = errorStyle.Render(p.Err.Error())
+		case p.Done:
+			status = doneStyle.Render("✓ " + p.Status)
+		}
+		fmt.Fprintf(&b, "%s %s  %s %s  %s\n", hostStyle.Render(p.Host), p.Model, renderPullBar(p), formatPullBytes(p), status)
+	}
+	if v.finished {
+		if v.err != nil {
+			b.WriteString(errorStyle.Render("Some pulls failed.") + "\n")
+		} else {
+			b.WriteString(doneStyle.Render("All pulls finished and verified.") + "\n")
+		}
+	} else {
+		b.WriteString(mutedStyle.Render("ctrl+c to cancel") + "\n")
+	}
+	return b.String()
+}
+
+// renderPullBar draws the download bar; finished pulls show a full bar.
+func renderPullBar(p models.PullProgress) string {
+	filled := 0
+	switch {
+	case p.Done && p.Err == nil:
+		filled = pullBarWidth
+	case p.Total > 0:
+		filled = int(float64(p.Completed) / float64(p.Total) * pullBarWidth)
+	}
+	if filled > pullBarWidth {
+		filled = pullBarWidth
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", pullBarWidth-filled) + "]"
+}
+
+// formatPullBytes renders the downloaded and total size of the current layer.
+func formatPullBytes(p models.PullProgress) string {
+	if p.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f/%.1f MB", float64(p.Completed)/1e6, float64(p.Total)/1e6)
+}
+
+// StartPullProgress runs pull while showing a progress bar for every host and model it
+// reports, and returns pull's error. Pressing ctrl+c cancels the context pull receives.
+func StartPullProgress(pull func(ctx context.Context, progress func(models.PullProgress)) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	view := newPullProgressView(cancel)
+	p := tea.NewProgram(view)
+	go func() {
+		err := pull(ctx, func(update models.PullProgress) { p.Send(pullProgressMsg(update)) })
+		p.Send(pullFinishedMsg{err: err})
+	}()
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	return view.err
+}
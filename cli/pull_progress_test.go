@@ -0,0 +1,29 @@
+// cli/pull_progress_test.go
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestPullProgressView verifies rows follow each pull's progress, keep their bar across
+// status lines without byte counts, and report failures.
+func TestPullProgressView(t *testing.T) {
+	view := newPullProgressView(func() {})
+	view.Update(pullProgressMsg{Host: "HostA", Model: "tiny", Status: "pulling abc", Completed: 50, Total: 100})
+	view.Update(pullProgressMsg{Host: "HostA", Model: "tiny", Status: "verifying sha256 digest"})
+	view.Update(pullProgressMsg{Host: "HostB", Model: "tiny", Status: "failed", Done: true, Err: errors.New("no space left")})
+
+	if got := view.rows["HostA\x00tiny"].progress; got.Completed != 50 || got.Status != "verifying sha256 digest" {
+		t.Fatalf("unexpected row state: %+v", got)
+	}
+	out := view.View()
+	if !strings.Contains(out, "█"+strings.Repeat("░", pullBarWidth/2)) || !strings.Contains(out, "no space left") {
+		t.Fatalf("unexpected view:\n%s", out)
+	}
+
+	if _, cmd := view.Update(pullFinishedMsg{err: errors.New("1 failed")}); cmd == nil || !strings.Contains(view.View(), "Some pulls failed") {
+		t.Fatalf("expected the view to quit and report the failure")
+	}
+}
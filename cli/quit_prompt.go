@@ -0,0 +1,96 @@
+// cli/quit_prompt.go
+package cli
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quitChoice is how the user answered the quit prompt.
+type quitChoice int
+
+const (
+	// quitStay keeps the TUI open.
+	quitStay quitChoice = iota
+	// quitWait quits once the requests in flight have finished.
+	quitWait
+	// quitCancel cancels the requests in flight, saves what finished, then quits.
+	quitCancel
+	// quitForce quits at once, abandoning the requests in flight.
+	quitForce
+)
+
+// quitPrompt confirms quitting while requests are in flight.
+type quitPrompt struct {
+	open    bool
+	waiting bool
+}
+
+// request handles a quit key. It reports whether the TUI can quit straight away, which is
+// when nothing is in flight; otherwise it opens the prompt.
+func (q *quitPrompt) request(busy bool) bool {
+	if !busy {
+		return true
+	}
+	q.open = true
+	return false
+}
+
+// handleKey answers an open prompt: w waits, c cancels, f or a second ctrl+c forces, and
+// esc stays. It reports the choice and whether the key was consumed, which every key is
+// while the prompt is open.
+func (q *quitPrompt) handleKey(key string) (quitChoice, bool) {
+	if !q.open {
+		return quitStay, false
+	}
+	switch key {
+	case "w", "enter":
+		q.open = false
+		q.waiting = true
+		return quitWait, true
+	case "c":
+		q.open = false
+		return quitCancel, true
+	case "f", "ctrl+c":
+		q.open = false
+		return quitForce, true
+	case "esc", "n":
+		q.open = false
+		q.waiting = false
+	}
+	return quitStay, true
+}
+
+// settled reports whether a quit waiting on the requests in flight can go ahead.
+func (q quitPrompt) settled(busy bool) bool {
+	return q.waiting && !busy
+}
+
+// attach appends the prompt, or the notice of a pending quit, beneath view, trimming view
+// so the combined output fits height.
+func (q quitPrompt) attach(view string, width, height int) string {
+	if !q.open && !q.waiting {
+		return view
+	}
+	prompt := q.render(width)
+	available := height - lipgloss.Height(prompt)
+	lines := strings.Split(view, "\n")
+	if available > 0 && len(lines) > available {
+		lines = lines[:available]
+	}
+	return strings.Join(lines, "\n") + "\n" + prompt
+}
+
+// render draws the prompt or the pending-quit notice for the given width.
+func (q quitPrompt) render(width int) string {
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("214")).Padding(0, 1)
+	if width > 4 {
+		style = style.Width(width - 2)
+	}
+	if !q.open {
+		return style.Render("Quitting once the requests in flight finish (ctrl+c to choose again)")
+	}
+	return style.Render("Requests are still in flight. Quit anyway?\n" +
+		"w wait for them • c cancel and save what finished • f force quit • esc keep working")
+}
@@ -0,0 +1,82 @@
+// cli/quit_prompt_test.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestPipelineQuitPrompt verifies quitting mid-run asks first, that cancelling stops the
+// run and exports the finished stages marked as cancelled, and that waiting quits once
+// the run is over.
+func TestPipelineQuitPrompt(t *testing.T) {
+	isQuit := func(cmd tea.Cmd) bool {
+		if cmd == nil {
+			return false
+		}
+		_, ok := cmd().(tea.QuitMsg)
+		return ok
+	}
+	running := func(exportPath string) *pipelineModel {
+		m := initialPipelineModel(context.Background(), &Config{}, nil)
+		m.exportPath = exportPath
+		m.viewState = pipelineViewRunning
+		m.runInProgress = true
+		m.exportRecords = []pipelineExportRecord{{Stage: 1, Model: "llama3"}}
+		m.stages[0].status = pipelineStageStatusDone
+		m.stages[1].status = pipelineStageStatusRunning
+		return m
+	}
+	press := func(m *pipelineModel, key string) tea.Cmd {
+		keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "ctrl+c":
+			keyMsg = tea.KeyMsg{Type: tea.KeyCtrlC}
+		case "esc":
+			keyMsg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		_, cmd := m.Update(keyMsg)
+		return cmd
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "pipeline.json")
+	m := running(exportPath)
+	if cmd := press(m, "ctrl+c"); isQuit(cmd) || !m.quit.open {
+		t.Fatalf("expected ctrl+c mid-run to open the prompt")
+	}
+	if cmd := press(m, "c"); !isQuit(cmd) {
+		t.Fatalf("expected cancelling to quit")
+	}
+	if m.runInProgress || m.stages[1].statusMessage != "Cancelled" {
+		t.Fatalf("expected the run to be cancelled, got %+v", m.stages[1])
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("expected the finished stages to be exported: %v", err)
+	}
+	var export pipelineRunExport
+	if err := json.Unmarshal(data, &export); err != nil || !export.Cancelled || len(export.Stages) != 1 {
+		t.Fatalf("unexpected export: %s, %v", data, err)
+	}
+
+	m = running("")
+	press(m, "ctrl+c")
+	if cmd := press(m, "w"); isQuit(cmd) {
+		t.Fatalf("expected waiting not to quit while the run is in progress")
+	}
+	m.runInProgress = false
+	if _, cmd := m.Update(logPaneTickMsg{}); !isQuit(cmd) {
+		t.Fatalf("expected waiting to quit once the run finished")
+	}
+
+	m = running("")
+	press(m, "ctrl+c")
+	if cmd := press(m, "esc"); isQuit(cmd) || m.quit.open || !m.runInProgress {
+		t.Fatalf("expected esc to keep the run going")
+	}
+}
@@ -0,0 +1,142 @@
+// cli/tool_approval.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// toolApprovalMsg asks the TUI to approve a tool call. The answer is sent on reply, which
+// is buffered so answering never blocks; done closes when the caller stops waiting.
+type toolApprovalMsg struct {
+	call  providers.ToolApproval
+	reply chan<- bool
+	done  <-chan struct{}
+}
+
+// toolApprover returns a ToolApprover that asks through the TUI run by p and waits for
+// the user's answer. Without a program nothing can be asked, so calls are declined.
+func toolApprover(p *tea.Program) providers.ToolApprover {
+	return func(ctx context.Context, call providers.ToolApproval) (bool, error) {
+		if p == nil {
+			return false, nil
+		}
+		reply := make(chan bool, 1)
+		p.Send(toolApprovalMsg{call: call, reply: reply, done: ctx.Done()})
+		select {
+		case approved := <-reply:
+			return approved, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// approvalPrompt holds the tool calls waiting for approval and asks about them one at a
+// time, oldest first.
+type approvalPrompt struct {
+	pending []toolApprovalMsg
+}
+
+// add queues a call for approval.
+func (a *approvalPrompt) add(msg toolApprovalMsg) {
+	a.pending = append(a.pending, msg)
+}
+
+// open reports whether a call is waiting for an answer, dropping the calls whose
+// requests were cancelled meanwhile.
+func (a *approvalPrompt) open() bool {
+	for len(a.pending) > 0 {
+		select {
+		case <-a.pending[0].done:
+			a.pending = a.pending[1:]
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// handleKey answers the oldest call: y or enter approves it and n or esc declines it.
+// While a call is waiting every key but ctrl+c is consumed, so typing cannot approve
+// anything by accident; ctrl+c still reaches the quit prompt.
+func (a *approvalPrompt) handleKey(key string) bool {
+	if !a.open() || key == "ctrl+c" {
+		return false
+	}
+	switch key {
+	case "y", "enter":
+		a.answer(true)
+	case "n", "esc":
+		a.answer(false)
+	}
+	return true
+}
+
+// answer replies to the oldest call and removes it from the queue.
+func (a *approvalPrompt) answer(approved bool) {
+	a.pending[0].reply <- approved
+	a.pending = a.pending[1:]
+}
+
+// attach appends the prompt for the oldest call beneath view, trimming view so the
+// combined output fits height.
+func (a *approvalPrompt) attach(view string, width, height int) string {
+	if !a.open() {
+		return view
+	}
+	prompt := a.render(width)
+	available := height - lipgloss.Height(prompt)
+	lines := strings.Split(view, "\n")
+	if available > 0 && len(lines) > available {
+		lines = lines[:available]
+	}
+	return strings.Join(lines, "\n") + "\n" + prompt
+}
+
+// render draws the prompt for the oldest call for the given width.
+func (a approvalPrompt) render(width int) string {
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("214")).Padding(0, 1)
+	if width > 4 {
+		style = style.Width(width - 2)
+	}
+	call := a.pending[0].call
+	title := fmt.Sprintf("%s on %s wants to run %s:", call.Model, call.Host, call.Tool)
+	if waiting := len(a.pending) - 1; waiting > 0 {
+		title += fmt.Sprintf(" (%d more waiting)", waiting)
+	}
+	return style.Render(title + "\n" + describeToolCall(call) + "\n" + "y approve • n decline")
+}
+
+// describeToolCall renders a call's arguments for the prompt. run_command calls read as
+// the command line they run; other tools show their arguments as JSON.
+func describeToolCall(call providers.ToolApproval) string {
+	if command, ok := call.Arguments["command"].(string); ok && call.Tool == "run_command" {
+		words := []string{command}
+		if args, ok := call.Arguments["args"].([]any); ok {
+			for _, arg := range args {
+				words = append(words, quoteArgument(fmt.Sprint(arg)))
+			}
+		}
+		return "  $ " + strings.Join(words, " ")
+	}
+	data, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return fmt.Sprintf("  %v", call.Arguments)
+	}
+	return "  " + string(data)
+}
+
+// quoteArgument quotes an argument that would not read as one word.
+func quoteArgument(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`") {
+		return fmt.Sprintf("%q", arg)
+	}
+	return arg
+}
@@ -0,0 +1,51 @@
+// cli/tool_approval_test.go
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestApprovalPrompt verifies waiting tool calls are shown oldest first and answered by
+// y or n, that other keys are swallowed while one waits, and that cancelled calls drop out.
+func TestApprovalPrompt(t *testing.T) {
+	m := initialPipelineModel(context.Background(), &Config{}, nil)
+	m.width, m.height = 80, 24
+	ask := func(command string) (chan bool, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		reply := make(chan bool, 1)
+		call := providers.ToolApproval{Host: "gpu", Model: "llama", Tool: "run_command", Arguments: map[string]any{"command": command, "args": []any{"test", "./my pkg"}}}
+		m.Update(toolApprovalMsg{call: call, reply: reply, done: ctx.Done()})
+		return reply, cancel
+	}
+	press := func(key string) {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	first, _ := ask("go")
+	second, _ := ask("make")
+	stale, cancel := ask("rm")
+	if view := m.View(); !strings.Contains(view, `$ go test "./my pkg"`) || !strings.Contains(view, "2 more waiting") {
+		t.Fatalf("expected the oldest call in the prompt, got:\n%s", view)
+	}
+	press("x")
+	if len(first) != 0 || len(m.approval.pending) != 3 {
+		t.Fatalf("expected other keys to leave the call waiting")
+	}
+	press("y")
+	if !<-first {
+		t.Fatalf("expected y to approve the call")
+	}
+	press("n")
+	if <-second {
+		t.Fatalf("expected n to decline the call")
+	}
+	cancel()
+	if view := m.View(); strings.Contains(view, "wants to run") || len(stale) != 0 {
+		t.Fatalf("expected the cancelled call to drop out unanswered, got:\n%s", view)
+	}
+}
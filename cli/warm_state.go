@@ -0,0 +1,115 @@
+// cli/warm_state.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// warmStateTimeout bounds how long host pickers wait for loaded-model queries.
+const warmStateTimeout = 5 * time.Second
+
+// hostWarmState records which models a host currently holds in memory, how busy it is
+// and which features each of its models supports.
+type hostWarmState struct {
+	loaded       []string
+	err          error
+	load         providers.HostLoad
+	capabilities map[string]providers.Capabilities
+}
+
+// warmStateMsg reports the warm state of every configured host, keyed by host name.
+type warmStateMsg map[string]hostWarmState
+
+// fetchWarmStateCmd queries every host concurrently for its loaded models and the
+// capabilities of its configured models.
+func fetchWarmStateCmd(ctx context.Context, provider providers.ChatProvider, hosts []Host) tea.Cmd {
+	if provider == nil || len(hosts) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		queryCtx, cancel := context.WithTimeout(ctx, warmStateTimeout)
+		defer cancel()
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		states := make(warmStateMsg, len(hosts))
+		for _, host := range hosts {
+			wg.Add(1)
+			go func(host Host) {
+				defer wg.Done()
+				loaded, err := provider.LoadedModels(queryCtx, host)
+				load, _, _ := providers.ReportHostLoad(queryCtx, provider, host)
+				capabilities := make(map[string]providers.Capabilities, len(host.Models))
+				for _, model := range host.Models {
+					if caps, err := providers.ReportModelCapabilities(queryCtx, provider, host, model); err == nil {
+						capabilities[model] = caps
+					}
+				}
+				mu.Lock()
+				states[host.Name] = hostWarmState{loaded: loaded, err: err, load: load, capabilities: capabilities}
+				mu.Unlock()
+			}(host)
+		}
+		wg.Wait()
+		return states
+	}
+}
+
+// isLoaded reports whether the named model is currently loaded on the host.
+func (s hostWarmState) isLoaded(modelName string) bool {
+	for _, name := range s.loaded {
+		if name == modelName {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilitiesOf returns what the named model supports on the host; unknown when not probed.
+func (s hostWarmState) capabilitiesOf(modelName string) providers.Capabilities {
+	return s.capabilities[modelName]
+}
+
+// summary renders a compact description of the host's loaded models.
+func (s hostWarmState) summary() string {
+	switch {
+	case s.err != nil:
+		return "warm state unavailable"
+	case len(s.loaded) == 0:
+		return "cold (no models loaded)" + busyHint(s.load)
+	default:
+		return fmt.Sprintf("warm: %s", strings.Join(s.loaded, ", ")) + busyHint(s.load)
+	}
+}
+
+// describeHost combines a host URL with its warm state, when known.
+func describeHost(host Host, states warmStateMsg) string {
+	state, ok := states[host.Name]
+	if !ok {
+		return host.URL
+	}
+	return host.URL + " · " + state.summary()
+}
+
+// withBadges appends a model's capability badges to a picker description.
+func withBadges(desc string, caps providers.Capabilities) string {
+	if badges := caps.String(); badges != "" {
+		return desc + " · " + badges
+	}
+	return desc
+}
+
+// checkModelCapabilities checks a model against the modes cfg enables: MCP mode needs
+// tools, which blocks the model when missing, and JSON mode warns without constrained output.
+func checkModelCapabilities(cfg *Config, host Host, model string, caps providers.Capabilities) (warning string, err error) {
+	return providers.CheckCapabilities(caps, host.Name, model, cfg.JSONMode, cfg.MCPMode)
+}
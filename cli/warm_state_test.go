@@ -0,0 +1,65 @@
+// cli/warm_state_test.go
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestFetchWarmState verifies that every host is queried and described by its loaded models.
+func TestFetchWarmState(t *testing.T) {
+	provider := newTestProvider()
+	provider.loadedModels["gpu-a"] = []string{"llama3.2:3b"}
+	hosts := []Host{
+		{Name: "gpu-a", URL: "http://a:11434"},
+		{Name: "gpu-b", URL: "http://b:11434"},
+	}
+
+	msg := fetchWarmStateCmd(context.Background(), provider, hosts)()
+	states, ok := msg.(warmStateMsg)
+	if !ok {
+		t.Fatalf("expected warmStateMsg, got %T", msg)
+	}
+	if !states["gpu-a"].isLoaded("llama3.2:3b") {
+		t.Fatalf("expected llama3.2:3b to be warm on gpu-a: %+v", states["gpu-a"])
+	}
+	if got := describeHost(hosts[0], states); !strings.Contains(got, "warm: llama3.2:3b") {
+		t.Fatalf("unexpected gpu-a description %q", got)
+	}
+	if got := describeHost(hosts[1], states); !strings.Contains(got, "cold") {
+		t.Fatalf("unexpected gpu-b description %q", got)
+	}
+	if got := describeHost(Host{Name: "other", URL: "http://c"}, states); got != "http://c" {
+		t.Fatalf("unknown host should fall back to its URL, got %q", got)
+	}
+}
+
+// TestWarmStateCapabilities verifies that model capabilities are probed with the warm
+// state, shown as picker badges, and that MCP mode blocks a model without tools.
+func TestWarmStateCapabilities(t *testing.T) {
+	provider := newTestProvider()
+	provider.capabilities = map[string]providers.Capabilities{
+		"qwen3:4b": {Known: true, JSON: true, Tools: true},
+		"gemma:2b": {Known: true, JSON: true},
+	}
+	host := Host{Name: "gpu-a", URL: "http://a:11434", Models: []string{"qwen3:4b", "gemma:2b", "custom"}}
+	states := fetchWarmStateCmd(context.Background(), provider, []Host{host})().(warmStateMsg)
+
+	tools := states["gpu-a"].capabilitiesOf("qwen3:4b")
+	if got := (modelSelectorItem{name: "qwen3:4b", caps: tools}).Description(); got != "Select model · [json] [tools]" {
+		t.Fatalf("unexpected picker description %q", got)
+	}
+	cfg := &Config{MCPMode: true}
+	if _, err := checkModelCapabilities(cfg, host, "qwen3:4b", tools); err != nil {
+		t.Fatalf("expected qwen3:4b to pass in MCP mode: %v", err)
+	}
+	if _, err := checkModelCapabilities(cfg, host, "gemma:2b", states["gpu-a"].capabilitiesOf("gemma:2b")); err == nil {
+		t.Fatalf("expected gemma:2b to be blocked in MCP mode")
+	}
+	if _, err := checkModelCapabilities(cfg, host, "custom", states["gpu-a"].capabilitiesOf("custom")); err != nil {
+		t.Fatalf("expected a model with unknown capabilities to pass: %v", err)
+	}
+}
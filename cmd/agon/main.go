@@ -30,6 +30,9 @@ func main() {
 	if err := logging.Init(cfg.LogFilePath()); err != nil {
 		log.Fatalf("failed to initialize logging: %v", err)
 	}
+	if err := logging.Configure(cfg.LogOptions()); err != nil {
+		log.Fatalf("failed to configure logging: %v", err)
+	}
 	defer logging.Close()
 
 	// Always get the instance to ensure it's initialized
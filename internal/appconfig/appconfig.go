@@ -6,15 +6,51 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/tokencount"
 )
 
 const (
 	// DefaultConfigPath is the default path to the application's configuration file.
 	DefaultConfigPath = "config/config.json"
+	// DefaultAnalysisPath is where `agon analyze metrics` writes the analysis JSON by default.
+	DefaultAnalysisPath = "reports/data/metrics-analysis.json"
+	// DefaultUsageStatsPath is where opt-in usage stats are recorded when usageStatsPath is unset.
+	DefaultUsageStatsPath = "reports/data/usage-stats.jsonl"
+	// DefaultDataDir is the directory agon keeps its recorded data under by default.
+	DefaultDataDir = "agonData"
+	// DefaultSessionsDir is where chat sessions are recorded when sessionsDir is unset.
+	DefaultSessionsDir = DefaultDataDir + "/sessions"
+	// DefaultToolAuditDir is where MCP tool calls are recorded when toolAuditDir is unset.
+	DefaultToolAuditDir = DefaultDataDir + "/toolCalls"
+	// DefaultRawArchiveDir is where raw benchmark exchanges are archived when rawArchiveDir is unset.
+	DefaultRawArchiveDir = DefaultDataDir + "/raw"
+	// DefaultAnalysisHistoryDir is where each analysis run is kept when analysisHistoryDir is unset.
+	DefaultAnalysisHistoryDir = DefaultDataDir + "/analyses"
+	// DefaultSecretsPath is the encrypted secrets file ${secret:NAME} references read when
+	// secretsFile is unset.
+	DefaultSecretsPath = DefaultDataDir + "/secrets.enc"
+	// DefaultSecretsKeyPath is the key file for the secrets file when secretsKeyFile is unset.
+	DefaultSecretsKeyPath = DefaultDataDir + "/secrets.key"
+	// DefaultConfigReloadInterval is how often the TUIs check the config file for changes.
+	DefaultConfigReloadInterval = 2 * time.Second
+	// DefaultGeocodeCachePath is where the weather tool persists geocoding results when
+	// geocodeCachePath is unset.
+	DefaultGeocodeCachePath = DefaultDataDir + "/geocode-cache.json"
+	// DefaultGeocodeCacheTTL bounds how long the weather tool reuses a geocoding result.
+	DefaultGeocodeCacheTTL = 24 * time.Hour
+	// DefaultGeocodeCacheSize caps the number of locations held in the geocoding cache.
+	DefaultGeocodeCacheSize = 256
+	// DefaultJournalPath is the experiment journal `agon journal` writes when journalPath is unset.
+	DefaultJournalPath = DefaultDataDir + "/journal.md"
 	// legacyConfigPath is the path to the configuration file used in previous versions.
 	legacyConfigPath = "config.json"
 	// defaultRequestTimeout is the default timeout for HTTP requests.
@@ -23,37 +59,622 @@ const (
 	defaultMCPInitTimeout = 10 * time.Second
 	// defaultMCPRetryCount defines how many times MCP tools are retried when the config omits the value.
 	defaultMCPRetryCount = 1
+	// defaultMCPMaxFrameBytes caps the size of a single MCP JSON-RPC frame body.
+	defaultMCPMaxFrameBytes = 1 << 20
+	// defaultMCPMaxArgumentLength caps the rune length of string tool arguments.
+	defaultMCPMaxArgumentLength = 4096
+	// defaultMCPChunkBytes is the size above which tool results are delivered in chunks.
+	defaultMCPChunkBytes = 64 << 10
+	// defaultMCPMaxResultBytes caps how much of a chunked tool result the client fetches.
+	defaultMCPMaxResultBytes = 256 << 10
+	// defaultFileToolsMaxReadBytes caps how much of a file the read_file tool returns.
+	defaultFileToolsMaxReadBytes = 256 << 10
+	// defaultFileToolsMaxWriteBytes caps the content the write_file tool accepts.
+	defaultFileToolsMaxWriteBytes = 64 << 10
+	// defaultWebSearchResults is how many results web_search returns.
+	defaultWebSearchResults = 5
+	// defaultWebFetchBytes caps how much of a page fetch_url downloads.
+	defaultWebFetchBytes = 512 << 10
+	// defaultWebFetchTimeout bounds how long fetch_url waits for a page.
+	defaultWebFetchTimeout = 15 * time.Second
+	// defaultCommandTimeout bounds how long run_command lets a command run.
+	defaultCommandTimeout = 30 * time.Second
+	// defaultCommandOutputBytes caps the stdout and stderr run_command returns.
+	defaultCommandOutputBytes = 64 << 10
+	// defaultPipelineStages is the number of stages Pipeline mode starts with.
+	defaultPipelineStages = 4
+	// MaxPipelineStages caps how many stages a pipeline may hold.
+	MaxPipelineStages = 8
+	// MaxPipelineStageRetries caps how many times a failed pipeline stage is retried.
+	MaxPipelineStageRetries = 5
+	// MaxBenchmarkConcurrency caps how many benchmark requests run at once on one host.
+	MaxBenchmarkConcurrency = 16
 )
 
 // Config represents the top-level application configuration.
 type Config struct {
-	Hosts              []Host `json:"hosts"`
-	Debug              bool   `json:"debug"`
-	MultimodelMode     bool   `json:"multimodelMode"`
-	PipelineMode       bool   `json:"pipelineMode"`
-	JSONMode           bool   `json:"jsonMode"`
-	MCPMode            bool   `json:"mcpMode"`
-	MCPBinary          string `json:"mcpBinary,omitempty"`
-	MCPInitTimeout     int    `json:"mcpInitTimeout,omitempty"`
-	MCPRetryCount      int    `json:"mcpRetryCount,omitempty"`
-	TimeoutSeconds     int    `json:"timeout,omitempty"`
-	ExportPath         string `json:"export,omitempty"`
-	ExportMarkdownPath string `json:"exportMarkdown,omitempty"`
-	LogFile            string `json:"logFile,omitempty"`
-	BenchmarkMode      bool   `json:"benchmarkMode"`
-	BenchmarkCount     int    `json:"benchmarkCount"`
-	Metrics            bool   `json:"metrics"`
-	ConfigPath         string `json:"-"`
+	Hosts                []Host        `json:"hosts"`
+	Debug                bool          `json:"debug"`
+	MultimodelMode       bool          `json:"multimodelMode"`
+	PipelineMode         bool          `json:"pipelineMode"`
+	JSONMode             bool          `json:"jsonMode"`
+	MCPMode              bool          `json:"mcpMode"`
+	MCPBinary            string        `json:"mcpBinary,omitempty"`
+	MCPInitTimeout       int           `json:"mcpInitTimeout,omitempty"`
+	MCPRetryCount        int           `json:"mcpRetryCount,omitempty"`
+	MCPMaxFrameBytes     int           `json:"mcpMaxFrameBytes,omitempty"`
+	MCPMaxArgLength      int           `json:"mcpMaxArgLength,omitempty"`
+	MCPChunkBytes        int           `json:"mcpChunkBytes,omitempty"`
+	MCPMaxResultBytes    int           `json:"mcpMaxResultBytes,omitempty"`
+	MCPMock              bool          `json:"mcpMock,omitempty"`
+	MCPFixtures          string        `json:"mcpFixtures,omitempty"`
+	GeocodeCacheTTL      int           `json:"geocodeCacheTTL,omitempty"`
+	GeocodeCacheSize     int           `json:"geocodeCacheSize,omitempty"`
+	TimeoutSeconds       int           `json:"timeout,omitempty"`
+	ExportPath           string        `json:"export,omitempty"`
+	ExportMarkdownPath   string        `json:"exportMarkdown,omitempty"`
+	PipelineHistoryDir   string        `json:"pipelineHistoryDir,omitempty"`
+	PipelineStages       int           `json:"pipelineStages,omitempty"`
+	PipelineStageRetries int           `json:"pipelineStageRetries,omitempty"`
+	ComparisonExportPath string        `json:"comparisonExport,omitempty"`
+	SavedPrompts         []SavedPrompt `json:"savedPrompts,omitempty"`
+	LogFile              string        `json:"logFile,omitempty"`
+	AnalysisPath         string        `json:"analysisPath,omitempty"`
+	ScoringProfile       string        `json:"scoringProfile,omitempty"`
+	ReportLanguage       string        `json:"reportLanguage,omitempty"`
+	ReportMessages       string        `json:"reportMessages,omitempty"`
+	UsageStats           bool          `json:"usageStats,omitempty"`
+	UsageStatsPath       string        `json:"usageStatsPath,omitempty"`
+	SessionsDir          string        `json:"sessionsDir,omitempty"`
+	DisableSessions      bool          `json:"disableSessions,omitempty"`
+	BenchmarkMode        bool          `json:"benchmarkMode"`
+	BenchmarkCount       int           `json:"benchmarkCount"`
+	Metrics              bool          `json:"metrics"`
+	ConfigPath           string        `json:"-"`
+	// ResumeSession is the chat session `agon chat --resume` reopens.
+	ResumeSession string `json:"-"`
+	// Aliases maps a command alias to the agon arguments it expands to, e.g.
+	// "smoke": "benchmark --benchmarkCount 3".
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// PromptSuites lists JSONL prompt-suite files benchmark runs ask; empty uses the built-in prompt.
+	PromptSuites []string `json:"promptSuites,omitempty"`
+	// Judge is the model that grades prompt-suite answers using the judge grader.
+	Judge *JudgeConfig `json:"judge,omitempty"`
+	// HandoffGuard neutralizes instruction-like text in pipeline handoffs; nil disables it.
+	HandoffGuard *HandoffGuardConfig `json:"handoffGuard,omitempty"`
+	// SystemPromptVariants maps a benchmarked model, or "*" for every model, to system prompts
+	// each prompt-suite question is asked with, so prompt variants compare head-to-head.
+	SystemPromptVariants map[string][]SystemPromptVariant `json:"systemPromptVariants,omitempty"`
+	// MCPServers are external MCP servers whose tools MCP mode offers beside agon's own.
+	MCPServers []MCPServer `json:"mcpServers,omitempty"`
+	// ToolAuditDir is where MCP tool calls are recorded; DisableToolAudit turns recording off.
+	ToolAuditDir     string `json:"toolAuditDir,omitempty"`
+	DisableToolAudit bool   `json:"disableToolAudit,omitempty"`
+	// GeocodeCachePath is where the weather tool persists geocoding results;
+	// DisableGeocodeCachePersistence keeps them in memory only.
+	GeocodeCachePath               string `json:"geocodeCachePath,omitempty"`
+	DisableGeocodeCachePersistence bool   `json:"disableGeocodeCachePersistence,omitempty"`
+	// AnomalyAcknowledgements is a YAML or JSON file of known anomalies metric reports
+	// demote or suppress.
+	AnomalyAcknowledgements string `json:"anomalyAcknowledgements,omitempty"`
+	// SkipModeMenu prints help instead of the mode menu when agon runs without a command.
+	SkipModeMenu bool `json:"skipModeMenu,omitempty"`
+	// JSONStreamGuard aborts JSON-mode responses as soon as they cannot be valid JSON and
+	// asks again with a corrective nudge; nil leaves them to be validated once complete.
+	JSONStreamGuard *JSONStreamGuardConfig `json:"jsonStreamGuard,omitempty"`
+	// BenchmarkConcurrency is how many benchmark questions run at once on each host;
+	// a host's own BenchmarkConcurrency overrides it. Zero or one runs them one at a time.
+	BenchmarkConcurrency int `json:"benchmarkConcurrency,omitempty"`
+	// MCPToolExamples adds the example calls MCP tools advertise to the system prompt as
+	// few-shot hints, which helps small models get tool arguments right.
+	MCPToolExamples bool `json:"mcpToolExamples,omitempty"`
+	// BenchmarkWarmup is how many leading iterations per model are treated as warm-up and
+	// left out of the benchmark averages and variance.
+	BenchmarkWarmup int `json:"benchmarkWarmup,omitempty"`
+	// RawArchive archives each benchmark iteration's provider requests and streamed
+	// responses, compressed under RawArchiveDir and linked from the results by record ID.
+	RawArchive    bool   `json:"rawArchive,omitempty"`
+	RawArchiveDir string `json:"rawArchiveDir,omitempty"`
+	// ReportSections lists the HTML report sections to render, in order, and
+	// ReportSkipSections leaves sections out; both are overridden by their flags.
+	ReportSections     []string `json:"reportSections,omitempty"`
+	ReportSkipSections []string `json:"reportSkipSections,omitempty"`
+	// ParameterTemplates maps a benchmarked model, or "*" for every model, to named sets of
+	// sampling parameters each question is asked with, so they compare head-to-head.
+	ParameterTemplates map[string][]ParameterTemplate `json:"parameterTemplates,omitempty"`
+	// AnalysisHistoryDir keeps a copy of every analysis `agon analyze metrics` writes, for
+	// browsing with `agon report serve`; DisableAnalysisHistory turns the copies off.
+	AnalysisHistoryDir     string `json:"analysisHistoryDir,omitempty"`
+	DisableAnalysisHistory bool   `json:"disableAnalysisHistory,omitempty"`
+	// FileTools enables the MCP server's read_file, list_directory and write_file tools,
+	// confined to its allow-listed roots; nil leaves them disabled.
+	FileTools *FileToolsConfig `json:"fileTools,omitempty"`
+	// JournalPath is the Markdown experiment journal `agon journal` appends notes to.
+	JournalPath string `json:"journalPath,omitempty"`
+	// WebTools enables the MCP server's web_search and fetch_url tools; nil leaves them
+	// disabled.
+	WebTools *WebToolsConfig `json:"webTools,omitempty"`
+	// CommandTool enables the MCP server's run_command tool for the allow-listed commands;
+	// nil leaves it disabled.
+	CommandTool *CommandToolConfig `json:"commandTool,omitempty"`
+	// DisableConfigReload stops the TUIs from applying changes to the config file while
+	// they run.
+	DisableConfigReload bool `json:"disableConfigReload,omitempty"`
+	// SecretsFile is the AES-GCM encrypted file ${secret:NAME} references are read from,
+	// and SecretsKeyFile holds its key unless AGON_SECRETS_KEY is set.
+	SecretsFile    string `json:"secretsFile,omitempty"`
+	SecretsKeyFile string `json:"secretsKeyFile,omitempty"`
+	// MaxInFlight caps how many requests run at once on each host; a host's own
+	// MaxInFlight overrides it. Zero leaves hosts unlimited.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+	// MaxQueued caps how many requests wait for a slot on each host before new ones are
+	// refused; a host's own MaxQueued overrides it. Zero leaves the queue unbounded.
+	MaxQueued int `json:"maxQueued,omitempty"`
+	// Tokenizers maps model names, or prefixes of them, to the tokenizer family ("bpe" or
+	// "llama") used to estimate their token counts, for models whose family is not
+	// recognized from the name.
+	Tokenizers map[string]string `json:"tokenizers,omitempty"`
+	// ContextWindow decides what happens when a request nears the model's context window;
+	// nil warns at the default threshold.
+	ContextWindow *ContextWindowConfig `json:"contextWindow,omitempty"`
+	// LogLevel is the lowest level written to the log file: debug, info, warn or error.
+	// Empty means info, or debug when Debug is set.
+	LogLevel string `json:"logLevel,omitempty"`
+	// LogFormat is "text" (the default) or "json" for one JSON object per line.
+	LogFormat string `json:"logFormat,omitempty"`
+	// LogLevels overrides LogLevel for the provider, pipeline, mcp and metrics subsystems.
+	LogLevels map[string]string `json:"logLevels,omitempty"`
+	// SecondJudge grades every judged answer again so disagreements with Judge are recorded.
+	SecondJudge *JudgeConfig `json:"secondJudge,omitempty"`
+}
+
+// JudgeConfig names the host and model used for LLM-as-judge grading. The host is separate
+// from Hosts so the judge is never benchmarked itself.
+type JudgeConfig struct {
+	Host  Host   `json:"host"`
+	Model string `json:"model"`
+}
+
+// HandoffGuardConfig configures the pipeline handoff sanitizer.
+type HandoffGuardConfig struct {
+	// Patterns are extra regular expressions matching instruction-like text.
+	Patterns []string `json:"patterns,omitempty"`
+	// DisableDefaults drops the built-in patterns so only Patterns apply.
+	DisableDefaults bool `json:"disableDefaults,omitempty"`
+}
+
+// JSONStreamGuardConfig configures the JSON-mode stream guard.
+type JSONStreamGuardConfig struct {
+	// Retries is how many times a response that is not JSON is aborted and asked again.
+	Retries int `json:"retries,omitempty"`
+	// Nudge replaces the corrective instruction added to the system prompt on a retry.
+	Nudge string `json:"nudge,omitempty"`
+}
+
+// DefaultJSONNudge is added to the system prompt when a JSON-mode response is asked again.
+const DefaultJSONNudge = "Respond with a single valid JSON value only. Do not write any text or Markdown code fences before or after it."
+
+// RetryCount returns how many times the guard asks again, defaulting to one and clamped
+// to MaxPipelineStageRetries.
+func (g JSONStreamGuardConfig) RetryCount() int {
+	if g.Retries <= 0 {
+		return 1
+	}
+	if g.Retries > MaxPipelineStageRetries {
+		return MaxPipelineStageRetries
+	}
+	return g.Retries
+}
+
+// NudgePrompt returns the configured nudge, or DefaultJSONNudge.
+func (g JSONStreamGuardConfig) NudgePrompt() string {
+	if strings.TrimSpace(g.Nudge) == "" {
+		return DefaultJSONNudge
+	}
+	return g.Nudge
+}
+
+// Context window policies, applied when a request nears the model's context window.
+const (
+	// ContextPolicyWarn sends the request as it is and warns that the host may drop its start.
+	ContextPolicyWarn = "warn"
+	// ContextPolicyTruncate drops the oldest messages until the request fits.
+	ContextPolicyTruncate = "truncate"
+	// ContextPolicySummarize has the model summarize the earlier messages and sends the
+	// summary in their place.
+	ContextPolicySummarize = "summarize"
+	// ContextPolicyOff skips the check.
+	ContextPolicyOff = "off"
+)
+
+const (
+	// defaultContextThreshold is the share of the context window a request may fill.
+	defaultContextThreshold = 0.9
+	// defaultContextKeepRecent is how many of the latest messages are never dropped or summarized.
+	defaultContextKeepRecent = 4
+)
+
+// ContextWindowConfig configures the check made before each chat and pipeline request.
+type ContextWindowConfig struct {
+	// Policy is "warn" (the default), "truncate", "summarize" or "off".
+	Policy string `json:"policy,omitempty"`
+	// Threshold is the share of the context window, between 0 and 1, a request may fill
+	// before the policy applies.
+	Threshold float64 `json:"threshold,omitempty"`
+	// KeepRecent is how many of the latest messages truncate and summarize always keep.
+	KeepRecent int `json:"keepRecent,omitempty"`
+	// FallbackTokens is the context window assumed when the host does not report one;
+	// zero skips the check for such models.
+	FallbackTokens int `json:"fallbackTokens,omitempty"`
+}
+
+// PolicyName returns the configured policy, defaulting to ContextPolicyWarn.
+func (w ContextWindowConfig) PolicyName() string {
+	if policy := strings.ToLower(strings.TrimSpace(w.Policy)); policy != "" {
+		return policy
+	}
+	return ContextPolicyWarn
+}
+
+// ThresholdShare returns the share of the context window a request may fill.
+func (w ContextWindowConfig) ThresholdShare() float64 {
+	if w.Threshold <= 0 || w.Threshold > 1 {
+		return defaultContextThreshold
+	}
+	return w.Threshold
+}
+
+// KeepRecentMessages returns how many of the latest messages are always kept.
+func (w ContextWindowConfig) KeepRecentMessages() int {
+	if w.KeepRecent <= 0 {
+		return defaultContextKeepRecent
+	}
+	return w.KeepRecent
+}
+
+// validateContextWindow checks the context window policy.
+func validateContextWindow(window *ContextWindowConfig) error {
+	if window == nil {
+		return nil
+	}
+	switch window.PolicyName() {
+	case ContextPolicyWarn, ContextPolicyTruncate, ContextPolicySummarize, ContextPolicyOff:
+	default:
+		return fmt.Errorf("contextWindow.policy: unknown policy %q (use warn, truncate, summarize or off)", window.Policy)
+	}
+	if window.Threshold < 0 || window.Threshold > 1 {
+		return fmt.Errorf("contextWindow.threshold: %v is not between 0 and 1", window.Threshold)
+	}
+	return nil
+}
+
+// validateLogging checks the log level, format and per-subsystem levels.
+func validateLogging(c Config) error {
+	if strings.TrimSpace(c.LogLevel) != "" {
+		if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+			return fmt.Errorf("logLevel: %w", err)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.LogFormat)) {
+	case "", logging.FormatText, logging.FormatJSON:
+	default:
+		return fmt.Errorf("logFormat: unknown format %q (use text or json)", c.LogFormat)
+	}
+	for subsystem, level := range c.LogLevels {
+		known := false
+		for _, name := range logging.Subsystems {
+			known = known || name == subsystem
+		}
+		if !known {
+			return fmt.Errorf("logLevels: unknown subsystem %q (use %s)", subsystem, strings.Join(logging.Subsystems, ", "))
+		}
+		if _, err := logging.ParseLevel(level); err != nil {
+			return fmt.Errorf("logLevels[%q]: %w", subsystem, err)
+		}
+	}
+	return nil
+}
+
+// SystemPromptVariant is a named system prompt benchmark runs evaluate.
+type SystemPromptVariant struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// ParameterTemplate is a named set of sampling parameters benchmark runs evaluate.
+type ParameterTemplate struct {
+	Name       string     `json:"name"`
+	Parameters Parameters `json:"parameters"`
+}
+
+// MCPServer is an external MCP server, started as a stdio command or reached over HTTP.
+type MCPServer struct {
+	// Name namespaces the server's tools, e.g. "github" exposes "github__create_issue".
+	Name string `json:"name"`
+	// Command, Args and Env start a stdio server.
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	// URL reaches a Streamable HTTP server instead, sending Headers with every request.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// mcpServerName restricts server names to characters tool-calling APIs accept in tool names.
+var mcpServerName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateMCPServers checks that every external MCP server has a unique name and exactly
+// one of a command or a URL.
+func validateMCPServers(servers []MCPServer) error {
+	seen := make(map[string]bool)
+	for i, server := range servers {
+		if !mcpServerName.MatchString(server.Name) {
+			return fmt.Errorf("mcpServers[%d]: name %q must be letters, digits, '-' or '_'", i, server.Name)
+		}
+		if seen[server.Name] {
+			return fmt.Errorf("mcpServers[%d]: duplicate name %q", i, server.Name)
+		}
+		seen[server.Name] = true
+		hasCommand := strings.TrimSpace(server.Command) != ""
+		hasURL := strings.TrimSpace(server.URL) != ""
+		if hasCommand == hasURL {
+			return fmt.Errorf("mcpServers[%d] (%s): set either command or url", i, server.Name)
+		}
+		if hasURL {
+			if parsed, err := url.Parse(server.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				return fmt.Errorf("mcpServers[%d] (%s): url %q must be an http or https URL", i, server.Name, server.URL)
+			}
+		}
+	}
+	return nil
+}
+
+// FileToolsConfig configures the MCP server's file-system tools. Each tool is enabled on
+// its own, and every path it touches must resolve, after following symlinks, inside Roots.
+type FileToolsConfig struct {
+	// Roots are the directories the tools may access.
+	Roots         []string `json:"roots"`
+	ReadFile      bool     `json:"readFile,omitempty"`
+	ListDirectory bool     `json:"listDirectory,omitempty"`
+	WriteFile     bool     `json:"writeFile,omitempty"`
+	// MaxReadBytes caps how much of a file read_file returns; MaxWriteBytes caps the
+	// content write_file accepts.
+	MaxReadBytes  int `json:"maxReadBytes,omitempty"`
+	MaxWriteBytes int `json:"maxWriteBytes,omitempty"`
+}
+
+// ReadLimit returns the most bytes read_file returns from one file.
+func (f FileToolsConfig) ReadLimit() int {
+	if f.MaxReadBytes <= 0 {
+		return defaultFileToolsMaxReadBytes
+	}
+	return f.MaxReadBytes
+}
+
+// WriteLimit returns the most bytes write_file writes in one call.
+func (f FileToolsConfig) WriteLimit() int {
+	if f.MaxWriteBytes <= 0 {
+		return defaultFileToolsMaxWriteBytes
+	}
+	return f.MaxWriteBytes
+}
+
+// WebToolsConfig configures the MCP server's web tools. Search names the web_search
+// backend, "searxng" or "brave"; Fetch enables fetch_url.
+type WebToolsConfig struct {
+	Search string `json:"search,omitempty"`
+	// SearchURL is the SearxNG instance, or overrides the Brave Search API endpoint.
+	SearchURL string `json:"searchURL,omitempty"`
+	// APIKey is the Brave Search subscription token; BRAVE_API_KEY is used when empty.
+	APIKey string `json:"apiKey,omitempty"`
+	// MaxResults caps the results web_search returns.
+	MaxResults int  `json:"maxResults,omitempty"`
+	Fetch      bool `json:"fetch,omitempty"`
+	// MaxFetchBytes caps how much of a page fetch_url downloads; FetchTimeout is in seconds.
+	MaxFetchBytes int `json:"maxFetchBytes,omitempty"`
+	FetchTimeout  int `json:"fetchTimeout,omitempty"`
+	// AllowPrivateNetworks lets fetch_url reach loopback, private and link-local addresses.
+	AllowPrivateNetworks bool `json:"allowPrivateNetworks,omitempty"`
+}
+
+// ResultLimit returns how many results web_search returns.
+func (w WebToolsConfig) ResultLimit() int {
+	if w.MaxResults <= 0 {
+		return defaultWebSearchResults
+	}
+	return w.MaxResults
+}
+
+// FetchLimit returns the most bytes fetch_url downloads from one page.
+func (w WebToolsConfig) FetchLimit() int {
+	if w.MaxFetchBytes <= 0 {
+		return defaultWebFetchBytes
+	}
+	return w.MaxFetchBytes
+}
+
+// FetchTimeoutDuration returns how long fetch_url waits for a page.
+func (w WebToolsConfig) FetchTimeoutDuration() time.Duration {
+	if w.FetchTimeout <= 0 {
+		return defaultWebFetchTimeout
+	}
+	return time.Duration(w.FetchTimeout) * time.Second
+}
+
+// validateWebTools checks the search backend is known and SearxNG has an instance to query.
+func validateWebTools(web *WebToolsConfig) error {
+	if web == nil {
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(web.Search)) {
+	case "", "brave":
+	case "searxng":
+		if strings.TrimSpace(web.SearchURL) == "" {
+			return errors.New("webTools: the searxng backend needs a searchURL")
+		}
+	default:
+		return fmt.Errorf("webTools.search: unknown backend %q (use searxng or brave)", web.Search)
+	}
+	return nil
+}
+
+// CommandToolConfig configures the MCP server's run_command tool. Only the listed commands
+// run, without a shell, and agon asks the user to approve every call before it is made.
+type CommandToolConfig struct {
+	Commands []AllowedCommand `json:"commands"`
+	// Dir is the working directory commands run in; empty uses the server's.
+	Dir string `json:"dir,omitempty"`
+	// MaxOutputBytes caps the stdout and the stderr returned for a call.
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty"`
+}
+
+// AllowedCommand is a command run_command may execute.
+type AllowedCommand struct {
+	// Name is the command the model asks for.
+	Name string `json:"name"`
+	// Path is the executable; it defaults to Name, looked up in PATH.
+	Path string `json:"path,omitempty"`
+	// Args are regular expressions; every argument must match one of them in full. A
+	// command without patterns takes no arguments.
+	Args []string `json:"args,omitempty"`
+	// Timeout is in seconds.
+	Timeout int `json:"timeout,omitempty"`
+	// Description tells the model what the command is for.
+	Description string `json:"description,omitempty"`
+}
+
+// TimeoutDuration returns how long the command may run.
+func (a AllowedCommand) TimeoutDuration() time.Duration {
+	if a.Timeout <= 0 {
+		return defaultCommandTimeout
+	}
+	return time.Duration(a.Timeout) * time.Second
+}
+
+// OutputLimit returns the most bytes of stdout, and of stderr, run_command returns.
+func (c CommandToolConfig) OutputLimit() int {
+	if c.MaxOutputBytes <= 0 {
+		return defaultCommandOutputBytes
+	}
+	return c.MaxOutputBytes
+}
+
+// LongestTimeout returns the longest time any allowed command may run.
+func (c CommandToolConfig) LongestTimeout() time.Duration {
+	var longest time.Duration
+	for _, command := range c.Commands {
+		longest = max(longest, command.TimeoutDuration())
+	}
+	return longest
+}
+
+// validateCommandTool checks every allowed command is named once and its argument
+// patterns compile.
+func validateCommandTool(tool *CommandToolConfig) error {
+	if tool == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(tool.Commands))
+	for i, command := range tool.Commands {
+		name := strings.TrimSpace(command.Name)
+		if name == "" {
+			return fmt.Errorf("commandTool.commands[%d]: name must not be empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("commandTool.commands[%d]: %q is listed twice", i, name)
+		}
+		seen[name] = true
+		for _, pattern := range command.Args {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("commandTool.commands[%d]: argument pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateFileTools checks that enabled file tools have at least one root to work in.
+func validateFileTools(files *FileToolsConfig) error {
+	if files == nil || !(files.ReadFile || files.ListDirectory || files.WriteFile) {
+		return nil
+	}
+	if len(files.Roots) == 0 {
+		return errors.New("fileTools: set at least one root to enable the file tools")
+	}
+	for i, root := range files.Roots {
+		if strings.TrimSpace(root) == "" {
+			return fmt.Errorf("fileTools.roots[%d]: root must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// SavedPrompt is a named prompt from the prompt library that can be fired with one keystroke.
+type SavedPrompt struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
 }
 
 // Host represents a single host that can serve language models.
 type Host struct {
-	Name         string     `json:"name"`
-	URL          string     `json:"url"`
-	Type         string     `json:"type"`
-	Models       []string   `json:"models"`
-	SystemPrompt string     `json:"systemprompt"`
-	Parameters   Parameters `json:"parameters"`
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Type         string            `json:"type"`
+	Models       []string          `json:"models"`
+	SystemPrompt string            `json:"systemprompt"`
+	Parameters   Parameters        `json:"parameters"`
+	ModelAliases map[string]string `json:"modelAliases,omitempty"`
+	// Headers are added to every HTTP request sent to the host, e.g. auth for a reverse proxy.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Proxy is an HTTP(S) proxy URL requests to the host are sent through.
+	Proxy string `json:"proxy,omitempty"`
+	// ModelsDiscovered is set when Models was populated from the host at runtime.
+	ModelsDiscovered bool `json:"-"`
+	// BenchmarkConcurrency overrides the top-level BenchmarkConcurrency for this host.
+	BenchmarkConcurrency int `json:"benchmarkConcurrency,omitempty"`
+	// MaxInFlight and MaxQueued override the top-level limits for this host.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+	MaxQueued   int `json:"maxQueued,omitempty"`
+}
+
+// ResolveModel maps a configured model name to the backend identifier using the host's aliases.
+func (h Host) ResolveModel(name string) string {
+	if backend, ok := h.ModelAliases[name]; ok && strings.TrimSpace(backend) != "" {
+		return backend
+	}
+	return name
+}
+
+// FriendlyModel maps a backend model identifier back to its configured alias, if one exists.
+func (h Host) FriendlyModel(backend string) string {
+	for friendly, id := range h.ModelAliases {
+		if id == backend {
+			return friendly
+		}
+	}
+	return backend
+}
+
+// BackendModels returns the host's configured models resolved to backend identifiers.
+func (h Host) BackendModels() []string {
+	resolved := make([]string, len(h.Models))
+	for i, name := range h.Models {
+		resolved[i] = h.ResolveModel(name)
+	}
+	return resolved
+}
+
+// ProxyURL parses the host's proxy setting, returning nil when none is configured.
+func (h Host) ProxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(h.Proxy)
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("host %q: invalid proxy URL %q", h.Name, h.Proxy)
+	}
+	return u, nil
 }
 
 // Parameters defines the set of parameters that can be used to control a language model's behavior.
@@ -68,6 +689,7 @@ type Parameters struct {
 	RepeatPenalty    *float64 `json:"repeat_penalty,omitempty"`
 	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
 	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
 }
 
 // RequestTimeout returns the timeout duration for HTTP requests, falling back to the default if not specified.
@@ -97,6 +719,171 @@ func (c Config) MCPRetryAttempts() int {
 	return c.MCPRetryCount
 }
 
+// MCPFrameLimit returns the maximum accepted MCP frame body size in bytes.
+func (c Config) MCPFrameLimit() int {
+	if c.MCPMaxFrameBytes <= 0 {
+		return defaultMCPMaxFrameBytes
+	}
+	return c.MCPMaxFrameBytes
+}
+
+// MCPArgumentLimit returns the maximum rune length allowed for string tool arguments.
+func (c Config) MCPArgumentLimit() int {
+	if c.MCPMaxArgLength <= 0 {
+		return defaultMCPMaxArgumentLength
+	}
+	return c.MCPMaxArgLength
+}
+
+// MCPChunkSize returns the byte size above which the MCP server delivers a tool result
+// as a resource, which is also the largest chunk a single resources/read returns.
+func (c Config) MCPChunkSize() int {
+	if c.MCPChunkBytes <= 0 {
+		return defaultMCPChunkBytes
+	}
+	return c.MCPChunkBytes
+}
+
+// MCPResultLimit returns the maximum number of bytes fetched from a chunked tool result.
+func (c Config) MCPResultLimit() int {
+	if c.MCPMaxResultBytes <= 0 {
+		return defaultMCPMaxResultBytes
+	}
+	return c.MCPMaxResultBytes
+}
+
+// PipelineStageCount returns the number of stages Pipeline mode starts with, clamped to MaxPipelineStages.
+func (c Config) PipelineStageCount() int {
+	if c.PipelineStages <= 0 {
+		return defaultPipelineStages
+	}
+	if c.PipelineStages > MaxPipelineStages {
+		return MaxPipelineStages
+	}
+	return c.PipelineStages
+}
+
+// StageRetryCount returns how many times a failed pipeline stage is retried by default,
+// clamped to MaxPipelineStageRetries.
+func (c Config) StageRetryCount() int {
+	if c.PipelineStageRetries <= 0 {
+		return 0
+	}
+	if c.PipelineStageRetries > MaxPipelineStageRetries {
+		return MaxPipelineStageRetries
+	}
+	return c.PipelineStageRetries
+}
+
+// BenchmarkConcurrencyFor returns how many benchmark questions run at once on host,
+// preferring the host's own setting, defaulting to one and clamped to MaxBenchmarkConcurrency.
+func (c Config) BenchmarkConcurrencyFor(host Host) int {
+	n := c.BenchmarkConcurrency
+	if host.BenchmarkConcurrency > 0 {
+		n = host.BenchmarkConcurrency
+	}
+	if n <= 0 {
+		return 1
+	}
+	if n > MaxBenchmarkConcurrency {
+		return MaxBenchmarkConcurrency
+	}
+	return n
+}
+
+// HostLimits returns how many requests may run at once on host and how many more may wait
+// for a slot, preferring the host's own limits. Zero means unlimited for either.
+func (c Config) HostLimits(host Host) (inFlight, queued int) {
+	inFlight, queued = c.MaxInFlight, c.MaxQueued
+	if host.MaxInFlight > 0 {
+		inFlight = host.MaxInFlight
+	}
+	if host.MaxQueued > 0 {
+		queued = host.MaxQueued
+	}
+	return max(inFlight, 0), max(queued, 0)
+}
+
+// ContextWindowSettings returns the context window policy, or the defaults when unset.
+func (c Config) ContextWindowSettings() ContextWindowConfig {
+	if c.ContextWindow == nil {
+		return ContextWindowConfig{}
+	}
+	return *c.ContextWindow
+}
+
+// TokenizerFor returns the tokenizer that estimates token counts for model.
+func (c Config) TokenizerFor(model string) tokencount.Tokenizer {
+	return tokencount.ForModel(model, c.Tokenizers)
+}
+
+// LogOptions returns the logging settings: the configured level, or debug when Debug is
+// set and no level is, the format, and the per-subsystem levels. Invalid names were
+// rejected when the config was read.
+func (c Config) LogOptions() logging.Options {
+	opts := logging.Options{Level: logging.LevelInfo, Format: logging.FormatText}
+	if c.Debug {
+		opts.Level = logging.LevelDebug
+	}
+	if level, err := logging.ParseLevel(c.LogLevel); err == nil && strings.TrimSpace(c.LogLevel) != "" {
+		opts.Level = level
+	}
+	if strings.EqualFold(strings.TrimSpace(c.LogFormat), logging.FormatJSON) {
+		opts.Format = logging.FormatJSON
+	}
+	for subsystem, name := range c.LogLevels {
+		level, err := logging.ParseLevel(name)
+		if err != nil {
+			continue
+		}
+		if opts.Subsystems == nil {
+			opts.Subsystems = make(map[string]logging.Level, len(c.LogLevels))
+		}
+		opts.Subsystems[subsystem] = level
+	}
+	return opts
+}
+
+// MCPFixturesDir returns the directory holding mock tool fixtures, applying a default if not set.
+func (c Config) MCPFixturesDir() string {
+	if dir := strings.TrimSpace(c.MCPFixtures); dir != "" {
+		return dir
+	}
+	return "mcp/fixtures"
+}
+
+// GeocodeCacheTTLDuration returns how long geocoding results are cached. A negative
+// value in the config disables the cache.
+func (c Config) GeocodeCacheTTLDuration() time.Duration {
+	if c.GeocodeCacheTTL < 0 {
+		return 0
+	}
+	if c.GeocodeCacheTTL == 0 {
+		return DefaultGeocodeCacheTTL
+	}
+	return time.Duration(c.GeocodeCacheTTL) * time.Second
+}
+
+// GeocodeCacheCapacity returns the maximum number of cached geocoding results.
+func (c Config) GeocodeCacheCapacity() int {
+	if c.GeocodeCacheSize <= 0 {
+		return DefaultGeocodeCacheSize
+	}
+	return c.GeocodeCacheSize
+}
+
+// GeocodeCacheFile returns the file the geocoding cache is persisted to, applying a default
+// under the data directory if not set. It returns "" when persistence is disabled.
+func (c Config) GeocodeCacheFile() string {
+	if c.DisableGeocodeCachePersistence {
+		return ""
+	}
+	if path := strings.TrimSpace(c.GeocodeCachePath); path != "" {
+		return path
+	}
+	return DefaultGeocodeCachePath
+}
+
 // LogFilePath returns the path to the application log file, applying a default if not set.
 func (c Config) LogFilePath() string {
 	if path := c.LogFile; strings.TrimSpace(path) != "" {
@@ -105,6 +892,78 @@ func (c Config) LogFilePath() string {
 	return "agon.log"
 }
 
+// AnalysisFilePath returns the metrics analysis used for pipeline auto-assignment, applying a default if not set.
+func (c Config) AnalysisFilePath() string {
+	if path := strings.TrimSpace(c.AnalysisPath); path != "" {
+		return path
+	}
+	return DefaultAnalysisPath
+}
+
+// UsageStatsFile returns the local file opt-in usage stats are recorded to, applying a default if not set.
+func (c Config) UsageStatsFile() string {
+	if path := strings.TrimSpace(c.UsageStatsPath); path != "" {
+		return path
+	}
+	return DefaultUsageStatsPath
+}
+
+// SessionsPath returns the directory chat sessions are recorded in, or "" when session
+// recording is disabled.
+func (c Config) SessionsPath() string {
+	if c.DisableSessions {
+		return ""
+	}
+	if dir := strings.TrimSpace(c.SessionsDir); dir != "" {
+		return dir
+	}
+	return DefaultSessionsDir
+}
+
+// ToolAuditPath returns the directory MCP tool calls are recorded to, or "" when the
+// audit log is disabled.
+func (c Config) ToolAuditPath() string {
+	if c.DisableToolAudit {
+		return ""
+	}
+	if dir := strings.TrimSpace(c.ToolAuditDir); dir != "" {
+		return dir
+	}
+	return DefaultToolAuditDir
+}
+
+// RawArchivePath returns the directory raw benchmark exchanges are archived to, or ""
+// when archiving is off.
+func (c Config) RawArchivePath() string {
+	if !c.RawArchive {
+		return ""
+	}
+	if dir := strings.TrimSpace(c.RawArchiveDir); dir != "" {
+		return dir
+	}
+	return DefaultRawArchiveDir
+}
+
+// AnalysisHistoryPath returns the directory analysis runs are kept in, or "" when the
+// history is disabled.
+func (c Config) AnalysisHistoryPath() string {
+	if c.DisableAnalysisHistory {
+		return ""
+	}
+	if dir := strings.TrimSpace(c.AnalysisHistoryDir); dir != "" {
+		return dir
+	}
+	return DefaultAnalysisHistoryDir
+}
+
+// JournalFile returns the experiment journal's path, defaulting to DefaultJournalPath.
+func (c Config) JournalFile() string {
+	if path := strings.TrimSpace(c.JournalPath); path != "" {
+		return path
+	}
+	return DefaultJournalPath
+}
+
 // MCPBinaryPath returns the resolved MCP server binary path, choosing a default based on the OS if not provided.
 func (c Config) MCPBinaryPath() string {
 	if b := strings.TrimSpace(c.MCPBinary); b != "" {
@@ -121,6 +980,26 @@ func (c Config) MCPBinaryPath() string {
 	}
 }
 
+// SystemPromptVariantsFor returns the system prompt variants model is benchmarked with:
+// its own followed by the ones configured for every model.
+func (c Config) SystemPromptVariantsFor(model string) []SystemPromptVariant {
+	variants := append([]SystemPromptVariant(nil), c.SystemPromptVariants[model]...)
+	if model != "*" {
+		variants = append(variants, c.SystemPromptVariants["*"]...)
+	}
+	return variants
+}
+
+// ParameterTemplatesFor returns the parameter templates model is benchmarked with: its own
+// followed by the ones configured for every model.
+func (c Config) ParameterTemplatesFor(model string) []ParameterTemplate {
+	templates := append([]ParameterTemplate(nil), c.ParameterTemplates[model]...)
+	if model != "*" {
+		templates = append(templates, c.ParameterTemplates["*"]...)
+	}
+	return templates
+}
+
 // Load reads the application configuration from the specified path, with fallback to a legacy path.
 func Load(path string) (Config, error) {
 	if path == "" {
@@ -160,14 +1039,56 @@ func loadFromPath(path string) (Config, error) {
 		return Config{}, err
 	}
 	defer file.Close()
+	return decode(file)
+}
 
+// decode reads a configuration from r, applying defaults and validating it.
+func decode(r io.Reader) (Config, error) {
 	var config Config
-	if err := json.NewDecoder(file).Decode(&config); err != nil {
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return Config{}, err
+	}
+	if err := ResolveSecrets(&config); err != nil {
 		return Config{}, err
 	}
 	if config.TimeoutSeconds <= 0 {
 		config.TimeoutSeconds = int(defaultRequestTimeout.Seconds())
 	}
+	for _, host := range config.Hosts {
+		if _, err := host.ProxyURL(); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := validateMCPServers(config.MCPServers); err != nil {
+		return Config{}, err
+	}
+	if err := validateCommandTool(config.CommandTool); err != nil {
+		return Config{}, err
+	}
+	if err := validateWebTools(config.WebTools); err != nil {
+		return Config{}, err
+	}
+	if err := validateFileTools(config.FileTools); err != nil {
+		return Config{}, err
+	}
+	if err := validateContextWindow(config.ContextWindow); err != nil {
+		return Config{}, err
+	}
+	if err := validateLogging(config); err != nil {
+		return Config{}, err
+	}
+	for model, family := range config.Tokenizers {
+		if _, err := tokencount.ParseFamily(family); err != nil {
+			return Config{}, fmt.Errorf("tokenizers[%q]: %w", model, err)
+		}
+	}
+	if config.HandoffGuard != nil {
+		for _, pattern := range config.HandoffGuard.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return Config{}, fmt.Errorf("invalid handoffGuard pattern %q: %w", pattern, err)
+			}
+		}
+	}
 
 	return config, nil
 }
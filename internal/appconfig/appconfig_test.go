@@ -3,8 +3,11 @@ package appconfig
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mwiater/agon/internal/logging"
 )
 
 // TestLoad tests the Load function to ensure it correctly handles various
@@ -96,3 +99,161 @@ func TestLoad(t *testing.T) {
 		t.Fatal("Load() with nonexistent file should have failed")
 	}
 }
+
+// TestHostModelAliases verifies that configured aliases resolve to backend identifiers
+// and map back to their friendly names, while unaliased models pass through unchanged.
+func TestHostModelAliases(t *testing.T) {
+	host := Host{
+		Models:       []string{"fast", "llama3.2:1b"},
+		ModelAliases: map[string]string{"fast": "hf.co/org/fast-model-GGUF:Q4_K_M"},
+	}
+
+	if got := host.ResolveModel("fast"); got != "hf.co/org/fast-model-GGUF:Q4_K_M" {
+		t.Fatalf("ResolveModel(fast) = %q", got)
+	}
+	if got := host.ResolveModel("llama3.2:1b"); got != "llama3.2:1b" {
+		t.Fatalf("ResolveModel(llama3.2:1b) = %q", got)
+	}
+	if got := host.FriendlyModel("hf.co/org/fast-model-GGUF:Q4_K_M"); got != "fast" {
+		t.Fatalf("FriendlyModel(backend) = %q", got)
+	}
+	backend := host.BackendModels()
+	if len(backend) != 2 || backend[0] != "hf.co/org/fast-model-GGUF:Q4_K_M" || backend[1] != "llama3.2:1b" {
+		t.Fatalf("BackendModels() = %v", backend)
+	}
+}
+
+// TestValidateMCPServers verifies external MCP servers need a usable name and exactly one
+// of a command or an http URL.
+func TestValidateMCPServers(t *testing.T) {
+	valid := []MCPServer{{Name: "files", Command: "mcp-files"}, {Name: "remote-1", URL: "https://mcp.example.com/mcp"}}
+	if err := validateMCPServers(valid); err != nil {
+		t.Fatalf("validateMCPServers(valid) returned error: %v", err)
+	}
+	for _, servers := range [][]MCPServer{
+		{{Name: "bad name", Command: "x"}},
+		{{Name: "a", Command: "x"}, {Name: "a", URL: "http://localhost"}},
+		{{Name: "a"}},
+		{{Name: "a", Command: "x", URL: "http://localhost"}},
+		{{Name: "a", URL: "localhost:8080"}},
+	} {
+		if err := validateMCPServers(servers); err == nil {
+			t.Fatalf("validateMCPServers(%+v) should fail", servers)
+		}
+	}
+}
+
+// TestValidateFileTools verifies enabled file tools need a root and limits fall back to defaults.
+func TestValidateFileTools(t *testing.T) {
+	if err := validateFileTools(&FileToolsConfig{Roots: []string{"."}, ReadFile: true}); err != nil {
+		t.Fatalf("validateFileTools returned error: %v", err)
+	}
+	if err := validateFileTools(&FileToolsConfig{}); err != nil {
+		t.Fatalf("disabled file tools should not need roots: %v", err)
+	}
+	for _, files := range []*FileToolsConfig{{ListDirectory: true}, {Roots: []string{" "}, WriteFile: true}} {
+		if err := validateFileTools(files); err == nil {
+			t.Fatalf("validateFileTools(%+v) should fail", files)
+		}
+	}
+	if limits := (FileToolsConfig{MaxWriteBytes: 10}); limits.ReadLimit() != defaultFileToolsMaxReadBytes || limits.WriteLimit() != 10 {
+		t.Fatalf("unexpected limits %d/%d", limits.ReadLimit(), limits.WriteLimit())
+	}
+}
+
+// TestValidateCommandTool verifies allowed commands need unique names and argument
+// patterns that compile, and timeouts fall back to the default.
+func TestValidateCommandTool(t *testing.T) {
+	valid := &CommandToolConfig{Commands: []AllowedCommand{{Name: "go", Args: []string{"test", `\./[a-z/.]*`}}, {Name: "make", Timeout: 120}}}
+	if err := validateCommandTool(valid); err != nil {
+		t.Fatalf("validateCommandTool returned error: %v", err)
+	}
+	for _, tool := range []*CommandToolConfig{
+		{Commands: []AllowedCommand{{Name: " "}}},
+		{Commands: []AllowedCommand{{Name: "go"}, {Name: "go"}}},
+		{Commands: []AllowedCommand{{Name: "go", Args: []string{"("}}}},
+	} {
+		if err := validateCommandTool(tool); err == nil {
+			t.Fatalf("validateCommandTool(%+v) should fail", tool)
+		}
+	}
+	if valid.Commands[0].TimeoutDuration() != defaultCommandTimeout || valid.LongestTimeout() != 120*time.Second {
+		t.Fatalf("unexpected timeouts %s/%s", valid.Commands[0].TimeoutDuration(), valid.LongestTimeout())
+	}
+}
+
+// TestDecodeTokenizers verifies tokenizer overrides are validated and applied.
+func TestDecodeTokenizers(t *testing.T) {
+	cfg, err := decode(strings.NewReader(`{"hosts": [], "tokenizers": {"my-finetune": "llama"}}`))
+	if err != nil {
+		t.Fatalf("decode returned error: %v", err)
+	}
+	if family := cfg.TokenizerFor("my-finetune:latest").Family(); family != "llama" {
+		t.Fatalf("expected the override to select llama, got %s", family)
+	}
+	if _, err := decode(strings.NewReader(`{"hosts": [], "tokenizers": {"x": "wordpiece"}}`)); err == nil {
+		t.Fatalf("expected an unknown tokenizer family to be rejected")
+	}
+}
+
+// TestDecodeContextWindow verifies the context window policy is validated and defaulted.
+func TestDecodeContextWindow(t *testing.T) {
+	cfg, err := decode(strings.NewReader(`{"hosts": [], "contextWindow": {"policy": "Summarize"}}`))
+	if err != nil {
+		t.Fatalf("decode returned error: %v", err)
+	}
+	settings := cfg.ContextWindowSettings()
+	if settings.PolicyName() != ContextPolicySummarize || settings.ThresholdShare() != 0.9 || settings.KeepRecentMessages() != 4 {
+		t.Fatalf("unexpected settings: policy=%s threshold=%v keep=%d", settings.PolicyName(), settings.ThresholdShare(), settings.KeepRecentMessages())
+	}
+	if (Config{}).ContextWindowSettings().PolicyName() != ContextPolicyWarn {
+		t.Fatalf("expected warn by default")
+	}
+	for _, bad := range []string{`{"policy": "compress"}`, `{"threshold": 1.5}`} {
+		if _, err := decode(strings.NewReader(`{"hosts": [], "contextWindow": ` + bad + `}`)); err == nil {
+			t.Fatalf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+// TestDecodeLogging verifies the log settings become logging options and unknown levels,
+// formats and subsystems are rejected.
+func TestDecodeLogging(t *testing.T) {
+	cfg, err := decode(strings.NewReader(`{"hosts": [], "logLevel": "warn", "logFormat": "json", "logLevels": {"provider": "debug"}}`))
+	if err != nil {
+		t.Fatalf("decode returned error: %v", err)
+	}
+	opts := cfg.LogOptions()
+	if opts.Level != logging.LevelWarn || opts.Format != logging.FormatJSON || opts.Subsystems[logging.SubsystemProvider] != logging.LevelDebug {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+	if opts := (Config{Debug: true}).LogOptions(); opts.Level != logging.LevelDebug || opts.Format != logging.FormatText {
+		t.Fatalf("expected debug text logging when debug is set, got %+v", opts)
+	}
+	for _, bad := range []string{`"logLevel": "trace"`, `"logFormat": "xml"`, `"logLevels": {"ui": "debug"}`, `"logLevels": {"mcp": "loud"}`} {
+		if _, err := decode(strings.NewReader(`{"hosts": [], ` + bad + `}`)); err == nil {
+			t.Fatalf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+// TestGeocodeCacheSettings verifies the geocoding cache defaults, including a persistence
+// file under the data directory, and that each setting can be overridden or disabled.
+func TestGeocodeCacheSettings(t *testing.T) {
+	var cfg Config
+	if cfg.GeocodeCacheTTLDuration() != DefaultGeocodeCacheTTL || cfg.GeocodeCacheCapacity() != DefaultGeocodeCacheSize {
+		t.Fatalf("unexpected defaults %v, %d", cfg.GeocodeCacheTTLDuration(), cfg.GeocodeCacheCapacity())
+	}
+	if got := cfg.GeocodeCacheFile(); got != "agonData/geocode-cache.json" {
+		t.Fatalf("GeocodeCacheFile() = %q", got)
+	}
+
+	cfg = Config{GeocodeCacheTTL: -1, GeocodeCacheSize: 8, GeocodeCachePath: "cache/geo.json"}
+	if cfg.GeocodeCacheTTLDuration() != 0 || cfg.GeocodeCacheCapacity() != 8 || cfg.GeocodeCacheFile() != "cache/geo.json" {
+		t.Fatalf("unexpected overrides %v, %d, %q", cfg.GeocodeCacheTTLDuration(), cfg.GeocodeCacheCapacity(), cfg.GeocodeCacheFile())
+	}
+	cfg.DisableGeocodeCachePersistence = true
+	if got := cfg.GeocodeCacheFile(); got != "" {
+		t.Fatalf("expected persistence disabled, got %q", got)
+	}
+}
@@ -0,0 +1,168 @@
+// internal/appconfig/secrets.go
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mwiater/agon/internal/secrets"
+)
+
+// secretReference matches ${NAME} and ${NAME:-default}, which read the environment, and
+// ${secret:NAME}, which reads the encrypted secrets file. A leading $$ escapes the
+// reference, so $${NAME} stays ${NAME}.
+var secretReference = regexp.MustCompile(`\$(\$?)\{(secret:)?([A-Za-z_][A-Za-z0-9_.-]*)(:-[^}]*)?\}`)
+
+// SecretsPath returns the encrypted secrets file, defaulting to DefaultSecretsPath.
+func (c Config) SecretsPath() string {
+	if path := strings.TrimSpace(c.SecretsFile); path != "" {
+		return path
+	}
+	return DefaultSecretsPath
+}
+
+// SecretsKeyPath returns the secrets key file, defaulting to DefaultSecretsKeyPath.
+func (c Config) SecretsKeyPath() string {
+	if path := strings.TrimSpace(c.SecretsKeyFile); path != "" {
+		return path
+	}
+	return DefaultSecretsKeyPath
+}
+
+// ResolveSecrets replaces the secret references in the settings that hold credentials with
+// their values: each host's URL, proxy and headers, including the judges' hosts, each MCP
+// server's URL, env and headers, and the web tools' API key. Other settings, such as system
+// prompts, are free text and are left as written. The secrets file and key file paths may
+// only use the environment. The secrets file is only opened when a setting refers to it.
+func ResolveSecrets(config *Config) error {
+	envOnly := func(value string) (string, error) {
+		return expandReferences(value, nil)
+	}
+	var err error
+	if config.SecretsFile, err = envOnly(config.SecretsFile); err != nil {
+		return err
+	}
+	if config.SecretsKeyFile, err = envOnly(config.SecretsKeyFile); err != nil {
+		return err
+	}
+
+	var stored map[string]string
+	lookupSecret := func(name string) (string, bool, error) {
+		if stored == nil {
+			key, err := secrets.LoadKey(config.SecretsKeyPath())
+			if err != nil {
+				return "", false, err
+			}
+			if stored, err = secrets.Read(config.SecretsPath(), key); err != nil {
+				return "", false, err
+			}
+		}
+		value, ok := stored[name]
+		return value, ok, nil
+	}
+	expand := func(value *string) error {
+		expanded, err := expandReferences(*value, lookupSecret)
+		if err != nil {
+			return err
+		}
+		*value = expanded
+		return nil
+	}
+	expandMap := func(values map[string]string) error {
+		for key, value := range values {
+			if err := expand(&value); err != nil {
+				return err
+			}
+			values[key] = value
+		}
+		return nil
+	}
+	expandHost := func(host *Host) error {
+		if err := expand(&host.URL); err != nil {
+			return err
+		}
+		if err := expand(&host.Proxy); err != nil {
+			return err
+		}
+		return expandMap(host.Headers)
+	}
+
+	for i := range config.Hosts {
+		if err := expandHost(&config.Hosts[i]); err != nil {
+			return err
+		}
+	}
+	for _, judge := range []*JudgeConfig{config.Judge, config.SecondJudge} {
+		if judge == nil {
+			continue
+		}
+		if err := expandHost(&judge.Host); err != nil {
+			return err
+		}
+	}
+	for i := range config.MCPServers {
+		server := &config.MCPServers[i]
+		if err := expand(&server.URL); err != nil {
+			return err
+		}
+		if err := expandMap(server.Env); err != nil {
+			return err
+		}
+		if err := expandMap(server.Headers); err != nil {
+			return err
+		}
+	}
+	if config.WebTools != nil {
+		return expand(&config.WebTools.APIKey)
+	}
+	return nil
+}
+
+// expandReferences replaces the references in value. Without lookupSecret, a
+// ${secret:NAME} reference is an error.
+func expandReferences(value string, lookupSecret func(name string) (string, bool, error)) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var failure error
+	expanded := secretReference.ReplaceAllStringFunc(value, func(match string) string {
+		parts := secretReference.FindStringSubmatch(match)
+		escaped, secret, name, fallback := parts[1] != "", parts[2] != "", parts[3], parts[4]
+		if escaped {
+			return strings.TrimPrefix(match, "$")
+		}
+		if failure != nil {
+			return match
+		}
+		if secret {
+			if lookupSecret == nil {
+				failure = fmt.Errorf("${secret:%s}: secrets file paths may only use environment variables", name)
+				return match
+			}
+			resolved, ok, err := lookupSecret(name)
+			if err != nil {
+				failure = fmt.Errorf("${secret:%s}: %w", name, err)
+				return match
+			}
+			if !ok {
+				failure = fmt.Errorf("${secret:%s}: no such secret; add it with 'agon secrets set %s'", name, name)
+				return match
+			}
+			return resolved
+		}
+		if resolved, ok := os.LookupEnv(name); ok && (resolved != "" || fallback == "") {
+			return resolved
+		}
+		if fallback != "" {
+			return strings.TrimPrefix(fallback, ":-")
+		}
+		failure = fmt.Errorf("${%s}: environment variable %s is not set", name, name)
+		return match
+	})
+	if failure != nil {
+		return "", failure
+	}
+	return expanded, nil
+}
@@ -0,0 +1,90 @@
+// internal/appconfig/secrets_test.go
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/secrets"
+)
+
+// TestLoadResolvesSecrets verifies environment variables, defaults, escapes and secrets
+// from the encrypted file are resolved in credential settings when the config is loaded.
+func TestLoadResolvesSecrets(t *testing.T) {
+	dir := t.TempDir()
+	key, err := secrets.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "secrets.key")
+	if err := secrets.WriteKeyFile(keyFile, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := secrets.Write(filepath.Join(dir, "secrets.enc"), key, map[string]string{"anthropic": "sk-ant"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(secrets.KeyEnv, "")
+	t.Setenv("AGON_TEST_DIR", dir)
+	t.Setenv("AGON_TEST_HOST", "gpu-1")
+
+	path := filepath.Join(dir, "config.json")
+	config := `{
+		"secretsFile": "${AGON_TEST_DIR}/secrets.enc",
+		"secretsKeyFile": "${AGON_TEST_DIR}/secrets.key",
+		"hosts": [{
+			"name": "claude",
+			"url": "http://${AGON_TEST_HOST}:${AGON_TEST_PORT:-11434}",
+			"headers": {"x-api-key": "${secret:anthropic}", "x-literal": "$${literal}"},
+			"systemprompt": "Write ${AGON_TEST_UNSET} as is."
+		}],
+		"mcpServers": [{"name": "github", "command": "github-mcp", "env": {"GITHUB_TOKEN": "${secret:anthropic}"}}]
+	}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	host := cfg.Hosts[0]
+	if host.URL != "http://gpu-1:11434" || host.Headers["x-api-key"] != "sk-ant" || host.Headers["x-literal"] != "${literal}" {
+		t.Fatalf("unexpected resolved host %+v", host)
+	}
+	if cfg.MCPServers[0].Env["GITHUB_TOKEN"] != "sk-ant" {
+		t.Fatalf("unexpected resolved MCP server %+v", cfg.MCPServers[0])
+	}
+
+	for reference, want := range map[string]string{
+		"${AGON_TEST_UNSET}": "AGON_TEST_UNSET is not set",
+		"${secret:missing}":  "no such secret",
+	} {
+		broken := strings.Replace(config, "${secret:anthropic}", reference, 1)
+		if err := os.WriteFile(path, []byte(broken), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected an error containing %q, got %v", reference, want, err)
+		}
+	}
+}
+
+// TestLoadLeavesFreeText verifies references in free-text settings such as system prompts
+// are left as written, whether or not the variable is set.
+func TestLoadLeavesFreeText(t *testing.T) {
+	t.Setenv("AGON_TEST_SET", "rewritten")
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"hosts": [{"name": "local", "url": "http://localhost:11434", "type": "ollama", "models": ["llama3"],
+		"systemprompt": "Fill in ${X} and ${AGON_TEST_SET}."}]}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if prompt := cfg.Hosts[0].SystemPrompt; prompt != "Fill in ${X} and ${AGON_TEST_SET}." {
+		t.Fatalf("expected the system prompt unchanged, got %q", prompt)
+	}
+}
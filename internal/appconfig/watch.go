@@ -0,0 +1,158 @@
+// internal/appconfig/watch.go
+package appconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Update is published when a watched config file changes. Err is set when the new file
+// could not be loaded, in which case Config is empty and the old config should be kept.
+type Update struct {
+	Config Config
+	Err    error
+}
+
+// Watcher polls a config file and publishes an Update to every subscriber when its
+// contents change. Polling rather than file-system events keeps it portable and copes
+// with editors that save by replacing the file.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[int]chan Update
+	nextID      int
+
+	// modTime, size and sum describe the file as last seen, so unchanged files are not
+	// re-read and a save that leaves the contents alone publishes nothing.
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+}
+
+// NewWatcher returns a Watcher for the config file at path, checked every interval.
+// The file as it is now is the baseline, so only later changes are published.
+func NewWatcher(path string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultConfigReloadInterval
+	}
+	w := &Watcher{path: path, interval: interval, subscribers: make(map[int]chan Update)}
+	if info, err := os.Stat(path); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			w.modTime, w.size, w.sum = info.ModTime(), info.Size(), sha256.Sum256(data)
+		}
+	}
+	return w
+}
+
+// Subscribe returns a channel that receives each Update and a function that ends the
+// subscription. A subscriber that falls behind only receives the latest update.
+func (w *Watcher) Subscribe() (<-chan Update, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	ch := make(chan Update, 1)
+	w.subscribers[id] = ch
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if _, ok := w.subscribers[id]; ok {
+			delete(w.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+// Run checks the file every interval until ctx is done, then closes every subscription.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	defer w.closeAll()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check looks at the file once and publishes an Update if its contents changed. A file
+// that is missing or unreadable is skipped, since editors briefly remove files on save.
+func (w *Watcher) Check() {
+	info, err := os.Stat(w.path)
+	if err != nil || (info.ModTime().Equal(w.modTime) && info.Size() == w.size) {
+		return
+	}
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	sum := sha256.Sum256(data)
+	if sum == w.sum {
+		return
+	}
+	w.sum = sum
+
+	config, err := decode(bytes.NewReader(data))
+	if err == nil && len(config.Hosts) == 0 {
+		err = errors.New("config must contain at least one host")
+	}
+	if err != nil {
+		w.publish(Update{Err: err})
+		return
+	}
+	config.ConfigPath = w.path
+	w.publish(Update{Config: config})
+}
+
+// publish sends update to every subscriber, replacing any update still waiting.
+func (w *Watcher) publish(update Update) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ch := range w.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- update
+	}
+}
+
+// closeAll ends every subscription.
+func (w *Watcher) closeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id, ch := range w.subscribers {
+		delete(w.subscribers, id)
+		close(ch)
+	}
+}
+
+// Reloaded returns next, a config re-read from disk, with the settings that describe
+// this run rather than the file kept from c: the modes, debug logging, the MCP binary,
+// the export and log paths, the config path and the session being resumed. Changing
+// those needs a restart, since the TUIs are built around them.
+func (c Config) Reloaded(next Config) Config {
+	next.Debug = c.Debug
+	next.MultimodelMode = c.MultimodelMode
+	next.PipelineMode = c.PipelineMode
+	next.JSONMode = c.JSONMode
+	next.MCPMode = c.MCPMode
+	next.MCPBinary = c.MCPBinary
+	next.ExportPath = c.ExportPath
+	next.ExportMarkdownPath = c.ExportMarkdownPath
+	next.LogFile = c.LogFile
+	next.ConfigPath = c.ConfigPath
+	next.ResumeSession = c.ResumeSession
+	return next
+}
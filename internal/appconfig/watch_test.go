@@ -0,0 +1,71 @@
+// internal/appconfig/watch_test.go
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWatcherPublishesChanges verifies only changed contents are published, a broken file
+// is reported as an error, and a subscriber that falls behind sees the latest update.
+func TestWatcherPublishesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(content string, age time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Distinct modification times, since some file systems only keep whole seconds.
+		stamp := time.Now().Add(age)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"hosts": [{"name": "a", "url": "http://a"}], "timeout": 30}`, -3*time.Hour)
+
+	watcher := NewWatcher(path, time.Hour)
+	updates, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	watcher.Check()
+	write(`{"hosts": [{"name": "a", "url": "http://a"}], "timeout": 30}`, -2*time.Hour)
+	watcher.Check()
+	select {
+	case update := <-updates:
+		t.Fatalf("expected no update for unchanged contents, got %+v", update)
+	default:
+	}
+
+	write(`{"hosts": [`, -time.Hour)
+	watcher.Check()
+	write(`{"hosts": [{"name": "b", "url": "http://b"}], "timeout": 45}`, 0)
+	watcher.Check()
+	update := <-updates
+	if update.Err != nil || len(update.Config.Hosts) != 1 || update.Config.Hosts[0].Name != "b" || update.Config.TimeoutSeconds != 45 {
+		t.Fatalf("expected the latest config, got %+v", update)
+	}
+	if update.Config.ConfigPath != path {
+		t.Fatalf("expected the config path to be set, got %q", update.Config.ConfigPath)
+	}
+
+	write(`{"hosts": []}`, time.Hour)
+	watcher.Check()
+	if update := <-updates; update.Err == nil {
+		t.Fatalf("expected a config without hosts to be reported as an error")
+	}
+}
+
+// TestReloadedKeepsRunSettings verifies a reload takes the file's settings but keeps the
+// modes and paths the run was started with.
+func TestReloadedKeepsRunSettings(t *testing.T) {
+	current := Config{MCPMode: true, JSONMode: true, ConfigPath: "config/config.json", ResumeSession: "s1", TimeoutSeconds: 30}
+	next := current.Reloaded(Config{Hosts: []Host{{Name: "b"}}, TimeoutSeconds: 90})
+	if !next.MCPMode || !next.JSONMode || next.ConfigPath != "config/config.json" || next.ResumeSession != "s1" {
+		t.Fatalf("expected the run's settings to be kept, got %+v", next)
+	}
+	if next.TimeoutSeconds != 90 || len(next.Hosts) != 1 {
+		t.Fatalf("expected the file's settings to be taken, got %+v", next)
+	}
+}
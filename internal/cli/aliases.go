@@ -0,0 +1,176 @@
+// internal/cli/aliases.go
+package agon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxAliasDepth bounds how many aliases may expand into one another.
+const maxAliasDepth = 10
+
+// expandConfigAliases loads the aliases from the config named on the command line and
+// expands the one args invokes, if any. A missing or unreadable config means no aliases;
+// the command itself reports config problems.
+func expandConfigAliases(args []string) ([]string, error) {
+	cfg, err := appconfig.Load(configPathFromArgs(args))
+	if err != nil || len(cfg.Aliases) == 0 {
+		return args, nil
+	}
+	return expandAliases(rootCmd, args, cfg.Aliases)
+}
+
+// expandAliases replaces the command word in args with the arguments of the alias it
+// names. Flags placed before the alias are kept in front and arguments after it are
+// appended, so "agon smoke --debug" runs the alias with --debug added. Built-in commands
+// always win over an alias of the same name.
+func expandAliases(root *cobra.Command, args []string, aliases map[string]string) ([]string, error) {
+	seen := make(map[string]bool)
+	for depth := 0; ; depth++ {
+		index := commandWordIndex(root, args)
+		if index < 0 {
+			return args, nil
+		}
+		name := args[index]
+		definition, ok := aliases[name]
+		if !ok || isBuiltinCommand(root, name) {
+			return args, nil
+		}
+		if seen[name] || depth >= maxAliasDepth {
+			return nil, fmt.Errorf("alias %q expands into itself", name)
+		}
+		seen[name] = true
+
+		words, err := splitAliasArgs(definition)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: %w", name, err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %q is empty", name)
+		}
+		expanded := append([]string{}, args[:index]...)
+		expanded = append(expanded, words...)
+		args = append(expanded, args[index+1:]...)
+	}
+}
+
+// commandWordIndex returns the index of the first positional argument, skipping root
+// flags and their values, or -1 when there is none.
+func commandWordIndex(root *cobra.Command, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return -1
+		case strings.HasPrefix(arg, "--"):
+			name := strings.TrimPrefix(arg, "--")
+			if !strings.Contains(name, "=") && takesValue(root.PersistentFlags().Lookup(name)) {
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if len(arg) == 2 && takesValue(root.PersistentFlags().ShorthandLookup(arg[1:])) {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+// takesValue reports whether a flag consumes the following argument as its value.
+func takesValue(flag *pflag.Flag) bool {
+	return flag != nil && flag.NoOptDefVal == ""
+}
+
+// isBuiltinCommand reports whether name is a subcommand of root or one of its aliases.
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// configPathFromArgs returns the --config value in args, or the default config path.
+func configPathFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return appconfig.DefaultConfigPath
+		case (arg == "--config" || arg == "-c") && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--config="):
+			return strings.TrimPrefix(arg, "--config=")
+		case strings.HasPrefix(arg, "-c="):
+			return strings.TrimPrefix(arg, "-c=")
+		}
+	}
+	return appconfig.DefaultConfigPath
+}
+
+// splitAliasArgs splits an alias definition into arguments the way a shell would for
+// simple cases: on whitespace, with single or double quotes grouping words and a
+// backslash escaping the next character outside single quotes.
+func splitAliasArgs(definition string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range definition {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// sortedAliasNames returns the alias names in alphabetical order.
+func sortedAliasNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,64 @@
+// internal/cli/aliases_test.go
+package agon
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestExpandAliases verifies that aliases expand in place, keep leading flags and trailing
+// arguments, chain into other aliases, never shadow built-in commands, and reject cycles.
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"smoke":   `analyze metrics --input "reports/data/smoke run.json" --format html,csv`,
+		"nightly": "smoke --check",
+		"list":    "chat",
+		"loop":    "loop",
+	}
+
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{
+			args: []string{"--config", "lab.json", "smoke", "--strict"},
+			want: []string{"--config", "lab.json", "analyze", "metrics", "--input", "reports/data/smoke run.json", "--format", "html,csv", "--strict"},
+		},
+		{
+			args: []string{"nightly"},
+			want: []string{"analyze", "metrics", "--input", "reports/data/smoke run.json", "--format", "html,csv", "--check"},
+		},
+		{args: []string{"list", "models"}, want: []string{"list", "models"}},
+		{args: []string{"--debug", "chat"}, want: []string{"--debug", "chat"}},
+	}
+	for _, tc := range cases {
+		got, err := expandAliases(rootCmd, tc.args, aliases)
+		if err != nil {
+			t.Fatalf("expandAliases(%v) returned error: %v", tc.args, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("expandAliases(%v) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+
+	if _, err := expandAliases(rootCmd, []string{"loop"}, aliases); err == nil {
+		t.Fatalf("expected a self-referencing alias to be rejected")
+	}
+	if _, err := splitAliasArgs(`chat --export "unterminated`); err == nil {
+		t.Fatalf("expected an unterminated quote to be rejected")
+	}
+}
+
+// TestConfigPathFromArgs verifies that the config flag is found in its accepted forms.
+func TestConfigPathFromArgs(t *testing.T) {
+	for want, args := range map[string][]string{
+		"lab.json":           {"smoke", "--config", "lab.json"},
+		"other.json":         {"-c", "other.json", "smoke"},
+		"eq.json":            {"--config=eq.json"},
+		"config/config.json": {"smoke"},
+	} {
+		if got := configPathFromArgs(args); got != want {
+			t.Fatalf("configPathFromArgs(%v) = %q, want %q", args, got, want)
+		}
+	}
+}
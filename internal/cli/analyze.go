@@ -10,9 +10,17 @@ var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "Analyze benchmark outputs",
 	Long: `Tools for post-processing benchmark runs. Use these commands to turn raw
-benchmark JSON into richer analysis artifacts such as interactive HTML reports.`,
+benchmark JSON into richer analysis artifacts such as interactive HTML reports.
+
+Benchmark JSON written by earlier releases is upgraded on load and a warning is
+printed; pass --strict to reject it instead.`,
 }
 
+// analyzeStrict rejects legacy benchmark JSON instead of upgrading it.
+var analyzeStrict bool
+
 func init() {
+	analyzeCmd.PersistentFlags().BoolVar(&analyzeStrict, "strict", false, "Reject benchmark JSON in legacy formats instead of upgrading it on load")
+
 	rootCmd.AddCommand(analyzeCmd)
 }
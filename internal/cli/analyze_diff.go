@@ -0,0 +1,138 @@
+// internal/cli/analyze_diff.go
+package agon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+type analyzeDiffOptions struct {
+	htmlPath         string
+	markdownPath     string
+	jsonPath         string
+	tolerancePercent float64
+	failOnRegression bool
+	cdn              bool
+}
+
+var analyzeDiffOpts analyzeDiffOptions
+
+// analyzeDiffCmd compares two benchmark result sets or analysis documents.
+var analyzeDiffCmd = &cobra.Command{
+	Use:   "diff <baseline> <candidate>",
+	Short: "Compare two analysis runs and report per-model changes",
+	Long: `Compare a baseline and a candidate run, each given as either benchmark JSON
+or an analysis JSON written by 'agon analyze metrics'. The delta report lists
+per-model changes in tokens/sec, time to first token and efficiency ranking, and
+highlights regressions beyond the tolerance. Use it to validate driver or
+quantization upgrades.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if analyzeDiffOpts.tolerancePercent < 0 {
+			return fmt.Errorf("--tolerance must not be negative")
+		}
+		cmd.SilenceUsage = true
+
+		baseline, err := loadAnalysisInput(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		candidate, err := loadAnalysisInput(cmd, args[1])
+		if err != nil {
+			return err
+		}
+
+		diff := analysis.DiffAnalyses(baseline, candidate, analyzeDiffOpts.tolerancePercent)
+		diff.BaselineLabel = filepath.Base(args[0])
+		diff.CandidateLabel = filepath.Base(args[1])
+
+		cmd.Print(metrics.RenderDiffMarkdown(diff))
+
+		if analyzeDiffOpts.htmlPath != "" {
+			html, err := renderWithAssets(analyzeDiffOpts.cdn, func(offline bool) (string, error) {
+				return metrics.GenerateDiffReport(diff, offline)
+			})
+			if err != nil {
+				return fmt.Errorf("failed generating HTML diff report: %w", err)
+			}
+			if err := writeDiffFile(analyzeDiffOpts.htmlPath, []byte(html)); err != nil {
+				return err
+			}
+			cmd.Printf("\nDiff report written to %s\n", analyzeDiffOpts.htmlPath)
+		}
+		if analyzeDiffOpts.markdownPath != "" {
+			if err := writeDiffFile(analyzeDiffOpts.markdownPath, []byte(metrics.RenderDiffMarkdown(diff))); err != nil {
+				return err
+			}
+			cmd.Printf("Diff Markdown written to %s\n", analyzeDiffOpts.markdownPath)
+		}
+		if analyzeDiffOpts.jsonPath != "" {
+			data, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return fmt.Errorf("unable to marshal diff JSON: %w", err)
+			}
+			if err := writeDiffFile(analyzeDiffOpts.jsonPath, data); err != nil {
+				return err
+			}
+			cmd.Printf("Diff JSON written to %s\n", analyzeDiffOpts.jsonPath)
+		}
+
+		if analyzeDiffOpts.failOnRegression && diff.Regressions > 0 {
+			return fmt.Errorf("%d model(s) regressed beyond %.1f%%", diff.Regressions, diff.TolerancePercent)
+		}
+		return nil
+	},
+}
+
+func init() {
+	analyzeDiffCmd.Flags().StringVar(&analyzeDiffOpts.htmlPath, "html-output", "reports/metrics-diff.html", "Destination HTML diff report (empty to skip)")
+	analyzeDiffCmd.Flags().StringVar(&analyzeDiffOpts.markdownPath, "markdown-output", "", "Optional path to write the diff as Markdown")
+	analyzeDiffCmd.Flags().StringVar(&analyzeDiffOpts.jsonPath, "json-output", "", "Optional path to write the diff as JSON")
+	analyzeDiffCmd.Flags().Float64Var(&analyzeDiffOpts.tolerancePercent, "tolerance", analysis.DefaultDiffTolerancePercent, "Percent change in tokens/sec or TTFT, or points of accuracy, tolerated before flagging a model")
+	analyzeDiffCmd.Flags().BoolVar(&analyzeDiffOpts.failOnRegression, "fail-on-regression", false, "Exit non-zero when any model regresses")
+	analyzeDiffCmd.Flags().BoolVar(&analyzeDiffOpts.cdn, "cdn", false, "Link Bootstrap from its CDN instead of embedding it, for smaller files")
+
+	analyzeCmd.AddCommand(analyzeDiffCmd)
+}
+
+// loadAnalysisInput reads an analysis JSON document, or analyzes a benchmark JSON file on the fly.
+func loadAnalysisInput(cmd *cobra.Command, path string) (analysis.Analysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return analysis.Analysis{}, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	var result analysis.Analysis
+	if err := json.Unmarshal(data, &result); err == nil && len(result.Models) > 0 {
+		return result, nil
+	}
+
+	results, err := parseBenchmarkResults(cmd, path, data)
+	if err != nil {
+		return analysis.Analysis{}, fmt.Errorf("unable to parse %s as analysis or benchmark JSON: %w", path, err)
+	}
+	scoring, err := resolveScoring("")
+	if err != nil {
+		return analysis.Analysis{}, err
+	}
+	return analysis.AnalyzeMetricsWithScoring(results, analysis.HostInfo{}, scoring), nil
+}
+
+// writeDiffFile writes a diff artifact, creating its directory when needed.
+func writeDiffFile(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("unable to create directory for %s: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -3,11 +3,16 @@ package agon
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/appconfig"
 	"github.com/mwiater/agon/internal/metrics"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +23,15 @@ type analyzeMetricsOptions struct {
 	analysisPath string
 	hostName     string
 	hostNotes    string
+	check        bool
+	thresholds   string
+	formats      []string
+	scoring      string
+	language     string
+	acks         string
+	sections     []string
+	skipSections []string
+	cdn          bool
 }
 
 var analyzeMetricsOpts analyzeMetricsOptions
@@ -34,45 +48,136 @@ dashboard for review.`,
 		if analyzeMetricsOpts.inputPath == "" {
 			return fmt.Errorf("input benchmark file is required (pass --input)")
 		}
+		formats, err := parseReportFormats(analyzeMetricsOpts.formats)
+		if err != nil {
+			return err
+		}
+
+		scoring, err := resolveScoring(analyzeMetricsOpts.scoring)
+		if err != nil {
+			return err
+		}
+
+		language, messages, err := resolveReportMessages(analyzeMetricsOpts.language)
+		if err != nil {
+			return err
+		}
+
+		include, skip := resolveReportSections(cmd)
+		if err := metrics.ValidateReportSections(include, skip); err != nil {
+			return err
+		}
+
+		// Past flag validation, failures are data problems rather than usage errors.
+		cmd.SilenceUsage = true
+
+		manifest := metrics.NewRunManifest("analyze metrics", appVersion)
+		if err := manifest.AddInput(analyzeMetricsOpts.inputPath); err != nil {
+			return err
+		}
 
 		data, err := os.ReadFile(analyzeMetricsOpts.inputPath)
 		if err != nil {
 			return fmt.Errorf("unable to read benchmark file %s: %w", analyzeMetricsOpts.inputPath, err)
 		}
 
-		results, err := parseBenchmarkResults(data)
+		results, err := parseBenchmarkResults(cmd, analyzeMetricsOpts.inputPath, data)
 		if err != nil {
 			return fmt.Errorf("unable to parse benchmark JSON %s: %w", analyzeMetricsOpts.inputPath, err)
 		}
 
-		host := metrics.HostInfo{
+		host := analysis.HostInfo{
 			ClusterName: analyzeMetricsOpts.hostName,
 			Notes:       analyzeMetricsOpts.hostNotes,
 		}
 
-		analysis := metrics.AnalyzeMetrics(results, host)
+		result := analysis.AnalyzeMetricsWithScoring(results, host, scoring)
+		if acksPath := resolveAcknowledgementsPath(analyzeMetricsOpts.acks); acksPath != "" {
+			acks, err := analysis.LoadAnomalyAcknowledgements(acksPath)
+			if err != nil {
+				return err
+			}
+			result.AcknowledgeAnomalies(acks)
+			if err := manifest.AddInput(acksPath); err != nil {
+				return err
+			}
+		}
+		envPath := metrics.EnvironmentPathFor(analyzeMetricsOpts.inputPath)
+		if env, err := metrics.LoadEnvironment(envPath); err == nil {
+			result.AttachEnvironment(env)
+			if err := manifest.AddInput(envPath); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
 
 		if analyzeMetricsOpts.analysisPath != "" {
-			if err := writeAnalysisJSON(analyzeMetricsOpts.analysisPath, analysis); err != nil {
+			if err := writeAnalysisJSON(analyzeMetricsOpts.analysisPath, result); err != nil {
 				return err
 			}
 			cmd.Printf("Analysis JSON written to %s\n", analyzeMetricsOpts.analysisPath)
+			if err := manifest.AddOutput(analyzeMetricsOpts.analysisPath); err != nil {
+				return err
+			}
 		}
-
-		html, err := metrics.GenerateReport(analysis)
-		if err != nil {
-			return fmt.Errorf("failed generating HTML report: %w", err)
+		if dir := analysisHistoryPath(); dir != "" {
+			path := filepath.Join(dir, manifest.RunID+".json")
+			if err := writeAnalysisJSON(path, result); err != nil {
+				return err
+			}
+			cmd.Printf("Analysis archived to %s\n", path)
+			if err := manifest.AddOutput(path); err != nil {
+				return err
+			}
 		}
 
 		if analyzeMetricsOpts.htmlPath == "" {
 			analyzeMetricsOpts.htmlPath = "reports/metrics-report.html"
 		}
 
-		if err := os.WriteFile(analyzeMetricsOpts.htmlPath, []byte(html), 0o644); err != nil {
-			return fmt.Errorf("unable to write HTML report %s: %w", analyzeMetricsOpts.htmlPath, err)
+		if formats["html"] {
+			html, err := renderWithAssets(analyzeMetricsOpts.cdn, func(offline bool) (string, error) {
+				return metrics.RenderReport(result, metrics.ReportOptions{Language: language, Messages: messages, Sections: include, SkipSections: skip, Offline: offline})
+			})
+			if err != nil {
+				return fmt.Errorf("failed generating HTML report: %w", err)
+			}
+			if err := os.WriteFile(analyzeMetricsOpts.htmlPath, []byte(html), 0o644); err != nil {
+				return fmt.Errorf("unable to write HTML report %s: %w", analyzeMetricsOpts.htmlPath, err)
+			}
+			cmd.Printf("Report written to %s\n", analyzeMetricsOpts.htmlPath)
+			if err := manifest.AddOutput(analyzeMetricsOpts.htmlPath); err != nil {
+				return err
+			}
+		}
+
+		written, err := writeTabularReports(analyzeMetricsOpts.htmlPath, result, formats)
+		if err != nil {
+			return err
+		}
+		for _, path := range written {
+			cmd.Printf("Report written to %s\n", path)
+			if err := manifest.AddOutput(path); err != nil {
+				return err
+			}
+		}
+
+		if analyzeMetricsOpts.check {
+			if err := manifest.AddInput(analyzeMetricsOpts.thresholds); err != nil {
+				return err
+			}
+		}
+
+		manifestPath, err := manifest.Write(analyzeMetricsOpts.htmlPath)
+		if err != nil {
+			return err
 		}
+		cmd.Printf("Run manifest written to %s\n", manifestPath)
 
-		cmd.Printf("Report written to %s\n", analyzeMetricsOpts.htmlPath)
+		if analyzeMetricsOpts.check {
+			return checkAnalysisThresholds(cmd, result, analyzeMetricsOpts.thresholds)
+		}
 		return nil
 	},
 }
@@ -80,14 +185,180 @@ dashboard for review.`,
 func init() {
 	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.inputPath, "input", "reports/data/model_performance_metrics.json", "Path to benchmark JSON (required)")
 	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.htmlPath, "html-output", "reports/metrics-report.html", "Destination HTML report path")
-	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.analysisPath, "analysis-output", "", "Optional path to write the analysis JSON")
+	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.analysisPath, "analysis-output", appconfig.DefaultAnalysisPath, "Path to write the analysis JSON (empty to skip)")
 	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.hostName, "host-name", "", "Optional cluster/host label to embed in the analysis")
 	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.hostNotes, "host-notes", "", "Optional host notes to embed in the analysis")
+	analyzeMetricsCmd.Flags().BoolVar(&analyzeMetricsOpts.check, "check", false, "Evaluate alerting thresholds and exit non-zero on violations")
+	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.thresholds, "thresholds", "config/thresholds.json", "Path to the thresholds JSON used by --check")
+	analyzeMetricsCmd.Flags().StringSliceVar(&analyzeMetricsOpts.formats, "format", []string{"html"}, "Report formats to write: html, csv, markdown (comma-separated); csv and markdown files are named after --html-output")
+
+	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.scoring, "scoring", "", "Scoring profile: interactive, batch, or a YAML/JSON profile file (defaults to the config's scoringProfile)")
+
+	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.language, "language", "", "HTML report language (defaults to the config's reportLanguage, then en)")
+
+	analyzeMetricsCmd.Flags().StringVar(&analyzeMetricsOpts.acks, "acknowledgements", "", "YAML or JSON file of known anomalies to demote or suppress (defaults to the config's anomalyAcknowledgements)")
+
+	analyzeMetricsCmd.Flags().StringSliceVar(&analyzeMetricsOpts.sections, "sections", nil, "HTML report sections to render, in order (defaults to the config's reportSections, then all): "+strings.Join(metrics.ReportSectionIDs(), ", "))
+	analyzeMetricsCmd.Flags().StringSliceVar(&analyzeMetricsOpts.skipSections, "skip-sections", nil, "HTML report sections to leave out (defaults to the config's reportSkipSections)")
+	analyzeMetricsCmd.Flags().BoolVar(&analyzeMetricsOpts.cdn, "cdn", false, "Link Bootstrap and jQuery from their CDNs instead of embedding them, for smaller files")
 
 	analyzeCmd.AddCommand(analyzeMetricsCmd)
 }
 
-func writeAnalysisJSON(path string, analysis metrics.Analysis) error {
+// resolveScoring picks the scoring profile from the flag, then the config, then the default.
+func resolveScoring(flagValue string) (analysis.ScoringConfig, error) {
+	ref := flagValue
+	if ref == "" {
+		if cfg := GetConfig(); cfg != nil {
+			ref = cfg.ScoringProfile
+		}
+	}
+	return analysis.ResolveScoringConfig(ref)
+}
+
+// resolveAcknowledgementsPath picks the anomaly acknowledgements file from the flag, then the config.
+func resolveAcknowledgementsPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.AnomalyAcknowledgements
+	}
+	return ""
+}
+
+// analysisHistoryPath returns the directory each analysis is archived in for
+// `agon report serve`, or "" when the history is disabled.
+func analysisHistoryPath() string {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.AnalysisHistoryPath()
+	}
+	return appconfig.DefaultAnalysisHistoryDir
+}
+
+// resolveReportSections picks the report sections to render and skip from the flags, then
+// the config.
+func resolveReportSections(cmd *cobra.Command) ([]string, []string) {
+	include, skip := analyzeMetricsOpts.sections, analyzeMetricsOpts.skipSections
+	if cfg := GetConfig(); cfg != nil {
+		if !cmd.Flags().Changed("sections") {
+			include = cfg.ReportSections
+		}
+		if !cmd.Flags().Changed("skip-sections") {
+			skip = cfg.ReportSkipSections
+		}
+	}
+	return include, skip
+}
+
+// resolveReportMessages picks the report language from the flag, then the config, and loads
+// its messages from the config's reportMessages catalog.
+func resolveReportMessages(flagValue string) (string, metrics.ReportMessages, error) {
+	language, catalog := flagValue, ""
+	if cfg := GetConfig(); cfg != nil {
+		if language == "" {
+			language = cfg.ReportLanguage
+		}
+		catalog = cfg.ReportMessages
+	}
+	messages, err := metrics.ResolveReportMessages(language, catalog)
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(language) == "" {
+		language = metrics.DefaultReportLanguage
+	}
+	return strings.ToLower(strings.TrimSpace(language)), messages, nil
+}
+
+// checkAnalysisThresholds prints every threshold violation and returns an error when any are found.
+func checkAnalysisThresholds(cmd *cobra.Command, result analysis.Analysis, thresholdsPath string) error {
+	thresholds, err := analysis.LoadThresholds(thresholdsPath)
+	if err != nil {
+		return err
+	}
+
+	violations := analysis.CheckThresholds(result, thresholds)
+	if len(violations) == 0 {
+		cmd.Printf("Threshold check passed for %d model(s)\n", len(result.Models))
+		return nil
+	}
+
+	cmd.PrintErrf("Threshold check failed with %d violation(s):\n", len(violations))
+	for _, v := range violations {
+		cmd.PrintErrf("  - [%s] %s\n", v.Metric, v.Message)
+	}
+	return fmt.Errorf("%d threshold violation(s) detected", len(violations))
+}
+
+// parseReportFormats validates --format values and returns them as a set.
+func parseReportFormats(values []string) (map[string]bool, error) {
+	formats := make(map[string]bool)
+	for _, value := range values {
+		switch format := strings.ToLower(strings.TrimSpace(value)); format {
+		case "html", "csv":
+			formats[format] = true
+		case "markdown", "md":
+			formats["markdown"] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown report format %q (want html, csv, or markdown)", value)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("at least one report format is required")
+	}
+	return formats, nil
+}
+
+// writeTabularReports writes the CSV and Markdown reports requested in formats next to
+// the HTML report path and returns the files written.
+func writeTabularReports(htmlPath string, result analysis.Analysis, formats map[string]bool) ([]string, error) {
+	stem := strings.TrimSuffix(htmlPath, filepath.Ext(htmlPath))
+	if dir := filepath.Dir(stem); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("unable to create directory for %s: %w", stem, err)
+		}
+	}
+
+	var written []string
+	if formats["csv"] {
+		for suffix, write := range map[string]func(io.Writer, analysis.Analysis) error{
+			"-models.csv":     metrics.WriteModelsCSV,
+			"-iterations.csv": metrics.WriteIterationsCSV,
+		} {
+			path := stem + suffix
+			if err := writeReportFile(path, func(w io.Writer) error { return write(w, result) }); err != nil {
+				return nil, err
+			}
+			written = append(written, path)
+		}
+	}
+	if formats["markdown"] {
+		path := stem + ".md"
+		if err := os.WriteFile(path, []byte(metrics.RenderMarkdown(result)), 0o644); err != nil {
+			return nil, fmt.Errorf("unable to write Markdown report %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	sort.Strings(written)
+	return written, nil
+}
+
+// writeReportFile creates path and streams a report into it.
+func writeReportFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create report %s: %w", path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write report %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+func writeAnalysisJSON(path string, result analysis.Analysis) error {
 	dir := filepath.Dir(path)
 	if dir != "." && dir != "" {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
@@ -95,7 +366,7 @@ func writeAnalysisJSON(path string, analysis metrics.Analysis) error {
 		}
 	}
 
-	data, err := json.MarshalIndent(analysis, "", "  ")
+	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return fmt.Errorf("unable to marshal analysis JSON: %w", err)
 	}
@@ -106,67 +377,29 @@ func writeAnalysisJSON(path string, analysis metrics.Analysis) error {
 	return nil
 }
 
-func parseBenchmarkResults(raw []byte) (metrics.BenchmarkResults, error) {
-	var results metrics.BenchmarkResults
-	if err := json.Unmarshal(raw, &results); err == nil && len(results) > 0 {
-		return results, nil
+// parseBenchmarkResults decodes benchmark JSON from path, upgrading legacy layouts unless
+// --strict is set and noting each upgrade on stderr.
+func parseBenchmarkResults(cmd *cobra.Command, path string, raw []byte) (analysis.BenchmarkResults, error) {
+	results, report, err := analysis.DecodeBenchmarkResults(raw, analyzeStrict)
+	if errors.Is(err, analysis.ErrLegacyFormat) {
+		return nil, fmt.Errorf("%w (rerun without --strict to upgrade it on load)", err)
 	}
-
-	var modelMetrics []metrics.ModelMetrics
-	if err := json.Unmarshal(raw, &modelMetrics); err == nil && len(modelMetrics) > 0 {
-		return convertModelMetrics(modelMetrics), nil
+	if err != nil {
+		return nil, err
 	}
-
-	// Final attempt: allow empty payload that still unmarshals into map.
-	if results != nil {
-		return results, nil
+	if len(report.Upgrades) > 0 {
+		cmd.PrintErrf("Warning: %s uses a legacy benchmark format; upgraded on load: %s\n", path, strings.Join(report.Upgrades, "; "))
 	}
-
-	return nil, fmt.Errorf("json did not match benchmark results schema or aggregator metrics array")
-}
-
-func convertModelMetrics(models []metrics.ModelMetrics) metrics.BenchmarkResults {
-	out := make(metrics.BenchmarkResults, len(models))
-	for _, m := range models {
-		overall := m.OverallStats
-		bench := metrics.ModelBenchmark{
-			ModelName:      m.ModelName,
-			BenchmarkCount: int(overall.TotalRequests),
-			AverageStats: metrics.Stats{
-				TotalExecutionTime: msToNs(overall.TotalDurationMillis.Mean),
-				TimeToFirstToken:   msToNs(overall.TTFTMillis.Mean),
-				TokensPerSecond:    overall.TokensPerSecond.Mean,
-				InputTokenCount:    roundToInt(overall.InputTokens.Mean),
-				OutputTokenCount:   roundToInt(overall.OutputTokens.Mean),
-			},
-			MinStats: metrics.Stats{
-				TotalExecutionTime: msToNs(overall.TotalDurationMillis.Min),
-				TimeToFirstToken:   msToNs(overall.TTFTMillis.Min),
-				TokensPerSecond:    overall.TokensPerSecond.Min,
-				InputTokenCount:    roundToInt(overall.InputTokens.Min),
-				OutputTokenCount:   roundToInt(overall.OutputTokens.Min),
-			},
-			MaxStats: metrics.Stats{
-				TotalExecutionTime: msToNs(overall.TotalDurationMillis.Max),
-				TimeToFirstToken:   msToNs(overall.TTFTMillis.Max),
-				TokensPerSecond:    overall.TokensPerSecond.Max,
-				InputTokenCount:    roundToInt(overall.InputTokens.Max),
-				OutputTokenCount:   roundToInt(overall.OutputTokens.Max),
-			},
-			Iterations: nil,
-		}
-		out[m.ModelName] = bench
-	}
-	return out
-}
-
-func msToNs(ms float64) int64 {
-	return int64(ms * 1e6)
+	return results, nil
 }
 
-func roundToInt(val float64) int {
-	if math.IsNaN(val) || math.IsInf(val, 0) {
-		return 0
+// renderWithAssets renders an HTML report with its assets embedded unless cdn is set. Builds
+// made without the bundled assets fail rather than quietly writing a report that needs the
+// internet.
+func renderWithAssets(cdn bool, render func(offline bool) (string, error)) (string, error) {
+	html, err := render(!cdn)
+	if errors.Is(err, metrics.ErrAssetsNotBundled) {
+		return "", fmt.Errorf("%w, or pass --cdn to link them from their CDNs", err)
 	}
-	return int(math.Round(val))
+	return html, err
 }
@@ -9,6 +9,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// benchmarkSuites overrides the configured prompt suites when set.
+var benchmarkSuites []string
+
+// benchmarkTools runs the benchmark through the MCP provider, so models may call tools.
+var benchmarkTools bool
+
 // benchmarkCmd represents the benchmark command.
 var benchmarkCmd = &cobra.Command{
 	Use:   "benchmark",
@@ -22,10 +28,20 @@ var benchmarkCmd = &cobra.Command{
 			return nil
 		}
 		log.Printf("benchmark mode: %v", cfg.BenchmarkMode)
-		return benchmark.BenchmarkModels(GetConfig())
+		if len(benchmarkSuites) > 0 || benchmarkTools {
+			override := *cfg
+			if len(benchmarkSuites) > 0 {
+				override.PromptSuites = benchmarkSuites
+			}
+			override.MCPMode = override.MCPMode || benchmarkTools
+			cfg = &override
+		}
+		return benchmark.BenchmarkModels(cfg, appVersion)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(benchmarkCmd)
+	benchmarkCmd.Flags().BoolVar(&benchmarkTools, "tools", false, "let models call MCP tools and record which tools each answer used; results are written to a separate -tools file")
+	benchmarkCmd.Flags().StringSliceVar(&benchmarkSuites, "suite", nil, "JSONL prompt suites to ask (repeat or comma-separate to mix suites); overrides promptSuites in the config")
 }
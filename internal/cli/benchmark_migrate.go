@@ -0,0 +1,48 @@
+// internal/cli/benchmark_migrate.go
+package agon
+
+import (
+	"github.com/mwiater/agon/benchmark"
+	"github.com/spf13/cobra"
+)
+
+type benchmarkMigrateOptions struct {
+	dir    string
+	dryRun bool
+}
+
+var benchmarkMigrateOpts benchmarkMigrateOptions
+
+// benchmarkMigrateCmd renames existing benchmark result files to the canonical model-name scheme.
+var benchmarkMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rename benchmark result files to normalized model names",
+	Long: `Older benchmark runs wrote result files using raw model names in map order.
+This command rewrites each file name from its contents so that every writer and
+reader agrees on the same normalized, sorted model bundle name.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		renamed, err := benchmark.MigrateResultFiles(benchmarkMigrateOpts.dir, benchmarkMigrateOpts.dryRun)
+		for _, r := range renamed {
+			if benchmarkMigrateOpts.dryRun {
+				cmd.Printf("would rename %s -> %s\n", r.From, r.To)
+			} else {
+				cmd.Printf("renamed %s -> %s\n", r.From, r.To)
+			}
+		}
+		if len(renamed) == 0 {
+			cmd.Println("All benchmark result files already use normalized names.")
+		}
+		if err != nil {
+			cmd.SilenceUsage = true
+		}
+		return err
+	},
+}
+
+func init() {
+	benchmarkMigrateCmd.Flags().StringVar(&benchmarkMigrateOpts.dir, "dir", benchmark.ResultsDir, "Directory containing benchmark result files")
+	benchmarkMigrateCmd.Flags().BoolVar(&benchmarkMigrateOpts.dryRun, "dry-run", false, "Print the renames without applying them")
+
+	benchmarkCmd.AddCommand(benchmarkMigrateCmd)
+}
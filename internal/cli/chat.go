@@ -32,6 +32,13 @@ var chatCmd = &cobra.Command{
 			return
 		}
 
+		if resume, _ := cmd.Flags().GetString("resume"); resume != "" {
+			if cfg.PipelineMode || cfg.MultimodelMode {
+				log.Fatalf("--resume reopens single-model chat sessions; turn off pipelineMode and multimodelMode")
+			}
+			cfg.ResumeSession = resume
+		}
+
 		if cfg.PipelineMode {
 			if err := startPipelineGUI(ctx, cfg, cancel); err != nil {
 				log.Fatalf("Error running pipeline program: %v", err)
@@ -45,4 +52,5 @@ var chatCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(chatCmd)
+	chatCmd.Flags().String("resume", "", "reopen a recorded chat session by ID (see 'agon list sessions')")
 }
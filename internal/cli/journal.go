@@ -0,0 +1,128 @@
+// internal/cli/journal.go
+package agon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/cli"
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/journal"
+	"github.com/mwiater/agon/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+// journalOptions holds the flags of 'journal'.
+var journalOptions struct {
+	file    string
+	capture bool
+	preset  string
+	runs    []string
+	lines   int
+}
+
+// journalCmd implements 'journal', which appends notes to the experiment journal or,
+// without a note, prints it.
+var journalCmd = &cobra.Command{
+	Use:   "journal [note...]",
+	Short: "Append a note to the experiment journal, or print it",
+	Long: `The 'journal' command keeps a lab notebook for benchmarking campaigns: an
+append-only Markdown file at journalPath (agonData/journal.md by default). Each note
+is stored under a timestamped heading. --run links it to analysis or pipeline run IDs,
+--preset to a config alias, and --capture records the config file and the IDs of the
+latest analysis and pipeline runs. 'agon report serve' shows linked notes beside their
+runs.
+
+Without a note, the journal is printed, newest last; --run limits it to the notes
+about those runs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := journalOptions
+		cfg := GetConfig()
+		if strings.TrimSpace(opts.file) == "" {
+			if cfg != nil {
+				opts.file = cfg.JournalFile()
+			} else {
+				opts.file = appconfig.DefaultJournalPath
+			}
+		}
+		cmd.SilenceUsage = true
+
+		note := strings.TrimSpace(strings.Join(args, " "))
+		if note == "" {
+			return printJournal(cmd, opts.file, opts.runs, opts.lines)
+		}
+
+		entry := journal.Entry{Time: time.Now(), Text: note, Runs: opts.runs}
+		if opts.preset != "" {
+			if cfg == nil || cfg.Aliases[opts.preset] == "" {
+				return fmt.Errorf("no alias named %q in the config", opts.preset)
+			}
+			entry.Preset = fmt.Sprintf("%s (agon %s)", opts.preset, cfg.Aliases[opts.preset])
+		}
+		if opts.capture && cfg != nil {
+			entry.Config = cfg.ConfigPath
+			for _, id := range latestRunIDs(*cfg) {
+				if !entry.Mentions(id) {
+					entry.Runs = append(entry.Runs, id)
+				}
+			}
+		}
+		if err := journal.Append(opts.file, entry); err != nil {
+			return err
+		}
+		cmd.Printf("Note added to %s\n", opts.file)
+		return nil
+	},
+}
+
+// printJournal prints the journal's entries, or the last lines of them, limited to those
+// mentioning runs when any are given.
+func printJournal(cmd *cobra.Command, path string, runs []string, lines int) error {
+	entries, err := journal.Read(path)
+	if err != nil {
+		return err
+	}
+	if len(runs) > 0 {
+		entries = journal.ForRun(entries, runs...)
+	}
+	if len(entries) == 0 {
+		cmd.Printf("No journal entries in %s.\n", path)
+		return nil
+	}
+	if lines > 0 && len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+	for _, entry := range entries {
+		cmd.Print(entry.Markdown())
+	}
+	return nil
+}
+
+// latestRunIDs returns the IDs of the newest analysis run in the analysis history and the
+// newest archived pipeline run, skipping histories that are disabled or empty.
+func latestRunIDs(cfg appconfig.Config) []string {
+	var ids []string
+	if dir := cfg.AnalysisHistoryPath(); dir != "" {
+		if runs, err := metrics.ListAnalysisRuns(dir); err == nil && len(runs) > 0 {
+			ids = append(ids, runs[0].Name())
+		}
+	}
+	if dir := strings.TrimSpace(cfg.PipelineHistoryDir); dir != "" {
+		if runs, err := cli.ListPipelineRuns(dir); err == nil && len(runs) > 0 {
+			ids = append(ids, strings.TrimSuffix(filepath.Base(runs[0].Path), ".json"))
+		}
+	}
+	return ids
+}
+
+func init() {
+	journalCmd.Flags().StringVar(&journalOptions.file, "file", "", "Journal file (defaults to the config's journalPath)")
+	journalCmd.Flags().BoolVar(&journalOptions.capture, "capture", false, "Record the config file and the latest analysis and pipeline run IDs with the note")
+	journalCmd.Flags().StringVar(&journalOptions.preset, "preset", "", "Config alias the note is about")
+	journalCmd.Flags().StringSliceVar(&journalOptions.runs, "run", nil, "Run ID the note is about; when printing, show only notes about it (repeatable)")
+	journalCmd.Flags().IntVar(&journalOptions.lines, "lines", 0, "Print only the last N entries (0 prints all)")
+
+	rootCmd.AddCommand(journalCmd)
+}
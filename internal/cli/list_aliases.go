@@ -0,0 +1,34 @@
+// internal/cli/list_aliases.go
+package agon
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// listAliasesCmd implements 'list aliases', which prints the command aliases defined in
+// the config and what each expands to.
+var listAliasesCmd = &cobra.Command{
+	Use:   "aliases",
+	Short: "List command aliases defined in the config",
+	Long:  `The 'aliases' subcommand lists the command aliases defined under "aliases" in the config. Running 'agon <alias> [args]' runs agon with the alias's arguments followed by any extra arguments. Aliases never override built-in commands.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := GetConfig()
+		if cfg == nil || len(cfg.Aliases) == 0 {
+			fmt.Println("No aliases defined.")
+			return
+		}
+		for _, name := range sortedAliasNames(cfg.Aliases) {
+			note := ""
+			if isBuiltinCommand(rootCmd, name) {
+				note = "  (ignored: a built-in command has this name)"
+			}
+			fmt.Printf("  %s = agon %s%s\n", name, cfg.Aliases[name], note)
+		}
+	},
+}
+
+func init() {
+	listCmd.AddCommand(listAliasesCmd)
+}
@@ -0,0 +1,62 @@
+// internal/cli/list_pipelineruns.go
+package agon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mwiater/agon/cli"
+	"github.com/spf13/cobra"
+)
+
+// listPipelineRunsCmd implements 'list pipelineruns', which prints the archived
+// pipeline runs with their tags and notes so experiments stay identifiable.
+var listPipelineRunsCmd = &cobra.Command{
+	Use:   "pipelineruns",
+	Short: "List archived pipeline runs with their tags and notes",
+	Long:  `The 'pipelineruns' subcommand lists the pipeline runs archived in the directory set by pipelineHistoryDir, newest first, along with the tag, note, and stage models recorded for each run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dir, _ := cmd.Flags().GetString("dir")
+		if strings.TrimSpace(dir) == "" {
+			if cfg := GetConfig(); cfg != nil {
+				dir = cfg.PipelineHistoryDir
+			}
+		}
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("no pipeline history directory: set pipelineHistoryDir in the config or pass --dir")
+		}
+		tag, _ := cmd.Flags().GetString("tag")
+
+		runs, err := cli.ListPipelineRuns(dir)
+		if err != nil {
+			return err
+		}
+		printed := 0
+		for _, run := range runs {
+			if tag != "" && !strings.EqualFold(run.Tag, tag) {
+				continue
+			}
+			label := run.Tag
+			if label == "" {
+				label = "(untagged)"
+			}
+			fmt.Printf("%s  %s  %s\n", run.RunStarted, label, strings.Join(run.Models, " → "))
+			if run.Note != "" {
+				fmt.Printf("    note: %s\n", run.Note)
+			}
+			fmt.Printf("    file: %s\n", run.Path)
+			printed++
+		}
+		if printed == 0 {
+			fmt.Println("No pipeline runs found.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	listCmd.AddCommand(listPipelineRunsCmd)
+	listPipelineRunsCmd.Flags().String("dir", "", "directory of archived pipeline runs (defaults to pipelineHistoryDir)")
+	listPipelineRunsCmd.Flags().String("tag", "", "only list runs with this tag")
+}
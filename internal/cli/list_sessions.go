@@ -0,0 +1,51 @@
+// internal/cli/list_sessions.go
+package agon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mwiater/agon/internal/sessions"
+	"github.com/spf13/cobra"
+)
+
+// listSessionsCmd implements 'list sessions', which prints the recorded chat sessions so
+// one can be reopened with 'agon chat --resume'.
+var listSessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List recorded chat sessions",
+	Long:  `The 'sessions' subcommand lists the chat sessions recorded in sessionsDir (agonData/sessions by default), most recent first, with the host, model, message count and first prompt of each. Pass a session's ID to 'agon chat --resume' to continue it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dir, _ := cmd.Flags().GetString("dir")
+		if strings.TrimSpace(dir) == "" {
+			if cfg := GetConfig(); cfg != nil {
+				dir = cfg.SessionsPath()
+			}
+		}
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("session recording is disabled: unset disableSessions in the config or pass --dir")
+		}
+
+		summaries, err := sessions.NewStore(dir).List()
+		if err != nil {
+			return err
+		}
+		if len(summaries) == 0 {
+			fmt.Println("No chat sessions found.")
+			return nil
+		}
+		for _, s := range summaries {
+			fmt.Printf("%s  %s  %s • %s  (%d messages)\n", s.ID, s.Updated.Local().Format("2006-01-02 15:04"), s.Host, s.Model, s.Messages)
+			if s.Preview != "" {
+				fmt.Printf("    %s\n", s.Preview)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	listCmd.AddCommand(listSessionsCmd)
+	listSessionsCmd.Flags().String("dir", "", "directory of recorded sessions (defaults to sessionsDir)")
+}
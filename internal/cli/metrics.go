@@ -0,0 +1,17 @@
+// internal/cli/metrics.go
+package agon
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// metricsCmd represents the 'metrics' command group for working with collected metrics.
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Group commands for working with collected metrics",
+	Long:  `The 'metrics' command groups subcommands that work with collected metrics and benchmark results. It performs no action on its own.`,
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+}
@@ -0,0 +1,41 @@
+// internal/cli/metrics_explore.go
+package agon
+
+import (
+	"github.com/mwiater/agon/cli"
+	"github.com/spf13/cobra"
+)
+
+// metricsExplorePath is the file explored when no argument is given.
+const metricsExplorePath = "reports/data/model_performance_metrics.json"
+
+// startMetricsExplorer is a function alias to cli.StartMetricsExplorer so tests can stub the TUI.
+var startMetricsExplorer = cli.StartMetricsExplorer
+
+// metricsExploreCmd opens the interactive metrics explorer.
+var metricsExploreCmd = &cobra.Command{
+	Use:   "explore [file]",
+	Short: "Browse models and per-question results in an interactive TUI",
+	Long: `Load benchmark JSON, the aggregated metrics, or an analysis JSON written by
+'agon analyze metrics' and browse it in the terminal. The model table can be
+sorted by any aggregate and filtered by name; press enter on a model to see its
+per-question records. The file defaults to the aggregated metrics.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := metricsExplorePath
+		if len(args) == 1 {
+			path = args[0]
+		}
+		cmd.SilenceUsage = true
+
+		analysis, err := loadAnalysisInput(cmd, path)
+		if err != nil {
+			return err
+		}
+		return startMetricsExplorer(analysis)
+	},
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsExploreCmd)
+}
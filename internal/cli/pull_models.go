@@ -2,21 +2,79 @@
 package agon
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mwiater/agon/cli"
 	"github.com/mwiater/agon/internal/models"
 	"github.com/spf13/cobra"
 )
 
-// pullModelsCmd implements 'pull models', which pulls all configured models
-// to each supported host defined in the configuration file.
+var (
+	// pullModelsHost limits 'pull models' to one configured host.
+	pullModelsHost string
+	// pullModelsPlain prints progress as lines instead of the progress view.
+	pullModelsPlain bool
+	// startPullProgress is a function alias to cli.StartPullProgress so tests can stub the TUI.
+	startPullProgress = cli.StartPullProgress
+)
+
+// pullModelsCmd implements 'pull models', which pulls models to each supported host
+// defined in the configuration file and verifies they arrived.
 var pullModelsCmd = &cobra.Command{
-	Use:   "models",
-	Short: "Pull all models from the configuration file",
-	Long:  `The 'models' subcommand pulls all models from the configuration file (default: config/config.json).`,
-	Run: func(cmd *cobra.Command, args []string) {
-		models.PullModels(GetConfig())
+	Use:   "models [model...]",
+	Short: "Pull models to the configured hosts, showing download progress",
+	Long: `The 'models' subcommand pulls models to every Ollama host in the configuration file
+(default: config/config.json), streaming download progress, and then checks that each
+model appears in the host's model list. Name models to pull those instead of each host's
+configured models, and pass --host to pull to one host only. In a terminal the progress
+is shown as a bar per host and model; --plain, or output that is not a terminal, prints
+status lines instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		if cfg == nil {
+			return fmt.Errorf("configuration is not initialized")
+		}
+		cmd.SilenceUsage = true
+
+		pull := func(ctx context.Context, progress func(models.PullProgress)) error {
+			return models.PullModelsWithProgress(ctx, cfg, pullModelsHost, args, progress)
+		}
+		if pullModelsPlain || !stdinIsTerminal() {
+			return pull(cmd.Context(), plainPullProgress(cmd.OutOrStdout()))
+		}
+		return startPullProgress(pull)
 	},
 }
 
+// plainPullProgress prints a line whenever a pull's status changes, so layer downloads
+// are reported once rather than on every byte count.
+func plainPullProgress(w io.Writer) func(models.PullProgress) {
+	var mu sync.Mutex
+	last := make(map[string]string)
+	return func(p models.PullProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := p.Host + "\x00" + p.Model
+		if p.Status == last[key] && !p.Done {
+			return
+		}
+		last[key] = p.Status
+		switch {
+		case p.Done && p.Err != nil:
+			fmt.Fprintf(w, "%s %s: failed: %v\n", p.Host, p.Model, p.Err)
+		case p.Done:
+			fmt.Fprintf(w, "%s %s: pulled and verified\n", p.Host, p.Model)
+		default:
+			fmt.Fprintf(w, "%s %s: %s\n", p.Host, p.Model, p.Status)
+		}
+	}
+}
+
 func init() {
 	pullCmd.AddCommand(pullModelsCmd)
+	pullModelsCmd.Flags().StringVar(&pullModelsHost, "host", "", "pull to this configured host only")
+	pullModelsCmd.Flags().BoolVar(&pullModelsPlain, "plain", false, "print progress as lines instead of progress bars")
 }
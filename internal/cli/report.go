@@ -0,0 +1,19 @@
+// internal/cli/report.go
+package agon
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// reportCmd hosts commands that browse the reports of past analysis runs.
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Browse the reports of past analysis runs",
+	Long: `Tools for reviewing analysis runs after the fact. 'agon analyze metrics' keeps a copy
+of every analysis in analysisHistoryDir (agonData/analyses by default); these commands
+render and compare them.`,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+}
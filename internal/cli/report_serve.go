@@ -0,0 +1,89 @@
+// internal/cli/report_serve.go
+package agon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+type reportServeOptions struct {
+	addr             string
+	dir              string
+	language         string
+	tolerancePercent float64
+	cdn              bool
+	journal          string
+}
+
+var reportServeOpts reportServeOptions
+
+// reportServeCmd serves the analysis history as browsable reports.
+var reportServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve past analysis runs as browsable reports",
+	Long: `Start a local HTTP server listing every analysis run found under the history
+directory, newest first. Each run's report is rendered on demand, and any two runs
+can be picked for a side-by-side diff, so there is no need to hunt for standalone
+HTML files. Runs added while the server is up appear on the next page load.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportServeOpts.tolerancePercent < 0 {
+			return fmt.Errorf("--tolerance must not be negative")
+		}
+		language, messages, err := resolveReportMessages(reportServeOpts.language)
+		if err != nil {
+			return err
+		}
+		dir := strings.TrimSpace(reportServeOpts.dir)
+		journalPath := strings.TrimSpace(reportServeOpts.journal)
+		opts := metrics.ReportOptions{Language: language, Messages: messages, Offline: !reportServeOpts.cdn}
+		if cfg := GetConfig(); cfg != nil {
+			if dir == "" {
+				dir = cfg.AnalysisHistoryPath()
+			}
+			if journalPath == "" {
+				journalPath = cfg.JournalFile()
+			}
+			opts.Sections, opts.SkipSections = cfg.ReportSections, cfg.ReportSkipSections
+		}
+		if dir == "" {
+			dir = "agonData"
+		}
+		if err := metrics.ValidateReportSections(opts.Sections, opts.SkipSections); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		listener, err := net.Listen("tcp", reportServeOpts.addr)
+		if err != nil {
+			return fmt.Errorf("unable to listen on %s: %w", reportServeOpts.addr, err)
+		}
+		server := &metrics.ReportServer{Dir: dir, Journal: journalPath, Options: opts, TolerancePercent: reportServeOpts.tolerancePercent}
+		cmd.Printf("Serving analysis runs from %s at http://%s/ (ctrl+c to stop)\n", dir, listener.Addr())
+
+		httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportServeCmd.Flags().StringVar(&reportServeOpts.addr, "addr", "127.0.0.1:8686", "Address to listen on")
+	reportServeCmd.Flags().StringVar(&reportServeOpts.dir, "dir", "", "Directory searched for analysis JSON (defaults to the config's analysisHistoryDir)")
+	reportServeCmd.Flags().StringVar(&reportServeOpts.language, "language", "", "Report language (defaults to the config's reportLanguage, then en)")
+	reportServeCmd.Flags().Float64Var(&reportServeOpts.tolerancePercent, "tolerance", analysis.DefaultDiffTolerancePercent, "Percent change in tokens/sec or TTFT tolerated before a comparison flags a model")
+	reportServeCmd.Flags().StringVar(&reportServeOpts.journal, "journal", "", "Experiment journal whose notes are shown beside the runs they mention (defaults to the config's journalPath)")
+	reportServeCmd.Flags().BoolVar(&reportServeOpts.cdn, "cdn", false, "Link Bootstrap and jQuery from their CDNs instead of embedding them in each page")
+
+	reportCmd.AddCommand(reportServeCmd)
+}
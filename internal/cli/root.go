@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/charmbracelet/x/term"
+	"github.com/mwiater/agon/cli"
 	"github.com/mwiater/agon/internal/appconfig"
 	"github.com/mwiater/agon/internal/logging"
 	"github.com/spf13/cobra"
@@ -18,6 +21,11 @@ var (
 	appVersion    = "dev"
 	appCommit     = "none"
 	appDate       = "unknown"
+	// modeMenuArgs holds the arguments chosen in the startup mode menu, run once the
+	// root command returns.
+	modeMenuArgs []string
+	// runModeMenu is a function alias to cli.RunModeMenu so tests can stub the TUI.
+	runModeMenu = cli.RunModeMenu
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -48,6 +56,10 @@ var rootCmd = &cobra.Command{
 		if err := viper.Unmarshal(&cfg); err != nil {
 			return fmt.Errorf("unmarshal config: %w", err)
 		}
+		// 'agon secrets' must run even while the config refers to secrets not yet stored.
+		if err := appconfig.ResolveSecrets(&cfg); err != nil && !isSecretsCommand(cmd) {
+			return fmt.Errorf("resolve config secrets: %w", err)
+		}
 		cfg.ConfigPath = cfgFile
 		if cfg.MultimodelMode && cfg.PipelineMode {
 			return fmt.Errorf("invalid configuration: only one of multimodelMode or pipelineMode can be enabled")
@@ -57,9 +69,25 @@ var rootCmd = &cobra.Command{
 		if err := logging.Init(currentConfig.LogFilePath()); err != nil {
 			return fmt.Errorf("failed to initialize logger: %w", err)
 		}
+		if err := logging.Configure(currentConfig.LogOptions()); err != nil {
+			return fmt.Errorf("failed to configure logger: %w", err)
+		}
 
 		return nil
 	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		if (cfg != nil && cfg.SkipModeMenu) || !stdinIsTerminal() {
+			return cmd.Help()
+		}
+		cmd.SilenceUsage = true
+		choice, err := runModeMenu(cfg)
+		if err != nil {
+			return err
+		}
+		modeMenuArgs = choice
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -68,11 +96,39 @@ func Execute() {
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", appVersion, appCommit, appDate)
 
 	defer logging.Close()
-	if err := rootCmd.Execute(); err != nil {
+	args, err := expandConfigAliases(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	cmd, elapsed, err := executeArgs(args)
+	recordCommandUsage(cmd, elapsed, err)
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// executeArgs runs the root command with args. When the bare command opened the mode
+// menu, the chosen mode runs next, and its command and duration are the ones returned.
+func executeArgs(args []string) (*cobra.Command, time.Duration, error) {
+	rootCmd.SetArgs(args)
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil && len(modeMenuArgs) > 0 {
+		// Flags given with the bare command, such as --config, carry over to the chosen mode.
+		chosen := append(modeMenuArgs, args...)
+		modeMenuArgs = nil
+		if args, err = expandConfigAliases(chosen); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return cmd, 0, err
+		}
+		rootCmd.SetArgs(args)
+		start = time.Now()
+		cmd, err = rootCmd.ExecuteC()
+	}
+	return cmd, time.Since(start), err
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -119,6 +175,12 @@ func ensureConfigLoaded() error {
 	return nil
 }
 
+// stdinIsTerminal reports whether agon is attached to an interactive terminal. It is a
+// variable so tests can take the mode menu path.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
+
 // GetConfig returns the loaded application configuration for other packages.
 func GetConfig() *appconfig.Config {
 	return currentConfig
@@ -3,8 +3,14 @@ package agon
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/spf13/cobra"
 )
 
 // TestRootCmd verifies running the root command with an invalid subcommand reports an error.
@@ -25,3 +31,56 @@ func TestRootCmd(t *testing.T) {
 		t.Errorf("Expected output to contain '%s', but got '%s'", expected, b.String())
 	}
 }
+
+// TestExecuteArgsModeMenu verifies the bare command opens the mode menu on a terminal and
+// then runs the chosen mode with the flags given to the bare command.
+func TestExecuteArgsModeMenu(t *testing.T) {
+	savedMenu, savedTerminal := runModeMenu, stdinIsTerminal
+	defer func() { runModeMenu, stdinIsTerminal = savedMenu, savedTerminal }()
+	stdinIsTerminal = func() bool { return true }
+	var menuOpened bool
+	runModeMenu = func(cfg *appconfig.Config) ([]string, error) {
+		menuOpened = true
+		return []string{"menu-probe", "--jsonMode"}, nil
+	}
+
+	var ran []string
+	probe := &cobra.Command{
+		Use: "menu-probe",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonMode, _ := cmd.Flags().GetBool("jsonMode")
+			config, _ := cmd.Flags().GetString("config")
+			ran = append(ran, "jsonMode="+strconv.FormatBool(jsonMode), "config="+config)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(probe)
+	savedConfig := cfgFile
+	defer func() {
+		rootCmd.RemoveCommand(probe)
+		_ = rootCmd.PersistentFlags().Set("config", savedConfig)
+		_ = rootCmd.PersistentFlags().Set("jsonMode", "false")
+	}()
+	b := new(bytes.Buffer)
+	rootCmd.SetOut(b)
+	rootCmd.SetErr(b)
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, []byte(`{"logFile": "`+filepath.ToSlash(filepath.Join(dir, "agon.log"))+`"}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cmd, _, err := executeArgs([]string{"--config", config})
+	if err != nil {
+		t.Fatalf("executeArgs returned error: %v", err)
+	}
+	if !menuOpened || cmd != probe {
+		t.Fatalf("expected the menu choice to run, got menu=%t command=%v", menuOpened, cmd.Name())
+	}
+	if strings.Join(ran, " ") != "jsonMode=true config="+config {
+		t.Fatalf("expected the chosen mode to get the menu's and the bare command's flags, got %v", ran)
+	}
+	if len(modeMenuArgs) != 0 {
+		t.Fatalf("expected the menu choice to be consumed, got %v", modeMenuArgs)
+	}
+}
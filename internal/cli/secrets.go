@@ -0,0 +1,226 @@
+// internal/cli/secrets.go
+package agon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// secretsOptions holds the flags shared by the 'secrets' subcommands.
+var secretsOptions struct {
+	file    string
+	keyFile string
+}
+
+// secretsCmd groups the commands that manage the encrypted secrets file.
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the encrypted secrets file config values can refer to",
+	Long: `The 'secrets' command manages the AES-GCM encrypted file that keeps API keys and
+other credentials out of the config. A config string can refer to a stored secret
+with ${secret:NAME} or to an environment variable with ${NAME} (or ${NAME:-default});
+both are resolved whenever the config is loaded.
+
+The file is secretsFile (agonData/secrets.enc by default). Its key is read from
+AGON_SECRETS_KEY, base64-encoded, or else from secretsKeyFile
+(agonData/secrets.key by default). Keep the key file out of version control.`,
+}
+
+// secretsInitCmd implements 'secrets init', which creates the key file.
+var secretsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a new key for the secrets file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, keyFile := secretsPaths()
+		cmd.SilenceUsage = true
+		key, err := secrets.NewKey()
+		if err != nil {
+			return err
+		}
+		if err := secrets.WriteKeyFile(keyFile, key); err != nil {
+			return err
+		}
+		if err := secrets.Write(file, key, map[string]string{}); err != nil {
+			return err
+		}
+		cmd.Printf("Key written to %s and an empty secrets file to %s.\n", keyFile, file)
+		return nil
+	},
+}
+
+// secretsSetCmd implements 'secrets set', which stores a secret read from stdin.
+var secretsSetCmd = &cobra.Command{
+	Use:   "set NAME",
+	Short: "Store a secret, reading its value from stdin",
+	Long: `Stores a secret under NAME. The value is read from stdin: typed without echo at
+a terminal, or piped, e.g. 'printenv BRAVE_API_KEY | agon secrets set brave'. It
+is never taken from the command line, where it would end up in the shell history.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if !validSecretName(name) {
+			return fmt.Errorf("invalid secret name %q: use letters, digits, _, . and -, starting with a letter or _", name)
+		}
+		cmd.SilenceUsage = true
+		file, key, values, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		value, err := readSecretValue(cmd, name)
+		if err != nil {
+			return err
+		}
+		values[name] = value
+		if err := secrets.Write(file, key, values); err != nil {
+			return err
+		}
+		cmd.Printf("Stored %s in %s; refer to it as ${secret:%s}.\n", name, file, name)
+		return nil
+	},
+}
+
+// secretsListCmd implements 'secrets list', which prints the stored names.
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the names of the stored secrets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		file, _, values, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			cmd.Printf("No secrets in %s.\n", file)
+			return nil
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cmd.Println(name)
+		}
+		return nil
+	},
+}
+
+// secretsDeleteCmd implements 'secrets delete', which removes stored secrets.
+var secretsDeleteCmd = &cobra.Command{
+	Use:   "delete NAME...",
+	Short: "Remove secrets from the secrets file",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		file, key, values, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			if _, ok := values[name]; !ok {
+				return fmt.Errorf("no secret named %q in %s", name, file)
+			}
+			delete(values, name)
+		}
+		if err := secrets.Write(file, key, values); err != nil {
+			return err
+		}
+		cmd.Printf("Removed %s from %s.\n", strings.Join(args, ", "), file)
+		return nil
+	},
+}
+
+// secretsPaths returns the secrets file and key file from the flags, or else the config.
+func secretsPaths() (string, string) {
+	var cfg appconfig.Config
+	if loaded := GetConfig(); loaded != nil {
+		cfg = *loaded
+	}
+	file, keyFile := cfg.SecretsPath(), cfg.SecretsKeyPath()
+	if strings.TrimSpace(secretsOptions.file) != "" {
+		file = secretsOptions.file
+	}
+	if strings.TrimSpace(secretsOptions.keyFile) != "" {
+		keyFile = secretsOptions.keyFile
+	}
+	return file, keyFile
+}
+
+// openSecrets loads the key and decrypts the secrets file.
+func openSecrets() (string, []byte, map[string]string, error) {
+	file, keyFile := secretsPaths()
+	key, err := secrets.LoadKey(keyFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, nil, fmt.Errorf("%w; run 'agon secrets init' to create a key", err)
+		}
+		return "", nil, nil, err
+	}
+	values, err := secrets.Read(file, key)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return file, key, values, nil
+}
+
+// readSecretValue reads a secret's value from stdin, without echo at a terminal.
+func readSecretValue(cmd *cobra.Command, name string) (string, error) {
+	in := cmd.InOrStdin()
+	if file, ok := in.(*os.File); ok && term.IsTerminal(file.Fd()) {
+		cmd.PrintErrf("Value for %s: ", name)
+		value, err := term.ReadPassword(file.Fd())
+		cmd.PrintErrln()
+		if err != nil {
+			return "", fmt.Errorf("read secret: %w", err)
+		}
+		return string(value), nil
+	}
+	value, err := io.ReadAll(bufio.NewReader(in))
+	if err != nil {
+		return "", fmt.Errorf("read secret: %w", err)
+	}
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
+
+// validSecretName reports whether name can be written as a ${secret:NAME} reference.
+func validSecretName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && ((r >= '0' && r <= '9') || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isSecretsCommand reports whether cmd is 'secrets' or one of its subcommands.
+func isSecretsCommand(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == secretsCmd {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	secretsCmd.PersistentFlags().StringVar(&secretsOptions.file, "file", "", "Secrets file (defaults to the config's secretsFile)")
+	secretsCmd.PersistentFlags().StringVar(&secretsOptions.keyFile, "key", "", "Key file (defaults to the config's secretsKeyFile)")
+
+	secretsCmd.AddCommand(secretsInitCmd, secretsSetCmd, secretsListCmd, secretsDeleteCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
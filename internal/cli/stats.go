@@ -0,0 +1,108 @@
+// internal/cli/stats.go
+package agon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd implements 'agon stats', which summarizes the opt-in usage stats recorded locally.
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize locally recorded command timings, run counts and token totals",
+	Long: `The 'stats' command summarizes the usage stats agon records when "usageStats" is true in the config:
+how long each command takes, how many benchmark and pipeline runs were executed this month, and
+how many tokens they used. The stats are kept in a local file (usageStatsPath) and never sent anywhere.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cfg := GetConfig()
+		path, _ := cmd.Flags().GetString("file")
+		if strings.TrimSpace(path) == "" && cfg != nil {
+			path = cfg.UsageStatsFile()
+		}
+		all, _ := cmd.Flags().GetBool("all")
+
+		events, err := usage.Load(path)
+		if errors.Is(err, os.ErrNotExist) {
+			if cfg == nil || !cfg.UsageStats {
+				fmt.Println(`Usage stats are off. Set "usageStats": true in the config to record them locally.`)
+			} else {
+				fmt.Printf("No usage stats recorded yet in %s.\n", path)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		monthly := usage.Summarize(events, usage.MonthStart(now))
+		overall := usage.Summarize(events, time.Time{})
+		writeStats(os.Stdout, path, now, monthly, overall, all)
+		return nil
+	},
+}
+
+// writeStats prints the monthly summary, all-time token totals and the per-command timing table.
+func writeStats(w io.Writer, path string, now time.Time, monthly, overall usage.Summary, all bool) {
+	fmt.Fprintf(w, "Usage stats (%s)\n\n", path)
+	fmt.Fprintf(w, "%s: %d benchmark runs, %d pipeline runs\n", now.Format("January 2006"), monthly.BenchmarkRuns, monthly.PipelineRuns)
+	fmt.Fprintf(w, "Tokens this month: %d prompt, %d generated\n", monthly.PromptTokens, monthly.OutputTokens)
+	fmt.Fprintf(w, "Tokens all time:   %d prompt, %d generated (%d benchmark runs, %d pipeline runs)\n\n",
+		overall.PromptTokens, overall.OutputTokens, overall.BenchmarkRuns, overall.PipelineRuns)
+
+	commands, label := monthly.Commands, "Commands this month"
+	if all {
+		commands, label = overall.Commands, "Commands, all time"
+	}
+	if len(commands) == 0 {
+		fmt.Fprintf(w, "%s: none recorded\n", label)
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", label)
+	fmt.Fprintf(w, "  %-32s %6s %7s %10s %10s %10s\n", "COMMAND", "RUNS", "FAILED", "AVG", "MAX", "TOTAL")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-32s %6d %7d %10s %10s %10s\n", c.Name, c.Runs, c.Failed,
+			formatStatDuration(c.AverageSeconds()), formatStatDuration(c.MaxSeconds), formatStatDuration(c.TotalSeconds))
+	}
+}
+
+// formatStatDuration renders seconds as a duration rounded for display.
+func formatStatDuration(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// recordCommandUsage records how long a command took when usage stats are enabled.
+func recordCommandUsage(cmd *cobra.Command, elapsed time.Duration, runErr error) {
+	cfg := GetConfig()
+	if cmd == nil || cfg == nil {
+		return
+	}
+	event := usage.Event{
+		Kind:            usage.KindCommand,
+		Name:            cmd.CommandPath(),
+		DurationSeconds: elapsed.Seconds(),
+		Failed:          runErr != nil,
+	}
+	if err := usage.Record(*cfg, event); err != nil {
+		logging.LogError("usage stats: %v", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().String("file", "", "usage stats file to summarize (defaults to usageStatsPath)")
+	statsCmd.Flags().Bool("all", false, "list command timings for all recorded history instead of this month")
+}
@@ -0,0 +1,67 @@
+// internal/cli/tools.go
+package agon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/providers/mcp"
+	"github.com/spf13/cobra"
+)
+
+// toolsMock forces the spawned MCP server into fixture-backed mock mode.
+var toolsMock bool
+
+// toolsCmd groups developer commands that talk to the MCP server directly.
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Inspect and invoke MCP server tools",
+	Long: `The 'tools' command groups developer subcommands that spawn the agon-mcp
+server and talk to it directly, so tools can be listed and exercised without
+driving them through an LLM.`,
+}
+
+func init() {
+	toolsCmd.PersistentFlags().BoolVar(&toolsMock, "mock", false, "Answer tool calls from mock fixtures instead of live APIs")
+
+	rootCmd.AddCommand(toolsCmd)
+}
+
+// toolsServer is the part of the MCP provider the tools subcommands use.
+type toolsServer interface {
+	Tools() []providers.ToolDefinition
+	CallTool(ctx context.Context, name string, args map[string]any) (json.RawMessage, error)
+	CallTools(ctx context.Context, calls []mcp.BatchCall) (map[string]json.RawMessage, error)
+	Close() error
+}
+
+// startToolsServer spawns the MCP server described by the loaded configuration. It is a
+// variable so tests can substitute a server.
+var startToolsServer = func(ctx context.Context) (toolsServer, error) {
+	cfg := GetConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+	serverCfg := *cfg
+	if toolsMock {
+		serverCfg.MCPMock = true
+	}
+	server, err := mcp.New(ctx, &serverCfg)
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// checkToolName reports an error unless the server advertises a tool called name, since
+// the server answers calls to unknown tools with an ordinary result.
+func checkToolName(server toolsServer, name string) error {
+	for _, def := range server.Tools() {
+		if def.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tool %q (run 'agon tools list' to see the available tools)", name)
+}
@@ -0,0 +1,76 @@
+// internal/cli/tools_batch.go
+package agon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mwiater/agon/internal/providers/mcp"
+	"github.com/spf13/cobra"
+)
+
+// toolsBatchCmd implements 'tools batch', which runs several tool calls concurrently.
+var toolsBatchCmd = &cobra.Command{
+	Use:   "batch <calls-json | @file>",
+	Short: "Invoke several MCP tools concurrently",
+	Long: `The 'batch' subcommand sends independent tool calls to the MCP server in one
+tools/callBatch request. The server runs them concurrently and the results are
+printed keyed by call ID; calls without an "id" are keyed by their position.
+Pass the calls as a JSON array, or as @path to read them from a file.
+
+Example:
+  agon tools batch '[{"id":"time","name":"current_time"},{"id":"paris","name":"current_weather","arguments":{"location":"Paris, France"}}]'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data := []byte(args[0])
+		if path, ok := strings.CutPrefix(args[0], "@"); ok {
+			var err error
+			if data, err = os.ReadFile(path); err != nil {
+				return fmt.Errorf("unable to read calls from %s: %w", path, err)
+			}
+		}
+		var calls []mcp.BatchCall
+		if err := json.Unmarshal(data, &calls); err != nil {
+			return fmt.Errorf("calls must be a JSON array of {\"id\", \"name\", \"arguments\"} objects: %w", err)
+		}
+		if len(calls) == 0 {
+			return fmt.Errorf("at least one call is required")
+		}
+		cmd.SilenceUsage = true
+
+		server, err := startToolsServer(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+		for _, call := range calls {
+			if err := checkToolName(server, call.Name); err != nil {
+				return err
+			}
+		}
+
+		results, err := server.CallTools(cmd.Context(), calls)
+		if err != nil {
+			return fmt.Errorf("tool batch failed: %w", err)
+		}
+
+		encoded, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, encoded, "", "  "); err != nil {
+			cmd.Println(string(encoded))
+			return nil
+		}
+		cmd.Println(out.String())
+		return nil
+	},
+}
+
+func init() {
+	toolsCmd.AddCommand(toolsBatchCmd)
+}
@@ -0,0 +1,60 @@
+// internal/cli/tools_call.go
+package agon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// toolsCallArgs holds the JSON object passed to the tool as its arguments.
+var toolsCallArgs string
+
+// toolsCallCmd implements 'tools call', which invokes a single tool and prints its result.
+var toolsCallCmd = &cobra.Command{
+	Use:   "call <tool>",
+	Short: "Invoke an MCP tool with JSON arguments",
+	Long: `The 'call' subcommand invokes a tool on the MCP server with the arguments
+given by --args (a JSON object) and prints the structured JSON-RPC result.
+
+Example:
+  agon tools call current_weather --args '{"location":"Paris, France"}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var toolArgs map[string]any
+		if err := json.Unmarshal([]byte(toolsCallArgs), &toolArgs); err != nil {
+			return fmt.Errorf("--args must be a JSON object: %w", err)
+		}
+		cmd.SilenceUsage = true
+
+		server, err := startToolsServer(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+		if err := checkToolName(server, args[0]); err != nil {
+			return err
+		}
+
+		result, err := server.CallTool(cmd.Context(), args[0], toolArgs)
+		if err != nil {
+			return fmt.Errorf("tool %s failed: %w", args[0], err)
+		}
+
+		var out bytes.Buffer
+		if err := json.Indent(&out, result, "", "  "); err != nil {
+			cmd.Println(string(result))
+			return nil
+		}
+		cmd.Println(out.String())
+		return nil
+	},
+}
+
+func init() {
+	toolsCallCmd.Flags().StringVar(&toolsCallArgs, "args", "{}", "Tool arguments as a JSON object")
+
+	toolsCmd.AddCommand(toolsCallCmd)
+}
@@ -0,0 +1,59 @@
+// internal/cli/tools_list.go
+package agon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// toolsListJSON prints the full tool definitions as JSON instead of a summary.
+var toolsListJSON bool
+
+// toolsListCmd implements 'tools list', which prints the tools advertised by the MCP server.
+var toolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tools exposed by the MCP server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		server, err := startToolsServer(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+
+		defs := server.Tools()
+		if toolsListJSON {
+			data, err := json.MarshalIndent(defs, "", "  ")
+			if err != nil {
+				return fmt.Errorf("unable to marshal tool definitions: %w", err)
+			}
+			cmd.Println(string(data))
+			return nil
+		}
+
+		if len(defs) == 0 {
+			cmd.Println("The MCP server did not advertise any tools.")
+			return nil
+		}
+		width := 0
+		for _, def := range defs {
+			if len(def.Name) > width {
+				width = len(def.Name)
+			}
+		}
+		for _, def := range defs {
+			cmd.Printf("  %s%s%s\n", def.Name, strings.Repeat(" ", width-len(def.Name)+2), def.Description)
+		}
+		return nil
+	},
+}
+
+func init() {
+	toolsListCmd.Flags().BoolVar(&toolsListJSON, "json", false, "Print full tool definitions, including parameter schemas, as JSON")
+
+	toolsCmd.AddCommand(toolsListCmd)
+}
@@ -0,0 +1,153 @@
+// internal/cli/tools_log.go
+package agon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/toolaudit"
+	"github.com/spf13/cobra"
+)
+
+// toolsLogPollInterval is how often 'tools log --follow' checks for new calls.
+const toolsLogPollInterval = time.Second
+
+// toolsLogOptions holds the flags of 'tools log'.
+var toolsLogOptions struct {
+	dir    string
+	filter toolaudit.Filter
+	since  time.Duration
+	lines  int
+	follow bool
+	json   bool
+}
+
+// toolsLogCmd implements 'tools log', which prints and filters the tool call audit log.
+var toolsLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the audit log of tool calls made during chat and pipeline runs",
+	Long: `The 'log' subcommand prints the most recent MCP tool calls recorded in toolAuditDir
+(agonData/toolCalls by default): when each ran, the mode, host, model and turn that
+triggered it, its duration, result size, arguments and any error. Filter with --tool,
+--model, --host, --mode, --errors and --since, and pass --follow to keep printing new
+calls as they are made.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := toolsLogOptions
+		if strings.TrimSpace(opts.dir) == "" {
+			if cfg := GetConfig(); cfg != nil {
+				opts.dir = cfg.ToolAuditPath()
+			}
+		}
+		if strings.TrimSpace(opts.dir) == "" {
+			return fmt.Errorf("the tool audit log is disabled: unset disableToolAudit in the config or pass --dir")
+		}
+		if opts.since > 0 {
+			opts.filter.Since = time.Now().Add(-opts.since)
+		}
+		cmd.SilenceUsage = true
+
+		store := toolaudit.NewStore(opts.dir)
+		entries, err := store.Read(opts.filter)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 && !opts.follow {
+			cmd.Printf("No matching tool calls recorded in %s.\n", opts.dir)
+			return nil
+		}
+		if opts.lines > 0 && len(entries) > opts.lines {
+			entries = entries[len(entries)-opts.lines:]
+		}
+		out := cmd.OutOrStdout()
+		for _, entry := range entries {
+			writeToolCall(out, entry, opts.json)
+		}
+		if !opts.follow {
+			return nil
+		}
+
+		printed := len(entries)
+		seen := time.Time{}
+		if len(entries) > 0 {
+			seen = entries[len(entries)-1].Time
+		}
+		for {
+			select {
+			case <-cmd.Context().Done():
+				return nil
+			case <-time.After(toolsLogPollInterval):
+			}
+			filter := opts.filter
+			if filter.Since.Before(seen) {
+				filter.Since = seen
+			}
+			entries, err := store.Read(filter)
+			if err != nil {
+				return err
+			}
+			// Calls recorded at the instant of the last printed one were already printed.
+			skip := 0
+			for skip < len(entries) && printed > 0 && entries[skip].Time.Equal(seen) {
+				skip++
+			}
+			for _, entry := range entries[skip:] {
+				writeToolCall(out, entry, opts.json)
+				seen = entry.Time
+				printed++
+			}
+		}
+	},
+}
+
+// writeToolCall prints one audit entry as a line of JSON or as a summary line.
+func writeToolCall(w io.Writer, entry toolaudit.Entry, asJSON bool) {
+	if asJSON {
+		data, err := json.Marshal(entry)
+		if err == nil {
+			fmt.Fprintln(w, string(data))
+		}
+		return
+	}
+	tool := entry.Tool
+	if entry.Attempt > 1 {
+		tool = fmt.Sprintf("%s (attempt %d)", tool, entry.Attempt)
+	}
+	args, _ := json.Marshal(entry.Arguments)
+	fmt.Fprintf(w, "%s  %-10s %s • %s  turn %d  %s  %s  %dB  %s\n",
+		entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Mode, entry.Host, entry.Model, entry.Turn,
+		tool, formatToolDuration(entry.DurationMs), entry.ResultBytes, args)
+	if entry.Approval != "" {
+		fmt.Fprintf(w, "    approval: %s\n", entry.Approval)
+	}
+	if entry.Error != "" {
+		fmt.Fprintf(w, "    error: %s\n", entry.Error)
+	}
+}
+
+// formatToolDuration renders a call duration in milliseconds, or seconds once it is long.
+func formatToolDuration(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
+
+func init() {
+	flags := toolsLogCmd.Flags()
+	flags.StringVar(&toolsLogOptions.dir, "dir", "", "directory of the tool audit log (defaults to toolAuditDir)")
+	flags.StringVar(&toolsLogOptions.filter.Tool, "tool", "", "only show calls of tools whose name contains this text")
+	flags.StringVar(&toolsLogOptions.filter.Model, "model", "", "only show calls made for this model")
+	flags.StringVar(&toolsLogOptions.filter.Host, "host", "", "only show calls made on this host")
+	flags.StringVar(&toolsLogOptions.filter.Mode, "mode", "", "only show calls made in this mode: chat, multimodel or pipeline")
+	flags.BoolVar(&toolsLogOptions.filter.ErrorsOnly, "errors", false, "only show failed calls")
+	flags.DurationVar(&toolsLogOptions.since, "since", 0, "only show calls made within this long, e.g. 2h")
+	flags.IntVarP(&toolsLogOptions.lines, "lines", "n", 20, "number of recent calls to show; 0 shows all")
+	flags.BoolVarP(&toolsLogOptions.follow, "follow", "f", false, "keep printing new calls as they are recorded")
+	flags.BoolVar(&toolsLogOptions.json, "json", false, "print each call as a line of JSON")
+
+	toolsCmd.AddCommand(toolsLogCmd)
+}
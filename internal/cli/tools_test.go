@@ -0,0 +1,121 @@
+// internal/cli/tools_test.go
+package agon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/providers/mcp"
+)
+
+// fakeToolsServer advertises a fixed set of tools and records the calls made to it.
+type fakeToolsServer struct {
+	defs   []providers.ToolDefinition
+	called []string
+	closed bool
+}
+
+func (f *fakeToolsServer) Tools() []providers.ToolDefinition { return f.defs }
+
+func (f *fakeToolsServer) CallTool(_ context.Context, name string, args map[string]any) (json.RawMessage, error) {
+	f.called = append(f.called, name)
+	data, _ := json.Marshal(map[string]any{"content": []map[string]any{{"type": "text", "text": name}}, "arguments": args})
+	return data, nil
+}
+
+func (f *fakeToolsServer) CallTools(_ context.Context, calls []mcp.BatchCall) (map[string]json.RawMessage, error) {
+	results := make(map[string]json.RawMessage)
+	for _, call := range calls {
+		f.called = append(f.called, call.Name)
+		results[call.ID] = json.RawMessage(`{"content":[]}`)
+	}
+	return results, nil
+}
+
+func (f *fakeToolsServer) Close() error {
+	f.closed = true
+	return nil
+}
+
+// useFakeToolsServer makes the tools subcommands talk to a fake server for one test.
+func useFakeToolsServer(t *testing.T) *fakeToolsServer {
+	t.Helper()
+	server := &fakeToolsServer{defs: []providers.ToolDefinition{
+		{Name: "current_time", Description: "Current local time", Parameters: map[string]any{"type": "object"}},
+		{Name: "calculate", Description: "Evaluate an arithmetic expression", Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"expression": map[string]any{"type": "string"}},
+		}},
+	}}
+	saved := startToolsServer
+	startToolsServer = func(context.Context) (toolsServer, error) { return server, nil }
+	t.Cleanup(func() { startToolsServer = saved })
+	return server
+}
+
+// TestToolsList verifies the summary lists each tool with its aligned description and
+// that --json prints the full definitions, including their schemas.
+func TestToolsList(t *testing.T) {
+	server := useFakeToolsServer(t)
+	b := new(bytes.Buffer)
+	toolsListCmd.SetOut(b)
+	toolsListCmd.SetContext(context.Background())
+
+	if err := toolsListCmd.RunE(toolsListCmd, nil); err != nil {
+		t.Fatalf("tools list returned error: %v", err)
+	}
+	want := "  current_time  Current local time\n  calculate     Evaluate an arithmetic expression\n"
+	if b.String() != want || !server.closed {
+		t.Fatalf("unexpected list output %q", b.String())
+	}
+
+	b.Reset()
+	toolsListJSON = true
+	defer func() { toolsListJSON = false }()
+	if err := toolsListCmd.RunE(toolsListCmd, nil); err != nil {
+		t.Fatalf("tools list --json returned error: %v", err)
+	}
+	var defs []providers.ToolDefinition
+	if err := json.Unmarshal(b.Bytes(), &defs); err != nil {
+		t.Fatalf("expected JSON definitions, got %q: %v", b.String(), err)
+	}
+	if len(defs) != 2 || defs[1].Parameters["properties"] == nil {
+		t.Fatalf("unexpected definitions %+v", defs)
+	}
+}
+
+// TestToolsCall verifies a known tool is called with the parsed arguments and that an
+// unknown tool name, alone or in a batch, fails without calling anything.
+func TestToolsCall(t *testing.T) {
+	server := useFakeToolsServer(t)
+	b := new(bytes.Buffer)
+	toolsCallCmd.SetOut(b)
+	toolsCallCmd.SetContext(context.Background())
+	toolsBatchCmd.SetOut(b)
+	toolsBatchCmd.SetContext(context.Background())
+
+	toolsCallArgs = `{"expression":"2+3"}`
+	defer func() { toolsCallArgs = "{}" }()
+	if err := toolsCallCmd.RunE(toolsCallCmd, []string{"calculate"}); err != nil {
+		t.Fatalf("tools call returned error: %v", err)
+	}
+	if !strings.Contains(b.String(), `"expression": "2+3"`) {
+		t.Fatalf("unexpected call output %q", b.String())
+	}
+
+	err := toolsCallCmd.RunE(toolsCallCmd, []string{"calculator"})
+	if err == nil || !strings.Contains(err.Error(), `unknown tool "calculator"`) {
+		t.Fatalf("expected an unknown tool error, got %v", err)
+	}
+	err = toolsBatchCmd.RunE(toolsBatchCmd, []string{`[{"name":"current_time"},{"name":"weather"}]`})
+	if err == nil || !strings.Contains(err.Error(), `unknown tool "weather"`) {
+		t.Fatalf("expected an unknown tool error from the batch, got %v", err)
+	}
+	if strings.Join(server.called, ",") != "calculate" {
+		t.Fatalf("expected only the known tool to be called, got %v", server.called)
+	}
+}
@@ -0,0 +1,201 @@
+// internal/journal/journal.go
+
+// Package journal keeps an append-only Markdown lab notebook of benchmarking notes. Each
+// entry is a timestamped heading with the note and, optionally, the config, preset and
+// run IDs it refers to, so reports can show the notes next to the runs they describe.
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// header starts a new journal file.
+const header = "# agon journal\n"
+
+// Entry is one note in the journal.
+type Entry struct {
+	Time time.Time
+	Text string
+	// Config is the config file in use when the note was written.
+	Config string
+	// Preset names the config alias the note is about.
+	Preset string
+	// Runs lists the analysis or pipeline run IDs the note refers to.
+	Runs []string
+}
+
+// Markdown renders the entry as it is stored in the journal.
+func (e Entry) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", e.Time.UTC().Format(time.RFC3339))
+	if text := strings.TrimSpace(e.Text); text != "" {
+		b.WriteString(text + "\n\n")
+	}
+	var meta []string
+	if e.Config != "" {
+		meta = append(meta, "- config: "+e.Config)
+	}
+	if e.Preset != "" {
+		meta = append(meta, "- preset: "+e.Preset)
+	}
+	if len(e.Runs) > 0 {
+		meta = append(meta, "- runs: "+strings.Join(e.Runs, ", "))
+	}
+	if len(meta) > 0 {
+		b.WriteString(strings.Join(meta, "\n") + "\n\n")
+	}
+	return b.String()
+}
+
+// Mentions reports whether the entry refers to any of ids, either in its runs or its text.
+func (e Entry) Mentions(ids ...string) bool {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if slices.Contains(e.Runs, id) || strings.Contains(e.Text, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Append adds entry to the end of the journal at path, creating it and its directory if
+// needed. Existing entries are never rewritten.
+func Append(path string, entry Entry) error {
+	if strings.TrimSpace(entry.Text) == "" {
+		return fmt.Errorf("journal entry needs a note")
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create journal directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open journal %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat journal %s: %w", path, err)
+	}
+	text := entry.Markdown()
+	if info.Size() == 0 {
+		text = header + "\n" + text
+	}
+	if _, err := file.WriteString(text); err != nil {
+		return fmt.Errorf("unable to write journal %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read parses the journal at path, oldest entry first. A missing journal has no entries.
+// Text outside a timestamped heading, such as the title, is ignored.
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to open journal %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var (
+		entries []Entry
+		current *Entry
+		lines   []string
+	)
+	flush := func() {
+		if current != nil {
+			entries = append(entries, finishEntry(*current, lines))
+		}
+		current, lines = nil, nil
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			if when, err := time.Parse(time.RFC3339, strings.TrimSpace(heading)); err == nil {
+				flush()
+				current = &Entry{Time: when}
+				continue
+			}
+		}
+		if current != nil {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read journal %s: %w", path, err)
+	}
+	flush()
+	return entries, nil
+}
+
+// ForRun returns the entries that mention any of a run's ids.
+func ForRun(entries []Entry, ids ...string) []Entry {
+	var matches []Entry
+	for _, entry := range entries {
+		if entry.Mentions(ids...) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// finishEntry splits an entry's lines into its note and the metadata list ending it.
+func finishEntry(entry Entry, lines []string) Entry {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	for end > 0 {
+		key, value, ok := metadataLine(lines[end-1])
+		if !ok {
+			break
+		}
+		switch key {
+		case "config":
+			entry.Config = value
+		case "preset":
+			entry.Preset = value
+		case "runs":
+			for _, run := range strings.Split(value, ",") {
+				if run = strings.TrimSpace(run); run != "" {
+					entry.Runs = append(entry.Runs, run)
+				}
+			}
+		}
+		end--
+	}
+	entry.Text = strings.TrimSpace(strings.Join(lines[:end], "\n"))
+	return entry
+}
+
+// metadataLine parses a "- key: value" line for one of the keys Markdown writes.
+func metadataLine(line string) (key, value string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(line), "- ")
+	if !found {
+		return "", "", false
+	}
+	key, value, found = strings.Cut(rest, ":")
+	if !found {
+		return "", "", false
+	}
+	switch key {
+	case "config", "preset", "runs":
+		return key, strings.TrimSpace(value), true
+	}
+	return "", "", false
+}
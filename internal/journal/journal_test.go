@@ -0,0 +1,59 @@
+// internal/journal/journal_test.go
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAppendRead verifies entries round-trip through the Markdown file, the title is written
+// once, and notes can be found by the runs they mention.
+func TestAppendRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "journal.md")
+	first := Entry{Time: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), Text: "Baseline on the lab cluster.\n\n- keep num_ctx at 4096", Config: "config/lab.json", Preset: "bench (agon benchmark models)", Runs: []string{"run-1", "run-2"}}
+	second := Entry{Time: first.Time.Add(time.Hour), Text: "Retried run-3 after the driver update."}
+	for _, entry := range []Entry{first, second} {
+		if err := Append(path, entry); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+	if err := Append(path, Entry{Text: "  "}); err == nil {
+		t.Fatalf("expected an empty note to be refused")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if strings.Count(string(data), header) != 1 || !strings.HasPrefix(string(data), header) {
+		t.Fatalf("expected one title at the top, got:\n%s", data)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	got := entries[0]
+	if !got.Time.Equal(first.Time) || got.Text != first.Text || got.Config != first.Config || got.Preset != first.Preset || strings.Join(got.Runs, ",") != "run-1,run-2" {
+		t.Fatalf("unexpected first entry %+v", got)
+	}
+	if entries[1].Text != second.Text || len(entries[1].Runs) != 0 {
+		t.Fatalf("unexpected second entry %+v", entries[1])
+	}
+
+	if matches := ForRun(entries, "run-2"); len(matches) != 1 || matches[0].Config != first.Config {
+		t.Fatalf("expected the run to match its linked note, got %+v", matches)
+	}
+	if matches := ForRun(entries, "missing", "run-3"); len(matches) != 1 || matches[0].Text != second.Text {
+		t.Fatalf("expected a run named in the text to match, got %+v", matches)
+	}
+	if entries, err := Read(filepath.Join(t.TempDir(), "none.md")); err != nil || entries != nil {
+		t.Fatalf("expected a missing journal to be empty, got %+v, %v", entries, err)
+	}
+}
@@ -2,6 +2,7 @@
 package logging
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,11 +11,86 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
+// Level orders log entries by severity. Entries below the configured level are dropped.
+type Level int
+
+const (
+	// LevelDebug marks detail for chasing problems, such as request and stream payloads.
+	LevelDebug Level = iota
+	// LevelInfo marks routine events.
+	LevelInfo
+	// LevelWarn marks retries, fallbacks, and bypassed tools.
+	LevelWarn
+	// LevelError marks failures.
+	LevelError
+)
+
+// String returns the short label used when rendering a level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// ParseLevel reads a level name: debug, info, warn (or warning) or error.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q: use debug, info, warn or error", name)
+	}
+}
+
+// Log formats.
+const (
+	// FormatText writes one line per entry: timestamp, level, [subsystem] and message.
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line.
+	FormatJSON = "json"
+)
+
+// Subsystems that log through their own Logger, so their levels can be set apart.
+const (
+	SubsystemProvider = "provider"
+	SubsystemPipeline = "pipeline"
+	SubsystemMCP      = "mcp"
+	SubsystemMetrics  = "metrics"
+)
+
+// Subsystems lists the subsystem names levels may be set for.
+var Subsystems = []string{SubsystemProvider, SubsystemPipeline, SubsystemMCP, SubsystemMetrics}
+
+// Options control which entries are written and how.
+type Options struct {
+	// Level is the lowest level written.
+	Level Level
+	// Format is FormatText or FormatJSON.
+	Format string
+	// Subsystems overrides Level for the named subsystems.
+	Subsystems map[string]Level
+}
+
 var (
 	mu      sync.Mutex
 	logFile *os.File
+	options = Options{Level: LevelInfo, Format: FormatText}
 )
 
 // Init initializes the logging system, setting the output to a file if a path is provided.
@@ -52,10 +128,41 @@ func Init(logPath string) error {
 	logFile = file
 
 	// Set the log output to *only* this file
-	log.SetOutput(logFile)
+	applyOutputLocked()
 	return nil
 }
 
+// Configure changes the level and format at runtime, e.g. when the config is reloaded.
+func Configure(opts Options) error {
+	switch opts.Format {
+	case "":
+		opts.Format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q: use text or json", opts.Format)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	options = opts
+	if logFile != nil {
+		applyOutputLocked()
+	}
+	return nil
+}
+
+// applyOutputLocked points the standard logger at the log file in the configured format.
+// Lines written with the log package directly are wrapped as JSON in JSON mode, so the
+// file stays one format. mu must be held.
+func applyOutputLocked() {
+	if options.Format == FormatJSON {
+		log.SetFlags(0)
+		log.SetOutput(jsonWriter{out: logFile})
+		return
+	}
+	log.SetFlags(log.LstdFlags)
+	log.SetOutput(logFile)
+}
+
 // Close closes the log file if it's open.
 func Close() error {
 	mu.Lock()
@@ -69,29 +176,184 @@ func Close() error {
 	return err
 }
 
-// LogEvent logs a general event message.
-func LogEvent(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	log.Println(msg)
+// Logger writes entries for one subsystem.
+type Logger struct {
+	subsystem string
 }
 
-// LogMetricsEvent logs a metrics-specific event message only to the log file, if configured.
-func LogMetricsEvent(format string, args ...any) {
+// For returns the logger for subsystem.
+func For(subsystem string) Logger {
+	return Logger{subsystem: subsystem}
+}
+
+// Enabled reports whether entries at level are written, so costly messages can be skipped.
+func (l Logger) Enabled(level Level) bool {
 	mu.Lock()
 	defer mu.Unlock()
-	if logFile != nil {
-		msg := fmt.Sprintf(format, args...)
-		// Use a new logger that writes directly to logFile, bypassing the global log.SetOutput
-		// This ensures metrics logs only go to the file and not potentially to stdout/stderr
-		metricsLogger := log.New(logFile, "", log.LstdFlags)
-		metricsLogger.Println(msg)
+	threshold, ok := options.Subsystems[l.subsystem]
+	if !ok {
+		threshold = options.Level
+	}
+	return level >= threshold
+}
+
+// Debugf logs detail that is only wanted while debugging.
+func (l Logger) Debugf(format string, args ...any) {
+	l.emit(LevelDebug, fmt.Sprintf(format, args...), nil, true)
+}
+
+// Infof logs a routine event.
+func (l Logger) Infof(format string, args ...any) {
+	l.emit(LevelInfo, fmt.Sprintf(format, args...), nil, true)
+}
+
+// Warnf logs a retry, fallback or other recoverable problem.
+func (l Logger) Warnf(format string, args ...any) {
+	l.emit(LevelWarn, fmt.Sprintf(format, args...), nil, true)
+}
+
+// Errorf logs a failure.
+func (l Logger) Errorf(format string, args ...any) {
+	l.emit(LevelError, fmt.Sprintf(format, args...), nil, true)
+}
+
+// Eventf logs a free-form event at the level read from its text, so a message starting
+// with "[ERROR]" or mentioning a retry is logged as an error or a warning. It is kept for
+// older call sites; failures should be logged with Warnf or Errorf instead.
+func (l Logger) Eventf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.emit(classifyLevel(msg), msg, nil, true)
+}
+
+// Request logs a request or response payload at debug level. In JSON mode the host,
+// model, tool and payload are separate fields.
+func (l Logger) Request(direction, host, model, tool string, payload any) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	fields := &requestFields{
+		Direction: strings.ToUpper(strings.TrimSpace(direction)),
+		Host:      strings.TrimSpace(host),
+		Model:     strings.TrimSpace(model),
+		Tool:      strings.TrimSpace(tool),
 	}
+	text := formatPayload(payload)
+	if json.Valid([]byte(text)) {
+		fields.Payload = json.RawMessage(text)
+	} else {
+		fields.Payload, _ = json.Marshal(text)
+	}
+	l.emit(LevelDebug, buildRequestMessage(direction, host, model, tool, payload), fields, true)
 }
 
-// LogRequest logs a request/response message with structured data.
+// emit writes an entry when its level is enabled, keeping it for the log pane when tail is set.
+func (l Logger) emit(level Level, msg string, fields *requestFields, tail bool) {
+	if !l.Enabled(level) {
+		return
+	}
+	now := time.Now()
+	if tail {
+		recordTail(Entry{Time: now, Level: level, Subsystem: l.subsystem, Message: msg})
+	}
+	mu.Lock()
+	format := options.Format
+	mu.Unlock()
+	if format == FormatJSON {
+		rec := record{
+			Time:      now.Format(time.RFC3339Nano),
+			Level:     strings.ToLower(level.String()),
+			Subsystem: l.subsystem,
+			Message:   msg,
+		}
+		if fields != nil {
+			rec.requestFields = *fields
+			rec.Message = fields.Direction
+		}
+		data, err := json.Marshal(rec)
+		if err == nil {
+			log.Print(string(data))
+			return
+		}
+	}
+	if l.subsystem != "" {
+		msg = "[" + l.subsystem + "] " + msg
+	}
+	log.Printf("%-5s %s", level, msg)
+}
+
+// general logs the events that belong to no subsystem.
+var general = For("")
+
+// LogEvent logs a general event message, at the level read from its text.
+func LogEvent(format string, args ...any) {
+	general.Eventf(format, args...)
+}
+
+// LogWarn logs a general recoverable problem at warn level.
+func LogWarn(format string, args ...any) {
+	general.Warnf(format, args...)
+}
+
+// LogError logs a general failure at error level.
+func LogError(format string, args ...any) {
+	general.Errorf(format, args...)
+}
+
+// LogMetricsEvent logs a metrics-specific event message only to the log file, if configured.
+func LogMetricsEvent(format string, args ...any) {
+	For(SubsystemMetrics).emit(LevelInfo, fmt.Sprintf(format, args...), nil, false)
+}
+
+// LogRequest logs a provider request/response message with structured data.
 func LogRequest(direction, host, model, tool string, payload any) {
-	msg := buildRequestMessage(direction, host, model, tool, payload)
-	log.Println(msg)
+	For(SubsystemProvider).Request(direction, host, model, tool, payload)
+}
+
+// record is one entry in JSON mode.
+type record struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Subsystem string `json:"subsystem,omitempty"`
+	Message   string `json:"msg"`
+	requestFields
+}
+
+// requestFields are the parts of a logged request kept apart in JSON mode.
+type requestFields struct {
+	Direction string          `json:"-"`
+	Host      string          `json:"host,omitempty"`
+	Model     string          `json:"model,omitempty"`
+	Tool      string          `json:"tool,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// jsonRecordPrefix starts every line emit writes in JSON mode.
+var jsonRecordPrefix = []byte(`{"time":`)
+
+// jsonWriter receives lines from the standard logger in JSON mode. Entries from emit are
+// already JSON; anything else was logged with the log package directly and is wrapped.
+type jsonWriter struct {
+	out io.Writer
+}
+
+// Write writes one log line.
+func (w jsonWriter) Write(p []byte) (int, error) {
+	if bytes.HasPrefix(p, jsonRecordPrefix) {
+		return w.out.Write(p)
+	}
+	msg := strings.TrimRight(string(p), "\n")
+	data, err := json.Marshal(record{
+		Time:    time.Now().Format(time.RFC3339Nano),
+		Level:   strings.ToLower(classifyLevel(msg).String()),
+		Message: msg,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(append(data, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
 // buildRequestMessage constructs a structured log message for a request.
@@ -0,0 +1,108 @@
+// internal/logging/logging_test.go
+package logging
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logTo points the logger at a temporary file with opts and returns a function that
+// reads what was written.
+func logTo(t *testing.T, opts Options) func() string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agon.log")
+	if err := Init(path); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := Configure(opts); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Close()
+		_ = Configure(Options{Level: LevelInfo})
+	})
+	return func() string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read log: %v", err)
+		}
+		return string(data)
+	}
+}
+
+// TestLevelFiltering verifies entries below the level are dropped, and that a
+// subsystem's own level overrides the global one.
+func TestLevelFiltering(t *testing.T) {
+	read := logTo(t, Options{Level: LevelWarn, Subsystems: map[string]Level{SubsystemProvider: LevelDebug}})
+	pipeline, provider := For(SubsystemPipeline), For(SubsystemProvider)
+	pipeline.Infof("stage dispatched")
+	pipeline.Warnf("stage retrying")
+	provider.Debugf("chunk received")
+	provider.Request("AGON->LLM", "local", "llama3", "", `{"model":"llama3"}`)
+
+	out := read()
+	if strings.Contains(out, "stage dispatched") {
+		t.Fatalf("expected the info entry to be dropped:\n%s", out)
+	}
+	for _, want := range []string{"WARN  [pipeline] stage retrying", "DEBUG [provider] chunk received", "host=local model=llama3"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the log:\n%s", want, out)
+		}
+	}
+	if !provider.Enabled(LevelDebug) || pipeline.Enabled(LevelInfo) {
+		t.Fatalf("Enabled does not match the configured levels")
+	}
+}
+
+// TestJSONFormat verifies each entry is one JSON object with the subsystem and, for
+// requests, structured fields, and that lines logged directly are wrapped.
+func TestJSONFormat(t *testing.T) {
+	read := logTo(t, Options{Level: LevelDebug, Format: FormatJSON})
+	For(SubsystemMCP).Errorf("server %s unavailable", "weather")
+	For(SubsystemProvider).Request("LLM->AGON", "local", "llama3", "", `{"done":true}`)
+	LogEvent("config reloaded")
+	log.Printf("request failed")
+
+	lines := strings.Split(strings.TrimSpace(read()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	var entries []map[string]any
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not JSON: %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if entries[0]["level"] != "error" || entries[0]["subsystem"] != "mcp" || entries[0]["msg"] != "server weather unavailable" {
+		t.Fatalf("unexpected entry %v", entries[0])
+	}
+	payload, ok := entries[1]["payload"].(map[string]any)
+	if entries[1]["msg"] != "LLM->AGON" || entries[1]["model"] != "llama3" || !ok || payload["done"] != true {
+		t.Fatalf("unexpected request entry %v", entries[1])
+	}
+	if entries[2]["level"] != "info" || entries[2]["msg"] != "config reloaded" {
+		t.Fatalf("unexpected event entry %v", entries[2])
+	}
+	if entries[3]["level"] != "error" || entries[3]["msg"] != "request failed" {
+		t.Fatalf("unexpected wrapped entry %v", entries[3])
+	}
+}
+
+// TestParseLevel verifies level names are read case-insensitively and unknown ones rejected.
+func TestParseLevel(t *testing.T) {
+	cases := map[string]Level{"debug": LevelDebug, " INFO ": LevelInfo, "warning": LevelWarn, "Error": LevelError}
+	for name, want := range cases {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Fatalf("expected an error for an unknown level")
+	}
+}
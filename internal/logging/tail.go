@@ -0,0 +1,92 @@
+// internal/logging/tail.go
+package logging
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Entry is a single log line retained in memory for tailing.
+type Entry struct {
+	Time  time.Time
+	Level Level
+	// Subsystem is the part of agon that logged the entry; empty for general events.
+	Subsystem string
+	Message   string
+}
+
+const (
+	// tailCapacity bounds how many recent entries are kept in memory.
+	tailCapacity = 500
+	// tailMaxRunes truncates long entries (request payloads) kept in memory.
+	tailMaxRunes = 400
+)
+
+var (
+	tailMu      sync.Mutex
+	tailEntries []Entry
+	tailNext    int
+)
+
+// Recent returns up to n of the most recent entries at or above minLevel, oldest first.
+func Recent(n int, minLevel Level) []Entry {
+	tailMu.Lock()
+	defer tailMu.Unlock()
+
+	ordered := make([]Entry, 0, len(tailEntries))
+	if len(tailEntries) == tailCapacity {
+		ordered = append(ordered, tailEntries[tailNext:]...)
+		ordered = append(ordered, tailEntries[:tailNext]...)
+	} else {
+		ordered = append(ordered, tailEntries...)
+	}
+
+	filtered := make([]Entry, 0, n)
+	for i := len(ordered) - 1; i >= 0 && len(filtered) < n; i-- {
+		if ordered[i].Level >= minLevel {
+			filtered = append(filtered, ordered[i])
+		}
+	}
+	for i, j := 0, len(filtered)-1; i < j; i, j = i+1, j-1 {
+		filtered[i], filtered[j] = filtered[j], filtered[i]
+	}
+	return filtered
+}
+
+// recordTail stores an entry in the in-memory ring buffer.
+func recordTail(entry Entry) {
+	if utf8.RuneCountInString(entry.Message) > tailMaxRunes {
+		entry.Message = string([]rune(entry.Message)[:tailMaxRunes]) + "…"
+	}
+
+	tailMu.Lock()
+	defer tailMu.Unlock()
+	if len(tailEntries) < tailCapacity {
+		tailEntries = append(tailEntries, entry)
+		return
+	}
+	tailEntries[tailNext] = entry
+	tailNext = (tailNext + 1) % tailCapacity
+}
+
+// classifyLevel infers a level from message content for Eventf and LogEvent, whose call
+// sites log free-form text. Leveled calls do not pass through it.
+func classifyLevel(msg string) Level {
+	if strings.HasPrefix(msg, "[AGON->") || strings.HasPrefix(msg, "[LLM->") || strings.HasPrefix(msg, "[MCP->") {
+		return LevelInfo
+	}
+	lower := strings.ToLower(msg)
+	for _, marker := range []string{"[error]", "error", "failed", "unavailable", "panic"} {
+		if strings.Contains(lower, marker) {
+			return LevelError
+		}
+	}
+	for _, marker := range []string{"[warn]", "warning", "retry", "bypassed", "falling back", "fallback", "timeout"} {
+		if strings.Contains(lower, marker) {
+			return LevelWarn
+		}
+	}
+	return LevelInfo
+}
@@ -0,0 +1,89 @@
+// internal/logging/tail_test.go
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetTail empties the in-memory tail for a test and restores it afterwards.
+func resetTail(t *testing.T) {
+	t.Helper()
+	tailMu.Lock()
+	savedEntries, savedNext := tailEntries, tailNext
+	tailEntries, tailNext = nil, 0
+	tailMu.Unlock()
+	t.Cleanup(func() {
+		tailMu.Lock()
+		tailEntries, tailNext = savedEntries, savedNext
+		tailMu.Unlock()
+	})
+}
+
+// messages returns the messages of entries, in order.
+func messages(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Message
+	}
+	return out
+}
+
+// TestRecent verifies entries are filtered by level and returned oldest first, and that
+// entries logged after a read show up in the next one.
+func TestRecent(t *testing.T) {
+	resetTail(t)
+	record := func(level Level, msg string) {
+		recordTail(Entry{Time: time.Now(), Level: level, Message: msg})
+	}
+	record(LevelWarn, "w1")
+	record(LevelInfo, "i1")
+	record(LevelError, "e1")
+	record(LevelWarn, "w2")
+
+	if got := strings.Join(messages(Recent(2, LevelWarn)), ","); got != "e1,w2" {
+		t.Fatalf("expected the two newest warnings oldest first, got %s", got)
+	}
+	if got := strings.Join(messages(Recent(10, LevelInfo)), ","); got != "w1,i1,e1,w2" {
+		t.Fatalf("expected every entry, got %s", got)
+	}
+
+	record(LevelError, "e2")
+	if got := strings.Join(messages(Recent(2, LevelWarn)), ","); got != "w2,e2" {
+		t.Fatalf("expected the appended entry to be followed, got %s", got)
+	}
+}
+
+// TestRecentWrapsAround verifies that once the tail is full the oldest entries are
+// overwritten and the rest still come back in order.
+func TestRecentWrapsAround(t *testing.T) {
+	resetTail(t)
+	for i := 0; i < tailCapacity+5; i++ {
+		recordTail(Entry{Level: LevelInfo, Message: fmt.Sprintf("entry %d", i)})
+	}
+
+	entries := Recent(tailCapacity+5, LevelInfo)
+	if len(entries) != tailCapacity {
+		t.Fatalf("expected %d entries, got %d", tailCapacity, len(entries))
+	}
+	if entries[0].Message != "entry 5" || entries[len(entries)-1].Message != fmt.Sprintf("entry %d", tailCapacity+4) {
+		t.Fatalf("unexpected window %s … %s", entries[0].Message, entries[len(entries)-1].Message)
+	}
+	if got := strings.Join(messages(Recent(2, LevelInfo)), ","); got != fmt.Sprintf("entry %d,entry %d", tailCapacity+3, tailCapacity+4) {
+		t.Fatalf("unexpected newest entries %s", got)
+	}
+}
+
+// TestRecordTailTruncates verifies long messages are cut to tailMaxRunes runes without
+// splitting a multi-byte character.
+func TestRecordTailTruncates(t *testing.T) {
+	resetTail(t)
+	recordTail(Entry{Level: LevelInfo, Message: strings.Repeat("é", tailMaxRunes+10)})
+
+	entries := Recent(1, LevelInfo)
+	if len(entries) != 1 || entries[0].Message != strings.Repeat("é", tailMaxRunes)+"…" {
+		t.Fatalf("unexpected truncated message %q", entries[0].Message)
+	}
+}
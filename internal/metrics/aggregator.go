@@ -7,16 +7,18 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mwiater/agon/analysis"
 	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/modelname"
 	"github.com/mwiater/agon/internal/providers"
 )
 
 // Aggregator collects and manages performance metrics for models.
 type Aggregator struct {
-	mutex    sync.Mutex
-	metrics  map[string]*ModelMetrics
-	filePath string
-	ticker   *time.Ticker
+	mutex          sync.Mutex
+	metrics        map[string]*analysis.ModelMetrics
+	filePath       string
+	ticker         *time.Ticker
 	metricsEnabled bool
 }
 
@@ -36,8 +38,8 @@ func GetInstance() *Aggregator {
 // NewAggregator creates and initializes a new Aggregator.
 func NewAggregator() *Aggregator {
 	agg := &Aggregator{
-		metrics:  make(map[string]*ModelMetrics),
-		filePath: "reports/data/model_performance_metrics.json",
+		metrics:        make(map[string]*analysis.ModelMetrics),
+		filePath:       "reports/data/model_performance_metrics.json",
 		metricsEnabled: false, // Metrics are disabled by default
 	}
 
@@ -57,14 +59,87 @@ func (a *Aggregator) load() {
 		return
 	}
 
-	var metricsSlice []*ModelMetrics
+	var metricsSlice []*analysis.ModelMetrics
 	if err := json.Unmarshal(data, &metricsSlice); err != nil {
 		return
 	}
 
+	// Files written before names were normalized can hold one model under several
+	// spellings; their runs are merged rather than letting the last one win.
 	for _, m := range metricsSlice {
-		a.metrics[m.ModelName] = m
+		key := modelname.Normalize(m.ModelName)
+		if existing, ok := a.metrics[key]; ok {
+			mergeModelMetrics(existing, m)
+			continue
+		}
+		a.metrics[key] = m
+	}
+}
+
+// mergeModelMetrics folds src's runs into dst, combining the overall stats, matching
+// performance buckets and error counts.
+func mergeModelMetrics(dst, src *analysis.ModelMetrics) {
+	if src.LastUpdatedUTC.After(dst.LastUpdatedUTC) {
+		dst.LastUpdatedUTC = src.LastUpdatedUTC
+	}
+	mergeStats(&dst.OverallStats, src.OverallStats)
+
+	for _, bucket := range src.PerformanceBuckets {
+		found := false
+		for i := range dst.PerformanceBuckets {
+			if dst.PerformanceBuckets[i].Dimension == bucket.Dimension && dst.PerformanceBuckets[i].Bucket == bucket.Bucket {
+				mergeStats(&dst.PerformanceBuckets[i].Stats, bucket.Stats)
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.PerformanceBuckets = append(dst.PerformanceBuckets, bucket)
+		}
+	}
+
+	for category, count := range src.ErrorCounts {
+		if dst.ErrorCounts == nil {
+			dst.ErrorCounts = make(map[string]int64)
+		}
+		dst.ErrorCounts[category] += count
+	}
+}
+
+// mergeStats combines two sets of running statistics, weighting each by its request count
+// since the per-stat counts are not saved.
+func mergeStats(dst *analysis.RunningAggregatedStats, src analysis.RunningAggregatedStats) {
+	n1, n2 := dst.TotalRequests, src.TotalRequests
+	dst.TotalRequests += src.TotalRequests
+	mergeRunningStat(&dst.TTFTMillis, src.TTFTMillis, n1, n2)
+	mergeRunningStat(&dst.TokensPerSecond, src.TokensPerSecond, n1, n2)
+	mergeRunningStat(&dst.InputTokens, src.InputTokens, n1, n2)
+	mergeRunningStat(&dst.OutputTokens, src.OutputTokens, n1, n2)
+	mergeRunningStat(&dst.TotalDurationMillis, src.TotalDurationMillis, n1, n2)
+	mergeRunningStat(&dst.QueueWaitMillis, src.QueueWaitMillis, n1, n2)
+}
+
+// mergeRunningStat combines two running statistics over n1 and n2 values using the
+// parallel form of Welford's algorithm.
+func mergeRunningStat(dst *analysis.RunningStat, src analysis.RunningStat, n1, n2 int64) {
+	if n2 == 0 {
+		return
+	}
+	if n1 == 0 {
+		*dst = src
+		return
+	}
+	if src.Min < dst.Min {
+		dst.Min = src.Min
 	}
+	if src.Max > dst.Max {
+		dst.Max = src.Max
+	}
+	n := float64(n1 + n2)
+	delta := src.Mean - dst.Mean
+	dst.Mean += delta * float64(n2) / n
+	dst.M2 += src.M2 + delta*delta*float64(n1)*float64(n2)/n
+	dst.Count += src.Count
 }
 
 // SetMetricsEnabled enables or disables metrics collection and periodic saving.
@@ -104,7 +179,7 @@ func (a *Aggregator) save() {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	var metricsSlice []*ModelMetrics
+	var metricsSlice []*analysis.ModelMetrics
 	for _, m := range a.metrics {
 		metricsSlice = append(metricsSlice, m)
 	}
@@ -128,12 +203,13 @@ func (a *Aggregator) Record(meta providers.StreamMetadata, ttft int64) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	modelMetrics, exists := a.metrics[meta.Model]
+	key := modelname.Normalize(meta.Model)
+	modelMetrics, exists := a.metrics[key]
 	if !exists {
-		modelMetrics = &ModelMetrics{
+		modelMetrics = &analysis.ModelMetrics{
 			ModelName: meta.Model,
 		}
-		a.metrics[meta.Model] = modelMetrics
+		a.metrics[key] = modelMetrics
 	}
 
 	modelMetrics.LastUpdatedUTC = time.Now().UTC()
@@ -150,18 +226,41 @@ func (a *Aggregator) Record(meta providers.StreamMetadata, ttft int64) {
 		}
 	}
 	if !found {
-		newBucket := PerformanceBucket{
+		newBucket := analysis.PerformanceBucket{
 			Dimension: "input_tokens",
 			Bucket:    bucket,
-			Stats:     RunningAggregatedStats{},
+			Stats:     analysis.RunningAggregatedStats{},
 		}
 		updateStats(&newBucket.Stats, meta, ttft)
 		modelMetrics.PerformanceBuckets = append(modelMetrics.PerformanceBuckets, newBucket)
 	}
 }
 
+// RecordError counts a failed request for a model under the error's category.
+func (a *Aggregator) RecordError(model string, err error) {
+	if !a.metricsEnabled || err == nil {
+		return
+	}
+	category := providers.ErrorCategory(err)
+	logging.LogMetricsEvent("[METRICS] RecordError called for model %s: %s", model, category)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	key := modelname.Normalize(model)
+	modelMetrics, exists := a.metrics[key]
+	if !exists {
+		modelMetrics = &analysis.ModelMetrics{ModelName: model}
+		a.metrics[key] = modelMetrics
+	}
+	if modelMetrics.ErrorCounts == nil {
+		modelMetrics.ErrorCounts = make(map[string]int64)
+	}
+	modelMetrics.ErrorCounts[category]++
+	modelMetrics.LastUpdatedUTC = time.Now().UTC()
+}
+
 // updateStats updates the running statistics with new metadata.
-func updateStats(stats *RunningAggregatedStats, meta providers.StreamMetadata, ttft int64) {
+func updateStats(stats *analysis.RunningAggregatedStats, meta providers.StreamMetadata, ttft int64) {
 	stats.TotalRequests++
 	updateRunningStat(&stats.TTFTMillis, float64(ttft))
 
@@ -174,10 +273,11 @@ func updateStats(stats *RunningAggregatedStats, meta providers.StreamMetadata, t
 	updateRunningStat(&stats.InputTokens, float64(meta.PromptEvalCount))
 	updateRunningStat(&stats.OutputTokens, float64(meta.EvalCount))
 	updateRunningStat(&stats.TotalDurationMillis, float64(meta.TotalDuration/1e6))
+	updateRunningStat(&stats.QueueWaitMillis, float64(meta.QueueWait.Milliseconds()))
 }
 
 // updateRunningStat updates a single running statistic using Welford's online algorithm.
-func updateRunningStat(rs *RunningStat, value float64) {
+func updateRunningStat(rs *analysis.RunningStat, value float64) {
 	rs.Count++
 	if rs.Count == 1 {
 		rs.Min = value
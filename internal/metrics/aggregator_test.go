@@ -0,0 +1,50 @@
+// internal/metrics/aggregator_test.go
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// TestAggregatorLoadMergesSpellings verifies that runs saved under two spellings of the
+// same model are merged on load instead of one overwriting the other.
+func TestAggregatorLoadMergesSpellings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	data := `[
+  {"model_name": "Llama3.2:3B", "overall_stats": {"total_requests": 2, "tokens_per_second": {"mean": 10, "min": 8, "max": 12}},
+   "performance_buckets": [{"dimension": "input_tokens", "bucket": "0-256", "stats": {"total_requests": 2, "tokens_per_second": {"mean": 10, "min": 8, "max": 12}}}],
+   "error_counts": {"timeout": 1}},
+  {"model_name": "llama3.2:3b", "overall_stats": {"total_requests": 6, "tokens_per_second": {"mean": 20, "min": 15, "max": 30}},
+   "performance_buckets": [{"dimension": "input_tokens", "bucket": "0-256", "stats": {"total_requests": 1, "tokens_per_second": {"mean": 16, "min": 16, "max": 16}}},
+                           {"dimension": "input_tokens", "bucket": "257-1024", "stats": {"total_requests": 5, "tokens_per_second": {"mean": 20.8, "min": 15, "max": 30}}}],
+   "error_counts": {"timeout": 2, "connection": 1}}
+]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+
+	agg := &Aggregator{metrics: make(map[string]*analysis.ModelMetrics), filePath: path}
+	agg.load()
+
+	if len(agg.metrics) != 1 {
+		t.Fatalf("expected the spellings to merge into one model, got %d", len(agg.metrics))
+	}
+	merged := agg.metrics["llama3.2_3b"]
+	overall := merged.OverallStats
+	if overall.TotalRequests != 8 || overall.TokensPerSecond.Mean != 17.5 || overall.TokensPerSecond.Min != 8 || overall.TokensPerSecond.Max != 30 {
+		t.Fatalf("unexpected overall stats %+v", overall)
+	}
+	if len(merged.PerformanceBuckets) != 2 {
+		t.Fatalf("expected two buckets, got %+v", merged.PerformanceBuckets)
+	}
+	small := merged.PerformanceBuckets[0].Stats
+	if small.TotalRequests != 3 || small.TokensPerSecond.Mean != 12 || small.TokensPerSecond.Max != 16 {
+		t.Fatalf("unexpected merged bucket %+v", small)
+	}
+	if merged.ErrorCounts["timeout"] != 3 || merged.ErrorCounts["connection"] != 1 {
+		t.Fatalf("unexpected error counts %v", merged.ErrorCounts)
+	}
+}
@@ -0,0 +1,68 @@
+// internal/metrics/assets.go
+package metrics
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"strings"
+)
+
+//go:generate go run fetch_assets.go
+
+// ErrAssetsNotBundled reports that an offline report asset was not fetched into the build.
+var ErrAssetsNotBundled = errors.New("report assets are not bundled in this build")
+
+// ReportAsset is a stylesheet or script the HTML reports load, either linked from its CDN
+// or inlined from the copy embedded at build time.
+type ReportAsset struct {
+	// File is the asset's name under internal/metrics/assets.
+	File string
+	URL  string
+}
+
+var (
+	bootstrapCSS     = ReportAsset{File: "bootstrap.min.css", URL: "https://cdn.jsdelivr.net/npm/bootstrap@5.3.3/dist/css/bootstrap.min.css"}
+	materialIconsCSS = ReportAsset{File: "material-icons-two-tone.css", URL: "https://fonts.googleapis.com/icon?family=Material+Icons+Two+Tone"}
+	jqueryJS         = ReportAsset{File: "jquery.min.js", URL: "https://code.jquery.com/jquery-3.7.1.min.js"}
+	bootstrapJS      = ReportAsset{File: "bootstrap.bundle.min.js", URL: "https://cdn.jsdelivr.net/npm/bootstrap@5.3.3/dist/js/bootstrap.bundle.min.js"}
+)
+
+// ReportAssets lists every asset the HTML reports load; fetch_assets.go downloads them.
+var ReportAssets = []ReportAsset{bootstrapCSS, materialIconsCSS, jqueryJS, bootstrapJS}
+
+//go:embed assets
+var embeddedAssets embed.FS
+
+// bundledAssets is where offline assets are read from; tests swap it out.
+var bundledAssets fs.FS = embeddedAssets
+
+// assetTags renders the tags that load assets: links to their CDNs, or with offline set,
+// their bundled contents inlined.
+func assetTags(offline bool, assets ...ReportAsset) (template.HTML, error) {
+	var b strings.Builder
+	for _, asset := range assets {
+		stylesheet := strings.HasSuffix(asset.File, ".css")
+		if !offline {
+			if stylesheet {
+				fmt.Fprintf(&b, "  <link rel=\"stylesheet\" href=\"%s\">\n", asset.URL)
+			} else {
+				fmt.Fprintf(&b, "  <script src=\"%s\"></script>\n", asset.URL)
+			}
+			continue
+		}
+
+		data, err := fs.ReadFile(bundledAssets, "assets/"+asset.File)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s is missing (run go generate ./internal/metrics)", ErrAssetsNotBundled, asset.File)
+		}
+		if stylesheet {
+			b.WriteString("  <style>" + strings.ReplaceAll(string(data), "</style", `<\/style`) + "</style>\n")
+		} else {
+			b.WriteString("  <script>" + strings.ReplaceAll(string(data), "</script", `<\/script`) + "</script>\n")
+		}
+	}
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,40 @@
+// internal/metrics/assets_test.go
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestAssetTags verifies CDN mode links assets, offline mode inlines the bundled copies
+// without letting them close their tag early, and a missing asset is reported.
+func TestAssetTags(t *testing.T) {
+	original := bundledAssets
+	defer func() { bundledAssets = original }()
+	bundledAssets = fstest.MapFS{
+		"assets/" + bootstrapCSS.File: {Data: []byte("body{color:red}")},
+		"assets/" + jqueryJS.File:     {Data: []byte(`var s="</script>";`)},
+	}
+
+	linked, err := assetTags(false, bootstrapCSS, jqueryJS)
+	if err != nil || !strings.Contains(string(linked), `<link rel="stylesheet" href="`+bootstrapCSS.URL+`">`) || !strings.Contains(string(linked), `<script src="`+jqueryJS.URL+`">`) {
+		t.Fatalf("expected CDN tags, got %q, %v", linked, err)
+	}
+
+	inlined, err := assetTags(true, bootstrapCSS, jqueryJS)
+	if err != nil {
+		t.Fatalf("assetTags returned error: %v", err)
+	}
+	if !strings.Contains(string(inlined), "<style>body{color:red}</style>") || !strings.Contains(string(inlined), `<script>var s="<\/script>";</script>`) {
+		t.Fatalf("expected inlined assets, got %q", inlined)
+	}
+	if strings.Contains(string(inlined), "https://") {
+		t.Fatalf("expected no CDN links offline, got %q", inlined)
+	}
+
+	if _, err := assetTags(true, bootstrapJS); !errors.Is(err, ErrAssetsNotBundled) {
+		t.Fatalf("expected ErrAssetsNotBundled, got %v", err)
+	}
+}
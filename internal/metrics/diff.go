@@ -0,0 +1,192 @@
+// internal/metrics/diff.go
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// RenderDiffMarkdown renders the delta report as a Markdown table.
+func RenderDiffMarkdown(diff analysis.AnalysisDiff) string {
+	var b strings.Builder
+	b.WriteString("# Model Performance Diff\n\n")
+	b.WriteString(fmt.Sprintf("- Baseline: %s (%s)\n", markdownCell(diff.BaselineLabel), diff.BaselineGeneratedAt.Format("2006-01-02 15:04:05 MST")))
+	b.WriteString(fmt.Sprintf("- Candidate: %s (%s)\n", markdownCell(diff.CandidateLabel), diff.CandidateGeneratedAt.Format("2006-01-02 15:04:05 MST")))
+	b.WriteString(fmt.Sprintf("- Tolerance: %.1f%%\n", diff.TolerancePercent))
+	b.WriteString(fmt.Sprintf("- Regressions: %d, improvements: %d\n\n", diff.Regressions, diff.Improvements))
+
+	b.WriteString("| Model | Status | t/s | Δ t/s | TTFT (s) | Δ TTFT | Accuracy (%) | Δ accuracy | Rank | Notes |\n")
+	b.WriteString("| --- | --- | ---: | ---: | ---: | ---: | ---: | ---: | ---: | --- |\n")
+	for _, d := range diff.Models {
+		status := d.Status
+		if d.Regression {
+			status = "**regression**"
+		} else if d.Improvement {
+			status = "improvement"
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(d.ModelName), status,
+			formatDeltaSpan(d, d.BaselineTokensPerSec, d.CandidateTokensPerSec), formatDeltaPercent(d, d.TokensPerSecChangePct),
+			formatDeltaSpan(d, d.BaselineTTFTSeconds, d.CandidateTTFTSeconds), formatDeltaPercent(d, d.TTFTChangePct),
+			formatAccuracySpan(d), formatAccuracyChange(d),
+			formatRankMovement(d), markdownCell(strings.Join(d.Reasons, "; "))))
+	}
+	return b.String()
+}
+
+// formatDeltaSpan renders a "before → after" pair, using a dash for the side a model is missing from.
+func formatDeltaSpan(d analysis.ModelDelta, base, next float64) string {
+	before, after := fmt.Sprintf("%.2f", base), fmt.Sprintf("%.2f", next)
+	switch d.Status {
+	case analysis.DeltaAdded:
+		before = "—"
+	case analysis.DeltaRemoved:
+		after = "—"
+	}
+	return before + " → " + after
+}
+
+// formatDeltaPercent renders a signed percent change, or a dash when the model is not in both runs.
+func formatDeltaPercent(d analysis.ModelDelta, pct float64) string {
+	if d.Status != analysis.DeltaChanged {
+		return "—"
+	}
+	return fmt.Sprintf("%+.1f%%", pct)
+}
+
+// formatAccuracySpan renders accuracy as "before → after" percentages, using a dash for a
+// run that scored no answers.
+func formatAccuracySpan(d analysis.ModelDelta) string {
+	side := func(rate *float64) string {
+		if rate == nil {
+			return "—"
+		}
+		return fmt.Sprintf("%.1f", *rate*100)
+	}
+	if d.BaselineAccuracy == nil && d.CandidateAccuracy == nil {
+		return "—"
+	}
+	return side(d.BaselineAccuracy) + " → " + side(d.CandidateAccuracy)
+}
+
+// formatAccuracyChange renders the signed change in accuracy points, or a dash when either
+// run has no accuracy.
+func formatAccuracyChange(d analysis.ModelDelta) string {
+	if d.BaselineAccuracy == nil || d.CandidateAccuracy == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%+.1f pts", d.AccuracyChangePoints)
+}
+
+// formatRankMovement renders a rank change such as "3 → 1 (+2)".
+func formatRankMovement(d analysis.ModelDelta) string {
+	switch {
+	case d.BaselineRank == 0 && d.CandidateRank == 0:
+		return "—"
+	case d.BaselineRank == 0:
+		return fmt.Sprintf("new → %d", d.CandidateRank)
+	case d.CandidateRank == 0:
+		return fmt.Sprintf("%d → gone", d.BaselineRank)
+	default:
+		return fmt.Sprintf("%d → %d (%+d)", d.BaselineRank, d.CandidateRank, d.RankMovement)
+	}
+}
+
+// GenerateDiffReport renders the delta report as a standalone HTML page with regressions
+// highlighted. With offline set, the stylesheet is inlined rather than linked.
+func GenerateDiffReport(diff analysis.AnalysisDiff, offline bool) (string, error) {
+	styles, err := assetTags(offline, bootstrapCSS)
+	if err != nil {
+		return "", err
+	}
+	data := struct {
+		analysis.AnalysisDiff
+		Styles template.HTML
+	}{diff, styles}
+
+	var buf bytes.Buffer
+	if err := diffReportTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var diffReportTemplate = template.Must(template.New("metrics-diff").Funcs(template.FuncMap{
+	"pct":           formatDeltaPercent,
+	"span":          formatDeltaSpan,
+	"rank":          formatRankMovement,
+	"accuracy":      formatAccuracySpan,
+	"accuracyDelta": formatAccuracyChange,
+	"when":          func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"join":          strings.Join,
+}).Parse(diffReportTemplateHTML))
+
+const diffReportTemplateHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>agon: LLM Benchmark Diff</title>
+{{ .Styles }}  <style>
+    body { background-color: #f5f7fb; }
+    .card { border: none; }
+  </style>
+</head>
+<body>
+  <nav class="navbar navbar-dark bg-dark">
+    <div class="container-fluid">
+      <span class="navbar-brand mb-0 h1">agon: LLM Benchmark Diff</span>
+      <span class="text-light">Generated: {{ when .GeneratedAt }}</span>
+    </div>
+  </nav>
+  <main class="container-fluid my-4">
+    <div class="row g-3">
+      <div class="col-md-4"><div class="card shadow-sm h-100"><div class="card-body">
+        <p class="text-muted mb-1">Baseline</p>
+        <h5 class="card-title">{{ .BaselineLabel }}</h5>
+        <small class="text-muted">{{ when .BaselineGeneratedAt }}</small>
+      </div></div></div>
+      <div class="col-md-4"><div class="card shadow-sm h-100"><div class="card-body">
+        <p class="text-muted mb-1">Candidate</p>
+        <h5 class="card-title">{{ .CandidateLabel }}</h5>
+        <small class="text-muted">{{ when .CandidateGeneratedAt }}</small>
+      </div></div></div>
+      <div class="col-md-4"><div class="card shadow-sm h-100"><div class="card-body">
+        <p class="text-muted mb-1">Regressions (tolerance {{ printf "%.1f" .TolerancePercent }}%)</p>
+        <h5 class="card-title {{ if .Regressions }}text-danger{{ else }}text-success{{ end }}">{{ .Regressions }} regressed, {{ .Improvements }} improved</h5>
+      </div></div></div>
+    </div>
+    <section class="mt-4">
+      <div class="card shadow-sm"><div class="card-body">
+        <table class="table table-sm align-middle mb-0">
+          <thead>
+            <tr><th>Model</th><th>Status</th><th class="text-end">Tokens/s</th><th class="text-end">Δ</th><th class="text-end">TTFT (s)</th><th class="text-end">Δ</th><th class="text-end">Accuracy (%)</th><th class="text-end">Δ</th><th class="text-end">Efficiency rank</th><th>Notes</th></tr>
+          </thead>
+          <tbody>
+          {{- range .Models }}
+            <tr class="{{ if .Regression }}table-danger{{ else if .Improvement }}table-success{{ else if ne .Status "changed" }}table-secondary{{ end }}">
+              <td>{{ .ModelName }}</td>
+              <td>{{ if .Regression }}<span class="badge bg-danger">regression</span>{{ else if .Improvement }}<span class="badge bg-success">improvement</span>{{ else }}<span class="badge bg-secondary">{{ .Status }}</span>{{ end }}</td>
+              <td class="text-end">{{ span . .BaselineTokensPerSec .CandidateTokensPerSec }}</td>
+              <td class="text-end">{{ pct . .TokensPerSecChangePct }}</td>
+              <td class="text-end">{{ span . .BaselineTTFTSeconds .CandidateTTFTSeconds }}</td>
+              <td class="text-end">{{ pct . .TTFTChangePct }}</td>
+              <td class="text-end">{{ accuracy . }}</td>
+              <td class="text-end">{{ accuracyDelta . }}</td>
+              <td class="text-end">{{ rank . }}</td>
+              <td>{{ join .Reasons "; " }}</td>
+            </tr>
+          {{- end }}
+          </tbody>
+        </table>
+      </div></div>
+    </section>
+  </main>
+</body>
+</html>
+`
@@ -0,0 +1,109 @@
+// internal/metrics/diff_test.go
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// TestDiffAnalyses verifies that models are matched by normalized name, that changes beyond
+// the tolerance are flagged as regressions or improvements, and that added and removed
+// models are reported.
+func TestDiffAnalyses(t *testing.T) {
+	baseline := analysis.Analysis{
+		Models: []analysis.ModelAnalysis{
+			{ModelName: "Llama3.2:1B", Avg: analysis.AggregatedStats{TokensPerSecond: 100, TimeToFirstTokenSeconds: 0.5}},
+			{ModelName: "qwen3:4b", Avg: analysis.AggregatedStats{TokensPerSecond: 40, TimeToFirstTokenSeconds: 1.0}},
+			{ModelName: "old:7b", Avg: analysis.AggregatedStats{TokensPerSecond: 20}},
+		},
+		Rankings: analysis.Rankings{ByEfficiencyScore: []analysis.EfficiencyRankingEntry{{ModelName: "Llama3.2:1B"}, {ModelName: "qwen3:4b"}, {ModelName: "old:7b"}}},
+	}
+	candidate := analysis.Analysis{
+		Models: []analysis.ModelAnalysis{
+			{ModelName: "llama3.2:1b", Avg: analysis.AggregatedStats{TokensPerSecond: 80, TimeToFirstTokenSeconds: 0.5}},
+			{ModelName: "qwen3:4b", Avg: analysis.AggregatedStats{TokensPerSecond: 41, TimeToFirstTokenSeconds: 0.5}},
+			{ModelName: "new:3b", Avg: analysis.AggregatedStats{TokensPerSecond: 60}},
+		},
+		Rankings: analysis.Rankings{ByEfficiencyScore: []analysis.EfficiencyRankingEntry{{ModelName: "qwen3:4b"}, {ModelName: "new:3b"}, {ModelName: "llama3.2:1b"}}},
+	}
+
+	diff := analysis.DiffAnalyses(baseline, candidate, 5)
+	if diff.Regressions != 1 || diff.Improvements != 1 {
+		t.Fatalf("regressions=%d improvements=%d, want 1 and 1", diff.Regressions, diff.Improvements)
+	}
+
+	byName := make(map[string]analysis.ModelDelta)
+	for _, d := range diff.Models {
+		byName[d.ModelName] = d
+	}
+	if diff.Models[0].ModelName != "Llama3.2:1B" {
+		t.Fatalf("regressions should sort first, got %q", diff.Models[0].ModelName)
+	}
+	llama := byName["Llama3.2:1B"]
+	if !llama.Regression || llama.TokensPerSecChangePct != -20 || llama.RankMovement != -2 {
+		t.Fatalf("unexpected llama delta: %+v", llama)
+	}
+	qwen := byName["qwen3:4b"]
+	if !qwen.Improvement || qwen.Regression || qwen.TTFTChangePct != -50 || qwen.RankMovement != 1 {
+		t.Fatalf("unexpected qwen delta: %+v", qwen)
+	}
+	if byName["old:7b"].Status != analysis.DeltaRemoved || byName["new:3b"].Status != analysis.DeltaAdded {
+		t.Fatalf("added/removed statuses wrong: %+v", diff.Models)
+	}
+
+	md := RenderDiffMarkdown(diff)
+	if !strings.Contains(md, "**regression**") || !strings.Contains(md, "2 → 1 (+1)") {
+		t.Fatalf("markdown missing regression or rank movement:\n%s", md)
+	}
+	html, err := GenerateDiffReport(diff, false)
+	if err != nil {
+		t.Fatalf("GenerateDiffReport returned error: %v", err)
+	}
+	if !strings.Contains(html, "table-danger") {
+		t.Fatalf("HTML report should highlight regressions")
+	}
+}
+
+// TestDiffAnalysesAccuracy verifies accuracy is compared in percentage points, that a drop
+// beyond the tolerance is flagged as a regression, and that models without accuracy are
+// not compared.
+func TestDiffAnalysesAccuracy(t *testing.T) {
+	model := func(name string, rate float64) analysis.ModelAnalysis {
+		m := analysis.ModelAnalysis{ModelName: name, Avg: analysis.AggregatedStats{TokensPerSecond: 50, TimeToFirstTokenSeconds: 0.5}}
+		if rate >= 0 {
+			m.Accuracy = &analysis.AccuracyStats{AccuracyCount: analysis.AccuracyCount{Scored: 20, Correct: int(rate * 20)}, Rate: rate}
+		}
+		return m
+	}
+	baseline := analysis.Analysis{Models: []analysis.ModelAnalysis{model("llama", 0.9), model("qwen", 0.5), model("phi", -1)}}
+	candidate := analysis.Analysis{Models: []analysis.ModelAnalysis{model("llama", 0.75), model("qwen", 0.52), model("phi", 0.8)}}
+
+	diff := analysis.DiffAnalyses(baseline, candidate, 5)
+	byName := make(map[string]analysis.ModelDelta)
+	for _, d := range diff.Models {
+		byName[d.ModelName] = d
+	}
+	llama := byName["llama"]
+	if !llama.AccuracyRegression || !llama.Regression || *llama.BaselineAccuracy != 0.9 || *llama.CandidateAccuracy != 0.75 || math.Abs(llama.AccuracyChangePoints+15) > 1e-9 {
+		t.Fatalf("unexpected llama delta: %+v", llama)
+	}
+	if qwen := byName["qwen"]; qwen.Regression || qwen.AccuracyRegression || math.Abs(qwen.AccuracyChangePoints-2) > 1e-9 {
+		t.Fatalf("unexpected qwen delta: %+v", qwen)
+	}
+	if phi := byName["phi"]; phi.Regression || phi.Improvement || phi.BaselineAccuracy != nil || phi.AccuracyChangePoints != 0 {
+		t.Fatalf("unexpected phi delta: %+v", phi)
+	}
+	if diff.Regressions != 1 {
+		t.Fatalf("regressions=%d, want 1", diff.Regressions)
+	}
+
+	md := RenderDiffMarkdown(diff)
+	for _, want := range []string{"90.0 → 75.0", "-15.0 pts", "accuracy down 15.0 points", "— → 80.0"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+}
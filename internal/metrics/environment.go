@@ -0,0 +1,138 @@
+// internal/metrics/environment.go
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// EnvironmentFileSuffix is appended to a result file's stem to name its environment snapshot.
+const EnvironmentFileSuffix = ".env.json"
+
+// environmentPaths locates the Linux files read for governor, load and GPU driver details.
+// Tests point these at fixtures.
+var environmentPaths = struct {
+	governor, loadavg, nvidiaDriver string
+}{
+	governor:     "/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor",
+	loadavg:      "/proc/loadavg",
+	nvidiaDriver: "/proc/driver/nvidia/version",
+}
+
+// CaptureEnvironment snapshots the local machine and asks every Ollama host for its
+// server version. Unreachable hosts are recorded with their error rather than failing.
+func CaptureEnvironment(ctx context.Context, agonVersion string, hosts []appconfig.Host, timeout time.Duration) analysis.EnvironmentSnapshot {
+	snapshot := analysis.EnvironmentSnapshot{
+		CapturedAt:  time.Now().UTC(),
+		AgonVersion: agonVersion,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		NumCPU:      runtime.NumCPU(),
+		CPUGovernor: readFirstLine(environmentPaths.governor),
+		LoadAverage: loadAverage(readFirstLine(environmentPaths.loadavg)),
+		GPUDriver:   readFirstLine(environmentPaths.nvidiaDriver),
+	}
+	if name, err := os.Hostname(); err == nil {
+		snapshot.Hostname = name
+	}
+
+	clients := providers.NewHostClients(&http.Client{Timeout: timeout})
+	for _, host := range hosts {
+		pv := analysis.ProviderVersion{Host: host.Name, URL: host.URL, Type: host.Type}
+		if host.Type == "ollama" {
+			client, err := clients.For(host)
+			if err == nil {
+				pv.Version, err = ollamaVersion(ctx, client, host.URL)
+			}
+			if err != nil {
+				pv.Error = err.Error()
+			}
+		}
+		snapshot.Providers = append(snapshot.Providers, pv)
+	}
+	return snapshot
+}
+
+// EnvironmentPathFor returns the snapshot path that sits beside a result file.
+func EnvironmentPathFor(resultPath string) string {
+	return strings.TrimSuffix(resultPath, filepath.Ext(resultPath)) + EnvironmentFileSuffix
+}
+
+// WriteEnvironment writes a snapshot as indented JSON.
+func WriteEnvironment(path string, snapshot analysis.EnvironmentSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal environment snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("unable to write environment snapshot %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadEnvironment reads a snapshot written by WriteEnvironment.
+func LoadEnvironment(path string) (analysis.EnvironmentSnapshot, error) {
+	var snapshot analysis.EnvironmentSnapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("unable to read environment snapshot %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("unable to parse environment snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// ollamaVersion queries an Ollama server's /api/version endpoint.
+func ollamaVersion(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/api/version", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var payload struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode version: %w", err)
+	}
+	return payload.Version, nil
+}
+
+// readFirstLine returns the trimmed first line of a file, or "" when it cannot be read.
+func readFirstLine(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line)
+}
+
+// loadAverage keeps the 1, 5 and 15 minute averages from a /proc/loadavg line.
+func loadAverage(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.Join(fields[:3], " ")
+}
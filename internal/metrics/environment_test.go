@@ -0,0 +1,84 @@
+// internal/metrics/environment_test.go
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestCaptureEnvironment verifies that host versions, governor, load and GPU driver are
+// captured, that unreachable hosts are recorded rather than failing, and that the snapshot
+// round-trips through its sidecar file and into the analysis summary.
+func TestCaptureEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"0.6.2"}`))
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	dir := t.TempDir()
+	writeFixture := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	saved := environmentPaths
+	defer func() { environmentPaths = saved }()
+	environmentPaths.governor = writeFixture("governor", "powersave\n")
+	environmentPaths.loadavg = writeFixture("loadavg", "3.10 2.05 1.00 2/900 1234\n")
+	environmentPaths.nvidiaDriver = filepath.Join(dir, "missing")
+
+	hosts := []appconfig.Host{
+		{Name: "gpu1", URL: server.URL, Type: "ollama"},
+		{Name: "gpu2", URL: down.URL, Type: "ollama"},
+	}
+	env := CaptureEnvironment(context.Background(), "v1.2.3", hosts, time.Second)
+
+	if env.AgonVersion != "v1.2.3" || env.CPUGovernor != "powersave" || env.LoadAverage != "3.10 2.05 1.00" || env.GPUDriver != "" {
+		t.Fatalf("unexpected local snapshot: %+v", env)
+	}
+	if len(env.Providers) != 2 || env.Providers[0].Version != "0.6.2" || env.Providers[1].Error == "" {
+		t.Fatalf("unexpected provider versions: %+v", env.Providers)
+	}
+
+	resultPath := filepath.Join(dir, "llama3+qwen3-5.json")
+	envPath := EnvironmentPathFor(resultPath)
+	if filepath.Base(envPath) != "llama3+qwen3-5.env.json" {
+		t.Fatalf("EnvironmentPathFor = %s", envPath)
+	}
+	if err := WriteEnvironment(envPath, env); err != nil {
+		t.Fatalf("WriteEnvironment returned error: %v", err)
+	}
+	loaded, err := LoadEnvironment(envPath)
+	if err != nil {
+		t.Fatalf("LoadEnvironment returned error: %v", err)
+	}
+
+	var result analysis.Analysis
+	result.AttachEnvironment(loaded)
+	summary := strings.Join(result.EnvironmentSummary, "\n")
+	for _, want := range []string{"CPU governor: powersave", "gpu1: ollama 0.6.2", "gpu2: version unavailable"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if !strings.Contains(RenderMarkdown(result), "## Environment") {
+		t.Fatalf("markdown report should include the environment")
+	}
+}
@@ -0,0 +1,140 @@
+// internal/metrics/export.go
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// modelsCSVHeader lists the columns written by WriteModelsCSV.
+var modelsCSVHeader = []string{
+	"model", "benchmark_count",
+	"avg_tokens_per_second", "min_tokens_per_second", "max_tokens_per_second", "p95_tokens_per_second",
+	"avg_ttft_seconds", "p95_ttft_seconds", "avg_total_seconds",
+	"avg_input_tokens", "avg_output_tokens",
+	"tokens_per_second_stddev", "ttft_stddev_seconds",
+	"throughput_score", "latency_score", "efficiency_score",
+	"speed_tier", "latency_profile", "stability", "interactive_suitability",
+}
+
+// WriteModelsCSV writes one row per analyzed model with its aggregates, scores and labels.
+func WriteModelsCSV(w io.Writer, result analysis.Analysis) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(modelsCSVHeader); err != nil {
+		return err
+	}
+	for _, m := range result.Models {
+		record := []string{
+			m.ModelName, strconv.Itoa(m.BenchmarkCount),
+			formatFloat(m.Avg.TokensPerSecond), formatFloat(m.Min.TokensPerSecond), formatFloat(m.Max.TokensPerSecond), formatFloat(m.P95.TokensPerSecond),
+			formatFloat(m.Avg.TimeToFirstTokenSeconds), formatFloat(m.P95.TimeToFirstTokenSeconds), formatFloat(m.Avg.TotalExecutionTimeSeconds),
+			formatFloat(m.Avg.InputTokens), formatFloat(m.Avg.OutputTokens),
+			formatFloat(m.Variance.TokensPerSecondStdDev), formatFloat(m.Variance.TimeToFirstTokenStdDevSeconds),
+			formatFloat(m.Scores.ThroughputScore), formatFloat(m.Scores.LatencyScore), formatFloat(m.Scores.EfficiencyScore),
+			m.Labels.RelativeSpeedTier, m.Labels.LatencyProfile, m.Labels.Stability, m.Labels.InteractiveSuitability,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteIterationsCSV writes one row per recorded benchmark iteration across all models.
+func WriteIterationsCSV(w io.Writer, result analysis.Analysis) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"model", "iteration", "tokens_per_second", "ttft_seconds", "total_seconds", "output_tokens"}); err != nil {
+		return err
+	}
+	for _, m := range result.Models {
+		for _, it := range m.Iterations {
+			record := []string{
+				m.ModelName, strconv.Itoa(it.Iteration),
+				formatFloat(it.TokensPerSecond), formatFloat(it.TimeToFirstTokenSeconds), formatFloat(it.TotalExecutionTimeSeconds),
+				strconv.Itoa(it.OutputTokens),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// RenderMarkdown renders a summary of the analysis suitable for pasting into a wiki.
+func RenderMarkdown(result analysis.Analysis) string {
+	var b strings.Builder
+	b.WriteString("# Model Performance Analysis\n\n")
+	b.WriteString(fmt.Sprintf("- Generated: %s\n", result.GeneratedAt.Format("2006-01-02 15:04:05 MST")))
+	if result.HostInfo.ClusterName != "" {
+		b.WriteString(fmt.Sprintf("- Host: %s\n", markdownCell(result.HostInfo.ClusterName)))
+	}
+	if result.HostInfo.Notes != "" {
+		b.WriteString(fmt.Sprintf("- Notes: %s\n", markdownCell(result.HostInfo.Notes)))
+	}
+	b.WriteString(fmt.Sprintf("- Fastest: %s\n", result.Overall.FastestModel))
+	b.WriteString(fmt.Sprintf("- Best latency: %s\n", result.Overall.BestLatencyModel))
+	b.WriteString(fmt.Sprintf("- Most efficient: %s\n\n", result.Overall.MostEfficientModel))
+
+	b.WriteString("## Models\n\n")
+	b.WriteString("| Model | Runs | Avg t/s | P95 TTFT (s) | Avg total (s) | Efficiency | Speed | Stability | Interactive |\n")
+	b.WriteString("| --- | ---: | ---: | ---: | ---: | ---: | --- | --- | --- |\n")
+	for _, m := range result.Models {
+		b.WriteString(fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f | %s | %s | %s |\n",
+			markdownCell(m.ModelName), m.BenchmarkCount, m.Avg.TokensPerSecond, m.P95.TimeToFirstTokenSeconds,
+			m.Avg.TotalExecutionTimeSeconds, m.Scores.EfficiencyScore,
+			m.Labels.RelativeSpeedTier, m.Labels.Stability, m.Labels.InteractiveSuitability))
+	}
+
+	if len(result.Anomalies) > 0 {
+		b.WriteString("\n## Anomalies\n\n")
+		b.WriteString("| Severity | Model | Type | Message |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, a := range result.Anomalies {
+			message := a.Message
+			if a.Acknowledgement != "" {
+				message += fmt.Sprintf(" (acknowledged, was %s: %s)", a.OriginalSeverity, a.Acknowledgement)
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", a.Severity, markdownCell(a.ModelName), a.Type, markdownCell(message)))
+		}
+	}
+
+	if len(result.SuppressedAnomalies) > 0 {
+		b.WriteString("\n## Acknowledged Anomalies\n\n")
+		for _, a := range result.SuppressedAnomalies {
+			b.WriteString(fmt.Sprintf("- %s (%s, %s): %s\n", markdownCell(a.ModelName), a.Type, a.Severity, markdownCell(a.Acknowledgement)))
+		}
+	}
+
+	if len(result.EnvironmentSummary) > 0 {
+		b.WriteString("\n## Environment\n\n")
+		for _, line := range result.EnvironmentSummary {
+			b.WriteString("- " + markdownCell(line) + "\n")
+		}
+	}
+
+	if len(result.Recommendations) > 0 {
+		b.WriteString("\n## Recommendations\n\n")
+		for _, r := range result.Recommendations {
+			b.WriteString("- " + r + "\n")
+		}
+	}
+	return b.String()
+}
+
+// formatFloat renders a float for CSV output with fixed precision.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// markdownCell escapes pipes and collapses whitespace so text stays inside a table cell.
+func markdownCell(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "|", `\|`)), " ")
+}
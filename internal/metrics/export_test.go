@@ -0,0 +1,74 @@
+// internal/metrics/export_test.go
+package metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// TestAnalysisExports verifies the CSV tables carry one row per model and per iteration
+// and that the Markdown summary escapes table cells and lists anomalies.
+func TestAnalysisExports(t *testing.T) {
+	result := analysis.Analysis{
+		Overall: analysis.OverallSummary{FastestModel: "fast:1b"},
+		Models: []analysis.ModelAnalysis{
+			{
+				ModelName:      "fast:1b",
+				BenchmarkCount: 2,
+				Avg:            analysis.AggregatedStats{TokensPerSecond: 42.5},
+				Labels:         analysis.LabelStats{Stability: "stable"},
+				Iterations:     []analysis.IterationSample{{Iteration: 1, TokensPerSecond: 40}, {Iteration: 2, TokensPerSecond: 45}},
+			},
+			{ModelName: "odd|name", BenchmarkCount: 1},
+		},
+		Anomalies: []analysis.Anomaly{{Type: "variance", ModelName: "fast:1b", Severity: "warning", Message: "noisy\nruns"}},
+	}
+
+	var models bytes.Buffer
+	if err := WriteModelsCSV(&models, result); err != nil {
+		t.Fatalf("WriteModelsCSV returned error: %v", err)
+	}
+	rows, err := csv.NewReader(&models).ReadAll()
+	if err != nil {
+		t.Fatalf("parse models csv: %v", err)
+	}
+	if len(rows) != 3 || rows[1][0] != "fast:1b" || rows[1][2] != "42.5000" || rows[2][0] != "odd|name" {
+		t.Fatalf("unexpected models csv: %v", rows)
+	}
+
+	var iterations bytes.Buffer
+	if err := WriteIterationsCSV(&iterations, result); err != nil {
+		t.Fatalf("WriteIterationsCSV returned error: %v", err)
+	}
+	rows, err = csv.NewReader(&iterations).ReadAll()
+	if err != nil {
+		t.Fatalf("parse iterations csv: %v", err)
+	}
+	if len(rows) != 3 || rows[2][1] != "2" {
+		t.Fatalf("unexpected iterations csv: %v", rows)
+	}
+
+	markdown := RenderMarkdown(result)
+	for _, want := range []string{"| fast:1b | 2 | 42.50 |", `| odd\|name |`, "| warning | fast:1b | variance | noisy runs |"} {
+		if !strings.Contains(markdown, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+// TestRenderMarkdownAcknowledgements verifies that the Markdown summary notes demoted
+// anomalies and lists suppressed ones.
+func TestRenderMarkdownAcknowledgements(t *testing.T) {
+	result := analysis.Analysis{
+		Anomalies:           []analysis.Anomaly{{Type: "high_variance", ModelName: "small:1b", Severity: "info", OriginalSeverity: "warning", Acknowledgement: "shared GPU"}},
+		SuppressedAnomalies: []analysis.Anomaly{{Type: "very_high_latency", ModelName: "big:70b", Severity: "critical", Acknowledgement: "CPU-only host"}},
+	}
+	md := RenderMarkdown(result)
+	if !strings.Contains(md, "acknowledged, was warning: shared GPU") || !strings.Contains(md, "## Acknowledged Anomalies") {
+		t.Fatalf("markdown report does not note the acknowledgements:\n%s", md)
+	}
+}
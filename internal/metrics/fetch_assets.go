@@ -0,0 +1,90 @@
+// internal/metrics/fetch_assets.go
+//go:build ignore
+
+// fetch_assets downloads the HTML reports' stylesheets and scripts into assets/ so they
+// are embedded for offline reports. Fonts a stylesheet references are inlined as data
+// URIs, and source map comments are dropped since the maps are not bundled.
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/metrics"
+)
+
+// userAgent asks Google Fonts for woff2 fonts, which every current browser supports.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36"
+
+var (
+	cssURL    = regexp.MustCompile(`url\((['"]?)(https?://[^)'"]+)['"]?\)`)
+	sourceMap = regexp.MustCompile(`(?m)^\s*(//|/\*)# sourceMappingURL=.*$`)
+)
+
+func main() {
+	client := &http.Client{Timeout: time.Minute}
+	for _, asset := range metrics.ReportAssets {
+		body, _, err := fetch(client, asset.URL)
+		if err != nil {
+			log.Fatalf("fetching %s: %v", asset.File, err)
+		}
+		content := sourceMap.ReplaceAllString(string(body), "")
+		if strings.HasSuffix(asset.File, ".css") {
+			if content, err = inlineURLs(client, content); err != nil {
+				log.Fatalf("inlining fonts for %s: %v", asset.File, err)
+			}
+		}
+		target := filepath.Join("assets", asset.File)
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			log.Fatalf("writing %s: %v", target, err)
+		}
+		log.Printf("%s: %d bytes from %s", target, len(content), asset.URL)
+	}
+}
+
+// inlineURLs replaces the absolute url() references in css with data URIs.
+func inlineURLs(client *http.Client, css string) (string, error) {
+	var fetchErr error
+	inlined := cssURL.ReplaceAllStringFunc(css, func(match string) string {
+		ref := cssURL.FindStringSubmatch(match)[2]
+		body, contentType, err := fetch(client, ref)
+		if err != nil {
+			fetchErr = err
+			return match
+		}
+		if contentType == "" || strings.HasPrefix(contentType, "application/octet-stream") {
+			contentType = mime.TypeByExtension(path.Ext(ref))
+		}
+		return fmt.Sprintf("url(data:%s;base64,%s)", contentType, base64.StdEncoding.EncodeToString(body))
+	})
+	return inlined, fetchErr
+}
+
+// fetch downloads url and returns its body and content type.
+func fetch(client *http.Client, url string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	return body, resp.Header.Get("Content-Type"), err
+}
@@ -0,0 +1,71 @@
+// internal/metrics/history.go
+package metrics
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// AnalysisRun describes an analysis JSON document found in the run history.
+type AnalysisRun struct {
+	// ID is the document's path relative to the history directory, with forward slashes.
+	ID          string
+	Path        string
+	GeneratedAt time.Time
+	ClusterName string
+	Models      []string
+}
+
+// Name returns the run's file name without its extension: the run ID for analyses that
+// `agon analyze metrics` archives.
+func (r AnalysisRun) Name() string {
+	return strings.TrimSuffix(path.Base(r.ID), ".json")
+}
+
+// ListAnalysisRuns finds the analysis documents under dir and its subdirectories, newest
+// first. JSON files that are not analyses, such as benchmark results or manifests, are
+// skipped, and a missing dir has no runs.
+func ListAnalysisRuns(dir string) ([]AnalysisRun, error) {
+	var runs []AnalysisRun
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		result, err := analysis.LoadAnalysis(path)
+		if err != nil || len(result.Models) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		run := AnalysisRun{ID: filepath.ToSlash(rel), Path: path, GeneratedAt: result.GeneratedAt, ClusterName: result.HostInfo.ClusterName}
+		for _, model := range result.Models {
+			run.Models = append(run.Models, model.ModelName)
+		}
+		runs = append(runs, run)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read analysis history %s: %w", dir, err)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].GeneratedAt.After(runs[j].GeneratedAt)
+	})
+	return runs, nil
+}
@@ -0,0 +1,195 @@
+// internal/metrics/i18n.go
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultReportLanguage is the language of the built-in report messages.
+const DefaultReportLanguage = "en"
+
+// ReportMessages maps message keys to the UI strings shown in the HTML report.
+type ReportMessages map[string]string
+
+// defaultReportMessages holds the English UI strings every other language falls back to.
+// Keys starting with "label." translate the speed tier, suitability and severity values
+// shown in badges and table cells.
+var defaultReportMessages = ReportMessages{
+	"title":                  "agon: LLM Benchmark Report",
+	"filterModels":           "Filter models…",
+	"toggleTheme":            "Toggle theme",
+	"environment":            "Environment",
+	"runEnvironment":         "Run environment:",
+	"generated":              "Generated:",
+	"fastestModel":           "Fastest Model",
+	"bestLatency":            "Best Latency",
+	"mostEfficient":          "Most Efficient",
+	"interactiveReady":       "Interactive-Ready Models",
+	"modelComparison":        "Model Comparison",
+	"colModel":               "Model",
+	"colAvgTPS":              "Avg TPS",
+	"colAvgTTFT":             "Avg TTFT (s)",
+	"colAvgTotal":            "Avg Total (s)",
+	"colAvgOutputTokens":     "Avg Output Tokens",
+	"colThroughputScore":     "Throughput Score",
+	"colLatencyScore":        "Latency Score",
+	"colEfficiencyScore":     "Efficiency Score",
+	"colSpeedTier":           "Speed Tier",
+	"colSuitability":         "Suitability",
+	"colJudgeDisagreement":   "Judge Disagreement (%)",
+	"colDisagreementHelp":    "Share of answers a second judge graded differently; highlighted when some questions' grading is unreliable.",
+	"percentilesTitle":       "Percentiles",
+	"percentilesHelp":        "Per-iteration percentiles of time to first token, total time (seconds) and tokens/sec, so tail latency is visible beside the averages.",
+	"templatesTitle":         "Parameter Templates",
+	"templatesHelp":          "Each model's iterations grouped by parameter template. Deltas compare with the model's first template; the best template is the most accurate, then the fastest.",
+	"colTemplate":            "Template",
+	"colIterations":          "Iterations",
+	"colAccuracy":            "Accuracy (%)",
+	"colDelta":               "Δ",
+	"templateWinner":         "Best template",
+	"templateBaseline":       "baseline",
+	"toolsTitle":             "Tool Use",
+	"toolsHelp":              "How often each model called MCP tools in a run with tools. Missed counts questions the suite marks as needing a tool that were answered without one; unnecessary counts tool calls for questions that need none. Accuracy and total time compare answers that called a tool with the rest.",
+	"colToolRate":            "Called Tools (%)",
+	"colToolCalls":           "Calls",
+	"colToolMissed":          "Missed",
+	"colToolUnnecessary":     "Unnecessary",
+	"colAccuracyWithTool":    "Accuracy with Tool (%)",
+	"colAccuracyWithoutTool": "Accuracy without (%)",
+	"colTotalWithTool":       "Total with Tool (s)",
+	"colTotalWithoutTool":    "Total without (s)",
+	"distributionTitle":      "Tokens/sec Distribution",
+	"distributionHelp":       "Box plots of per-iteration tokens/sec on a shared scale. Whiskers extend to 1.5× IQR; points beyond them are outliers.",
+	"distributionEmpty":      "No per-iteration data available; distributions require benchmark results with iterations.",
+	"distributionMedian":     "median",
+	"distributionIQR":        "IQR",
+	"perModelDetails":        "Per-Model Details",
+	"averageStats":           "Average Stats",
+	"tokensPerSecond":        "Tokens/sec:",
+	"ttftSeconds":            "TTFT (s):",
+	"totalSeconds":           "Total (s):",
+	"outputTokens":           "Output tokens:",
+	"variance":               "Variance",
+	"tpsStdDev":              "TPS σ:",
+	"ttftStdDev":             "TTFT σ (s):",
+	"outputStdDev":           "Output σ:",
+	"extremes":               "Extremes",
+	"minTPS":                 "Min TPS:",
+	"maxTPS":                 "Max TPS:",
+	"minTTFT":                "Min TTFT (s):",
+	"maxTTFT":                "Max TTFT (s):",
+	"ratiosAndNotes":         "Ratios & Notes",
+	"latencyShare":           "Latency share:",
+	"relativeToFastest":      "Relative to fastest:",
+	"noNotes":                "No significant notes for this model.",
+	"latencyStrip":           "Latency by Question",
+	"latencyStripHelp":       "Total seconds per iteration, ordered by question; darker cells are slower. Click a cell to see its iteration.",
+	"iterationDetails":       "Iterations",
+	"colQuestion":            "Question",
+	"colIteration":           "Iteration",
+	"colSystemPrompt":        "System Prompt",
+	"colTotal":               "Total (s)",
+	"colTTFT":                "TTFT (s)",
+	"colTPS":                 "TPS",
+	"colCorrect":             "Correct",
+	"anomalies":              "Anomalies",
+	"noAnomalies":            "No anomalies detected.",
+	"acknowledged":           "Acknowledged:",
+	"suppressedAnomalies":    "Acknowledged anomalies suppressed:",
+	"recommendations":        "Recommendations",
+	"noRecommendations":      "No recommendations generated.",
+	"label.top":              "top",
+	"label.mid":              "mid",
+	"label.slow":             "slow",
+	"label.good":             "good",
+	"label.borderline":       "borderline",
+	"label.unusable":         "unusable",
+	"label.info":             "info",
+	"label.warning":          "warning",
+	"label.critical":         "critical",
+}
+
+// DefaultReportMessages returns a copy of the built-in English report messages.
+func DefaultReportMessages() ReportMessages {
+	messages := make(ReportMessages, len(defaultReportMessages))
+	for key, text := range defaultReportMessages {
+		messages[key] = text
+	}
+	return messages
+}
+
+// ResolveReportMessages returns the messages for language, laid over the English
+// defaults so a translation only needs the keys it changes. English needs no catalog;
+// any other language must be present in the YAML or JSON catalog at path, which maps
+// language codes to their messages.
+func ResolveReportMessages(language, path string) (ReportMessages, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	messages := DefaultReportMessages()
+	if strings.TrimSpace(path) == "" {
+		if language == "" || language == DefaultReportLanguage {
+			return messages, nil
+		}
+		return nil, fmt.Errorf("report language %q needs a messages catalog (set reportMessages in the config)", language)
+	}
+	if language == "" {
+		language = DefaultReportLanguage
+	}
+
+	catalog, err := loadReportCatalog(path)
+	if err != nil {
+		return nil, err
+	}
+	translated, ok := catalog[language]
+	if !ok {
+		if language == DefaultReportLanguage {
+			return messages, nil
+		}
+		return nil, fmt.Errorf("report messages %s have no %q entry (available: %s)", path, language, strings.Join(catalogLanguages(catalog), ", "))
+	}
+	for key, text := range translated {
+		if _, known := defaultReportMessages[key]; !known {
+			return nil, fmt.Errorf("report messages %s: unknown key %q for %q", path, key, language)
+		}
+		messages[key] = text
+	}
+	return messages, nil
+}
+
+// loadReportCatalog reads a language → messages catalog from YAML or JSON.
+func loadReportCatalog(path string) (map[string]ReportMessages, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read report messages %s: %w", path, err)
+	}
+	var raw map[string]ReportMessages
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse report messages %s: %w", path, err)
+	}
+	catalog := make(map[string]ReportMessages, len(raw))
+	for language, messages := range raw {
+		catalog[strings.ToLower(strings.TrimSpace(language))] = messages
+	}
+	return catalog, nil
+}
+
+// catalogLanguages returns the catalog's language codes, sorted.
+func catalogLanguages(catalog map[string]ReportMessages) []string {
+	languages := make([]string, 0, len(catalog))
+	for language := range catalog {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
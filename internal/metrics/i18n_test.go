@@ -0,0 +1,74 @@
+// internal/metrics/i18n_test.go
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// TestResolveReportMessages verifies that a translation is laid over the English defaults
+// and that unknown keys and missing languages are rejected.
+func TestResolveReportMessages(t *testing.T) {
+	messages, err := ResolveReportMessages("", "")
+	if err != nil || messages["title"] != defaultReportMessages["title"] {
+		t.Fatalf("default messages = %v, %v", messages["title"], err)
+	}
+	if _, err := ResolveReportMessages("es", ""); err == nil {
+		t.Fatalf("expected an error for a non-English language without a catalog")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "messages.yaml")
+	catalog := "es:\n  title: \"Informe\"\n  label.top: \"alto\"\n"
+	if err := os.WriteFile(path, []byte(catalog), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	messages, err = ResolveReportMessages("ES", path)
+	if err != nil {
+		t.Fatalf("ResolveReportMessages returned error: %v", err)
+	}
+	if messages["title"] != "Informe" || messages["label.top"] != "alto" {
+		t.Fatalf("translation not applied: %v", messages)
+	}
+	if messages["anomalies"] != "Anomalies" {
+		t.Fatalf("missing key did not fall back to English: %q", messages["anomalies"])
+	}
+	if _, err := ResolveReportMessages("fr", path); err == nil || !strings.Contains(err.Error(), "es") {
+		t.Fatalf("expected a missing-language error listing es, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"es": {"titel": "Informe"}}`), 0o644); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+	if _, err := ResolveReportMessages("es", bad); err == nil {
+		t.Fatalf("expected an error for an unknown message key")
+	}
+}
+
+// TestGenerateLocalizedReport verifies that the rendered report carries the language and
+// translated strings while untranslated ones stay in English.
+func TestGenerateLocalizedReport(t *testing.T) {
+	messages := ReportMessages{"title": "Informe de rendimiento", "modelComparison": "Comparación de modelos"}
+	html, err := GenerateLocalizedReport(analysis.Analysis{}, "es", messages)
+	if err != nil {
+		t.Fatalf("GenerateLocalizedReport returned error: %v", err)
+	}
+	for _, want := range []string{`<html lang="es">`, "<title>Informe de rendimiento</title>", "Comparación de modelos", "Per-Model Details"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("report missing %q", want)
+		}
+	}
+
+	english, err := GenerateReport(analysis.Analysis{})
+	if err != nil {
+		t.Fatalf("GenerateReport returned error: %v", err)
+	}
+	if !strings.Contains(english, `<html lang="en">`) || !strings.Contains(english, "Model Comparison") {
+		t.Fatalf("default report is not in English")
+	}
+}
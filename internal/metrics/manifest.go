@@ -0,0 +1,120 @@
+// internal/metrics/manifest.go
+package metrics
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFileSuffix replaces a report's extension to name the run manifest written beside
+// it, so reports sharing a directory keep their own manifests.
+const ManifestFileSuffix = ".manifest.json"
+
+// ManifestFile identifies a single input or output artifact by path and content hash.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// RunManifest links the inputs of an analysis run to every artifact it produced.
+type RunManifest struct {
+	RunID       string         `json:"runId"`
+	Command     string         `json:"command"`
+	AgonVersion string         `json:"agonVersion"`
+	StartedAt   time.Time      `json:"startedAt"`
+	CompletedAt time.Time      `json:"completedAt"`
+	Inputs      []ManifestFile `json:"inputs"`
+	Outputs     []ManifestFile `json:"outputs"`
+}
+
+// NewRunManifest starts a manifest for the named command and agon version.
+func NewRunManifest(command, version string) *RunManifest {
+	started := time.Now().UTC()
+	return &RunManifest{
+		RunID:       newRunID(started),
+		Command:     command,
+		AgonVersion: version,
+		StartedAt:   started,
+		Inputs:      []ManifestFile{},
+		Outputs:     []ManifestFile{},
+	}
+}
+
+// AddInput hashes path and records it as an input of the run.
+func (m *RunManifest) AddInput(path string) error {
+	file, err := describeManifestFile(path)
+	if err != nil {
+		return err
+	}
+	m.Inputs = append(m.Inputs, file)
+	return nil
+}
+
+// AddOutput hashes path and records it as an output of the run.
+func (m *RunManifest) AddOutput(path string) error {
+	file, err := describeManifestFile(path)
+	if err != nil {
+		return err
+	}
+	m.Outputs = append(m.Outputs, file)
+	return nil
+}
+
+// Write stamps the completion time and writes the manifest beside reportPath, returning
+// the manifest's path.
+func (m *RunManifest) Write(reportPath string) (string, error) {
+	m.CompletedAt = time.Now().UTC()
+	path := ManifestPathFor(reportPath)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("unable to create manifest directory %s: %w", dir, err)
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal run manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("unable to write run manifest %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// ManifestPathFor returns the run manifest written beside a report.
+func ManifestPathFor(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ManifestFileSuffix
+}
+
+// describeManifestFile computes the size and SHA-256 of the file at path.
+func describeManifestFile(path string) (ManifestFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ManifestFile{}, fmt.Errorf("unable to open %s for manifest: %w", path, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, f)
+	if err != nil {
+		return ManifestFile{}, fmt.Errorf("unable to hash %s for manifest: %w", path, err)
+	}
+	return ManifestFile{Path: filepath.ToSlash(path), SHA256: hex.EncodeToString(hash.Sum(nil)), Bytes: n}, nil
+}
+
+// newRunID builds a sortable run identifier from the start time plus a random suffix.
+func newRunID(started time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return started.Format("20060102T150405Z")
+	}
+	return started.Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,52 @@
+// internal/metrics/manifest_test.go
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunManifestWrite verifies two reports written to the same directory each get their own
+// manifest, named after the report and listing only that report's outputs.
+func TestRunManifestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	write := func(name string) string {
+		report := filepath.Join(dir, name)
+		if err := os.WriteFile(report, []byte("<html>"+name+"</html>"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		manifest := NewRunManifest("analyze metrics", "test")
+		if err := manifest.AddOutput(report); err != nil {
+			t.Fatalf("add output: %v", err)
+		}
+		path, err := manifest.Write(report)
+		if err != nil {
+			t.Fatalf("write manifest: %v", err)
+		}
+		return path
+	}
+
+	nightly := write("nightly.html")
+	tuning := write("tuning.html")
+	if nightly != filepath.Join(dir, "nightly.manifest.json") || tuning != filepath.Join(dir, "tuning.manifest.json") {
+		t.Fatalf("unexpected manifest paths %s and %s", nightly, tuning)
+	}
+	for path, report := range map[string]string{nightly: "nightly.html", tuning: "tuning.html"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		var manifest RunManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		if len(manifest.Outputs) != 1 || filepath.Base(manifest.Outputs[0].Path) != report || manifest.CompletedAt.IsZero() {
+			t.Fatalf("unexpected manifest %s: %+v", path, manifest)
+		}
+	}
+}
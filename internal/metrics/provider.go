@@ -12,10 +12,8 @@ import (
 
 // Provider is a decorator that wraps a ChatProvider to record metrics.
 type Provider struct {
-	wrapped        providers.ChatProvider
-	aggregator     *Aggregator
-	startTime      time.Time
-	firstChunkTime time.Time
+	wrapped    providers.ChatProvider
+	aggregator *Aggregator
 }
 
 // NewProvider creates a new metrics-enabled provider that wraps an existing ChatProvider.
@@ -27,13 +25,12 @@ func NewProvider(wrapped providers.ChatProvider, aggregator *Aggregator) *Provid
 // Stream intercepts the call to the wrapped provider's Stream method to record performance metrics.
 func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
 	logging.LogMetricsEvent("[METRICS] Stream called on metrics provider for model %s", req.Model)
-	p.startTime = time.Now()
-	firstChunkReceived := false
+	startTime := time.Now()
+	var firstChunkTime time.Time
 
 	onChunk := func(chunk providers.ChatMessage) error {
-		if !firstChunkReceived {
-			p.firstChunkTime = time.Now()
-			firstChunkReceived = true
+		if firstChunkTime.IsZero() {
+			firstChunkTime = time.Now()
 		}
 		if callbacks.OnChunk != nil {
 			return callbacks.OnChunk(chunk)
@@ -44,9 +41,15 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	onComplete := func(meta providers.StreamMetadata) error {
 		logging.LogMetricsEvent("[METRICS] onComplete called for model %s", meta.Model)
 		if p.aggregator != nil {
-			ttft := int64(0)
-			if firstChunkReceived {
-				ttft = p.firstChunkTime.Sub(p.startTime).Milliseconds()
+			// Transport-level timestamps from the provider exclude callback scheduling jitter;
+			// fall back to wall-clock at the first chunk when they are unavailable.
+			ttft := meta.TimeToFirstToken().Milliseconds()
+			if ttft == 0 && !firstChunkTime.IsZero() {
+				ttft = (firstChunkTime.Sub(startTime) - meta.QueueWait).Milliseconds()
+			}
+			// A scheduler in front of this provider reports its wait on the request.
+			if meta.QueueWait == 0 {
+				meta.QueueWait = req.QueueWait
 			}
 			p.aggregator.Record(meta, ttft)
 		}
@@ -62,7 +65,16 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 		OnComplete: onComplete,
 	}
 
-	return p.wrapped.Stream(ctx, req, newCallbacks)
+	err := p.wrapped.Stream(ctx, req, newCallbacks)
+	if err != nil && p.aggregator != nil {
+		p.aggregator.RecordError(req.Model, err)
+	}
+	return err
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() providers.ChatProvider {
+	return p.wrapped
 }
 
 // LoadedModels passes the call through to the wrapped provider.
@@ -70,6 +82,17 @@ func (p *Provider) LoadedModels(ctx context.Context, host appconfig.Host) ([]str
 	return p.wrapped.LoadedModels(ctx, host)
 }
 
+// HostLoad passes the call through to the wrapped provider, if it can report load.
+func (p *Provider) HostLoad(ctx context.Context, host appconfig.Host) (providers.HostLoad, error) {
+	load, _, err := providers.ReportHostLoad(ctx, p.wrapped, host)
+	return load, err
+}
+
+// ModelCapabilities passes the call through to the wrapped provider, if it can report them.
+func (p *Provider) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (providers.Capabilities, error) {
+	return providers.ReportModelCapabilities(ctx, p.wrapped, host, model)
+}
+
 // EnsureModelReady passes the call through to the wrapped provider.
 func (p *Provider) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
 	return p.wrapped.EnsureModelReady(ctx, host, model)
@@ -0,0 +1,392 @@
+// internal/metrics/report.go
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// ReportOptions controls how the HTML report is rendered.
+type ReportOptions struct {
+	// Language is the report's html lang attribute; empty means DefaultReportLanguage.
+	Language string
+	// Messages overrides the English UI strings, key by key.
+	Messages ReportMessages
+	// Sections lists the section IDs to render, in order; empty renders every registered
+	// section. SkipSections leaves sections out.
+	Sections     []string
+	SkipSections []string
+	// Offline inlines the bundled Bootstrap, jQuery and icon font instead of linking their
+	// CDNs, so the report renders without internet access.
+	Offline bool
+}
+
+// ReportTemplateData feeds the HTML template for metric reports.
+type ReportTemplateData struct {
+	Title    string
+	Language string
+	// HeaderJSON carries the generation time and environment summary shown in the navbar.
+	HeaderJSON      template.JS
+	SectionDataJSON template.JS
+	MessagesJSON    template.JS
+	Sections        []RenderedSection
+	// Styles and Scripts load the page's assets, linked or inlined.
+	Styles  template.HTML
+	Scripts template.HTML
+}
+
+// RenderedSection is a report section with its markup rendered, ready for the page.
+type RenderedSection struct {
+	ID     string
+	Markup template.HTML
+	Script template.JS
+}
+
+// reportHeader is the part of the analysis the page itself shows, outside any section.
+type reportHeader struct {
+	GeneratedAt        time.Time `json:"generatedAt"`
+	EnvironmentSummary []string  `json:"environmentSummary,omitempty"`
+}
+
+// GenerateReport renders a standalone HTML dashboard powered by the Analysis payload.
+func GenerateReport(result analysis.Analysis) (string, error) {
+	return RenderReport(result, ReportOptions{})
+}
+
+// GenerateLocalizedReport renders the HTML dashboard with its UI strings taken from
+// messages; keys missing from messages fall back to English.
+func GenerateLocalizedReport(result analysis.Analysis, language string, messages ReportMessages) (string, error) {
+	return RenderReport(result, ReportOptions{Language: language, Messages: messages})
+}
+
+// RenderReport renders the HTML dashboard from the sections selected in opts. Each
+// section's markup is rendered in turn and its data extracted from analysis, so the page
+// only embeds what its sections read.
+func RenderReport(result analysis.Analysis, opts ReportOptions) (string, error) {
+	sections, err := selectReportSections(opts.Sections, opts.SkipSections)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := DefaultReportMessages()
+	for key, text := range opts.Messages {
+		resolved[key] = text
+	}
+	translate := func(key string) string {
+		if text, ok := resolved[key]; ok {
+			return text
+		}
+		return key
+	}
+	messagesJSON, err := json.Marshal(resolved)
+	if err != nil {
+		return "", err
+	}
+	language := opts.Language
+	if language == "" {
+		language = DefaultReportLanguage
+	}
+
+	header, err := json.Marshal(reportHeader{GeneratedAt: result.GeneratedAt, EnvironmentSummary: result.EnvironmentSummary})
+	if err != nil {
+		return "", err
+	}
+	sectionData := make(map[string]any, len(sections))
+	rendered := make([]RenderedSection, 0, len(sections))
+	for _, section := range sections {
+		tmpl, err := parseSectionMarkup(section, translate)
+		if err != nil {
+			return "", err
+		}
+		var markup bytes.Buffer
+		if err := tmpl.Execute(&markup, nil); err != nil {
+			return "", err
+		}
+		rendered = append(rendered, RenderedSection{ID: section.ID, Markup: template.HTML(markup.String()), Script: template.JS(section.Script)})
+		if section.Data != nil {
+			sectionData[section.ID] = section.Data(result)
+		}
+	}
+	dataJSON, err := json.Marshal(sectionData)
+	if err != nil {
+		return "", err
+	}
+	styles, err := assetTags(opts.Offline, bootstrapCSS, materialIconsCSS)
+	if err != nil {
+		return "", err
+	}
+	scripts, err := assetTags(opts.Offline, jqueryJS, bootstrapJS)
+	if err != nil {
+		return "", err
+	}
+
+	viewModel := ReportTemplateData{
+		Title:           resolved["title"],
+		Language:        language,
+		HeaderJSON:      template.JS(header),
+		SectionDataJSON: template.JS(dataJSON),
+		MessagesJSON:    template.JS(messagesJSON),
+		Sections:        rendered,
+		Styles:          styles,
+		Scripts:         scripts,
+	}
+
+	tmpl, err := reportTemplate.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(template.FuncMap{"t": translate})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, viewModel); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// reportTemplate is parsed with a placeholder t function; RenderReport binds the real messages.
+var reportTemplate = template.Must(template.New("metrics-report").Funcs(template.FuncMap{"t": func(string) string { return "" }}).Parse(reportTemplateHTML))
+
+// reportTemplateHTML is the page around the sections: the navbar, the shared script
+// helpers and the view state kept in the URL hash.
+const reportTemplateHTML = `<!DOCTYPE html>
+<html lang="{{ .Language }}">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+{{ .Styles }}  <style>
+    body { background-color: #f5f7fb; }
+    .card { border: none; }
+    .table thead th { cursor: pointer; }
+    .sort-icon { font-size: 0.8rem; margin-left: 0.25rem; }
+    .accordion-button .badge { margin-left: 0.5rem; }
+    .list-group-item { display: flex; align-items: center; justify-content: space-between; }
+    .notes-list li { margin-bottom: 0.25rem; }
+    .dist-card svg { width: 100%; height: auto; }
+    .dist-card .dist-title { font-size: 0.85rem; font-weight: 600; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+    .heat-strip { display: flex; gap: 1px; height: 1.25rem; }
+    .heat-cell { flex: 1 1 0; min-width: 3px; cursor: pointer; border-radius: 1px; }
+    .heat-0 { background-color: rgba(220, 53, 69, 0.15); }
+    .heat-1 { background-color: rgba(220, 53, 69, 0.36); }
+    .heat-2 { background-color: rgba(220, 53, 69, 0.58); }
+    .heat-3 { background-color: rgba(220, 53, 69, 0.79); }
+    .heat-4 { background-color: rgba(220, 53, 69, 1); }
+    .drilldown-table { font-size: 0.8rem; }
+    #modelFilter { width: 14rem; }
+    [data-bs-theme="dark"] body { background-color: #12151c; }
+    [data-bs-theme="dark"] .bg-white { background-color: var(--bs-body-bg) !important; }
+  </style>
+</head>
+<body>
+  <nav class="navbar navbar-dark bg-dark">
+    <div class="container-fluid">
+      <span class="navbar-brand mb-0 h1">{{ .Title }}</span>
+      <div class="d-flex align-items-center gap-3">
+        <input type="search" class="form-control form-control-sm" id="modelFilter" placeholder="{{ t "filterModels" }}" aria-label="{{ t "filterModels" }}">
+        <button type="button" class="btn btn-sm btn-outline-light" id="themeToggle" title="{{ t "toggleTheme" }}"><span class="material-icons-two-tone align-middle" style="filter: invert(1);">dark_mode</span></button>
+        <span class="badge bg-secondary d-none" id="environmentInfo" style="cursor: help;">{{ t "environment" }}</span>
+        <span class="text-light">{{ t "generated" }} <span id="generatedAt">—</span></span>
+      </div>
+    </div>
+  </nav>
+  <main class="container-fluid my-4">
+{{- range .Sections }}
+
+{{ .Markup }}
+{{- end }}
+  </main>
+
+{{ .Scripts }}  <script>
+    var report = {{ .HeaderJSON }};
+    var sectionData = {{ .SectionDataJSON }};
+    var messages = {{ .MessagesJSON }};
+  </script>
+  <script>
+    (function($) {
+      // t returns a UI string from the report messages, escaped for use in HTML.
+      function t(key) {
+        return escapeAttr(messages[key] !== undefined ? messages[key] : key);
+      }
+
+      // label translates a speed tier, suitability or severity value for display.
+      function label(value) {
+        var text = messages['label.' + value];
+        return escapeAttr(text !== undefined ? text : value);
+      }
+
+      function formatNumber(value, decimals) {
+        if (value === null || value === undefined || isNaN(value)) {
+          return '—';
+        }
+        return Number(value).toFixed(decimals);
+      }
+
+      function createNumericCell(value, decimals) {
+        var display = formatNumber(value, decimals);
+        var $td = $('<td></td>').text(display);
+        if (!isNaN(value)) {
+          $td.attr('data-value', value);
+        }
+        return $td;
+      }
+
+      function updateSortIcons($header, direction) {
+				$header.closest('tr').find('.sort').each(function() {
+					$(this)[0].innerHTML = 'import_export'
+				});
+
+        if (direction === 'asc') {
+					$header.find('.sort')[0].innerHTML = 'keyboard_double_arrow_up'
+        } else if (direction === 'desc') {
+          $header.find('.sort')[0].innerHTML = 'keyboard_double_arrow_down'
+        }
+      }
+
+      function escapeAttr(value) {
+        return String(value).replace(/&/g, '&amp;').replace(/"/g, '&quot;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+      }
+
+      function populateEnvironment(lines) {
+        if (!lines || lines.length === 0) {
+          return;
+        }
+        var title = (messages.runEnvironment || 'Run environment:') + '\n' + lines.join('\n');
+        $('#environmentInfo').attr('title', title).removeClass('d-none');
+        $('#anomaliesHeader').attr('title', title).css('cursor', 'help');
+      }
+
+      function applySort(columnIndex, direction) {
+        var $header = $('#modelsTable thead th.sortable').eq(columnIndex);
+        if ($header.length === 0) {
+          return;
+        }
+        $header.closest('tr').find('th.sortable').removeData('direction');
+        $header.data('direction', direction);
+        sortTable(columnIndex, $header.data('type'), direction);
+        updateSortIcons($header, direction);
+      }
+
+      // reportState mirrors the bookmarkable view settings kept in the URL hash.
+      var reportState = { sort: null, dir: 'asc', model: '', theme: 'light' };
+
+      function readHashState() {
+        var params = new URLSearchParams(window.location.hash.replace(/^#/, ''));
+        var sort = (params.get('sort') || '').split(':');
+        var column = parseInt(sort[0], 10);
+        reportState.sort = isNaN(column) ? null : column;
+        reportState.dir = sort[1] === 'desc' ? 'desc' : 'asc';
+        reportState.model = params.get('model') || '';
+        reportState.theme = params.get('theme') === 'dark' ? 'dark' : 'light';
+      }
+
+      function writeHashState() {
+        var params = new URLSearchParams();
+        if (reportState.sort !== null) {
+          params.set('sort', reportState.sort + ':' + reportState.dir);
+        }
+        if (reportState.model) {
+          params.set('model', reportState.model);
+        }
+        if (reportState.theme === 'dark') {
+          params.set('theme', 'dark');
+        }
+        var hash = params.toString();
+        history.replaceState(null, '', hash ? '#' + hash : window.location.pathname + window.location.search);
+      }
+
+      function applyModelFilter(term) {
+        var needle = term.trim().toLowerCase();
+        $('[data-model]').each(function() {
+          var name = String($(this).attr('data-model')).toLowerCase();
+          $(this).toggle(needle === '' || name.indexOf(needle) !== -1);
+        });
+      }
+
+      function applyTheme(theme) {
+        document.documentElement.setAttribute('data-bs-theme', theme);
+        $('#themeToggle .material-icons-two-tone').text(theme === 'dark' ? 'light_mode' : 'dark_mode');
+      }
+
+      function applyState() {
+        if (reportState.sort !== null) {
+          applySort(reportState.sort, reportState.dir);
+        }
+        $('#modelFilter').val(reportState.model);
+        applyModelFilter(reportState.model);
+        applyTheme(reportState.theme);
+      }
+
+      function attachStateControls() {
+        $('#modelFilter').on('input', function() {
+          reportState.model = $(this).val();
+          applyModelFilter(reportState.model);
+          writeHashState();
+        });
+        $('#themeToggle').on('click', function() {
+          reportState.theme = reportState.theme === 'dark' ? 'light' : 'dark';
+          applyTheme(reportState.theme);
+          writeHashState();
+        });
+        $(window).on('hashchange', function() {
+          readHashState();
+          applyState();
+        });
+      }
+
+      function sortTable(columnIndex, type, direction) {
+        var $tbody = $('#modelsTable tbody');
+        var rows = $tbody.find('tr').get();
+        rows.sort(function(a, b) {
+          var A = $(a).children().eq(columnIndex).text();
+          var B = $(b).children().eq(columnIndex).text();
+          if (type === 'number') {
+            A = parseFloat($(a).children().eq(columnIndex).attr('data-value')) || 0;
+            B = parseFloat($(b).children().eq(columnIndex).attr('data-value')) || 0;
+          }
+          if (A < B) {
+            return direction === 'asc' ? -1 : 1;
+          }
+          if (A > B) {
+            return direction === 'asc' ? 1 : -1;
+          }
+          return 0;
+        });
+        $.each(rows, function(_, row) {
+          $tbody.append(row);
+        });
+      }
+
+      // sections are the report's section scripts in page order; each is called with
+      // its own slice of the analysis.
+      var sections = [];
+{{- range .Sections }}
+      sections.push({ id: {{ .ID }}, render: function(data) {
+{{ .Script }}
+      } });
+{{- end }}
+
+      $(function() {
+        var generatedAt = report.generatedAt ? new Date(report.generatedAt) : null;
+        if (generatedAt) {
+          $('#generatedAt').text(generatedAt.toLocaleString());
+        }
+        populateEnvironment(report.environmentSummary || []);
+
+        sections.forEach(function(section) {
+          section.render(sectionData[section.id]);
+        });
+
+        readHashState();
+        applyState();
+        attachStateControls();
+      });
+    })(jQuery);
+  </script>
+</body>
+</html>`
@@ -0,0 +1,891 @@
+// internal/metrics/sections.go
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// ReportSection is one chart or table of the HTML report, rendered in its own card.
+type ReportSection struct {
+	// ID names the section in the reportSections and reportSkipSections settings.
+	ID string
+	// Markup is an html/template fragment for the section; it may use {{ t "key" }}.
+	Markup string
+	// Script fills the markup in. It runs as a function body with the report's shared
+	// helpers in scope and the section's data in the variable data.
+	Script string
+	// Data extracts what Script reads from the analysis, or nil to pass null.
+	Data func(analysis.Analysis) any
+}
+
+// reportSections are the registered sections in report order.
+var reportSections = []ReportSection{
+	summarySection,
+	comparisonSection,
+	percentilesSection,
+	templatesSection,
+	toolsSection,
+	distributionSection,
+	modelDetailsSection,
+	findingsSection,
+}
+
+// RegisterReportSection adds section to the end of the report, or replaces the registered
+// section with the same ID in place.
+func RegisterReportSection(section ReportSection) error {
+	if strings.TrimSpace(section.ID) == "" {
+		return fmt.Errorf("report section needs an ID")
+	}
+	if _, err := parseSectionMarkup(section, func(string) string { return "" }); err != nil {
+		return err
+	}
+	for i, existing := range reportSections {
+		if existing.ID == section.ID {
+			reportSections[i] = section
+			return nil
+		}
+	}
+	reportSections = append(reportSections, section)
+	return nil
+}
+
+// ReportSectionIDs returns the IDs of the registered sections in report order.
+func ReportSectionIDs() []string {
+	ids := make([]string, len(reportSections))
+	for i, section := range reportSections {
+		ids[i] = section.ID
+	}
+	return ids
+}
+
+// selectReportSections returns the sections named in include, in that order, or every
+// registered section when include is empty, leaving out those named in skip. Unknown IDs
+// are an error.
+func selectReportSections(include, skip []string) ([]ReportSection, error) {
+	byID := make(map[string]ReportSection, len(reportSections))
+	for _, section := range reportSections {
+		byID[section.ID] = section
+	}
+	skipped := make(map[string]bool, len(skip))
+	for _, id := range skip {
+		id = strings.TrimSpace(id)
+		if _, ok := byID[id]; !ok {
+			return nil, unknownSectionError(id)
+		}
+		skipped[id] = true
+	}
+
+	order := ReportSectionIDs()
+	if len(include) > 0 {
+		order = nil
+		for _, id := range include {
+			id = strings.TrimSpace(id)
+			if _, ok := byID[id]; !ok {
+				return nil, unknownSectionError(id)
+			}
+			order = append(order, id)
+		}
+	}
+
+	var sections []ReportSection
+	for _, id := range order {
+		if !skipped[id] {
+			sections = append(sections, byID[id])
+		}
+	}
+	return sections, nil
+}
+
+// ValidateReportSections reports an error when include or skip names a section that is
+// not registered.
+func ValidateReportSections(include, skip []string) error {
+	_, err := selectReportSections(include, skip)
+	return err
+}
+
+// unknownSectionError reports a section ID that is not registered.
+func unknownSectionError(id string) error {
+	return fmt.Errorf("unknown report section %q (want one of %s)", id, strings.Join(ReportSectionIDs(), ", "))
+}
+
+// parseSectionMarkup parses a section's markup with t bound to translate.
+func parseSectionMarkup(section ReportSection, translate func(string) string) (*template.Template, error) {
+	tmpl, err := template.New(section.ID).Funcs(template.FuncMap{"t": translate}).Parse(section.Markup)
+	if err != nil {
+		return nil, fmt.Errorf("invalid markup for report section %q: %w", section.ID, err)
+	}
+	return tmpl, nil
+}
+
+// summarySectionData is the summary cards' view of the analysis.
+type summarySectionData struct {
+	Overall          analysis.OverallSummary `json:"overall"`
+	InteractiveCount int                     `json:"interactiveCount"`
+}
+
+// distributionSample is one model's per-iteration throughput for the box plots.
+type distributionSample struct {
+	ModelName       string    `json:"modelName"`
+	TokensPerSecond []float64 `json:"tokensPerSecond"`
+}
+
+// templateComparison is one model's parameter templates for the templates section.
+type templateComparison struct {
+	ModelName string                            `json:"modelName"`
+	Templates []analysis.ParameterTemplateStats `json:"templates"`
+}
+
+// findingsSectionData is the anomalies and recommendations panel's view of the analysis.
+type findingsSectionData struct {
+	Anomalies           []analysis.Anomaly `json:"anomalies"`
+	SuppressedAnomalies []analysis.Anomaly `json:"suppressedAnomalies"`
+	Recommendations     []string           `json:"recommendations"`
+}
+
+// heatStripBuckets is how many shades the latency heat strip uses, from a model's fastest
+// iterations to its slowest.
+const heatStripBuckets = 5
+
+// modelDetails is a model's view in the details section, with its latency heat strip.
+type modelDetails struct {
+	analysis.ModelAnalysis
+	HeatStrip []heatCell `json:"heatStrip"`
+}
+
+// heatCell is one iteration in the latency heat strip. Index points into the model's
+// iterations, and Bucket is its shade, 0 for the fastest.
+type heatCell struct {
+	Index  int `json:"index"`
+	Bucket int `json:"bucket"`
+}
+
+// latencyHeatStrip orders iterations by question and iteration number and buckets each by
+// total time relative to the fastest and slowest of them.
+func latencyHeatStrip(iterations []analysis.IterationSample) []heatCell {
+	if len(iterations) == 0 {
+		return nil
+	}
+	order := make([]int, len(iterations))
+	fastest, slowest := iterations[0].TotalExecutionTimeSeconds, iterations[0].TotalExecutionTimeSeconds
+	for i, it := range iterations {
+		order[i] = i
+		fastest = math.Min(fastest, it.TotalExecutionTimeSeconds)
+		slowest = math.Max(slowest, it.TotalExecutionTimeSeconds)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := iterations[order[i]], iterations[order[j]]
+		if a.QuestionID != b.QuestionID {
+			return a.QuestionID < b.QuestionID
+		}
+		return a.Iteration < b.Iteration
+	})
+
+	cells := make([]heatCell, len(order))
+	for i, index := range order {
+		bucket := 0
+		if slowest > fastest {
+			share := (iterations[index].TotalExecutionTimeSeconds - fastest) / (slowest - fastest)
+			bucket = min(int(share*heatStripBuckets), heatStripBuckets-1)
+		}
+		cells[i] = heatCell{Index: index, Bucket: bucket}
+	}
+	return cells
+}
+
+// modelsWithoutIterations returns the models with their iteration samples dropped, for
+// sections that only show aggregates.
+func modelsWithoutIterations(a analysis.Analysis) any {
+	models := make([]analysis.ModelAnalysis, len(a.Models))
+	for i, model := range a.Models {
+		model.Iterations = nil
+		models[i] = model
+	}
+	return models
+}
+
+// summarySection shows the headline cards: the fastest, lowest-latency and most efficient
+// models and how many are fit for interactive use.
+var summarySection = ReportSection{
+	ID: "summary",
+	Markup: `<div class="row g-3">
+  <div class="col-sm-6 col-lg-3">
+    <div class="card shadow-sm h-100">
+      <div class="card-body">
+        <p style="font-size: 1.5em;" class="text-muted mb-1"><span style="display: inline-block;font-size: 1.5em;vertical-align: top;" class="material-icons-two-tone">speed</span> {{ t "fastestModel" }}</p>
+        <h5 class="card-title" id="fastestModel">—</h5>
+      </div>
+    </div>
+  </div>
+  <div class="col-sm-6 col-lg-3">
+    <div class="card shadow-sm h-100">
+      <div class="card-body">
+        <p style="font-size: 1.5em;" class="text-muted mb-1"><span style="display: inline-block;font-size: 1.5em;vertical-align: top;" class="material-icons-two-tone">speed</span> {{ t "bestLatency" }}</p>
+        <h5 class="card-title" id="bestLatencyModel">—</h5>
+      </div>
+    </div>
+  </div>
+  <div class="col-sm-6 col-lg-3">
+    <div class="card shadow-sm h-100">
+      <div class="card-body">
+        <p style="font-size: 1.5em;" class="text-muted mb-1"><span style="display: inline-block;font-size: 1.5em;vertical-align: top;" class="material-icons-two-tone">speed</span> {{ t "mostEfficient" }}</p>
+        <h5 class="card-title" id="mostEfficientModel">—</h5>
+      </div>
+    </div>
+  </div>
+  <div class="col-sm-6 col-lg-3">
+    <div class="card shadow-sm h-100">
+      <div class="card-body">
+        <p style="font-size: 1.5em;" class="text-muted mb-1"><span style="display: inline-block;font-size: 1.5em;vertical-align: top;" class="material-icons-two-tone">speed</span> {{ t "interactiveReady" }}</p>
+        <h5 class="card-title" id="interactiveCount">0</h5>
+      </div>
+    </div>
+  </div>
+</div>`,
+	Script: `var summary = data.overall || {};
+$('#fastestModel').text(summary.fastestModel || '—');
+$('#bestLatencyModel').text(summary.bestLatencyModel || '—');
+$('#mostEfficientModel').text(summary.mostEfficientModel || '—');
+$('#interactiveCount').text(data.interactiveCount || 0);`,
+	Data: func(a analysis.Analysis) any {
+		data := summarySectionData{Overall: a.Overall}
+		for _, model := range a.Models {
+			if model.Labels.InteractiveSuitability == "good" {
+				data.InteractiveCount++
+			}
+		}
+		return data
+	},
+}
+
+// comparisonSection is the sortable table of every model's averages, scores and labels.
+var comparisonSection = ReportSection{
+	ID: "comparison",
+	Markup: `<section class="mt-4">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "modelComparison" }}</h5>
+    </div>
+    <div class="card-body">
+      <div class="table-responsive">
+        <table class="table table-striped table-hover table-bordered table-sm" id="modelsTable">
+          <thead class="table-light">
+            <tr>
+              <th class="sortable" data-type="text">{{ t "colModel" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colAvgTPS" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colAvgTTFT" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colAvgTotal" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colAvgOutputTokens" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colThroughputScore" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colLatencyScore" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number">{{ t "colEfficiencyScore" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="text">{{ t "colSpeedTier" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="text">{{ t "colSuitability" }} <span class="material-icons-two-tone sort">import_export</span></th>
+              <th class="sortable" data-type="number" title="{{ t "colDisagreementHelp" }}">{{ t "colJudgeDisagreement" }} <span class="material-icons-two-tone sort">import_export</span></th>
+            </tr>
+          </thead>
+          <tbody></tbody>
+        </table>
+      </div>
+    </div>
+  </div>
+</section>`,
+	Script: `function populateTable(models) {
+  var $tbody = $('#modelsTable tbody').empty();
+  models.forEach(function(model) {
+    var $row = $('<tr></tr>').attr('data-model', model.modelName);
+    $row.append($('<td><span class="material-icons-two-tone">smart_toy</span> '+model.modelName+'</td>'))
+    $row.append(createNumericCell(model.avg.tokensPerSecond, 2));
+    $row.append(createNumericCell(model.avg.timeToFirstTokenSeconds, 2));
+    $row.append(createNumericCell(model.avg.totalExecutionTimeSeconds, 2));
+    $row.append(createNumericCell(model.avg.outputTokens, 1));
+    $row.append(createNumericCell(model.scores.throughputScore, 1));
+    $row.append(createNumericCell(model.scores.latencyScore, 1));
+    $row.append(createNumericCell(model.scores.efficiencyScore, 1));
+    $row.append($('<td></td>').html(model.labels.relativeSpeedTier ? label(model.labels.relativeSpeedTier) : '—'));
+    $row.append($('<td></td>').html(model.labels.interactiveSuitability ? label(model.labels.interactiveSuitability) : '—'));
+    var $judging = createNumericCell(model.judging ? model.judging.disagreementRate * 100 : NaN, 1);
+    if (model.judging && model.judging.unreliable) {
+      $judging.addClass('table-warning').attr('title', model.judging.unreliable.map(function(q) { return q.questionId + ': ' + q.disagreements + '/' + q.judged; }).join(', '));
+    }
+    $row.append($judging);
+    $tbody.append($row);
+  });
+}
+
+function attachSorting() {
+  $('#modelsTable thead th.sortable').each(function(index) {
+    $(this).on('click', function() {
+      var direction = $(this).data('direction') === 'asc' ? 'desc' : 'asc';
+      applySort(index, direction);
+      reportState.sort = index;
+      reportState.dir = direction;
+      writeHashState();
+    });
+  });
+}
+
+populateTable(data || []);
+attachSorting();`,
+	Data: modelsWithoutIterations,
+}
+
+// percentilesSection tabulates each model's P50 to P99 latency and throughput.
+var percentilesSection = ReportSection{
+	ID: "percentiles",
+	Markup: `<section class="mt-4">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "percentilesTitle" }}</h5>
+    </div>
+    <div class="card-body">
+      <p class="text-muted small mb-3">{{ t "percentilesHelp" }}</p>
+      <div class="table-responsive">
+        <table class="table table-striped table-hover table-bordered table-sm" id="percentilesTable">
+          <thead class="table-light">
+            <tr>
+              <th rowspan="2">{{ t "colModel" }}</th>
+              <th colspan="4" class="text-center">{{ t "colTTFT" }}</th>
+              <th colspan="4" class="text-center">{{ t "colTotal" }}</th>
+              <th colspan="4" class="text-center">{{ t "colTPS" }}</th>
+            </tr>
+            <tr>
+              <th>P50</th><th>P90</th><th>P95</th><th>P99</th>
+              <th>P50</th><th>P90</th><th>P95</th><th>P99</th>
+              <th>P50</th><th>P90</th><th>P95</th><th>P99</th>
+            </tr>
+          </thead>
+          <tbody></tbody>
+        </table>
+      </div>
+    </div>
+  </div>
+</section>`,
+	Script: `function populatePercentiles(models) {
+  var $tbody = $('#percentilesTable tbody').empty();
+  var percentiles = ['p50', 'p90', 'p95', 'p99'];
+  models.forEach(function(model) {
+    var $row = $('<tr></tr>').attr('data-model', model.modelName);
+    $row.append($('<td></td>').text(model.modelName));
+    ['timeToFirstTokenSeconds', 'totalExecutionTimeSeconds', 'tokensPerSecond'].forEach(function(metric) {
+      percentiles.forEach(function(p) {
+        $row.append(createNumericCell(model[p] ? model[p][metric] : NaN, 2));
+      });
+    });
+    $tbody.append($row);
+  });
+}
+
+populatePercentiles(data || []);`,
+	Data: modelsWithoutIterations,
+}
+
+// templatesSection compares each model's parameter templates and calls out the winner; it
+// hides itself when no model ran under more than one template.
+var templatesSection = ReportSection{
+	ID: "templates",
+	Markup: `<section class="mt-4" id="templatesSection">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "templatesTitle" }}</h5>
+    </div>
+    <div class="card-body">
+      <p class="text-muted small mb-3">{{ t "templatesHelp" }}</p>
+      <ul class="list-unstyled mb-3" id="templateWinners"></ul>
+      <div class="table-responsive">
+        <table class="table table-striped table-hover table-bordered table-sm" id="templatesTable">
+          <thead class="table-light">
+            <tr>
+              <th>{{ t "colModel" }}</th>
+              <th>{{ t "colTemplate" }}</th>
+              <th>{{ t "colIterations" }}</th>
+              <th>{{ t "colAccuracy" }}</th>
+              <th>{{ t "colDelta" }}</th>
+              <th>{{ t "colTTFT" }}</th>
+              <th>{{ t "colDelta" }}</th>
+              <th>{{ t "colTotal" }}</th>
+              <th>{{ t "colDelta" }}</th>
+              <th>{{ t "colTPS" }}</th>
+            </tr>
+          </thead>
+          <tbody></tbody>
+        </table>
+      </div>
+    </div>
+  </div>
+</section>`,
+	Script: `// signedDelta formats a change from the baseline template with an explicit sign.
+function signedDelta(value, decimals) {
+  if (value === null || value === undefined || isNaN(value)) {
+    return '—';
+  }
+  return (value > 0 ? '+' : '') + Number(value).toFixed(decimals);
+}
+
+function populateTemplates(models) {
+  if (models.length === 0) {
+    $('#templatesSection').addClass('d-none');
+    return;
+  }
+  var $winners = $('#templateWinners').empty();
+  var $tbody = $('#templatesTable tbody').empty();
+  models.forEach(function(model) {
+    model.templates.forEach(function(tpl, index) {
+      var delta = function(value, decimals) {
+        return index === 0 ? t('templateBaseline') : signedDelta(value, decimals);
+      };
+      var accuracy = tpl.accuracy === undefined ? NaN : tpl.accuracy * 100;
+      var accuracyDelta = tpl.accuracyDelta === undefined ? NaN : tpl.accuracyDelta * 100;
+      var $row = $('<tr></tr>').attr('data-model', model.modelName);
+      if (tpl.winner) {
+        $row.addClass('table-success');
+      }
+      $row.append($('<td></td>').text(model.modelName));
+      $row.append($('<td></td>').text(tpl.name));
+      $row.append(createNumericCell(tpl.iterations, 0));
+      $row.append(createNumericCell(accuracy, 1));
+      $row.append($('<td></td>').html(delta(accuracyDelta, 1)));
+      $row.append(createNumericCell(tpl.timeToFirstTokenSeconds, 2));
+      $row.append($('<td></td>').html(delta(tpl.timeToFirstTokenDeltaSeconds, 2)));
+      $row.append(createNumericCell(tpl.totalExecutionTimeSeconds, 2));
+      $row.append($('<td></td>').html(delta(tpl.totalExecutionTimeDeltaSeconds, 2)));
+      $row.append(createNumericCell(tpl.tokensPerSecond, 2));
+      $tbody.append($row);
+      if (tpl.winner) {
+        $winners.append('<li data-model="' + escapeAttr(model.modelName) + '"><span class="badge bg-success me-2">' + t('templateWinner') + '</span>'
+          + '<strong>' + escapeAttr(model.modelName) + '</strong>: ' + escapeAttr(tpl.name) + '</li>');
+      }
+    });
+  });
+}
+
+populateTemplates(data || []);`,
+	Data: func(a analysis.Analysis) any {
+		comparisons := []templateComparison{}
+		for _, model := range a.Models {
+			if len(model.ParameterTemplates) > 0 {
+				comparisons = append(comparisons, templateComparison{ModelName: model.ModelName, Templates: model.ParameterTemplates})
+			}
+		}
+		return comparisons
+	},
+}
+
+// toolUseSummary is one model's tool use for the tools section.
+type toolUseSummary struct {
+	ModelName string                 `json:"modelName"`
+	ToolUse   *analysis.ToolUseStats `json:"toolUse"`
+}
+
+// toolsSection shows how often each model called MCP tools and compares its answers with
+// and without them; it hides itself unless the run let models call tools.
+var toolsSection = ReportSection{
+	ID: "tools",
+	Markup: `<section class="mt-4" id="toolsSection">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "toolsTitle" }}</h5>
+    </div>
+    <div class="card-body">
+      <p class="text-muted small mb-3">{{ t "toolsHelp" }}</p>
+      <div class="table-responsive">
+        <table class="table table-striped table-hover table-bordered table-sm" id="toolsTable">
+          <thead class="table-light">
+            <tr>
+              <th>{{ t "colModel" }}</th>
+              <th>{{ t "colToolRate" }}</th>
+              <th>{{ t "colToolCalls" }}</th>
+              <th>{{ t "colToolMissed" }}</th>
+              <th>{{ t "colToolUnnecessary" }}</th>
+              <th>{{ t "colAccuracyWithTool" }}</th>
+              <th>{{ t "colAccuracyWithoutTool" }}</th>
+              <th>{{ t "colTotalWithTool" }}</th>
+              <th>{{ t "colTotalWithoutTool" }}</th>
+            </tr>
+          </thead>
+          <tbody></tbody>
+        </table>
+      </div>
+    </div>
+  </div>
+</section>`,
+	Script: `// ratioText formats a count out of a total, or a dash when nothing was counted.
+function ratioText(count, total) {
+  return total > 0 ? count + ' / ' + total : '—';
+}
+
+function populateTools(models) {
+  if (models.length === 0) {
+    $('#toolsSection').addClass('d-none');
+    return;
+  }
+  var $tbody = $('#toolsTable tbody').empty();
+  models.forEach(function(model) {
+    var use = model.toolUse;
+    var accuracy = function(group) {
+      return group.accuracy === undefined ? NaN : group.accuracy * 100;
+    };
+    var calls = Object.keys(use.calls || {}).sort().map(function(name) {
+      return name + ' ×' + use.calls[name];
+    }).join(', ');
+    var $row = $('<tr></tr>').attr('data-model', model.modelName);
+    $row.append($('<td></td>').text(model.modelName));
+    $row.append(createNumericCell(use.invocationRate * 100, 1));
+    $row.append($('<td></td>').text(calls || '—'));
+    $row.append($('<td></td>').text(ratioText(use.missed, use.needed)));
+    $row.append($('<td></td>').text(ratioText(use.unnecessary, use.unneeded)));
+    $row.append(createNumericCell(accuracy(use.withTool), 1));
+    $row.append(createNumericCell(accuracy(use.withoutTool), 1));
+    $row.append(createNumericCell(use.withTool.iterations > 0 ? use.withTool.totalExecutionTimeSeconds : NaN, 2));
+    $row.append(createNumericCell(use.withoutTool.iterations > 0 ? use.withoutTool.totalExecutionTimeSeconds : NaN, 2));
+    $tbody.append($row);
+  });
+}
+
+populateTools(data || []);`,
+	Data: func(a analysis.Analysis) any {
+		summaries := []toolUseSummary{}
+		for _, model := range a.Models {
+			if model.ToolUse != nil {
+				summaries = append(summaries, toolUseSummary{ModelName: model.ModelName, ToolUse: model.ToolUse})
+			}
+		}
+		return summaries
+	},
+}
+
+// distributionSection draws a box plot of each model's per-iteration throughput.
+var distributionSection = ReportSection{
+	ID: "distribution",
+	Markup: `<section class="mt-4">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "distributionTitle" }}</h5>
+    </div>
+    <div class="card-body">
+      <p class="text-muted small mb-3">{{ t "distributionHelp" }}</p>
+      <div class="row g-3" id="distributionGrid"></div>
+    </div>
+  </div>
+</section>`,
+	Script: `function quantile(sorted, q) {
+  if (sorted.length === 0) {
+    return 0;
+  }
+  var pos = (sorted.length - 1) * q;
+  var lower = Math.floor(pos);
+  var upper = Math.ceil(pos);
+  if (lower === upper) {
+    return sorted[lower];
+  }
+  return sorted[lower] + (sorted[upper] - sorted[lower]) * (pos - lower);
+}
+
+function boxPlotSVG(values, scaleMax) {
+  var width = 240, height = 90, pad = 12, mid = 40;
+  var sorted = values.slice().sort(function(a, b) { return a - b; });
+  var q1 = quantile(sorted, 0.25);
+  var median = quantile(sorted, 0.5);
+  var q3 = quantile(sorted, 0.75);
+  var iqr = q3 - q1;
+  var lowFence = q1 - 1.5 * iqr;
+  var highFence = q3 + 1.5 * iqr;
+  var inliers = sorted.filter(function(v) { return v >= lowFence && v <= highFence; });
+  var whiskerLow = inliers.length ? inliers[0] : q1;
+  var whiskerHigh = inliers.length ? inliers[inliers.length - 1] : q3;
+  var x = function(v) {
+    return pad + (scaleMax > 0 ? (v / scaleMax) : 0) * (width - 2 * pad);
+  };
+  var parts = [];
+  parts.push('<svg viewBox="0 0 ' + width + ' ' + height + '" xmlns="http://www.w3.org/2000/svg">');
+  parts.push('<line x1="' + pad + '" y1="' + (height - 18) + '" x2="' + (width - pad) + '" y2="' + (height - 18) + '" stroke="#ced4da"/>');
+  parts.push('<text x="' + pad + '" y="' + (height - 4) + '" font-size="9" fill="#6c757d">0</text>');
+  parts.push('<text x="' + (width - pad) + '" y="' + (height - 4) + '" font-size="9" fill="#6c757d" text-anchor="end">' + formatNumber(scaleMax, 1) + ' t/s</text>');
+  parts.push('<line x1="' + x(whiskerLow) + '" y1="' + mid + '" x2="' + x(q1) + '" y2="' + mid + '" stroke="#495057"/>');
+  parts.push('<line x1="' + x(q3) + '" y1="' + mid + '" x2="' + x(whiskerHigh) + '" y2="' + mid + '" stroke="#495057"/>');
+  parts.push('<line x1="' + x(whiskerLow) + '" y1="' + (mid - 8) + '" x2="' + x(whiskerLow) + '" y2="' + (mid + 8) + '" stroke="#495057"/>');
+  parts.push('<line x1="' + x(whiskerHigh) + '" y1="' + (mid - 8) + '" x2="' + x(whiskerHigh) + '" y2="' + (mid + 8) + '" stroke="#495057"/>');
+  parts.push('<rect x="' + x(q1) + '" y="' + (mid - 14) + '" width="' + Math.max(x(q3) - x(q1), 1) + '" height="28" fill="#cfe2ff" stroke="#0d6efd"/>');
+  parts.push('<line x1="' + x(median) + '" y1="' + (mid - 14) + '" x2="' + x(median) + '" y2="' + (mid + 14) + '" stroke="#0d6efd" stroke-width="2"/>');
+  sorted.forEach(function(v, i) {
+    var outlier = v < lowFence || v > highFence;
+    var jitter = ((i * 7) % 11) - 5;
+    parts.push('<circle cx="' + x(v) + '" cy="' + (mid + jitter) + '" r="' + (outlier ? 3 : 1.5) + '" fill="' + (outlier ? '#dc3545' : '#6c757d') + '" fill-opacity="' + (outlier ? 1 : 0.6) + '"><title>' + formatNumber(v, 2) + ' t/s</title></circle>');
+  });
+  parts.push('</svg>');
+  return {
+    svg: parts.join(''),
+    summary: t('distributionMedian') + ' ' + formatNumber(median, 2) + ' · ' + t('distributionIQR') + ' ' + formatNumber(q1, 2) + '–' + formatNumber(q3, 2)
+  };
+}
+
+function renderDistributions(samples) {
+  var $grid = $('#distributionGrid').empty();
+  if (samples.length === 0) {
+    $grid.append('<div class="col-12 text-muted">' + t('distributionEmpty') + '</div>');
+    return;
+  }
+  var scaleMax = 0;
+  samples.forEach(function(model) {
+    model.tokensPerSecond.forEach(function(value) {
+      if (value > scaleMax) {
+        scaleMax = value;
+      }
+    });
+  });
+  samples.forEach(function(model) {
+    var values = model.tokensPerSecond;
+    var plot = boxPlotSVG(values, scaleMax);
+    var card = ''
+      + '<div class="col-sm-6 col-lg-4 col-xl-3" data-model="' + model.modelName + '">'
+      + '<div class="border rounded p-2 bg-white dist-card">'
+      + '<div class="dist-title" title="' + model.modelName + '">' + model.modelName + '</div>'
+      + plot.svg
+      + '<div class="small text-muted">' + plot.summary + ' · n=' + values.length + '</div>'
+      + '</div></div>';
+    $grid.append(card);
+  });
+}
+
+renderDistributions(data || []);`,
+	Data: func(a analysis.Analysis) any {
+		samples := []distributionSample{}
+		for _, model := range a.Models {
+			if len(model.Iterations) == 0 {
+				continue
+			}
+			sample := distributionSample{ModelName: model.ModelName}
+			for _, iteration := range model.Iterations {
+				sample.TokensPerSecond = append(sample.TokensPerSecond, iteration.TokensPerSecond)
+			}
+			samples = append(samples, sample)
+		}
+		return samples
+	},
+}
+
+// modelDetailsSection is the per-model accordion of stats, notes and the latency heat strip.
+var modelDetailsSection = ReportSection{
+	ID: "details",
+	Markup: `<section class="mt-4">
+  <div class="card shadow-sm">
+    <div class="card-header bg-white">
+      <h5 class="mb-0">{{ t "perModelDetails" }}</h5>
+    </div>
+    <div class="card-body">
+      <div class="accordion" id="modelAccordion"></div>
+    </div>
+  </div>
+</section>`,
+	Script: `function buildAccordion(models) {
+  var $accordion = $('#modelAccordion').empty();
+  models.forEach(function(model, index) {
+    var collapseID = 'model-details-' + index;
+    var headerID = 'heading-' + index;
+    var $item = $('<div class="accordion-item"></div>').attr('data-model', model.modelName);
+    var badges = '';
+    if (model.labels.relativeSpeedTier) {
+      badges += '<span class="badge bg-primary text-uppercase">' + label(model.labels.relativeSpeedTier) + '</span>';
+    }
+    if (model.labels.interactiveSuitability) {
+      var badgeClass = 'bg-success';
+      if (model.labels.interactiveSuitability === 'borderline') {
+        badgeClass = 'bg-warning text-dark';
+      } else if (model.labels.interactiveSuitability === 'unusable') {
+        badgeClass = 'bg-danger';
+      }
+      badges += '<span class="badge ' + badgeClass + ' text-uppercase">' + label(model.labels.interactiveSuitability) + '</span>';
+    }
+    var header = ''
+      + '<h2 class="accordion-header" id="' + headerID + '">'
+      + '<button class="accordion-button ' + (index !== 0 ? 'collapsed' : '') + '" type="button" data-bs-toggle="collapse"'
+      + ' data-bs-target="#' + collapseID + '" aria-expanded="' + (index === 0 ? 'true' : 'false') + '"'
+      + ' aria-controls="' + collapseID + '">'
+      + model.modelName + ' ' + badges
+      + '</button>'
+      + '</h2>';
+    var notes = (model.notes || []).map(function(note) {
+      return '<li>' + note + '</li>';
+    }).join('');
+    if (!notes) {
+      notes = '<li>' + t('noNotes') + '</li>';
+    }
+    var bodyParts = [];
+    bodyParts.push('<div id="' + collapseID + '" class="accordion-collapse collapse ' + (index === 0 ? 'show' : '') + '" aria-labelledby="' + headerID + '" data-bs-parent="#modelAccordion">');
+    bodyParts.push('<div class="accordion-body"><div class="row g-3">');
+    bodyParts.push('<div class="col-md-6">');
+    bodyParts.push('<h6>' + t('averageStats') + '</h6><ul class="list-unstyled mb-3">');
+    bodyParts.push('<li><strong>' + t('tokensPerSecond') + '</strong> ' + formatNumber(model.avg.tokensPerSecond, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('ttftSeconds') + '</strong> ' + formatNumber(model.avg.timeToFirstTokenSeconds, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('totalSeconds') + '</strong> ' + formatNumber(model.avg.totalExecutionTimeSeconds, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('outputTokens') + '</strong> ' + formatNumber(model.avg.outputTokens, 1) + '</li>');
+    bodyParts.push('</ul><h6>' + t('variance') + '</h6><ul class="list-unstyled mb-3">');
+    bodyParts.push('<li><strong>' + t('tpsStdDev') + '</strong> ' + formatNumber(model.variance.tokensPerSecondStdDev, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('ttftStdDev') + '</strong> ' + formatNumber(model.variance.timeToFirstTokenStdDevSeconds, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('outputStdDev') + '</strong> ' + formatNumber(model.variance.outputTokensStdDev, 2) + '</li>');
+    bodyParts.push('</ul></div>');
+    bodyParts.push('<div class="col-md-6">');
+    bodyParts.push('<h6>' + t('extremes') + '</h6><ul class="list-unstyled mb-3">');
+    bodyParts.push('<li><strong>' + t('minTPS') + '</strong> ' + formatNumber(model.min.tokensPerSecond, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('maxTPS') + '</strong> ' + formatNumber(model.max.tokensPerSecond, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('minTTFT') + '</strong> ' + formatNumber(model.min.timeToFirstTokenSeconds, 2) + '</li>');
+    bodyParts.push('<li><strong>' + t('maxTTFT') + '</strong> ' + formatNumber(model.max.timeToFirstTokenSeconds, 2) + '</li>');
+    bodyParts.push('</ul><h6>' + t('ratiosAndNotes') + '</h6><ul class="list-unstyled mb-3">');
+    bodyParts.push('<li><strong>' + t('latencyShare') + '</strong> ' + formatNumber((model.derivedRatios.latencyShareOfTotal || 0) * 100, 1) + '%</li>');
+    bodyParts.push('<li><strong>' + t('relativeToFastest') + '</strong> ' + formatNumber((model.derivedRatios.relativeToFastest || 0) * 100, 1) + '%</li>');
+    bodyParts.push('</ul><ul class="notes-list">' + notes + '</ul>');
+    bodyParts.push('</div>');
+    bodyParts.push(latencyStrip(model, index));
+    bodyParts.push('</div></div></div>');
+    var body = bodyParts.join('');
+    $item.append(header);
+    $item.append(body);
+    $accordion.append($item);
+  });
+}
+
+// latencyStrip renders a cell per iteration of the model's heat strip, shaded by its
+// bucket, above a drill-down table the cells link to.
+function latencyStrip(model, index) {
+  var strip = model.heatStrip || [];
+  if (strip.length === 0) {
+    return '';
+  }
+  var cells = [], rows = [];
+  strip.forEach(function(cell) {
+    var it = model.iterations[cell.index];
+    var rowID = 'iter-' + index + '-' + it.iteration;
+    var question = it.questionId || '—';
+    cells.push('<span class="heat-cell heat-' + cell.bucket + '" data-row="' + rowID + '"'
+      + ' title="' + escapeAttr(question) + ' #' + it.iteration + ': ' + formatNumber(it.totalExecutionTimeSeconds, 2) + ' s"></span>');
+    var correct = it.correct === undefined || it.correct === null ? '—' : (it.correct ? '✓' : '✗');
+    var record = it.recordId ? ' title="' + escapeAttr(it.recordId) + '"' : '';
+    rows.push('<tr id="' + rowID + '"' + record + '><td>' + escapeAttr(question) + '</td><td>' + it.iteration + '</td>'
+      + '<td>' + escapeAttr(it.systemPrompt || '—') + '</td>'
+      + '<td>' + formatNumber(it.totalExecutionTimeSeconds, 2) + '</td><td>' + formatNumber(it.timeToFirstTokenSeconds, 2) + '</td>'
+      + '<td>' + formatNumber(it.tokensPerSecond, 2) + '</td><td>' + correct + '</td></tr>');
+  });
+  return '<div class="col-12">'
+    + '<h6>' + t('latencyStrip') + '</h6>'
+    + '<p class="text-muted small mb-1">' + t('latencyStripHelp') + '</p>'
+    + '<div class="heat-strip">' + cells.join('') + '</div>'
+    + '<details class="mt-2"><summary class="small">' + t('iterationDetails') + '</summary>'
+    + '<table class="table table-sm drilldown-table mt-2"><thead><tr>'
+    + '<th>' + t('colQuestion') + '</th><th>' + t('colIteration') + '</th><th>' + t('colSystemPrompt') + '</th>'
+    + '<th>' + t('colTotal') + '</th><th>' + t('colTTFT') + '</th><th>' + t('colTPS') + '</th><th>' + t('colCorrect') + '</th>'
+    + '</tr></thead><tbody>' + rows.join('') + '</tbody></table></details>'
+    + '</div>';
+}
+
+// showIterationRow opens the drill-down table holding a heat strip cell's row and
+// scrolls to it.
+function showIterationRow(rowID) {
+  var $row = $('#' + rowID);
+  $row.closest('details').prop('open', true);
+  $row.closest('tbody').find('tr').removeClass('table-warning');
+  $row.addClass('table-warning');
+  $row[0].scrollIntoView({ behavior: 'smooth', block: 'center' });
+}
+
+buildAccordion(data || []);
+$('#modelAccordion').on('click', '.heat-cell', function() {
+  showIterationRow($(this).attr('data-row'));
+});`,
+	Data: func(a analysis.Analysis) any {
+		details := make([]modelDetails, len(a.Models))
+		for i, model := range a.Models {
+			details[i] = modelDetails{ModelAnalysis: model, HeatStrip: latencyHeatStrip(model.Iterations)}
+		}
+		return details
+	},
+}
+
+// findingsSection lists the detected anomalies beside the recommendations.
+var findingsSection = ReportSection{
+	ID: "findings",
+	Markup: `<section class="mt-4">
+  <div class="row g-3">
+    <div class="col-md-6">
+      <div class="card shadow-sm h-100">
+        <div class="card-header bg-white" id="anomaliesHeader">
+          <h5 class="mb-0">{{ t "anomalies" }}</h5>
+        </div>
+        <div class="card-body">
+          <div class="list-group" id="anomaliesList"></div>
+        </div>
+      </div>
+    </div>
+    <div class="col-md-6">
+      <div class="card shadow-sm h-100">
+        <div class="card-header bg-white">
+          <h5 class="mb-0">{{ t "recommendations" }}</h5>
+        </div>
+        <div class="card-body">
+          <ol class="list-group list-group-numbered" id="recommendationsList"></ol>
+        </div>
+      </div>
+    </div>
+  </div>
+</section>`,
+	Script: `function populateAnomalies(anomalies, suppressed) {
+  var $container = $('#anomaliesList').empty();
+  if ((!anomalies || anomalies.length === 0) && (!suppressed || suppressed.length === 0)) {
+    $container.append('<div class="list-group-item text-muted">' + t('noAnomalies') + '</div>');
+    return;
+  }
+  (anomalies || []).forEach(function(anomaly) {
+    var badgeClass = 'bg-secondary';
+    if (anomaly.severity === 'warning') {
+      badgeClass = 'bg-warning text-dark';
+    } else if (anomaly.severity === 'critical') {
+      badgeClass = 'bg-danger';
+    }
+    var item = ''
+      + '<div class="list-group-item' + (anomaly.acknowledgement ? ' text-muted' : '') + '">'
+      + '<div>'
+      + '<span class="badge ' + badgeClass + ' text-uppercase me-2">' + label(anomaly.severity || 'info') + '</span>'
+      + '<strong>' + (anomaly.modelName || '—') + '</strong>'
+      + '</div>'
+      + '<p class="mb-0 small">' + (anomaly.message || '') + '</p>'
+      + acknowledgementNote(anomaly)
+      + '</div>';
+    $container.append(item);
+  });
+  if (suppressed && suppressed.length > 0) {
+    var lines = suppressed.map(function(anomaly) {
+      return (anomaly.modelName || '—') + ' (' + (anomaly.type || '') + '): ' + (anomaly.acknowledgement || '');
+    });
+    $container.append('<div class="list-group-item text-muted small" title="' + escapeAttr(lines.join('\n')) + '">'
+      + t('suppressedAnomalies') + ' ' + suppressed.length + '</div>');
+  }
+}
+
+function acknowledgementNote(anomaly) {
+  if (!anomaly.acknowledgement) {
+    return '';
+  }
+  return '<p class="mb-0 small fst-italic">' + t('acknowledged') + ' ' + escapeAttr(anomaly.acknowledgement) + '</p>';
+}
+
+function populateRecommendations(recommendations) {
+  var $list = $('#recommendationsList').empty();
+  if (!recommendations || recommendations.length === 0) {
+    $list.append('<li class="list-group-item">' + t('noRecommendations') + '</li>');
+    return;
+  }
+  recommendations.forEach(function(rec) {
+    $list.append('<li class="list-group-item">' + rec + '</li>');
+  });
+}
+
+populateAnomalies(data.anomalies || [], data.suppressedAnomalies || []);
+populateRecommendations(data.recommendations || []);`,
+	Data: func(a analysis.Analysis) any {
+		return findingsSectionData{Anomalies: a.Anomalies, SuppressedAnomalies: a.SuppressedAnomalies, Recommendations: a.Recommendations}
+	},
+}
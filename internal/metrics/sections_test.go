@@ -0,0 +1,163 @@
+// internal/metrics/sections_test.go
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/analysis"
+)
+
+// TestRenderReportSections verifies sections can be picked, reordered and skipped, that
+// unknown IDs are rejected, and that a registered section renders its markup, script and data.
+func TestRenderReportSections(t *testing.T) {
+	result := analysis.Analysis{
+		Models:          []analysis.ModelAnalysis{{ModelName: "llama3", Iterations: []analysis.IterationSample{{Iteration: 1, TokensPerSecond: 42}}}},
+		Recommendations: []string{"Use llama3"},
+	}
+
+	html, err := RenderReport(result, ReportOptions{Sections: []string{"findings", "summary"}})
+	if err != nil {
+		t.Fatalf("RenderReport returned error: %v", err)
+	}
+	if strings.Contains(html, `id="modelsTable"`) || strings.Contains(html, `"tokensPerSecond":[42]`) {
+		t.Fatalf("expected unselected sections and their data to be left out")
+	}
+	if findings, summary := strings.Index(html, `id="anomaliesList"`), strings.Index(html, `id="fastestModel"`); findings < 0 || summary < findings {
+		t.Fatalf("expected findings before summary, got %d and %d", findings, summary)
+	}
+
+	html, err = RenderReport(result, ReportOptions{SkipSections: []string{"details"}})
+	if err != nil {
+		t.Fatalf("RenderReport returned error: %v", err)
+	}
+	if strings.Contains(html, `id="modelAccordion"`) || !strings.Contains(html, `id="percentilesTable"`) {
+		t.Fatalf("expected only the details section to be skipped")
+	}
+	if err := ValidateReportSections([]string{"summary", "charts"}, nil); err == nil || !strings.Contains(err.Error(), "distribution") {
+		t.Fatalf("expected an unknown section error listing the valid IDs, got %v", err)
+	}
+
+	registered := append([]ReportSection(nil), reportSections...)
+	defer func() { reportSections = registered }()
+	if err := RegisterReportSection(ReportSection{ID: "broken", Markup: "{{ t "}); err == nil {
+		t.Fatalf("expected invalid markup to be rejected")
+	}
+	custom := ReportSection{
+		ID:     "models",
+		Markup: `<p id="modelCount">{{ t "colModel" }}</p>`,
+		Script: `$('#modelCount').text(data);`,
+		Data:   func(a analysis.Analysis) any { return len(a.Models) },
+	}
+	if err := RegisterReportSection(custom); err != nil {
+		t.Fatalf("RegisterReportSection returned error: %v", err)
+	}
+	html, err = RenderReport(result, ReportOptions{Sections: []string{"models"}})
+	if err != nil {
+		t.Fatalf("RenderReport returned error: %v", err)
+	}
+	for _, want := range []string{`<p id="modelCount">Model</p>`, `$('#modelCount').text(data);`, `"models":1`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("custom section missing %q", want)
+		}
+	}
+}
+
+// TestTemplatesSection verifies that the parameter templates section only carries models
+// that ran under several templates.
+func TestTemplatesSection(t *testing.T) {
+	iteration := func(template string) analysis.Iteration {
+		return analysis.Iteration{ParameterTemplate: template, Stats: analysis.Stats{TotalExecutionTime: 1e9, TokensPerSecond: 10}}
+	}
+	result := analysis.AnalyzeMetrics(analysis.BenchmarkResults{
+		"llama": {ModelName: "llama", Iterations: []analysis.Iteration{iteration("greedy"), iteration("creative")}},
+		"qwen":  {ModelName: "qwen", Iterations: []analysis.Iteration{iteration("")}},
+	}, analysis.HostInfo{})
+
+	html, err := GenerateReport(result)
+	if err != nil {
+		t.Fatalf("GenerateReport returned error: %v", err)
+	}
+	if !strings.Contains(html, `"templates":[{"modelName":"llama"`) {
+		t.Fatalf("expected the templates section to carry llama only")
+	}
+}
+
+// TestToolsSection verifies that the tools section only carries models that ran with tools.
+func TestToolsSection(t *testing.T) {
+	result := analysis.AnalyzeMetrics(analysis.BenchmarkResults{
+		"llama": {ModelName: "llama", Tools: true, Iterations: []analysis.Iteration{{ToolCalls: []string{"weather"}, Stats: analysis.Stats{TotalExecutionTime: 1e9}}}},
+		"qwen":  {ModelName: "qwen", Iterations: []analysis.Iteration{{Stats: analysis.Stats{TotalExecutionTime: 1e9}}}},
+	}, analysis.HostInfo{})
+
+	html, err := GenerateReport(result)
+	if err != nil {
+		t.Fatalf("GenerateReport returned error: %v", err)
+	}
+	if !strings.Contains(html, `"tools":[{"modelName":"llama","toolUse":{"iterations":1,"invoked":1`) {
+		t.Fatalf("expected the tools section to carry llama only")
+	}
+}
+
+// TestComparisonJudgeDisagreement verifies the comparison table gets each model's judge
+// disagreement rate when a second judge graded its answers.
+func TestComparisonJudgeDisagreement(t *testing.T) {
+	yes, no := true, false
+	result := analysis.AnalyzeMetrics(analysis.BenchmarkResults{
+		"llama": {ModelName: "llama", Iterations: []analysis.Iteration{{QuestionID: "essay/poem", Correct: &yes, SecondOpinion: &no, Stats: analysis.Stats{TotalExecutionTime: 1e9}}}},
+	}, analysis.HostInfo{})
+
+	html, err := GenerateReport(result)
+	if err != nil {
+		t.Fatalf("GenerateReport returned error: %v", err)
+	}
+	for _, want := range []string{"Judge Disagreement (%)", `"judging":{"judged":1,"disagreements":1,"disagreementRate":1`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in the report", want)
+		}
+	}
+}
+
+// TestLatencyHeatStrip verifies the heat strip orders iterations by question and number,
+// buckets them from the fastest to the slowest, and is carried by the rendered report.
+func TestLatencyHeatStrip(t *testing.T) {
+	sample := func(question string, iteration int, seconds float64) analysis.IterationSample {
+		return analysis.IterationSample{QuestionID: question, Iteration: iteration, TotalExecutionTimeSeconds: seconds}
+	}
+	iterations := []analysis.IterationSample{
+		sample("math", 2, 3.0),
+		sample("essay", 3, 11.0),
+		sample("math", 1, 1.0),
+		sample("essay", 4, 5.0),
+		sample("trivia", 5, 8.9),
+	}
+
+	want := []heatCell{{Index: 1, Bucket: 4}, {Index: 3, Bucket: 2}, {Index: 2, Bucket: 0}, {Index: 0, Bucket: 1}, {Index: 4, Bucket: 3}}
+	got := latencyHeatStrip(iterations)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d cells, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("cell %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+	for _, cell := range latencyHeatStrip([]analysis.IterationSample{sample("a", 1, 2), sample("b", 2, 2)}) {
+		if cell.Bucket != 0 {
+			t.Fatalf("expected equal times to share the fastest bucket, got %+v", cell)
+		}
+	}
+	if latencyHeatStrip(nil) != nil {
+		t.Fatalf("expected no strip without iterations")
+	}
+
+	html, err := RenderReport(analysis.Analysis{Models: []analysis.ModelAnalysis{{ModelName: "llama", Iterations: iterations}}}, ReportOptions{Sections: []string{"details"}})
+	if err != nil {
+		t.Fatalf("RenderReport returned error: %v", err)
+	}
+	for _, want := range []string{`"heatStrip":[{"index":1,"bucket":4},{"index":3,"bucket":2}`, ".heat-4 {", `heat-cell heat-`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected the report to contain %q", want)
+		}
+	}
+}
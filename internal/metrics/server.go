@@ -0,0 +1,251 @@
+// internal/metrics/server.go
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/journal"
+)
+
+// ReportServer serves the analysis runs under a history directory: an index of the runs
+// with a picker for comparing two of them, each run's report, and the diff of a pair.
+// Runs are listed and rendered per request, so new analyses show up without a restart.
+type ReportServer struct {
+	Dir string
+	// Journal is the experiment journal whose notes are listed beside the runs they
+	// mention; empty shows none.
+	Journal string
+	// Options renders every report; Offline pages fail to render when the assets are
+	// not bundled.
+	Options ReportOptions
+	// TolerancePercent flags regressions in comparisons; zero uses DefaultDiffTolerancePercent.
+	TolerancePercent float64
+}
+
+// Handler routes the index, /report?run=<id> and /compare?baseline=<id>&candidate=<id>.
+func (s *ReportServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveIndex)
+	mux.HandleFunc("GET /report", s.serveReport)
+	mux.HandleFunc("GET /compare", s.serveCompare)
+	return mux
+}
+
+// serveIndex lists the runs, newest first, under the comparison picker.
+func (s *ReportServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	runs, err := ListAnalysisRuns(s.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var notes []journal.Entry
+	if s.Journal != "" {
+		if notes, err = journal.Read(s.Journal); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	rows := make([]historyRow, len(runs))
+	for i, run := range runs {
+		rows[i] = historyRow{AnalysisRun: run, Notes: journal.ForRun(notes, run.ID, run.Name())}
+	}
+	html, err := s.render(func(offline bool) (string, error) {
+		styles, err := assetTags(offline, bootstrapCSS)
+		if err != nil {
+			return "", err
+		}
+		data := struct {
+			Dir    string
+			Runs   []historyRow
+			Styles template.HTML
+		}{s.Dir, rows, styles}
+		var buf bytes.Buffer
+		if err := historyIndexTemplate.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	})
+	s.write(w, html, err)
+}
+
+// historyRow is a run in the index with the journal notes that mention it.
+type historyRow struct {
+	AnalysisRun
+	Notes []journal.Entry
+}
+
+// serveReport renders the report of the run named by the run parameter.
+func (s *ReportServer) serveReport(w http.ResponseWriter, r *http.Request) {
+	result, ok := s.loadRun(w, r.URL.Query().Get("run"))
+	if !ok {
+		return
+	}
+	html, err := s.render(func(offline bool) (string, error) {
+		opts := s.Options
+		opts.Offline = offline
+		return RenderReport(result, opts)
+	})
+	s.write(w, html, err)
+}
+
+// serveCompare renders the diff of the baseline and candidate runs.
+func (s *ReportServer) serveCompare(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	baselineID, candidateID := query.Get("baseline"), query.Get("candidate")
+	baseline, ok := s.loadRun(w, baselineID)
+	if !ok {
+		return
+	}
+	candidate, ok := s.loadRun(w, candidateID)
+	if !ok {
+		return
+	}
+	tolerance := s.TolerancePercent
+	if tolerance == 0 {
+		tolerance = analysis.DefaultDiffTolerancePercent
+	}
+	diff := analysis.DiffAnalyses(baseline, candidate, tolerance)
+	diff.BaselineLabel, diff.CandidateLabel = baselineID, candidateID
+
+	html, err := s.render(func(offline bool) (string, error) {
+		return GenerateDiffReport(diff, offline)
+	})
+	s.write(w, html, err)
+}
+
+// loadRun loads the run with the given ID, answering 404 when the history has no such run.
+// Only listed runs are loaded, so an ID cannot reach files outside the history.
+func (s *ReportServer) loadRun(w http.ResponseWriter, id string) (analysis.Analysis, bool) {
+	runs, err := ListAnalysisRuns(s.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return analysis.Analysis{}, false
+	}
+	for _, run := range runs {
+		if run.ID != id {
+			continue
+		}
+		result, err := analysis.LoadAnalysis(run.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return analysis.Analysis{}, false
+		}
+		return result, true
+	}
+	http.Error(w, "no analysis run "+id, http.StatusNotFound)
+	return analysis.Analysis{}, false
+}
+
+// render renders a page with the configured assets. Offline pages fail when this build has
+// no bundled copies, rather than quietly linking their CDNs.
+func (s *ReportServer) render(page func(offline bool) (string, error)) (string, error) {
+	html, err := page(s.Options.Offline)
+	if errors.Is(err, ErrAssetsNotBundled) {
+		return "", fmt.Errorf("%w, or serve with --cdn to link them from their CDNs", err)
+	}
+	return html, err
+}
+
+// write sends a rendered page, or the error that kept it from rendering.
+func (s *ReportServer) write(w http.ResponseWriter, html string, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html))
+}
+
+var historyIndexTemplate = template.Must(template.New("history-index").Funcs(template.FuncMap{
+	"when": func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"join": strings.Join,
+}).Parse(historyIndexTemplateHTML))
+
+const historyIndexTemplateHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>agon: Analysis Runs</title>
+{{ .Styles }}  <style>
+    body { background-color: #f5f7fb; }
+    .card { border: none; }
+  </style>
+</head>
+<body>
+  <nav class="navbar navbar-dark bg-dark mb-4">
+    <div class="container-fluid">
+      <span class="navbar-brand">agon: Analysis Runs</span>
+      <span class="text-light">{{ .Dir }}</span>
+    </div>
+  </nav>
+  <main class="container-fluid">
+  {{- if not .Runs }}
+    <div class="alert alert-secondary">No analysis runs found under {{ .Dir }}. Run <code>agon analyze metrics</code> to add one.</div>
+  {{- else }}
+    {{- if gt (len .Runs) 1 }}
+    <div class="card shadow-sm mb-4">
+      <div class="card-body">
+        <h5 class="card-title">Compare runs</h5>
+        <form class="row g-2 align-items-end" action="compare" method="get">
+          <div class="col-md-5">
+            <label class="form-label" for="baseline">Baseline</label>
+            <select class="form-select" id="baseline" name="baseline">
+            {{- range $i, $run := .Runs }}
+              <option value="{{ $run.ID }}"{{ if eq $i 1 }} selected{{ end }}>{{ when $run.GeneratedAt }} · {{ $run.ID }}</option>
+            {{- end }}
+            </select>
+          </div>
+          <div class="col-md-5">
+            <label class="form-label" for="candidate">Candidate</label>
+            <select class="form-select" id="candidate" name="candidate">
+            {{- range $i, $run := .Runs }}
+              <option value="{{ $run.ID }}"{{ if eq $i 0 }} selected{{ end }}>{{ when $run.GeneratedAt }} · {{ $run.ID }}</option>
+            {{- end }}
+            </select>
+          </div>
+          <div class="col-md-2">
+            <button class="btn btn-primary w-100" type="submit">Compare</button>
+          </div>
+        </form>
+      </div>
+    </div>
+    {{- end }}
+    <div class="card shadow-sm">
+      <div class="card-body">
+        <table class="table table-sm table-hover align-middle mb-0">
+          <thead>
+            <tr><th>Generated</th><th>Cluster</th><th>Models</th><th>File</th><th></th></tr>
+          </thead>
+          <tbody>
+          {{- range .Runs }}
+            <tr>
+              <td>{{ when .GeneratedAt }}</td>
+              <td>{{ .ClusterName }}</td>
+              <td>{{ join .Models ", " }}</td>
+              <td><code>{{ .ID }}</code></td>
+              <td class="text-end"><a class="btn btn-sm btn-outline-primary" href="report?run={{ .ID }}">View report</a></td>
+            </tr>
+            {{- range .Notes }}
+            <tr class="table-light">
+              <td class="text-muted small">{{ when .Time }}</td>
+              <td colspan="4" class="small"><i>Journal:</i> {{ .Text }}{{ if .Preset }} <span class="text-muted">· preset {{ .Preset }}</span>{{ end }}</td>
+            </tr>
+            {{- end }}
+          {{- end }}
+          </tbody>
+        </table>
+      </div>
+    </div>
+  {{- end }}
+  </main>
+</body>
+</html>
+`
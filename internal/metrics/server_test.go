@@ -0,0 +1,103 @@
+// internal/metrics/server_test.go
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/mwiater/agon/analysis"
+	"github.com/mwiater/agon/internal/journal"
+)
+
+// TestReportServer verifies the history lists analyses newest first, with the journal notes
+// about them, while skipping other JSON, that runs render and compare on demand, and that
+// unknown runs are not found.
+func TestReportServer(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, value any) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	results := analysis.BenchmarkResults{"llama": {ModelName: "llama", Iterations: []analysis.Iteration{{Stats: analysis.Stats{TotalExecutionTime: 1e9, TokensPerSecond: 10}}}}}
+	older := analysis.AnalyzeMetrics(results, analysis.HostInfo{ClusterName: "lab"})
+	older.GeneratedAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	newer := older
+	newer.GeneratedAt = older.GeneratedAt.Add(time.Hour)
+	write("old.json", older)
+	write("nightly/new.json", newer)
+	write("metrics-report.manifest.json", RunManifest{RunID: "x"})
+
+	runs, err := ListAnalysisRuns(dir)
+	if err != nil || len(runs) != 2 || runs[0].ID != "nightly/new.json" || runs[1].ClusterName != "lab" || runs[1].Models[0] != "llama" {
+		t.Fatalf("unexpected runs: %+v, %v", runs, err)
+	}
+	if runs, err := ListAnalysisRuns(filepath.Join(dir, "missing")); err != nil || len(runs) != 0 {
+		t.Fatalf("expected a missing history to have no runs, got %+v, %v", runs, err)
+	}
+
+	notes := filepath.Join(t.TempDir(), "journal.md")
+	if err := journal.Append(notes, journal.Entry{Time: newer.GeneratedAt, Text: "bumped num_ctx", Runs: []string{"new"}}); err != nil {
+		t.Fatalf("append journal: %v", err)
+	}
+	server := httptest.NewServer((&ReportServer{Dir: dir, Journal: notes}).Handler())
+	defer server.Close()
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	if status, body := get("/"); status != http.StatusOK || !strings.Contains(body, `href="report?run=nightly%2fnew.json"`) || !strings.Contains(body, `action="compare"`) || strings.Count(body, "bumped num_ctx") != 1 {
+		t.Fatalf("unexpected index (%d): %s", status, body)
+	}
+	if status, body := get("/report?run=old.json"); status != http.StatusOK || !strings.Contains(body, "sectionData") {
+		t.Fatalf("unexpected report (%d)", status)
+	}
+	if status, body := get("/compare?baseline=old.json&candidate=nightly/new.json"); status != http.StatusOK || !strings.Contains(body, "nightly/new.json") {
+		t.Fatalf("unexpected comparison (%d): %s", status, body)
+	}
+	for _, path := range []string{"/report?run=metrics-report.manifest.json", "/report?run=../old.json", "/compare?baseline=old.json&candidate=gone.json"} {
+		if status, _ := get(path); status != http.StatusNotFound {
+			t.Fatalf("expected %s to be not found, got %d", path, status)
+		}
+	}
+}
+
+// TestReportServerOfflineWithoutAssets verifies an offline server reports missing bundled
+// assets instead of quietly linking their CDNs.
+func TestReportServerOfflineWithoutAssets(t *testing.T) {
+	original := bundledAssets
+	defer func() { bundledAssets = original }()
+	bundledAssets = fstest.MapFS{}
+
+	server := &ReportServer{Dir: t.TempDir(), Options: ReportOptions{Offline: true}}
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusInternalServerError || !strings.Contains(recorder.Body.String(), "--cdn") || strings.Contains(recorder.Body.String(), "https://") {
+		t.Fatalf("expected a missing assets error, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
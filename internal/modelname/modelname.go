@@ -0,0 +1,57 @@
+// internal/modelname/modelname.go
+// Package modelname provides the canonical model-name normalization shared by
+// every component that writes or reads per-model files and metrics.
+package modelname
+
+import (
+	"sort"
+	"strings"
+)
+
+// Normalize converts a model name into its canonical, filesystem-safe form.
+// Names are lowercased and any run of characters outside [a-z0-9.-] becomes a
+// single underscore, so "Llama3.2:3B" and "llama3.2:3b" both map to "llama3.2_3b".
+func Normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var builder strings.Builder
+	pendingSep := false
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			if pendingSep && builder.Len() > 0 {
+				builder.WriteByte('_')
+			}
+			pendingSep = false
+			builder.WriteRune(r)
+		default:
+			pendingSep = true
+		}
+	}
+	normalized := strings.Trim(builder.String(), ".")
+	if normalized == "" {
+		return "unknown"
+	}
+	return normalized
+}
+
+// Equal reports whether two model names refer to the same model after normalization.
+func Equal(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+// BundleStem returns a deterministic file stem for a set of models: the
+// normalized names, de-duplicated, sorted, and joined with "+".
+func BundleStem(names []string) string {
+	seen := make(map[string]struct{}, len(names))
+	stems := make([]string, 0, len(names))
+	for _, name := range names {
+		n := Normalize(name)
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		stems = append(stems, n)
+	}
+	sort.Strings(stems)
+	return strings.Join(stems, "+")
+}
@@ -0,0 +1,39 @@
+// internal/modelname/modelname_test.go
+package modelname
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "tag separator", in: "llama3.2:3b", want: "llama3.2_3b"},
+		{name: "case folded", in: "Llama3.2:3B", want: "llama3.2_3b"},
+		{name: "namespace path", in: "hf.co/org/Model-Q4:latest", want: "hf.co_org_model-q4_latest"},
+		{name: "collapsed runs", in: "  qwen  //  7b ", want: "qwen_7b"},
+		{name: "empty", in: "  ", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Normalize(tt.in); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleStem(t *testing.T) {
+	t.Parallel()
+
+	got := BundleStem([]string{"Qwen:7b", "llama3.2:3b", "qwen:7B"})
+	if want := "llama3.2_3b+qwen_7b"; got != want {
+		t.Fatalf("BundleStem() = %q, want %q", got, want)
+	}
+}
@@ -12,25 +12,34 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/k0kubun/pp"
 	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
 )
 
 // createHosts creates LLMHost implementations for each configured host entry.
 func createHosts(config appconfig.Config) []LLMHost {
 	var hosts []LLMHost
 	timeout := config.RequestTimeout()
-	client := &http.Client{
+	clients := providers.NewHostClients(&http.Client{
 		Timeout: timeout,
-	}
+	})
 	for _, hostConfig := range config.Hosts {
 		switch hostConfig.Type {
 		case "ollama":
+			client, err := clients.For(hostConfig)
+			if err != nil {
+				fmt.Printf("Skipping host %s: %v\n", hostConfig.Name, err)
+				continue
+			}
 			hosts = append(hosts, &OllamaHost{
 				Name:           hostConfig.Name,
 				URL:            hostConfig.URL,
-				Models:         hostConfig.Models,
+				Models:         hostConfig.BackendModels(),
 				client:         client,
 				requestTimeout: timeout,
 			})
+		case "anthropic":
+			// Hosted models are not pulled, deleted or listed from the host.
+			continue
 		default:
 			fmt.Printf("Unknown host type: %s\n", hostConfig.Type)
 		}
@@ -88,6 +97,11 @@ func DeleteModels(config *appconfig.Config) {
 				fmt.Printf("Deleting models is not supported for %s (%s)\n", h.GetName(), h.GetType())
 				return
 			}
+			if len(h.GetModels()) == 0 {
+				// An empty list means the models are discovered at runtime, not "keep nothing".
+				fmt.Printf("No models configured for %s; skipping model cleanup.\n", h.GetName())
+				return
+			}
 			deleteModelsOnNode(h, h.GetModels())
 		}(host)
 	}
@@ -0,0 +1,75 @@
+// internal/models/discover.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// DiscoverHostModels queries the /api/tags endpoint of every Ollama host that was
+// configured without models (or, with refresh set, whose models were discovered
+// earlier) and returns the reported models keyed by host name. Backend names are
+// mapped back to the host's aliases. Hosts that cannot be reached are reported in
+// the joined error and left out of the result.
+func DiscoverHostModels(config appconfig.Config, refresh bool) (map[string][]string, error) {
+	timeout := config.RequestTimeout()
+	clients := providers.NewHostClients(&http.Client{Timeout: timeout})
+
+	found := make(map[string][]string)
+	var errs []error
+	for _, host := range config.Hosts {
+		if host.Type != "ollama" || !needsDiscovery(host, refresh) {
+			continue
+		}
+		client, err := clients.For(host)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ollamaHost := &OllamaHost{Name: host.Name, URL: host.URL, client: client, requestTimeout: timeout}
+		names, err := ollamaHost.ListRawModels()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", host.Name, err))
+			continue
+		}
+		discovered := make([]string, 0, len(names))
+		for _, name := range names {
+			discovered = append(discovered, host.FriendlyModel(name))
+		}
+		found[host.Name] = discovered
+	}
+	return found, errors.Join(errs...)
+}
+
+// ApplyDiscoveredModels stores discovered model lists on the matching hosts and marks
+// them as discovered so later refreshes query them again.
+func ApplyDiscoveredModels(config *appconfig.Config, found map[string][]string) {
+	for i := range config.Hosts {
+		names, ok := found[config.Hosts[i].Name]
+		if !ok {
+			continue
+		}
+		config.Hosts[i].Models = names
+		config.Hosts[i].ModelsDiscovered = true
+	}
+}
+
+// DiscoverModels populates the model list of every Ollama host configured without
+// models. It is called at startup so pickers reflect what each host actually serves.
+func DiscoverModels(config *appconfig.Config) error {
+	if config == nil {
+		return nil
+	}
+	found, err := DiscoverHostModels(*config, false)
+	ApplyDiscoveredModels(config, found)
+	return err
+}
+
+// needsDiscovery reports whether a host's model list should be fetched from the host.
+func needsDiscovery(host appconfig.Host, refresh bool) bool {
+	return len(host.Models) == 0 || (refresh && host.ModelsDiscovered)
+}
@@ -0,0 +1,73 @@
+// internal/models/discover_test.go
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestDiscoverModels verifies that only hosts without configured models are queried,
+// that backend names are mapped to aliases, and that refresh re-queries discovered hosts.
+func TestDiscoverModels(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		calls++
+		if _, err := w.Write([]byte(`{"models":[{"name":"llama3.2:1b"},{"name":"hf.co/org/fast-GGUF:Q4_K_M"}]}`)); err != nil {
+			t.Fatalf("write response for /api/tags: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &appconfig.Config{
+		Hosts: []appconfig.Host{
+			{Name: "empty", URL: server.URL, Type: "ollama", ModelAliases: map[string]string{"fast": "hf.co/org/fast-GGUF:Q4_K_M"}},
+			{Name: "pinned", URL: server.URL, Type: "ollama", Models: []string{"qwen3:4b"}},
+		},
+	}
+
+	if err := DiscoverModels(cfg); err != nil {
+		t.Fatalf("DiscoverModels() error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 /api/tags call, got %d", calls)
+	}
+	got := cfg.Hosts[0].Models
+	if len(got) != 2 || got[0] != "llama3.2:1b" || got[1] != "fast" {
+		t.Fatalf("discovered models = %v", got)
+	}
+	if !cfg.Hosts[0].ModelsDiscovered || cfg.Hosts[1].ModelsDiscovered {
+		t.Fatalf("ModelsDiscovered flags = %v, %v", cfg.Hosts[0].ModelsDiscovered, cfg.Hosts[1].ModelsDiscovered)
+	}
+	if len(cfg.Hosts[1].Models) != 1 || cfg.Hosts[1].Models[0] != "qwen3:4b" {
+		t.Fatalf("configured models were overwritten: %v", cfg.Hosts[1].Models)
+	}
+
+	found, err := DiscoverHostModels(*cfg, true)
+	if err != nil {
+		t.Fatalf("DiscoverHostModels(refresh) error = %v", err)
+	}
+	if _, ok := found["empty"]; !ok || len(found) != 1 {
+		t.Fatalf("refresh queried %v, want only the discovered host", found)
+	}
+}
+
+// TestDiscoverModelsUnreachable verifies that an unreachable host is reported and left empty.
+func TestDiscoverModelsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	cfg := &appconfig.Config{Hosts: []appconfig.Host{{Name: "down", URL: server.URL, Type: "ollama"}}}
+	if err := DiscoverModels(cfg); err == nil {
+		t.Fatalf("expected an error for an unreachable host")
+	}
+	if len(cfg.Hosts[0].Models) != 0 || cfg.Hosts[0].ModelsDiscovered {
+		t.Fatalf("unreachable host should stay empty, got %v", cfg.Hosts[0].Models)
+	}
+}
@@ -0,0 +1,166 @@
+// internal/models/pull.go
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// PullProgress is one status update of a model pull. The last update of each pull has
+// Done set, and Err when the pull or its verification failed.
+type PullProgress struct {
+	Host      string
+	Model     string
+	Status    string
+	Completed int64
+	Total     int64
+	Done      bool
+	Err       error
+}
+
+// pullStatus is one line of Ollama's streamed /api/pull response.
+type pullStatus struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// PullModelWithProgress pulls model to the host, reporting every status line Ollama
+// streams. The pull is bounded by ctx rather than the request timeout, since large
+// models take far longer to download than any other request.
+func (h *OllamaHost) PullModelWithProgress(ctx context.Context, model string, progress func(PullProgress)) error {
+	body, _ := json.Marshal(map[string]any{"model": model, "stream": true})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := *h.httpClient()
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pull %s on %s: %w", model, h.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pull %s on %s: %s", model, h.Name, strings.TrimSpace(string(respBody)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	succeeded := false
+	for scanner.Scan() {
+		var status pullStatus
+		if err := json.Unmarshal(scanner.Bytes(), &status); err != nil {
+			continue
+		}
+		if status.Error != "" {
+			return fmt.Errorf("pull %s on %s: %s", model, h.Name, status.Error)
+		}
+		succeeded = status.Status == "success"
+		progress(PullProgress{Host: h.Name, Model: model, Status: status.Status, Completed: status.Completed, Total: status.Total})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("pull %s on %s: %w", model, h.Name, err)
+	}
+	if !succeeded {
+		return fmt.Errorf("pull %s on %s: the host ended the pull without reporting success", model, h.Name)
+	}
+	return nil
+}
+
+// hasModel reports whether listed contains model, treating an untagged name as ":latest".
+func hasModel(listed []string, model string) bool {
+	want := model
+	if !strings.Contains(want, ":") {
+		want += ":latest"
+	}
+	for _, name := range listed {
+		if name == model || name == want {
+			return true
+		}
+	}
+	return false
+}
+
+// PullModelsWithProgress pulls models to every configured Ollama host, or only to the
+// host named hostName, and checks each model appears in the host's model list afterwards.
+// Without models, each host pulls its configured models. Hosts pull in parallel and
+// models one at a time; progress is called from several goroutines. The returned error
+// joins every failed pull.
+func PullModelsWithProgress(ctx context.Context, config *appconfig.Config, hostName string, models []string, progress func(PullProgress)) error {
+	if config == nil {
+		return fmt.Errorf("configuration is not initialized")
+	}
+	var targets []*OllamaHost
+	for _, host := range createHosts(*config) {
+		ollama, ok := host.(*OllamaHost)
+		if !ok || (hostName != "" && !strings.EqualFold(ollama.Name, hostName)) {
+			continue
+		}
+		targets = append(targets, ollama)
+	}
+	if len(targets) == 0 {
+		if hostName != "" {
+			return fmt.Errorf("no Ollama host named %q in the configuration", hostName)
+		}
+		return fmt.Errorf("no Ollama hosts in the configuration")
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, host := range targets {
+		wanted := models
+		if len(wanted) == 0 {
+			wanted = host.GetModels()
+		}
+		wg.Add(1)
+		go func(h *OllamaHost, wanted []string) {
+			defer wg.Done()
+			for _, model := range wanted {
+				err := h.PullModelWithProgress(ctx, model, progress)
+				if err == nil {
+					err = verifyPulled(h, model)
+				}
+				final := PullProgress{Host: h.Name, Model: model, Status: "verified", Done: true, Err: err}
+				if err != nil {
+					final.Status = "failed"
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+				progress(final)
+			}
+		}(host, wanted)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// verifyPulled checks that a pulled model shows up in the host's model list.
+func verifyPulled(h *OllamaHost, model string) error {
+	listed, err := h.ListRawModels()
+	if err != nil {
+		return fmt.Errorf("verify %s on %s: %w", model, h.Name, err)
+	}
+	if !hasModel(listed, model) {
+		return fmt.Errorf("verify %s on %s: the model is not in the host's model list after the pull", model, h.Name)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// internal/models/pull_test.go
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestPullModelsWithProgress verifies streamed progress is reported, pulled models are
+// verified against the host's model list, and failed pulls are returned as errors.
+func TestPullModelsWithProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/pull":
+			var req struct {
+				Model string `json:"model"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			switch req.Model {
+			case "missing":
+				fmt.Fprintln(w, `{"error":"pull model manifest: file does not exist"}`)
+			default:
+				fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+				fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":40}`)
+				fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":100}`)
+				fmt.Fprintln(w, `{"status":"success"}`)
+			}
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"tiny:latest"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &appconfig.Config{Hosts: []appconfig.Host{
+		{Name: "HostA", URL: server.URL, Type: "ollama", Models: []string{"tiny"}},
+		{Name: "HostB", URL: server.URL, Type: "ollama", Models: []string{"unlisted:1b"}},
+	}}
+
+	var (
+		mu      sync.Mutex
+		updates []PullProgress
+	)
+	record := func(p PullProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, p)
+	}
+
+	if err := PullModelsWithProgress(context.Background(), cfg, "hosta", nil, record); err != nil {
+		t.Fatalf("PullModelsWithProgress returned error: %v", err)
+	}
+	if len(updates) != 5 || updates[2].Completed != 100 || !updates[4].Done || updates[4].Status != "verified" {
+		t.Fatalf("unexpected progress updates: %+v", updates)
+	}
+
+	updates = nil
+	err := PullModelsWithProgress(context.Background(), cfg, "", []string{"missing"}, record)
+	if err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Fatalf("expected the pull error to be returned, got %v", err)
+	}
+	err = PullModelsWithProgress(context.Background(), cfg, "HostB", nil, record)
+	if err == nil || !strings.Contains(err.Error(), "not in the host's model list") {
+		t.Fatalf("expected the verification to fail, got %v", err)
+	}
+	if err := PullModelsWithProgress(context.Background(), cfg, "HostC", nil, record); err == nil {
+		t.Fatalf("expected an unknown host to fail")
+	}
+}
@@ -9,14 +9,27 @@ import (
 	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/metrics"
 	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/providers/anthropic"
 	"github.com/mwiater/agon/internal/providers/mcp"
 	"github.com/mwiater/agon/internal/providers/ollama"
 )
 
 // NewChatProvider selects and configures the appropriate chat provider based on the
-// application configuration. It will choose between the MCP and Ollama providers
-// and wrap the selected provider with metrics collection if enabled.
+// application configuration. It will choose between the MCP provider and a router over
+// the Ollama and Anthropic providers, and wrap the selected provider with metrics
+// collection if enabled.
 func NewChatProvider(cfg *appconfig.Config) (providers.ChatProvider, error) {
+	return newChatProvider(cfg, true)
+}
+
+// NewUnscheduledChatProvider builds the provider NewChatProvider does, without the host
+// scheduler, for callers that keep one scheduler in front of the providers they rebuild.
+func NewUnscheduledChatProvider(cfg *appconfig.Config) (providers.ChatProvider, error) {
+	return newChatProvider(cfg, false)
+}
+
+// newChatProvider builds the provider stack for cfg, limiting hosts when scheduled is set.
+func newChatProvider(cfg *appconfig.Config, scheduled bool) (providers.ChatProvider, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("nil config provided to provider factory")
 	}
@@ -27,12 +40,20 @@ func NewChatProvider(cfg *appconfig.Config) (providers.ChatProvider, error) {
 	if cfg.MCPMode {
 		provider, err = mcp.New(context.Background(), cfg)
 		if err != nil {
-			logging.LogEvent("MCP provider unavailable: %v", err)
+			logging.LogError("MCP provider unavailable: %v", err)
 			return nil, err
 		}
 		logging.LogEvent("MCP provider ready: using local server")
 	} else {
-		provider = ollama.New(cfg)
+		provider = NewHostRouter(cfg)
+	}
+
+	if scheduled && (cfg.MaxInFlight > 0 || hasHostLimits(cfg.Hosts)) {
+		provider = providers.NewScheduler(provider, cfg.HostLimits)
+	}
+
+	if cfg.JSONStreamGuard != nil {
+		provider = providers.NewJSONGuard(provider, *cfg.JSONStreamGuard)
 	}
 
 	if cfg.Metrics {
@@ -42,3 +63,21 @@ func NewChatProvider(cfg *appconfig.Config) (providers.ChatProvider, error) {
 
 	return provider, nil
 }
+
+// hasHostLimits reports whether any host sets its own in-flight limit.
+func hasHostLimits(hosts []appconfig.Host) bool {
+	for _, host := range hosts {
+		if host.MaxInFlight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHostRouter returns a provider that sends anthropic hosts to the Anthropic provider and
+// every other host to the Ollama provider.
+func NewHostRouter(cfg *appconfig.Config) *providers.Router {
+	return providers.NewRouter(ollama.New(cfg), map[string]providers.ChatProvider{
+		anthropic.HostType: anthropic.New(cfg),
+	})
+}
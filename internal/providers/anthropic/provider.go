@@ -0,0 +1,465 @@
+// internal/providers/anthropic/provider.go
+// Package anthropic provides a ChatProvider backed by the Anthropic Messages API, so hosted
+// Claude models can be chatted with and benchmarked alongside local models.
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/logging"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+const (
+	// HostType is the host "type" routed to this provider.
+	HostType = "anthropic"
+	// DefaultBaseURL is used when an anthropic host has no URL.
+	DefaultBaseURL = "https://api.anthropic.com"
+	// APIKeyEnv names the environment variable the API key is read from.
+	APIKeyEnv = "ANTHROPIC_API_KEY"
+	// apiVersion is the Messages API version requested.
+	apiVersion = "2023-06-01"
+	// defaultMaxTokens caps each response; the API requires an explicit limit.
+	defaultMaxTokens = 4096
+	// contextLength is the context window of current Claude models, in tokens.
+	contextLength = 200_000
+)
+
+// errMissingAPIKey reports a host without credentials.
+var errMissingAPIKey = errors.New("no API key: set " + APIKeyEnv + " or an x-api-key header on the host")
+
+// providerLog logs requests and responses under the provider subsystem.
+var providerLog = logging.For(logging.SubsystemProvider)
+
+// Provider implements the providers.ChatProvider interface using the Anthropic Messages API.
+type Provider struct {
+	clients *providers.HostClients
+	timeout time.Duration
+	apiKey  string
+}
+
+// New constructs a Provider configured with the application's request timeout and the
+// API key from the environment.
+func New(cfg *appconfig.Config) *Provider {
+	timeout := cfg.RequestTimeout()
+	return &Provider{
+		clients: providers.NewHostClients(&http.Client{Timeout: timeout}),
+		timeout: timeout,
+		apiKey:  strings.TrimSpace(os.Getenv(APIKeyEnv)),
+	}
+}
+
+// message is one turn of the Messages API conversation.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// tool is a tool definition in the Messages API format.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// usage is the token accounting reported by the API.
+type usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// contentBlock is a text or tool_use block of a response.
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// response is a complete, non-streamed Messages API response.
+type response struct {
+	Model      string         `json:"model"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+// streamEvent is one server-sent event of a streamed response. Only the fields of the
+// event types agon uses are decoded.
+type streamEvent struct {
+	Type         string       `json:"type"`
+	Index        int          `json:"index"`
+	Message      response     `json:"message"`
+	ContentBlock contentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage usage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// LoadedModels returns the host's configured models: hosted models need no loading.
+func (p *Provider) LoadedModels(ctx context.Context, host appconfig.Host) ([]string, error) {
+	return append([]string(nil), host.Models...), nil
+}
+
+// ModelCapabilities reports what every current Claude model supports: tools, image input
+// and a 200K-token context window. JSON mode is only asked for in the system prompt, so
+// output is not constrained.
+func (p *Provider) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (providers.Capabilities, error) {
+	return providers.Capabilities{Known: true, Tools: true, Vision: true, ContextLength: contextLength}, nil
+}
+
+// EnsureModelReady checks that the host has credentials; hosted models are always loaded.
+func (p *Provider) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
+	if p.apiKey == "" && !hasHeader(host, "x-api-key") {
+		return &providers.Error{Op: "anthropic", Host: hostIdentifier(host), Model: model, Err: errMissingAPIKey}
+	}
+	return nil
+}
+
+// Stream sends a Messages API request and forwards the response to the callbacks. Text
+// deltas become chunks; tool_use blocks are run through the request's ToolExecutor and
+// their output is sent as a final chunk, as the Ollama provider does. Token counts come
+// from the usage the API reports.
+func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
+	hostID := hostIdentifier(req.Host)
+	if err := p.EnsureModelReady(ctx, req.Host, req.Model); err != nil {
+		return err
+	}
+
+	payload := p.buildPayload(req)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	providerLog.Request("AGON->LLM", hostID, req.Model, "", body)
+
+	streamCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodPost, baseURL(req.Host)+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", apiVersion)
+	if p.apiKey != "" {
+		httpReq.Header.Set("x-api-key", p.apiKey)
+	}
+	client, err := p.clients.For(req.Host)
+	if err != nil {
+		return err
+	}
+
+	var timing streamTiming
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { timing.firstByteAt = time.Now() },
+	}))
+
+	timing.requestStart = time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return providers.TransportError("anthropic /v1/messages", hostID, req.Model, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		providerLog.Request("LLM->AGON", hostID, req.Model, "", body)
+		return providers.StatusError("anthropic /v1/messages", hostID, req.Model, resp.StatusCode, body)
+	}
+
+	var result response
+	if req.DisableStreaming {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return providers.TransportError("anthropic /v1/messages", hostID, req.Model, err)
+		}
+		providerLog.Request("LLM->AGON", hostID, req.Model, "", data)
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+		timing.recordChunk(time.Now(), true)
+		var text strings.Builder
+		for _, block := range result.Content {
+			text.WriteString(block.Text)
+		}
+		if err := emit(callbacks, text.String()); err != nil {
+			return err
+		}
+	} else {
+		result, err = p.readStream(resp.Body, req, hostID, &timing, callbacks)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output, err := executeToolUses(ctx, req, result.Content); err != nil {
+		return err
+	} else if err := emit(callbacks, output); err != nil {
+		return err
+	}
+
+	if callbacks.OnComplete == nil {
+		return nil
+	}
+	modelName := req.Host.FriendlyModel(result.Model)
+	if modelName == "" {
+		modelName = req.Model
+	}
+	end := time.Now()
+	meta := providers.StreamMetadata{
+		Model:           modelName,
+		CreatedAt:       end,
+		Done:            true,
+		TotalDuration:   end.Sub(timing.requestStart).Nanoseconds(),
+		PromptEvalCount: result.Usage.InputTokens,
+		EvalCount:       result.Usage.OutputTokens,
+	}
+	// The API reports no server-side timings: split the wall clock at the first streamed
+	// token, or count it all as generation when nothing was streamed.
+	if !req.DisableStreaming && !timing.firstTokenAt.IsZero() {
+		meta.PromptEvalDuration = timing.firstTokenAt.Sub(timing.requestStart).Nanoseconds()
+		meta.EvalDuration = end.Sub(timing.firstTokenAt).Nanoseconds()
+	} else {
+		meta.EvalDuration = meta.TotalDuration
+	}
+	timing.apply(&meta)
+	return callbacks.OnComplete(meta)
+}
+
+// readStream decodes the server-sent events of a streamed response, forwarding text deltas
+// as chunks and assembling the final message, including tool_use blocks and usage.
+func (p *Provider) readStream(body io.Reader, req providers.StreamRequest, hostID string, timing *streamTiming, callbacks providers.StreamCallbacks) (response, error) {
+	var result response
+	toolInput := make(map[int]*strings.Builder)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		providerLog.Request("LLM->AGON", hostID, req.Model, "", []byte(data))
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return result, fmt.Errorf("anthropic: unable to decode stream event: %w", err)
+		}
+		timing.recordChunk(time.Now(), event.Type == "content_block_delta" && event.Delta.Text != "")
+
+		switch event.Type {
+		case "message_start":
+			result.Model = event.Message.Model
+			result.Usage = event.Message.Usage
+		case "content_block_start":
+			for len(result.Content) <= event.Index {
+				result.Content = append(result.Content, contentBlock{})
+			}
+			result.Content[event.Index] = event.ContentBlock
+			if event.ContentBlock.Type == "tool_use" {
+				toolInput[event.Index] = &strings.Builder{}
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				if err := emit(callbacks, event.Delta.Text); err != nil {
+					return result, err
+				}
+			case "input_json_delta":
+				if input, ok := toolInput[event.Index]; ok {
+					input.WriteString(event.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			result.StopReason = event.Delta.StopReason
+			if event.Usage.OutputTokens > 0 {
+				result.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+			if event.Usage.InputTokens > 0 {
+				result.Usage.InputTokens = event.Usage.InputTokens
+			}
+		case "error":
+			return result, &providers.Error{Op: "anthropic /v1/messages", Host: hostID, Model: req.Model,
+				Err: fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message)}
+		case "message_stop":
+			for index, input := range toolInput {
+				if input.Len() > 0 {
+					result.Content[index].Input = json.RawMessage(input.String())
+				}
+			}
+			return result, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, providers.TransportError("anthropic /v1/messages", hostID, req.Model, err)
+	}
+	return result, providers.TransportError("anthropic /v1/messages", hostID, req.Model, io.ErrUnexpectedEOF)
+}
+
+// buildPayload converts a stream request into a Messages API request body. System messages
+// in the history are folded into the system prompt, which the API takes separately.
+func (p *Provider) buildPayload(req providers.StreamRequest) map[string]any {
+	system := []string{}
+	if strings.TrimSpace(req.SystemPrompt) != "" {
+		system = append(system, req.SystemPrompt)
+	}
+	messages := make([]message, 0, len(req.History))
+	for _, msg := range req.History {
+		switch msg.Role {
+		case "system":
+			system = append(system, msg.Content)
+		case "assistant":
+			messages = append(messages, message{Role: "assistant", Content: msg.Content})
+		default:
+			messages = append(messages, message{Role: "user", Content: msg.Content})
+		}
+	}
+	if req.JSONMode {
+		system = append(system, "Respond with a single valid JSON value and nothing else.")
+	}
+
+	payload := map[string]any{
+		"model":      req.Host.ResolveModel(req.Model),
+		"max_tokens": defaultMaxTokens,
+		"messages":   messages,
+		"stream":     !req.DisableStreaming,
+	}
+	if len(system) > 0 {
+		payload["system"] = strings.Join(system, "\n\n")
+	}
+	if req.Parameters.Temperature != nil {
+		payload["temperature"] = *req.Parameters.Temperature
+	}
+	if req.Parameters.TopP != nil {
+		payload["top_p"] = *req.Parameters.TopP
+	}
+	if req.Parameters.TopK != nil {
+		payload["top_k"] = *req.Parameters.TopK
+	}
+	if len(req.Tools) > 0 {
+		tools := make([]tool, len(req.Tools))
+		for i, def := range req.Tools {
+			schema := def.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			tools[i] = tool{Name: def.Name, Description: def.Description, InputSchema: schema}
+		}
+		payload["tools"] = tools
+	}
+	return payload
+}
+
+// executeToolUses runs the response's tool_use blocks and returns their combined output.
+// Without an executor the requested calls are summarized instead.
+func executeToolUses(ctx context.Context, req providers.StreamRequest, blocks []contentBlock) (string, error) {
+	var outputs []string
+	for _, block := range blocks {
+		if block.Type != "tool_use" {
+			continue
+		}
+		if req.ToolExecutor == nil {
+			outputs = append(outputs, fmt.Sprintf("[Tool call requested] %s args: %s", block.Name, string(block.Input)))
+			continue
+		}
+		args := map[string]any{}
+		if len(block.Input) > 0 {
+			if err := json.Unmarshal(block.Input, &args); err != nil {
+				return "", fmt.Errorf("anthropic: invalid arguments for tool %s: %w", block.Name, err)
+			}
+		}
+		result, err := req.ToolExecutor(ctx, block.Name, args)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(result) != "" {
+			outputs = append(outputs, fmt.Sprintf("[Tool %s]\n%s", block.Name, result))
+		}
+	}
+	return strings.Join(outputs, "\n\n"), nil
+}
+
+// emit forwards non-empty text to OnChunk as an assistant message.
+func emit(callbacks providers.StreamCallbacks, text string) error {
+	if callbacks.OnChunk == nil || text == "" {
+		return nil
+	}
+	return callbacks.OnChunk(providers.ChatMessage{Role: "assistant", Content: text})
+}
+
+// hasHeader reports whether the host configures the named header.
+func hasHeader(host appconfig.Host, name string) bool {
+	for key, value := range host.Headers {
+		if strings.EqualFold(key, name) && strings.TrimSpace(value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// baseURL returns the host's API base URL without a trailing slash.
+func baseURL(host appconfig.Host) string {
+	if url := strings.TrimRight(strings.TrimSpace(host.URL), "/"); url != "" {
+		return url
+	}
+	return DefaultBaseURL
+}
+
+// hostIdentifier names the host in logs and errors.
+func hostIdentifier(host appconfig.Host) string {
+	if name := strings.TrimSpace(host.Name); name != "" {
+		return name
+	}
+	return baseURL(host)
+}
+
+// streamTiming captures transport-level timestamps while a response is read.
+type streamTiming struct {
+	requestStart time.Time
+	firstByteAt  time.Time
+	firstTokenAt time.Time
+	chunkTimes   []time.Time
+}
+
+// recordChunk notes when an event was decoded and whether it carried text.
+func (t *streamTiming) recordChunk(at time.Time, hasContent bool) {
+	t.chunkTimes = append(t.chunkTimes, at)
+	if hasContent && t.firstTokenAt.IsZero() {
+		t.firstTokenAt = at
+	}
+}
+
+// apply copies the captured timestamps onto the stream metadata.
+func (t *streamTiming) apply(meta *providers.StreamMetadata) {
+	meta.RequestStart = t.requestStart
+	meta.FirstByteAt = t.firstByteAt
+	meta.FirstTokenAt = t.firstTokenAt
+	meta.ChunkTimes = t.chunkTimes
+}
+
+// Close releases any resources held by the provider.
+func (p *Provider) Close() error {
+	return nil
+}
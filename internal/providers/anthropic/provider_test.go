@@ -0,0 +1,157 @@
+// internal/providers/anthropic/provider_test.go
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// sseStream renders server-sent events in the Messages API format.
+func sseStream(events ...string) string {
+	var b strings.Builder
+	for _, event := range events {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(event), &typed)
+		fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", typed.Type, event)
+	}
+	return b.String()
+}
+
+// TestProviderStreamMapsEvents verifies that streamed text deltas reach OnChunk, that token
+// counts come from the usage events, and that the request carries the API headers and
+// Messages API payload.
+func TestProviderStreamMapsEvents(t *testing.T) {
+	t.Setenv(APIKeyEnv, "test-key")
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			t.Fatalf("missing API headers: %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(sseStream(
+			`{"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":12,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+			`{"type":"message_stop"}`,
+		)))
+	}))
+	defer server.Close()
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	temperature := 0.2
+	req := providers.StreamRequest{
+		Host:         appconfig.Host{Name: "claude", URL: server.URL, Type: HostType},
+		Model:        "claude-test",
+		SystemPrompt: "Be brief.",
+		Parameters:   appconfig.Parameters{Temperature: &temperature},
+		History:      []providers.ChatMessage{{Role: "user", Content: "Hi"}},
+	}
+
+	var text strings.Builder
+	var meta providers.StreamMetadata
+	err := provider.Stream(context.Background(), req, providers.StreamCallbacks{
+		OnChunk: func(msg providers.ChatMessage) error {
+			text.WriteString(msg.Content)
+			return nil
+		},
+		OnComplete: func(m providers.StreamMetadata) error {
+			meta = m
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	if text.String() != "Hello there" {
+		t.Fatalf("unexpected streamed text: %q", text.String())
+	}
+	if meta.PromptEvalCount != 12 || meta.EvalCount != 7 || meta.Model != "claude-test" || meta.EvalDuration <= 0 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	if payload["system"] != "Be brief." || payload["temperature"] != 0.2 || payload["stream"] != true || payload["max_tokens"] == nil {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
+
+// TestProviderStreamExecutesToolUse verifies that a streamed tool_use block is assembled from
+// its JSON deltas and run through the request's ToolExecutor.
+func TestProviderStreamExecutesToolUse(t *testing.T) {
+	t.Setenv(APIKeyEnv, "test-key")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(sseStream(
+			`{"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":30}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"current_weather","input":{}}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\": "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":15}}`,
+			`{"type":"message_stop"}`,
+		)))
+	}))
+	defer server.Close()
+
+	var gotArgs map[string]any
+	req := providers.StreamRequest{
+		Host:    appconfig.Host{Name: "claude", URL: server.URL, Type: HostType},
+		Model:   "claude-test",
+		History: []providers.ChatMessage{{Role: "user", Content: "Weather in Paris?"}},
+		Tools:   []providers.ToolDefinition{{Name: "current_weather"}},
+		ToolExecutor: func(ctx context.Context, name string, args map[string]any) (string, error) {
+			gotArgs = args
+			return "sunny", nil
+		},
+	}
+
+	var output string
+	err := New(&appconfig.Config{TimeoutSeconds: 5}).Stream(context.Background(), req, providers.StreamCallbacks{
+		OnChunk: func(msg providers.ChatMessage) error {
+			output += msg.Content
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	if gotArgs["city"] != "Paris" || output != "[Tool current_weather]\nsunny" {
+		t.Fatalf("tool call not executed: args=%v output=%q", gotArgs, output)
+	}
+}
+
+// TestProviderRequiresAPIKey verifies that a host without credentials fails before any request.
+func TestProviderRequiresAPIKey(t *testing.T) {
+	t.Setenv(APIKeyEnv, "")
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	err := provider.EnsureModelReady(context.Background(), appconfig.Host{Name: "claude", Type: HostType}, "claude-test")
+	if !errors.Is(err, errMissingAPIKey) {
+		t.Fatalf("expected errMissingAPIKey, got %v", err)
+	}
+
+	withHeader := appconfig.Host{Name: "claude", Type: HostType, Headers: map[string]string{"X-Api-Key": "from-config"}}
+	if err := provider.EnsureModelReady(context.Background(), withHeader, "claude-test"); err != nil {
+		t.Fatalf("a configured x-api-key header should be accepted: %v", err)
+	}
+}
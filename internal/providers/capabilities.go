@@ -0,0 +1,82 @@
+// internal/providers/capabilities.go
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// Capabilities records which request features a model supports on a host.
+type Capabilities struct {
+	// Known is false when the host could not say; nothing is then assumed to be missing.
+	Known bool
+	// JSON reports that JSON mode constrains the output, rather than only asking for it.
+	JSON bool
+	// Tools reports native tool calling, which MCP mode needs.
+	Tools bool
+	// Vision reports image input.
+	Vision bool
+	// ContextLength is the model's context window in tokens; zero when the host did not say.
+	ContextLength int
+}
+
+// Badges lists the supported features for pickers, e.g. ["json", "tools"].
+func (c Capabilities) Badges() []string {
+	var badges []string
+	if c.JSON {
+		badges = append(badges, "json")
+	}
+	if c.Tools {
+		badges = append(badges, "tools")
+	}
+	if c.Vision {
+		badges = append(badges, "vision")
+	}
+	return badges
+}
+
+// String renders the badges for pickers, e.g. "[json] [tools]", or "" when unknown.
+func (c Capabilities) String() string {
+	badges := c.Badges()
+	for i, badge := range badges {
+		badges[i] = "[" + badge + "]"
+	}
+	return strings.Join(badges, " ")
+}
+
+// CheckCapabilities reports whether a model with caps can serve a request in JSON mode or
+// with tools. A model without tool calling cannot run MCP mode at all, so that returns an
+// ErrUnsupported error; a model that cannot constrain JSON only gets a warning, since it
+// may still follow the instruction. Unknown capabilities pass.
+func CheckCapabilities(caps Capabilities, host, model string, jsonMode, tools bool) (warning string, err error) {
+	if !caps.Known {
+		return "", nil
+	}
+	if tools && !caps.Tools {
+		return "", &Error{Kind: ErrUnsupported, Op: "tools", Host: host, Model: model, Err: errors.New("model does not support tool calling")}
+	}
+	if jsonMode && !caps.JSON {
+		return fmt.Sprintf("%s on %s cannot constrain JSON output; responses may not be valid JSON", model, host), nil
+	}
+	return "", nil
+}
+
+// CapabilityReporter is implemented by providers that can tell which features a model supports.
+type CapabilityReporter interface {
+	// ModelCapabilities returns the features model supports on host.
+	ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (Capabilities, error)
+}
+
+// ReportModelCapabilities queries model's capabilities through provider. Providers that
+// cannot report them return unknown capabilities.
+func ReportModelCapabilities(ctx context.Context, provider ChatProvider, host appconfig.Host, model string) (Capabilities, error) {
+	reporter, ok := provider.(CapabilityReporter)
+	if !ok {
+		return Capabilities{}, nil
+	}
+	return reporter.ModelCapabilities(ctx, host, model)
+}
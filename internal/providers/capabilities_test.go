@@ -0,0 +1,25 @@
+// internal/providers/capabilities_test.go
+package providers
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCheckCapabilities verifies that missing tools block MCP mode, that JSON mode without
+// constrained output only warns, and that unknown capabilities pass.
+func TestCheckCapabilities(t *testing.T) {
+	chatOnly := Capabilities{Known: true, JSON: true}
+	if _, err := CheckCapabilities(chatOnly, "gpu", "gemma", false, true); !errors.Is(err, ErrUnsupported) || Retryable(err) {
+		t.Fatalf("expected a model without tools to be blocked in MCP mode, got %v", err)
+	}
+	if warning, err := CheckCapabilities(Capabilities{Known: true, Tools: true}, "cloud", "claude", true, true); err != nil || warning == "" {
+		t.Fatalf("expected a warning for JSON mode without constrained output, got %q, %v", warning, err)
+	}
+	if warning, err := CheckCapabilities(Capabilities{}, "llama", "any", true, true); err != nil || warning != "" {
+		t.Fatalf("expected unknown capabilities to pass, got %q, %v", warning, err)
+	}
+	if got := (Capabilities{Known: true, JSON: true, Tools: true, Vision: true}).String(); got != "[json] [tools] [vision]" {
+		t.Fatalf("unexpected badges %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+// internal/providers/errors.go
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Sentinel categories for provider failures. Match them with errors.Is.
+var (
+	// ErrTimeout reports a request that exceeded its deadline.
+	ErrTimeout = errors.New("request timed out")
+	// ErrModelNotFound reports a model the host does not have.
+	ErrModelNotFound = errors.New("model not found")
+	// ErrContextOverflow reports a prompt that exceeds the model's context window.
+	ErrContextOverflow = errors.New("context window exceeded")
+	// ErrConnectionRefused reports a host that could not be reached.
+	ErrConnectionRefused = errors.New("connection refused")
+	// ErrUnsupported reports a request feature, such as tools, the model does not support.
+	ErrUnsupported = errors.New("feature not supported by model")
+	// ErrHostBusy reports a request turned away because its host's queue was full.
+	ErrHostBusy = errors.New("host queue is full")
+)
+
+// Error describes a failed provider call: its category, where it happened, and the
+// underlying cause. errors.Is matches both the category sentinel and the cause.
+type Error struct {
+	// Kind is one of the sentinel categories, or nil when the failure is uncategorized.
+	Kind error
+	// Op names the operation that failed, for example "chat" or "ps".
+	Op string
+	// Host and Model identify the target of the request.
+	Host  string
+	Model string
+	// Status is the HTTP status code, when the host answered.
+	Status int
+	// Detail is the response body or other diagnostic text from the host.
+	Detail string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Host != "" {
+		b.WriteString(" " + e.Host)
+	}
+	if e.Model != "" {
+		b.WriteString(" (" + e.Model + ")")
+	}
+	b.WriteString(": ")
+	switch {
+	case e.Status != 0:
+		b.WriteString(fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)))
+		if e.Detail != "" {
+			b.WriteString(": " + e.Detail)
+		}
+	case e.Err != nil:
+		b.WriteString(e.Err.Error())
+	case e.Kind != nil:
+		b.WriteString(e.Kind.Error())
+	}
+	return b.String()
+}
+
+// Unwrap exposes both the category and the cause to errors.Is and errors.As.
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// TransportError categorizes an error returned while sending a request or reading its response.
+func TransportError(op, host, model string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var kind error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		// Cancellation is a caller decision, not a provider failure; leave it uncategorized.
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		kind = ErrTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), isNoSuchHost(err):
+		kind = ErrConnectionRefused
+	}
+	return &Error{Kind: kind, Op: op, Host: host, Model: model, Err: err}
+}
+
+// StatusError categorizes a non-success HTTP response using its status and body.
+func StatusError(op, host, model string, status int, body []byte) error {
+	detail := strings.TrimSpace(string(body))
+	lower := strings.ToLower(detail)
+	var kind error
+	switch {
+	case strings.Contains(lower, "context length") || strings.Contains(lower, "context window") ||
+		strings.Contains(lower, "exceeds the context") || strings.Contains(lower, "too many tokens"):
+		kind = ErrContextOverflow
+	case strings.Contains(lower, "does not support"):
+		kind = ErrUnsupported
+	case status == http.StatusNotFound || (strings.Contains(lower, "model") && strings.Contains(lower, "not found")):
+		kind = ErrModelNotFound
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		kind = ErrTimeout
+	}
+	return &Error{Kind: kind, Op: op, Host: host, Model: model, Status: status, Detail: detail}
+}
+
+// isNoSuchHost reports whether err is a DNS lookup failure for an unknown host.
+func isNoSuchHost(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// ErrorCategory returns a short, stable label for err, for grouping failures in metrics.
+func ErrorCategory(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrModelNotFound):
+		return "model_not_found"
+	case errors.Is(err, ErrContextOverflow):
+		return "context_overflow"
+	case errors.Is(err, ErrConnectionRefused):
+		return "connection_refused"
+	case errors.Is(err, ErrUnsupported):
+		return "unsupported"
+	case errors.Is(err, ErrHostBusy):
+		return "host_busy"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "other"
+	}
+}
+
+// Retryable reports whether repeating the request could succeed. Missing models,
+// oversized prompts and unsupported features fail the same way every time; timeouts and
+// unreachable hosts may not.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return !errors.Is(err, ErrModelNotFound) && !errors.Is(err, ErrContextOverflow) && !errors.Is(err, ErrUnsupported)
+}
+
+// UserMessage returns a short explanation of err with a suggested fix, for display in the UI.
+func UserMessage(err error) string {
+	var perr *Error
+	target := "the host"
+	model := "the model"
+	if errors.As(err, &perr) {
+		if perr.Host != "" {
+			target = perr.Host
+		}
+		if perr.Model != "" {
+			model = perr.Model
+		}
+	}
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrTimeout):
+		return fmt.Sprintf("%s did not answer in time. Increase \"timeout\" in the config or try a smaller model.", target)
+	case errors.Is(err, ErrModelNotFound):
+		return fmt.Sprintf("%s is not available on %s. Run 'agon pull models' or check the model name.", model, target)
+	case errors.Is(err, ErrContextOverflow):
+		return fmt.Sprintf("The conversation is too long for %s's context window. Shorten the prompt or start a new chat.", model)
+	case errors.Is(err, ErrUnsupported):
+		return fmt.Sprintf("%s does not support this request on %s. In MCP mode, pick a model with the [tools] badge.", model, target)
+	case errors.Is(err, ErrConnectionRefused):
+		return fmt.Sprintf("Could not connect to %s. Check that the server is running and the URL is correct.", target)
+	case errors.Is(err, ErrHostBusy):
+		return fmt.Sprintf("%s already has a full queue of requests. Try again shortly or raise \"maxQueued\" in the config.", target)
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,111 @@
+// internal/providers/hostclient.go
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// HostClients hands out HTTP clients that apply each host's custom headers and proxy.
+// Hosts without either share the base client; the others get one client each, built
+// on first use and reused so connections stay pooled.
+type HostClients struct {
+	base    *http.Client
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewHostClients wraps base, which supplies the timeout and transport settings.
+func NewHostClients(base *http.Client) *HostClients {
+	if base == nil {
+		base = &http.Client{}
+	}
+	return &HostClients{base: base, clients: make(map[string]*http.Client)}
+}
+
+// For returns the client to use for requests to host.
+func (c *HostClients) For(host appconfig.Host) (*http.Client, error) {
+	if len(host.Headers) == 0 && strings.TrimSpace(host.Proxy) == "" {
+		return c.base, nil
+	}
+	key := hostClientKey(host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+	client, err := newHostClient(c.base, host)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[key] = client
+	return client, nil
+}
+
+// newHostClient copies base with a transport that routes through the host's proxy and
+// adds its headers.
+func newHostClient(base *http.Client, host appconfig.Host) (*http.Client, error) {
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	proxy, err := host.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		httpTransport, ok := transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("host %q: proxy needs an *http.Transport, have %T", host.Name, transport)
+		}
+		httpTransport = httpTransport.Clone()
+		httpTransport.Proxy = http.ProxyURL(proxy)
+		transport = httpTransport
+	}
+	if len(host.Headers) > 0 {
+		transport = &headerTransport{base: transport, headers: host.Headers}
+	}
+	client := *base
+	client.Transport = transport
+	return &client, nil
+}
+
+// headerTransport sets the configured headers on every request before sending it.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// hostClientKey identifies a host's header and proxy settings, so a host whose
+// settings change gets a new client.
+func hostClientKey(host appconfig.Host) string {
+	names := make([]string, 0, len(host.Headers))
+	for name := range host.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%s\x00%s", host.Name, host.URL, host.Proxy)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\x00%s=%s", name, host.Headers[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+// internal/providers/hostclient_test.go
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestHostClientsApplyHeadersAndProxy verifies that a host's headers are sent with its
+// requests, that its proxy receives them, and that plain hosts share the base client.
+func TestHostClientsApplyHeadersAndProxy(t *testing.T) {
+	var gotAuth, gotURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotURL = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	base := &http.Client{Transport: &http.Transport{}}
+	clients := NewHostClients(base)
+
+	plain, err := clients.For(appconfig.Host{Name: "plain", URL: "http://plain:11434"})
+	if err != nil || plain != base {
+		t.Fatalf("plain host should share the base client, got %p (%v)", plain, err)
+	}
+
+	host := appconfig.Host{
+		Name:    "gated",
+		URL:     "http://ollama.internal:11434",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Proxy:   proxy.URL,
+	}
+	client, err := clients.For(host)
+	if err != nil {
+		t.Fatalf("For returned error: %v", err)
+	}
+	if again, _ := clients.For(host); again != client {
+		t.Fatalf("expected the host's client to be reused")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, host.URL+"/api/ps", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAuth != "Bearer secret" {
+		t.Fatalf("Authorization header = %q", gotAuth)
+	}
+	if gotURL != host.URL+"/api/ps" {
+		t.Fatalf("proxy saw URL %q, want the host's absolute URL", gotURL)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Fatalf("caller's request was modified")
+	}
+
+	if _, err := clients.For(appconfig.Host{Name: "bad", Proxy: "not a url"}); err == nil {
+		t.Fatalf("expected an error for an invalid proxy URL")
+	}
+}
@@ -0,0 +1,184 @@
+// internal/providers/jsonguard.go
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/logging"
+)
+
+// jsonGuardWindow is how many bytes at the start of a JSON-mode response are held back
+// and checked. A prose preamble shows up within it, and holding back more would delay
+// what the user sees.
+const jsonGuardWindow = 256
+
+// errInvalidJSONStream stops a JSON-mode response that can no longer be valid JSON.
+var errInvalidJSONStream = errors.New("response is not valid JSON")
+
+// providerLog logs under the provider subsystem.
+var providerLog = logging.For(logging.SubsystemProvider)
+
+// JSONGuard is a decorator that watches the start of JSON-mode responses and, as soon as
+// one cannot be valid JSON, aborts it and asks again with a corrective nudge added to the
+// system prompt. The last attempt streams unchecked so the caller's own validation and
+// repair still run on it.
+type JSONGuard struct {
+	wrapped ChatProvider
+	retries int
+	nudge   string
+}
+
+// NewJSONGuard wraps provider with the JSON stream guard configured by cfg.
+func NewJSONGuard(wrapped ChatProvider, cfg appconfig.JSONStreamGuardConfig) *JSONGuard {
+	return &JSONGuard{wrapped: wrapped, retries: cfg.RetryCount(), nudge: cfg.NudgePrompt()}
+}
+
+// Unwrap returns the wrapped provider.
+func (g *JSONGuard) Unwrap() ChatProvider {
+	return g.wrapped
+}
+
+// Stream passes requests outside JSON mode straight through and guards the others.
+func (g *JSONGuard) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	if !req.JSONMode {
+		return g.wrapped.Stream(ctx, req, callbacks)
+	}
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq.SystemPrompt = strings.TrimSpace(req.SystemPrompt + "\n\n" + g.nudge)
+		}
+		if attempt >= g.retries {
+			return g.wrapped.Stream(ctx, attemptReq, callbacks)
+		}
+		text, err := g.streamChecked(ctx, attemptReq, callbacks)
+		if !errors.Is(err, errInvalidJSONStream) || ctx.Err() != nil {
+			return err
+		}
+		providerLog.Warnf("[JSON] %s (%s) started a response that is not JSON, retrying with a nudge (attempt %d of %d): %q",
+			req.Host.Name, req.Model, attempt+1, g.retries, text)
+	}
+}
+
+// streamChecked streams one attempt, holding back the first jsonGuardWindow bytes until
+// they are known to start a JSON value. It returns errInvalidJSONStream and the text
+// seen so far when they are not; nothing of that attempt reaches the caller.
+func (g *JSONGuard) streamChecked(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) (string, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		held     []ChatMessage
+		text     strings.Builder
+		checking = true
+		aborted  = false
+	)
+	flush := func() error {
+		for _, chunk := range held {
+			if callbacks.OnChunk != nil {
+				if err := callbacks.OnChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+		held = nil
+		return nil
+	}
+	wrapped := StreamCallbacks{
+		OnChunk: func(chunk ChatMessage) error {
+			if !checking {
+				if callbacks.OnChunk != nil {
+					return callbacks.OnChunk(chunk)
+				}
+				return nil
+			}
+			text.WriteString(chunk.Content)
+			held = append(held, chunk)
+			if !jsonPrefixValid(text.String()) {
+				aborted = true
+				cancel()
+				return errInvalidJSONStream
+			}
+			if text.Len() < jsonGuardWindow {
+				return nil
+			}
+			checking = false
+			return flush()
+		},
+		OnComplete: func(meta StreamMetadata) error {
+			if aborted {
+				return nil
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			if callbacks.OnComplete != nil {
+				return callbacks.OnComplete(meta)
+			}
+			return nil
+		},
+	}
+	err := g.wrapped.Stream(streamCtx, req, wrapped)
+	if aborted {
+		return text.String(), errInvalidJSONStream
+	}
+	return text.String(), err
+}
+
+// jsonPrefixValid reports whether text could still grow into a single valid JSON value:
+// it holds no syntax error and nothing follows a completed value.
+func jsonPrefixValid(text string) bool {
+	dec := json.NewDecoder(strings.NewReader(text))
+	depth := 0
+	complete := false
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return errors.Is(err, io.ErrUnexpectedEOF)
+		}
+		if complete {
+			return false
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		complete = depth == 0
+	}
+}
+
+// LoadedModels passes the call through to the wrapped provider.
+func (g *JSONGuard) LoadedModels(ctx context.Context, host appconfig.Host) ([]string, error) {
+	return g.wrapped.LoadedModels(ctx, host)
+}
+
+// EnsureModelReady passes the call through to the wrapped provider.
+func (g *JSONGuard) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
+	return g.wrapped.EnsureModelReady(ctx, host, model)
+}
+
+// HostLoad passes the call through to the wrapped provider, if it can report load.
+func (g *JSONGuard) HostLoad(ctx context.Context, host appconfig.Host) (HostLoad, error) {
+	load, _, err := ReportHostLoad(ctx, g.wrapped, host)
+	return load, err
+}
+
+// ModelCapabilities passes the call through to the wrapped provider, if it can report them.
+func (g *JSONGuard) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (Capabilities, error) {
+	return ReportModelCapabilities(ctx, g.wrapped, host, model)
+}
+
+// Close passes the call through to the wrapped provider.
+func (g *JSONGuard) Close() error {
+	return g.wrapped.Close()
+}
@@ -0,0 +1,109 @@
+// internal/providers/jsonguard_test.go
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// scriptedStream answers each Stream call with the next scripted list of chunks and
+// records the system prompt it was sent.
+type scriptedStream struct {
+	replies       [][]string
+	systemPrompts []string
+}
+
+func (s *scriptedStream) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	s.systemPrompts = append(s.systemPrompts, req.SystemPrompt)
+	reply := s.replies[len(s.systemPrompts)-1]
+	for _, chunk := range reply {
+		if err := callbacks.OnChunk(ChatMessage{Role: "assistant", Content: chunk}); err != nil {
+			return err
+		}
+	}
+	return callbacks.OnComplete(StreamMetadata{Done: true})
+}
+
+func (s *scriptedStream) LoadedModels(context.Context, appconfig.Host) ([]string, error) {
+	return nil, nil
+}
+
+func (s *scriptedStream) EnsureModelReady(context.Context, appconfig.Host, string) error {
+	return nil
+}
+
+func (s *scriptedStream) Close() error { return nil }
+
+// collect streams req through provider and returns the text the caller received.
+func collect(t *testing.T, provider ChatProvider, req StreamRequest) string {
+	t.Helper()
+	var out strings.Builder
+	err := provider.Stream(context.Background(), req, StreamCallbacks{
+		OnChunk:    func(chunk ChatMessage) error { out.WriteString(chunk.Content); return nil },
+		OnComplete: func(StreamMetadata) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	return out.String()
+}
+
+// TestJSONGuardRetriesProse verifies a prose preamble is aborted without reaching the
+// caller and asked again with the nudge, and that the last attempt is passed through.
+func TestJSONGuardRetriesProse(t *testing.T) {
+	inner := &scriptedStream{replies: [][]string{
+		{"Sure! Here", " is the JSON: {"},
+		{`{"answer"`, `: 42}`},
+	}}
+	guard := NewJSONGuard(inner, appconfig.JSONStreamGuardConfig{})
+	got := collect(t, guard, StreamRequest{JSONMode: true, SystemPrompt: "Be terse."})
+	if got != `{"answer": 42}` {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if len(inner.systemPrompts) != 2 || !strings.HasSuffix(inner.systemPrompts[1], appconfig.DefaultJSONNudge) || !strings.HasPrefix(inner.systemPrompts[1], "Be terse.") {
+		t.Fatalf("unexpected system prompts %q", inner.systemPrompts)
+	}
+
+	stubborn := &scriptedStream{replies: [][]string{{"No."}, {"Still no."}}}
+	if got := collect(t, NewJSONGuard(stubborn, appconfig.JSONStreamGuardConfig{}), StreamRequest{JSONMode: true}); got != "Still no." {
+		t.Fatalf("expected the last attempt to pass through, got %q", got)
+	}
+}
+
+// TestJSONGuardPassesValidAndPlain verifies valid JSON streams once and plain requests
+// are not checked.
+func TestJSONGuardPassesValidAndPlain(t *testing.T) {
+	inner := &scriptedStream{replies: [][]string{{"[1, ", "2]"}, {"plain text"}}}
+	guard := NewJSONGuard(inner, appconfig.JSONStreamGuardConfig{Retries: 3})
+	if got := collect(t, guard, StreamRequest{JSONMode: true}); got != "[1, 2]" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if got := collect(t, guard, StreamRequest{}); got != "plain text" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if len(inner.systemPrompts) != 2 {
+		t.Fatalf("expected one call per request, got %d", len(inner.systemPrompts))
+	}
+}
+
+// TestJSONPrefixValid verifies prefixes that can still become JSON are accepted and
+// preambles, syntax errors and trailing text are not.
+func TestJSONPrefixValid(t *testing.T) {
+	for text, want := range map[string]bool{
+		"":                   true,
+		`  {"a": "hel`:       true,
+		`{"a": tr`:           true,
+		`[1, 2`:              true,
+		"Here is":            false,
+		"```json\n{":         false,
+		`{"a" 1`:             false,
+		`{"a": 1} Hope this`: false,
+	} {
+		if got := jsonPrefixValid(text); got != want {
+			t.Fatalf("jsonPrefixValid(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// internal/providers/load.go
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// HostLoad describes how busy a host reports itself to be.
+type HostLoad struct {
+	// Slots and IdleSlots count the processing slots of servers that expose them, such as
+	// llama.cpp's /slots; both are zero when the server reports none.
+	Slots     int
+	IdleSlots int
+	// Queued counts requests the server has deferred until a slot frees.
+	Queued int
+	// Loading lists models the host is still loading into memory.
+	Loading []string
+}
+
+// Busy reports whether a new request would wait on the server before it is processed.
+func (l HostLoad) Busy() bool {
+	return l.Queued > 0 || len(l.Loading) > 0 || (l.Slots > 0 && l.IdleSlots == 0)
+}
+
+// String renders the load for status lines, e.g. "host busy: 3 requests queued".
+func (l HostLoad) String() string {
+	if !l.Busy() {
+		if l.Slots > 0 {
+			return fmt.Sprintf("host idle: %d of %d slots free", l.IdleSlots, l.Slots)
+		}
+		return "host idle"
+	}
+	var parts []string
+	if l.Queued > 0 {
+		noun := "requests"
+		if l.Queued == 1 {
+			noun = "request"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s queued", l.Queued, noun))
+	}
+	if l.Slots > 0 && l.IdleSlots == 0 {
+		parts = append(parts, fmt.Sprintf("all %d slots in use", l.Slots))
+	}
+	if len(l.Loading) > 0 {
+		parts = append(parts, "loading "+strings.Join(l.Loading, ", "))
+	}
+	return "host busy: " + strings.Join(parts, ", ")
+}
+
+// LoadReporter is implemented by providers that can query a host's load.
+type LoadReporter interface {
+	// HostLoad returns the load the host currently reports.
+	HostLoad(ctx context.Context, host appconfig.Host) (HostLoad, error)
+}
+
+// ReportHostLoad queries the host's load through provider. ok is false when the provider
+// cannot report load.
+func ReportHostLoad(ctx context.Context, provider ChatProvider, host appconfig.Host) (load HostLoad, ok bool, err error) {
+	reporter, ok := provider.(LoadReporter)
+	if !ok {
+		return HostLoad{}, false, nil
+	}
+	load, err = reporter.HostLoad(ctx, host)
+	return load, true, err
+}
+
+// WaitForCapacity polls the host's load every interval until it is no longer busy, calling
+// onBusy with each busy reading. It gives up after maxWait so a stuck server cannot stall
+// the caller, and returns at once when the provider cannot report load or a query fails.
+func WaitForCapacity(ctx context.Context, provider ChatProvider, host appconfig.Host, interval, maxWait time.Duration, onBusy func(HostLoad)) error {
+	deadline := time.Now().Add(maxWait)
+	for {
+		load, ok, err := ReportHostLoad(ctx, provider, host)
+		if !ok || err != nil || !load.Busy() || time.Now().After(deadline) {
+			return nil
+		}
+		if onBusy != nil {
+			onBusy(load)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
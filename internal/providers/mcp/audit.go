@@ -0,0 +1,148 @@
+// internal/providers/mcp/audit.go
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/toolaudit"
+)
+
+// runCommandTool is agon's own command-running tool, whose calls may outlast the MCP timeout.
+const runCommandTool = "run_command"
+
+// commandTimeoutGrace is added to the longest command timeout so the server can report a
+// timed-out command before the call itself times out.
+const commandTimeoutGrace = 5 * time.Second
+
+// auditedCall runs a tool for the model in req within the tool's timeout and records the
+// call in the tool audit log. Tools that require approval first ask through
+// req.ApproveTool; a declined call is not made, and the model is told so instead.
+func (p *Provider) auditedCall(ctx context.Context, req providers.StreamRequest, name string, args map[string]any) (toolCallResponse, error) {
+	approval, err := p.approveCall(ctx, req, name, args)
+	if err != nil {
+		p.recordToolCall(req, name, args, 0, toolCallResponse{}, err, approval)
+		return toolCallResponse{}, err
+	}
+	if approval == toolaudit.ApprovalDenied {
+		result := toolCallResponse{Output: fmt.Sprintf("The user did not approve this %s call, so it was not run. Do not retry it unless the user asks.", name)}
+		p.recordToolCall(req, name, args, 0, result, nil, approval)
+		return result, nil
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, p.toolTimeout(name))
+	defer cancel()
+	start := time.Now()
+	result, err := p.callTool(toolCtx, hostLabel(req.Host), req.Model, name, args)
+	p.recordToolCall(req, name, args, time.Since(start), result, err, approval)
+	return result, err
+}
+
+// approveCall asks the user to approve a call of a tool that requires it, returning
+// toolaudit.ApprovalApproved or ApprovalDenied, or "" for tools that need no approval.
+// Without an approver to ask, as in benchmarks, such calls are declined.
+func (p *Provider) approveCall(ctx context.Context, req providers.StreamRequest, name string, args map[string]any) (string, error) {
+	def, ok := p.toolIndex[toolKey(name)]
+	if !ok || !def.RequiresApproval {
+		return "", nil
+	}
+	if req.ApproveTool == nil {
+		p.log("Tool declined: tool=%s host=%s model=%s reason=no approval prompt", name, hostLabel(req.Host), req.Model)
+		return toolaudit.ApprovalDenied, nil
+	}
+	call := providers.ToolApproval{Host: hostLabel(req.Host), Model: req.Model, Tool: name, Arguments: modelArguments(args)}
+	approved, err := req.ApproveTool(ctx, call)
+	if err != nil {
+		return toolaudit.ApprovalDenied, err
+	}
+	if !approved {
+		p.log("Tool declined: tool=%s host=%s model=%s reason=user declined", name, call.Host, req.Model)
+		return toolaudit.ApprovalDenied, nil
+	}
+	return toolaudit.ApprovalApproved, nil
+}
+
+// toolTimeout bounds one call of the named tool: the MCP timeout, or for run_command its
+// longest command timeout when that is longer.
+func (p *Provider) toolTimeout(name string) time.Duration {
+	timeout := p.cfg.MCPInitTimeoutDuration()
+	if name == runCommandTool && p.cfg.CommandTool != nil {
+		timeout = max(timeout, p.cfg.CommandTool.LongestTimeout()+commandTimeoutGrace)
+	}
+	return timeout
+}
+
+// modelArguments returns args without the ones agon adds for its own server.
+func modelArguments(args map[string]any) map[string]any {
+	filtered := make(map[string]any, len(args))
+	for key, value := range args {
+		if !strings.HasPrefix(key, "__") {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// recordToolCall appends one tool call to the audit log with the user's approval, if it
+// needed one. The arguments agon adds for its own server are left out, apart from the
+// attempt number.
+func (p *Provider) recordToolCall(req providers.StreamRequest, name string, args map[string]any, elapsed time.Duration, result toolCallResponse, callErr error, approval string) {
+	if p.audit == nil {
+		return
+	}
+	entry := toolaudit.Entry{
+		Mode:        p.chatMode(),
+		Host:        hostLabel(req.Host),
+		Model:       req.Model,
+		Turn:        userTurns(req.History),
+		Tool:        name,
+		Arguments:   make(map[string]any, len(args)),
+		DurationMs:  float64(elapsed.Microseconds()) / 1000,
+		ResultBytes: len(result.Output),
+		Approval:    approval,
+	}
+	if tool, ok := p.externalTools[toolKey(name)]; ok {
+		entry.Server = tool.server.name
+	}
+	for key, value := range args {
+		if key == "__mcp_attempt" {
+			entry.Attempt, _ = value.(int)
+			continue
+		}
+		if !strings.HasPrefix(key, "__") {
+			entry.Arguments[key] = value
+		}
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+	if err := p.audit.Record(entry); err != nil {
+		mcpLog.Errorf("Tool audit failed: tool=%s reason=%v", name, err)
+	}
+}
+
+// chatMode names the mode the provider serves, as recorded in the audit log.
+func (p *Provider) chatMode() string {
+	switch {
+	case p.cfg.PipelineMode:
+		return "pipeline"
+	case p.cfg.MultimodelMode:
+		return "multimodel"
+	default:
+		return "chat"
+	}
+}
+
+// userTurns counts the user messages in a conversation.
+func userTurns(history []providers.ChatMessage) int {
+	turns := 0
+	for _, msg := range history {
+		if strings.EqualFold(msg.Role, "user") {
+			turns++
+		}
+	}
+	return turns
+}
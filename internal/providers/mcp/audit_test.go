@@ -0,0 +1,127 @@
+// internal/providers/mcp/audit_test.go
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/toolaudit"
+)
+
+// TestRecordToolCall verifies audit entries carry the mode, turn and attempt while leaving
+// out the arguments agon adds for its own server.
+func TestRecordToolCall(t *testing.T) {
+	store := toolaudit.NewStore(t.TempDir())
+	provider := &Provider{cfg: &appconfig.Config{PipelineMode: true}, audit: store}
+	req := providers.StreamRequest{
+		Host:  appconfig.Host{Name: "gpu"},
+		Model: "llama",
+		History: []providers.ChatMessage{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+			{Role: "user", Content: "weather in Paris?"},
+		},
+	}
+	args := map[string]any{"location": "Paris", "__user_prompt": "weather in Paris?", "__mcp_attempt": 2}
+	provider.recordToolCall(req, "current_weather", args, 1500*time.Microsecond, toolCallResponse{Output: "sunny"}, errors.New("upstream slow"), "")
+
+	entries, err := store.Read(toolaudit.Filter{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one entry, got %v, %v", entries, err)
+	}
+	entry := entries[0]
+	if entry.Mode != "pipeline" || entry.Host != "gpu" || entry.Model != "llama" || entry.Turn != 2 || entry.Attempt != 2 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if len(entry.Arguments) != 1 || entry.Arguments["location"] != "Paris" {
+		t.Fatalf("unexpected arguments: %+v", entry.Arguments)
+	}
+	if entry.DurationMs != 1.5 || entry.ResultBytes != 5 || entry.Error != "upstream slow" {
+		t.Fatalf("unexpected outcome: %+v", entry)
+	}
+}
+
+// TestAuditedCallApproval verifies tools that require approval are not called when the
+// user declines or nothing can ask, and that the decline is audited and told to the model.
+func TestAuditedCallApproval(t *testing.T) {
+	store := toolaudit.NewStore(t.TempDir())
+	provider := &Provider{
+		cfg:       &appconfig.Config{},
+		audit:     store,
+		toolIndex: map[string]providers.ToolDefinition{"run_command": {Name: "run_command", RequiresApproval: true}},
+	}
+	var asked providers.ToolApproval
+	req := providers.StreamRequest{
+		Host:  appconfig.Host{Name: "gpu"},
+		Model: "llama",
+		ApproveTool: func(_ context.Context, call providers.ToolApproval) (bool, error) {
+			asked = call
+			return false, nil
+		},
+	}
+	args := map[string]any{"command": "go", "args": []any{"test", "./..."}, "__user_prompt": "run the tests"}
+	for _, approver := range []providers.ToolApprover{req.ApproveTool, nil} {
+		req.ApproveTool = approver
+		result, err := provider.auditedCall(context.Background(), req, "run_command", args)
+		if err != nil || !strings.Contains(result.Output, "did not approve") {
+			t.Fatalf("expected a declined call, got %+v, %v", result, err)
+		}
+	}
+	if asked.Tool != "run_command" || asked.Host != "gpu" || asked.Arguments["command"] != "go" || asked.Arguments["__user_prompt"] != nil {
+		t.Fatalf("unexpected approval request %+v", asked)
+	}
+
+	entries, err := store.Read(toolaudit.Filter{})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected two audited calls, got %v, %v", entries, err)
+	}
+	for _, entry := range entries {
+		if entry.Approval != toolaudit.ApprovalDenied || entry.Arguments["command"] != "go" {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+	}
+}
+
+// toolCallingProvider stands in for the model: it calls one tool through the request's
+// executor and completes.
+type toolCallingProvider struct {
+	providers.ChatProvider
+	tool string
+}
+
+func (f toolCallingProvider) Stream(ctx context.Context, req providers.StreamRequest, callbacks providers.StreamCallbacks) error {
+	if _, err := req.ToolExecutor(ctx, f.tool, map[string]any{"command": "ls"}); err != nil {
+		return err
+	}
+	return callbacks.OnComplete(providers.StreamMetadata{EvalCount: 3})
+}
+
+// TestStreamReportsToolCalls verifies the tools called while answering reach the stream
+// metadata, so benchmarks can record them.
+func TestStreamReportsToolCalls(t *testing.T) {
+	provider := &Provider{
+		cfg:       &appconfig.Config{},
+		fallback:  toolCallingProvider{tool: "run_command"},
+		toolIndex: map[string]providers.ToolDefinition{"run_command": {Name: "run_command", RequiresApproval: true}},
+	}
+	var meta providers.StreamMetadata
+	err := provider.Stream(context.Background(), providers.StreamRequest{
+		Host:    appconfig.Host{Name: "gpu"},
+		Model:   "llama",
+		History: []providers.ChatMessage{{Role: "user", Content: "list the files"}},
+	}, providers.StreamCallbacks{OnComplete: func(m providers.StreamMetadata) error {
+		meta = m
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	if len(meta.ToolCalls) != 1 || meta.ToolCalls[0] != "run_command" || meta.EvalCount != 3 {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+}
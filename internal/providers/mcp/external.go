@@ -0,0 +1,506 @@
+// internal/providers/mcp/external.go
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+const (
+	// externalToolSeparator joins a server name and a tool name into the namespaced name
+	// models see, e.g. "github__create_issue".
+	externalToolSeparator = "__"
+	// externalProtocolVersion is the MCP revision agon asks external servers for.
+	externalProtocolVersion = "2025-03-26"
+	// externalMaxMessageBytes caps a single message read from an external stdio server.
+	externalMaxMessageBytes = 16 << 20
+)
+
+// rpcMessage is any JSON-RPC 2.0 message exchanged with an external server: a request,
+// a notification or a response.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// externalTransport carries JSON-RPC messages to one external server.
+type externalTransport interface {
+	// request sends msg and returns the response with the same id.
+	request(ctx context.Context, msg rpcMessage) (rpcMessage, error)
+	// notify sends a notification, which has no response.
+	notify(ctx context.Context, msg rpcMessage) error
+	close() error
+}
+
+// externalServer is a connected external MCP server.
+type externalServer struct {
+	name      string
+	transport externalTransport
+	seqMu     sync.Mutex
+	seq       int64
+}
+
+// externalTool is a tool of an external server, keyed by its namespaced name.
+type externalTool struct {
+	server *externalServer
+	name   string
+}
+
+// connectExternal starts or reaches the configured server and performs the initialize
+// handshake.
+func connectExternal(ctx context.Context, server appconfig.MCPServer) (*externalServer, error) {
+	var (
+		transport externalTransport
+		err       error
+	)
+	if strings.TrimSpace(server.Command) != "" {
+		transport, err = startStdioTransport(server)
+	} else {
+		transport = newHTTPTransport(server)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &externalServer{name: server.Name, transport: transport}
+	params := map[string]any{
+		"protocolVersion": externalProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "agon-cli", "version": "dev"},
+	}
+	if _, err := s.call(ctx, "initialize", params); err != nil {
+		_ = transport.close()
+		return nil, fmt.Errorf("mcp server %s: initialize: %w", server.Name, err)
+	}
+	if err := transport.notify(ctx, rpcMessage{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		_ = transport.close()
+		return nil, fmt.Errorf("mcp server %s: initialized notification: %w", server.Name, err)
+	}
+	return s, nil
+}
+
+// call sends a request to the server and returns its result.
+func (s *externalServer) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	s.seqMu.Lock()
+	s.seq++
+	id := s.seq
+	s.seqMu.Unlock()
+
+	msg := rpcMessage{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(id)), Method: method, Params: params}
+	mcpLog.Request("AGON->MCP", s.name, "", method, msg)
+	resp, err := s.transport.request(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	mcpLog.Request("MCP->AGON", s.name, "", method, resp)
+	if resp.Error != nil {
+		return nil, fmt.Errorf("%s", resp.Error.Message)
+	}
+	return resp.Result, nil
+}
+
+// listTools returns the server's tools, following pagination cursors.
+func (s *externalServer) listTools(ctx context.Context) ([]providers.ToolDefinition, error) {
+	var defs []providers.ToolDefinition
+	cursor := ""
+	for {
+		var params map[string]any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		result, err := s.call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Tools []struct {
+				Name        string                  `json:"name"`
+				Description string                  `json:"description,omitempty"`
+				InputSchema map[string]any          `json:"inputSchema,omitempty"`
+				Parameters  map[string]any          `json:"parameters,omitempty"`
+				Examples    []providers.ToolExample `json:"examples,omitempty"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor,omitempty"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
+			return nil, fmt.Errorf("decode tools/list result: %w", err)
+		}
+		for _, tool := range page.Tools {
+			schema := tool.InputSchema
+			if schema == nil {
+				schema = tool.Parameters
+			}
+			defs = append(defs, providers.ToolDefinition{Name: tool.Name, Description: tool.Description, Parameters: schema, Examples: tool.Examples})
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return defs, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// call invokes the tool with args, dropping the arguments agon adds for its own server.
+func (t externalTool) call(ctx context.Context, args map[string]any) (json.RawMessage, error) {
+	arguments := make(map[string]any, len(args))
+	for key, value := range args {
+		if !strings.HasPrefix(key, "__") {
+			arguments[key] = value
+		}
+	}
+	result, err := t.server.call(ctx, "tools/call", map[string]any{"name": t.name, "arguments": arguments})
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", t.server.name, err)
+	}
+	return result, nil
+}
+
+// connectExternalServers connects every configured external server and adds its tools,
+// namespaced by server name, to the provider's tools. A server that cannot be reached is
+// logged and skipped so the others stay usable.
+func (p *Provider) connectExternalServers(ctx context.Context) {
+	for _, server := range p.cfg.MCPServers {
+		connectCtx, cancel := context.WithTimeout(ctx, p.cfg.MCPInitTimeoutDuration())
+		s, err := connectExternal(connectCtx, server)
+		if err != nil {
+			cancel()
+			mcpLog.Errorf("MCP server %s unavailable: %v", server.Name, err)
+			continue
+		}
+		defs, err := s.listTools(connectCtx)
+		cancel()
+		if err != nil {
+			mcpLog.Errorf("MCP server %s: failed to list tools: %v", server.Name, err)
+			_ = s.transport.close()
+			continue
+		}
+
+		p.external = append(p.external, s)
+		if p.externalTools == nil {
+			p.externalTools = make(map[string]externalTool)
+		}
+		var names []string
+		for _, def := range defs {
+			name := server.Name + externalToolSeparator + def.Name
+			p.externalTools[toolKey(name)] = externalTool{server: s, name: def.Name}
+			def.Name = name
+			p.toolIndex[toolKey(name)] = def
+			p.toolDefs = append(p.toolDefs, def)
+			names = append(names, name)
+		}
+		p.log("MCP server %s connected: tools=%s", server.Name, strings.Join(names, ", "))
+	}
+}
+
+// stdioTransport talks to a server it started, exchanging newline-delimited JSON-RPC
+// messages over the process's stdin and stdout.
+type stdioTransport struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan rpcMessage
+	err     error
+}
+
+// startStdioTransport starts the server's command and begins reading its output.
+func startStdioTransport(server appconfig.MCPServer) (*stdioTransport, error) {
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: stdin pipe: %w", server.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: stdout pipe: %w", server.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp server %s: start %s: %w", server.Name, server.Command, err)
+	}
+	t := &stdioTransport{name: server.Name, cmd: cmd, stdin: stdin, pending: make(map[string]chan rpcMessage)}
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+// readLoop delivers responses to their pending requests, answers the server's own
+// requests, and fails every pending request once the server's output ends.
+func (t *stdioTransport) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), externalMaxMessageBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			mcpLog.Warnf("MCP server %s: ignoring malformed message: %v", t.name, err)
+			continue
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			t.answerServerRequest(msg)
+		case msg.Method != "":
+			mcpLog.Eventf("MCP server %s notification: %s", t.name, msg.Method)
+		default:
+			t.mu.Lock()
+			ch, ok := t.pending[normalizeID(msg.ID)]
+			delete(t.pending, normalizeID(msg.ID))
+			t.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	t.mu.Lock()
+	t.err = fmt.Errorf("mcp server %s closed its output: %w", t.name, err)
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+	t.mu.Unlock()
+}
+
+// answerServerRequest replies to a request the server sent: ping succeeds and anything
+// else is reported as unsupported.
+func (t *stdioTransport) answerServerRequest(msg rpcMessage) {
+	reply := rpcMessage{JSONRPC: "2.0", ID: msg.ID}
+	if msg.Method == "ping" {
+		reply.Result = json.RawMessage("{}")
+	} else {
+		reply.Error = &jsonrpcError{Code: -32601, Message: "method not supported by agon: " + msg.Method}
+	}
+	if err := t.write(reply); err != nil {
+		mcpLog.Warnf("MCP server %s: reply to %s failed: %v", t.name, msg.Method, err)
+	}
+}
+
+// write sends one message as a line of JSON.
+func (t *stdioTransport) write(msg rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (t *stdioTransport) request(ctx context.Context, msg rpcMessage) (rpcMessage, error) {
+	id := normalizeID(msg.ID)
+	ch := make(chan rpcMessage, 1)
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return rpcMessage{}, t.err
+	}
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	if err := t.write(msg); err != nil {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+		return rpcMessage{}, err
+	}
+
+	select {
+	case <-ctx.Done():
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+		return rpcMessage{}, ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return rpcMessage{}, t.err
+		}
+		return resp, nil
+	}
+}
+
+func (t *stdioTransport) notify(ctx context.Context, msg rpcMessage) error {
+	return t.write(msg)
+}
+
+// close ends the server's input and waits briefly for it to exit before killing it.
+func (t *stdioTransport) close() error {
+	_ = t.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- t.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		_ = t.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// httpTransport talks to a server over MCP's Streamable HTTP transport: each message is
+// POSTed, and the response arrives as JSON or as a server-sent event stream.
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu      sync.Mutex
+	session string
+}
+
+// newHTTPTransport returns a transport for the server's URL.
+func newHTTPTransport(server appconfig.MCPServer) *httpTransport {
+	return &httpTransport{url: server.URL, headers: server.Headers, client: &http.Client{}}
+}
+
+// post sends msg and returns the HTTP response, remembering the session the server assigns.
+func (t *httpTransport) post(ctx context.Context, msg rpcMessage) (*http.Response, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	t.mu.Lock()
+	if t.session != "" {
+		req.Header.Set("Mcp-Session-Id", t.session)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if session := resp.Header.Get("Mcp-Session-Id"); session != "" {
+		t.mu.Lock()
+		t.session = session
+		t.mu.Unlock()
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s: %s", t.url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) request(ctx context.Context, msg rpcMessage) (rpcMessage, error) {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return rpcMessage{}, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		var reply rpcMessage
+		if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+			return rpcMessage{}, fmt.Errorf("decode response from %s: %w", t.url, err)
+		}
+		return reply, nil
+	}
+	return readEventStream(resp.Body, normalizeID(msg.ID))
+}
+
+// readEventStream returns the response with id from a server-sent event stream, skipping
+// the notifications and requests the server interleaves.
+func readEventStream(body io.Reader, id string) (rpcMessage, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), externalMaxMessageBytes)
+	var data strings.Builder
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+		if more && line != "" {
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if data.Len() > 0 {
+			var msg rpcMessage
+			if err := json.Unmarshal([]byte(data.String()), &msg); err == nil && msg.Method == "" && normalizeID(msg.ID) == id {
+				return msg, nil
+			}
+			data.Reset()
+		}
+		if !more {
+			if err := scanner.Err(); err != nil {
+				return rpcMessage{}, err
+			}
+			return rpcMessage{}, fmt.Errorf("event stream ended without a response to request %s", id)
+		}
+	}
+}
+
+func (t *httpTransport) notify(ctx context.Context, msg rpcMessage) error {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// close ends the server's session, if it assigned one.
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	session := t.session
+	t.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Mcp-Session-Id", session)
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,162 @@
+// internal/providers/mcp/external_test.go
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// echoServer answers MCP requests with one "echo" tool that returns its arguments as text.
+func echoServer(method string, params json.RawMessage) (any, *jsonrpcError) {
+	switch method {
+	case "initialize":
+		return map[string]any{"protocolVersion": externalProtocolVersion, "capabilities": map[string]any{"tools": map[string]any{}}}, nil
+	case "tools/list":
+		return map[string]any{"tools": []map[string]any{{
+			"name":        "echo",
+			"description": "Echo the arguments",
+			"inputSchema": map[string]any{"type": "object", "properties": map[string]any{"text": map[string]any{"type": "string"}}},
+		}}}, nil
+	case "tools/call":
+		var call struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(params, &call)
+		args, _ := json.Marshal(call.Arguments)
+		return map[string]any{"content": []map[string]any{{"type": "text", "text": call.Name + " " + string(args)}}}, nil
+	default:
+		return nil, &jsonrpcError{Code: -32601, Message: "unknown method " + method}
+	}
+}
+
+// TestExternalHTTPServer verifies the Streamable HTTP handshake, namespaced tool discovery,
+// SSE responses, session headers, and that agon's own arguments are not forwarded.
+func TestExternalHTTPServer(t *testing.T) {
+	var sessions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sessions = append(sessions, r.Header.Get("Mcp-Session-Id"))
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if len(msg.ID) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		result, rpcErr := echoServer(msg.Method, msg.Params)
+		reply, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result, "error": rpcErr})
+		if msg.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		}
+		if msg.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", reply)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(reply)
+	}))
+	defer server.Close()
+
+	cfg := &appconfig.Config{MCPServers: []appconfig.MCPServer{{Name: "remote", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}}}
+	provider := &Provider{cfg: cfg, toolIndex: make(map[string]providers.ToolDefinition)}
+	provider.connectExternalServers(context.Background())
+	defer provider.Close()
+
+	tools := provider.Tools()
+	if len(tools) != 1 || tools[0].Name != "remote__echo" || tools[0].Parameters["type"] != "object" {
+		t.Fatalf("unexpected tools: %+v", tools)
+	}
+
+	result, err := provider.callTool(context.Background(), "host", "model", "remote__echo", map[string]any{"text": "hi", "__user_prompt": "say hi"})
+	if err != nil {
+		t.Fatalf("callTool returned error: %v", err)
+	}
+	if result.Output != `echo {"text":"hi"}` {
+		t.Fatalf("unexpected tool output %q", result.Output)
+	}
+
+	// Models do not always keep a tool's casing, so the lookup must ignore it.
+	result, err = provider.callTool(context.Background(), "host", "model", "Remote__Echo", map[string]any{"text": "case"})
+	if err != nil || result.Output != `echo {"text":"case"}` {
+		t.Fatalf("expected the tool matched case-insensitively, got %+v, %v", result, err)
+	}
+	if sessions[0] != "" || sessions[len(sessions)-1] != "session-1" {
+		t.Fatalf("expected the session id to be sent after initialize, got %q", sessions)
+	}
+}
+
+// TestExternalStdioServer verifies a stdio server started from a command, using this test
+// binary as the server.
+func TestExternalStdioServer(t *testing.T) {
+	cfg := &appconfig.Config{MCPServers: []appconfig.MCPServer{{
+		Name:    "local",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperStdioServer"},
+		Env:     map[string]string{"AGON_MCP_HELPER": "1"},
+	}}}
+	provider := &Provider{cfg: cfg, toolIndex: make(map[string]providers.ToolDefinition)}
+	provider.connectExternalServers(context.Background())
+	defer provider.Close()
+
+	raw, err := provider.CallTool(context.Background(), "local__echo", map[string]any{"text": "stdio"})
+	if err != nil {
+		t.Fatalf("CallTool returned error: %v", err)
+	}
+	if !strings.Contains(string(raw), `echo {\"text\":\"stdio\"}`) {
+		t.Fatalf("unexpected tool result %s", raw)
+	}
+	if _, ok := provider.toolIndex["local__echo"]; !ok {
+		t.Fatalf("expected the namespaced tool in the tool index")
+	}
+}
+
+// TestHelperStdioServer is the stdio MCP server TestExternalStdioServer starts; it does
+// nothing when run as a normal test.
+func TestHelperStdioServer(t *testing.T) {
+	if os.Getenv("AGON_MCP_HELPER") != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil || len(msg.ID) == 0 {
+			continue
+		}
+		// A notification before the response must be skipped by the client.
+		fmt.Println(`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info"}}`)
+		result, rpcErr := echoServer(msg.Method, msg.Params)
+		reply, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result, "error": rpcErr})
+		fmt.Println(string(reply))
+	}
+	os.Exit(0)
+}
@@ -11,9 +11,10 @@ import (
 	"time"
 
 	"github.com/mwiater/agon/internal/appconfig"
-	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/providers/anthropic"
 	"github.com/mwiater/agon/internal/providers/ollama"
+	"github.com/mwiater/agon/internal/toolaudit"
 )
 
 // New spins up the MCP server process and performs the initialize handshake.
@@ -26,14 +27,18 @@ func New(ctx context.Context, cfg *appconfig.Config) (*Provider, error) {
 
 	if _, err := os.Stat(binary); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			logging.LogEvent("MCP server start aborted: binary %q missing", binary)
+			mcpLog.Errorf("MCP server start aborted: binary %q missing", binary)
 			return nil, fmt.Errorf("mcp binary not found at %q", binary)
 		}
-		logging.LogEvent("MCP server start aborted: binary %q not accessible (%v)", binary, err)
+		mcpLog.Errorf("MCP server start aborted: binary %q not accessible (%v)", binary, err)
 		return nil, fmt.Errorf("mcp binary %q not accessible: %w", binary, err)
 	}
 
-	cmd := exec.CommandContext(ctx, binary, "--config", cfg.ConfigPath)
+	args := []string{"--config", cfg.ConfigPath}
+	if cfg.MCPMock {
+		args = append(args, "--mock", "--fixtures", cfg.MCPFixturesDir())
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
 	cmd.Env = os.Environ()
 	cmd.Stderr = os.Stderr
 
@@ -47,20 +52,27 @@ func New(ctx context.Context, cfg *appconfig.Config) (*Provider, error) {
 	}
 
 	if err := cmd.Start(); err != nil {
-		logging.LogEvent("MCP server failed to start: %v", err)
+		mcpLog.Errorf("MCP server failed to start: %v", err)
 		return nil, fmt.Errorf("start mcp server: %w", err)
 	}
 
+	// The fallback serves chat requests, routing each to the backend its host type names.
+	fallback := providers.NewRouter(ollama.New(cfg), map[string]providers.ChatProvider{
+		anthropic.HostType: anthropic.New(cfg),
+	})
 	provider := &Provider{
 		cfg:       cfg,
 		cmd:       cmd,
 		stdin:     stdin,
 		reader:    bufio.NewReader(stdout),
 		writer:    bufio.NewWriter(stdin),
-		fallback:  ollama.New(cfg),
+		fallback:  fallback,
 		rpcMeta:   make(map[string]rpcMetadata),
 		toolIndex: make(map[string]providers.ToolDefinition),
 	}
+	if dir := cfg.ToolAuditPath(); dir != "" {
+		provider.audit = toolaudit.NewStore(dir)
+	}
 
 	initCtx, cancel := context.WithTimeout(ctx, cfg.MCPInitTimeoutDuration())
 	defer cancel()
@@ -80,6 +92,7 @@ func New(ctx context.Context, cfg *appconfig.Config) (*Provider, error) {
 	if err := provider.discoverTools(); err != nil {
 		provider.log("Failed to list MCP tools: %v", err)
 	}
+	provider.connectExternalServers(ctx)
 
 	return provider, nil
 }
@@ -125,6 +138,12 @@ func (p *Provider) Close() error {
 		}
 	}
 
+	for _, server := range p.external {
+		if err := server.transport.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
 	if p.fallback != nil {
 		if err := p.fallback.Close(); err != nil && firstErr == nil {
 			firstErr = err
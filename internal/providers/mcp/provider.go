@@ -15,6 +15,7 @@ import (
 	"github.com/mwiater/agon/internal/appconfig"
 	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providers"
+	"github.com/mwiater/agon/internal/toolaudit"
 )
 
 // Provider implements the providers.ChatProvider interface by orchestrating
@@ -33,11 +34,20 @@ type Provider struct {
 	rpcMeta   map[string]rpcMetadata
 	toolIndex map[string]providers.ToolDefinition
 	toolDefs  []providers.ToolDefinition
+	// external are the connected external servers; externalTools maps each of their
+	// namespaced tool names to the server and name it is called with.
+	external      []*externalServer
+	externalTools map[string]externalTool
+	// audit records tool calls made for models; nil when the audit log is disabled.
+	audit *toolaudit.Store
 }
 
-// log logs an event using the global logger.
+// mcpLog logs tool calls and MCP server traffic under the mcp subsystem.
+var mcpLog = logging.For(logging.SubsystemMCP)
+
+// log logs an event under the mcp subsystem.
 func (p *Provider) log(format string, args ...any) {
-	logging.LogEvent(format, args...)
+	mcpLog.Eventf(format, args...)
 }
 
 // truncateForLog truncates a string to a maximum number of runes for logging.
@@ -89,13 +99,13 @@ func lastUserPrompt(history []providers.ChatMessage) string {
 // logToolRequest logs a tool request event.
 func (p *Provider) logToolRequest(name, host, model string, args map[string]any) {
 	payload := formatArgs(args)
-	logging.LogEvent("Tool requested: tool=%s host=%s model=%s args=%s", name, host, model, payload)
+	mcpLog.Eventf("Tool requested: tool=%s host=%s model=%s args=%s", name, host, model, payload)
 }
 
 // logToolSuccess logs a successful tool execution event.
 func (p *Provider) logToolSuccess(name, result, host, model string) {
 	truncated := truncateForLog(result, 160)
-	logging.LogEvent("Tool executed: tool=%s host=%s model=%s output=%s", name, host, model, truncated)
+	mcpLog.Eventf("Tool executed: tool=%s host=%s model=%s output=%s", name, host, model, truncated)
 }
 
 // defaultMCPHost returns the default MCP host identifier.
@@ -116,18 +126,29 @@ func (p *Provider) LoadedModels(ctx context.Context, host appconfig.Host) ([]str
 	p.log("Tool invoked: tool=loaded_models host=%s", host.Name)
 	models, err := p.fallback.LoadedModels(ctx, host)
 	if err != nil {
-		p.log("Tool bypassed: tool=loaded_models host=%s reason=%v", host.Name, err)
+		mcpLog.Warnf("Tool bypassed: tool=loaded_models host=%s reason=%v", host.Name, err)
 		return nil, err
 	}
 	p.log("Tool bypassed: tool=loaded_models host=%s reason=delegated to Ollama API", host.Name)
 	return models, nil
 }
 
+// HostLoad delegates to the underlying fallback provider to report the host's load.
+func (p *Provider) HostLoad(ctx context.Context, host appconfig.Host) (providers.HostLoad, error) {
+	load, _, err := providers.ReportHostLoad(ctx, p.fallback, host)
+	return load, err
+}
+
+// ModelCapabilities delegates to the underlying fallback provider to report the model's features.
+func (p *Provider) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (providers.Capabilities, error) {
+	return providers.ReportModelCapabilities(ctx, p.fallback, host, model)
+}
+
 // EnsureModelReady delegates to the underlying fallback provider to ensure a model is ready.
 func (p *Provider) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
 	p.log("Tool invoked: tool=ensure_model host=%s model=%s", host.Name, model)
 	if err := p.fallback.EnsureModelReady(ctx, host, model); err != nil {
-		p.log("Tool bypassed: tool=ensure_model host=%s model=%s reason=%v", host.Name, model, err)
+		mcpLog.Warnf("Tool bypassed: tool=ensure_model host=%s model=%s reason=%v", host.Name, model, err)
 		return err
 	}
 	p.log("Tool bypassed: tool=ensure_model host=%s model=%s reason=delegated to Ollama API", host.Name, model)
@@ -139,7 +160,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	userPrompt := lastUserPrompt(req.History)
 	systemPrompt := req.SystemPrompt
 	hostName := hostLabel(req.Host)
-	logging.LogEvent("[AGON->MCP] Incoming request metadata: user_prompt='%s', system_prompt='%s'", userPrompt, systemPrompt)
+	mcpLog.Eventf("[AGON->MCP] Incoming request metadata: user_prompt='%s', system_prompt='%s'", userPrompt, systemPrompt)
 	toolName, userText := p.selectTool(req.History)
 	executed := false
 	forwardReq := req
@@ -148,6 +169,11 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	}
 
 	newSystemPrompt := "You are a helpful assistant with access to the following tools. When the user asks a question, first determine if one of the tools can help."
+	if p.cfg.MCPToolExamples {
+		if examples := toolExamplesPrompt(p.toolDefs); examples != "" {
+			newSystemPrompt += "\n\n" + examples
+		}
+	}
 	foundSystemPrompt := false
 	for i, msg := range forwardReq.History {
 		if msg.Role == "system" {
@@ -163,7 +189,10 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	forwardReq.DisableStreaming = true
 	retryState := make(map[string]int)
 	retryLimit := p.cfg.MCPRetryAttempts()
+	// called lists the tools run for this request, in order, for the stream metadata.
+	var called []string
 	forwardReq.ToolExecutor = func(execCtx context.Context, name string, callArgs map[string]any) (string, error) {
+		called = append(called, name)
 		wireArgs := make(map[string]any, len(callArgs)+2)
 		for k, v := range callArgs {
 			wireArgs[k] = v
@@ -180,13 +209,11 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			}
 			retryState[name] = attempt
 			wireArgs["__mcp_attempt"] = attempt
-			toolCtx, cancel := context.WithTimeout(execCtx, p.cfg.MCPInitTimeoutDuration())
-			logging.LogEvent("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d", name, hostName, req.Model, attempt, retryLimit)
+			mcpLog.Eventf("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d", name, hostName, req.Model, attempt, retryLimit)
 			p.logToolRequest(name, hostName, req.Model, wireArgs)
-			result, err := p.callTool(toolCtx, hostName, req.Model, name, wireArgs)
-			cancel()
+			result, err := p.auditedCall(execCtx, req, name, wireArgs)
 			if err != nil {
-				p.log("[ERROR] Tool bypassed: tool=%s host=%s model=%s reason=%v", name, hostName, req.Model, err)
+				mcpLog.Errorf("Tool bypassed: tool=%s host=%s model=%s reason=%v", name, hostName, req.Model, err)
 				return "", err
 			}
 			if result.Retry && attempt < retryLimit {
@@ -224,6 +251,10 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 				}
 			}
 			retryState[name] = 0
+			if structured, ok := result.jsonModeOutput(req.JSONMode); ok {
+				p.logToolSuccess(name, structured, hostName, req.Model)
+				return structured, nil
+			}
 			if interp, ok := p.maybeInterpretResult(execCtx, req, name, result.Output); ok {
 				p.logToolSuccess(name, interp, hostName, req.Model)
 				return interp, nil
@@ -234,6 +265,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	}
 
 	if toolName != "" {
+		called = append(called, toolName)
 		args := map[string]any{
 			"text":          userText,
 			"__user_prompt": userText,
@@ -245,13 +277,11 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			}
 			retryState[toolName] = attempt
 			args["__mcp_attempt"] = attempt
-			logging.LogEvent("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d", toolName, hostName, req.Model, attempt, retryLimit)
-			toolCtx, cancel := context.WithTimeout(ctx, p.cfg.MCPInitTimeoutDuration())
+			mcpLog.Eventf("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d", toolName, hostName, req.Model, attempt, retryLimit)
 			p.logToolRequest(toolName, hostName, req.Model, args)
-			result, err := p.callTool(toolCtx, hostName, req.Model, toolName, args)
-			cancel()
+			result, err := p.auditedCall(ctx, req, toolName, args)
 			if err != nil {
-				p.log("[ERROR] Tool bypassed: tool=%s host=%s model=%s reason=%v", toolName, hostName, req.Model, err)
+				mcpLog.Errorf("Tool bypassed: tool=%s host=%s model=%s reason=%v", toolName, hostName, req.Model, err)
 				break
 			}
 			if result.Retry && attempt < retryLimit {
@@ -282,12 +312,15 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			}
 			retryState[toolName] = 0
 			executed = true
+			if structured, ok := result.jsonModeOutput(req.JSONMode); ok {
+				result.Output = structured
+			}
 			if interp, ok := p.maybeInterpretResult(ctx, req, toolName, result.Output); ok {
 				p.logToolSuccess(toolName, interp, hostName, req.Model)
 				output := fmt.Sprintf("[MCP %s] %s", toolName, strings.TrimSpace(interp))
 				if callbacks.OnChunk != nil {
 					if err := callbacks.OnChunk(providers.ChatMessage{Role: "assistant", Content: output}); err != nil {
-						p.log("[ERROR] Tool output dispatch failed: %v", err)
+						mcpLog.Errorf("Tool output dispatch failed: %v", err)
 					}
 				}
 				forwardHistory := append([]providers.ChatMessage{}, req.History...)
@@ -298,7 +331,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 				output := fmt.Sprintf("[MCP %s] %s", toolName, result.Output)
 				if callbacks.OnChunk != nil {
 					if err := callbacks.OnChunk(providers.ChatMessage{Role: "assistant", Content: output}); err != nil {
-						p.log("[ERROR] Tool output dispatch failed: %v", err)
+						mcpLog.Errorf("Tool output dispatch failed: %v", err)
 					}
 				}
 				forwardHistory := append([]providers.ChatMessage{}, req.History...)
@@ -310,10 +343,16 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	}
 
 	p.log("Forwarding request: host=%s model=%s messages=%d tools=%d", hostName, forwardReq.Model, len(forwardReq.History), len(forwardReq.Tools))
+	if onComplete := callbacks.OnComplete; onComplete != nil {
+		callbacks.OnComplete = func(meta providers.StreamMetadata) error {
+			meta.ToolCalls = append(append([]string(nil), called...), meta.ToolCalls...)
+			return onComplete(meta)
+		}
+	}
 	err := p.fallback.Stream(ctx, forwardReq, callbacks)
 	if err != nil {
 		if !executed {
-			p.log("[ERROR] Tool bypassed: tool=chat host=%s model=%s reason=%v", hostName, req.Model, err)
+			mcpLog.Errorf("Tool bypassed: tool=chat host=%s model=%s reason=%v", hostName, req.Model, err)
 		}
 		return err
 	}
@@ -0,0 +1,90 @@
+// internal/providers/mcp/resources.go
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// resolveResources replaces resource references in a tools/call result with the text
+// they point to, fetched chunk by chunk with resources/read. Results larger than the
+// configured limit are truncated so a single tool call cannot flood the model context.
+func (p *Provider) resolveResources(ctx context.Context, meta rpcMetadata, result json.RawMessage) (json.RawMessage, error) {
+	if len(result) == 0 || !strings.Contains(string(result), `"resource"`) {
+		return result, nil
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(result, &payload); err != nil {
+		return result, nil
+	}
+	var parts []map[string]any
+	if err := json.Unmarshal(payload["content"], &parts); err != nil {
+		return result, nil
+	}
+
+	changed := false
+	for i, part := range parts {
+		if part["type"] != "resource" {
+			continue
+		}
+		uri, _ := part["uri"].(string)
+		if uri == "" {
+			continue
+		}
+		text, err := p.readResource(ctx, meta, uri)
+		if err != nil {
+			return nil, err
+		}
+		originalType, _ := part["originalType"].(string)
+		if originalType == "" {
+			originalType = "text"
+		}
+		parts[i] = map[string]any{"type": originalType, "text": text}
+		changed = true
+	}
+	if !changed {
+		return result, nil
+	}
+
+	content, err := json.Marshal(parts)
+	if err != nil {
+		return nil, err
+	}
+	payload["content"] = content
+	return json.Marshal(payload)
+}
+
+// readResource fetches a held tool result up to the configured byte limit.
+func (p *Provider) readResource(ctx context.Context, meta rpcMetadata, uri string) (string, error) {
+	limit := p.cfg.MCPResultLimit()
+	var builder strings.Builder
+	offset := 0
+	for {
+		params := map[string]any{"uri": uri, "offset": offset}
+		resp, err := p.rpcCall(ctx, "resources/read", params, rpcMetadata{host: meta.host, model: meta.model, tool: meta.tool, method: "resources/read"})
+		if err != nil {
+			return "", fmt.Errorf("read resource %s: %w", uri, err)
+		}
+		var chunk struct {
+			Text string `json:"text"`
+			Size int    `json:"size"`
+			EOF  bool   `json:"eof"`
+		}
+		if err := json.Unmarshal(resp.Result, &chunk); err != nil {
+			return "", fmt.Errorf("parse resource %s: %w", uri, err)
+		}
+		builder.WriteString(chunk.Text)
+		offset += len(chunk.Text)
+		if chunk.EOF || chunk.Text == "" {
+			break
+		}
+		if builder.Len() >= limit {
+			mcpLog.Warnf("MCP tool result truncated: tool=%s uri=%s fetched=%d size=%d", toolLabel(meta), uri, builder.Len(), chunk.Size)
+			builder.WriteString(fmt.Sprintf("\n[truncated: %d of %d bytes]", offset, chunk.Size))
+			break
+		}
+	}
+	return builder.String(), nil
+}
@@ -8,8 +8,6 @@ import (
 	"io"
 	"strconv"
 	"strings"
-
-	"github.com/mwiater/agon/internal/logging"
 )
 
 // jsonrpcResponse represents a JSON-RPC 2.0 response.
@@ -26,6 +24,11 @@ type jsonrpcError struct {
 	Message string `json:"message"`
 }
 
+// Error implements the error interface, so callers can inspect the code of a failed call.
+func (e *jsonrpcError) Error() string {
+	return e.Message
+}
+
 // rpcMetadata stores metadata associated with an RPC call for logging and tracking.
 type rpcMetadata struct {
 	method string
@@ -186,7 +189,7 @@ func (p *Provider) rpcCall(ctx context.Context, method string, params map[string
 		p.popRPCMeta(metaKey)
 		return jsonrpcResponse{}, err
 	}
-	logging.LogRequest("AGON->MCP", meta.host, meta.model, toolLabel(meta), data)
+	mcpLog.Request("AGON->MCP", meta.host, meta.model, toolLabel(meta), data)
 
 	if err := p.writeRawFrame(data); err != nil {
 		p.popRPCMeta(metaKey)
@@ -214,10 +217,10 @@ func (p *Provider) rpcCall(ctx context.Context, method string, params map[string
 			payloadIn = data
 		}
 	}
-	logging.LogRequest("MCP->AGON", meta.host, meta.model, toolLabel(meta), payloadIn)
+	mcpLog.Request("MCP->AGON", meta.host, meta.model, toolLabel(meta), payloadIn)
 
 	if resp.Error != nil {
-		return jsonrpcResponse{}, fmt.Errorf("%s", resp.Error.Message)
+		return jsonrpcResponse{}, resp.Error
 	}
 	return resp, nil
 }
@@ -2,13 +2,15 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providers"
 )
 
@@ -38,9 +40,12 @@ func (p *Provider) discoverTools() error {
 	}
 	var payload struct {
 		Tools []struct {
-			Name        string         `json:"name"`
-			Description string         `json:"description,omitempty"`
-			Parameters  map[string]any `json:"parameters,omitempty"`
+			Name        string                  `json:"name"`
+			Description string                  `json:"description,omitempty"`
+			Parameters  map[string]any          `json:"parameters,omitempty"`
+			Examples    []providers.ToolExample `json:"examples,omitempty"`
+			// RequiresApproval is set by tools the user must confirm, such as run_command.
+			RequiresApproval bool `json:"requiresApproval,omitempty"`
 		} `json:"tools"`
 	}
 	if err := json.Unmarshal(resp.Result, &payload); err != nil {
@@ -51,11 +56,13 @@ func (p *Provider) discoverTools() error {
 	var names []string
 	for _, tool := range payload.Tools {
 		def := providers.ToolDefinition{
-			Name:        tool.Name,
-			Description: tool.Description,
-			Parameters:  tool.Parameters,
+			Name:             tool.Name,
+			Description:      tool.Description,
+			Parameters:       tool.Parameters,
+			Examples:         tool.Examples,
+			RequiresApproval: tool.RequiresApproval,
 		}
-		key := strings.ToLower(tool.Name)
+		key := toolKey(tool.Name)
 		p.toolIndex[key] = def
 		toolDefs = append(toolDefs, def)
 		names = append(names, tool.Name)
@@ -67,6 +74,129 @@ func (p *Provider) discoverTools() error {
 	return nil
 }
 
+// maxExamplesPerTool caps the examples shown for one tool so the hints stay short.
+const maxExamplesPerTool = 2
+
+// toolExamplesPrompt renders the examples tools advertise as few-shot hints for the
+// system prompt, or returns "" when no tool has any.
+func toolExamplesPrompt(defs []providers.ToolDefinition) string {
+	var b strings.Builder
+	for _, def := range defs {
+		for i, example := range def.Examples {
+			if i == maxExamplesPerTool {
+				break
+			}
+			args := "{}"
+			if len(example.Arguments) > 0 {
+				data, err := json.Marshal(example.Arguments)
+				if err != nil {
+					continue
+				}
+				args = string(data)
+			}
+			if b.Len() == 0 {
+				b.WriteString("Examples of correct tool calls:")
+			}
+			b.WriteString("\n- ")
+			if example.Request != "" {
+				fmt.Fprintf(&b, "User: %q -> ", example.Request)
+			}
+			fmt.Fprintf(&b, "call %s with %s", def.Name, args)
+			if example.Result != "" {
+				fmt.Fprintf(&b, " (returns %s)", strings.TrimSuffix(example.Result, "."))
+			}
+		}
+	}
+	return b.String()
+}
+
+// Tools returns the tool definitions advertised by the MCP server.
+func (p *Provider) Tools() []providers.ToolDefinition {
+	return append([]providers.ToolDefinition(nil), p.toolDefs...)
+}
+
+// CallTool invokes a tool directly, bypassing any LLM, and returns the raw JSON-RPC result.
+func (p *Provider) CallTool(ctx context.Context, name string, args map[string]any) (json.RawMessage, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	if tool, ok := p.externalTools[toolKey(name)]; ok {
+		return tool.call(ctx, args)
+	}
+	params := map[string]any{
+		"name":      name,
+		"arguments": args,
+	}
+	meta := rpcMetadata{tool: name, method: "tools/call"}
+	resp, err := p.rpcCall(ctx, "tools/call", params, meta)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolveResources(ctx, meta, resp.Result)
+}
+
+// BatchCall is one call of a CallTools batch. ID keys its result and defaults to the
+// call's position in the batch.
+type BatchCall struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// CallTools invokes independent tools concurrently with a single tools/callBatch request
+// and returns each call's raw tools/call result keyed by call ID. Tools of external
+// servers are called one by one and merged into the results.
+func (p *Provider) CallTools(ctx context.Context, calls []BatchCall) (map[string]json.RawMessage, error) {
+	external := make(map[string]json.RawMessage)
+	var batch []BatchCall
+	for i := range calls {
+		if calls[i].Arguments == nil {
+			calls[i].Arguments = map[string]any{}
+		}
+		// Splitting the batch shifts positions, so key position-keyed calls explicitly.
+		if calls[i].ID == "" {
+			calls[i].ID = strconv.Itoa(i)
+		}
+		tool, ok := p.externalTools[toolKey(calls[i].Name)]
+		if !ok {
+			batch = append(batch, calls[i])
+			continue
+		}
+		result, err := tool.call(ctx, calls[i].Arguments)
+		if err != nil {
+			return nil, err
+		}
+		external[calls[i].ID] = result
+	}
+	if len(batch) == 0 {
+		return external, nil
+	}
+	calls = batch
+
+	meta := rpcMetadata{tool: fmt.Sprintf("batch(%d)", len(calls)), method: "tools/callBatch"}
+	resp, err := p.rpcCall(ctx, "tools/callBatch", map[string]any{"calls": calls}, meta)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Results map[string]json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Result, &payload); err != nil {
+		return nil, fmt.Errorf("decode tools/callBatch result: %w", err)
+	}
+	for id, result := range payload.Results {
+		resolved, err := p.resolveResources(ctx, meta, result)
+		if err != nil {
+			return nil, err
+		}
+		payload.Results[id] = resolved
+	}
+	for id, result := range external {
+		payload.Results[id] = result
+	}
+	return payload.Results, nil
+}
+
 // selectTool attempts to select a tool based on keywords in the user's chat history.
 func (p *Provider) selectTool(history []providers.ChatMessage) (string, string) {
 	if len(history) == 0 || len(p.toolIndex) == 0 {
@@ -89,10 +219,32 @@ func (p *Provider) selectTool(history []providers.ChatMessage) (string, string)
 	return "", ""
 }
 
-// toolCallResponse represents the response from a tool call, including output and a retry flag.
+// toolCallResponse represents the response from a tool call, including output, any
+// structured content the tool returned, and a retry flag.
 type toolCallResponse struct {
-	Output string
-	Retry  bool
+	Output     string
+	Structured json.RawMessage
+	Retry      bool
+}
+
+// jsonModeOutput returns the tool's structured content as compact JSON when the
+// conversation is in JSON mode, so the model receives the data without an
+// interpretation round-trip or re-parsing of stringified text.
+func (r toolCallResponse) jsonModeOutput(jsonMode bool) (string, bool) {
+	if !jsonMode || len(r.Structured) == 0 {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, r.Structured); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// toolKey normalizes a tool name for the tool index and the external tool lookup, since
+// models do not always keep a tool's advertised casing.
+func toolKey(name string) string {
+	return strings.ToLower(name)
 }
 
 // callTool executes a tool via an RPC call to the MCP.
@@ -100,6 +252,13 @@ func (p *Provider) callTool(ctx context.Context, host, model, name string, args
 	if args == nil {
 		args = map[string]any{}
 	}
+	if tool, ok := p.externalTools[toolKey(name)]; ok {
+		result, err := tool.call(ctx, args)
+		if err != nil || len(result) == 0 {
+			return toolCallResponse{}, err
+		}
+		return p.parseToolResult(name, result)
+	}
 	params := map[string]any{
 		"name":      name,
 		"arguments": args,
@@ -111,21 +270,41 @@ func (p *Provider) callTool(ctx context.Context, host, model, name string, args
 		method: "tools/call",
 	}
 	resp, err := p.rpcCall(ctx, "tools/call", params, meta)
+	var rpcErr *jsonrpcError
+	if errors.As(err, &rpcErr) && rpcErr.Code == -32602 {
+		// Arguments that failed the server's schema validation go back to the model to fix.
+		return toolCallResponse{Output: rpcErr.Message, Retry: true}, nil
+	}
 	if err != nil {
 		return toolCallResponse{}, err
 	}
 	if len(resp.Result) == 0 {
 		return toolCallResponse{}, nil
 	}
+	result, err := p.resolveResources(ctx, meta, resp.Result)
+	if err != nil {
+		return toolCallResponse{}, err
+	}
+	return p.parseToolResult(name, result)
+}
+
+// parseToolResult turns a tools/call result into the tool's output, logging its log parts
+// and noting a retry request.
+func (p *Provider) parseToolResult(name string, result json.RawMessage) (toolCallResponse, error) {
 	var payload struct {
 		Content []struct {
 			Type string `json:"type"`
 			Text string `json:"text"`
 		} `json:"content"`
+		StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
 	}
-	if err := json.Unmarshal(resp.Result, &payload); err != nil {
+	if err := json.Unmarshal(result, &payload); err != nil {
 		return toolCallResponse{}, err
 	}
+	structured := payload.StructuredContent
+	if string(structured) == "null" {
+		structured = nil
+	}
 
 	var (
 		jsonPart      string
@@ -155,6 +334,9 @@ func (p *Provider) callTool(ctx context.Context, host, model, name string, args
 			parts = append(parts, part.Text)
 		}
 	}
+	if len(structured) > 0 {
+		jsonPart = string(structured)
+	}
 	if strings.TrimSpace(jsonPart) != "" && strings.TrimSpace(interpretPart) != "" {
 		env := map[string]any{
 			"__mcp_interpret__": true,
@@ -164,10 +346,10 @@ func (p *Provider) callTool(ctx context.Context, host, model, name string, args
 		}
 		data, err := json.Marshal(env)
 		if err == nil {
-			return toolCallResponse{Output: string(data)}, nil
+			return toolCallResponse{Output: string(data), Structured: structured}, nil
 		}
 	}
-	return toolCallResponse{Output: strings.Join(parts, "\n"), Retry: retryRequested}, nil
+	return toolCallResponse{Output: strings.Join(parts, "\n"), Structured: structured, Retry: retryRequested}, nil
 }
 
 // fixWithLLMRoundTrip performs a one-off, non-streaming LLM request to correct and reissue a failing tool call.
@@ -207,19 +389,17 @@ func (p *Provider) fixWithLLMRoundTrip(ctx context.Context, req providers.Stream
 		if nextAttempt > 0 {
 			wireArgs["__mcp_attempt"] = nextAttempt
 		}
-		toolCtx, cancel := context.WithTimeout(execCtx, p.cfg.MCPInitTimeoutDuration())
-		defer cancel()
 		attemptLabel := nextAttempt
 		if attemptLabel <= 0 {
 			attemptLabel = 1
 		}
-		logging.LogEvent("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d (fix round-trip)", name, hostName, req.Model, attemptLabel, p.cfg.MCPRetryAttempts())
+		mcpLog.Warnf("MCP tool attempt: tool=%s host=%s model=%s attempt=%d/%d (fix round-trip)", name, hostName, req.Model, attemptLabel, p.cfg.MCPRetryAttempts())
 		p.logToolRequest(name, hostName, req.Model, wireArgs)
-		resp, err := p.callTool(toolCtx, hostName, req.Model, name, wireArgs)
+		resp, err := p.auditedCall(execCtx, req, name, wireArgs)
 		tcResp = resp
 		tcErr = err
 		if err != nil {
-			p.log("[ERROR] Tool retry via LLM failed: tool=%s host=%s model=%s reason=%v", name, hostName, req.Model, err)
+			mcpLog.Errorf("Tool retry via LLM failed: tool=%s host=%s model=%s reason=%v", name, hostName, req.Model, err)
 			return "", err
 		}
 		p.logToolSuccess(name, resp.Output, hostName, req.Model)
@@ -247,19 +427,19 @@ func (p *Provider) fixWithLLMRoundTrip(ctx context.Context, req providers.Stream
 		"disable_streaming": true,
 	}
 	if data, err := json.Marshal(sendSummary); err == nil {
-		logging.LogRequest("MCP->LLM", hostName, req.Model, toolName, data)
+		mcpLog.Request("MCP->LLM", hostName, req.Model, toolName, data)
 	} else {
-		logging.LogEvent("MCP->LLM fix send: tool=%s host=%s model=%s", toolName, hostName, req.Model)
+		mcpLog.Eventf("MCP->LLM fix send: tool=%s host=%s model=%s", toolName, hostName, req.Model)
 	}
 	if err := p.fallback.Stream(ctx, fixReq, cb); err != nil {
-		logging.LogEvent("MCP->LLM fix failed: tool=%s host=%s model=%s err=%v", toolName, hostName, req.Model, err)
+		mcpLog.Errorf("MCP->LLM fix failed: tool=%s host=%s model=%s err=%v", toolName, hostName, req.Model, err)
 		return "", false, false, err
 	}
 	dur := time.Since(start)
 	fixed := strings.TrimSpace(out.String())
 
 	recvPreview := truncateForLog(fixed, 500)
-	logging.LogRequest("LLM->MCP", hostName, req.Model, toolName, map[string]any{
+	mcpLog.Request("LLM->MCP", hostName, req.Model, toolName, map[string]any{
 		"characters": len(fixed),
 		"duration":   dur.String(),
 		"preview":    recvPreview,
@@ -305,7 +485,7 @@ func (p *Provider) maybeInterpretResult(ctx context.Context, req providers.Strea
 	var out strings.Builder
 	start := time.Now()
 	hostName := hostLabel(req.Host)
-	logging.LogRequest("MCP->LLM", hostName, req.Model, toolName, map[string]any{
+	mcpLog.Request("MCP->LLM", hostName, req.Model, toolName, map[string]any{
 		"json":   jsonContent,
 		"prompt": prompt,
 	})
@@ -317,12 +497,12 @@ func (p *Provider) maybeInterpretResult(ctx context.Context, req providers.Strea
 		OnComplete: func(meta providers.StreamMetadata) error { return nil },
 	}
 	if err := p.fallback.Stream(ctx, interpReq, cb); err != nil {
-		logging.LogEvent("MCP->LLM interpret failed: tool=%s host=%s model=%s err=%v", toolName, hostName, req.Model, err)
+		mcpLog.Errorf("MCP->LLM interpret failed: tool=%s host=%s model=%s err=%v", toolName, hostName, req.Model, err)
 		return "", false
 	}
 	dur := time.Since(start)
 	interpreted := strings.TrimSpace(out.String())
-	logging.LogRequest("LLM->MCP", hostName, req.Model, toolName, map[string]any{
+	mcpLog.Request("LLM->MCP", hostName, req.Model, toolName, map[string]any{
 		"characters": len(interpreted),
 		"duration":   dur.String(),
 		"output":     interpreted,
@@ -0,0 +1,39 @@
+// internal/providers/mcp/tools_test.go
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestToolExamplesPrompt verifies advertised examples are rendered as few-shot hints,
+// capped per tool, and that tools without examples add nothing.
+func TestToolExamplesPrompt(t *testing.T) {
+	if got := toolExamplesPrompt([]providers.ToolDefinition{{Name: "current_time"}}); got != "" {
+		t.Fatalf("expected no hints without examples, got %q", got)
+	}
+
+	defs := []providers.ToolDefinition{
+		{Name: "current_time", Examples: []providers.ToolExample{{Request: "What time is it?", Result: "The local time."}}},
+		{Name: "current_weather", Examples: []providers.ToolExample{
+			{Arguments: map[string]any{"location": "Paris, France"}},
+			{Arguments: map[string]any{"location": "Portland, OR"}},
+			{Arguments: map[string]any{"location": "Lima, Peru"}},
+		}},
+	}
+	got := toolExamplesPrompt(defs)
+	for _, want := range []string{
+		`- User: "What time is it?" -> call current_time with {} (returns The local time)`,
+		`- call current_weather with {"location":"Paris, France"}`,
+		`Portland, OR`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in hints:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Lima") {
+		t.Fatalf("expected at most %d examples per tool:\n%s", maxExamplesPerTool, got)
+	}
+}
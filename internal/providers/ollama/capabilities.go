@@ -0,0 +1,132 @@
+// internal/providers/ollama/capabilities.go
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// showResponse holds the parts of /api/show that describe a model's features. Servers
+// before Ollama 0.6.4 omit Capabilities, so the template and projector are checked instead.
+type showResponse struct {
+	Capabilities  []string       `json:"capabilities"`
+	Template      string         `json:"template"`
+	ProjectorInfo map[string]any `json:"projector_info"`
+	Details       struct {
+		Families []string `json:"families"`
+	} `json:"details"`
+	// ModelInfo holds the GGUF metadata, including "<architecture>.context_length".
+	ModelInfo map[string]any `json:"model_info"`
+	// Parameters lists the Modelfile parameters, one "name value" per line.
+	Parameters string `json:"parameters"`
+}
+
+// ModelCapabilities asks the host's /api/show which features model supports. Answers are
+// cached per host and model, so pickers can ask about every model cheaply. Hosts without
+// /api/show, such as llama.cpp servers, report unknown capabilities.
+func (p *Provider) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (providers.Capabilities, error) {
+	key := host.URL + "\x00" + model
+	p.mu.Lock()
+	caps, ok := p.capabilities[key]
+	p.mu.Unlock()
+	if ok {
+		return caps, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]any{"model": host.ResolveModel(model)})
+	if err != nil {
+		return providers.Capabilities{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host.URL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return providers.Capabilities{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client, err := p.clients.For(host)
+	if err != nil {
+		return providers.Capabilities{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return providers.Capabilities{}, providers.TransportError("ollama show", hostIdentifier(host), model, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var show showResponse
+		if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+			return providers.Capabilities{}, providers.TransportError("ollama show", hostIdentifier(host), model, err)
+		}
+		caps = show.capabilities()
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		// Not an Ollama server, or a model it does not have; neither says what is missing.
+		caps = providers.Capabilities{}
+	default:
+		data, _ := io.ReadAll(resp.Body)
+		return providers.Capabilities{}, providers.StatusError("ollama show", hostIdentifier(host), model, resp.StatusCode, data)
+	}
+	providerLog.Debugf("Model capabilities: host=%s model=%s known=%t json=%t tools=%t vision=%t context=%d", hostIdentifier(host), model, caps.Known, caps.JSON, caps.Tools, caps.Vision, caps.ContextLength)
+
+	p.mu.Lock()
+	if p.capabilities == nil {
+		p.capabilities = make(map[string]providers.Capabilities)
+	}
+	p.capabilities[key] = caps
+	p.mu.Unlock()
+	return caps, nil
+}
+
+// capabilities maps an /api/show answer onto agon's features. Every model that generates
+// text honors Ollama's grammar-constrained format, so JSON follows "completion".
+func (s showResponse) capabilities() providers.Capabilities {
+	if len(s.Capabilities) > 0 {
+		return providers.Capabilities{
+			Known:         true,
+			JSON:          slices.Contains(s.Capabilities, "completion"),
+			Tools:         slices.Contains(s.Capabilities, "tools"),
+			Vision:        slices.Contains(s.Capabilities, "vision"),
+			ContextLength: s.contextLength(),
+		}
+	}
+	return providers.Capabilities{
+		Known:         true,
+		JSON:          true,
+		Tools:         strings.Contains(s.Template, ".Tools"),
+		Vision:        len(s.ProjectorInfo) > 0 || slices.Contains(s.Details.Families, "clip") || slices.Contains(s.Details.Families, "mllama"),
+		ContextLength: s.contextLength(),
+	}
+}
+
+// contextLength returns the tokens the model is run with: num_ctx when the Modelfile sets
+// it, or else the context length the model was trained for.
+func (s showResponse) contextLength() int {
+	for _, line := range strings.Split(s.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	for key, value := range s.ModelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if n, ok := value.(float64); ok && n > 0 {
+			return int(n)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+// internal/providers/ollama/capabilities_test.go
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// TestModelCapabilities verifies that /api/show capabilities are mapped and cached per
+// model, that older servers are read from the template, and that a missing endpoint
+// reports unknown capabilities. The context window prefers num_ctx over the trained length.
+func TestModelCapabilities(t *testing.T) {
+	var shows atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/show" {
+			http.NotFound(w, r)
+			return
+		}
+		shows.Add(1)
+		var req struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Model {
+		case "qwen3:4b":
+			_, _ = w.Write([]byte(`{"capabilities": ["completion", "tools"], "model_info": {"qwen3.context_length": 40960}}`))
+		case "llava:7b":
+			_, _ = w.Write([]byte(`{"template": "{{ .Prompt }}", "projector_info": {"clip.has_vision_encoder": true}, "parameters": "stop \"USER:\"\nnum_ctx 8192", "model_info": {"llama.context_length": 32768}}`))
+		case "nomic-embed-text":
+			_, _ = w.Write([]byte(`{"capabilities": ["embedding"]}`))
+		default:
+			http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	host := appconfig.Host{Name: "ollama", URL: server.URL}
+	for model, want := range map[string]providers.Capabilities{
+		"qwen3:4b":         {Known: true, JSON: true, Tools: true, ContextLength: 40960},
+		"llava:7b":         {Known: true, JSON: true, Vision: true, ContextLength: 8192},
+		"nomic-embed-text": {Known: true},
+		"missing":          {},
+	} {
+		for i := 0; i < 2; i++ {
+			caps, err := provider.ModelCapabilities(context.Background(), host, model)
+			if err != nil {
+				t.Fatalf("ModelCapabilities(%s) returned error: %v", model, err)
+			}
+			if caps != want {
+				t.Fatalf("ModelCapabilities(%s) = %+v, want %+v", model, caps, want)
+			}
+		}
+	}
+	if got := shows.Load(); got != 4 {
+		t.Fatalf("expected each model to be probed once, got %d probes", got)
+	}
+}
@@ -0,0 +1,164 @@
+// internal/providers/ollama/load.go
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/providers"
+)
+
+// deferredMetric is the llama.cpp Prometheus gauge counting requests waiting for a slot.
+const deferredMetric = "llamacpp:requests_deferred"
+
+// loadProbes remembers per host which load endpoints the server lacks and which models a
+// load request is in flight for.
+type loadProbes struct {
+	unsupported map[string]bool
+	loading     map[string]map[string]int
+}
+
+// slotState is one entry of llama.cpp's /slots response; older servers report state
+// (0 idle, 1 processing) instead of is_processing.
+type slotState struct {
+	IsProcessing *bool `json:"is_processing"`
+	State        *int  `json:"state"`
+}
+
+// HostLoad reports the slot usage and queue length llama.cpp-compatible servers expose on
+// /slots and /metrics, plus the models this provider is still loading on the host.
+// Endpoints a host does not serve, such as on a plain Ollama server, are skipped from
+// then on.
+func (p *Provider) HostLoad(ctx context.Context, host appconfig.Host) (providers.HostLoad, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var load providers.HostLoad
+	load.Loading = p.loadingModels(host)
+
+	body, err := p.probe(ctx, host, "/slots")
+	if err != nil {
+		return load, err
+	}
+	if body != nil {
+		var slots []slotState
+		if err := json.Unmarshal(body, &slots); err != nil {
+			return load, fmt.Errorf("decode %s/slots: %w", host.URL, err)
+		}
+		load.Slots = len(slots)
+		for _, slot := range slots {
+			busy := (slot.IsProcessing != nil && *slot.IsProcessing) || (slot.State != nil && *slot.State != 0)
+			if !busy {
+				load.IdleSlots++
+			}
+		}
+	}
+
+	body, err = p.probe(ctx, host, "/metrics")
+	if err != nil {
+		return load, err
+	}
+	if body != nil {
+		load.Queued = parseDeferred(string(body))
+	}
+	return load, nil
+}
+
+// probe fetches a load endpoint, returning nil without error when the host does not
+// serve it.
+func (p *Provider) probe(ctx context.Context, host appconfig.Host, path string) ([]byte, error) {
+	key := host.URL + path
+	p.mu.Lock()
+	skip := p.probes.unsupported[key]
+	p.mu.Unlock()
+	if skip {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := p.clients.For(host)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, providers.TransportError("ollama "+path, hostIdentifier(host), "", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		p.mu.Lock()
+		if p.probes.unsupported == nil {
+			p.probes.unsupported = make(map[string]bool)
+		}
+		p.probes.unsupported[key] = true
+		p.mu.Unlock()
+		return nil, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, providers.StatusError("ollama "+path, hostIdentifier(host), "", resp.StatusCode, body)
+	}
+}
+
+// parseDeferred returns the deferred-request gauge from a Prometheus text exposition.
+func parseDeferred(text string) int {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != deferredMetric {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return 0
+		}
+		return int(value)
+	}
+	return 0
+}
+
+// trackLoading marks model as loading on host until the returned function is called.
+func (p *Provider) trackLoading(host appconfig.Host, model string) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.probes.loading == nil {
+		p.probes.loading = make(map[string]map[string]int)
+	}
+	if p.probes.loading[host.URL] == nil {
+		p.probes.loading[host.URL] = make(map[string]int)
+	}
+	p.probes.loading[host.URL][model]++
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.probes.loading[host.URL][model]--; p.probes.loading[host.URL][model] <= 0 {
+			delete(p.probes.loading[host.URL], model)
+		}
+	}
+}
+
+// loadingModels returns the models a load request is in flight for on host, sorted.
+func (p *Provider) loadingModels(host appconfig.Host) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var models []string
+	for model := range p.probes.loading[host.URL] {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
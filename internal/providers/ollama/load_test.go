@@ -0,0 +1,72 @@
+// internal/providers/ollama/load_test.go
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestHostLoadReadsLlamaCppSlots verifies that slot usage and the deferred-request gauge are
+// read from a llama.cpp-style server and reported as a busy host.
+func TestHostLoadReadsLlamaCppSlots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slots":
+			_, _ = w.Write([]byte(`[{"id":0,"is_processing":true},{"id":1,"state":1}]`))
+		case "/metrics":
+			_, _ = w.Write([]byte("# HELP llamacpp:requests_deferred Number of requests deferred.\n# TYPE llamacpp:requests_deferred gauge\nllamacpp:requests_processing 2\nllamacpp:requests_deferred 3\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	load, err := New(&appconfig.Config{TimeoutSeconds: 5}).HostLoad(context.Background(), appconfig.Host{Name: "llama", URL: server.URL})
+	if err != nil {
+		t.Fatalf("HostLoad returned error: %v", err)
+	}
+	if load.Slots != 2 || load.IdleSlots != 0 || load.Queued != 3 || !load.Busy() {
+		t.Fatalf("unexpected load: %+v", load)
+	}
+	if got := load.String(); got != "host busy: 3 requests queued, all 2 slots in use" {
+		t.Fatalf("unexpected load description %q", got)
+	}
+}
+
+// TestHostLoadSkipsMissingEndpoints verifies that an Ollama server without load endpoints is
+// probed once and that in-flight model loads are reported.
+func TestHostLoadSkipsMissingEndpoints(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	host := appconfig.Host{Name: "ollama", URL: server.URL}
+	done := provider.trackLoading(host, "llama3.2:3b")
+
+	for i := 0; i < 3; i++ {
+		load, err := provider.HostLoad(context.Background(), host)
+		if err != nil {
+			t.Fatalf("HostLoad returned error: %v", err)
+		}
+		if load.Slots != 0 || len(load.Loading) != 1 || load.String() != "host busy: loading llama3.2:3b" {
+			t.Fatalf("unexpected load: %+v", load)
+		}
+	}
+	if got := probes.Load(); got != 2 {
+		t.Fatalf("expected each missing endpoint to be probed once, got %d requests", got)
+	}
+
+	done()
+	if load, _ := provider.HostLoad(context.Background(), host); load.Busy() {
+		t.Fatalf("expected an idle host once the load finished: %+v", load)
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mwiater/agon/internal/appconfig"
@@ -19,23 +19,28 @@ import (
 	"github.com/mwiater/agon/internal/providers"
 )
 
+// providerLog logs requests and responses under the provider subsystem.
+var providerLog = logging.For(logging.SubsystemProvider)
+
 // Provider implements the providers.ChatProvider interface using Ollama HTTP APIs.
 type Provider struct {
-	client  *http.Client
+	clients *providers.HostClients
 	timeout time.Duration
-	debug   bool
+
+	mu           sync.Mutex
+	probes       loadProbes
+	capabilities map[string]providers.Capabilities
 }
 
 // New constructs a Provider configured with the application's request timeout.
 func New(cfg *appconfig.Config) *Provider {
 	timeout := cfg.RequestTimeout()
 	return &Provider{
-		client: &http.Client{
+		clients: providers.NewHostClients(&http.Client{
 			Timeout:   timeout,
 			Transport: &http.Transport{ForceAttemptHTTP2: false},
-		},
+		}),
 		timeout: timeout,
-		debug:   cfg.Debug,
 	}
 }
 
@@ -69,27 +74,32 @@ func (p *Provider) LoadedModels(ctx context.Context, host appconfig.Host) ([]str
 	defer cancel()
 
 	endpoint := host.URL + "/api/ps"
-	logging.LogRequest("AGON->LLM", hostIdentifier(host), "", "", map[string]string{"method": http.MethodGet, "url": endpoint})
+	providerLog.Request("AGON->LLM", hostIdentifier(host), "", "", map[string]string{"method": http.MethodGet, "url": endpoint})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := p.client.Do(req)
+	client, err := p.clients.For(host)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, providers.TransportError("ollama /api/ps", hostIdentifier(host), "", err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ollama: /api/ps returned %s", resp.Status)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, providers.StatusError("ollama /api/ps", hostIdentifier(host), "", resp.StatusCode, body)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	logging.LogRequest("LLM->AGON", hostIdentifier(host), "", "", body)
+	providerLog.Request("LLM->AGON", hostIdentifier(host), "", "", body)
 
 	var ps ollamaPsResponse
 	if err := json.Unmarshal(body, &ps); err != nil {
@@ -98,23 +108,24 @@ func (p *Provider) LoadedModels(ctx context.Context, host appconfig.Host) ([]str
 
 	names := make([]string, len(ps.Models))
 	for i, m := range ps.Models {
-		names[i] = m.Name
+		names[i] = host.FriendlyModel(m.Name)
 	}
 	return names, nil
 }
 
 // EnsureModelReady triggers a lightweight generate request to make sure the model is loaded.
 func (p *Provider) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
-	logTools(p.debug, nil)
+	logTools(nil)
+	defer p.trackLoading(host, model)()
 	payload := map[string]any{
-		"model": model,
+		"model": host.ResolveModel(model),
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
-	logging.LogRequest("AGON->LLM", hostIdentifier(host), model, "", body)
+	providerLog.Request("AGON->LLM", hostIdentifier(host), model, "", body)
 
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
 	defer cancel()
@@ -125,19 +136,23 @@ func (p *Provider) EnsureModelReady(ctx context.Context, host appconfig.Host, mo
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(req)
+	client, err := p.clients.For(host)
 	if err != nil {
 		return err
 	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return providers.TransportError("ollama /api/generate", hostIdentifier(host), model, err)
+	}
 	defer resp.Body.Close()
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	logging.LogRequest("LLM->AGON", hostIdentifier(host), model, "", respBody)
+	providerLog.Request("LLM->AGON", hostIdentifier(host), model, "", respBody)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("ollama: /api/generate returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+		return providers.StatusError("ollama /api/generate", hostIdentifier(host), model, resp.StatusCode, respBody)
 	}
 
 	return nil
@@ -157,13 +172,13 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 
 	streamEnabled := !req.DisableStreaming
 	payload := map[string]any{
-		"model":    req.Model,
+		"model":    req.Host.ResolveModel(req.Model),
 		"messages": messages,
 		"options":  req.Parameters,
 		"stream":   streamEnabled,
 	}
 
-	logTools(p.debug, req.Tools)
+	logTools(req.Tools)
 
 	if len(req.Tools) > 0 {
 		payload["tools"] = formatToolsForPayload(req.Tools)
@@ -179,9 +194,9 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	}
 
 	if pretty, perr := json.MarshalIndent(payload, "", "  "); perr == nil {
-		logging.LogRequest("AGON->LLM", hostID, req.Model, "", pretty)
+		providerLog.Request("AGON->LLM", hostID, req.Model, "", pretty)
 	} else {
-		logging.LogRequest("AGON->LLM", hostID, req.Model, "", body)
+		providerLog.Request("AGON->LLM", hostID, req.Model, "", body)
 	}
 
 	streamCtx, cancel := context.WithTimeout(ctx, p.timeout)
@@ -192,16 +207,26 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 		return err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := p.client.Do(httpReq)
+	client, err := p.clients.For(req.Host)
 	if err != nil {
 		return err
 	}
+
+	var timing streamTiming
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { timing.firstByteAt = time.Now() },
+	}))
+
+	timing.requestStart = time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return providers.TransportError("ollama /api/chat", hostID, req.Model, err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		logging.LogRequest("LLM->AGON", hostID, req.Model, "", body)
+		providerLog.Request("LLM->AGON", hostID, req.Model, "", body)
 		if req.DisableStreaming && isNoToolCapabilityResponse(body) {
 			if callbacks.OnChunk != nil {
 				if err := callbacks.OnChunk(providers.ChatMessage{Role: "assistant", Content: "This model does not have tool capabilities."}); err != nil {
@@ -220,7 +245,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			}
 			return nil
 		}
-		return fmt.Errorf("ollama: /api/chat returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		return providers.StatusError("ollama /api/chat", hostID, req.Model, resp.StatusCode, body)
 	}
 
 	if !streamEnabled {
@@ -228,11 +253,12 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 		if err != nil {
 			return err
 		}
-		logging.LogRequest("LLM->AGON", hostID, req.Model, "", body)
+		providerLog.Request("LLM->AGON", hostID, req.Model, "", body)
 		var result streamChunk
 		if err := json.Unmarshal(body, &result); err != nil {
 			return err
 		}
+		timing.recordChunk(time.Now(), result.Message.Content != "")
 		output := result.Message.Content
 		toolCalls := result.Message.ToolCalls
 		if len(toolCalls) == 0 {
@@ -243,8 +269,8 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			if len(req.Tools) > 0 {
 				call, err := rebuildToolCallFromContent(output, req.Tools)
 				if err != nil {
-					if p.debug && !errors.Is(err, errNoToolJSONFound) {
-						log.Printf("ollama: unable to reconstruct tool call: %v", err)
+					if !errors.Is(err, errNoToolJSONFound) {
+						providerLog.Debugf("ollama: unable to reconstruct tool call: %v", err)
 					}
 				} else if call != nil {
 					toolCalls = []toolCall{*call}
@@ -271,7 +297,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			}
 		}
 		if callbacks.OnComplete != nil {
-			modelName := result.Model
+			modelName := req.Host.FriendlyModel(result.Model)
 			if modelName == "" {
 				modelName = req.Model
 			}
@@ -286,6 +312,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 				EvalCount:          result.EvalCount,
 				EvalDuration:       result.EvalDuration,
 			}
+			timing.apply(&meta)
 			if err := callbacks.OnComplete(meta); err != nil {
 				return err
 			}
@@ -301,10 +328,11 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return err
+			return providers.TransportError("ollama /api/chat", hostID, req.Model, err)
 		}
+		timing.recordChunk(time.Now(), chunk.Message.Content != "")
 		if data, err := json.Marshal(chunk); err == nil {
-			logging.LogRequest("LLM->AGON", hostID, req.Model, "", data)
+			providerLog.Request("LLM->AGON", hostID, req.Model, "", data)
 		}
 
 		if callbacks.OnChunk != nil {
@@ -320,7 +348,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	}
 
 	if callbacks.OnComplete != nil {
-		modelName := final.Model
+		modelName := req.Host.FriendlyModel(final.Model)
 		if modelName == "" {
 			modelName = req.Model
 		}
@@ -335,6 +363,7 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 			EvalCount:          final.EvalCount,
 			EvalDuration:       final.EvalDuration,
 		}
+		timing.apply(&meta)
 		if err := callbacks.OnComplete(meta); err != nil {
 			return err
 		}
@@ -343,6 +372,30 @@ func (p *Provider) Stream(ctx context.Context, req providers.StreamRequest, call
 	return nil
 }
 
+// streamTiming captures transport-level timestamps while a response is read.
+type streamTiming struct {
+	requestStart time.Time
+	firstByteAt  time.Time
+	firstTokenAt time.Time
+	chunkTimes   []time.Time
+}
+
+// recordChunk notes when a chunk was decoded and whether it carried content.
+func (t *streamTiming) recordChunk(at time.Time, hasContent bool) {
+	t.chunkTimes = append(t.chunkTimes, at)
+	if hasContent && t.firstTokenAt.IsZero() {
+		t.firstTokenAt = at
+	}
+}
+
+// apply copies the captured timestamps onto the stream metadata.
+func (t *streamTiming) apply(meta *providers.StreamMetadata) {
+	meta.RequestStart = t.requestStart
+	meta.FirstByteAt = t.firstByteAt
+	meta.FirstTokenAt = t.firstTokenAt
+	meta.ChunkTimes = t.chunkTimes
+}
+
 // Close releases any resources held by the provider.
 func (p *Provider) Close() error {
 	return nil
@@ -4,6 +4,7 @@ package ollama
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -504,3 +505,106 @@ func TestParseLegacyToolCallMarkupTrailingBrace(t *testing.T) {
 		t.Fatalf("expected empty arguments, got %+v", args)
 	}
 }
+
+// TestProviderStreamRecordsChunkTimings verifies that streamed responses populate the
+// transport-level timestamps used for time-to-first-token.
+func TestProviderStreamRecordsChunkTimings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"model":"test-model","message":{"role":"assistant","content":""},"done":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"model":"test-model","message":{"role":"assistant","content":"hi"},"done":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"model":"test-model","message":{"role":"assistant","content":""},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	req := providers.StreamRequest{
+		Host:  appconfig.Host{Name: "test", URL: server.URL},
+		Model: "test-model",
+	}
+
+	var meta providers.StreamMetadata
+	err := provider.Stream(context.Background(), req, providers.StreamCallbacks{
+		OnComplete: func(m providers.StreamMetadata) error {
+			meta = m
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if meta.RequestStart.IsZero() || meta.FirstByteAt.IsZero() || meta.FirstTokenAt.IsZero() {
+		t.Fatalf("expected timing fields to be set, got %+v", meta)
+	}
+	if len(meta.ChunkTimes) != 3 {
+		t.Fatalf("expected 3 chunk times, got %d", len(meta.ChunkTimes))
+	}
+	if meta.FirstTokenAt != meta.ChunkTimes[1] {
+		t.Fatalf("expected first token at the first non-empty chunk")
+	}
+	if meta.TimeToFirstToken() < 0 {
+		t.Fatalf("unexpected time to first token: %v", meta.TimeToFirstToken())
+	}
+}
+
+// TestProviderStreamTypedErrors verifies that HTTP and transport failures surface as
+// categorized provider errors that errors.Is can match.
+func TestProviderStreamTypedErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "missing model", status: http.StatusNotFound, body: `{"error":"model 'nope' not found"}`, want: providers.ErrModelNotFound},
+		{name: "context overflow", status: http.StatusBadRequest, body: `{"error":"input exceeds the context length"}`, want: providers.ErrContextOverflow},
+		{name: "gateway timeout", status: http.StatusGatewayTimeout, body: ``, want: providers.ErrTimeout},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			provider := New(&appconfig.Config{TimeoutSeconds: 5})
+			err := provider.Stream(context.Background(), providers.StreamRequest{
+				Host:  appconfig.Host{Name: "test", URL: server.URL},
+				Model: "nope",
+			}, providers.StreamCallbacks{})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			var perr *providers.Error
+			if !errors.As(err, &perr) || perr.Status != tc.status || perr.Host != "test" {
+				t.Fatalf("expected provider error details, got %#v", err)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	provider := New(&appconfig.Config{TimeoutSeconds: 5})
+	err := provider.Stream(context.Background(), providers.StreamRequest{
+		Host:  appconfig.Host{Name: "down", URL: url},
+		Model: "llama3",
+	}, providers.StreamCallbacks{})
+	if !errors.Is(err, providers.ErrConnectionRefused) {
+		t.Fatalf("expected connection refused, got %v", err)
+	}
+	if !providers.Retryable(err) || providers.Retryable(providers.StatusError("chat", "h", "m", http.StatusNotFound, nil)) {
+		t.Fatalf("unexpected retry policy")
+	}
+	if got := providers.ErrorCategory(err); got != "connection_refused" {
+		t.Fatalf("unexpected category %q", got)
+	}
+}
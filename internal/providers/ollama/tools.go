@@ -6,23 +6,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/mwiater/agon/internal/appconfig"
+	"github.com/mwiater/agon/internal/logging"
 	"github.com/mwiater/agon/internal/providers"
 )
 
-// logTools logs the available tool names if debug mode is enabled.
-func logTools(debug bool, tools []providers.ToolDefinition) {
-	if !debug {
+// logTools logs the available tool names when provider debug logging is enabled.
+func logTools(tools []providers.ToolDefinition) {
+	if !providerLog.Enabled(logging.LevelDebug) {
 		return
 	}
 	if len(tools) == 0 {
-		log.Printf("Tools: false")
+		providerLog.Debugf("Tools: false")
 		return
 	}
 	names := make([]string, 0, len(tools))
@@ -32,10 +32,10 @@ func logTools(debug bool, tools []providers.ToolDefinition) {
 		}
 	}
 	if len(names) == 0 {
-		log.Printf("Tools: false")
+		providerLog.Debugf("Tools: false")
 		return
 	}
-	log.Printf("Tools: {%s}", strings.Join(names, ", "))
+	providerLog.Debugf("Tools: {%s}", strings.Join(names, ", "))
 }
 
 // formatToolsForPayload converts a slice of ToolDefinition into a format suitable for the Ollama API payload.
@@ -25,12 +25,36 @@ type ToolDefinition struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description,omitempty"`
 	Parameters  map[string]any `json:"parameters,omitempty"`
+	// Examples are sample calls the tool's server advertises as few-shot hints.
+	Examples []ToolExample `json:"examples,omitempty"`
+	// RequiresApproval means the user must confirm each call before it is made.
+	RequiresApproval bool `json:"requiresApproval,omitempty"`
+}
+
+// ToolExample is a sample call of a tool: the request it answers, the arguments to send
+// and a summary of the result.
+type ToolExample struct {
+	Request   string         `json:"request,omitempty"`
+	Arguments map[string]any `json:"arguments"`
+	Result    string         `json:"result,omitempty"`
 }
 
 // ToolExecutor is a function type for executing a tool.
 // It takes the tool's name and arguments and returns the result as a string.
 type ToolExecutor func(ctx context.Context, name string, args map[string]any) (string, error)
 
+// ToolApproval describes a tool call waiting for the user's approval.
+type ToolApproval struct {
+	Host      string
+	Model     string
+	Tool      string
+	Arguments map[string]any
+}
+
+// ToolApprover asks the user whether a tool call may go ahead. It blocks until the user
+// answers or ctx is done.
+type ToolApprover func(ctx context.Context, call ToolApproval) (bool, error)
+
 // StreamMetadata contains metadata about a completed chat stream,
 // including performance metrics like timing and token counts.
 type StreamMetadata struct {
@@ -43,6 +67,30 @@ type StreamMetadata struct {
 	PromptEvalDuration int64
 	EvalCount          int
 	EvalDuration       int64
+
+	// RequestStart is when the provider dispatched the request to the backend.
+	RequestStart time.Time
+	// FirstByteAt is when the first byte of the backend response arrived.
+	FirstByteAt time.Time
+	// FirstTokenAt is when the first chunk carrying content was decoded.
+	FirstTokenAt time.Time
+	// ChunkTimes records when each streamed chunk was decoded, in order.
+	ChunkTimes []time.Time
+	// QueueWait is how long the request waited for a free slot on its host before it
+	// was dispatched, so queueing can be told apart from inference time.
+	QueueWait time.Duration
+	// ToolCalls names the tools called while answering, in call order; empty when the
+	// model answered without tools.
+	ToolCalls []string
+}
+
+// TimeToFirstToken returns the transport-level time to first token, or zero when
+// the provider did not record chunk timestamps.
+func (m StreamMetadata) TimeToFirstToken() time.Duration {
+	if m.RequestStart.IsZero() || m.FirstTokenAt.IsZero() {
+		return 0
+	}
+	return m.FirstTokenAt.Sub(m.RequestStart)
 }
 
 // StreamRequest encapsulates all the information needed to initiate a chat stream.
@@ -56,6 +104,11 @@ type StreamRequest struct {
 	Tools            []ToolDefinition
 	DisableStreaming bool
 	ToolExecutor     ToolExecutor
+	// ApproveTool confirms calls of tools that require approval; without it they are declined.
+	ApproveTool ToolApprover
+	// QueueWait is how long a Scheduler in front of the provider held the request before
+	// passing it on.
+	QueueWait time.Duration
 }
 
 // StreamCallbacks defines the callback functions that are invoked during a chat stream.
@@ -0,0 +1,125 @@
+// internal/providers/reloadable.go
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// Reloadable is a ChatProvider whose underlying provider can be swapped while it is in
+// use, so a reloaded config takes effect without restarting the TUI. Calls already
+// running finish on the provider they started with, which is closed once they are done.
+type Reloadable struct {
+	mu      sync.Mutex
+	current *reloadSlot
+	closed  bool
+}
+
+// reloadSlot is one provider and the number of calls using it.
+type reloadSlot struct {
+	provider ChatProvider
+	active   int
+	retired  bool
+}
+
+// NewReloadable wraps provider so it can be swapped later.
+func NewReloadable(provider ChatProvider) *Reloadable {
+	return &Reloadable{current: &reloadSlot{provider: provider}}
+}
+
+// Swap makes provider the one new calls use. The previous provider is closed as soon
+// as the calls using it return; after Close, provider is closed straight away.
+func (r *Reloadable) Swap(provider ChatProvider) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return provider.Close()
+	}
+	old := r.current
+	r.current = &reloadSlot{provider: provider}
+	old.retired = true
+	idle := old.active == 0
+	r.mu.Unlock()
+	if idle {
+		return old.provider.Close()
+	}
+	return nil
+}
+
+// Unwrap returns the provider new calls use.
+func (r *Reloadable) Unwrap() ChatProvider {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current.provider
+}
+
+// acquire returns the current slot, counting the caller as using it until release.
+func (r *Reloadable) acquire() *reloadSlot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.active++
+	return r.current
+}
+
+// release ends a call on slot, closing its provider if it was swapped out meanwhile.
+func (r *Reloadable) release(slot *reloadSlot) {
+	r.mu.Lock()
+	slot.active--
+	idle := slot.retired && slot.active == 0
+	r.mu.Unlock()
+	if idle {
+		_ = slot.provider.Close()
+	}
+}
+
+// Stream passes the call through to the current provider.
+func (r *Reloadable) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	slot := r.acquire()
+	defer r.release(slot)
+	return slot.provider.Stream(ctx, req, callbacks)
+}
+
+// LoadedModels passes the call through to the current provider.
+func (r *Reloadable) LoadedModels(ctx context.Context, host appconfig.Host) ([]string, error) {
+	slot := r.acquire()
+	defer r.release(slot)
+	return slot.provider.LoadedModels(ctx, host)
+}
+
+// EnsureModelReady passes the call through to the current provider.
+func (r *Reloadable) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
+	slot := r.acquire()
+	defer r.release(slot)
+	return slot.provider.EnsureModelReady(ctx, host, model)
+}
+
+// HostLoad passes the call through to the current provider, if it can report load.
+func (r *Reloadable) HostLoad(ctx context.Context, host appconfig.Host) (HostLoad, error) {
+	slot := r.acquire()
+	defer r.release(slot)
+	load, _, err := ReportHostLoad(ctx, slot.provider, host)
+	return load, err
+}
+
+// ModelCapabilities passes the call through to the current provider, if it can report them.
+func (r *Reloadable) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (Capabilities, error) {
+	slot := r.acquire()
+	defer r.release(slot)
+	return ReportModelCapabilities(ctx, slot.provider, host, model)
+}
+
+// Close closes the current provider, even if calls are still using it, as the other
+// providers do. A swapped-out provider still in use is closed when its last call returns.
+func (r *Reloadable) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	provider := r.current.provider
+	r.mu.Unlock()
+	return provider.Close()
+}
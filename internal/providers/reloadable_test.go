@@ -0,0 +1,72 @@
+// internal/providers/reloadable_test.go
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// blockingProvider streams until release is closed and records whether it was closed.
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+	closed  bool
+}
+
+func (b *blockingProvider) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	close(b.started)
+	<-b.release
+	return nil
+}
+
+func (b *blockingProvider) LoadedModels(context.Context, appconfig.Host) ([]string, error) {
+	return nil, nil
+}
+
+func (b *blockingProvider) EnsureModelReady(context.Context, appconfig.Host, string) error {
+	return nil
+}
+
+func (b *blockingProvider) Close() error {
+	b.closed = true
+	return nil
+}
+
+// TestReloadableSwap verifies a stream keeps the provider it started on, that provider is
+// closed once the stream returns, and new calls go to the swapped-in provider.
+func TestReloadableSwap(t *testing.T) {
+	old := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	next := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	reloadable := NewReloadable(old)
+
+	done := make(chan error)
+	go func() { done <- reloadable.Stream(context.Background(), StreamRequest{}, StreamCallbacks{}) }()
+	<-old.started
+
+	if err := reloadable.Swap(next); err != nil {
+		t.Fatalf("Swap returned error: %v", err)
+	}
+	if reloadable.Unwrap() != next {
+		t.Fatalf("expected new calls to use the swapped-in provider")
+	}
+	if old.closed {
+		t.Fatalf("expected the old provider to stay open while it streams")
+	}
+	close(old.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	if !old.closed {
+		t.Fatalf("expected the old provider to be closed once its stream returned")
+	}
+
+	if err := reloadable.Close(); err != nil || !next.closed {
+		t.Fatalf("expected Close to close the current provider, got %v", err)
+	}
+	late := &blockingProvider{}
+	if err := reloadable.Swap(late); err != nil || !late.closed {
+		t.Fatalf("expected a provider swapped in after Close to be closed, got %v", err)
+	}
+}
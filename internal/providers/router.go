@@ -0,0 +1,66 @@
+// internal/providers/router.go
+package providers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// Router is a ChatProvider that dispatches each call to a provider chosen by the host's
+// type, so local and hosted backends can be mixed in one config.
+type Router struct {
+	fallback ChatProvider
+	byType   map[string]ChatProvider
+}
+
+// NewRouter returns a Router that sends hosts whose type is a key of byType to that
+// provider and every other host to fallback.
+func NewRouter(fallback ChatProvider, byType map[string]ChatProvider) *Router {
+	return &Router{fallback: fallback, byType: byType}
+}
+
+// For returns the provider that handles host.
+func (r *Router) For(host appconfig.Host) ChatProvider {
+	if provider, ok := r.byType[host.Type]; ok {
+		return provider
+	}
+	return r.fallback
+}
+
+// LoadedModels delegates to the host's provider.
+func (r *Router) LoadedModels(ctx context.Context, host appconfig.Host) ([]string, error) {
+	return r.For(host).LoadedModels(ctx, host)
+}
+
+// EnsureModelReady delegates to the host's provider.
+func (r *Router) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
+	return r.For(host).EnsureModelReady(ctx, host, model)
+}
+
+// Stream delegates to the request host's provider.
+func (r *Router) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	return r.For(req.Host).Stream(ctx, req, callbacks)
+}
+
+// Close closes every routed provider.
+func (r *Router) Close() error {
+	errs := []error{r.fallback.Close()}
+	for _, provider := range r.byType {
+		errs = append(errs, provider.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// ModelCapabilities delegates to the host's provider, reporting unknown capabilities when
+// it cannot tell.
+func (r *Router) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (Capabilities, error) {
+	return ReportModelCapabilities(ctx, r.For(host), host, model)
+}
+
+// HostLoad delegates to the host's provider, reporting an idle host when it cannot tell.
+func (r *Router) HostLoad(ctx context.Context, host appconfig.Host) (HostLoad, error) {
+	load, _, err := ReportHostLoad(ctx, r.For(host), host)
+	return load, err
+}
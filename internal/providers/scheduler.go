@@ -0,0 +1,185 @@
+// internal/providers/scheduler.go
+package providers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// HostLimits returns how many streams may run at once on host and how many more may
+// wait for a slot. Zero in-flight means no limit; zero queued means an unbounded queue.
+type HostLimits func(host appconfig.Host) (inFlight, queued int)
+
+// Scheduler is a ChatProvider decorator that limits how many streams run at once on each
+// host, so pipeline stages and multimodel columns sharing a host take turns instead of
+// colliding. Requests over the limit wait in first-come, first-served order; once the
+// queue is full they fail with ErrHostBusy. The wait is reported as QueueWait.
+type Scheduler struct {
+	wrapped ChatProvider
+
+	mu     sync.Mutex
+	limits HostLimits
+	hosts  map[string]*hostQueue
+}
+
+// hostQueue tracks the streams running on one host and those waiting for a slot.
+type hostQueue struct {
+	// host is the entry the queue's limits were last read for.
+	host    appconfig.Host
+	active  int
+	waiting []chan struct{}
+}
+
+// NewScheduler wraps provider, limiting each host as limits says.
+func NewScheduler(wrapped ChatProvider, limits HostLimits) *Scheduler {
+	return &Scheduler{wrapped: wrapped, limits: limits, hosts: make(map[string]*hostQueue)}
+}
+
+// Unwrap returns the wrapped provider.
+func (s *Scheduler) Unwrap() ChatProvider {
+	return s.wrapped
+}
+
+// SetLimits replaces the limits, e.g. after the config is reloaded. Streams keep the slots
+// they hold, so a lowered limit takes effect as they finish, and a raised one starts
+// waiting requests straight away, in the order they arrived.
+func (s *Scheduler) SetLimits(limits HostLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+	for _, q := range s.hosts {
+		inFlight, _ := limits(q.host)
+		for len(q.waiting) > 0 && (inFlight <= 0 || q.active < inFlight) {
+			close(q.waiting[0])
+			q.waiting = q.waiting[1:]
+			q.active++
+		}
+	}
+}
+
+// Stream waits for a slot on the request's host, then passes the call through, adding
+// the time spent waiting to the metadata. The wait is also set on the request, so a
+// metrics provider behind the scheduler can record it.
+func (s *Scheduler) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	wait, release, err := s.acquire(ctx, req.Host, req.Model)
+	if err != nil {
+		return err
+	}
+	defer release()
+	req.QueueWait = wait
+	onComplete := callbacks.OnComplete
+	callbacks.OnComplete = func(meta StreamMetadata) error {
+		meta.QueueWait = wait
+		if onComplete != nil {
+			return onComplete(meta)
+		}
+		return nil
+	}
+	return s.wrapped.Stream(ctx, req, callbacks)
+}
+
+// acquire takes a slot on host, waiting in its queue when every slot is taken. It
+// returns how long it waited and the function that gives the slot back.
+func (s *Scheduler) acquire(ctx context.Context, host appconfig.Host, model string) (time.Duration, func(), error) {
+	s.mu.Lock()
+	inFlight, queued := s.limits(host)
+	if inFlight <= 0 {
+		s.mu.Unlock()
+		return 0, func() {}, nil
+	}
+	key := schedulerKey(host)
+	q := s.hosts[key]
+	if q == nil {
+		q = &hostQueue{}
+		s.hosts[key] = q
+	}
+	q.host = host
+	release := func() { s.release(q) }
+	if q.active < inFlight && len(q.waiting) == 0 {
+		q.active++
+		s.mu.Unlock()
+		return 0, release, nil
+	}
+	if queued > 0 && len(q.waiting) >= queued {
+		s.mu.Unlock()
+		return 0, nil, &Error{Kind: ErrHostBusy, Op: "queue", Host: host.Name, Model: model,
+			Err: fmt.Errorf("%d running and %d waiting", q.active, len(q.waiting))}
+	}
+	turn := make(chan struct{})
+	q.waiting = append(q.waiting, turn)
+	s.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-turn:
+		return time.Since(start), release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		index := slices.Index(q.waiting, turn)
+		if index >= 0 {
+			q.waiting = slices.Delete(q.waiting, index, index+1)
+		}
+		s.mu.Unlock()
+		if index < 0 {
+			// The slot was handed over as ctx ended; pass it on.
+			release()
+		}
+		return 0, nil, ctx.Err()
+	}
+}
+
+// release hands the slot to the next waiting request, or frees it when the host is over
+// a limit lowered since the slot was taken.
+func (s *Scheduler) release(q *hostQueue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inFlight, _ := s.limits(q.host)
+	if len(q.waiting) > 0 && (inFlight <= 0 || q.active <= inFlight) {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		close(next)
+		return
+	}
+	q.active--
+}
+
+// schedulerKey identifies the server behind host, so two host entries for the same URL
+// share one queue.
+func schedulerKey(host appconfig.Host) string {
+	if url := strings.TrimRight(strings.TrimSpace(host.URL), "/"); url != "" {
+		return url
+	}
+	return host.Name
+}
+
+// LoadedModels passes the call through to the wrapped provider.
+func (s *Scheduler) LoadedModels(ctx context.Context, host appconfig.Host) ([]string, error) {
+	return s.wrapped.LoadedModels(ctx, host)
+}
+
+// EnsureModelReady passes the call through to the wrapped provider.
+func (s *Scheduler) EnsureModelReady(ctx context.Context, host appconfig.Host, model string) error {
+	return s.wrapped.EnsureModelReady(ctx, host, model)
+}
+
+// HostLoad passes the call through to the wrapped provider, if it can report load.
+func (s *Scheduler) HostLoad(ctx context.Context, host appconfig.Host) (HostLoad, error) {
+	load, _, err := ReportHostLoad(ctx, s.wrapped, host)
+	return load, err
+}
+
+// ModelCapabilities passes the call through to the wrapped provider, if it can report them.
+func (s *Scheduler) ModelCapabilities(ctx context.Context, host appconfig.Host, model string) (Capabilities, error) {
+	return ReportModelCapabilities(ctx, s.wrapped, host, model)
+}
+
+// Close passes the call through to the wrapped provider.
+func (s *Scheduler) Close() error {
+	return s.wrapped.Close()
+}
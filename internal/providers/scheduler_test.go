@@ -0,0 +1,214 @@
+// internal/providers/scheduler_test.go
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// gatedProvider reports each stream's model on started and streams until release has a value.
+type gatedProvider struct {
+	started chan string
+	release chan struct{}
+}
+
+func (g *gatedProvider) Stream(ctx context.Context, req StreamRequest, callbacks StreamCallbacks) error {
+	g.started <- req.Model
+	<-g.release
+	if callbacks.OnComplete != nil {
+		return callbacks.OnComplete(StreamMetadata{Model: req.Model})
+	}
+	return nil
+}
+
+func (g *gatedProvider) LoadedModels(context.Context, appconfig.Host) ([]string, error) {
+	return nil, nil
+}
+
+func (g *gatedProvider) EnsureModelReady(context.Context, appconfig.Host, string) error {
+	return nil
+}
+
+func (g *gatedProvider) Close() error { return nil }
+
+// fixedLimits returns limits that apply the same in-flight and queue sizes to every host.
+func fixedLimits(inFlight, queued int) HostLimits {
+	return func(appconfig.Host) (int, int) { return inFlight, queued }
+}
+
+// TestSchedulerQueuesInOrder verifies requests over the in-flight limit wait and then run
+// in arrival order, and that the wait is reported as QueueWait.
+func TestSchedulerQueuesInOrder(t *testing.T) {
+	gated := &gatedProvider{started: make(chan string, 3), release: make(chan struct{})}
+	scheduler := NewScheduler(gated, fixedLimits(1, 0))
+	host := appconfig.Host{Name: "h", URL: "http://h:11434"}
+
+	waits := make(chan time.Duration, 3)
+	stream := func(model string) {
+		_ = scheduler.Stream(context.Background(), StreamRequest{Host: host, Model: model}, StreamCallbacks{
+			OnComplete: func(meta StreamMetadata) error {
+				waits <- meta.QueueWait
+				return nil
+			},
+		})
+	}
+	go stream("first")
+	if got := <-gated.started; got != "first" {
+		t.Fatalf("expected first to start, got %s", got)
+	}
+	go stream("second")
+	waitForQueue(t, scheduler, host, 1)
+	go stream("third")
+	waitForQueue(t, scheduler, host, 2)
+
+	select {
+	case model := <-gated.started:
+		t.Fatalf("expected %s to wait for a slot", model)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	for _, want := range []string{"second", "third"} {
+		gated.release <- struct{}{}
+		if got := <-gated.started; got != want {
+			t.Fatalf("expected %s to start next, got %s", want, got)
+		}
+	}
+	gated.release <- struct{}{}
+
+	if wait := <-waits; wait != 0 {
+		t.Fatalf("expected no queue wait for the first request, got %v", wait)
+	}
+	for i := 0; i < 2; i++ {
+		if wait := <-waits; wait <= 0 {
+			t.Fatalf("expected a queued request to report its wait, got %v", wait)
+		}
+	}
+}
+
+// TestSchedulerRejectsWhenQueueFull verifies a request is refused with ErrHostBusy once the
+// host's queue is full, while another host is unaffected.
+func TestSchedulerRejectsWhenQueueFull(t *testing.T) {
+	gated := &gatedProvider{started: make(chan string, 3), release: make(chan struct{}, 3)}
+	scheduler := NewScheduler(gated, fixedLimits(1, 1))
+	busy := appconfig.Host{Name: "busy", URL: "http://busy:11434"}
+
+	go scheduler.Stream(context.Background(), StreamRequest{Host: busy, Model: "running"}, StreamCallbacks{})
+	<-gated.started
+	go scheduler.Stream(context.Background(), StreamRequest{Host: busy, Model: "queued"}, StreamCallbacks{})
+	waitForQueue(t, scheduler, busy, 1)
+
+	err := scheduler.Stream(context.Background(), StreamRequest{Host: busy, Model: "refused"}, StreamCallbacks{})
+	if !errors.Is(err, ErrHostBusy) {
+		t.Fatalf("expected ErrHostBusy, got %v", err)
+	}
+	if ErrorCategory(err) != "host_busy" {
+		t.Fatalf("expected host_busy category, got %q", ErrorCategory(err))
+	}
+
+	go scheduler.Stream(context.Background(), StreamRequest{Host: appconfig.Host{Name: "idle"}, Model: "other"}, StreamCallbacks{})
+	if got := <-gated.started; got != "other" {
+		t.Fatalf("expected the idle host to start at once, got %s", got)
+	}
+	for i := 0; i < 3; i++ {
+		gated.release <- struct{}{}
+	}
+}
+
+// TestSchedulerCancelWhileQueued verifies a cancelled request leaves the queue without
+// taking a slot, so the next request still runs.
+func TestSchedulerCancelWhileQueued(t *testing.T) {
+	gated := &gatedProvider{started: make(chan string, 2), release: make(chan struct{}, 2)}
+	scheduler := NewScheduler(gated, fixedLimits(1, 0))
+	host := appconfig.Host{Name: "h"}
+
+	go scheduler.Stream(context.Background(), StreamRequest{Host: host, Model: "running"}, StreamCallbacks{})
+	<-gated.started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		cancelled <- scheduler.Stream(ctx, StreamRequest{Host: host, Model: "cancelled"}, StreamCallbacks{})
+	}()
+	waitForQueue(t, scheduler, host, 1)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	waitForQueue(t, scheduler, host, 0)
+
+	gated.release <- struct{}{}
+	done := make(chan error)
+	go func() {
+		done <- scheduler.Stream(context.Background(), StreamRequest{Host: host, Model: "next"}, StreamCallbacks{})
+	}()
+	if got := <-gated.started; got != "next" {
+		t.Fatalf("expected next to start, got %s", got)
+	}
+	gated.release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+}
+
+// waitForQueue waits until want requests are queued for host.
+func waitForQueue(t *testing.T, s *Scheduler, host appconfig.Host, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		q := s.hosts[schedulerKey(host)]
+		n := 0
+		if q != nil {
+			n = len(q.waiting)
+		}
+		s.mu.Unlock()
+		if n == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued requests for %s", want, host.Name)
+}
+
+// TestSchedulerSetLimits verifies a lowered limit lets running streams finish without
+// handing their slots on, and a raised limit starts waiting requests at once.
+func TestSchedulerSetLimits(t *testing.T) {
+	gated := &gatedProvider{started: make(chan string, 4), release: make(chan struct{}, 4)}
+	scheduler := NewScheduler(gated, fixedLimits(2, 0))
+	host := appconfig.Host{Name: "h", URL: "http://h:11434"}
+	stream := func(model string) {
+		_ = scheduler.Stream(context.Background(), StreamRequest{Host: host, Model: model}, StreamCallbacks{})
+	}
+
+	go stream("first")
+	<-gated.started
+	go stream("second")
+	<-gated.started
+	scheduler.SetLimits(fixedLimits(1, 0))
+	go stream("third")
+	waitForQueue(t, scheduler, host, 1)
+
+	gated.release <- struct{}{}
+	select {
+	case model := <-gated.started:
+		t.Fatalf("expected %s to wait while the host is over its lowered limit", model)
+	case <-time.After(20 * time.Millisecond):
+	}
+	gated.release <- struct{}{}
+	if got := <-gated.started; got != "third" {
+		t.Fatalf("expected third to start once under the limit, got %s", got)
+	}
+
+	go stream("fourth")
+	waitForQueue(t, scheduler, host, 1)
+	scheduler.SetLimits(fixedLimits(2, 0))
+	if got := <-gated.started; got != "fourth" {
+		t.Fatalf("expected the raised limit to start fourth, got %s", got)
+	}
+	gated.release <- struct{}{}
+	gated.release <- struct{}{}
+}
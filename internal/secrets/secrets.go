@@ -0,0 +1,175 @@
+// internal/secrets/secrets.go
+
+// Package secrets keeps named secrets, such as API keys, in a file encrypted with
+// AES-256-GCM, so they can be referenced from the config instead of written into it.
+// The key lives in a separate key file or in the AGON_SECRETS_KEY environment variable.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyEnv names the environment variable that holds the key, base64-encoded. It takes
+// precedence over the key file, which suits CI where files are awkward.
+const KeyEnv = "AGON_SECRETS_KEY"
+
+// KeySize is the length of a key in bytes.
+const KeySize = 32
+
+// header starts every secrets file and is authenticated with the contents, so a file
+// from another format or version is rejected rather than misread.
+const header = "agon-secrets:v1:"
+
+// NewKey returns a random key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	return key, nil
+}
+
+// WriteKeyFile stores key at path, readable only by its owner. An existing key file is
+// never replaced, since the secrets encrypted with it would be lost.
+func WriteKeyFile(path string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create key directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create key file: %w", err)
+	}
+	if _, err := file.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("write key file: %w", err)
+	}
+	return file.Close()
+}
+
+// LoadKey returns the key from KeyEnv when it is set, or else from the key file at path.
+func LoadKey(path string) ([]byte, error) {
+	if encoded := strings.TrimSpace(os.Getenv(KeyEnv)); encoded != "" {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", KeyEnv, err)
+		}
+		return key, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	key, err := decodeKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// decodeKey decodes a base64 key and checks its length.
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("key is not valid base64")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key is %d bytes, want %d", len(key), KeySize)
+	}
+	return key, nil
+}
+
+// Read decrypts the secrets file at path with key. A missing file holds no secrets.
+func Read(path string, key []byte) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets file: %w", err)
+	}
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, header) {
+		return nil, fmt.Errorf("%s is not an agon secrets file", path)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, header))
+	if err != nil {
+		return nil, fmt.Errorf("%s is corrupt: %w", path, err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is corrupt: too short", path)
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(header))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: wrong key or tampered file", path)
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Write encrypts values with key and stores them at path, readable only by its owner.
+// The file is replaced atomically, so an interrupted write never loses the old secrets.
+func Write(path string, key []byte, values map[string]string) error {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("encode secrets: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(header))
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create secrets directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".secrets-*")
+	if err != nil {
+		return fmt.Errorf("create secrets file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(header + base64.StdEncoding.EncodeToString(sealed) + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write secrets file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write secrets file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace secrets file: %w", err)
+	}
+	return nil
+}
+
+// newGCM returns AES-256-GCM for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key is %d bytes, want %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
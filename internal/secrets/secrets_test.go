@@ -0,0 +1,86 @@
+// internal/secrets/secrets_test.go
+package secrets
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteRead verifies secrets survive a round trip, the file holds no plaintext, and
+// a wrong key or an edited file is refused.
+func TestWriteRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.enc")
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey returned error: %v", err)
+	}
+	if err := Write(path, key, map[string]string{"brave": "sk-123"}); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-123") || !strings.HasPrefix(string(data), header) {
+		t.Fatalf("unexpected secrets file %q", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected an owner-only secrets file, got %v, %v", info.Mode(), err)
+	}
+
+	values, err := Read(path, key)
+	if err != nil || values["brave"] != "sk-123" {
+		t.Fatalf("unexpected secrets %v, %v", values, err)
+	}
+
+	other, _ := NewKey()
+	if _, err := Read(path, other); err == nil {
+		t.Fatalf("expected a wrong key to be refused")
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), header))
+	sealed[len(sealed)-1] ^= 1
+	tampered := header + base64.StdEncoding.EncodeToString(sealed)
+	if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path, key); err == nil {
+		t.Fatalf("expected a tampered file to be refused")
+	}
+
+	if values, err := Read(filepath.Join(dir, "missing.enc"), key); err != nil || len(values) != 0 {
+		t.Fatalf("expected a missing file to hold no secrets, got %v, %v", values, err)
+	}
+}
+
+// TestLoadKey verifies the key is read from the key file, the environment takes
+// precedence, and a key file is never replaced.
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "secrets.key")
+	key, _ := NewKey()
+	if err := WriteKeyFile(path, key); err != nil {
+		t.Fatalf("WriteKeyFile returned error: %v", err)
+	}
+	if err := WriteKeyFile(path, key); err == nil {
+		t.Fatalf("expected an existing key file to be kept")
+	}
+
+	t.Setenv(KeyEnv, "")
+	loaded, err := LoadKey(path)
+	if err != nil || string(loaded) != string(key) {
+		t.Fatalf("unexpected key from file: %v", err)
+	}
+
+	envKey, _ := NewKey()
+	t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString(envKey))
+	if loaded, err := LoadKey(path); err != nil || string(loaded) != string(envKey) {
+		t.Fatalf("expected the environment key to win: %v", err)
+	}
+	t.Setenv(KeyEnv, "c2hvcnQ=")
+	if _, err := LoadKey(path); err == nil {
+		t.Fatalf("expected a short key to be refused")
+	}
+}
@@ -0,0 +1,235 @@
+// internal/sessions/sessions.go
+// Package sessions records chat conversations as JSONL files, one per session, so they
+// can be listed and resumed with their full context.
+package sessions
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// fileSuffix is the extension of session files.
+const fileSuffix = ".jsonl"
+
+// previewLength caps the first-prompt preview shown in session listings.
+const previewLength = 60
+
+// validID matches the session identifiers this package generates, so an ID taken from
+// the command line can never point outside the store.
+var validID = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Entry is one message of a session, one line of its file.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Host    string    `json:"host"`
+	Model   string    `json:"model"`
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	// Params records the settings a generated message was produced with; it is nil for
+	// the user's messages.
+	Params *Params `json:"params,omitempty"`
+}
+
+// Params are the generation settings in effect for one generated message.
+type Params struct {
+	Parameters appconfig.Parameters `json:"parameters"`
+	JSONMode   bool                 `json:"jsonMode,omitempty"`
+	// Template is the system prompt the host was configured with when the message was
+	// generated.
+	Template string `json:"template,omitempty"`
+}
+
+// Summary describes a recorded session for listings and the session browser.
+type Summary struct {
+	ID       string
+	Host     string
+	Model    string
+	Started  time.Time
+	Updated  time.Time
+	Messages int
+	// Preview is the start of the session's first prompt.
+	Preview string
+}
+
+// Store is a directory of session files.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir. The directory is created when the first
+// message is recorded.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Session appends the messages of one conversation to its file. A nil *Session records
+// nothing, so callers can hold one unconditionally.
+type Session struct {
+	ID    string
+	Host  string
+	Model string
+	path  string
+}
+
+// Create starts a new session for host and model. Nothing is written until the first
+// message is appended.
+func (s *Store) Create(host, model string) *Session {
+	id := newSessionID(time.Now())
+	return &Session{ID: id, Host: host, Model: model, path: s.path(id)}
+}
+
+// Open loads a recorded session so it can be resumed. New messages are appended to the
+// same file.
+func (s *Store) Open(id string) (*Session, []Entry, error) {
+	if !validID.MatchString(id) {
+		return nil, nil, fmt.Errorf("invalid session id %q", id)
+	}
+	entries, err := readEntries(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("session %s not found in %s", id, s.dir)
+		}
+		return nil, nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil, fmt.Errorf("session %s has no messages", id)
+	}
+	last := entries[len(entries)-1]
+	return &Session{ID: id, Host: last.Host, Model: last.Model, path: s.path(id)}, entries, nil
+}
+
+// List summarizes every recorded session, most recently updated first. A missing
+// directory means no sessions have been recorded yet.
+func (s *Store) List() ([]Summary, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var summaries []Summary
+	for _, path := range files {
+		entries, err := readEntries(path)
+		if err != nil || len(entries) == 0 {
+			continue
+		}
+		summaries = append(summaries, summarize(strings.TrimSuffix(filepath.Base(path), fileSuffix), entries))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Updated.After(summaries[j].Updated)
+	})
+	return summaries, nil
+}
+
+// Append records a message. The host and model may change mid-session, for example
+// when a resumed chat is pointed at another model, so each entry carries its own.
+func (s *Session) Append(role, content string) error {
+	return s.write(Entry{Role: role, Content: content})
+}
+
+// AppendGenerated records a generated message with the settings it was produced with.
+func (s *Session) AppendGenerated(role, content string, params Params) error {
+	return s.write(Entry{Role: role, Content: content, Params: &params})
+}
+
+// write stamps entry with the time and the session's host and model and appends it.
+func (s *Session) write(entry Entry) error {
+	if s == nil {
+		return nil
+	}
+	entry.Time, entry.Host, entry.Model = time.Now(), s.Host, s.Model
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to marshal session entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("unable to create session directory: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open session %s: %w", s.ID, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write session %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// path returns the file a session is stored in.
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileSuffix)
+}
+
+// readEntries reads a session file, skipping lines that cannot be parsed such as one
+// cut short by an interrupted write.
+func readEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read session %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// summarize builds a session's summary from its entries.
+func summarize(id string, entries []Entry) Summary {
+	last := entries[len(entries)-1]
+	summary := Summary{
+		ID:       id,
+		Host:     last.Host,
+		Model:    last.Model,
+		Started:  entries[0].Time,
+		Updated:  last.Time,
+		Messages: len(entries),
+	}
+	for _, entry := range entries {
+		if entry.Role == "user" {
+			summary.Preview = preview(entry.Content)
+			break
+		}
+	}
+	return summary
+}
+
+// preview flattens a prompt to a single line and shortens it for listings.
+func preview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > previewLength {
+		return string(runes[:previewLength-1]) + "…"
+	}
+	return text
+}
+
+// newSessionID builds a sortable session identifier from the start time plus a random suffix.
+func newSessionID(started time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return started.UTC().Format("20060102T150405Z")
+	}
+	return started.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,97 @@
+// internal/sessions/sessions_test.go
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestSessionRoundTrip verifies that appended messages are listed and reopened with
+// their context, and that resumed sessions keep appending to the same file.
+func TestSessionRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sessions")
+	store := NewStore(dir)
+
+	if summaries, err := store.List(); err != nil || len(summaries) != 0 {
+		t.Fatalf("empty store listed %v, %v", summaries, err)
+	}
+
+	session := store.Create("gpu-box", "llama3")
+	if err := session.Append("user", "What is\nthe capital of France?"); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if err := session.Append("assistant", "Paris."); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	summaries, err := store.List()
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("List = %v, %v", summaries, err)
+	}
+	if got := summaries[0]; got.ID != session.ID || got.Messages != 2 || got.Preview != "What is the capital of France?" || got.Model != "llama3" {
+		t.Fatalf("unexpected summary: %+v", got)
+	}
+
+	resumed, entries, err := store.Open(session.ID)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if resumed.Host != "gpu-box" || len(entries) != 2 || entries[1].Content != "Paris." {
+		t.Fatalf("resumed %+v with %+v", resumed, entries)
+	}
+	resumed.Model = "qwen3"
+	if err := resumed.Append("user", "And Spain?"); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if _, entries, _ = store.Open(session.ID); len(entries) != 3 || entries[2].Model != "qwen3" {
+		t.Fatalf("resumed session did not append: %+v", entries)
+	}
+
+	if _, _, err := store.Open("../etc/passwd"); err == nil {
+		t.Fatalf("expected an invalid id to be rejected")
+	}
+	if _, _, err := store.Open("missing"); err == nil {
+		t.Fatalf("expected an error for an unknown session")
+	}
+
+	var none *Session
+	if err := none.Append("user", "ignored"); err != nil {
+		t.Fatalf("nil session should record nothing: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("expected one session file, found %d", len(files))
+	}
+}
+
+// TestAppendGeneratedParams verifies generated messages are recorded with their settings
+// and template, while other messages carry none.
+func TestAppendGeneratedParams(t *testing.T) {
+	store := NewStore(t.TempDir())
+	session := store.Create("gpu-box", "llama3")
+	temperature := 0.2
+	params := Params{Parameters: appconfig.Parameters{Temperature: &temperature}, JSONMode: true, Template: "Answer in JSON."}
+	if err := session.Append("user", "Capital of France?"); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if err := session.AppendGenerated("assistant", `{"capital":"Paris"}`, params); err != nil {
+		t.Fatalf("AppendGenerated returned error: %v", err)
+	}
+
+	_, entries, err := store.Open(session.ID)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Open = %+v, %v", entries, err)
+	}
+	if entries[0].Params != nil {
+		t.Fatalf("expected no params on the user's message, got %+v", entries[0].Params)
+	}
+	got := entries[1].Params
+	if got == nil || !got.JSONMode || got.Template != "Answer in JSON." || got.Parameters.Temperature == nil || *got.Parameters.Temperature != 0.2 {
+		t.Fatalf("unexpected recorded params %+v", got)
+	}
+	if entries[1].Host != "gpu-box" || entries[1].Model != "llama3" {
+		t.Fatalf("expected the entry stamped with the session's host and model, got %+v", entries[1])
+	}
+}
@@ -0,0 +1,309 @@
+// internal/tokencount/tokencount.go
+
+// Package tokencount estimates how many tokens a model's tokenizer turns text into. It
+// has no vocabularies; instead it splits text the way tokenizers pre-tokenize it and
+// prices each piece by the habits of the tokenizer family, which lands far closer to real
+// counts than counting words, especially for code, numbers and non-Latin scripts.
+package tokencount
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/mwiater/agon/internal/modelname"
+)
+
+// Family names a kind of tokenizer.
+type Family string
+
+const (
+	// BPE is byte-level BPE in the style of tiktoken: GPT, Claude, Llama 3, Qwen and
+	// DeepSeek models. Common words are one token and digits group in threes.
+	BPE Family = "bpe"
+	// Llama is the SentencePiece tokenizer of Llama 2 and the models built like it,
+	// such as Mistral and Gemma. Words split more often and every digit is a token.
+	Llama Family = "llama"
+)
+
+// messageOverhead is the tokens a chat template adds around each message for its role
+// and separators.
+const messageOverhead = 4
+
+// modelFamilies maps fragments of normalized model names to their tokenizer family. The
+// first match wins, so versioned names come before the bare family name.
+var modelFamilies = []struct {
+	fragment string
+	family   Family
+}{
+	{"llama3", BPE},
+	{"llama-3", BPE},
+	{"llama4", BPE},
+	{"llama-4", BPE},
+	{"codellama", Llama},
+	{"tinyllama", Llama},
+	{"llama", Llama},
+	{"mistral", Llama},
+	{"mixtral", Llama},
+	{"gemma", Llama},
+	{"phi3", Llama},
+	{"phi-3", Llama},
+	{"vicuna", Llama},
+}
+
+// Tokenizer estimates token counts for one tokenizer family.
+type Tokenizer struct {
+	family Family
+}
+
+// New returns the tokenizer for family.
+func New(family Family) Tokenizer {
+	return Tokenizer{family: family}
+}
+
+// ParseFamily validates a family name from the config.
+func ParseFamily(name string) (Family, error) {
+	switch family := Family(strings.ToLower(strings.TrimSpace(name))); family {
+	case BPE, Llama:
+		return family, nil
+	default:
+		return "", fmt.Errorf("unknown tokenizer %q: use %q or %q", name, BPE, Llama)
+	}
+}
+
+// ForModel returns the tokenizer for model. overrides maps model names, or prefixes of
+// them, to a family and takes precedence; the longest matching prefix wins. Otherwise the
+// family is guessed from the model's name, falling back to BPE.
+func ForModel(model string, overrides map[string]string) Tokenizer {
+	name := modelname.Normalize(model)
+	best := ""
+	var chosen Family
+	for prefix, familyName := range overrides {
+		normalized := modelname.Normalize(prefix)
+		if !strings.HasPrefix(name, normalized) || len(normalized) <= len(best) {
+			continue
+		}
+		if family, err := ParseFamily(familyName); err == nil {
+			best, chosen = normalized, family
+		}
+	}
+	if chosen != "" {
+		return New(chosen)
+	}
+	for _, entry := range modelFamilies {
+		if strings.Contains(name, entry.fragment) {
+			return New(entry.family)
+		}
+	}
+	return New(BPE)
+}
+
+// Family returns the tokenizer's family.
+func (t Tokenizer) Family() Family {
+	if t.family == "" {
+		return BPE
+	}
+	return t.family
+}
+
+// Count estimates the tokens in text.
+func (t Tokenizer) Count(text string) int {
+	total := 0
+	for _, p := range split(text) {
+		total += t.cost(text[p.start:p.end], p.kind)
+	}
+	return total
+}
+
+// CountMessages estimates the tokens a conversation with these message contents takes,
+// including what the chat template adds around each message.
+func (t Tokenizer) CountMessages(contents []string) int {
+	total := 0
+	for _, content := range contents {
+		total += t.Count(content) + messageOverhead
+	}
+	return total
+}
+
+// Tail returns the end of text that fits in limit tokens, cut at a piece boundary, and
+// whether anything was cut. The kept text is not re-spaced.
+func (t Tokenizer) Tail(text string, limit int) (string, bool) {
+	pieces := split(text)
+	total := 0
+	for i := len(pieces) - 1; i >= 0; i-- {
+		total += t.cost(text[pieces[i].start:pieces[i].end], pieces[i].kind)
+		if total > limit {
+			if i == len(pieces)-1 {
+				return "", true
+			}
+			return strings.TrimLeftFunc(text[pieces[i+1].start:], unicode.IsSpace), true
+		}
+	}
+	return text, false
+}
+
+// pieceKind classifies a pre-tokenized piece of text.
+type pieceKind int
+
+const (
+	pieceWord pieceKind = iota
+	pieceNumber
+	pieceContraction
+	pieceSymbols
+	pieceSpace
+)
+
+// piece is a run of text a tokenizer handles as a unit before splitting it further.
+type piece struct {
+	start, end int
+	kind       pieceKind
+}
+
+// split pre-tokenizes text much as tiktoken's pattern does: runs of letters, runs of
+// digits, English contractions, runs of other symbols, and runs of whitespace.
+func split(text string) []piece {
+	var pieces []piece
+	runes := []rune(text)
+	offsets := make([]int, len(runes)+1)
+	for i, offset := 0, 0; i < len(runes); i++ {
+		offsets[i] = offset
+		offset += len(string(runes[i]))
+		offsets[i+1] = offset
+	}
+	for i := 0; i < len(runes); {
+		start := i
+		var kind pieceKind
+		switch r := runes[i]; {
+		case isLetter(r):
+			kind = pieceWord
+			for i < len(runes) && isLetter(runes[i]) {
+				i++
+			}
+		case unicode.IsDigit(r):
+			kind = pieceNumber
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+		case unicode.IsSpace(r):
+			kind = pieceSpace
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+		case (r == '\'' || r == '’') && contractionLength(runes[i+1:]) > 0:
+			kind = pieceContraction
+			i += 1 + contractionLength(runes[i+1:])
+		default:
+			kind = pieceSymbols
+			for i < len(runes) && !isLetter(runes[i]) && !unicode.IsDigit(runes[i]) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+		}
+		pieces = append(pieces, piece{start: offsets[start], end: offsets[i], kind: kind})
+	}
+	return pieces
+}
+
+// contractionLength returns the length of the contraction suffix ('s, 't, 're, 've, 'm,
+// 'll, 'd) that starts rest, or zero.
+func contractionLength(rest []rune) int {
+	for _, suffix := range []string{"re", "ve", "ll", "s", "t", "m", "d"} {
+		n := len(suffix)
+		if len(rest) < n || strings.ToLower(string(rest[:n])) != suffix {
+			continue
+		}
+		if len(rest) > n && isLetter(rest[n]) {
+			continue
+		}
+		return n
+	}
+	return 0
+}
+
+// isLetter reports whether r belongs in a word, counting combining marks.
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+// isIdeograph reports whether r is from a script written without spaces, where
+// tokenizers spend about a token per character.
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai)
+}
+
+// cost estimates the tokens in one piece of the given kind.
+func (t Tokenizer) cost(text string, kind pieceKind) int {
+	llama := t.Family() == Llama
+	switch kind {
+	case pieceWord:
+		units, ideographs := 0, 0
+		for _, r := range text {
+			switch {
+			case isIdeograph(r):
+				ideographs++
+			case r < unicode.MaxASCII:
+				units++
+			default:
+				// Accented and non-Latin letters take more than one byte and split
+				// about twice as often.
+				units += 2
+			}
+		}
+		if llama {
+			return wordCost(units, 5, 4) + 2*ideographs
+		}
+		return wordCost(units, 7, 5) + ideographs
+	case pieceNumber:
+		digits := len([]rune(text))
+		if llama {
+			return digits
+		}
+		return ceilDiv(digits, 3)
+	case pieceContraction:
+		return 1
+	case pieceSymbols:
+		ascii, other := 0, 0
+		for _, r := range text {
+			if r < unicode.MaxASCII {
+				ascii++
+			} else {
+				other++
+			}
+		}
+		if llama {
+			return ascii + 3*other
+		}
+		return ceilDiv(ascii, 2) + 2*other
+	case pieceSpace:
+		// A single space is folded into the next word.
+		if text == " " {
+			return 0
+		}
+		newlines := strings.Count(text, "\n")
+		if llama {
+			if len(text) > newlines+1 {
+				return newlines + 1
+			}
+			return newlines
+		}
+		return 1
+	}
+	return 0
+}
+
+// wordCost prices a word of length units: words up to whole units long are one token,
+// longer ones about one token per perToken units.
+func wordCost(units, whole, perToken int) int {
+	switch {
+	case units == 0:
+		return 0
+	case units <= whole:
+		return 1
+	default:
+		return ceilDiv(units, perToken)
+	}
+}
+
+// ceilDiv divides a by b, rounding up.
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,116 @@
+// internal/tokencount/tokencount_test.go
+package tokencount
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCountBPE verifies the BPE estimates for prose, numbers, code and ideographs.
+func TestCountBPE(t *testing.T) {
+	tokenizer := New(BPE)
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Hello world", 2},
+		{"The tokenizer doesn't split common words.", 9},
+		{"1234567", 3},
+		{"if (x >= 10) { return; }", 10},
+		{"你好世界", 4},
+	}
+	for _, tc := range cases {
+		if got := tokenizer.Count(tc.text); got != tc.want {
+			t.Fatalf("Count(%q) = %d, want %d", tc.text, got, tc.want)
+		}
+	}
+}
+
+// TestCountLlamaSplitsMore verifies the Llama family prices digits, long words and
+// symbols above BPE, as SentencePiece vocabularies do.
+func TestCountLlamaSplitsMore(t *testing.T) {
+	bpe, llama := New(BPE), New(Llama)
+	if got := llama.Count("1234567"); got != 7 {
+		t.Fatalf("expected one token per digit, got %d", got)
+	}
+	text := "Internationalization requires careful handling: 2024-01-15T10:30:00Z."
+	if llama.Count(text) <= bpe.Count(text) {
+		t.Fatalf("expected llama (%d) to exceed bpe (%d)", llama.Count(text), bpe.Count(text))
+	}
+}
+
+// TestCountBeatsWordCount verifies code is priced well above its whitespace-separated
+// word count, which the handoff limit used to rely on.
+func TestCountBeatsWordCount(t *testing.T) {
+	code := `func main() { fmt.Println(strings.Repeat("=", 80)) }`
+	words := len(strings.Fields(code))
+	if got := New(BPE).Count(code); got <= 2*words {
+		t.Fatalf("expected well over %d tokens for code, got %d", words, got)
+	}
+}
+
+// TestForModel verifies families are recognized from model names and that config
+// overrides win, longest prefix first.
+func TestForModel(t *testing.T) {
+	cases := []struct {
+		model     string
+		overrides map[string]string
+		want      Family
+	}{
+		{"llama3.2:3b", nil, BPE},
+		{"hf.co/bartowski/Meta-Llama-3-8B-GGUF:Q4_K_M", nil, BPE},
+		{"llama2:7b", nil, Llama},
+		{"mistral:7b-instruct", nil, Llama},
+		{"gemma2:2b", nil, Llama},
+		{"qwen2.5:7b", nil, BPE},
+		{"claude-sonnet-4-5", nil, BPE},
+		{"my-finetune:latest", nil, BPE},
+		{"my-finetune:latest", map[string]string{"my-": "llama"}, Llama},
+		{"my-finetune:latest", map[string]string{"my-": "llama", "my-finetune": "bpe"}, BPE},
+		{"llama2:7b", map[string]string{"llama2": "BPE"}, BPE},
+	}
+	for _, tc := range cases {
+		if got := ForModel(tc.model, tc.overrides).Family(); got != tc.want {
+			t.Fatalf("ForModel(%q, %v) = %s, want %s", tc.model, tc.overrides, got, tc.want)
+		}
+	}
+}
+
+// TestParseFamily verifies unknown families are rejected.
+func TestParseFamily(t *testing.T) {
+	if family, err := ParseFamily(" Llama "); err != nil || family != Llama {
+		t.Fatalf("ParseFamily returned %q, %v", family, err)
+	}
+	if _, err := ParseFamily("wordpiece"); err == nil {
+		t.Fatalf("expected an error for an unknown family")
+	}
+}
+
+// TestTail verifies Tail keeps the end of the text within the limit, without re-spacing it.
+func TestTail(t *testing.T) {
+	tokenizer := New(BPE)
+	text := "first line\nsecond line\n\tthird line"
+	if got, cut := tokenizer.Tail(text, 100); got != text || cut {
+		t.Fatalf("expected text under the limit to be kept, got %q (cut %t)", got, cut)
+	}
+	got, cut := tokenizer.Tail(text, 5)
+	if !cut {
+		t.Fatalf("expected the text to be cut")
+	}
+	if got != "second line\n\tthird line" {
+		t.Fatalf("unexpected tail %q", got)
+	}
+	if n := tokenizer.Count(got); n > 5 {
+		t.Fatalf("expected at most 5 tokens, got %d", n)
+	}
+}
+
+// TestCountMessages verifies each message adds the chat template's overhead.
+func TestCountMessages(t *testing.T) {
+	tokenizer := New(BPE)
+	got := tokenizer.CountMessages([]string{"Hello world", ""})
+	if want := 2 + 2*messageOverhead; got != want {
+		t.Fatalf("CountMessages = %d, want %d", got, want)
+	}
+}
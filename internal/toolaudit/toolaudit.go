@@ -0,0 +1,183 @@
+// internal/toolaudit/toolaudit.go
+// Package toolaudit records every MCP tool call made during chat and pipeline runs as
+// JSONL, one file per day, so tool use can be traced and audited after the fact.
+package toolaudit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileSuffix is the extension of audit files.
+const fileSuffix = ".jsonl"
+
+// Entry is one tool call, one line of an audit file.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Mode is the chat mode that made the call: chat, multimodel or pipeline.
+	Mode  string `json:"mode"`
+	Host  string `json:"host"`
+	Model string `json:"model"`
+	// Turn counts the user messages in the conversation when the model called the tool.
+	Turn int    `json:"turn"`
+	Tool string `json:"tool"`
+	// Server names the external MCP server that ran the tool; empty for agon's own.
+	Server     string         `json:"server,omitempty"`
+	Arguments  map[string]any `json:"arguments"`
+	Attempt    int            `json:"attempt,omitempty"`
+	DurationMs float64        `json:"durationMs"`
+	// ResultBytes is the size of the tool output handed back to the model.
+	ResultBytes int    `json:"resultBytes"`
+	Error       string `json:"error,omitempty"`
+	// Approval is ApprovalApproved or ApprovalDenied for tools the user must approve.
+	Approval string `json:"approval,omitempty"`
+}
+
+// Approval outcomes recorded for tools that require the user's approval.
+const (
+	ApprovalApproved = "approved"
+	ApprovalDenied   = "denied"
+)
+
+// Store is a directory of daily audit files.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore returns a store rooted at dir. The directory is created when the first call
+// is recorded.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory the store writes to.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Record appends entry to the file for its day.
+func (s *Store) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to marshal tool call: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create tool audit directory %s: %w", s.dir, err)
+	}
+	path := filepath.Join(s.dir, entry.Time.Format("2006-01-02")+fileSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open tool audit %s: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write tool audit %s: %w", path, err)
+	}
+	return nil
+}
+
+// Filter selects audit entries; zero fields match everything.
+type Filter struct {
+	// Tool, Model, Host and Mode match case-insensitively; Tool also matches a substring,
+	// so "weather" finds "current_weather".
+	Tool  string
+	Model string
+	Host  string
+	Mode  string
+	// ErrorsOnly keeps failed calls.
+	ErrorsOnly bool
+	// Since keeps calls at or after the time.
+	Since time.Time
+}
+
+// Match reports whether entry passes the filter.
+func (f Filter) Match(entry Entry) bool {
+	if f.Tool != "" && !strings.Contains(strings.ToLower(entry.Tool), strings.ToLower(f.Tool)) {
+		return false
+	}
+	if f.Model != "" && !strings.EqualFold(entry.Model, f.Model) {
+		return false
+	}
+	if f.Host != "" && !strings.EqualFold(entry.Host, f.Host) {
+		return false
+	}
+	if f.Mode != "" && !strings.EqualFold(entry.Mode, f.Mode) {
+		return false
+	}
+	if f.ErrorsOnly && entry.Error == "" {
+		return false
+	}
+	return f.Since.IsZero() || !entry.Time.Before(f.Since)
+}
+
+// Read returns the entries matching filter, oldest first. Days before filter.Since are not
+// read, and lines that cannot be parsed, such as one cut short by an interrupted write,
+// are skipped. A missing directory yields no entries.
+func (s *Store) Read(filter Filter) ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tool audit directory %s: %w", s.dir, err)
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		if day, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(name, fileSuffix), time.Local); err == nil && !filter.Since.IsZero() && day.AddDate(0, 0, 1).Before(filter.Since) {
+			continue
+		}
+		read, err := readFile(filepath.Join(s.dir, name), filter)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, read...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// readFile reads the matching entries of one audit file.
+func readFile(path string, filter Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tool audit %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read tool audit %s: %w", path, err)
+	}
+	return entries, nil
+}
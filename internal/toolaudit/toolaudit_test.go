@@ -0,0 +1,63 @@
+// internal/toolaudit/toolaudit_test.go
+package toolaudit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRecordAndRead verifies entries land in daily files, come back oldest first, skip
+// malformed lines, and can be filtered.
+func TestRecordAndRead(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "toolCalls")
+	store := NewStore(dir)
+	if entries, err := store.Read(Filter{}); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries before recording, got %v, %v", entries, err)
+	}
+
+	day := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.Local)
+	recorded := []Entry{
+		{Time: day.AddDate(0, 0, -2), Mode: "chat", Host: "gpu", Model: "llama", Turn: 1, Tool: "current_weather", Arguments: map[string]any{"location": "Paris"}},
+		{Time: day, Mode: "pipeline", Host: "gpu", Model: "qwen", Turn: 2, Tool: "current_time", Error: "timeout"},
+		{Time: day.Add(time.Minute), Mode: "chat", Host: "cpu", Model: "llama", Turn: 3, Tool: "files__read_file", Server: "files"},
+	}
+	for i := len(recorded) - 1; i >= 0; i-- {
+		if err := store.Record(recorded[i]); err != nil {
+			t.Fatalf("Record returned error: %v", err)
+		}
+	}
+	file, err := os.OpenFile(filepath.Join(dir, "2026-10-16.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("expected a file for the day: %v", err)
+	}
+	file.WriteString(`{"time": "2026-10-16T1` + "\n")
+	file.Close()
+
+	entries, err := store.Read(Filter{})
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Tool != "current_weather" || entries[2].Server != "files" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if entries[0].Arguments["location"] != "Paris" {
+		t.Fatalf("arguments did not round-trip: %+v", entries[0].Arguments)
+	}
+
+	for filter, want := range map[*Filter]int{
+		{Tool: "WEATHER"}:            1,
+		{Model: "llama"}:             2,
+		{Host: "cpu"}:                1,
+		{Mode: "pipeline"}:           1,
+		{ErrorsOnly: true}:           1,
+		{Since: day}:                 2,
+		{Model: "llama", Since: day}: 1,
+	} {
+		entries, err := store.Read(*filter)
+		if err != nil || len(entries) != want {
+			t.Fatalf("Read(%+v) returned %d entries (%v), want %d", *filter, len(entries), err, want)
+		}
+	}
+}
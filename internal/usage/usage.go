@@ -0,0 +1,167 @@
+// internal/usage/usage.go
+// Package usage records opt-in, local-only usage stats (command timings, run counts
+// and token totals) and summarizes them for `agon stats`. Nothing is ever sent anywhere.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+const (
+	// KindCommand marks a CLI command invocation.
+	KindCommand = "command"
+	// KindBenchmark marks a completed benchmark run.
+	KindBenchmark = "benchmark"
+	// KindPipeline marks a completed pipeline run.
+	KindPipeline = "pipeline"
+)
+
+// Event is one line of the usage stats file.
+type Event struct {
+	Time            time.Time `json:"time"`
+	Kind            string    `json:"kind"`
+	Name            string    `json:"name,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Failed          bool      `json:"failed,omitempty"`
+	PromptTokens    int       `json:"promptTokens,omitempty"`
+	OutputTokens    int       `json:"outputTokens,omitempty"`
+}
+
+// Record appends an event to the configured stats file. It does nothing unless
+// usageStats is enabled in the config.
+func Record(cfg appconfig.Config, event Event) error {
+	if !cfg.UsageStats {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to marshal usage event: %w", err)
+	}
+	path := cfg.UsageStatsFile()
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("unable to create usage stats directory %s: %w", dir, err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open usage stats %s: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write usage stats %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load reads every event from a stats file. Lines that cannot be parsed, such as a
+// line cut short by an interrupted write, are skipped.
+func Load(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read usage stats %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read usage stats %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// CommandSummary aggregates the invocations of one command.
+type CommandSummary struct {
+	Name         string
+	Runs         int
+	Failed       int
+	TotalSeconds float64
+	MaxSeconds   float64
+}
+
+// AverageSeconds returns the mean duration of the command's invocations.
+func (c CommandSummary) AverageSeconds() float64 {
+	if c.Runs == 0 {
+		return 0
+	}
+	return c.TotalSeconds / float64(c.Runs)
+}
+
+// Summary aggregates the events recorded since a point in time.
+type Summary struct {
+	Since         time.Time
+	Commands      []CommandSummary
+	BenchmarkRuns int
+	PipelineRuns  int
+	PromptTokens  int
+	OutputTokens  int
+}
+
+// Summarize aggregates the events at or after since; a zero since covers every event.
+// Commands are ordered by total time spent, longest first.
+func Summarize(events []Event, since time.Time) Summary {
+	summary := Summary{Since: since}
+	byName := make(map[string]*CommandSummary)
+	for _, event := range events {
+		if event.Time.Before(since) {
+			continue
+		}
+		summary.PromptTokens += event.PromptTokens
+		summary.OutputTokens += event.OutputTokens
+		switch event.Kind {
+		case KindBenchmark:
+			summary.BenchmarkRuns++
+		case KindPipeline:
+			summary.PipelineRuns++
+		case KindCommand:
+			cmd, ok := byName[event.Name]
+			if !ok {
+				cmd = &CommandSummary{Name: event.Name}
+				byName[event.Name] = cmd
+			}
+			cmd.Runs++
+			if event.Failed {
+				cmd.Failed++
+			}
+			cmd.TotalSeconds += event.DurationSeconds
+			if event.DurationSeconds > cmd.MaxSeconds {
+				cmd.MaxSeconds = event.DurationSeconds
+			}
+		}
+	}
+	for _, cmd := range byName {
+		summary.Commands = append(summary.Commands, *cmd)
+	}
+	sort.Slice(summary.Commands, func(i, j int) bool {
+		if summary.Commands[i].TotalSeconds != summary.Commands[j].TotalSeconds {
+			return summary.Commands[i].TotalSeconds > summary.Commands[j].TotalSeconds
+		}
+		return summary.Commands[i].Name < summary.Commands[j].Name
+	})
+	return summary
+}
+
+// MonthStart returns midnight on the first day of t's month, in t's location.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
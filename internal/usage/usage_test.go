@@ -0,0 +1,72 @@
+// internal/usage/usage_test.go
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// TestRecordAndSummarize verifies that nothing is written unless usage stats are enabled,
+// that events round-trip through the stats file, and that summaries split by month.
+func TestRecordAndSummarize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "usage.jsonl")
+	if err := Record(appconfig.Config{UsageStatsPath: path}, Event{Kind: KindCommand, Name: "agon chat"}); err != nil {
+		t.Fatalf("Record returned error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("usage stats should not be written when disabled")
+	}
+
+	cfg := appconfig.Config{UsageStats: true, UsageStatsPath: path}
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	lastMonth := now.AddDate(0, -1, 0)
+	events := []Event{
+		{Time: lastMonth, Kind: KindBenchmark, PromptTokens: 100, OutputTokens: 1000},
+		{Time: now, Kind: KindBenchmark, PromptTokens: 10, OutputTokens: 200},
+		{Time: now, Kind: KindPipeline, PromptTokens: 5, OutputTokens: 50},
+		{Time: now, Kind: KindCommand, Name: "agon benchmark", DurationSeconds: 30},
+		{Time: now, Kind: KindCommand, Name: "agon benchmark", DurationSeconds: 10, Failed: true},
+		{Time: now, Kind: KindCommand, Name: "agon list models", DurationSeconds: 0.5},
+		{Time: lastMonth, Kind: KindCommand, Name: "agon list models", DurationSeconds: 99},
+	}
+	for _, event := range events {
+		if err := Record(cfg, event); err != nil {
+			t.Fatalf("Record returned error: %v", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open stats: %v", err)
+	}
+	_, _ = file.WriteString(`{"time":"2026-10-16T12:00:00Z","kind":"comm`)
+	file.Close()
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != len(events) {
+		t.Fatalf("loaded %d events, want %d (the truncated line should be skipped)", len(loaded), len(events))
+	}
+
+	monthly := Summarize(loaded, MonthStart(now))
+	if monthly.BenchmarkRuns != 1 || monthly.PipelineRuns != 1 || monthly.PromptTokens != 15 || monthly.OutputTokens != 250 {
+		t.Fatalf("unexpected monthly summary: %+v", monthly)
+	}
+	if len(monthly.Commands) != 2 || monthly.Commands[0].Name != "agon benchmark" {
+		t.Fatalf("commands should be ordered by total time: %+v", monthly.Commands)
+	}
+	bench := monthly.Commands[0]
+	if bench.Runs != 2 || bench.Failed != 1 || bench.AverageSeconds() != 20 || bench.MaxSeconds != 30 {
+		t.Fatalf("unexpected benchmark command summary: %+v", bench)
+	}
+
+	overall := Summarize(loaded, time.Time{})
+	if overall.BenchmarkRuns != 2 || overall.OutputTokens != 1250 || overall.Commands[0].Name != "agon list models" {
+		t.Fatalf("unexpected all-time summary: %+v", overall)
+	}
+}
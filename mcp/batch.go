@@ -0,0 +1,89 @@
+// mcp/batch.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+const (
+	// maxBatchCalls bounds the number of calls in one tools/callBatch request.
+	maxBatchCalls = 32
+	// batchWorkers bounds how many calls of a batch run at once.
+	batchWorkers = 8
+)
+
+// batchCall is one call of a tools/callBatch request. ID keys its result; calls without
+// one are keyed by their position in the batch.
+type batchCall struct {
+	ID string `json:"id"`
+	toolsCallParams
+}
+
+// toolsCallBatchParams are the parameters of a tools/callBatch request.
+type toolsCallBatchParams struct {
+	Calls []batchCall `json:"calls"`
+}
+
+// handleToolsCallBatch runs independent tool calls concurrently and returns their
+// tools/call results keyed by call ID, so a client that needs several tools pays for the
+// slowest call rather than the sum of all of them. A failing tool reports its error in
+// its own result, as tools/call does, without affecting the others; a call whose
+// arguments fail validation gets the -32602 error object tools/call would answer.
+func handleToolsCallBatch(ctx context.Context, raw json.RawMessage) (map[string]any, error) {
+	var params toolsCallBatchParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("Invalid params: %v", err)
+		}
+	}
+	if len(params.Calls) == 0 {
+		return nil, fmt.Errorf("Invalid params: calls must not be empty")
+	}
+	if len(params.Calls) > maxBatchCalls {
+		return nil, fmt.Errorf("Invalid params: %d calls exceeds the limit of %d", len(params.Calls), maxBatchCalls)
+	}
+
+	ids := make([]string, len(params.Calls))
+	seen := make(map[string]bool, len(params.Calls))
+	for i, call := range params.Calls {
+		id := call.ID
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("Invalid params: duplicate call id %q", id)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+
+	results := make([]map[string]any, len(params.Calls))
+	slots := make(chan struct{}, batchWorkers)
+	var wg sync.WaitGroup
+	for i, call := range params.Calls {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, call toolsCallParams) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result, err := callToolResult(ctx, call)
+			var invalid *invalidArgumentsError
+			if errors.As(err, &invalid) {
+				result = map[string]any{"error": invalid.rpcError()}
+			}
+			results[i] = result
+		}(i, call.toolsCallParams)
+	}
+	wg.Wait()
+
+	keyed := make(map[string]any, len(results))
+	for i, result := range results {
+		keyed[ids[i]] = result
+	}
+	return map[string]any{"results": keyed}, nil
+}
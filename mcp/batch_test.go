@@ -0,0 +1,45 @@
+// mcp/batch_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// TestHandleToolsCallBatch verifies that every call of a batch gets its own result keyed
+// by call ID or position, and that malformed batches are rejected.
+func TestHandleToolsCallBatch(t *testing.T) {
+	raw := json.RawMessage(`{"calls":[
+		{"id":"list","name":"available_tools"},
+		{"name":"no_such_tool","arguments":{"x":1}}
+	]}`)
+	result, err := handleToolsCallBatch(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("handleToolsCallBatch returned error: %v", err)
+	}
+	results, ok := result["results"].(map[string]any)
+	if !ok || len(results) != 2 {
+		t.Fatalf("expected two keyed results, got %#v", result)
+	}
+	if _, ok := results["list"]; !ok {
+		t.Fatalf("missing result for call id \"list\": %#v", results)
+	}
+	unknown, ok := results["1"].(map[string]any)
+	if !ok {
+		t.Fatalf("missing positional result for the unnamed call: %#v", results)
+	}
+	parts, _ := unknown["content"].([]any)
+	if len(parts) != 1 || !strings.Contains(parts[0].(tools.ContentPart).Text, "Unknown tool") {
+		t.Fatalf("unexpected result for unknown tool: %#v", unknown)
+	}
+
+	for _, bad := range []string{`{"calls":[]}`, `{"calls":[{"id":"a","name":"x"},{"id":"a","name":"y"}]}`, `[1,2]`} {
+		if _, err := handleToolsCallBatch(context.Background(), json.RawMessage(bad)); err == nil {
+			t.Fatalf("expected %s to be rejected", bad)
+		}
+	}
+}
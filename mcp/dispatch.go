@@ -0,0 +1,152 @@
+// mcp/dispatch.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// requestCancelledCode answers a request the client cancelled with $/cancelRequest.
+const requestCancelledCode = -32800
+
+// dispatcher serves requests concurrently, so a slow tool call does not hold up ping or
+// tools/list, and tracks the requests in flight so the client can cancel them.
+type dispatcher struct {
+	handle func(ctx context.Context, req *jsonrpcRequest) jsonrpcResponse
+
+	writeMu sync.Mutex
+	out     *bufio.Writer
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightRequest
+	wg       sync.WaitGroup
+}
+
+// inFlightRequest is a request being handled.
+type inFlightRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool
+	// silent drops the response, as MCP's notifications/cancelled asks of the receiver.
+	silent bool
+}
+
+// newDispatcher returns a dispatcher that answers requests with handleRequest on out.
+func newDispatcher(out *bufio.Writer) *dispatcher {
+	return &dispatcher{handle: handleRequest, out: out, inFlight: make(map[string]*inFlightRequest)}
+}
+
+// write sends one message. Responses finish out of order, so each is written whole.
+func (d *dispatcher) write(v any) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	return writeMessage(d.out, v)
+}
+
+// dispatch handles one incoming message. Notifications, including the cancellations
+// $/cancelRequest and notifications/cancelled, are handled inline and never answered;
+// requests run on their own goroutine and are answered when they finish.
+func (d *dispatcher) dispatch(req *jsonrpcRequest) {
+	switch req.Method {
+	case "$/cancelRequest", "notifications/cancelled":
+		d.cancel(req)
+		return
+	}
+	if req.isNotification() {
+		return
+	}
+
+	key := requestKey(req.ID)
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &inFlightRequest{cancel: cancel}
+	d.mu.Lock()
+	if _, busy := d.inFlight[key]; busy {
+		d.mu.Unlock()
+		cancel()
+		_ = d.write(makeError(req.ID, -32600, "Invalid Request: id "+key+" is already in flight"))
+		return
+	}
+	d.inFlight[key] = entry
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		resp := d.handle(ctx, req)
+		d.mu.Lock()
+		delete(d.inFlight, key)
+		cancelled, silent := entry.cancelled, entry.silent
+		d.mu.Unlock()
+		cancel()
+
+		if silent {
+			return
+		}
+		if cancelled {
+			resp = makeError(req.ID, requestCancelledCode, "Request cancelled")
+		}
+		_ = d.write(resp)
+	}()
+}
+
+// cancel cancels the in-flight request named by a cancellation notification: params.id
+// for $/cancelRequest, params.requestId for notifications/cancelled. Unknown or finished
+// requests are ignored.
+func (d *dispatcher) cancel(req *jsonrpcRequest) {
+	var params struct {
+		ID        any `json:"id"`
+		RequestID any `json:"requestId"`
+	}
+	if len(req.Params) > 0 && json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+	id, silent := params.ID, false
+	if req.Method == "notifications/cancelled" {
+		id, silent = params.RequestID, true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry, ok := d.inFlight[requestKey(id)]; ok {
+		entry.cancelled = true
+		entry.silent = silent
+		entry.cancel()
+	}
+}
+
+// wait blocks until every request in flight has been answered.
+func (d *dispatcher) wait() {
+	d.wg.Wait()
+}
+
+// requestKey identifies a request ID, so the number 1 and the string "1" stay distinct.
+func requestKey(id any) string {
+	data, err := json.Marshal(id)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// UnmarshalJSON decodes a message and notes whether it carried an id, since a request
+// with "id": null must still be answered while a notification must not.
+func (r *jsonrpcRequest) UnmarshalJSON(data []byte) error {
+	type wire jsonrpcRequest
+	var msg wire
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	_, msg.hasID = fields["id"]
+	*r = jsonrpcRequest(msg)
+	return nil
+}
+
+// isNotification reports whether the message is a notification, which gets no response.
+func (r *jsonrpcRequest) isNotification() bool {
+	return !r.hasID
+}
@@ -0,0 +1,94 @@
+// mcp/dispatch_test.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestDispatcher verifies that requests are answered concurrently, that notifications get
+// no response, and that both cancellation forms stop an in-flight request.
+func TestDispatcher(t *testing.T) {
+	pr, pw := io.Pipe()
+	d := newDispatcher(bufio.NewWriter(pw))
+	d.handle = func(ctx context.Context, req *jsonrpcRequest) jsonrpcResponse {
+		if req.Method == "slow" {
+			<-ctx.Done()
+			return makeResult(req.ID, "finished")
+		}
+		return makeResult(req.ID, req.Method)
+	}
+	frames := make(chan jsonrpcResponse, 8)
+	go readResponses(pr, frames)
+	send := func(msg string) {
+		var req jsonrpcRequest
+		if err := json.Unmarshal([]byte(msg), &req); err != nil {
+			t.Fatalf("decode %s: %v", msg, err)
+		}
+		d.dispatch(&req)
+	}
+	next := func() jsonrpcResponse {
+		select {
+		case resp := <-frames:
+			return resp
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for a response")
+			return jsonrpcResponse{}
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"slow"}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if resp := next(); resp.ID != float64(2) || resp.Result != "ping" {
+		t.Fatalf("expected ping to be answered while the slow request runs, got %+v", resp)
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"slow"}`)
+	if resp := next(); resp.Error == nil || resp.Error.Code != -32600 {
+		t.Fatalf("expected a duplicate in-flight id to be rejected, got %+v", resp)
+	}
+
+	send(`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":1}}`)
+	if resp := next(); resp.ID != float64(1) || resp.Error == nil || resp.Error.Code != requestCancelledCode {
+		t.Fatalf("expected the cancelled request to answer -32800, got %+v", resp)
+	}
+
+	send(`{"jsonrpc":"2.0","id":"a","method":"slow"}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"a"}}`)
+	send(`{"jsonrpc":"2.0","id":null,"method":"tools/list"}`)
+	if resp := next(); resp.ID != nil || resp.Result != "tools/list" {
+		t.Fatalf("expected the request with a null id to be answered, got %+v", resp)
+	}
+	d.wait()
+	pw.Close()
+	if resp, ok := <-frames; ok {
+		t.Fatalf("expected no response for the silently cancelled request, got %+v", resp)
+	}
+}
+
+// readResponses decodes the framed responses written to r until it is closed.
+func readResponses(r io.Reader, frames chan<- jsonrpcResponse) {
+	defer close(frames)
+	br := bufio.NewReader(r)
+	for {
+		var length int
+		if _, err := fmt.Fscanf(br, "Content-Length: %d\r\n\r\n", &length); err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return
+		}
+		var resp jsonrpcResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return
+		}
+		frames <- resp
+	}
+}
@@ -5,7 +5,9 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -18,11 +20,15 @@ import (
 )
 
 var (
-	configPath string
+	configPath  string
+	mockMode    bool
+	fixturesDir string
 )
 
 func init() {
 	flag.StringVar(&configPath, "config", "", "path to the config file")
+	flag.BoolVar(&mockMode, "mock", false, "answer tool calls from fixture files instead of live APIs")
+	flag.StringVar(&fixturesDir, "fixtures", "", "directory of mock fixtures (defaults to the config value)")
 }
 
 // --- Protocol data types ---
@@ -32,11 +38,14 @@ type jsonrpcRequest struct {
 	ID      any             `json:"id,omitempty"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
+
+	hasID bool
 }
 
 type jsonrpcError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
 }
 
 type jsonrpcResponse struct {
@@ -52,7 +61,12 @@ type toolsCallParams struct {
 	Arguments map[string]any `json:"arguments"`
 }
 
-var retryCount = (appconfig.Config{}).MCPRetryAttempts()
+var (
+	retryCount     = (appconfig.Config{}).MCPRetryAttempts()
+	maxFrameBytes  = (appconfig.Config{}).MCPFrameLimit()
+	maxArgumentLen = (appconfig.Config{}).MCPArgumentLimit()
+	chunkBytes     = (appconfig.Config{}).MCPChunkSize()
+)
 
 // --- Framing Helpers ---
 
@@ -70,11 +84,32 @@ func writeMessage(w *bufio.Writer, v any) error {
 	return w.Flush()
 }
 
-func readMessage(r *bufio.Reader) (*jsonrpcRequest, error) {
+// maxHeaderLineBytes caps one frame header line, so a line that never ends cannot be
+// buffered without bound ahead of the frame limit.
+const maxHeaderLineBytes = 4096
+
+// readHeaderLine reads one header line, newline included, failing once it grows past
+// limit bytes. The stream cannot be resynchronized after that, so the error is fatal.
+func readHeaderLine(r *bufio.Reader, limit int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > limit {
+			return "", fmt.Errorf("header line exceeds limit of %d bytes", limit)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return string(line), err
+	}
+}
+
+func readMessage(r *bufio.Reader, limit int) (*jsonrpcRequest, error) {
 	// Read headers until blank line
 	headers := map[string]string{}
 	for {
-		line, err := r.ReadString('\n')
+		line, err := readHeaderLine(r, maxHeaderLineBytes)
 		if err != nil {
 			if err == io.EOF {
 				return nil, io.EOF
@@ -102,8 +137,15 @@ func readMessage(r *bufio.Reader) (*jsonrpcRequest, error) {
 		return nil, fmt.Errorf("missing Content-Length")
 	}
 	var length int
-	if _, err := fmt.Sscanf(clStr, "%d", &length); err != nil {
-		return nil, fmt.Errorf("invalid Content-Length: %v", err)
+	if _, err := fmt.Sscanf(clStr, "%d", &length); err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length: %q", clStr)
+	}
+	if length > limit {
+		// Drain the oversized body without buffering it so the next frame stays aligned.
+		if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return nil, err
+		}
+		return nil, newFrameTooLargeError(length, limit)
 	}
 	body := make([]byte, length)
 	if _, err := io.ReadFull(r, body); err != nil {
@@ -111,7 +153,7 @@ func readMessage(r *bufio.Reader) (*jsonrpcRequest, error) {
 	}
 	var req jsonrpcRequest
 	if err := json.Unmarshal(body, &req); err != nil {
-		return nil, err
+		return nil, &frameError{code: -32700, message: fmt.Sprintf("Parse error: %v", err)}
 	}
 	return &req, nil
 }
@@ -129,25 +171,33 @@ func makeError(id any, code int, msg string) jsonrpcResponse {
 // --- Tool Definitions ---
 
 func toolDefinitions() []tools.Definition {
-	return []tools.Definition{
+	definitions := []tools.Definition{
 		tools.AvailableToolsDefinition(),
 		tools.CurrentTimeDefinition(),
 		tools.CurrentWeatherDefinition(),
+		tools.CalculateDefinition(),
+		tools.ConvertUnitsDefinition(),
 	}
+	definitions = append(definitions, tools.WebToolDefinitions()...)
+	definitions = append(definitions, tools.CommandToolDefinitions()...)
+	return append(definitions, tools.FileToolDefinitions()...)
 }
 
 // --- Tool Implementation Wrapper ---
 
-func runTool(name string, args map[string]any) []tools.ContentPart {
+func runTool(ctx context.Context, name string, args map[string]any) []tools.ContentPart {
 	handler := handlerFor(name)
 	if handler == nil {
 		return []tools.ContentPart{{Type: "text", Text: fmt.Sprintf("Unknown tool: %s", name)}}
 	}
 
-	return invokeWithRetries(name, handler, args)
+	return invokeWithRetries(ctx, name, handler, args)
 }
 
 func handlerFor(name string) tools.Handler {
+	if mockFixtures != nil {
+		return mockHandler(name)
+	}
 	switch name {
 	case tools.AvailableToolsName:
 		return tools.AvailableTools
@@ -155,8 +205,18 @@ func handlerFor(name string) tools.Handler {
 		return tools.CurrentWeather
 	case tools.CurrentTimeName:
 		return tools.CurrentTime
+	case tools.CalculateName:
+		return tools.Calculate
+	case tools.ConvertUnitsName:
+		return tools.ConvertUnits
 	default:
-		return nil
+		if handler := tools.WebToolHandler(name); handler != nil {
+			return handler
+		}
+		if handler := tools.CommandToolHandler(name); handler != nil {
+			return handler
+		}
+		return tools.FileToolHandler(name)
 	}
 }
 
@@ -207,13 +267,13 @@ func promptFromArgs(args map[string]any) string {
 	return ""
 }
 
-func invokeWithRetries(toolName string, handler tools.Handler, args map[string]any) []tools.ContentPart {
+func invokeWithRetries(ctx context.Context, toolName string, handler tools.Handler, args map[string]any) []tools.ContentPart {
 	attempt := attemptFromArgs(args)
 	prompt := promptFromArgs(args)
 	if attempt <= 0 {
 		attempt = 1
 	}
-	content, err := handler(args)
+	content, err := handler(ctx, args)
 	if err == nil {
 		return content
 	}
@@ -233,40 +293,80 @@ func invokeWithRetries(toolName string, handler tools.Handler, args map[string]a
 	return logs
 }
 
+// callToolResult runs one tool call and builds its tools/call result. Arguments that are
+// too long or do not match the tool's input schema are rejected with an
+// *invalidArgumentsError before the tool runs.
+func callToolResult(ctx context.Context, p toolsCallParams) (map[string]any, error) {
+	if p.Arguments == nil {
+		p.Arguments = map[string]any{}
+	}
+	args, err := sanitizeToolArguments(p.Name, p.Arguments, maxArgumentLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateArguments(p.Name, args); err != nil {
+		return nil, err
+	}
+	content := runTool(ctx, p.Name, args)
+	result := map[string]any{"content": chunkContent(content, chunkBytes)}
+	if structured := structuredContent(content, chunkBytes); structured != nil {
+		result["structuredContent"] = structured
+	}
+	return result, nil
+}
+
 // --- MCP Request Handler ---
 
-func handleRequest(req *jsonrpcRequest, w *bufio.Writer) error {
+// handleRequest answers one request. ctx is cancelled when the client cancels the request.
+func handleRequest(ctx context.Context, req *jsonrpcRequest) jsonrpcResponse {
 	switch req.Method {
 	case "initialize":
 		result := map[string]any{
-			"serverInfo":   map[string]any{"name": "agon-mcp", "version": "0.1.0"},
-			"capabilities": map[string]any{"tools": map[string]any{"list": true, "call": true}},
+			"serverInfo": map[string]any{"name": "agon-mcp", "version": "0.1.0"},
+			"capabilities": map[string]any{
+				"tools":     map[string]any{"list": true, "call": true, "callBatch": true},
+				"resources": map[string]any{"read": true},
+			},
 		}
-		return writeMessage(w, makeResult(req.ID, result))
+		return makeResult(req.ID, result)
 
 	case "ping":
-		return writeMessage(w, makeResult(req.ID, map[string]any{}))
+		return makeResult(req.ID, map[string]any{})
 
 	case "tools/list":
 		result := map[string]any{"tools": toolDefinitions()}
-		return writeMessage(w, makeResult(req.ID, result))
+		return makeResult(req.ID, result)
 
 	case "tools/call":
 		var p toolsCallParams
 		if len(req.Params) > 0 {
 			if err := json.Unmarshal(req.Params, &p); err != nil {
-				return writeMessage(w, makeError(req.ID, -32602, "Invalid params"))
+				return makeError(req.ID, -32602, "Invalid params")
 			}
 		}
-		if p.Arguments == nil {
-			p.Arguments = map[string]any{}
+		result, err := callToolResult(ctx, p)
+		var invalid *invalidArgumentsError
+		if errors.As(err, &invalid) {
+			return jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Error: invalid.rpcError()}
+		}
+		return makeResult(req.ID, result)
+
+	case "tools/callBatch":
+		result, err := handleToolsCallBatch(ctx, req.Params)
+		if err != nil {
+			return makeError(req.ID, -32602, err.Error())
+		}
+		return makeResult(req.ID, result)
+
+	case "resources/read":
+		result, err := handleResourcesRead(req.Params)
+		if err != nil {
+			return makeError(req.ID, -32602, err.Error())
 		}
-		content := runTool(p.Name, p.Arguments)
-		result := map[string]any{"content": content}
-		return writeMessage(w, makeResult(req.ID, result))
+		return makeResult(req.ID, result)
 	}
 
-	return writeMessage(w, makeError(req.ID, -32601, fmt.Sprintf("Method not found: %s", req.Method)))
+	return makeError(req.ID, -32601, fmt.Sprintf("Method not found: %s", req.Method))
 }
 
 // --- Main Server Loop ---
@@ -276,30 +376,97 @@ func main() {
 	cfg, err := appconfig.Load(configPath)
 	if err == nil {
 		retryCount = cfg.MCPRetryAttempts()
+		maxFrameBytes = cfg.MCPFrameLimit()
+		maxArgumentLen = cfg.MCPArgumentLimit()
+		chunkBytes = cfg.MCPChunkSize()
+		mockMode = mockMode || cfg.MCPMock
+		if fixturesDir == "" {
+			fixturesDir = cfg.MCPFixturesDir()
+		}
+		if err := tools.ConfigureGeocodeCache(cfg.GeocodeCacheCapacity(), cfg.GeocodeCacheTTLDuration(), cfg.GeocodeCacheFile()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if web := cfg.WebTools; web != nil {
+			search, err := tools.NewSearchBackend(web.Search, web.SearchURL, web.APIKey)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			tools.ConfigureWebTools(tools.WebToolsOptions{
+				Search:               search,
+				MaxResults:           web.ResultLimit(),
+				Fetch:                web.Fetch,
+				MaxFetchBytes:        web.FetchLimit(),
+				FetchTimeout:         web.FetchTimeoutDuration(),
+				AllowPrivateNetworks: web.AllowPrivateNetworks,
+			})
+		}
+		if command := cfg.CommandTool; command != nil {
+			rules := make([]tools.CommandRule, 0, len(command.Commands))
+			for _, allowed := range command.Commands {
+				rules = append(rules, tools.CommandRule{
+					Name:        allowed.Name,
+					Path:        allowed.Path,
+					Args:        allowed.Args,
+					Timeout:     allowed.TimeoutDuration(),
+					Description: allowed.Description,
+				})
+			}
+			err := tools.ConfigureCommandTool(tools.CommandToolOptions{Commands: rules, Dir: command.Dir, MaxOutputBytes: command.OutputLimit()})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+		if files := cfg.FileTools; files != nil {
+			err := tools.ConfigureFileTools(tools.FileToolsOptions{
+				Roots:         files.Roots,
+				ReadFile:      files.ReadFile,
+				ListDirectory: files.ListDirectory,
+				WriteFile:     files.WriteFile,
+				MaxReadBytes:  files.ReadLimit(),
+				MaxWriteBytes: files.WriteLimit(),
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	if mockMode {
+		if fixturesDir == "" {
+			fixturesDir = (appconfig.Config{}).MCPFixturesDir()
+		}
+		fixtures, err := loadMockFixtures(fixturesDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		mockFixtures = fixtures
 	}
 
 	r := bufio.NewReader(os.Stdin)
-	w := bufio.NewWriter(os.Stdout)
+	d := newDispatcher(bufio.NewWriter(os.Stdout))
+	// Answer the requests still in flight before exiting.
+	defer d.wait()
 
 	for {
-		req, err := readMessage(r)
+		req, err := readMessage(r, maxFrameBytes)
 		if err != nil {
 			if err == io.EOF {
 				return
 			}
+			var frameErr *frameError
+			if errors.As(err, &frameErr) {
+				_ = d.write(makeError(nil, frameErr.code, frameErr.message))
+				continue
+			}
 			// Try to send a generic server error if we can parse an id (we can't here); else break
 			// write a best-effort error frame without id to keep stream sane
-			_ = writeMessage(w, jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{Code: -32000, Message: err.Error()}})
+			_ = d.write(jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{Code: -32000, Message: err.Error()}})
 			return
 		}
 		if req == nil {
 			// malformed; end
 			return
 		}
-		if err := handleRequest(req, w); err != nil {
-			// Attempt to report per-request error
-			_ = writeMessage(w, makeError(req.ID, -32000, err.Error()))
-			// Do not exit; continue processing
-		}
+		d.dispatch(req)
 	}
 }
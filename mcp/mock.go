@@ -0,0 +1,111 @@
+// mcp/mock.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// mockFixture holds the canned responses for a single tool in mock mode.
+type mockFixture struct {
+	// Cases are checked in order; the first whose arguments all match the call wins.
+	Cases []mockCase `json:"cases"`
+	// Default is returned when no case matches.
+	Default []tools.ContentPart `json:"default"`
+}
+
+// mockCase pairs a set of expected arguments with the content returned for them.
+type mockCase struct {
+	Arguments map[string]any      `json:"arguments"`
+	Content   []tools.ContentPart `json:"content"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// mockFixtures maps tool names to their loaded fixtures; nil when mock mode is off.
+var mockFixtures map[string]mockFixture
+
+// loadMockFixtures reads every <tool>.json fixture in dir.
+func loadMockFixtures(dir string) (map[string]mockFixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read mock fixtures %s: %w", dir, err)
+	}
+	fixtures := make(map[string]mockFixture)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read mock fixture %s: %w", path, err)
+		}
+		var fixture mockFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("unable to parse mock fixture %s: %w", path, err)
+		}
+		fixtures[strings.TrimSuffix(entry.Name(), ".json")] = fixture
+	}
+	return fixtures, nil
+}
+
+// mockHandler returns a handler that answers from the named tool's fixture. Tools
+// without a fixture fail, so a mock run never falls through to a live API. Tools that are
+// deterministic and local are the exception: the calculator and unit converter run as
+// usual, and the file tools run against their sandbox.
+func mockHandler(name string) tools.Handler {
+	fixture, ok := mockFixtures[name]
+	if !ok {
+		switch name {
+		case tools.AvailableToolsName:
+			return tools.AvailableTools
+		case tools.CalculateName:
+			return tools.Calculate
+		case tools.ConvertUnitsName:
+			return tools.ConvertUnits
+		}
+		if handler := tools.FileToolHandler(name); handler != nil {
+			return handler
+		}
+		return func(context.Context, map[string]any) ([]tools.ContentPart, error) {
+			return nil, fmt.Errorf("mock mode: no fixture for tool %q", name)
+		}
+	}
+	return func(_ context.Context, args map[string]any) ([]tools.ContentPart, error) {
+		for _, c := range fixture.Cases {
+			if !mockArgumentsMatch(c.Arguments, args) {
+				continue
+			}
+			if c.Error != "" {
+				return nil, fmt.Errorf("%s", c.Error)
+			}
+			return c.Content, nil
+		}
+		if fixture.Default == nil {
+			return nil, fmt.Errorf("mock mode: no fixture case for tool %q matches the arguments", name)
+		}
+		return fixture.Default, nil
+	}
+}
+
+// mockArgumentsMatch reports whether every expected argument equals the actual value.
+// Values are compared by their string form, case-insensitively, so fixtures written
+// by hand match numbers and casing variations the model might produce.
+func mockArgumentsMatch(expected, actual map[string]any) bool {
+	for key, want := range expected {
+		got, ok := actual[key]
+		if !ok {
+			return false
+		}
+		if !strings.EqualFold(strings.TrimSpace(fmt.Sprint(want)), strings.TrimSpace(fmt.Sprint(got))) {
+			return false
+		}
+	}
+	return true
+}
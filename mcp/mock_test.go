@@ -0,0 +1,96 @@
+// mcp/mock_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// useMockFixtures loads the fixtures in dir as the active mock set for a test.
+func useMockFixtures(t *testing.T, dir string) {
+	t.Helper()
+	fixtures, err := loadMockFixtures(dir)
+	if err != nil {
+		t.Fatalf("loadMockFixtures: %v", err)
+	}
+	saved := mockFixtures
+	mockFixtures = fixtures
+	t.Cleanup(func() { mockFixtures = saved })
+}
+
+// TestMockHandlerScripted verifies a fixture's cases are matched in order with loosely
+// compared arguments, that a case can script an error, and that unmatched calls get the
+// default.
+func TestMockHandlerScripted(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `{
+  "cases": [
+    {"arguments": {"location": "Paris, France"}, "content": [{"type": "json", "text": "{\"temp\":61}"}]},
+    {"arguments": {"location": "Atlantis"}, "error": "location not found"}
+  ],
+  "default": [{"type": "text", "text": "mild"}]
+}`
+	if err := os.WriteFile(filepath.Join(dir, "current_weather.json"), []byte(fixture), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	useMockFixtures(t, dir)
+
+	if len(mockFixtures) != 1 {
+		t.Fatalf("expected only the JSON fixture to load, got %v", mockFixtures)
+	}
+	handler := mockHandler(tools.CurrentWeatherName)
+	content, err := handler(context.Background(), map[string]any{"location": " paris, FRANCE "})
+	if err != nil || len(content) != 1 || content[0].Text != `{"temp":61}` {
+		t.Fatalf("expected the Paris case, got %+v, %v", content, err)
+	}
+	if _, err := handler(context.Background(), map[string]any{"location": "Atlantis"}); err == nil || err.Error() != "location not found" {
+		t.Fatalf("expected the scripted error, got %v", err)
+	}
+	if content, err := handler(context.Background(), map[string]any{"location": "Oslo"}); err != nil || content[0].Text != "mild" {
+		t.Fatalf("expected the default response, got %+v, %v", content, err)
+	}
+
+	parts := runTool(context.Background(), tools.CurrentWeatherName, map[string]any{"location": "Paris, France"})
+	if len(parts) != 1 || parts[0].Type != "json" {
+		t.Fatalf("expected runTool to answer from the fixture, got %+v", parts)
+	}
+}
+
+// TestMockHandlerUnknownTool verifies a tool without a fixture fails instead of reaching
+// a live API, while local tools still run.
+func TestMockHandlerUnknownTool(t *testing.T) {
+	useMockFixtures(t, t.TempDir())
+
+	_, err := mockHandler("stock_price")(context.Background(), map[string]any{"symbol": "ACME"})
+	if err == nil || !strings.Contains(err.Error(), `no fixture for tool "stock_price"`) {
+		t.Fatalf("expected a missing fixture error, got %v", err)
+	}
+	if _, err := mockHandler(tools.CurrentWeatherName)(context.Background(), map[string]any{"location": "Paris"}); err == nil {
+		t.Fatalf("expected a network tool without a fixture to fail")
+	}
+	if content, err := mockHandler(tools.CalculateName)(context.Background(), map[string]any{"expression": "2+3"}); err != nil || len(content) == 0 {
+		t.Fatalf("expected the calculator to run, got %+v, %v", content, err)
+	}
+}
+
+// TestLoadMockFixtures verifies the bundled fixtures parse.
+func TestLoadMockFixtures(t *testing.T) {
+	fixtures, err := loadMockFixtures("fixtures")
+	if err != nil {
+		t.Fatalf("loadMockFixtures: %v", err)
+	}
+	if len(fixtures[tools.CurrentWeatherName].Cases) == 0 || fixtures[tools.CurrentTimeName].Default == nil {
+		t.Fatalf("unexpected bundled fixtures %+v", fixtures)
+	}
+	if _, err := loadMockFixtures(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("expected a missing directory to fail")
+	}
+}
@@ -0,0 +1,129 @@
+// mcp/resources.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// maxHeldResources bounds how many unread tool results the server keeps in memory.
+const maxHeldResources = 16
+
+// resourceRef replaces an oversized content part in a tools/call result. The client
+// fetches the original text with resources/read.
+type resourceRef struct {
+	Type         string `json:"type"`
+	URI          string `json:"uri"`
+	Size         int    `json:"size"`
+	OriginalType string `json:"originalType"`
+}
+
+// resourcesReadParams are the parameters of a resources/read request.
+type resourcesReadParams struct {
+	URI    string `json:"uri"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// resourceStore holds large tool results until the client has read them to the end.
+type resourceStore struct {
+	mu    sync.Mutex
+	next  int
+	data  map[string]string
+	order []string
+}
+
+// heldResources is the server-wide store of chunked tool results.
+var heldResources = &resourceStore{data: make(map[string]string)}
+
+// hold stores text and returns its URI, dropping the oldest held result when full.
+func (s *resourceStore) hold(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	uri := fmt.Sprintf("agon://results/%d", s.next)
+	s.data[uri] = text
+	s.order = append(s.order, uri)
+	for len(s.order) > maxHeldResources {
+		delete(s.data, s.order[0])
+		s.order = s.order[1:]
+	}
+	return uri
+}
+
+// read returns up to length bytes of the resource starting at offset, ending on a rune
+// boundary so every chunk is valid UTF-8. The resource is released once fully read.
+func (s *resourceStore) read(uri string, offset, length int) (string, bool, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text, ok := s.data[uri]
+	if !ok {
+		return "", false, 0, fmt.Errorf("unknown or expired resource: %s", uri)
+	}
+	if offset < 0 || offset > len(text) {
+		return "", false, 0, fmt.Errorf("offset %d out of range for %s", offset, uri)
+	}
+	end := offset + length
+	if length <= 0 || end > len(text) {
+		end = len(text)
+	}
+	for end > offset && end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	eof := end >= len(text)
+	if eof {
+		delete(s.data, uri)
+		for i, held := range s.order {
+			if held == uri {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	return text[offset:end], eof, len(text), nil
+}
+
+// chunkContent swaps every part whose text exceeds threshold for a resource reference.
+func chunkContent(content []tools.ContentPart, threshold int) []any {
+	out := make([]any, 0, len(content))
+	for _, part := range content {
+		if threshold <= 0 || len(part.Text) <= threshold {
+			out = append(out, part)
+			continue
+		}
+		out = append(out, resourceRef{
+			Type:         "resource",
+			URI:          heldResources.hold(part.Text),
+			Size:         len(part.Text),
+			OriginalType: part.Type,
+		})
+	}
+	return out
+}
+
+// handleResourcesRead answers a resources/read request with one chunk of a held result.
+func handleResourcesRead(raw json.RawMessage) (map[string]any, error) {
+	var p resourcesReadParams
+	if err := json.Unmarshal(raw, &p); err != nil || p.URI == "" {
+		return nil, fmt.Errorf("Invalid params")
+	}
+	length := p.Length
+	if length <= 0 || length > chunkBytes {
+		length = chunkBytes
+	}
+	text, eof, size, err := heldResources.read(p.URI, p.Offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"uri":    p.URI,
+		"offset": p.Offset,
+		"text":   text,
+		"size":   size,
+		"eof":    eof,
+	}, nil
+}
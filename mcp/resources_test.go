@@ -0,0 +1,51 @@
+// mcp/resources_test.go
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// TestChunkContentAndReadResource verifies oversized parts become resource references
+// that read back intact in rune-aligned chunks and are released at end of stream.
+func TestChunkContentAndReadResource(t *testing.T) {
+	large := strings.Repeat("héllo wörld ", 20)
+	content := chunkContent([]tools.ContentPart{
+		{Type: "text", Text: "short"},
+		{Type: "json", Text: large},
+	}, 32)
+
+	if _, ok := content[0].(tools.ContentPart); !ok {
+		t.Fatalf("expected small part to pass through, got %#v", content[0])
+	}
+	ref, ok := content[1].(resourceRef)
+	if !ok || ref.OriginalType != "json" || ref.Size != len(large) {
+		t.Fatalf("expected resource reference, got %#v", content[1])
+	}
+
+	var builder strings.Builder
+	offset := 0
+	for {
+		text, eof, _, err := heldResources.read(ref.URI, offset, 7)
+		if err != nil {
+			t.Fatalf("read returned error: %v", err)
+		}
+		if !utf8.ValidString(text) {
+			t.Fatalf("chunk at offset %d is not valid UTF-8: %q", offset, text)
+		}
+		builder.WriteString(text)
+		offset += len(text)
+		if eof {
+			break
+		}
+	}
+	if builder.String() != large {
+		t.Fatalf("reassembled text does not match original")
+	}
+	if _, _, _, err := heldResources.read(ref.URI, 0, 7); err == nil {
+		t.Fatalf("expected resource to be released after reaching the end")
+	}
+}
@@ -0,0 +1,102 @@
+// mcp/sanitize.go
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// frameError reports a frame that was consumed but cannot be processed. The stream
+// stays in sync, so the server can answer with a JSON-RPC error and keep serving.
+type frameError struct {
+	code    int
+	message string
+}
+
+// Error implements the error interface.
+func (e *frameError) Error() string {
+	return e.message
+}
+
+// newFrameTooLargeError builds the error returned when a frame exceeds the configured limit.
+func newFrameTooLargeError(length, limit int) *frameError {
+	return &frameError{
+		code:    -32600,
+		message: fmt.Sprintf("Invalid Request: frame of %d bytes exceeds limit of %d bytes", length, limit),
+	}
+}
+
+// payloadArguments names each tool's arguments that carry data to pass through verbatim,
+// such as file content, where stripping a '\r' would corrupt it. The tool enforces its own
+// size limit on them.
+var payloadArguments = map[string]map[string]bool{
+	tools.WriteFileName: {"content": true},
+}
+
+// sanitizeToolArguments sanitizes a tool call's arguments, leaving its payload arguments
+// as they are. A string longer than maxLen runes is never cut short, since the tool would
+// run with a different argument than the model sent; the call is rejected with an
+// *invalidArgumentsError naming each such field instead.
+func sanitizeToolArguments(name string, args map[string]any, maxLen int) (map[string]any, error) {
+	var errs []fieldError
+	clean := make(map[string]any, len(args))
+	for key, value := range args {
+		if payloadArguments[name][key] {
+			clean[key] = value
+			continue
+		}
+		clean[key] = sanitizeValue(joinPath("arguments", key), value, maxLen, &errs)
+	}
+	if len(errs) > 0 {
+		return nil, &invalidArgumentsError{tool: name, fields: errs}
+	}
+	return clean, nil
+}
+
+// sanitizeValue recursively sanitizes strings nested inside maps and slices, appending
+// an error to errs, by the value's path, for each string over maxLen runes.
+func sanitizeValue(path string, value any, maxLen int, errs *[]fieldError) any {
+	switch v := value.(type) {
+	case string:
+		cleaned := sanitizeString(v)
+		if length := utf8.RuneCountInString(cleaned); maxLen > 0 && length > maxLen {
+			*errs = append(*errs, fieldError{Field: path, Message: fmt.Sprintf("is %d characters, over the limit of %d", length, maxLen)})
+		}
+		return cleaned
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = sanitizeValue(joinPath(path, key), item, maxLen, errs)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = sanitizeValue(fmt.Sprintf("%s[%d]", path, i), item, maxLen, errs)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// sanitizeString removes control characters (other than newlines and tabs) and invalid
+// UTF-8.
+func sanitizeString(s string) string {
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
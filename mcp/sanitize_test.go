@@ -0,0 +1,135 @@
+// mcp/sanitize_test.go
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// frame encodes body as one Content-Length framed message.
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// TestReadMessageFrameLimit verifies that an oversized frame is drained and reported as an
+// Invalid Request, and that the next frame is still read in sync.
+func TestReadMessageFrameLimit(t *testing.T) {
+	oversized := `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"padding":"` + strings.Repeat("x", 64) + `"}}`
+	next := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	r := bufio.NewReader(strings.NewReader(frame(oversized) + frame(next)))
+
+	_, err := readMessage(r, 32)
+	var frameErr *frameError
+	if !errors.As(err, &frameErr) || frameErr.code != -32600 || !strings.Contains(frameErr.message, "exceeds limit of 32 bytes") {
+		t.Fatalf("expected a frame-too-large error, got %v", err)
+	}
+
+	req, err := readMessage(r, len(next))
+	if err != nil || req.Method != "tools/list" || req.ID != 2.0 {
+		t.Fatalf("expected the next frame to be read, got %+v, %v", req, err)
+	}
+}
+
+// TestReadMessageParseError verifies that a frame whose body is not JSON is reported as a
+// Parse error and leaves the stream at the next frame.
+func TestReadMessageParseError(t *testing.T) {
+	next := `{"jsonrpc":"2.0","id":3,"method":"initialize"}`
+	r := bufio.NewReader(strings.NewReader(frame(`{"jsonrpc": "2.0",`) + frame(next)))
+
+	_, err := readMessage(r, maxFrameBytes)
+	var frameErr *frameError
+	if !errors.As(err, &frameErr) || frameErr.code != -32700 || !strings.HasPrefix(frameErr.message, "Parse error:") {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	response := makeError(nil, frameErr.code, frameErr.message)
+	if response.Error == nil || response.Error.Code != -32700 {
+		t.Fatalf("unexpected error response %+v", response)
+	}
+
+	req, err := readMessage(r, maxFrameBytes)
+	if err != nil || req.Method != "initialize" {
+		t.Fatalf("expected the next frame to be read, got %+v, %v", req, err)
+	}
+}
+
+// TestReadMessageHeaderLimit verifies that a header line longer than the cap is refused
+// before it is buffered in full, even when it never ends.
+func TestReadMessageHeaderLimit(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("X-Padding: " + strings.Repeat("x", 3*maxHeaderLineBytes)))
+	_, err := readMessage(r, maxFrameBytes)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("header line exceeds limit of %d bytes", maxHeaderLineBytes)) {
+		t.Fatalf("expected a header line limit error, got %v", err)
+	}
+
+	long := "X-Padding: " + strings.Repeat("x", maxHeaderLineBytes-len("X-Padding: ")-2) + "\r\n"
+	body := `{"jsonrpc":"2.0","id":4,"method":"ping"}`
+	r = bufio.NewReader(strings.NewReader(long + frame(body)))
+	if req, err := readMessage(r, maxFrameBytes); err != nil || req.Method != "ping" {
+		t.Fatalf("expected a header line at the cap to be read, got %+v, %v", req, err)
+	}
+}
+
+// TestSanitizeToolArguments verifies that control characters and invalid UTF-8 are
+// stripped from nested string arguments and that write_file content is passed through
+// untouched.
+func TestSanitizeToolArguments(t *testing.T) {
+	args := map[string]any{
+		"location": "Par\x00is\x1b[31m\xff",
+		"options":  map[string]any{"units": []any{"met\x07ric", 3.0}},
+	}
+	clean, err := sanitizeToolArguments("current_weather", args, 16)
+	if err != nil {
+		t.Fatalf("sanitizeToolArguments returned error: %v", err)
+	}
+	if clean["location"] != "Paris[31m" {
+		t.Fatalf("unexpected location %q", clean["location"])
+	}
+	units := clean["options"].(map[string]any)["units"].([]any)
+	if units[0] != "metric" || units[1] != 3.0 {
+		t.Fatalf("unexpected nested units %v", units)
+	}
+	if args["location"] != "Par\x00is\x1b[31m\xff" {
+		t.Fatalf("expected the original arguments untouched")
+	}
+
+	content := "line one\r\nline\ttwo\x00\x1b\r\n" + strings.Repeat("z", 32)
+	written, err := sanitizeToolArguments("write_file", map[string]any{"path": "notes\x00.md", "content": content}, 8)
+	if err != nil {
+		t.Fatalf("expected write_file content to skip the length limit, got %v", err)
+	}
+	if written["content"] != content {
+		t.Fatalf("expected write_file content to pass through, got %q", written["content"])
+	}
+	if written["path"] != "notes.md" {
+		t.Fatalf("expected the path sanitized, got %q", written["path"])
+	}
+}
+
+// TestSanitizeToolArgumentsRejectsOversized verifies that strings over the limit are
+// rejected by field with a -32602 error instead of being cut short for the tool.
+func TestSanitizeToolArgumentsRejectsOversized(t *testing.T) {
+	args := map[string]any{
+		"path":    "notes/" + strings.Repeat("a", 8) + ".md",
+		"options": map[string]any{"tags": []any{"ok", strings.Repeat("b", 9)}},
+	}
+	clean, err := sanitizeToolArguments("write_file", args, 8)
+	var invalid *invalidArgumentsError
+	if clean != nil || !errors.As(err, &invalid) {
+		t.Fatalf("expected an invalidArgumentsError, got %v, %v", clean, err)
+	}
+	sort.Slice(invalid.fields, func(i, j int) bool { return invalid.fields[i].Field < invalid.fields[j].Field })
+	want := []fieldError{
+		{Field: "options.tags[1]", Message: "is 9 characters, over the limit of 8"},
+		{Field: "path", Message: "is 17 characters, over the limit of 8"},
+	}
+	if len(invalid.fields) != len(want) || invalid.fields[0] != want[0] || invalid.fields[1] != want[1] {
+		t.Fatalf("unexpected field errors %#v", invalid.fields)
+	}
+	if code := invalid.rpcError().Code; code != invalidParamsCode {
+		t.Fatalf("expected code %d, got %d", invalidParamsCode, code)
+	}
+}
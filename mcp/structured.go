@@ -0,0 +1,30 @@
+// mcp/structured.go
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// structuredContent returns the tool's "json" part decoded as an object, for the
+// structuredContent field of a tools/call result. It returns nil when there is no JSON
+// object, or when the part is large enough to be chunked, so results stay bounded by
+// the chunk size and clients fall back to the text content.
+func structuredContent(content []tools.ContentPart, threshold int) map[string]any {
+	for _, part := range content {
+		if strings.ToLower(strings.TrimSpace(part.Type)) != "json" {
+			continue
+		}
+		if threshold > 0 && len(part.Text) > threshold {
+			return nil
+		}
+		var object map[string]any
+		if err := json.Unmarshal([]byte(part.Text), &object); err != nil {
+			return nil
+		}
+		return object
+	}
+	return nil
+}
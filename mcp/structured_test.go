@@ -0,0 +1,34 @@
+// mcp/structured_test.go
+package main
+
+import (
+	"testing"
+
+	"github.com/mwiater/agon/mcp/tools"
+)
+
+// TestStructuredContent verifies that a JSON object part is surfaced as structured content
+// and that arrays, invalid JSON and parts large enough to be chunked are left as text only.
+func TestStructuredContent(t *testing.T) {
+	content := []tools.ContentPart{
+		{Type: "interpret", Text: "Explain this."},
+		{Type: "json", Text: `{"timezone":"UTC","unix":1736962200}`},
+	}
+	got := structuredContent(content, 1024)
+	if got["timezone"] != "UTC" || got["unix"] != float64(1736962200) {
+		t.Fatalf("unexpected structured content: %#v", got)
+	}
+
+	for name, parts := range map[string][]tools.ContentPart{
+		"array":   {{Type: "json", Text: `[{"name":"current_time"}]`}},
+		"invalid": {{Type: "json", Text: `{"broken"`}},
+		"text":    {{Type: "text", Text: "I could not handle your request."}},
+	} {
+		if got := structuredContent(parts, 1024); got != nil {
+			t.Fatalf("%s: expected no structured content, got %#v", name, got)
+		}
+	}
+	if got := structuredContent(content, 8); got != nil {
+		t.Fatalf("chunked parts should not be duplicated as structured content, got %#v", got)
+	}
+}
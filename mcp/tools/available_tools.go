@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -15,6 +16,11 @@ func AvailableToolsDefinition() Definition {
 			"type":       "object",
 			"properties": map[string]any{},
 		},
+		Examples: []Example{{
+			Request:   "Which tools can you use?",
+			Arguments: map[string]any{},
+			Result:    "The name and description of every MCP tool.",
+		}},
 	}
 }
 
@@ -27,12 +33,17 @@ func AvailableToolsTool() Tool {
 }
 
 // AvailableTools returns the set of tools exposed by the MCP server in both JSON and summaries.
-func AvailableTools(args map[string]any) ([]ContentPart, error) {
+func AvailableTools(ctx context.Context, args map[string]any) ([]ContentPart, error) {
 	definitions := []Definition{
 		AvailableToolsDefinition(),
 		CurrentTimeDefinition(),
 		CurrentWeatherDefinition(),
+		CalculateDefinition(),
+		ConvertUnitsDefinition(),
 	}
+	definitions = append(definitions, WebToolDefinitions()...)
+	definitions = append(definitions, CommandToolDefinitions()...)
+	definitions = append(definitions, FileToolDefinitions()...)
 
 	payload := make([]map[string]string, 0, len(definitions))
 	var summaryBuilder strings.Builder
@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxExpressionLength caps the expressions calculate accepts.
+const maxExpressionLength = 1024
+
+// calcFunctions are the functions calculate understands, by name and argument count.
+var calcFunctions = map[string]struct {
+	args int
+	fn   func(args []float64) float64
+}{
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"cbrt":  {1, func(a []float64) float64 { return math.Cbrt(a[0]) }},
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"ln":    {1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"log":   {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+	"log2":  {1, func(a []float64) float64 { return math.Log2(a[0]) }},
+	"sin":   {1, func(a []float64) float64 { return math.Sin(a[0]) }},
+	"cos":   {1, func(a []float64) float64 { return math.Cos(a[0]) }},
+	"tan":   {1, func(a []float64) float64 { return math.Tan(a[0]) }},
+	"asin":  {1, func(a []float64) float64 { return math.Asin(a[0]) }},
+	"acos":  {1, func(a []float64) float64 { return math.Acos(a[0]) }},
+	"atan":  {1, func(a []float64) float64 { return math.Atan(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"min":   {2, func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {2, func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+}
+
+// calcConstants are the named constants calculate understands.
+var calcConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// CalculateDefinition describes the calculator tool.
+func CalculateDefinition() Definition {
+	names := make([]string, 0, len(calcFunctions))
+	for name := range calcFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return Definition{
+		Name: CalculateName,
+		Description: "Evaluate an arithmetic expression exactly instead of working it out. Supports + - * / % ^ (power), " +
+			"parentheses, the constants pi and e, and the functions " + strings.Join(names, ", ") + ". Trigonometry uses radians.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"expression": map[string]any{"type": "string", "description": "The expression, e.g. (3 + 4) * 2^3 / sqrt(16)"},
+			},
+			"required": []string{"expression"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"expression": map[string]any{"type": "string"},
+				"result":     map[string]any{"type": "number"},
+			},
+			"required": []string{"expression", "result"},
+		},
+		Examples: []Example{{
+			Request:   "What is 17% of 2,340?",
+			Arguments: map[string]any{"expression": "2340 * 17 / 100"},
+			Result:    "The exact result, 397.8.",
+		}},
+	}
+}
+
+// Calculate evaluates an arithmetic expression and returns the result as JSON.
+func Calculate(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	expression := strings.TrimSpace(stringArgument(args, "expression"))
+	if expression == "" {
+		return nil, errors.New("expression is required")
+	}
+	result, err := evaluateExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return jsonContent(map[string]any{"expression": expression, "result": roundSignificant(result)})
+}
+
+// evaluateExpression parses and evaluates expression. Results that are not finite, such
+// as division by zero, are errors rather than Inf or NaN.
+func evaluateExpression(expression string) (float64, error) {
+	if len(expression) > maxExpressionLength {
+		return 0, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+	p := &calcParser{input: expression}
+	value, err := p.expression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos+1)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, errors.New("the result is not a finite number")
+	}
+	return value, nil
+}
+
+// calcParser is a recursive-descent parser that evaluates as it parses:
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = ("-" | "+") unary | power
+//	power      = primary [ ("^" | "**") unary ]
+//	primary    = number | constant | function "(" expression { "," expression } ")" | "(" expression ")"
+type calcParser struct {
+	input string
+	pos   int
+}
+
+func (p *calcParser) expression() (float64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.consume("+"):
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value += right
+		case p.consume("-"):
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value -= right
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *calcParser) term() (float64, error) {
+	value, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		var op string
+		switch {
+		case p.peek("**"):
+			return value, nil
+		case p.consume("*"):
+			op = "*"
+		case p.consume("/"):
+			op = "/"
+		case p.consume("%"):
+			op = "%"
+		default:
+			return value, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			value *= right
+		case "/":
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			value /= right
+		case "%":
+			if right == 0 {
+				return 0, errors.New("modulo by zero")
+			}
+			value = math.Mod(value, right)
+		}
+	}
+}
+
+func (p *calcParser) unary() (float64, error) {
+	switch {
+	case p.consume("-"):
+		value, err := p.unary()
+		return -value, err
+	case p.consume("+"):
+		return p.unary()
+	default:
+		return p.power()
+	}
+}
+
+func (p *calcParser) power() (float64, error) {
+	base, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+	if p.consume("**") || p.consume("^") {
+		// Right-associative, and binding tighter than a unary minus on its left:
+		// 2^3^2 is 2^9 and -2^2 is -4.
+		exponent, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *calcParser) primary() (float64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0, errors.New("unexpected end of expression")
+	}
+	c := rune(p.input[p.pos])
+	switch {
+	case c == '(':
+		p.pos++
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(")") {
+			return 0, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		return value, nil
+	case unicode.IsDigit(c) || c == '.':
+		return p.number()
+	case unicode.IsLetter(c):
+		return p.identifier()
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+	}
+}
+
+// number reads a decimal number with an optional exponent. Thousands separators are not
+// accepted, since "1,000" would be ambiguous inside a function's arguments.
+func (p *calcParser) number() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		next := p.pos + 1
+		if next < len(p.input) && (p.input[next] == '+' || p.input[next] == '-') {
+			next++
+		}
+		if next < len(p.input) && isDigit(p.input[next]) {
+			p.pos = next
+			for p.pos < len(p.input) && isDigit(p.input[p.pos]) {
+				p.pos++
+			}
+		}
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+	}
+	return value, nil
+}
+
+// identifier reads a constant or a function call.
+func (p *calcParser) identifier() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || unicode.IsLetter(rune(p.input[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+	if value, ok := calcConstants[name]; ok && !p.peek("(") {
+		return value, nil
+	}
+	function, ok := calcFunctions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown name %q", name)
+	}
+	if !p.consume("(") {
+		return 0, fmt.Errorf("%s needs its arguments in parentheses", name)
+	}
+	var args []float64
+	for {
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, value)
+		if p.consume(",") {
+			continue
+		}
+		if !p.consume(")") {
+			return 0, fmt.Errorf("missing ) after the arguments of %s", name)
+		}
+		break
+	}
+	if len(args) != function.args {
+		return 0, fmt.Errorf("%s takes %d argument(s), got %d", name, function.args, len(args))
+	}
+	return function.fn(args), nil
+}
+
+// consume skips spaces and then token, reporting whether it was there.
+func (p *calcParser) consume(token string) bool {
+	if p.peek(token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// peek skips spaces and reports whether token comes next.
+func (p *calcParser) peek(token string) bool {
+	p.skipSpace()
+	return strings.HasPrefix(p.input[p.pos:], token)
+}
+
+func (p *calcParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestEvaluateExpression verifies precedence, associativity, functions and constants.
+func TestEvaluateExpression(t *testing.T) {
+	cases := map[string]float64{
+		"1 + 2 * 3":            7,
+		"(1 + 2) * 3":          9,
+		"10 - 4 - 3":           3,
+		"2 ^ 3 ^ 2":            512,
+		"2 ** 10":              1024,
+		"-2^2":                 -4,
+		"2^-1":                 0.5,
+		"7 % 3":                1,
+		"1.5e3 / 3":            500,
+		"sqrt(16) + abs(-3)":   7,
+		"max(2, pow(2, 3))":    8,
+		"log(1000) + ln(e)":    4,
+		"round(2 * pi * 100)":  628,
+		"2340 * 17 / 100":      397.8,
+		"  floor( 7 / 2 ) ":    3,
+		"SQRT(9)":              3,
+		"min(1 - 2, -(3 + 4))": -7,
+		".5 + .25":             0.75,
+		"ceil(log2(1000))":     10,
+		"exp(0) + cbrt(27)":    4,
+		"0.1 + 0.2":            0.3,
+		"2 * -3":               -6,
+		"3 - -3":               6,
+	}
+	for expression, want := range cases {
+		got, err := evaluateExpression(expression)
+		if err != nil {
+			t.Fatalf("%q returned error: %v", expression, err)
+		}
+		if math.Abs(roundSignificant(got)-want) > 1e-12 {
+			t.Fatalf("%q = %v, want %v", expression, got, want)
+		}
+	}
+}
+
+// TestEvaluateExpressionErrors verifies malformed expressions and undefined results are
+// reported as errors.
+func TestEvaluateExpressionErrors(t *testing.T) {
+	cases := map[string]string{
+		"1 / 0":                         "division by zero",
+		"5 % 0":                         "modulo by zero",
+		"sqrt(-1)":                      "not a finite number",
+		"ln(0)":                         "not a finite number",
+		"1 +":                           "unexpected end",
+		"(1 + 2":                        "missing )",
+		"2 3":                           "unexpected",
+		"foo(2)":                        "unknown name",
+		"sqrt 4":                        "parentheses",
+		"pow(2)":                        "takes 2 argument(s)",
+		"1,000":                         "unexpected",
+		"1 & 2":                         "unexpected",
+		strings.Repeat("1+", 600) + "1": "longer than",
+	}
+	for expression, want := range cases {
+		_, err := evaluateExpression(expression)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: expected an error containing %q, got %v", expression, want, err)
+		}
+	}
+}
+
+// TestCalculate verifies the handler returns the expression and its result as JSON.
+func TestCalculate(t *testing.T) {
+	content, err := Calculate(context.Background(), map[string]any{"expression": " 0.1 + 0.2 "})
+	if err != nil {
+		t.Fatalf("Calculate returned error: %v", err)
+	}
+	payload := decodeFileResult(t, content)
+	if payload["expression"] != "0.1 + 0.2" || payload["result"] != 0.3 {
+		t.Fatalf("unexpected result %+v", payload)
+	}
+	if _, err := Calculate(context.Background(), map[string]any{}); err == nil {
+		t.Fatalf("expected a missing expression to fail")
+	}
+}
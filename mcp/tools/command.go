@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunCommandName is the canonical name for the command-running tool.
+const RunCommandName = "run_command"
+
+// CommandRule allows one command: the executable it runs, the patterns its arguments must
+// match and how long it may run.
+type CommandRule struct {
+	Name        string
+	Path        string
+	Args        []string
+	Timeout     time.Duration
+	Description string
+}
+
+// CommandToolOptions configures run_command. It is enabled when Commands is not empty.
+type CommandToolOptions struct {
+	Commands       []CommandRule
+	Dir            string
+	MaxOutputBytes int
+}
+
+// allowedCommand is a CommandRule with its executable resolved and patterns compiled.
+type allowedCommand struct {
+	rule     CommandRule
+	path     string
+	patterns []*regexp.Regexp
+}
+
+// commandRunner holds run_command's allowed commands, working directory and output limit.
+type commandRunner struct {
+	commands  map[string]allowedCommand
+	dir       string
+	maxOutput int
+}
+
+// commandTool is nil until ConfigureCommandTool allows at least one command.
+var commandTool *commandRunner
+
+// ConfigureCommandTool enables run_command for opts' commands. Every executable must be
+// found now, so a typo fails at startup rather than when the model calls it.
+func ConfigureCommandTool(opts CommandToolOptions) error {
+	if len(opts.Commands) == 0 {
+		commandTool = nil
+		return nil
+	}
+	commands := make(map[string]allowedCommand, len(opts.Commands))
+	for _, rule := range opts.Commands {
+		executable := rule.Path
+		if strings.TrimSpace(executable) == "" {
+			executable = rule.Name
+		}
+		path, err := exec.LookPath(executable)
+		if err != nil {
+			return fmt.Errorf("run_command %q: %w", rule.Name, err)
+		}
+		allowed := allowedCommand{rule: rule, path: path}
+		for _, pattern := range rule.Args {
+			re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+			if err != nil {
+				return fmt.Errorf("run_command %q: argument pattern %q: %w", rule.Name, pattern, err)
+			}
+			allowed.patterns = append(allowed.patterns, re)
+		}
+		commands[rule.Name] = allowed
+	}
+	commandTool = &commandRunner{commands: commands, dir: opts.Dir, maxOutput: opts.MaxOutputBytes}
+	return nil
+}
+
+// CommandToolDefinitions describes run_command when it is enabled.
+func CommandToolDefinitions() []Definition {
+	if commandTool == nil {
+		return nil
+	}
+	return []Definition{RunCommandDefinition()}
+}
+
+// CommandToolHandler returns run_command's handler when name is run_command and it is enabled.
+func CommandToolHandler(name string) Handler {
+	if commandTool == nil || name != RunCommandName {
+		return nil
+	}
+	return RunCommand
+}
+
+// RunCommandDefinition describes the command-running tool, listing the allowed commands.
+// Clients must ask the user before every call.
+func RunCommandDefinition() Definition {
+	var names, lines []string
+	if commandTool != nil {
+		for name := range commandTool.commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			line := "- " + name
+			if description := commandTool.commands[name].rule.Description; description != "" {
+				line += ": " + description
+			}
+			lines = append(lines, line)
+		}
+	}
+	description := "Run one of the allowed commands, without a shell, after the user approves it. Returns its exit code and output."
+	if len(lines) > 0 {
+		description += " Allowed commands:\n" + strings.Join(lines, "\n")
+	}
+	command := map[string]any{"type": "string", "description": "The command to run"}
+	if len(names) > 0 {
+		command["enum"] = names
+	}
+	return Definition{
+		Name:        RunCommandName,
+		Description: description,
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command": command,
+				"args": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "The command's arguments, one per item",
+				},
+			},
+			"required": []string{"command"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command":    map[string]any{"type": "string"},
+				"args":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"exitCode":   map[string]any{"type": "integer"},
+				"timedOut":   map[string]any{"type": "boolean"},
+				"stdout":     map[string]any{"type": "string"},
+				"stderr":     map[string]any{"type": "string"},
+				"truncated":  map[string]any{"type": "boolean"},
+				"durationMs": map[string]any{"type": "integer"},
+			},
+			"required": []string{"command", "args", "exitCode", "timedOut", "stdout", "stderr", "truncated", "durationMs"},
+		},
+		RequiresApproval: true,
+		Examples: []Example{{
+			Request:   "Which Go version is installed?",
+			Arguments: map[string]any{"command": "go", "args": []string{"version"}},
+			Result:    "The command's exit code, stdout and stderr.",
+		}},
+	}
+}
+
+// RunCommand runs an allowed command whose arguments all match its patterns, stopping it
+// at its timeout. A non-zero exit is reported in the result, not as an error.
+func RunCommand(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if commandTool == nil {
+		return nil, errors.New("run_command is disabled")
+	}
+	name := strings.TrimSpace(stringArgument(args, "command"))
+	allowed, ok := commandTool.commands[name]
+	if !ok {
+		return nil, fmt.Errorf("command %q is not allowed", name)
+	}
+	arguments, err := commandArguments(args["args"])
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range arguments {
+		if !allowed.accepts(arg) {
+			return nil, fmt.Errorf("argument %q is not allowed for %s", arg, name)
+		}
+	}
+
+	if allowed.rule.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, allowed.rule.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, allowed.path, arguments...)
+	cmd.Dir = commandTool.dir
+	cmd.WaitDelay = time.Second
+	stdout := &cappedBuffer{limit: commandTool.maxOutput}
+	stderr := &cappedBuffer{limit: commandTool.maxOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	elapsed := time.Since(start)
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !timedOut {
+		return nil, fmt.Errorf("unable to run %s: %w", name, err)
+	}
+	exitCode := 0
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	return jsonContent(map[string]any{
+		"command":    name,
+		"args":       arguments,
+		"exitCode":   exitCode,
+		"timedOut":   timedOut,
+		"stdout":     strings.ToValidUTF8(stdout.String(), "�"),
+		"stderr":     strings.ToValidUTF8(stderr.String(), "�"),
+		"truncated":  stdout.truncated || stderr.truncated,
+		"durationMs": elapsed.Milliseconds(),
+	})
+}
+
+// accepts reports whether arg matches one of the command's patterns in full.
+func (c allowedCommand) accepts(arg string) bool {
+	for _, re := range c.patterns {
+		if re.MatchString(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandArguments reads run_command's args, which must be a list of strings.
+func commandArguments(value any) ([]string, error) {
+	switch list := value.(type) {
+	case nil:
+		return []string{}, nil
+	case []string:
+		return list, nil
+	case []any:
+		arguments := make([]string, 0, len(list))
+		for _, item := range list {
+			arg, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("args must be strings, got %T", item)
+			}
+			arguments = append(arguments, arg)
+		}
+		return arguments, nil
+	default:
+		return nil, errors.New("args must be a list of strings")
+	}
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest, so a chatty
+// command cannot exhaust memory. A zero limit keeps everything.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer, always reporting the whole of p as written.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	keep := p
+	if b.limit > 0 {
+		if room := b.limit - b.buf.Len(); len(p) > room {
+			keep = p[:max(room, 0)]
+			b.truncated = true
+		}
+	}
+	b.buf.Write(keep)
+	return len(p), nil
+}
+
+// String returns what was kept.
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
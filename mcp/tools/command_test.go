@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configureTestCommands allows sh, with arguments limited to "-c" and a few scripts, and
+// skips the test where there is no sh.
+func configureTestCommands(t *testing.T, timeout time.Duration, maxOutput int) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	err := ConfigureCommandTool(CommandToolOptions{
+		Commands: []CommandRule{{
+			Name:    "sh",
+			Args:    []string{"-c", "echo [a-z]+", "exit [0-9]", "sleep 5", `printf x%.0s \$\(seq 1 100\)`},
+			Timeout: timeout,
+		}},
+		MaxOutputBytes: maxOutput,
+	})
+	if err != nil {
+		t.Fatalf("ConfigureCommandTool returned error: %v", err)
+	}
+	t.Cleanup(func() { commandTool = nil })
+}
+
+// TestRunCommandAllowList verifies only allowed commands with matching arguments run, and
+// that a failing command reports its exit code rather than an error.
+func TestRunCommandAllowList(t *testing.T) {
+	configureTestCommands(t, time.Second, 0)
+	ctx := context.Background()
+
+	content, err := RunCommand(ctx, map[string]any{"command": "sh", "args": []any{"-c", "echo hello"}})
+	if err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["stdout"] != "hello\n" || payload["exitCode"] != float64(0) || payload["timedOut"] != false {
+		t.Fatalf("unexpected result %+v", payload)
+	}
+	content, err = RunCommand(ctx, map[string]any{"command": "sh", "args": []any{"-c", "exit 3"}})
+	if err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["exitCode"] != float64(3) {
+		t.Fatalf("expected exit code 3, got %+v", payload)
+	}
+
+	for _, args := range []map[string]any{
+		{"command": "bash", "args": []any{"-c", "echo hi"}},
+		{"command": "sh", "args": []any{"-c", "echo hi; rm -rf /"}},
+		{"command": "sh", "args": []any{"-c", "echo HI"}},
+		{"command": "sh", "args": []any{"-c", 1}},
+		{"command": "sh", "args": "-c echo hi"},
+	} {
+		if _, err := RunCommand(ctx, args); err == nil {
+			t.Fatalf("expected %v to be refused", args)
+		}
+	}
+
+	defs := CommandToolDefinitions()
+	if len(defs) != 1 || !defs[0].RequiresApproval || !strings.Contains(defs[0].Description, "- sh") {
+		t.Fatalf("unexpected definitions %+v", defs)
+	}
+	if CommandToolHandler(RunCommandName) == nil || CommandToolHandler(ReadFileName) != nil {
+		t.Fatalf("expected a handler for run_command only")
+	}
+}
+
+// TestRunCommandLimits verifies commands are stopped at their timeout and their output is
+// capped.
+func TestRunCommandLimits(t *testing.T) {
+	configureTestCommands(t, 100*time.Millisecond, 10)
+	ctx := context.Background()
+
+	start := time.Now()
+	content, err := RunCommand(ctx, map[string]any{"command": "sh", "args": []any{"-c", "sleep 5"}})
+	if err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["timedOut"] != true || time.Since(start) > 3*time.Second {
+		t.Fatalf("expected the command to time out, got %+v after %s", payload, time.Since(start))
+	}
+
+	content, err = RunCommand(ctx, map[string]any{"command": "sh", "args": []any{"-c", "printf x%.0s $(seq 1 100)"}})
+	if err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["stdout"] != strings.Repeat("x", 10) || payload["truncated"] != true {
+		t.Fatalf("expected capped output, got %+v", payload)
+	}
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -16,6 +17,20 @@ func CurrentTimeDefinition() Definition {
 			"type":       "object",
 			"properties": map[string]any{},
 		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"local_time": map[string]any{"type": "string", "description": "Local time in RFC 3339 format"},
+				"timezone":   map[string]any{"type": "string"},
+				"unix":       map[string]any{"type": "integer"},
+			},
+			"required": []string{"local_time", "timezone", "unix"},
+		},
+		Examples: []Example{{
+			Request:   "What time is it?",
+			Arguments: map[string]any{},
+			Result:    "The local time in RFC 3339 format with the timezone name.",
+		}},
 	}
 }
 
@@ -28,7 +43,7 @@ func CurrentTimeTool() Tool {
 }
 
 // CurrentTime returns the current system time as JSON for interpretation by the LLM.
-func CurrentTime(args map[string]any) ([]ContentPart, error) {
+func CurrentTime(ctx context.Context, args map[string]any) ([]ContentPart, error) {
 	now := time.Now()
 	payload := map[string]any{
 		"local_time": now.Format(time.RFC3339),
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -102,9 +103,34 @@ func CurrentWeatherDefinition() Definition {
 			},
 			"required": []string{"location"},
 		},
+		OutputSchema: parsedWeatherSchema(),
+		Examples: []Example{
+			{
+				Request:   "What's the weather like in Paris right now?",
+				Arguments: map[string]any{"location": "Paris, France"},
+				Result:    "Current temperature, wind speed and conditions for Paris, France.",
+			},
+			{
+				Request:   "Is it raining in Portland, Oregon?",
+				Arguments: map[string]any{"location": "Portland, OR"},
+				Result:    "Current conditions for Portland, OR, including precipitation.",
+			},
+		},
 	}
 }
 
+// parsedWeatherSchema describes the ParsedWeather object returned as structured content.
+func parsedWeatherSchema() map[string]any {
+	properties := map[string]any{"IsDay": map[string]any{"type": "boolean"}}
+	for _, name := range []string{
+		"Timezone", "Temperature", "RelativeHumidity", "Precipitation", "CloudCover", "WindSpeed10M",
+		"ApparentTemperature", "Low", "High", "Sunrise", "Sunset", "TotalPrecipitation",
+	} {
+		properties[name] = map[string]any{"type": "string"}
+	}
+	return map[string]any{"type": "object", "properties": properties}
+}
+
 // CurrentWeatherTool returns the complete, wrapped tool definition.
 func CurrentWeatherTool() Tool {
 	return Tool{
@@ -157,7 +183,7 @@ func ValidateCurrentWeatherData(jsonString string) (bool, error) {
 }
 
 // CurrentWeather executes the weather lookup workflow and returns JSON content for the LLM to interpret.
-func CurrentWeather(args map[string]any) ([]ContentPart, error) {
+func CurrentWeather(ctx context.Context, args map[string]any) ([]ContentPart, error) {
 	locationVal, ok := args["location"]
 	if !ok {
 		return nil, fmt.Errorf("Error: 'location' argument is required.")
@@ -170,7 +196,7 @@ func CurrentWeather(args map[string]any) ([]ContentPart, error) {
 		return nil, fmt.Errorf("Error: 'location' argument cannot be empty.")
 	}
 
-	weather, err := getGeocodedWeather(location)
+	weather, err := getGeocodedWeather(ctx, location)
 	if err != nil {
 		return nil, fmt.Errorf("Error fetching weather: %v", err)
 	}
@@ -198,68 +224,86 @@ func CurrentWeather(args map[string]any) ([]ContentPart, error) {
 	}, nil
 }
 
-func getGeocodedWeather(location string) (openMeteoResponse, error) {
-	geoURL := fmt.Sprintf("https://nominatim.openstreetmap.org/search?q=%s&format=jsonv2&limit=1", url.QueryEscape(location))
-
-	req, err := http.NewRequest("GET", geoURL, nil)
+func getGeocodedWeather(ctx context.Context, location string) (openMeteoResponse, error) {
+	lat, lon, err := geocodeLocation(ctx, location)
 	if err != nil {
-		return openMeteoResponse{}, fmt.Errorf("failed to create geocoding request: %v", err)
+		return openMeteoResponse{}, err
 	}
-	req.Header.Set("User-Agent", "mcp-weather-tool/1.0 (dev)")
 
+	weatherURL := fmt.Sprintf(
+		"https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,temperature_2m_min,sunrise,sunset,precipitation_sum&current=temperature_2m,relative_humidity_2m,is_day,precipitation,cloud_cover,wind_speed_10m,apparent_temperature&timezone=auto&forecast_days=1&wind_speed_unit=mph&temperature_unit=fahrenheit",
+		lat, lon,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, weatherURL, nil)
+	if err != nil {
+		return openMeteoResponse{}, fmt.Errorf("failed to create weather request: %v", err)
+	}
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return openMeteoResponse{}, fmt.Errorf("geocoding request failed: %v", err)
+		return openMeteoResponse{}, fmt.Errorf("weather request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return openMeteoResponse{}, fmt.Errorf("geocoding service returned status: %s", resp.Status)
+		return openMeteoResponse{}, fmt.Errorf("weather service returned status: %s", resp.Status)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return openMeteoResponse{}, fmt.Errorf("failed to read geocoding response: %v", err)
+		return openMeteoResponse{}, fmt.Errorf("failed to read weather response: %v", err)
 	}
 
-	var geoResp []nominatimResponse
-	if err := json.Unmarshal(body, &geoResp); err != nil {
-		return openMeteoResponse{}, fmt.Errorf("failed to parse geocoding JSON: %v", err)
+	var weatherResp openMeteoResponse
+	if err := json.Unmarshal(body, &weatherResp); err != nil {
+		return openMeteoResponse{}, fmt.Errorf("failed to parse weather JSON: %v", err)
 	}
 
-	if len(geoResp) == 0 {
-		return openMeteoResponse{}, fmt.Errorf("location not found: '%s'", location)
+	return weatherResp, nil
+}
+
+// geocodeLocation resolves a location to coordinates, consulting the geocode cache first.
+func geocodeLocation(ctx context.Context, location string) (string, string, error) {
+	if lat, lon, ok := geocoder.get(location); ok {
+		return lat, lon, nil
 	}
 
-	lat := geoResp[0].Lat
-	lon := geoResp[0].Lon
+	geoURL := fmt.Sprintf("https://nominatim.openstreetmap.org/search?q=%s&format=jsonv2&limit=1", url.QueryEscape(location))
 
-	weatherURL := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,temperature_2m_min,sunrise,sunset,precipitation_sum&current=temperature_2m,relative_humidity_2m,is_day,precipitation,cloud_cover,wind_speed_10m,apparent_temperature&timezone=auto&forecast_days=1&wind_speed_unit=mph&temperature_unit=fahrenheit",
-		lat, lon,
-	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, geoURL, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create geocoding request: %v", err)
+	}
+	req.Header.Set("User-Agent", "mcp-weather-tool/1.0 (dev)")
 
-	resp, err = httpClient.Get(weatherURL)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return openMeteoResponse{}, fmt.Errorf("weather request failed: %v", err)
+		return "", "", fmt.Errorf("geocoding request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return openMeteoResponse{}, fmt.Errorf("weather service returned status: %s", resp.Status)
+		return "", "", fmt.Errorf("geocoding service returned status: %s", resp.Status)
 	}
 
-	body, err = io.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return openMeteoResponse{}, fmt.Errorf("failed to read weather response: %v", err)
+		return "", "", fmt.Errorf("failed to read geocoding response: %v", err)
 	}
 
-	var weatherResp openMeteoResponse
-	if err := json.Unmarshal(body, &weatherResp); err != nil {
-		return openMeteoResponse{}, fmt.Errorf("failed to parse weather JSON: %v", err)
+	var geoResp []nominatimResponse
+	if err := json.Unmarshal(body, &geoResp); err != nil {
+		return "", "", fmt.Errorf("failed to parse geocoding JSON: %v", err)
 	}
 
-	return weatherResp, nil
+	if len(geoResp) == 0 {
+		return "", "", fmt.Errorf("location not found: '%s'", location)
+	}
+
+	lat := geoResp[0].Lat
+	lon := geoResp[0].Lon
+	geocoder.put(location, lat, lon)
+	return lat, lon, nil
 }
 
 // NewParsedWeather is a "constructor" function that transforms the raw
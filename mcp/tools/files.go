@@ -0,0 +1,413 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ReadFileName is the canonical name for the file-reading tool.
+	ReadFileName = "read_file"
+	// ListDirectoryName is the canonical name for the directory-listing tool.
+	ListDirectoryName = "list_directory"
+	// WriteFileName is the canonical name for the file-writing tool.
+	WriteFileName = "write_file"
+
+	// maxDirectoryEntries caps how many entries list_directory returns.
+	maxDirectoryEntries = 500
+)
+
+// FileToolsOptions configures the file-system tools. Each tool is enabled on its own,
+// and every path a tool touches must resolve inside one of Roots.
+type FileToolsOptions struct {
+	Roots         []string
+	ReadFile      bool
+	ListDirectory bool
+	WriteFile     bool
+	MaxReadBytes  int
+	MaxWriteBytes int
+}
+
+// fileRoot is an allow-listed directory, as configured and with symlinks resolved.
+type fileRoot struct {
+	abs  string
+	real string
+}
+
+// fileSandbox confines the file-system tools to their roots.
+type fileSandbox struct {
+	roots    []fileRoot
+	enabled  map[string]bool
+	maxRead  int
+	maxWrite int
+}
+
+// sandbox is nil until ConfigureFileTools enables at least one file tool.
+var sandbox *fileSandbox
+
+// ConfigureFileTools enables the file-system tools opts turns on. Roots must be existing
+// directories; relative tool paths are resolved against the first one.
+func ConfigureFileTools(opts FileToolsOptions) error {
+	enabled := map[string]bool{
+		ReadFileName:      opts.ReadFile,
+		ListDirectoryName: opts.ListDirectory,
+		WriteFileName:     opts.WriteFile,
+	}
+	if !opts.ReadFile && !opts.ListDirectory && !opts.WriteFile {
+		sandbox = nil
+		return nil
+	}
+	if len(opts.Roots) == 0 {
+		return errors.New("file tools need at least one root")
+	}
+	s := &fileSandbox{enabled: enabled, maxRead: opts.MaxReadBytes, maxWrite: opts.MaxWriteBytes}
+	for _, root := range opts.Roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf("file tool root %q: %w", root, err)
+		}
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return fmt.Errorf("file tool root %q: %w", root, err)
+		}
+		info, err := os.Stat(real)
+		if err != nil {
+			return fmt.Errorf("file tool root %q: %w", root, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("file tool root %q is not a directory", root)
+		}
+		s.roots = append(s.roots, fileRoot{abs: abs, real: real})
+	}
+	sandbox = s
+	return nil
+}
+
+// FileToolDefinitions describes the enabled file-system tools.
+func FileToolDefinitions() []Definition {
+	if sandbox == nil {
+		return nil
+	}
+	var definitions []Definition
+	for _, def := range []Definition{ReadFileDefinition(), ListDirectoryDefinition(), WriteFileDefinition()} {
+		if sandbox.enabled[def.Name] {
+			definitions = append(definitions, def)
+		}
+	}
+	return definitions
+}
+
+// FileToolHandler returns the handler for the named file-system tool, or nil when it is
+// not a file tool or is disabled.
+func FileToolHandler(name string) Handler {
+	if sandbox == nil || !sandbox.enabled[name] {
+		return nil
+	}
+	switch name {
+	case ReadFileName:
+		return ReadFile
+	case ListDirectoryName:
+		return ListDirectory
+	case WriteFileName:
+		return WriteFile
+	default:
+		return nil
+	}
+}
+
+// ReadFileDefinition describes the file-reading tool.
+func ReadFileDefinition() Definition {
+	return Definition{
+		Name:        ReadFileName,
+		Description: "Read a UTF-8 text file from the project. Long files are truncated.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string", "description": "File path, absolute or relative to the project root"},
+			},
+			"required": []string{"path"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path":      map[string]any{"type": "string"},
+				"size":      map[string]any{"type": "integer", "description": "File size in bytes"},
+				"truncated": map[string]any{"type": "boolean"},
+				"content":   map[string]any{"type": "string"},
+			},
+			"required": []string{"path", "size", "truncated", "content"},
+		},
+		Examples: []Example{{
+			Request:   "What does the README say about installation?",
+			Arguments: map[string]any{"path": "README.md"},
+			Result:    "The file's text, truncated if it is longer than the read limit.",
+		}},
+	}
+}
+
+// ListDirectoryDefinition describes the directory-listing tool.
+func ListDirectoryDefinition() Definition {
+	return Definition{
+		Name:        ListDirectoryName,
+		Description: "List the files and directories in a project directory.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string", "description": "Directory path, absolute or relative to the project root; empty lists the root"},
+			},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string"},
+				"entries": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name": map[string]any{"type": "string"},
+							"type": map[string]any{"type": "string", "enum": []string{"file", "directory", "symlink", "other"}},
+							"size": map[string]any{"type": "integer"},
+						},
+					},
+				},
+				"truncated": map[string]any{"type": "boolean"},
+			},
+			"required": []string{"path", "entries", "truncated"},
+		},
+		Examples: []Example{{
+			Request:   "Which files are in the cmd directory?",
+			Arguments: map[string]any{"path": "cmd"},
+			Result:    "The name, type and size of each entry in the directory.",
+		}},
+	}
+}
+
+// WriteFileDefinition describes the file-writing tool.
+func WriteFileDefinition() Definition {
+	return Definition{
+		Name:        WriteFileName,
+		Description: "Create or overwrite a text file in the project. The parent directory must already exist.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string", "description": "File path, absolute or relative to the project root"},
+				"content": map[string]any{"type": "string", "description": "The complete new file content"},
+			},
+			"required": []string{"path", "content"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path":         map[string]any{"type": "string"},
+				"bytesWritten": map[string]any{"type": "integer"},
+			},
+			"required": []string{"path", "bytesWritten"},
+		},
+		Examples: []Example{{
+			Request:   "Save these notes to notes.md",
+			Arguments: map[string]any{"path": "notes.md", "content": "# Notes\n"},
+			Result:    "The path written and the number of bytes written.",
+		}},
+	}
+}
+
+// ReadFile returns the start of a text file inside the sandbox, up to the read limit.
+func ReadFile(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if sandbox == nil {
+		return nil, errors.New("file tools are disabled")
+	}
+	path, err := sandbox.resolve(stringArgument(args, "path"), false)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory; use %s instead", path, ListDirectoryName)
+	}
+
+	var reader io.Reader = file
+	if sandbox.maxRead > 0 {
+		reader = io.LimitReader(file, int64(sandbox.maxRead)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	truncated := sandbox.maxRead > 0 && len(data) > sandbox.maxRead
+	if truncated {
+		data = data[:sandbox.maxRead]
+		// Drop a rune the limit cut in half.
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%s is not a UTF-8 text file", path)
+	}
+	return jsonContent(map[string]any{
+		"path":      path,
+		"size":      info.Size(),
+		"truncated": truncated,
+		"content":   string(data),
+	})
+}
+
+// ListDirectory lists a directory inside the sandbox, defaulting to the first root.
+func ListDirectory(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if sandbox == nil {
+		return nil, errors.New("file tools are disabled")
+	}
+	path := stringArgument(args, "path")
+	if strings.TrimSpace(path) == "" {
+		path = sandbox.roots[0].real
+	}
+	path, err := sandbox.resolve(path, false)
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %w", path, err)
+	}
+
+	truncated := len(dirEntries) > maxDirectoryEntries
+	if truncated {
+		dirEntries = dirEntries[:maxDirectoryEntries]
+	}
+	entries := make([]map[string]any, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		item := map[string]any{"name": entry.Name(), "type": entryType(entry.Type())}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				item["size"] = info.Size()
+			}
+		}
+		entries = append(entries, item)
+	}
+	return jsonContent(map[string]any{"path": path, "entries": entries, "truncated": truncated})
+}
+
+// WriteFile creates or replaces a file inside the sandbox with content up to the write limit.
+func WriteFile(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if sandbox == nil {
+		return nil, errors.New("file tools are disabled")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return nil, errors.New("content must be a string")
+	}
+	if sandbox.maxWrite > 0 && len(content) > sandbox.maxWrite {
+		return nil, fmt.Errorf("content is %d bytes, over the %d byte write limit", len(content), sandbox.maxWrite)
+	}
+	path, err := sandbox.resolve(stringArgument(args, "path"), true)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	return jsonContent(map[string]any{"path": path, "bytesWritten": len(content)})
+}
+
+// resolve turns a tool path into an absolute path inside a root. The path is checked
+// before symlinks are followed, so ".." cannot probe outside the roots, and again after,
+// so a symlink inside a root cannot lead out of it. A file being written need not exist
+// yet, so only its parent directory is resolved.
+func (s *fileSandbox) resolve(path string, forWrite bool) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", errors.New("path is required")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.roots[0].real, path)
+	}
+	path = filepath.Clean(path)
+	if !s.contains(path, true) {
+		return "", fmt.Errorf("path %s is outside the allowed roots", path)
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if forWrite && errors.Is(err, os.ErrNotExist) {
+		// A dangling symlink would be followed by the write, wherever it points.
+		if _, lerr := os.Lstat(path); lerr == nil {
+			return "", fmt.Errorf("%s is a broken symlink", path)
+		}
+		var dir string
+		dir, err = filepath.EvalSymlinks(filepath.Dir(path))
+		real = filepath.Join(dir, filepath.Base(path))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", path, err)
+	}
+	if !s.contains(real, false) {
+		return "", fmt.Errorf("path %s is outside the allowed roots", path)
+	}
+	return real, nil
+}
+
+// contains reports whether path is a root or lies beneath one. Lexical checks also
+// accept the roots as configured, before their own symlinks were resolved.
+func (s *fileSandbox) contains(path string, lexical bool) bool {
+	for _, root := range s.roots {
+		if within(root.real, path) || (lexical && within(root.abs, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is root or a descendant of it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// entryType names the kind of a directory entry.
+func entryType(mode os.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "directory"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "other"
+	}
+}
+
+// stringArgument returns the named argument when it is a string.
+func stringArgument(args map[string]any, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// jsonContent returns payload as a tool's JSON content part.
+func jsonContent(payload map[string]any) ([]ContentPart, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing tool response: %w", err)
+	}
+	return []ContentPart{{Type: "json", Text: string(data)}}, nil
+}
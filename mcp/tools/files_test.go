@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configureTestSandbox enables every file tool on a fresh root holding notes.txt and a
+// sibling directory outside it holding secret.txt.
+func configureTestSandbox(t *testing.T, maxRead, maxWrite int) (root, outside string) {
+	t.Helper()
+	base := t.TempDir()
+	root = filepath.Join(base, "project")
+	outside = filepath.Join(base, "private")
+	for _, dir := range []string{filepath.Join(root, "docs"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("héllo world"), 0o644); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	err := ConfigureFileTools(FileToolsOptions{Roots: []string{root}, ReadFile: true, ListDirectory: true, WriteFile: true, MaxReadBytes: maxRead, MaxWriteBytes: maxWrite})
+	if err != nil {
+		t.Fatalf("ConfigureFileTools returned error: %v", err)
+	}
+	t.Cleanup(func() { sandbox = nil })
+	return root, outside
+}
+
+// decodeFileResult decodes a file tool's JSON content part.
+func decodeFileResult(t *testing.T, content []ContentPart) map[string]any {
+	t.Helper()
+	var payload map[string]any
+	if len(content) != 1 || json.Unmarshal([]byte(content[0].Text), &payload) != nil {
+		t.Fatalf("unexpected content %+v", content)
+	}
+	return payload
+}
+
+// TestFileToolsStayInsideRoots verifies traversal and symlinks cannot reach files
+// outside the roots, for reads, listings and writes alike.
+func TestFileToolsStayInsideRoots(t *testing.T) {
+	root, outside := configureTestSandbox(t, 0, 0)
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, path := range []string{"../private/secret.txt", filepath.Join(outside, "secret.txt"), "escape/secret.txt", "docs/../../private/secret.txt"} {
+		if _, err := ReadFile(ctx, map[string]any{"path": path}); err == nil || !strings.Contains(err.Error(), "outside the allowed roots") {
+			t.Fatalf("expected reading %s to be refused, got %v", path, err)
+		}
+	}
+	if _, err := ListDirectory(ctx, map[string]any{"path": "escape"}); err == nil {
+		t.Fatalf("expected listing through a symlink out of the root to be refused")
+	}
+	for _, path := range []string{"escape/new.txt", "dangling", "../private/new.txt"} {
+		if _, err := WriteFile(ctx, map[string]any{"path": path, "content": "x"}); err == nil {
+			t.Fatalf("expected writing %s to be refused", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be written outside the root, got %v", err)
+	}
+
+	content, err := ReadFile(ctx, map[string]any{"path": "docs/../notes.txt"})
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["content"] != "héllo world" {
+		t.Fatalf("unexpected read result %+v", payload)
+	}
+	content, err = ListDirectory(ctx, map[string]any{})
+	if err != nil {
+		t.Fatalf("ListDirectory returned error: %v", err)
+	}
+	if entries := decodeFileResult(t, content)["entries"].([]any); len(entries) != 4 {
+		t.Fatalf("expected the root's four entries, got %+v", entries)
+	}
+}
+
+// TestFileToolsLimits verifies reads are truncated on a rune boundary and oversized
+// writes are refused.
+func TestFileToolsLimits(t *testing.T) {
+	root, _ := configureTestSandbox(t, 2, 4)
+	ctx := context.Background()
+
+	content, err := ReadFile(ctx, map[string]any{"path": "notes.txt"})
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["content"] != "h" || payload["truncated"] != true || payload["size"] != float64(12) {
+		t.Fatalf("expected a truncated read, got %+v", payload)
+	}
+
+	if _, err := WriteFile(ctx, map[string]any{"path": "big.txt", "content": "12345"}); err == nil {
+		t.Fatalf("expected content over the write limit to be refused")
+	}
+	if _, err := WriteFile(ctx, map[string]any{"path": "docs/ok.txt", "content": "1234"}); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "docs", "ok.txt")); err != nil || string(data) != "1234" {
+		t.Fatalf("unexpected written file %q (%v)", data, err)
+	}
+}
+
+// TestFileToolsEnableFlags verifies only enabled tools are advertised and handled.
+func TestFileToolsEnableFlags(t *testing.T) {
+	t.Cleanup(func() { sandbox = nil })
+	if err := ConfigureFileTools(FileToolsOptions{Roots: []string{t.TempDir()}, ReadFile: true}); err != nil {
+		t.Fatalf("ConfigureFileTools returned error: %v", err)
+	}
+	if defs := FileToolDefinitions(); len(defs) != 1 || defs[0].Name != ReadFileName {
+		t.Fatalf("expected only read_file to be advertised, got %+v", defs)
+	}
+	if FileToolHandler(ReadFileName) == nil || FileToolHandler(WriteFileName) != nil || FileToolHandler(CurrentTimeName) != nil {
+		t.Fatalf("expected only read_file to have a handler")
+	}
+	if err := ConfigureFileTools(FileToolsOptions{ReadFile: true}); err == nil {
+		t.Fatalf("expected enabled file tools without a root to fail")
+	}
+	if err := ConfigureFileTools(FileToolsOptions{Roots: []string{filepath.Join(t.TempDir(), "missing")}, WriteFile: true}); err == nil {
+		t.Fatalf("expected a missing root to fail")
+	}
+}
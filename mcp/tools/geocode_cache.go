@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwiater/agon/internal/appconfig"
+)
+
+// geocodeEntry records resolved coordinates for a normalized location.
+type geocodeEntry struct {
+	Key    string    `json:"key"`
+	Lat    string    `json:"lat"`
+	Lon    string    `json:"lon"`
+	Stored time.Time `json:"stored"`
+}
+
+// geocodeCache is an LRU of geocoding results with expiry and optional on-disk persistence,
+// so repeated weather lookups skip the rate-limited Nominatim round-trip.
+type geocodeCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	path     string
+	order    *list.List
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+// geocoder is the process-wide cache used by the weather tool.
+var geocoder = newGeocodeCache(appconfig.DefaultGeocodeCacheSize, appconfig.DefaultGeocodeCacheTTL, "")
+
+// newGeocodeCache creates an empty cache; a non-positive capacity or ttl disables caching.
+func newGeocodeCache(capacity int, ttl time.Duration, path string) *geocodeCache {
+	return &geocodeCache{
+		capacity: capacity,
+		ttl:      ttl,
+		path:     path,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// ConfigureGeocodeCache replaces the weather tool's geocoding cache. When path is set,
+// previously persisted entries are loaded from it and every new entry is written back.
+func ConfigureGeocodeCache(capacity int, ttl time.Duration, path string) error {
+	cache := newGeocodeCache(capacity, ttl, path)
+	if err := cache.load(); err != nil {
+		return err
+	}
+	geocoder = cache
+	return nil
+}
+
+// normalizeLocation folds case and whitespace so "San Francisco,CA" and
+// " san francisco, ca " share a cache entry.
+func normalizeLocation(location string) string {
+	parts := strings.Split(strings.ToLower(location), ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.Trim(strings.Join(parts, ","), ", ")
+}
+
+// get returns cached coordinates for location if present and not expired.
+func (c *geocodeCache) get(location string) (string, string, bool) {
+	if c.capacity <= 0 || c.ttl <= 0 {
+		return "", "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[normalizeLocation(location)]
+	if !ok {
+		return "", "", false
+	}
+	entry := elem.Value.(geocodeEntry)
+	if c.now().Sub(entry.Stored) > c.ttl {
+		c.order.Remove(elem)
+		delete(c.entries, entry.Key)
+		return "", "", false
+	}
+	c.order.MoveToFront(elem)
+	return entry.Lat, entry.Lon, true
+}
+
+// put stores coordinates for location, evicting the least recently used entry when full.
+func (c *geocodeCache) put(location, lat, lon string) {
+	if c.capacity <= 0 || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.insert(geocodeEntry{Key: normalizeLocation(location), Lat: lat, Lon: lon, Stored: c.now()})
+	if err := c.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "geocode cache: %v\n", err)
+	}
+}
+
+// insert adds or refreshes an entry; the caller must hold c.mu.
+func (c *geocodeCache) insert(entry geocodeEntry) {
+	if elem, ok := c.entries[entry.Key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.Key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(geocodeEntry).Key)
+	}
+}
+
+// load reads persisted entries, skipping expired ones. A missing file is not an error.
+func (c *geocodeCache) load() error {
+	if c.path == "" || c.capacity <= 0 || c.ttl <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("unable to read geocode cache %s: %w", c.path, err)
+	}
+	var stored []geocodeEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("unable to parse geocode cache %s: %w", c.path, err)
+	}
+	// Entries are persisted most recent first; insert oldest first to preserve that order.
+	for i := len(stored) - 1; i >= 0; i-- {
+		if c.now().Sub(stored[i].Stored) <= c.ttl {
+			c.insert(stored[i])
+		}
+	}
+	return nil
+}
+
+// save writes the cache to disk, most recently used first; the caller must hold c.mu.
+func (c *geocodeCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+	stored := make([]geocodeEntry, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		stored = append(stored, elem.Value.(geocodeEntry))
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("unable to create geocode cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("unable to write geocode cache %s: %w", c.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestGeocodeCacheNormalizesEvictsAndExpires verifies lookups share entries across
+// spelling variations, the least recently used entry is evicted, and entries expire.
+func TestGeocodeCacheNormalizesEvictsAndExpires(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newGeocodeCache(2, time.Hour, "")
+	cache.now = func() time.Time { return now }
+
+	cache.put("San Francisco,CA", "37.7", "-122.4")
+	if lat, lon, ok := cache.get("  san   francisco, ca "); !ok || lat != "37.7" || lon != "-122.4" {
+		t.Fatalf("expected normalized hit, got %q %q %v", lat, lon, ok)
+	}
+
+	cache.put("Paris, France", "48.8", "2.3")
+	cache.get("San Francisco, CA")
+	cache.put("Tokyo", "35.6", "139.6")
+	if _, _, ok := cache.get("Paris, France"); ok {
+		t.Fatalf("expected least recently used entry to be evicted")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, _, ok := cache.get("Tokyo"); ok {
+		t.Fatalf("expected entry to expire after the TTL")
+	}
+}
+
+// TestGeocodeCachePersists verifies entries written to disk are reloaded by a new cache.
+func TestGeocodeCachePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agonData", "geocode-cache.json")
+	first := newGeocodeCache(4, time.Hour, path)
+	first.put("Berlin", "52.5", "13.4")
+
+	second := newGeocodeCache(4, time.Hour, path)
+	if err := second.load(); err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+	if lat, _, ok := second.get("berlin"); !ok || lat != "52.5" {
+		t.Fatalf("expected persisted entry, got %q %v", lat, ok)
+	}
+}
@@ -1,10 +1,26 @@
 package tools
 
-// Definition describes the metadata the MCP server exposes for a tool.
+import "context"
+
+// Definition describes the metadata the MCP server exposes for a tool. OutputSchema, when
+// set, describes the structuredContent object returned alongside the tool's text content.
+// Examples are sample calls clients may show models as few-shot hints. RequiresApproval
+// asks clients to have the user confirm every call before making it.
 type Definition struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Parameters  map[string]any `json:"parameters"`
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	Parameters       map[string]any `json:"parameters"`
+	OutputSchema     map[string]any `json:"outputSchema,omitempty"`
+	Examples         []Example      `json:"examples,omitempty"`
+	RequiresApproval bool           `json:"requiresApproval,omitempty"`
+}
+
+// Example is a sample call of a tool: the arguments a model should send for a request and
+// a summary of the result it gets back.
+type Example struct {
+	Request   string         `json:"request,omitempty"`
+	Arguments map[string]any `json:"arguments"`
+	Result    string         `json:"result,omitempty"`
 }
 
 // Tool wraps a Definition to match the required "function" wrapper structure.
@@ -20,7 +36,8 @@ type ContentPart struct {
 }
 
 // Handler executes a tool using the provided arguments and returns content for the LLM.
-type Handler func(map[string]any) ([]ContentPart, error)
+// Handlers that do I/O stop when ctx is cancelled.
+type Handler func(ctx context.Context, args map[string]any) ([]ContentPart, error)
 
 const (
 	// CurrentWeatherName is the canonical name for the weather tool.
@@ -29,4 +46,8 @@ const (
 	CurrentTimeName = "current_time"
 	// AvailableToolsName is the canonical name for the available-tools helper.
 	AvailableToolsName = "available_tools"
+	// CalculateName is the canonical name for the calculator tool.
+	CalculateName = "calculate"
+	// ConvertUnitsName is the canonical name for the unit-conversion tool.
+	ConvertUnitsName = "convert_units"
 )
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// unit is a unit of measure: its category and how many of the category's base unit one
+// of it is. Temperatures also carry an offset, since their scales do not share a zero.
+type unit struct {
+	name     string
+	category string
+	factor   float64
+	offset   float64
+}
+
+// unitTable lists the units convert_units knows, each with the names it is known by.
+// Lookups try a name as written first, so "Mb" (megabit) and "MB" (megabyte) differ,
+// and then in lower case.
+var unitTable = []struct {
+	names    []string
+	category string
+	factor   float64
+	offset   float64
+}{
+	// Length, in metres.
+	{[]string{"m", "meter", "meters", "metre", "metres"}, "length", 1, 0},
+	{[]string{"km", "kilometer", "kilometers", "kilometre", "kilometres"}, "length", 1000, 0},
+	{[]string{"cm", "centimeter", "centimeters", "centimetre", "centimetres"}, "length", 0.01, 0},
+	{[]string{"mm", "millimeter", "millimeters", "millimetre", "millimetres"}, "length", 0.001, 0},
+	{[]string{"um", "µm", "micrometer", "micrometers", "micron", "microns"}, "length", 1e-6, 0},
+	{[]string{"nm", "nanometer", "nanometers", "nanometre", "nanometres"}, "length", 1e-9, 0},
+	{[]string{"mi", "mile", "miles"}, "length", 1609.344, 0},
+	{[]string{"yd", "yard", "yards"}, "length", 0.9144, 0},
+	{[]string{"ft", "foot", "feet"}, "length", 0.3048, 0},
+	{[]string{"in", "inch", "inches"}, "length", 0.0254, 0},
+	{[]string{"nmi", "nautical mile", "nautical miles"}, "length", 1852, 0},
+	// Mass, in kilograms.
+	{[]string{"kg", "kilogram", "kilograms"}, "mass", 1, 0},
+	{[]string{"g", "gram", "grams"}, "mass", 0.001, 0},
+	{[]string{"mg", "milligram", "milligrams"}, "mass", 1e-6, 0},
+	{[]string{"µg", "ug", "mcg", "microgram", "micrograms"}, "mass", 1e-9, 0},
+	{[]string{"t", "tonne", "tonnes", "metric ton", "metric tons"}, "mass", 1000, 0},
+	{[]string{"lb", "lbs", "pound", "pounds"}, "mass", 0.45359237, 0},
+	{[]string{"oz", "ounce", "ounces"}, "mass", 0.028349523125, 0},
+	{[]string{"st", "stone", "stones"}, "mass", 6.35029318, 0},
+	// Volume, in litres. Gallons, quarts, pints, cups and fluid ounces are US measures.
+	{[]string{"l", "liter", "liters", "litre", "litres"}, "volume", 1, 0},
+	{[]string{"ml", "milliliter", "milliliters", "millilitre", "millilitres"}, "volume", 0.001, 0},
+	{[]string{"cl", "centiliter", "centiliters", "centilitre", "centilitres"}, "volume", 0.01, 0},
+	{[]string{"m3", "cubic meter", "cubic meters", "cubic metre", "cubic metres"}, "volume", 1000, 0},
+	{[]string{"gal", "gallon", "gallons"}, "volume", 3.785411784, 0},
+	{[]string{"imperial gallon", "imperial gallons"}, "volume", 4.54609, 0},
+	{[]string{"qt", "quart", "quarts"}, "volume", 0.946352946, 0},
+	{[]string{"pt", "pint", "pints"}, "volume", 0.473176473, 0},
+	{[]string{"cup", "cups"}, "volume", 0.2365882365, 0},
+	{[]string{"floz", "fl oz", "fluid ounce", "fluid ounces"}, "volume", 0.0295735295625, 0},
+	{[]string{"tbsp", "tablespoon", "tablespoons"}, "volume", 0.01478676478125, 0},
+	{[]string{"tsp", "teaspoon", "teaspoons"}, "volume", 0.00492892159375, 0},
+	// Area, in square metres.
+	{[]string{"m2", "sq m", "square meter", "square meters", "square metre", "square metres"}, "area", 1, 0},
+	{[]string{"km2", "sq km", "square kilometer", "square kilometers", "square kilometre", "square kilometres"}, "area", 1e6, 0},
+	{[]string{"cm2", "sq cm", "square centimeter", "square centimeters", "square centimetre", "square centimetres"}, "area", 1e-4, 0},
+	{[]string{"ha", "hectare", "hectares"}, "area", 1e4, 0},
+	{[]string{"acre", "acres"}, "area", 4046.8564224, 0},
+	{[]string{"ft2", "sq ft", "square foot", "square feet"}, "area", 0.09290304, 0},
+	{[]string{"in2", "sq in", "square inch", "square inches"}, "area", 0.00064516, 0},
+	{[]string{"mi2", "sq mi", "square mile", "square miles"}, "area", 2589988.110336, 0},
+	// Time, in seconds. A year is a Julian year of 365.25 days.
+	{[]string{"ns", "nanosecond", "nanoseconds"}, "time", 1e-9, 0},
+	{[]string{"us", "µs", "microsecond", "microseconds"}, "time", 1e-6, 0},
+	{[]string{"ms", "millisecond", "milliseconds"}, "time", 1e-3, 0},
+	{[]string{"s", "sec", "secs", "second", "seconds"}, "time", 1, 0},
+	{[]string{"min", "mins", "minute", "minutes"}, "time", 60, 0},
+	{[]string{"h", "hr", "hrs", "hour", "hours"}, "time", 3600, 0},
+	{[]string{"d", "day", "days"}, "time", 86400, 0},
+	{[]string{"wk", "week", "weeks"}, "time", 604800, 0},
+	{[]string{"yr", "year", "years"}, "time", 31557600, 0},
+	// Speed, in metres per second.
+	{[]string{"m/s", "mps", "meters per second", "metres per second"}, "speed", 1, 0},
+	{[]string{"km/h", "kph", "kmh", "kilometers per hour", "kilometres per hour"}, "speed", 1000.0 / 3600, 0},
+	{[]string{"mph", "mi/h", "miles per hour"}, "speed", 0.44704, 0},
+	{[]string{"kn", "kt", "knot", "knots"}, "speed", 1852.0 / 3600, 0},
+	{[]string{"ft/s", "fps", "feet per second"}, "speed", 0.3048, 0},
+	// Temperature, in kelvin.
+	{[]string{"k", "kelvin"}, "temperature", 1, 0},
+	{[]string{"c", "°c", "celsius", "degc"}, "temperature", 1, 273.15},
+	{[]string{"f", "°f", "fahrenheit", "degf"}, "temperature", 5.0 / 9, 459.67},
+	// Pressure, in pascals.
+	{[]string{"pa", "pascal", "pascals"}, "pressure", 1, 0},
+	{[]string{"kpa", "kilopascal", "kilopascals"}, "pressure", 1000, 0},
+	{[]string{"bar", "bars"}, "pressure", 1e5, 0},
+	{[]string{"psi"}, "pressure", 6894.757293168361, 0},
+	{[]string{"atm", "atmosphere", "atmospheres"}, "pressure", 101325, 0},
+	{[]string{"mmhg"}, "pressure", 133.322387415, 0},
+	// Energy, in joules.
+	{[]string{"j", "joule", "joules"}, "energy", 1, 0},
+	{[]string{"kj", "kilojoule", "kilojoules"}, "energy", 1000, 0},
+	{[]string{"cal", "calorie", "calories"}, "energy", 4.184, 0},
+	{[]string{"kcal", "kilocalorie", "kilocalories"}, "energy", 4184, 0},
+	{[]string{"wh", "watt hour", "watt hours"}, "energy", 3600, 0},
+	{[]string{"kwh", "kilowatt hour", "kilowatt hours"}, "energy", 3.6e6, 0},
+	{[]string{"btu"}, "energy", 1055.05585262, 0},
+	// Digital storage, in bytes. Decimal prefixes are powers of 1000 and binary ones of 1024.
+	{[]string{"b", "bit", "bits"}, "data", 0.125, 0},
+	{[]string{"B", "byte", "bytes"}, "data", 1, 0},
+	{[]string{"KB", "kB", "kilobyte", "kilobytes"}, "data", 1e3, 0},
+	{[]string{"MB", "megabyte", "megabytes"}, "data", 1e6, 0},
+	{[]string{"GB", "gigabyte", "gigabytes"}, "data", 1e9, 0},
+	{[]string{"TB", "terabyte", "terabytes"}, "data", 1e12, 0},
+	{[]string{"KiB", "kibibyte", "kibibytes"}, "data", 1 << 10, 0},
+	{[]string{"MiB", "mebibyte", "mebibytes"}, "data", 1 << 20, 0},
+	{[]string{"GiB", "gibibyte", "gibibytes"}, "data", 1 << 30, 0},
+	{[]string{"TiB", "tebibyte", "tebibytes"}, "data", 1 << 40, 0},
+	{[]string{"Kb", "kb", "kilobit", "kilobits"}, "data", 125, 0},
+	{[]string{"Mb", "megabit", "megabits"}, "data", 1.25e5, 0},
+	{[]string{"Gb", "gigabit", "gigabits"}, "data", 1.25e8, 0},
+}
+
+// units indexes unitTable by every name, as written.
+var units = func() map[string]unit {
+	index := make(map[string]unit)
+	for _, entry := range unitTable {
+		for _, name := range entry.names {
+			index[name] = unit{name: entry.names[0], category: entry.category, factor: entry.factor, offset: entry.offset}
+		}
+	}
+	return index
+}()
+
+// lookupUnit finds a unit by name: as written, then in lower case, ignoring spaces
+// around it and reading ² and ³ as 2 and 3.
+func lookupUnit(name string) (unit, bool) {
+	name = strings.NewReplacer("²", "2", "³", "3", "^2", "2", "^3", "3").Replace(strings.TrimSpace(name))
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	u, ok := units[strings.ToLower(name)]
+	return u, ok
+}
+
+// ConvertUnitsDefinition describes the unit-conversion tool.
+func ConvertUnitsDefinition() Definition {
+	categories := make(map[string]bool)
+	for _, entry := range unitTable {
+		categories[entry.category] = true
+	}
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return Definition{
+		Name: ConvertUnitsName,
+		Description: "Convert a quantity between units of the same kind exactly, e.g. miles to kilometres or °F to °C. " +
+			"Supports " + strings.Join(names, ", ") + ". US gallons, pints and cups; MB is 10^6 bytes and MiB 2^20.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"value": map[string]any{"type": "number", "description": "The quantity to convert"},
+				"from":  map[string]any{"type": "string", "description": "The unit of value, e.g. mi, kg, °F, GiB"},
+				"to":    map[string]any{"type": "string", "description": "The unit to convert to"},
+			},
+			"required": []string{"value", "from", "to"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"value":    map[string]any{"type": "number"},
+				"from":     map[string]any{"type": "string"},
+				"to":       map[string]any{"type": "string"},
+				"result":   map[string]any{"type": "number"},
+				"category": map[string]any{"type": "string"},
+			},
+			"required": []string{"value", "from", "to", "result", "category"},
+		},
+		Examples: []Example{{
+			Request:   "How many kilometres is a marathon of 26.2 miles?",
+			Arguments: map[string]any{"value": 26.2, "from": "mi", "to": "km"},
+			Result:    "The converted quantity, 42.1648128 km.",
+		}},
+	}
+}
+
+// ConvertUnits converts a quantity between two units of the same category and returns
+// the result as JSON.
+func ConvertUnits(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	value, ok := numberArgument(args["value"])
+	if !ok {
+		return nil, errors.New("value must be a number")
+	}
+	fromName, toName := stringArgument(args, "from"), stringArgument(args, "to")
+	from, ok := lookupUnit(fromName)
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", fromName)
+	}
+	to, ok := lookupUnit(toName)
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", toName)
+	}
+	if from.category != to.category {
+		return nil, fmt.Errorf("cannot convert %s (%s) to %s (%s)", fromName, from.category, toName, to.category)
+	}
+	result := ((value+from.offset)*from.factor)/to.factor - to.offset
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, errors.New("the result is not a finite number")
+	}
+	return jsonContent(map[string]any{
+		"value":    value,
+		"from":     from.name,
+		"to":       to.name,
+		"result":   roundSignificant(result),
+		"category": from.category,
+	})
+}
+
+// numberArgument reads a numeric argument, accepting numbers sent as strings.
+func numberArgument(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// roundSignificant rounds v to 15 significant digits, dropping the binary noise of
+// results such as 0.1 + 0.2 without losing precision a model could use.
+func roundSignificant(v float64) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 15, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestConvertUnits verifies conversions within each category, including temperatures and
+// the decimal and binary data prefixes.
+func TestConvertUnits(t *testing.T) {
+	cases := []struct {
+		value    any
+		from, to string
+		want     float64
+		category string
+	}{
+		{26.2, "mi", "km", 42.1648128, "length"},
+		{12, "inches", "ft", 1, "length"},
+		{1, "kg", "lb", 2.20462262184878, "mass"},
+		{1, "gallon", "L", 3.785411784, "volume"},
+		{90, "minutes", "h", 1.5, "time"},
+		{100, "km/h", "mph", 62.1371192237334, "speed"},
+		{1, "acre", "m²", 4046.8564224, "area"},
+		{212, "°F", "C", 100, "temperature"},
+		{-40, "celsius", "fahrenheit", -40, "temperature"},
+		{0, "C", "K", 273.15, "temperature"},
+		{1, "GiB", "MB", 1073.741824, "data"},
+		{100, "Mb", "MB", 12.5, "data"},
+		{"1", "atm", "kPa", 101.325, "pressure"},
+		{1, "kWh", "kJ", 3600, "energy"},
+	}
+	for _, c := range cases {
+		content, err := ConvertUnits(context.Background(), map[string]any{"value": c.value, "from": c.from, "to": c.to})
+		if err != nil {
+			t.Fatalf("%v %s to %s returned error: %v", c.value, c.from, c.to, err)
+		}
+		payload := decodeFileResult(t, content)
+		result, _ := payload["result"].(float64)
+		if math.Abs(result-c.want) > 1e-9 || payload["category"] != c.category {
+			t.Fatalf("%v %s to %s = %+v, want %v (%s)", c.value, c.from, c.to, payload, c.want, c.category)
+		}
+	}
+}
+
+// TestConvertUnitsErrors verifies unknown units, mismatched categories and bad values fail.
+func TestConvertUnitsErrors(t *testing.T) {
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"value": 1.0, "from": "parsec", "to": "m"}, "unknown unit"},
+		{map[string]any{"value": 1.0, "from": "kg", "to": "m"}, "cannot convert"},
+		{map[string]any{"value": "many", "from": "kg", "to": "g"}, "must be a number"},
+		{map[string]any{"from": "kg", "to": "g"}, "must be a number"},
+	}
+	for _, c := range cases {
+		_, err := ConvertUnits(context.Background(), c.args)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("%+v: expected an error containing %q, got %v", c.args, c.want, err)
+		}
+	}
+}
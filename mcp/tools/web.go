@@ -0,0 +1,484 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// WebSearchName is the canonical name for the web search tool.
+	WebSearchName = "web_search"
+	// FetchURLName is the canonical name for the page-fetching tool.
+	FetchURLName = "fetch_url"
+
+	// BraveAPIKeyEnv names the environment variable the Brave Search token is read from.
+	BraveAPIKeyEnv = "BRAVE_API_KEY"
+	// braveSearchURL is the Brave Search API endpoint.
+	braveSearchURL = "https://api.search.brave.com/res/v1/web/search"
+	// maxSearchResults caps the results a model may ask web_search for.
+	maxSearchResults = 20
+	// maxRedirects caps the redirects fetch_url follows.
+	maxRedirects = 5
+)
+
+// SearchResult is one web search hit.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchBackend answers web_search queries.
+type SearchBackend interface {
+	// Search returns up to limit results for query.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
+
+// WebToolsOptions configures the web tools. web_search is enabled when Search is set and
+// fetch_url when Fetch is; MaxResults, MaxFetchBytes and FetchTimeout bound their output.
+type WebToolsOptions struct {
+	Search               SearchBackend
+	MaxResults           int
+	Fetch                bool
+	MaxFetchBytes        int
+	FetchTimeout         time.Duration
+	AllowPrivateNetworks bool
+}
+
+// webTools holds the configured web tools, or nil when none are enabled.
+var webTools *WebToolsOptions
+
+// fetchClient downloads pages for fetch_url; ConfigureWebTools rebuilds it.
+var fetchClient = newFetchClient(false)
+
+// ConfigureWebTools enables the web tools opts turns on.
+func ConfigureWebTools(opts WebToolsOptions) {
+	if opts.Search == nil && !opts.Fetch {
+		webTools = nil
+		return
+	}
+	fetchClient = newFetchClient(opts.AllowPrivateNetworks)
+	webTools = &opts
+}
+
+// NewSearchBackend returns the named backend: "searxng" queries the instance at endpoint,
+// "brave" the Brave Search API with apiKey, falling back to BRAVE_API_KEY. An empty name
+// disables web search and returns nil.
+func NewSearchBackend(name, endpoint, apiKey string) (SearchBackend, error) {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case "searxng":
+		if endpoint == "" {
+			return nil, errors.New("searxng search needs the instance URL")
+		}
+		return &SearxNG{URL: endpoint}, nil
+	case "brave":
+		if apiKey = strings.TrimSpace(apiKey); apiKey == "" {
+			apiKey = strings.TrimSpace(os.Getenv(BraveAPIKeyEnv))
+		}
+		if apiKey == "" {
+			return nil, errors.New("brave search needs an API key: set webTools.apiKey or " + BraveAPIKeyEnv)
+		}
+		if endpoint == "" {
+			endpoint = braveSearchURL
+		}
+		return &Brave{URL: endpoint, APIKey: apiKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", name)
+	}
+}
+
+// WebToolDefinitions describes the enabled web tools.
+func WebToolDefinitions() []Definition {
+	if webTools == nil {
+		return nil
+	}
+	var definitions []Definition
+	if webTools.Search != nil {
+		definitions = append(definitions, WebSearchDefinition())
+	}
+	if webTools.Fetch {
+		definitions = append(definitions, FetchURLDefinition())
+	}
+	return definitions
+}
+
+// WebToolHandler returns the handler for the named web tool, or nil when it is not a web
+// tool or is disabled.
+func WebToolHandler(name string) Handler {
+	if webTools == nil {
+		return nil
+	}
+	switch {
+	case name == WebSearchName && webTools.Search != nil:
+		return WebSearch
+	case name == FetchURLName && webTools.Fetch:
+		return FetchURL
+	default:
+		return nil
+	}
+}
+
+// WebSearchDefinition describes the web search tool.
+func WebSearchDefinition() Definition {
+	return Definition{
+		Name:        WebSearchName,
+		Description: "Search the web and return the title, URL and snippet of the top results. Use fetch_url to read a result.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string", "description": "The search terms"},
+				"limit": map[string]any{"type": "integer", "description": "How many results to return", "minimum": 1, "maximum": maxSearchResults},
+			},
+			"required": []string{"query"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string"},
+				"results": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"title":   map[string]any{"type": "string"},
+							"url":     map[string]any{"type": "string"},
+							"snippet": map[string]any{"type": "string"},
+						},
+						"required": []string{"title", "url"},
+					},
+				},
+			},
+			"required": []string{"query", "results"},
+		},
+		Examples: []Example{{
+			Request:   "What changed in the latest Go release?",
+			Arguments: map[string]any{"query": "Go release notes latest version"},
+			Result:    "The top results with their titles, URLs and snippets.",
+		}},
+	}
+}
+
+// FetchURLDefinition describes the page-fetching tool.
+func FetchURLDefinition() Definition {
+	return Definition{
+		Name:        FetchURLName,
+		Description: "Fetch a web page over HTTP(S) and return its title and readable text. Long pages are truncated.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{"type": "string", "description": "The http or https URL to fetch"},
+			},
+			"required": []string{"url"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url":         map[string]any{"type": "string", "description": "The URL after redirects"},
+				"status":      map[string]any{"type": "integer"},
+				"contentType": map[string]any{"type": "string"},
+				"title":       map[string]any{"type": "string"},
+				"truncated":   map[string]any{"type": "boolean"},
+				"text":        map[string]any{"type": "string"},
+			},
+			"required": []string{"url", "status", "truncated", "text"},
+		},
+		Examples: []Example{{
+			Request:   "Summarize https://go.dev/doc/effective_go",
+			Arguments: map[string]any{"url": "https://go.dev/doc/effective_go"},
+			Result:    "The page title and its text without markup, truncated if it is longer than the fetch limit.",
+		}},
+	}
+}
+
+// WebSearch queries the configured backend and returns the results as JSON.
+func WebSearch(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if webTools == nil || webTools.Search == nil {
+		return nil, errors.New("web search is disabled")
+	}
+	query := strings.TrimSpace(stringArgument(args, "query"))
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	limit := webTools.MaxResults
+	switch requested := args["limit"].(type) {
+	case float64:
+		if requested >= 1 {
+			limit = int(requested)
+		}
+	case int:
+		if requested >= 1 {
+			limit = requested
+		}
+	}
+	if limit <= 0 || limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+	results, err := webTools.Search.Search(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("web search failed: %w", err)
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []SearchResult{}
+	}
+	return jsonContent(map[string]any{"query": query, "results": results})
+}
+
+// FetchURL downloads a page within the size and time limits and returns its readable
+// text. HTML is reduced to text; other textual types are returned as they are.
+func FetchURL(ctx context.Context, args map[string]any) ([]ContentPart, error) {
+	if webTools == nil || !webTools.Fetch {
+		return nil, errors.New("fetch_url is disabled")
+	}
+	target, err := url.Parse(strings.TrimSpace(stringArgument(args, "url")))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+	if webTools.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, webTools.FetchTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "agon-mcp/1.0")
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, */*;q=0.5")
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && !textual(mediaType) {
+		return nil, fmt.Errorf("%s is %s, not a text page", target, mediaType)
+	}
+	var reader io.Reader = resp.Body
+	if webTools.MaxFetchBytes > 0 {
+		reader = io.LimitReader(resp.Body, int64(webTools.MaxFetchBytes)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", target, err)
+	}
+	truncated := webTools.MaxFetchBytes > 0 && len(data) > webTools.MaxFetchBytes
+	if truncated {
+		data = data[:webTools.MaxFetchBytes]
+	}
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+		if !textual(mediaType) {
+			return nil, fmt.Errorf("%s is %s, not a text page", target, mediaType)
+		}
+	}
+
+	body := strings.ToValidUTF8(string(data), "")
+	payload := map[string]any{
+		"url":         resp.Request.URL.String(),
+		"status":      resp.StatusCode,
+		"contentType": mediaType,
+		"truncated":   truncated,
+	}
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		payload["title"], payload["text"] = htmlToText(body)
+	} else {
+		payload["text"] = body
+	}
+	return jsonContent(payload)
+}
+
+// textual reports whether a media type is text fetch_url can return.
+func textual(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/xml", mediaType == "application/xhtml+xml":
+		return true
+	default:
+		return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+	}
+}
+
+var (
+	// htmlTitle captures the document title.
+	htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title\s*>`)
+	// htmlHidden matches comments and elements whose content is never read.
+	htmlHidden = regexp.MustCompile(`(?is)<!--.*?-->|<head[\s>].*?</head\s*>|<title[\s>].*?</title\s*>|<script[\s>].*?</script\s*>|<style[\s>].*?</style\s*>|<noscript[\s>].*?</noscript\s*>|<svg[\s>].*?</svg\s*>|<template[\s>].*?</template\s*>`)
+	// htmlBlock matches tags that start a new line of text.
+	htmlBlock = regexp.MustCompile(`(?i)<(?:br|hr|/?(?:p|div|section|article|header|footer|main|aside|nav|ul|ol|li|dl|dt|dd|h[1-6]|table|tr|blockquote|pre|figure|figcaption))\b[^>]*>`)
+	// htmlTag matches any remaining tag.
+	htmlTag = regexp.MustCompile(`(?s)<[^>]*>`)
+	// spaceRun matches runs of horizontal whitespace.
+	spaceRun = regexp.MustCompile(`[ \t\f\r\x{00a0}]+`)
+)
+
+// htmlToText returns a page's title and its visible text, one block per line.
+func htmlToText(page string) (title, text string) {
+	if match := htmlTitle.FindStringSubmatch(page); match != nil {
+		title = strings.TrimSpace(spaceRun.ReplaceAllString(html.UnescapeString(htmlTag.ReplaceAllString(match[1], "")), " "))
+	}
+	page = htmlHidden.ReplaceAllString(page, " ")
+	page = htmlBlock.ReplaceAllString(page, "\n")
+	page = html.UnescapeString(htmlTag.ReplaceAllString(page, " "))
+
+	var lines []string
+	for _, line := range strings.Split(page, "\n") {
+		if line = strings.TrimSpace(spaceRun.ReplaceAllString(line, " ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+// newFetchClient returns the client fetch_url downloads with. Unless private networks are
+// allowed it refuses to connect to loopback, private and link-local addresses, checked
+// after DNS resolution so redirects and rebinding cannot reach them either.
+func newFetchClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateAddress(ip) {
+				return fmt.Errorf("refusing to fetch from private address %s", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the proxy's address the only one checked.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow a redirect to %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// privateAddress reports whether ip is loopback, private, link-local or unspecified.
+func privateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// SearxNG searches through a SearxNG instance's JSON API, which must be enabled in its
+// settings (search.formats includes json).
+type SearxNG struct {
+	URL    string
+	Client *http.Client
+}
+
+// Search implements SearchBackend.
+func (s *SearxNG) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	var body struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := searchRequest(ctx, s.Client, s.URL+"/search?"+params.Encode(), nil, &body); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, min(limit, len(body.Results)))
+	for _, r := range body.Results {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
+
+// Brave searches through the Brave Search API.
+type Brave struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Search implements SearchBackend.
+func (b *Brave) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}, "count": {fmt.Sprint(limit)}}
+	var body struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	header := http.Header{"X-Subscription-Token": {b.APIKey}}
+	if err := searchRequest(ctx, b.Client, b.URL+"?"+params.Encode(), header, &body); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, min(limit, len(body.Web.Results)))
+	for _, r := range body.Web.Results {
+		if len(results) == limit {
+			break
+		}
+		// Brave marks the matched terms in descriptions with <strong>.
+		snippet := html.UnescapeString(htmlTag.ReplaceAllString(r.Description, ""))
+		results = append(results, SearchResult{Title: html.UnescapeString(r.Title), URL: r.URL, Snippet: snippet})
+	}
+	return results, nil
+}
+
+// searchRequest GETs a search API endpoint and decodes its JSON answer into out.
+func searchRequest(ctx context.Context, client *http.Client, endpoint string, header http.Header, out any) error {
+	if client == nil {
+		client = httpClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("search API returned %s: %s", resp.Status, strings.TrimSpace(strings.ToValidUTF8(string(data), "")))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unable to decode search results: %w", err)
+	}
+	return nil
+}
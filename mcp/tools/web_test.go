@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHTMLToText verifies markup, scripts and styles are dropped and blocks become lines.
+func TestHTMLToText(t *testing.T) {
+	page := `<!doctype html><html><head><title>Release &amp; notes</title><style>p{color:red}</style></head>
+<body><script>alert("x")</script><h1>Go 1.25</h1><p>Faster <b>builds</b>&nbsp;and
+  fewer   allocations.</p><!-- hidden --><ul><li>one</li><li>two</li></ul></body></html>`
+	title, text := htmlToText(page)
+	if title != "Release & notes" {
+		t.Fatalf("unexpected title %q", title)
+	}
+	if want := "Go 1.25\nFaster builds and\nfewer allocations.\none\ntwo"; text != want {
+		t.Fatalf("unexpected text:\n%q\nwant:\n%q", text, want)
+	}
+}
+
+// TestFetchURL verifies pages are reduced to text within the size limit, binary content is
+// refused, and private addresses are refused unless allowed.
+func TestFetchURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<title>Docs</title><p>" + strings.Repeat("a", 100) + "</p>"))
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	defer server.Close()
+	t.Cleanup(func() { ConfigureWebTools(WebToolsOptions{}) })
+	ctx := context.Background()
+
+	ConfigureWebTools(WebToolsOptions{Fetch: true, MaxFetchBytes: 1 << 10, FetchTimeout: time.Second})
+	if _, err := FetchURL(ctx, map[string]any{"url": server.URL + "/page"}); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Fatalf("expected a loopback address to be refused, got %v", err)
+	}
+
+	ConfigureWebTools(WebToolsOptions{Fetch: true, MaxFetchBytes: 1 << 10, FetchTimeout: time.Second, AllowPrivateNetworks: true})
+	content, err := FetchURL(ctx, map[string]any{"url": server.URL + "/moved"})
+	if err != nil {
+		t.Fatalf("FetchURL returned error: %v", err)
+	}
+	payload := decodeFileResult(t, content)
+	if payload["title"] != "Docs" || payload["text"] != strings.Repeat("a", 100) || payload["truncated"] != false || payload["url"] != server.URL+"/page" {
+		t.Fatalf("unexpected fetch result %+v", payload)
+	}
+
+	ConfigureWebTools(WebToolsOptions{Fetch: true, MaxFetchBytes: 20, AllowPrivateNetworks: true})
+	content, err = FetchURL(ctx, map[string]any{"url": server.URL + "/page"})
+	if err != nil {
+		t.Fatalf("FetchURL returned error: %v", err)
+	}
+	if payload := decodeFileResult(t, content); payload["truncated"] != true {
+		t.Fatalf("expected a truncated fetch, got %+v", payload)
+	}
+	if _, err := FetchURL(ctx, map[string]any{"url": server.URL + "/logo.png"}); err == nil {
+		t.Fatalf("expected an image to be refused")
+	}
+	for _, target := range []string{"file:///etc/passwd", "/relative", ""} {
+		if _, err := FetchURL(ctx, map[string]any{"url": target}); err == nil {
+			t.Fatalf("expected %q to be refused", target)
+		}
+	}
+}
+
+// TestWebSearchBackends verifies both backends map their APIs onto search results and
+// only enabled web tools are advertised.
+func TestWebSearchBackends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("format") != "json" {
+				http.Error(w, "format", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"results":[{"title":"A","url":"https://a.example","content":"first"},{"title":"B","url":"https://b.example"},{"title":"C","url":"https://c.example"}]}`))
+		case "/brave":
+			if r.Header.Get("X-Subscription-Token") != "token" || r.URL.Query().Get("count") != "2" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"web":{"results":[{"title":"Go &amp; you","url":"https://go.dev","description":"The <strong>Go</strong> language"}]}}`))
+		}
+	}))
+	defer server.Close()
+	t.Cleanup(func() { ConfigureWebTools(WebToolsOptions{}) })
+	ctx := context.Background()
+
+	searx, err := NewSearchBackend("searxng", server.URL+"/", "")
+	if err != nil {
+		t.Fatalf("NewSearchBackend returned error: %v", err)
+	}
+	ConfigureWebTools(WebToolsOptions{Search: searx, MaxResults: 2})
+	if defs := WebToolDefinitions(); len(defs) != 1 || defs[0].Name != WebSearchName || WebToolHandler(FetchURLName) != nil {
+		t.Fatalf("expected only web_search to be enabled, got %+v", defs)
+	}
+	content, err := WebSearch(ctx, map[string]any{"query": "agon"})
+	if err != nil {
+		t.Fatalf("WebSearch returned error: %v", err)
+	}
+	results := decodeFileResult(t, content)["results"].([]any)
+	if len(results) != 2 || results[0].(map[string]any)["snippet"] != "first" {
+		t.Fatalf("unexpected searxng results %+v", results)
+	}
+
+	brave, err := NewSearchBackend("brave", server.URL+"/brave", "token")
+	if err != nil {
+		t.Fatalf("NewSearchBackend returned error: %v", err)
+	}
+	ConfigureWebTools(WebToolsOptions{Search: brave, MaxResults: 5})
+	content, err = WebSearch(ctx, map[string]any{"query": "go", "limit": float64(2)})
+	if err != nil {
+		t.Fatalf("WebSearch returned error: %v", err)
+	}
+	result := decodeFileResult(t, content)["results"].([]any)[0].(map[string]any)
+	if result["title"] != "Go & you" || result["snippet"] != "The Go language" {
+		t.Fatalf("unexpected brave result %+v", result)
+	}
+
+	t.Setenv(BraveAPIKeyEnv, "")
+	if _, err := NewSearchBackend("brave", "", ""); err == nil {
+		t.Fatalf("expected brave without a key to fail")
+	}
+	if backend, err := NewSearchBackend("", "", ""); backend != nil || err != nil {
+		t.Fatalf("expected no backend without a name, got %v, %v", backend, err)
+	}
+}
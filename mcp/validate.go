@@ -0,0 +1,310 @@
+// mcp/validate.go
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// invalidParamsCode is the JSON-RPC error code for arguments that fail validation.
+const invalidParamsCode = -32602
+
+// fieldError is one way an argument does not match its tool's input schema.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// invalidArgumentsError reports tool arguments that failed schema validation. It is
+// answered with a -32602 error whose data lists each field, so a model can correct them.
+type invalidArgumentsError struct {
+	tool   string
+	fields []fieldError
+}
+
+// Error implements the error interface.
+func (e *invalidArgumentsError) Error() string {
+	parts := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		parts[i] = field.Field + " " + field.Message
+	}
+	return fmt.Sprintf("Invalid params: %s arguments: %s", e.tool, strings.Join(parts, "; "))
+}
+
+// rpcError converts the error to its JSON-RPC form.
+func (e *invalidArgumentsError) rpcError() *jsonrpcError {
+	return &jsonrpcError{
+		Code:    invalidParamsCode,
+		Message: e.Error(),
+		Data:    map[string]any{"tool": e.tool, "errors": e.fields},
+	}
+}
+
+// validateArguments checks args against the input schema of the named tool before it is
+// dispatched. Arguments agon adds for its own server, prefixed "__", are ignored, and so
+// are tools without a definition, which answer as unknown.
+func validateArguments(name string, args map[string]any) error {
+	var schema map[string]any
+	for _, def := range toolDefinitions() {
+		if def.Name == name {
+			schema = def.Parameters
+			break
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	visible := make(map[string]any, len(args))
+	for key, value := range args {
+		if !strings.HasPrefix(key, "__") {
+			visible[key] = value
+		}
+	}
+	var errs []fieldError
+	validateValue("arguments", visible, schema, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &invalidArgumentsError{tool: name, fields: errs}
+}
+
+// validateValue appends each way value breaks schema to errs, naming the value path. It
+// covers the JSON Schema keywords tool definitions use: type, enum, properties, required,
+// additionalProperties, items, and the numeric, length and pattern bounds.
+func validateValue(path string, value any, schema map[string]any, errs *[]fieldError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaStrings(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must be %s, got %s", joinTypes(types), jsonType(value))
+			return
+		}
+	}
+	if options, ok := schema["enum"].([]any); ok && !containsValue(options, value) {
+		fail("must be one of %s", formatValues(options))
+	} else if options := schemaStrings(schema["enum"]); len(options) > 0 && !containsString(options, value) {
+		fail("must be one of %s", strings.Join(quoteAll(options), ", "))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for _, field := range schemaStrings(schema["required"]) {
+			if _, ok := v[field]; !ok {
+				*errs = append(*errs, fieldError{Field: joinPath(path, field), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := properties[key].(map[string]any); ok {
+				validateValue(joinPath(path, key), v[key], property, errs)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*errs = append(*errs, fieldError{Field: joinPath(path, key), Message: "is not a known argument"})
+				}
+			case map[string]any:
+				validateValue(joinPath(path, key), v[key], extra, errs)
+			}
+		}
+	case []any:
+		if limit, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < limit {
+			fail("must have at least %v items", limit)
+		}
+		if limit, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > limit {
+			fail("must have at most %v items", limit)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateValue(fmt.Sprintf("%s[%d]", path, i), item, items, errs)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if limit, ok := schemaNumber(schema["minLength"]); ok && length < limit {
+			fail("must be at least %v characters", limit)
+		}
+		if limit, ok := schemaNumber(schema["maxLength"]); ok && length > limit {
+			fail("must be at most %v characters", limit)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("must match %q", pattern)
+			}
+		}
+	default:
+		if number, ok := schemaNumber(value); ok {
+			if limit, ok := schemaNumber(schema["minimum"]); ok && number < limit {
+				fail("must be at least %v", limit)
+			}
+			if limit, ok := schemaNumber(schema["maximum"]); ok && number > limit {
+				fail("must be at most %v", limit)
+			}
+		}
+	}
+}
+
+// matchesType reports whether value is of the named JSON Schema type.
+func matchesType(value any, name string) bool {
+	switch name {
+	case "integer":
+		number, ok := schemaNumber(value)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := schemaNumber(value)
+		return ok
+	default:
+		return jsonType(value) == name
+	}
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if _, ok := schemaNumber(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaNumber reads a decoded JSON number, or one a Go schema literal holds as an int.
+func schemaNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// schemaStrings reads a keyword that holds one string or a list of them, as written in Go
+// schema literals ([]string) or decoded from JSON ([]any).
+func schemaStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// containsValue reports whether options holds value, comparing numbers by value.
+func containsValue(options []any, value any) bool {
+	for _, option := range options {
+		if a, ok := schemaNumber(option); ok {
+			if b, ok := schemaNumber(value); ok && a == b {
+				return true
+			}
+			continue
+		}
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether value is a string in options.
+func containsString(options []string, value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, option := range options {
+		if option == s {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValues renders enum options for an error message.
+func formatValues(options []any) string {
+	parts := make([]string, len(options))
+	for i, option := range options {
+		if s, ok := option.(string); ok {
+			parts[i] = fmt.Sprintf("%q", s)
+		} else {
+			parts[i] = fmt.Sprint(option)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// quoteAll quotes each string.
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return quoted
+}
+
+// joinTypes renders allowed types for an error message, e.g. "a string or null".
+func joinTypes(types []string) string {
+	named := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "null":
+			named[i] = "null"
+		case "array", "integer", "object":
+			named[i] = "an " + t
+		default:
+			named[i] = "a " + t
+		}
+	}
+	return strings.Join(named, " or ")
+}
+
+// joinPath names a property of the value at path. Top-level arguments go by their own name.
+func joinPath(path, key string) string {
+	if path == "arguments" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,106 @@
+// mcp/validate_test.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestValidateArguments verifies that tool arguments are checked against the tool's input
+// schema, ignoring the server's own "__" arguments and tools without a definition.
+func TestValidateArguments(t *testing.T) {
+	if err := validateArguments("current_weather", map[string]any{"location": "Paris", "__mcp_attempt": 2.0}); err != nil {
+		t.Fatalf("expected valid arguments to pass, got %v", err)
+	}
+	if err := validateArguments("no_such_tool", map[string]any{"x": 1.0}); err != nil {
+		t.Fatalf("expected unknown tools to be left to dispatch, got %v", err)
+	}
+
+	err := validateArguments("current_weather", map[string]any{"__user_prompt": "weather?"})
+	var invalid *invalidArgumentsError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected an invalidArgumentsError for a missing location, got %v", err)
+	}
+	if len(invalid.fields) != 1 || invalid.fields[0] != (fieldError{Field: "location", Message: "is required"}) {
+		t.Fatalf("unexpected field errors: %#v", invalid.fields)
+	}
+
+	err = validateArguments("current_weather", map[string]any{"location": 42.0})
+	if !errors.As(err, &invalid) || invalid.fields[0].Field != "location" || invalid.fields[0].Message != "must be a string, got number" {
+		t.Fatalf("unexpected error for a numeric location: %v", err)
+	}
+
+	rpcErr := invalid.rpcError()
+	if rpcErr.Code != -32602 {
+		t.Fatalf("expected code -32602, got %d", rpcErr.Code)
+	}
+	encoded, _ := json.Marshal(rpcErr)
+	var decoded struct {
+		Data struct {
+			Tool   string       `json:"tool"`
+			Errors []fieldError `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.Data.Tool != "current_weather" || len(decoded.Data.Errors) != 1 {
+		t.Fatalf("unexpected error data %s", encoded)
+	}
+}
+
+// TestValidateValue verifies the schema keywords tool definitions can use, reporting each
+// failing field by its path.
+func TestValidateValue(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["path", "mode"],
+		"additionalProperties": false,
+		"properties": {
+			"path": {"type": "string", "minLength": 1, "pattern": "^/"},
+			"mode": {"type": "string", "enum": ["read", "write"]},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 100},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"options": {"type": "object", "properties": {"recursive": {"type": "boolean"}}}
+		}
+	}`), &schema); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"path": "relative",
+		"mode": "append",
+		"limit": 2.5,
+		"tags": ["a", 1, "c"],
+		"options": {"recursive": "yes"},
+		"extra": true
+	}`), &args); err != nil {
+		t.Fatalf("args: %v", err)
+	}
+
+	var errs []fieldError
+	validateValue("arguments", args, schema, &errs)
+	want := map[string]string{
+		"extra":             "is not a known argument",
+		"limit":             "must be an integer, got number",
+		"mode":              `must be one of "read", "write"`,
+		"options.recursive": "must be a boolean, got string",
+		"path":              `must match "^/"`,
+		"tags":              "must have at most 2 items",
+		"tags[1]":           "must be a string, got number",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d field errors, got %#v", len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Field] != e.Message {
+			t.Fatalf("unexpected error for %s: %q", e.Field, e.Message)
+		}
+	}
+
+	errs = nil
+	validateValue("arguments", map[string]any{"path": "/tmp", "mode": "read", "limit": 10.0}, schema, &errs)
+	if len(errs) != 0 {
+		t.Fatalf("expected valid arguments to pass, got %#v", errs)
+	}
+}